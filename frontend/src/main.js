@@ -5,11 +5,17 @@ if (!apiBase) {
   apiBase = 'http://localhost:8080';
 }
 
+// Admin endpoints require an API key (see `go run ./cmd/apictl bootstrap`).
+const adminApiKey = import.meta.env.VITE_ADMIN_API_KEY;
+
 function setOutput(status, body) {
   output.textContent = JSON.stringify({ status, body }, null, 2);
 }
 
 async function request(path, options = {}) {
+  if (adminApiKey && path.startsWith('/admin/')) {
+    options = { ...options, headers: { ...options.headers, 'X-API-Key': adminApiKey } };
+  }
   const res = await fetch(`${apiBase}${path}`, options);
   const text = await res.text();
   let body = text;
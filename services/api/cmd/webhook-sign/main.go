@@ -0,0 +1,64 @@
+// Command webhook-sign signs a webhook payload the way a payment processor
+// would, so the webhook endpoint can be exercised locally without one.
+//
+//	echo '{"id":"evt_1","type":"payment.captured","order_id":"<order_id>"}' | \
+//	  go run ./cmd/webhook-sign -url http://localhost:8080/webhooks/payments
+//
+// Without -url it prints the signature header to stdout.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/cimillas/ultimate-ticket/services/api/internal/webhook"
+)
+
+func main() {
+	secret := flag.String("secret", os.Getenv("PAYMENT_WEBHOOK_SECRET"), "signing secret (default: $PAYMENT_WEBHOOK_SECRET)")
+	data := flag.String("data", "", "payload to sign (default: read stdin)")
+	url := flag.String("url", "", "if set, POST the signed payload to this URL")
+	skew := flag.Duration("skew", 0, "shift the signature timestamp, e.g. -10m to test tolerance")
+	flag.Parse()
+
+	if *secret == "" {
+		log.Fatal("secret is required (-secret or PAYMENT_WEBHOOK_SECRET)")
+	}
+
+	payload := []byte(*data)
+	if *data == "" {
+		var err error
+		payload, err = io.ReadAll(os.Stdin)
+		if err != nil {
+			log.Fatalf("read payload: %v", err)
+		}
+		payload = bytes.TrimSpace(payload)
+	}
+
+	signature := webhook.Sign([]byte(*secret), time.Now().Add(*skew), payload)
+	if *url == "" {
+		fmt.Printf("%s: %s\n", webhook.SignatureHeader, signature)
+		return
+	}
+
+	req, err := http.NewRequest(http.MethodPost, *url, bytes.NewReader(payload))
+	if err != nil {
+		log.Fatalf("build request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(webhook.SignatureHeader, signature)
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		log.Fatalf("send webhook: %v", err)
+	}
+	defer res.Body.Close()
+	body, _ := io.ReadAll(res.Body)
+	fmt.Printf("%s\n%s\n", res.Status, bytes.TrimSpace(body))
+}
@@ -0,0 +1,50 @@
+package admission
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cimillas/ultimate-ticket/services/api/internal/clock"
+)
+
+func TestSigner(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, 1, 7, 12, 0, 0, 0, time.UTC)
+	secret := []byte("admission_test")
+	s := NewSigner(secret, clock.NewFixed(now))
+	valid := s.Issue("event-1", "entry-1", "cust-1", now.Add(5*time.Minute))
+	payload, _, _ := strings.Cut(valid, ".")
+
+	tests := []struct {
+		name         string
+		token        string
+		eventID      string
+		wantCustomer string
+		want         error
+	}{
+		{name: "valid", token: valid, eventID: "event-1", wantCustomer: "cust-1"},
+		{name: "anonymous", token: s.Issue("event-1", "entry-1", "", now.Add(time.Minute)), eventID: "event-1"},
+		{name: "other event", token: valid, eventID: "event-2", want: ErrInvalidToken},
+		{name: "expired", token: s.Issue("event-1", "entry-1", "", now), eventID: "event-1", want: ErrTokenExpired},
+		{name: "wrong secret", token: NewSigner([]byte("other"), clock.NewFixed(now)).Issue("event-1", "entry-1", "", now.Add(time.Minute)), eventID: "event-1", want: ErrInvalidToken},
+		{name: "tampered payload", token: s.Issue("event-1", "entry-1", "cust-2", now.Add(time.Hour))[:len(payload)] + valid[len(payload):], eventID: "event-1", want: ErrInvalidToken},
+		{name: "malformed", token: "garbage", eventID: "event-1", want: ErrInvalidToken},
+		{name: "empty", token: "", eventID: "event-1", want: ErrInvalidToken},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			entryID, customerID, err := s.Verify(tt.token, tt.eventID)
+			if err != tt.want {
+				t.Fatalf("expected %v, got %v", tt.want, err)
+			}
+			if err == nil && (entryID != "entry-1" || customerID != tt.wantCustomer) {
+				t.Fatalf("expected entry-1 for %q, got %q for %q", tt.wantCustomer, entryID, customerID)
+			}
+		})
+	}
+}
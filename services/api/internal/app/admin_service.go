@@ -8,11 +8,18 @@ import (
 	"github.com/cimillas/ultimate-ticket/services/api/internal/domain"
 )
 
+// AdminRepository reads and writes events and zones on behalf of one
+// organizer. Events of other organizers are reported as ErrEventNotFound.
 type AdminRepository interface {
+	WithTx(ctx context.Context, fn func(ctx context.Context) error) error
 	CreateEvent(ctx context.Context, event domain.Event) error
-	ListEvents(ctx context.Context) ([]domain.Event, error)
-	CreateZone(ctx context.Context, zone domain.Zone) error
-	ListZonesByEvent(ctx context.Context, eventID string) ([]domain.Zone, error)
+	ListEvents(ctx context.Context, organizerID string) ([]domain.Event, error)
+	GetEventForUpdate(ctx context.Context, organizerID, eventID string) (domain.Event, error)
+	UpdateEvent(ctx context.Context, event domain.Event) error
+	AppendOutboxEvent(ctx context.Context, event domain.OutboxEvent) error
+	AppendAuditEntry(ctx context.Context, entry domain.AuditEntry) error
+	CreateZone(ctx context.Context, organizerID string, zone domain.Zone) error
+	ListZonesByEvent(ctx context.Context, organizerID, eventID string) ([]domain.Zone, error)
 }
 
 type AdminService struct {
@@ -28,39 +35,155 @@ func NewAdminService(repo AdminRepository, clk clock.Clock) *AdminService {
 }
 
 type CreateEventInput struct {
-	Name     string
-	StartsAt *time.Time
+	OrganizerID string
+	Name        string
+	StartsAt    *time.Time
+	WaitingRoom domain.WaitingRoom
+	// PurchaseLimit caps the tickets per customer; 0 means unlimited.
+	PurchaseLimit int
 }
 
 func (s *AdminService) CreateEvent(ctx context.Context, in CreateEventInput) (domain.Event, error) {
 	if in.Name == "" {
 		return domain.Event{}, domain.ErrEventNameRequired
 	}
+	if !validWaitingRoom(in.WaitingRoom) {
+		return domain.Event{}, domain.ErrInvalidAdmissionRate
+	}
+	if in.PurchaseLimit < 0 {
+		return domain.Event{}, domain.ErrInvalidPurchaseLimit
+	}
 	startsAt := s.clock.Now()
 	if in.StartsAt != nil {
 		startsAt = *in.StartsAt
 	}
 
 	event := domain.Event{
-		ID:       newUUID(),
-		Name:     in.Name,
-		StartsAt: startsAt,
+		ID:            newUUID(),
+		OrganizerID:   in.OrganizerID,
+		Name:          in.Name,
+		StartsAt:      startsAt,
+		WaitingRoom:   in.WaitingRoom,
+		PurchaseLimit: in.PurchaseLimit,
 	}
 
-	if err := s.repo.CreateEvent(ctx, event); err != nil {
+	err := s.repo.WithTx(ctx, func(txCtx context.Context) error {
+		if err := s.repo.CreateEvent(txCtx, event); err != nil {
+			return err
+		}
+		return s.audit(txCtx, in.OrganizerID, domain.AuditEventCreated, domain.AuditTargetEvent, event.ID, nil, newEventSnapshot(event))
+	})
+	if err != nil {
 		return domain.Event{}, err
 	}
 	return event, nil
 }
 
-func (s *AdminService) ListEvents(ctx context.Context) ([]domain.Event, error) {
-	return s.repo.ListEvents(ctx)
+func (s *AdminService) ListEvents(ctx context.Context, organizerID string) ([]domain.Event, error) {
+	return s.repo.ListEvents(ctx, organizerID)
+}
+
+type UpdateEventInput struct {
+	OrganizerID string
+	EventID     string
+	Name        *string
+	StartsAt    *time.Time
+	WaitingRoom *domain.WaitingRoom
+	// PurchaseLimit replaces the tickets allowed per customer; 0 removes it.
+	PurchaseLimit *int
+}
+
+type eventUpdatedPayload struct {
+	EventID          string    `json:"event_id"`
+	Name             string    `json:"name"`
+	StartsAt         time.Time `json:"starts_at"`
+	PreviousName     string    `json:"previous_name"`
+	PreviousStartsAt time.Time `json:"previous_starts_at"`
+}
+
+// UpdateEvent changes an event's name, start time, waiting room or purchase
+// limit. Name and start time changes emit event.updated so ticket holders can
+// be notified. Unchanged updates emit nothing.
+func (s *AdminService) UpdateEvent(ctx context.Context, in UpdateEventInput) (domain.Event, error) {
+	if in.EventID == "" {
+		return domain.Event{}, domain.ErrInvalidID
+	}
+	if in.Name != nil && *in.Name == "" {
+		return domain.Event{}, domain.ErrEventNameRequired
+	}
+	if in.WaitingRoom != nil && !validWaitingRoom(*in.WaitingRoom) {
+		return domain.Event{}, domain.ErrInvalidAdmissionRate
+	}
+	if in.PurchaseLimit != nil && *in.PurchaseLimit < 0 {
+		return domain.Event{}, domain.ErrInvalidPurchaseLimit
+	}
+
+	now := s.clock.Now()
+	var result domain.Event
+	err := s.repo.WithTx(ctx, func(txCtx context.Context) error {
+		current, err := s.repo.GetEventForUpdate(txCtx, in.OrganizerID, in.EventID)
+		if err != nil {
+			return err
+		}
+		updated := current
+		if in.Name != nil {
+			updated.Name = *in.Name
+		}
+		if in.StartsAt != nil {
+			updated.StartsAt = *in.StartsAt
+		}
+		if in.WaitingRoom != nil {
+			updated.WaitingRoom = *in.WaitingRoom
+		}
+		if in.PurchaseLimit != nil {
+			updated.PurchaseLimit = *in.PurchaseLimit
+		}
+		result = updated
+		scheduleChanged := updated.Name != current.Name || !updated.StartsAt.Equal(current.StartsAt)
+		if !scheduleChanged && updated.WaitingRoom == current.WaitingRoom && updated.PurchaseLimit == current.PurchaseLimit {
+			return nil
+		}
+
+		if err := s.repo.UpdateEvent(txCtx, updated); err != nil {
+			return err
+		}
+		if scheduleChanged {
+			event, err := newOutboxEvent(domain.OutboxAggregateEvent, updated.ID, domain.OutboxEventUpdated, eventUpdatedPayload{
+				EventID:          updated.ID,
+				Name:             updated.Name,
+				StartsAt:         updated.StartsAt,
+				PreviousName:     current.Name,
+				PreviousStartsAt: current.StartsAt,
+			}, now)
+			if err != nil {
+				return err
+			}
+			if err := s.repo.AppendOutboxEvent(txCtx, event); err != nil {
+				return err
+			}
+		}
+		return s.audit(txCtx, in.OrganizerID, domain.AuditEventUpdated, domain.AuditTargetEvent, updated.ID,
+			newEventSnapshot(current), newEventSnapshot(updated))
+	})
+	if err != nil {
+		return domain.Event{}, err
+	}
+	return result, nil
 }
 
 type CreateZoneInput struct {
-	EventID  string
-	Name     string
-	Capacity int
+	OrganizerID string
+	EventID     string
+	Name        string
+	Capacity    int
+	// Optional per-hold quantity rules; zero means the default (a step of 1,
+	// a minimum of one step, no maximum).
+	MinQuantity  int
+	MaxQuantity  int
+	QuantityStep int
+	// PurchaseLimit caps the tickets per customer in the zone; 0 means
+	// unlimited.
+	PurchaseLimit int
 }
 
 func (s *AdminService) CreateZone(ctx context.Context, in CreateZoneInput) (domain.Zone, error) {
@@ -73,23 +196,74 @@ func (s *AdminService) CreateZone(ctx context.Context, in CreateZoneInput) (doma
 	if in.Capacity <= 0 {
 		return domain.Zone{}, domain.ErrInvalidCapacity
 	}
+	if in.MinQuantity < 0 || in.MaxQuantity < 0 || in.QuantityStep < 0 {
+		return domain.Zone{}, domain.ErrInvalidQuantityRules
+	}
+	if in.PurchaseLimit < 0 {
+		return domain.Zone{}, domain.ErrInvalidPurchaseLimit
+	}
 
 	zone := domain.Zone{
-		ID:       newUUID(),
-		EventID:  in.EventID,
-		Name:     in.Name,
-		Capacity: in.Capacity,
+		ID:            newUUID(),
+		EventID:       in.EventID,
+		Name:          in.Name,
+		Capacity:      in.Capacity,
+		MinQuantity:   in.MinQuantity,
+		MaxQuantity:   in.MaxQuantity,
+		QuantityStep:  in.QuantityStep,
+		PurchaseLimit: in.PurchaseLimit,
+	}
+	zone.MinQuantity, zone.MaxQuantity, zone.QuantityStep = zone.QuantityRules()
+	if !validQuantityRules(zone) {
+		return domain.Zone{}, domain.ErrInvalidQuantityRules
 	}
 
-	if err := s.repo.CreateZone(ctx, zone); err != nil {
+	err := s.repo.WithTx(ctx, func(txCtx context.Context) error {
+		if err := s.repo.CreateZone(txCtx, in.OrganizerID, zone); err != nil {
+			return err
+		}
+		return s.audit(txCtx, in.OrganizerID, domain.AuditZoneCreated, domain.AuditTargetZone, zone.ID, nil, newZoneSnapshot(zone))
+	})
+	if err != nil {
 		return domain.Zone{}, err
 	}
 	return zone, nil
 }
 
-func (s *AdminService) ListZones(ctx context.Context, eventID string) ([]domain.Zone, error) {
+// validWaitingRoom rejects negative rates and enabled waiting rooms that
+// would never admit anyone.
+func validWaitingRoom(w domain.WaitingRoom) bool {
+	if w.AdmitPerMinute < 0 {
+		return false
+	}
+	return !w.Enabled || w.AdmitPerMinute > 0
+}
+
+// validQuantityRules reports whether some hold can satisfy the zone's rules:
+// the bounds must be multiples of the step and the minimum must fit in the
+// zone.
+func validQuantityRules(z domain.Zone) bool {
+	minQty, maxQty, step := z.QuantityRules()
+	if minQty%step != 0 || minQty > z.Capacity {
+		return false
+	}
+	if maxQty > 0 && (maxQty < minQty || maxQty%step != 0) {
+		return false
+	}
+	return true
+}
+
+func (s *AdminService) ListZones(ctx context.Context, organizerID, eventID string) ([]domain.Zone, error) {
 	if eventID == "" {
 		return nil, domain.ErrInvalidID
 	}
-	return s.repo.ListZonesByEvent(ctx, eventID)
+	return s.repo.ListZonesByEvent(ctx, organizerID, eventID)
+}
+
+func (s *AdminService) audit(ctx context.Context, organizerID string, action domain.AuditAction, targetType, targetID string, before, after any) error {
+	entry, err := newAuditEntry(ctx, organizerID, action, targetType, targetID, before, after, s.clock.Now())
+	if err != nil {
+		return err
+	}
+	return s.repo.AppendAuditEntry(ctx, entry)
 }
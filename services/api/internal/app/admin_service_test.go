@@ -12,6 +12,9 @@ import (
 type fakeAdminRepo struct {
 	createdEvent domain.Event
 	createdZone  domain.Zone
+	events       map[string]domain.Event
+	outbox       []domain.OutboxEvent
+	audit        []domain.AuditEntry
 
 	createEventErr error
 	createZoneErr  error
@@ -22,16 +25,49 @@ func (f *fakeAdminRepo) CreateEvent(ctx context.Context, event domain.Event) err
 	return f.createEventErr
 }
 
-func (f *fakeAdminRepo) ListEvents(ctx context.Context) ([]domain.Event, error) {
+func (f *fakeAdminRepo) ListEvents(ctx context.Context, organizerID string) ([]domain.Event, error) {
 	return nil, nil
 }
 
-func (f *fakeAdminRepo) CreateZone(ctx context.Context, zone domain.Zone) error {
+func (f *fakeAdminRepo) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+func (f *fakeAdminRepo) GetEventForUpdate(ctx context.Context, organizerID, eventID string) (domain.Event, error) {
+	event, ok := f.events[eventID]
+	if !ok || event.OrganizerID != organizerID {
+		return domain.Event{}, domain.ErrEventNotFound
+	}
+	return event, nil
+}
+
+func (f *fakeAdminRepo) UpdateEvent(ctx context.Context, event domain.Event) error {
+	f.events[event.ID] = event
+	return nil
+}
+
+func (f *fakeAdminRepo) AppendOutboxEvent(ctx context.Context, event domain.OutboxEvent) error {
+	f.outbox = append(f.outbox, event)
+	return nil
+}
+
+func (f *fakeAdminRepo) AppendAuditEntry(ctx context.Context, entry domain.AuditEntry) error {
+	f.audit = append(f.audit, entry)
+	return nil
+}
+
+func (f *fakeAdminRepo) CreateZone(ctx context.Context, organizerID string, zone domain.Zone) error {
+	if event, ok := f.events[zone.EventID]; ok && event.OrganizerID != organizerID {
+		return domain.ErrEventNotFound
+	}
 	f.createdZone = zone
 	return f.createZoneErr
 }
 
-func (f *fakeAdminRepo) ListZonesByEvent(ctx context.Context, eventID string) ([]domain.Zone, error) {
+func (f *fakeAdminRepo) ListZonesByEvent(ctx context.Context, organizerID, eventID string) ([]domain.Zone, error) {
+	if event, ok := f.events[eventID]; !ok || event.OrganizerID != organizerID {
+		return nil, domain.ErrEventNotFound
+	}
 	return nil, nil
 }
 
@@ -40,10 +76,13 @@ func TestAdminService_CreateEvent_DefaultStartsAt(t *testing.T) {
 	now := time.Date(2025, 1, 5, 10, 0, 0, 0, time.UTC)
 	svc := NewAdminService(repo, clock.NewFixed(now))
 
-	got, err := svc.CreateEvent(context.Background(), CreateEventInput{Name: "Concert"})
+	got, err := svc.CreateEvent(context.Background(), CreateEventInput{OrganizerID: "org-1", Name: "Concert"})
 	if err != nil {
 		t.Fatalf("create event: %v", err)
 	}
+	if got.OrganizerID != "org-1" {
+		t.Fatalf("expected organizer org-1, got %q", got.OrganizerID)
+	}
 	if got.Name != "Concert" {
 		t.Fatalf("expected name, got %q", got.Name)
 	}
@@ -85,3 +124,190 @@ func TestAdminService_CreateZone_ValidatesInput(t *testing.T) {
 		t.Fatalf("expected ErrInvalidCapacity, got %v", err)
 	}
 }
+
+func TestAdminService_CreateZone_QuantityRules(t *testing.T) {
+	tests := []struct {
+		name     string
+		in       CreateZoneInput
+		wantErr  error
+		wantZone domain.Zone
+	}{
+		{
+			name:     "defaults",
+			in:       CreateZoneInput{Capacity: 100},
+			wantZone: domain.Zone{MinQuantity: 1, MaxQuantity: 0, QuantityStep: 1},
+		},
+		{
+			name:     "pairs only",
+			in:       CreateZoneInput{Capacity: 100, MinQuantity: 2, MaxQuantity: 8, QuantityStep: 2},
+			wantZone: domain.Zone{MinQuantity: 2, MaxQuantity: 8, QuantityStep: 2},
+		},
+		{
+			name:     "tables of six",
+			in:       CreateZoneInput{Capacity: 60, QuantityStep: 6},
+			wantZone: domain.Zone{MinQuantity: 6, MaxQuantity: 0, QuantityStep: 6},
+		},
+		{name: "minimum off step", in: CreateZoneInput{Capacity: 60, MinQuantity: 3, QuantityStep: 6}, wantErr: domain.ErrInvalidQuantityRules},
+		{name: "negative rule", in: CreateZoneInput{Capacity: 100, MaxQuantity: -1}, wantErr: domain.ErrInvalidQuantityRules},
+		{name: "maximum below minimum", in: CreateZoneInput{Capacity: 100, MinQuantity: 4, MaxQuantity: 2}, wantErr: domain.ErrInvalidQuantityRules},
+		{name: "maximum off step", in: CreateZoneInput{Capacity: 100, MinQuantity: 2, MaxQuantity: 7, QuantityStep: 2}, wantErr: domain.ErrInvalidQuantityRules},
+		{name: "minimum above capacity", in: CreateZoneInput{Capacity: 4, MinQuantity: 6}, wantErr: domain.ErrInvalidQuantityRules},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			repo := &fakeAdminRepo{}
+			svc := NewAdminService(repo, clock.NewFixed(time.Now()))
+			tt.in.EventID = "event"
+			tt.in.Name = "Zone A"
+
+			zone, err := svc.CreateZone(context.Background(), tt.in)
+			if err != tt.wantErr {
+				t.Fatalf("expected %v, got %v", tt.wantErr, err)
+			}
+			if err != nil {
+				return
+			}
+			if zone.MinQuantity != tt.wantZone.MinQuantity || zone.MaxQuantity != tt.wantZone.MaxQuantity || zone.QuantityStep != tt.wantZone.QuantityStep {
+				t.Fatalf("expected rules %+v, got %+v", tt.wantZone, zone)
+			}
+			if repo.createdZone.QuantityStep != zone.QuantityStep || repo.createdZone.MinQuantity != zone.MinQuantity {
+				t.Fatalf("expected stored rules to match, got %+v", repo.createdZone)
+			}
+		})
+	}
+}
+
+func TestAdminService_WaitingRoomSettings(t *testing.T) {
+	startsAt := time.Date(2025, 6, 1, 20, 0, 0, 0, time.UTC)
+	repo := &fakeAdminRepo{events: map[string]domain.Event{
+		"event-1": {ID: "event-1", OrganizerID: "org-1", Name: "Concert", StartsAt: startsAt},
+	}}
+	svc := NewAdminService(repo, clock.NewFixed(time.Now()))
+	ctx := context.Background()
+
+	if _, err := svc.CreateEvent(ctx, CreateEventInput{OrganizerID: "org-1", Name: "Festival", WaitingRoom: domain.WaitingRoom{Enabled: true}}); err != domain.ErrInvalidAdmissionRate {
+		t.Fatalf("expected ErrInvalidAdmissionRate, got %v", err)
+	}
+	created, err := svc.CreateEvent(ctx, CreateEventInput{OrganizerID: "org-1", Name: "Festival", WaitingRoom: domain.WaitingRoom{Enabled: true, AdmitPerMinute: 100}})
+	if err != nil || !created.WaitingRoom.Enabled || created.WaitingRoom.AdmitPerMinute != 100 {
+		t.Fatalf("expected waiting room on created event, got %+v (%v)", created, err)
+	}
+
+	if _, err := svc.UpdateEvent(ctx, UpdateEventInput{OrganizerID: "org-1", EventID: "event-1", WaitingRoom: &domain.WaitingRoom{AdmitPerMinute: -1}}); err != domain.ErrInvalidAdmissionRate {
+		t.Fatalf("expected ErrInvalidAdmissionRate, got %v", err)
+	}
+	updated, err := svc.UpdateEvent(ctx, UpdateEventInput{OrganizerID: "org-1", EventID: "event-1", WaitingRoom: &domain.WaitingRoom{Enabled: true, AdmitPerMinute: 50}})
+	if err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if !updated.WaitingRoom.Enabled || repo.events["event-1"].WaitingRoom.AdmitPerMinute != 50 {
+		t.Fatalf("expected waiting room to be stored, got %+v", repo.events["event-1"])
+	}
+	if len(repo.outbox) != 0 {
+		t.Fatalf("expected no event.updated for a waiting room change, got %+v", repo.outbox)
+	}
+	if len(repo.audit) != 2 || repo.audit[1].Action != domain.AuditEventUpdated {
+		t.Fatalf("expected the change to be audited, got %+v", repo.audit)
+	}
+}
+
+func TestAdminService_PurchaseLimits(t *testing.T) {
+	repo := &fakeAdminRepo{events: map[string]domain.Event{
+		"event-1": {ID: "event-1", OrganizerID: "org-1", Name: "Concert", StartsAt: time.Now()},
+	}}
+	svc := NewAdminService(repo, clock.NewFixed(time.Now()))
+	ctx := context.Background()
+
+	if _, err := svc.CreateEvent(ctx, CreateEventInput{OrganizerID: "org-1", Name: "Festival", PurchaseLimit: -1}); err != domain.ErrInvalidPurchaseLimit {
+		t.Fatalf("expected ErrInvalidPurchaseLimit, got %v", err)
+	}
+	created, err := svc.CreateEvent(ctx, CreateEventInput{OrganizerID: "org-1", Name: "Festival", PurchaseLimit: 4})
+	if err != nil || created.PurchaseLimit != 4 || repo.createdEvent.PurchaseLimit != 4 {
+		t.Fatalf("expected limit 4 on created event, got %+v (%v)", created, err)
+	}
+
+	negative := -2
+	if _, err := svc.UpdateEvent(ctx, UpdateEventInput{OrganizerID: "org-1", EventID: "event-1", PurchaseLimit: &negative}); err != domain.ErrInvalidPurchaseLimit {
+		t.Fatalf("expected ErrInvalidPurchaseLimit, got %v", err)
+	}
+	limit := 6
+	if _, err := svc.UpdateEvent(ctx, UpdateEventInput{OrganizerID: "org-1", EventID: "event-1", PurchaseLimit: &limit}); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if repo.events["event-1"].PurchaseLimit != 6 {
+		t.Fatalf("expected limit to be stored, got %+v", repo.events["event-1"])
+	}
+	if len(repo.outbox) != 0 || len(repo.audit) != 2 {
+		t.Fatalf("expected an audited change without event.updated, got outbox %+v audit %+v", repo.outbox, repo.audit)
+	}
+
+	if _, err := svc.CreateZone(ctx, CreateZoneInput{OrganizerID: "org-1", EventID: "event-1", Name: "Floor", Capacity: 10, PurchaseLimit: -1}); err != domain.ErrInvalidPurchaseLimit {
+		t.Fatalf("expected ErrInvalidPurchaseLimit, got %v", err)
+	}
+	zone, err := svc.CreateZone(ctx, CreateZoneInput{OrganizerID: "org-1", EventID: "event-1", Name: "Floor", Capacity: 10, PurchaseLimit: 2})
+	if err != nil || zone.PurchaseLimit != 2 || repo.createdZone.PurchaseLimit != 2 {
+		t.Fatalf("expected limit 2 on created zone, got %+v (%v)", zone, err)
+	}
+}
+
+func TestAdminService_UpdateEvent(t *testing.T) {
+	startsAt := time.Date(2025, 6, 1, 20, 0, 0, 0, time.UTC)
+	repo := &fakeAdminRepo{events: map[string]domain.Event{
+		"event-1": {ID: "event-1", OrganizerID: "org-1", Name: "Concert", StartsAt: startsAt},
+	}}
+	svc := NewAdminService(repo, clock.NewFixed(time.Now()))
+	ctx := context.Background()
+
+	sameName := "Concert"
+	if _, err := svc.UpdateEvent(ctx, UpdateEventInput{OrganizerID: "org-1", EventID: "event-1", Name: &sameName}); err != nil {
+		t.Fatalf("update event: %v", err)
+	}
+	if len(repo.outbox) != 0 {
+		t.Fatalf("expected no event for unchanged update, got %d", len(repo.outbox))
+	}
+
+	moved := startsAt.Add(24 * time.Hour)
+	got, err := svc.UpdateEvent(ctx, UpdateEventInput{OrganizerID: "org-1", EventID: "event-1", StartsAt: &moved})
+	if err != nil {
+		t.Fatalf("update event: %v", err)
+	}
+	if !got.StartsAt.Equal(moved) || got.Name != "Concert" {
+		t.Fatalf("unexpected event %+v", got)
+	}
+	if len(repo.outbox) != 1 || repo.outbox[0].Type != domain.OutboxEventUpdated || repo.outbox[0].AggregateID != "event-1" {
+		t.Fatalf("expected event.updated outbox event, got %+v", repo.outbox)
+	}
+
+	empty := ""
+	if _, err := svc.UpdateEvent(ctx, UpdateEventInput{OrganizerID: "org-1", EventID: "event-1", Name: &empty}); err != domain.ErrEventNameRequired {
+		t.Fatalf("expected ErrEventNameRequired, got %v", err)
+	}
+	if _, err := svc.UpdateEvent(ctx, UpdateEventInput{OrganizerID: "org-1", EventID: "missing", Name: &sameName}); err != domain.ErrEventNotFound {
+		t.Fatalf("expected ErrEventNotFound, got %v", err)
+	}
+}
+
+func TestAdminService_OtherOrganizersEventsAreNotFound(t *testing.T) {
+	repo := &fakeAdminRepo{events: map[string]domain.Event{
+		"event-1": {ID: "event-1", OrganizerID: "org-1", Name: "Concert", StartsAt: time.Now()},
+	}}
+	svc := NewAdminService(repo, clock.NewFixed(time.Now()))
+	ctx := context.Background()
+
+	renamed := "Hijacked"
+	if _, err := svc.UpdateEvent(ctx, UpdateEventInput{OrganizerID: "org-2", EventID: "event-1", Name: &renamed}); err != domain.ErrEventNotFound {
+		t.Fatalf("expected ErrEventNotFound on update, got %v", err)
+	}
+	if repo.events["event-1"].Name != "Concert" || len(repo.outbox) != 0 {
+		t.Fatalf("expected event untouched, got %+v", repo.events["event-1"])
+	}
+	if _, err := svc.CreateZone(ctx, CreateZoneInput{OrganizerID: "org-2", EventID: "event-1", Name: "Zone A", Capacity: 10}); err != domain.ErrEventNotFound {
+		t.Fatalf("expected ErrEventNotFound on zone create, got %v", err)
+	}
+	if _, err := svc.ListZones(ctx, "org-2", "event-1"); err != domain.ErrEventNotFound {
+		t.Fatalf("expected ErrEventNotFound on zone list, got %v", err)
+	}
+}
@@ -0,0 +1,194 @@
+package app
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cimillas/ultimate-ticket/services/api/internal/clock"
+	"github.com/cimillas/ultimate-ticket/services/api/internal/domain"
+)
+
+type APIKeyRepository interface {
+	WithTx(ctx context.Context, fn func(ctx context.Context) error) error
+	CreateAPIKey(ctx context.Context, key domain.APIKey) error
+	// GetAPIKeyByHash returns ErrInvalidAPIKey when no key has the hash.
+	GetAPIKeyByHash(ctx context.Context, keyHash string) (domain.APIKey, error)
+	ListAPIKeys(ctx context.Context, organizerID string) ([]domain.APIKey, error)
+	// GetAPIKeyForUpdate returns ErrAPIKeyNotFound for keys of other organizers.
+	GetAPIKeyForUpdate(ctx context.Context, organizerID, id string) (domain.APIKey, error)
+	// CountActiveAPIKeys counts unrevoked keys of all organizers. It locks the
+	// table so concurrent bootstraps cannot both see zero.
+	CountActiveAPIKeys(ctx context.Context) (int, error)
+	// RevokeAPIKey returns the key after revoking it; revoking twice keeps
+	// the first revocation time. Keys of other organizers are not found.
+	RevokeAPIKey(ctx context.Context, organizerID, id string, now time.Time) (domain.APIKey, error)
+	// TouchAPIKey records that the key was used at now.
+	TouchAPIKey(ctx context.Context, id string, now time.Time) error
+	AppendAuditEntry(ctx context.Context, entry domain.AuditEntry) error
+}
+
+// APIKeyService issues and checks API keys for the admin endpoints.
+type APIKeyService struct {
+	repo  APIKeyRepository
+	clock clock.Clock
+}
+
+const (
+	apiKeyPrefix      = "utk_"
+	apiKeySecretBytes = 24
+	// apiKeyDisplayLen is how much of a key is stored in clear to identify it.
+	apiKeyDisplayLen = len(apiKeyPrefix) + 8
+	// apiKeyTouchInterval limits last_used_at writes to one per key per interval.
+	apiKeyTouchInterval = time.Minute
+)
+
+func NewAPIKeyService(repo APIKeyRepository, clk clock.Clock) *APIKeyService {
+	return &APIKeyService{
+		repo:  repo,
+		clock: clk,
+	}
+}
+
+type CreateAPIKeyInput struct {
+	OrganizerID string
+	Name        string
+	Role        domain.Role
+}
+
+// CreatedAPIKey carries the plaintext secret, which is not stored and cannot
+// be recovered later.
+type CreatedAPIKey struct {
+	Key    domain.APIKey
+	Secret string
+}
+
+func (s *APIKeyService) CreateKey(ctx context.Context, in CreateAPIKeyInput) (CreatedAPIKey, error) {
+	name := strings.TrimSpace(in.Name)
+	if name == "" {
+		return CreatedAPIKey{}, domain.ErrAPIKeyNameRequired
+	}
+	if !in.Role.Valid() {
+		return CreatedAPIKey{}, domain.ErrInvalidRole
+	}
+	secret, err := newAPIKeySecret()
+	if err != nil {
+		return CreatedAPIKey{}, err
+	}
+
+	key := domain.APIKey{
+		ID:          newUUID(),
+		OrganizerID: in.OrganizerID,
+		Name:        name,
+		Prefix:      secret[:apiKeyDisplayLen],
+		KeyHash:     hashSecret(secret),
+		Role:        in.Role,
+		CreatedAt:   s.clock.Now(),
+	}
+	err = s.repo.WithTx(ctx, func(txCtx context.Context) error {
+		if err := s.repo.CreateAPIKey(txCtx, key); err != nil {
+			return err
+		}
+		return s.audit(txCtx, key.OrganizerID, domain.AuditAPIKeyCreated, key.ID, nil, newAPIKeySnapshot(key))
+	})
+	if err != nil {
+		return CreatedAPIKey{}, err
+	}
+	return CreatedAPIKey{Key: key, Secret: secret}, nil
+}
+
+// BootstrapOwnerKey creates the first owner key for the default organizer.
+// It fails with ErrAPIKeysExist once any active key exists, so it cannot be
+// used to mint more keys later.
+func (s *APIKeyService) BootstrapOwnerKey(ctx context.Context, name string) (CreatedAPIKey, error) {
+	var created CreatedAPIKey
+	err := s.repo.WithTx(ctx, func(txCtx context.Context) error {
+		n, err := s.repo.CountActiveAPIKeys(txCtx)
+		if err != nil {
+			return err
+		}
+		if n > 0 {
+			return domain.ErrAPIKeysExist
+		}
+		created, err = s.CreateKey(txCtx, CreateAPIKeyInput{
+			OrganizerID: domain.DefaultOrganizerID,
+			Name:        name,
+			Role:        domain.RoleOwner,
+		})
+		return err
+	})
+	if err != nil {
+		return CreatedAPIKey{}, err
+	}
+	return created, nil
+}
+
+// Authenticate returns the active key for a secret.
+func (s *APIKeyService) Authenticate(ctx context.Context, secret string) (domain.APIKey, error) {
+	if !strings.HasPrefix(secret, apiKeyPrefix) {
+		return domain.APIKey{}, domain.ErrInvalidAPIKey
+	}
+	key, err := s.repo.GetAPIKeyByHash(ctx, hashSecret(secret))
+	if err != nil {
+		return domain.APIKey{}, err
+	}
+	if key.RevokedAt != nil {
+		return domain.APIKey{}, domain.ErrInvalidAPIKey
+	}
+
+	now := s.clock.Now()
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= apiKeyTouchInterval {
+		if err := s.repo.TouchAPIKey(ctx, key.ID, now); err != nil {
+			return domain.APIKey{}, err
+		}
+		key.LastUsedAt = &now
+	}
+	return key, nil
+}
+
+func (s *APIKeyService) ListKeys(ctx context.Context, organizerID string) ([]domain.APIKey, error) {
+	return s.repo.ListAPIKeys(ctx, organizerID)
+}
+
+func (s *APIKeyService) RevokeKey(ctx context.Context, organizerID, id string) (domain.APIKey, error) {
+	var revoked domain.APIKey
+	err := s.repo.WithTx(ctx, func(txCtx context.Context) error {
+		current, err := s.repo.GetAPIKeyForUpdate(txCtx, organizerID, id)
+		if err != nil {
+			return err
+		}
+		// Revoking an already revoked key changes nothing and is not audited.
+		if current.RevokedAt != nil {
+			revoked = current
+			return nil
+		}
+		revoked, err = s.repo.RevokeAPIKey(txCtx, organizerID, id, s.clock.Now())
+		if err != nil {
+			return err
+		}
+		return s.audit(txCtx, organizerID, domain.AuditAPIKeyRevoked, revoked.ID, newAPIKeySnapshot(current), newAPIKeySnapshot(revoked))
+	})
+	if err != nil {
+		return domain.APIKey{}, err
+	}
+	return revoked, nil
+}
+
+func (s *APIKeyService) audit(ctx context.Context, organizerID string, action domain.AuditAction, targetID string, before, after any) error {
+	entry, err := newAuditEntry(ctx, organizerID, action, domain.AuditTargetAPIKey, targetID, before, after, s.clock.Now())
+	if err != nil {
+		return err
+	}
+	return s.repo.AppendAuditEntry(ctx, entry)
+}
+
+func newAPIKeySecret() (string, error) {
+	b := make([]byte, apiKeySecretBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generate api key: %w", err)
+	}
+	return apiKeyPrefix + base64.RawURLEncoding.EncodeToString(b), nil
+}
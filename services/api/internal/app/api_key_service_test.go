@@ -0,0 +1,188 @@
+package app
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cimillas/ultimate-ticket/services/api/internal/clock"
+	"github.com/cimillas/ultimate-ticket/services/api/internal/domain"
+)
+
+func TestAPIKeyService(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, 1, 2, 10, 0, 0, 0, time.UTC)
+
+	t.Run("created key authenticates until revoked", func(t *testing.T) {
+		repo := newFakeAPIKeyRepo()
+		svc := NewAPIKeyService(repo, clock.NewFixed(now))
+
+		created, err := svc.CreateKey(context.Background(), CreateAPIKeyInput{OrganizerID: "org-1", Name: " box office ", Role: domain.RoleBoxOffice})
+		if err != nil {
+			t.Fatalf("create: %v", err)
+		}
+		if !strings.HasPrefix(created.Secret, apiKeyPrefix) || !strings.HasPrefix(created.Secret, created.Key.Prefix) {
+			t.Fatalf("unexpected secret %q with prefix %q", created.Secret, created.Key.Prefix)
+		}
+		if created.Key.Name != "box office" || created.Key.KeyHash == created.Secret {
+			t.Fatalf("unexpected key %+v", created.Key)
+		}
+
+		key, err := svc.Authenticate(context.Background(), created.Secret)
+		if err != nil {
+			t.Fatalf("authenticate: %v", err)
+		}
+		if key.ID != created.Key.ID || key.Role != domain.RoleBoxOffice || key.OrganizerID != "org-1" {
+			t.Fatalf("unexpected key %+v", key)
+		}
+		if repo.touches != 1 {
+			t.Fatalf("expected last use recorded once, got %d", repo.touches)
+		}
+		if _, err := svc.Authenticate(context.Background(), created.Secret); err != nil {
+			t.Fatalf("authenticate again: %v", err)
+		}
+		if repo.touches != 1 {
+			t.Fatalf("expected last use throttled, got %d writes", repo.touches)
+		}
+
+		if _, err := svc.RevokeKey(context.Background(), "org-2", created.Key.ID); err != domain.ErrAPIKeyNotFound {
+			t.Fatalf("expected another organizer's revoke to fail with ErrAPIKeyNotFound, got %v", err)
+		}
+		if _, err := svc.RevokeKey(context.Background(), "org-1", created.Key.ID); err != nil {
+			t.Fatalf("revoke: %v", err)
+		}
+		if _, err := svc.Authenticate(context.Background(), created.Secret); err != domain.ErrInvalidAPIKey {
+			t.Fatalf("expected ErrInvalidAPIKey after revoke, got %v", err)
+		}
+	})
+
+	t.Run("rejects unknown secrets", func(t *testing.T) {
+		svc := NewAPIKeyService(newFakeAPIKeyRepo(), clock.NewFixed(now))
+
+		for _, secret := range []string{"", "nope", apiKeyPrefix + "unknown"} {
+			if _, err := svc.Authenticate(context.Background(), secret); err != domain.ErrInvalidAPIKey {
+				t.Fatalf("%q: expected ErrInvalidAPIKey, got %v", secret, err)
+			}
+		}
+	})
+
+	t.Run("validates input", func(t *testing.T) {
+		svc := NewAPIKeyService(newFakeAPIKeyRepo(), clock.NewFixed(now))
+
+		if _, err := svc.CreateKey(context.Background(), CreateAPIKeyInput{Role: domain.RoleOwner}); err != domain.ErrAPIKeyNameRequired {
+			t.Fatalf("expected ErrAPIKeyNameRequired, got %v", err)
+		}
+		if _, err := svc.CreateKey(context.Background(), CreateAPIKeyInput{Name: "ci", Role: "admin"}); err != domain.ErrInvalidRole {
+			t.Fatalf("expected ErrInvalidRole, got %v", err)
+		}
+	})
+
+	t.Run("bootstrap only works without active keys", func(t *testing.T) {
+		repo := newFakeAPIKeyRepo()
+		svc := NewAPIKeyService(repo, clock.NewFixed(now))
+
+		created, err := svc.BootstrapOwnerKey(context.Background(), "bootstrap")
+		if err != nil {
+			t.Fatalf("bootstrap: %v", err)
+		}
+		if created.Key.Role != domain.RoleOwner || created.Key.OrganizerID != domain.DefaultOrganizerID {
+			t.Fatalf("expected owner key of the default organizer, got %+v", created.Key)
+		}
+		if _, err := svc.BootstrapOwnerKey(context.Background(), "again"); err != domain.ErrAPIKeysExist {
+			t.Fatalf("expected ErrAPIKeysExist, got %v", err)
+		}
+
+		if _, err := svc.RevokeKey(context.Background(), domain.DefaultOrganizerID, created.Key.ID); err != nil {
+			t.Fatalf("revoke: %v", err)
+		}
+		if _, err := svc.BootstrapOwnerKey(context.Background(), "recovery"); err != nil {
+			t.Fatalf("expected bootstrap after revoking all keys, got %v", err)
+		}
+	})
+}
+
+type fakeAPIKeyRepo struct {
+	keys    []domain.APIKey
+	touches int
+	audit   []domain.AuditEntry
+}
+
+func newFakeAPIKeyRepo() *fakeAPIKeyRepo {
+	return &fakeAPIKeyRepo{}
+}
+
+func (f *fakeAPIKeyRepo) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+func (f *fakeAPIKeyRepo) AppendAuditEntry(_ context.Context, entry domain.AuditEntry) error {
+	f.audit = append(f.audit, entry)
+	return nil
+}
+
+func (f *fakeAPIKeyRepo) CreateAPIKey(_ context.Context, key domain.APIKey) error {
+	f.keys = append(f.keys, key)
+	return nil
+}
+
+func (f *fakeAPIKeyRepo) GetAPIKeyByHash(_ context.Context, keyHash string) (domain.APIKey, error) {
+	for _, k := range f.keys {
+		if k.KeyHash == keyHash {
+			return k, nil
+		}
+	}
+	return domain.APIKey{}, domain.ErrInvalidAPIKey
+}
+
+func (f *fakeAPIKeyRepo) ListAPIKeys(_ context.Context, organizerID string) ([]domain.APIKey, error) {
+	var out []domain.APIKey
+	for _, k := range f.keys {
+		if k.OrganizerID == organizerID {
+			out = append(out, k)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeAPIKeyRepo) CountActiveAPIKeys(_ context.Context) (int, error) {
+	n := 0
+	for _, k := range f.keys {
+		if k.RevokedAt == nil {
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (f *fakeAPIKeyRepo) GetAPIKeyForUpdate(_ context.Context, organizerID, id string) (domain.APIKey, error) {
+	for _, k := range f.keys {
+		if k.ID == id && k.OrganizerID == organizerID {
+			return k, nil
+		}
+	}
+	return domain.APIKey{}, domain.ErrAPIKeyNotFound
+}
+
+func (f *fakeAPIKeyRepo) RevokeAPIKey(_ context.Context, organizerID, id string, now time.Time) (domain.APIKey, error) {
+	for i := range f.keys {
+		if f.keys[i].ID == id && f.keys[i].OrganizerID == organizerID {
+			if f.keys[i].RevokedAt == nil {
+				f.keys[i].RevokedAt = &now
+			}
+			return f.keys[i], nil
+		}
+	}
+	return domain.APIKey{}, domain.ErrAPIKeyNotFound
+}
+
+func (f *fakeAPIKeyRepo) TouchAPIKey(_ context.Context, id string, now time.Time) error {
+	f.touches++
+	for i := range f.keys {
+		if f.keys[i].ID == id {
+			f.keys[i].LastUsedAt = &now
+		}
+	}
+	return nil
+}
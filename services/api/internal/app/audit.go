@@ -0,0 +1,286 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/cimillas/ultimate-ticket/services/api/internal/domain"
+)
+
+const (
+	defaultAuditLimit = 100
+	maxAuditLimit     = 500
+)
+
+// AuditContext identifies who is making a change and the request it came
+// from. Transports attach it to the context; services copy it into every
+// audit entry they write.
+type AuditContext struct {
+	Actor     string
+	RequestID string
+}
+
+type auditContextKey struct{}
+
+// WithAuditContext returns a context whose changes are attributed to ac.
+func WithAuditContext(ctx context.Context, ac AuditContext) context.Context {
+	return context.WithValue(ctx, auditContextKey{}, ac)
+}
+
+// AuditContextFrom returns the caller attached to ctx. Changes without one,
+// such as those made from the command line, are attributed to the system.
+func AuditContextFrom(ctx context.Context) AuditContext {
+	ac, _ := ctx.Value(auditContextKey{}).(AuditContext)
+	if ac.Actor == "" {
+		ac.Actor = domain.AuditActorSystem
+	}
+	return ac
+}
+
+// newAuditEntry snapshots before and after as JSON; pass nil for a side
+// where the target does not exist.
+func newAuditEntry(ctx context.Context, organizerID string, action domain.AuditAction, targetType, targetID string, before, after any, now time.Time) (domain.AuditEntry, error) {
+	ac := AuditContextFrom(ctx)
+	entry := domain.AuditEntry{
+		ID:          newUUID(),
+		OrganizerID: organizerID,
+		Actor:       ac.Actor,
+		Action:      action,
+		TargetType:  targetType,
+		TargetID:    targetID,
+		RequestID:   ac.RequestID,
+		CreatedAt:   now,
+	}
+	var err error
+	if before != nil {
+		if entry.Before, err = json.Marshal(before); err != nil {
+			return domain.AuditEntry{}, fmt.Errorf("encode %s audit snapshot: %w", action, err)
+		}
+	}
+	if after != nil {
+		if entry.After, err = json.Marshal(after); err != nil {
+			return domain.AuditEntry{}, fmt.Errorf("encode %s audit snapshot: %w", action, err)
+		}
+	}
+	return entry, nil
+}
+
+// Audit snapshots keep the fields an operator needs to see what changed and
+// leave out secrets and rendered bodies.
+
+type eventSnapshot struct {
+	ID                 string    `json:"id"`
+	Name               string    `json:"name"`
+	StartsAt           time.Time `json:"starts_at"`
+	WaitingRoomEnabled bool      `json:"waiting_room_enabled"`
+	AdmitPerMinute     int       `json:"admit_per_minute"`
+	PurchaseLimit      int       `json:"purchase_limit"`
+}
+
+func newEventSnapshot(e domain.Event) eventSnapshot {
+	return eventSnapshot{
+		ID:                 e.ID,
+		Name:               e.Name,
+		StartsAt:           e.StartsAt,
+		WaitingRoomEnabled: e.WaitingRoom.Enabled,
+		AdmitPerMinute:     e.WaitingRoom.AdmitPerMinute,
+		PurchaseLimit:      e.PurchaseLimit,
+	}
+}
+
+type zoneSnapshot struct {
+	ID            string `json:"id"`
+	EventID       string `json:"event_id"`
+	Name          string `json:"name"`
+	Capacity      int    `json:"capacity"`
+	MinQuantity   int    `json:"min_quantity"`
+	MaxQuantity   int    `json:"max_quantity"`
+	QuantityStep  int    `json:"quantity_step"`
+	PurchaseLimit int    `json:"purchase_limit"`
+}
+
+func newZoneSnapshot(z domain.Zone) zoneSnapshot {
+	return zoneSnapshot{
+		ID:            z.ID,
+		EventID:       z.EventID,
+		Name:          z.Name,
+		Capacity:      z.Capacity,
+		MinQuantity:   z.MinQuantity,
+		MaxQuantity:   z.MaxQuantity,
+		QuantityStep:  z.QuantityStep,
+		PurchaseLimit: z.PurchaseLimit,
+	}
+}
+
+type webhookSnapshot struct {
+	ID         string   `json:"id"`
+	URL        string   `json:"url"`
+	EventTypes []string `json:"event_types"`
+	Active     bool     `json:"active"`
+}
+
+func newWebhookSnapshot(s domain.WebhookSubscription) webhookSnapshot {
+	types := make([]string, 0, len(s.EventTypes))
+	for _, t := range s.EventTypes {
+		types = append(types, string(t))
+	}
+	return webhookSnapshot{ID: s.ID, URL: s.URL, EventTypes: types, Active: s.Active}
+}
+
+type deliverySnapshot struct {
+	ID             string    `json:"id"`
+	SubscriptionID string    `json:"subscription_id"`
+	EventID        string    `json:"event_id"`
+	Status         string    `json:"status"`
+	Attempts       int       `json:"attempts"`
+	NextAttemptAt  time.Time `json:"next_attempt_at"`
+}
+
+func newDeliverySnapshot(d domain.WebhookDelivery) deliverySnapshot {
+	return deliverySnapshot{
+		ID:             d.ID,
+		SubscriptionID: d.SubscriptionID,
+		EventID:        d.EventID,
+		Status:         string(d.Status),
+		Attempts:       d.Attempts,
+		NextAttemptAt:  d.NextAttemptAt,
+	}
+}
+
+type notificationSnapshot struct {
+	ID            string    `json:"id"`
+	Kind          string    `json:"kind"`
+	Recipient     string    `json:"recipient"`
+	Status        string    `json:"status"`
+	Attempts      int       `json:"attempts"`
+	NextAttemptAt time.Time `json:"next_attempt_at"`
+}
+
+func newNotificationSnapshot(n domain.Notification) notificationSnapshot {
+	return notificationSnapshot{
+		ID:            n.ID,
+		Kind:          string(n.Kind),
+		Recipient:     n.Recipient,
+		Status:        string(n.Status),
+		Attempts:      n.Attempts,
+		NextAttemptAt: n.NextAttemptAt,
+	}
+}
+
+type apiKeySnapshot struct {
+	ID        string     `json:"id"`
+	Name      string     `json:"name"`
+	Prefix    string     `json:"prefix"`
+	Role      string     `json:"role"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+}
+
+func newAPIKeySnapshot(k domain.APIKey) apiKeySnapshot {
+	return apiKeySnapshot{ID: k.ID, Name: k.Name, Prefix: k.Prefix, Role: string(k.Role), RevokedAt: k.RevokedAt}
+}
+
+type lotterySnapshot struct {
+	ID                   string     `json:"id"`
+	ZoneID               string     `json:"zone_id"`
+	RegistrationOpensAt  time.Time  `json:"registration_opens_at"`
+	RegistrationClosesAt time.Time  `json:"registration_closes_at"`
+	ClaimWindowSeconds   int        `json:"claim_window_seconds"`
+	Status               string     `json:"status"`
+	SeedHash             string     `json:"seed_hash"`
+	Seed                 string     `json:"seed,omitempty"`
+	DrawnAt              *time.Time `json:"drawn_at,omitempty"`
+}
+
+func newLotterySnapshot(l domain.Lottery) lotterySnapshot {
+	return lotterySnapshot{
+		ID:                   l.ID,
+		ZoneID:               l.ZoneID,
+		RegistrationOpensAt:  l.RegistrationOpensAt,
+		RegistrationClosesAt: l.RegistrationClosesAt,
+		ClaimWindowSeconds:   int(l.ClaimWindow / time.Second),
+		Status:               string(l.Status),
+		SeedHash:             l.SeedHash,
+		Seed:                 l.PublishedSeed(),
+		DrawnAt:              l.DrawnAt,
+	}
+}
+
+type riskRulesSnapshot struct {
+	EventID             string   `json:"event_id"`
+	Enabled             bool     `json:"enabled"`
+	ChallengeScore      int      `json:"challenge_score"`
+	BlockScore          int      `json:"block_score"`
+	MaxHoldsPerIP       int      `json:"max_holds_per_ip"`
+	MaxHoldsPerCustomer int      `json:"max_holds_per_customer"`
+	MaxHoldsPerZone     int      `json:"max_holds_per_zone"`
+	BlockedUserAgents   []string `json:"blocked_user_agents"`
+}
+
+func newRiskRulesSnapshot(eventID string, r domain.RiskRules) riskRulesSnapshot {
+	return riskRulesSnapshot{
+		EventID:             eventID,
+		Enabled:             r.Enabled,
+		ChallengeScore:      r.ChallengeScore,
+		BlockScore:          r.BlockScore,
+		MaxHoldsPerIP:       r.MaxHoldsPerIP,
+		MaxHoldsPerCustomer: r.MaxHoldsPerCustomer,
+		MaxHoldsPerZone:     r.MaxHoldsPerZone,
+		BlockedUserAgents:   r.BlockedUserAgents,
+	}
+}
+
+type organizerSnapshot struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type AuditRepository interface {
+	// ListAuditEntries returns matching entries, newest first.
+	ListAuditEntries(ctx context.Context, filter domain.AuditFilter) ([]domain.AuditEntry, error)
+}
+
+// AuditService reads the audit log. Entries are written by the services
+// making the changes, in the same transaction.
+type AuditService struct {
+	repo AuditRepository
+}
+
+func NewAuditService(repo AuditRepository) *AuditService {
+	return &AuditService{repo: repo}
+}
+
+// ListEntries returns an organizer's audit entries, newest first. A zero
+// limit means the default; larger limits are capped.
+func (s *AuditService) ListEntries(ctx context.Context, filter domain.AuditFilter) ([]domain.AuditEntry, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = defaultAuditLimit
+	}
+	if filter.Limit > maxAuditLimit {
+		filter.Limit = maxAuditLimit
+	}
+	return s.repo.ListAuditEntries(ctx, filter)
+}
+
+type orderSnapshot struct {
+	ID               string     `json:"id"`
+	HoldID           string     `json:"hold_id"`
+	Status           string     `json:"status"`
+	PaymentReference string     `json:"payment_reference,omitempty"`
+	PaidAt           *time.Time `json:"paid_at,omitempty"`
+	FailedAt         *time.Time `json:"failed_at,omitempty"`
+	CancelledAt      *time.Time `json:"cancelled_at,omitempty"`
+}
+
+func newOrderSnapshot(o domain.Order) orderSnapshot {
+	return orderSnapshot{
+		ID:               o.ID,
+		HoldID:           o.HoldID,
+		Status:           string(o.Status),
+		PaymentReference: o.PaymentReference,
+		PaidAt:           o.PaidAt,
+		FailedAt:         o.FailedAt,
+		CancelledAt:      o.CancelledAt,
+	}
+}
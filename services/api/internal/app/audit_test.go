@@ -0,0 +1,199 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/cimillas/ultimate-ticket/services/api/internal/clock"
+	"github.com/cimillas/ultimate-ticket/services/api/internal/domain"
+)
+
+func TestAdminService_AuditsChanges(t *testing.T) {
+	startsAt := time.Date(2025, 6, 1, 20, 0, 0, 0, time.UTC)
+	repo := &fakeAdminRepo{events: map[string]domain.Event{
+		"event-1": {ID: "event-1", OrganizerID: "org-1", Name: "Concert", StartsAt: startsAt},
+	}}
+	now := time.Date(2025, 1, 5, 10, 0, 0, 0, time.UTC)
+	svc := NewAdminService(repo, clock.NewFixed(now))
+	ctx := WithAuditContext(context.Background(), AuditContext{Actor: "api_key:key-1", RequestID: "req-1"})
+
+	created, err := svc.CreateEvent(ctx, CreateEventInput{OrganizerID: "org-1", Name: "Festival"})
+	if err != nil {
+		t.Fatalf("create event: %v", err)
+	}
+	sameName := "Concert"
+	if _, err := svc.UpdateEvent(ctx, UpdateEventInput{OrganizerID: "org-1", EventID: "event-1", Name: &sameName}); err != nil {
+		t.Fatalf("update event: %v", err)
+	}
+	renamed := "Concert (late show)"
+	if _, err := svc.UpdateEvent(ctx, UpdateEventInput{OrganizerID: "org-1", EventID: "event-1", Name: &renamed}); err != nil {
+		t.Fatalf("update event: %v", err)
+	}
+	zone, err := svc.CreateZone(ctx, CreateZoneInput{OrganizerID: "org-1", EventID: "event-1", Name: "Floor", Capacity: 100})
+	if err != nil {
+		t.Fatalf("create zone: %v", err)
+	}
+	if _, err := svc.CreateZone(ctx, CreateZoneInput{OrganizerID: "org-2", EventID: "event-1", Name: "Stolen", Capacity: 1}); err != domain.ErrEventNotFound {
+		t.Fatalf("expected ErrEventNotFound, got %v", err)
+	}
+
+	want := []struct {
+		action   domain.AuditAction
+		targetID string
+	}{
+		{domain.AuditEventCreated, created.ID},
+		{domain.AuditEventUpdated, "event-1"},
+		{domain.AuditZoneCreated, zone.ID},
+	}
+	if len(repo.audit) != len(want) {
+		t.Fatalf("expected %d audit entries, got %+v", len(want), repo.audit)
+	}
+	for i, w := range want {
+		got := repo.audit[i]
+		if got.Action != w.action || got.TargetID != w.targetID {
+			t.Fatalf("entry %d: expected %s on %s, got %s on %s", i, w.action, w.targetID, got.Action, got.TargetID)
+		}
+		if got.OrganizerID != "org-1" || got.Actor != "api_key:key-1" || got.RequestID != "req-1" || !got.CreatedAt.Equal(now) {
+			t.Fatalf("entry %d: unexpected attribution %+v", i, got)
+		}
+	}
+	if repo.audit[0].Before != nil {
+		t.Fatalf("expected no before snapshot on create, got %s", repo.audit[0].Before)
+	}
+
+	var before, after eventSnapshot
+	if err := json.Unmarshal(repo.audit[1].Before, &before); err != nil {
+		t.Fatalf("decode before: %v", err)
+	}
+	if err := json.Unmarshal(repo.audit[1].After, &after); err != nil {
+		t.Fatalf("decode after: %v", err)
+	}
+	if before.Name != "Concert" || after.Name != renamed || !after.StartsAt.Equal(startsAt) {
+		t.Fatalf("unexpected snapshots before=%+v after=%+v", before, after)
+	}
+}
+
+func TestOverrideOperationsAreAudited(t *testing.T) {
+	now := time.Date(2025, 1, 5, 10, 0, 0, 0, time.UTC)
+	clk := clock.NewFixed(now)
+	ctx := WithAuditContext(context.Background(), AuditContext{Actor: "api_key:key-1", RequestID: "req-1"})
+
+	t.Run("webhook replay and delete", func(t *testing.T) {
+		repo := newFakeWebhookRepo()
+		repo.subs = []domain.WebhookSubscription{{ID: "sub-1", OrganizerID: "org-1", URL: "https://example.com/hook", Secret: "s", Active: true}}
+		repo.deliveries = []domain.WebhookDelivery{{ID: "del-1", OrganizerID: "org-1", SubscriptionID: "sub-1", Status: domain.WebhookDeliveryDead, Attempts: 8}}
+		svc := NewWebhookService(repo, nil, clk)
+
+		if _, err := svc.ReplayDelivery(ctx, "org-1", "del-1"); err != nil {
+			t.Fatalf("replay: %v", err)
+		}
+		if err := svc.DeleteSubscription(ctx, "org-1", "sub-1"); err != nil {
+			t.Fatalf("delete: %v", err)
+		}
+		if len(repo.audit) != 2 || repo.audit[0].Action != domain.AuditDeliveryReplayed || repo.audit[1].Action != domain.AuditWebhookDeleted {
+			t.Fatalf("unexpected audit entries %+v", repo.audit)
+		}
+		var before, after deliverySnapshot
+		_ = json.Unmarshal(repo.audit[0].Before, &before)
+		_ = json.Unmarshal(repo.audit[0].After, &after)
+		if before.Status != "dead" || before.Attempts != 8 || after.Status != "pending" || after.Attempts != 0 {
+			t.Fatalf("unexpected replay snapshots before=%+v after=%+v", before, after)
+		}
+		var sub webhookSnapshot
+		_ = json.Unmarshal(repo.audit[1].After, &sub)
+		if sub.Active || sub.URL != "https://example.com/hook" {
+			t.Fatalf("unexpected delete snapshot %+v", sub)
+		}
+	})
+
+	t.Run("notification retry", func(t *testing.T) {
+		repo := &fakeNotificationRepo{notifications: []domain.Notification{
+			{ID: "n-1", OrganizerID: "org-1", Status: domain.NotificationFailed, Attempts: 6},
+		}}
+		svc := NewNotificationService(repo, nil, nil, clk)
+
+		if _, err := svc.RetryNotification(ctx, "org-2", "n-1"); err != domain.ErrNotificationNotFound {
+			t.Fatalf("expected ErrNotificationNotFound, got %v", err)
+		}
+		if _, err := svc.RetryNotification(ctx, "org-1", "n-1"); err != nil {
+			t.Fatalf("retry: %v", err)
+		}
+		if len(repo.audit) != 1 || repo.audit[0].Action != domain.AuditNotificationRetried || repo.audit[0].TargetID != "n-1" {
+			t.Fatalf("unexpected audit entries %+v", repo.audit)
+		}
+	})
+
+	t.Run("api key revoke is audited once", func(t *testing.T) {
+		repo := newFakeAPIKeyRepo()
+		svc := NewAPIKeyService(repo, clk)
+		created, err := svc.CreateKey(ctx, CreateAPIKeyInput{OrganizerID: "org-1", Name: "ops", Role: domain.RoleReadOnly})
+		if err != nil {
+			t.Fatalf("create key: %v", err)
+		}
+		for i := 0; i < 2; i++ {
+			if _, err := svc.RevokeKey(ctx, "org-1", created.Key.ID); err != nil {
+				t.Fatalf("revoke key: %v", err)
+			}
+		}
+		if len(repo.audit) != 2 || repo.audit[0].Action != domain.AuditAPIKeyCreated || repo.audit[1].Action != domain.AuditAPIKeyRevoked {
+			t.Fatalf("unexpected audit entries %+v", repo.audit)
+		}
+		for _, entry := range repo.audit {
+			var snap map[string]any
+			_ = json.Unmarshal(entry.After, &snap)
+			if _, leaked := snap["key_hash"]; leaked {
+				t.Fatalf("expected key hash to stay out of the audit log, got %s", entry.After)
+			}
+		}
+	})
+}
+
+func TestAuditContext_DefaultsToSystem(t *testing.T) {
+	repo := newFakeAPIKeyRepo()
+	svc := NewAPIKeyService(repo, clock.NewFixed(time.Now()))
+
+	if _, err := svc.BootstrapOwnerKey(context.Background(), "first"); err != nil {
+		t.Fatalf("bootstrap: %v", err)
+	}
+	if len(repo.audit) != 1 || repo.audit[0].Actor != domain.AuditActorSystem || repo.audit[0].RequestID != "" {
+		t.Fatalf("expected a system audit entry, got %+v", repo.audit)
+	}
+}
+
+func TestAuditService_ListEntries_Limit(t *testing.T) {
+	tests := []struct {
+		name  string
+		limit int
+		want  int
+	}{
+		{name: "default", limit: 0, want: defaultAuditLimit},
+		{name: "explicit", limit: 20, want: 20},
+		{name: "capped", limit: 10000, want: maxAuditLimit},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			repo := &fakeAuditRepo{}
+			svc := NewAuditService(repo)
+
+			if _, err := svc.ListEntries(context.Background(), domain.AuditFilter{OrganizerID: "org-1", Limit: tt.limit}); err != nil {
+				t.Fatalf("list: %v", err)
+			}
+			if repo.filter.Limit != tt.want || repo.filter.OrganizerID != "org-1" {
+				t.Fatalf("expected limit %d, got %+v", tt.want, repo.filter)
+			}
+		})
+	}
+}
+
+type fakeAuditRepo struct {
+	filter domain.AuditFilter
+}
+
+func (f *fakeAuditRepo) ListAuditEntries(_ context.Context, filter domain.AuditFilter) ([]domain.AuditEntry, error) {
+	f.filter = filter
+	return nil, nil
+}
@@ -0,0 +1,317 @@
+package app
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/cimillas/ultimate-ticket/services/api/internal/clock"
+	"github.com/cimillas/ultimate-ticket/services/api/internal/domain"
+)
+
+type AuthRepository interface {
+	WithTx(ctx context.Context, fn func(ctx context.Context) error) error
+	// ConsumeLoginCodes marks every unused code for email as consumed.
+	ConsumeLoginCodes(ctx context.Context, email string, now time.Time) error
+	CreateLoginCode(ctx context.Context, code domain.LoginCode) error
+	// CountLoginCodes returns how many codes were created after since for
+	// email and for clientIP; an empty clientIP counts none.
+	CountLoginCodes(ctx context.Context, email, clientIP string, since time.Time) (byEmail, byIP int, err error)
+	// CountFailedLoginAttempts sums the wrong guesses against codes for email
+	// created after since.
+	CountFailedLoginAttempts(ctx context.Context, email string, since time.Time) (int, error)
+	// GetLatestLoginCodeForUpdate returns ErrInvalidLoginCode when email has no code.
+	GetLatestLoginCodeForUpdate(ctx context.Context, email string) (domain.LoginCode, error)
+	UpdateLoginCode(ctx context.Context, code domain.LoginCode) error
+	CreateSession(ctx context.Context, session domain.Session) error
+	// GetSession returns ErrInvalidSession when no session has the hash.
+	GetSession(ctx context.Context, tokenHash string) (domain.Session, error)
+	RevokeSession(ctx context.Context, tokenHash string, now time.Time) error
+}
+
+// AuthService signs customers in without passwords: a short-lived,
+// single-use code is emailed to them and exchanged for an opaque session
+// token. Codes and tokens are stored hashed.
+type AuthService struct {
+	repo        AuthRepository
+	customers   *CustomerService
+	mailer      Mailer
+	clock       clock.Clock
+	codeTTL     time.Duration
+	sessionTTL  time.Duration
+	maxAttempts int
+	limits      LoginLimits
+}
+
+// LoginLimits throttle sign-in per address and per client across codes, so
+// requesting a fresh code does not also reset the number of guesses.
+type LoginLimits struct {
+	// Window is how far back requests and guesses are counted.
+	Window time.Duration
+	// CodesPerEmail caps the codes emailed to one address in Window.
+	CodesPerEmail int
+	// CodesPerIP caps the codes one client IP can request in Window.
+	CodesPerIP int
+	// FailedAttempts caps the wrong guesses for one address in Window.
+	FailedAttempts int
+}
+
+const (
+	defaultLoginCodeTTL      = 10 * time.Minute
+	defaultSessionTTL        = 30 * 24 * time.Hour
+	defaultLoginCodeAttempts = 5
+	defaultLoginWindow       = time.Hour
+	defaultLoginCodesPerMail = 5
+	defaultLoginCodesPerIP   = 20
+	defaultLoginFailures     = 10
+	loginCodeDigits          = 6
+	sessionTokenBytes        = 32
+)
+
+func NewAuthService(repo AuthRepository, customers *CustomerService, mailer Mailer, clk clock.Clock, opts ...AuthServiceOption) *AuthService {
+	svc := &AuthService{
+		repo:        repo,
+		customers:   customers,
+		mailer:      mailer,
+		clock:       clk,
+		codeTTL:     defaultLoginCodeTTL,
+		sessionTTL:  defaultSessionTTL,
+		maxAttempts: defaultLoginCodeAttempts,
+		limits: LoginLimits{
+			Window:         defaultLoginWindow,
+			CodesPerEmail:  defaultLoginCodesPerMail,
+			CodesPerIP:     defaultLoginCodesPerIP,
+			FailedAttempts: defaultLoginFailures,
+		},
+	}
+	for _, opt := range opts {
+		opt(svc)
+	}
+	return svc
+}
+
+type AuthServiceOption func(*AuthService)
+
+// WithLoginCodeTTL sets how long an emailed code stays valid.
+func WithLoginCodeTTL(d time.Duration) AuthServiceOption {
+	return func(s *AuthService) {
+		if d > 0 {
+			s.codeTTL = d
+		}
+	}
+}
+
+// WithSessionTTL sets how long a session token stays valid.
+func WithSessionTTL(d time.Duration) AuthServiceOption {
+	return func(s *AuthService) {
+		if d > 0 {
+			s.sessionTTL = d
+		}
+	}
+}
+
+// WithLoginLimits overrides the sign-in throttles; zero fields keep their
+// defaults.
+func WithLoginLimits(l LoginLimits) AuthServiceOption {
+	return func(s *AuthService) {
+		if l.Window > 0 {
+			s.limits.Window = l.Window
+		}
+		if l.CodesPerEmail > 0 {
+			s.limits.CodesPerEmail = l.CodesPerEmail
+		}
+		if l.CodesPerIP > 0 {
+			s.limits.CodesPerIP = l.CodesPerIP
+		}
+		if l.FailedAttempts > 0 {
+			s.limits.FailedAttempts = l.FailedAttempts
+		}
+	}
+}
+
+// RequestLoginCode emails a new code to the address and invalidates any
+// earlier one. Unknown addresses are handled the same as known ones; the
+// account is only created once the code is verified. It returns
+// ErrLoginThrottled once the address or the client IP has asked for too
+// many codes recently.
+func (s *AuthService) RequestLoginCode(ctx context.Context, email, clientIP string) error {
+	email, err := normalizeEmail(email)
+	if err != nil {
+		return err
+	}
+	code, err := newLoginCode()
+	if err != nil {
+		return err
+	}
+
+	now := s.clock.Now()
+	lc := domain.LoginCode{
+		ID:        newUUID(),
+		Email:     email,
+		ClientIP:  clientIP,
+		ExpiresAt: now.Add(s.codeTTL),
+		CreatedAt: now,
+	}
+	lc.CodeHash = hashLoginCode(lc.ID, code)
+
+	err = s.repo.WithTx(ctx, func(txCtx context.Context) error {
+		byEmail, byIP, err := s.repo.CountLoginCodes(txCtx, email, clientIP, now.Add(-s.limits.Window))
+		if err != nil {
+			return err
+		}
+		if byEmail >= s.limits.CodesPerEmail || byIP >= s.limits.CodesPerIP {
+			return domain.ErrLoginThrottled
+		}
+		if err := s.repo.ConsumeLoginCodes(txCtx, email, now); err != nil {
+			return err
+		}
+		return s.repo.CreateLoginCode(txCtx, lc)
+	})
+	if err != nil {
+		return err
+	}
+
+	minutes := int(s.codeTTL / time.Minute)
+	return s.mailer.Send(ctx, MailMessage{
+		To:      email,
+		Subject: "Your sign-in code",
+		Text:    fmt.Sprintf("Your sign-in code is %s. It expires in %d minutes.\n\nIf you did not request it, you can ignore this email.\n", code, minutes),
+		HTML:    fmt.Sprintf("<p>Your sign-in code is <strong>%s</strong>. It expires in %d minutes.</p><p>If you did not request it, you can ignore this email.</p>", code, minutes),
+	})
+}
+
+type LoginResult struct {
+	Customer  domain.Customer
+	Token     string
+	ExpiresAt time.Time
+}
+
+// VerifyLoginCode exchanges a code for a session token, creating the
+// customer on first sign-in. Each code can be used once; a code is burned
+// after too many wrong guesses, and the address is locked out with
+// ErrLoginThrottled after too many across codes.
+func (s *AuthService) VerifyLoginCode(ctx context.Context, email, code string) (LoginResult, error) {
+	email, err := normalizeEmail(email)
+	if err != nil {
+		return LoginResult{}, domain.ErrInvalidLoginCode
+	}
+	token, err := newSessionToken()
+	if err != nil {
+		return LoginResult{}, err
+	}
+
+	now := s.clock.Now()
+	var res LoginResult
+	// A wrong guess still commits the attempt counter, so the rejection is
+	// reported after the transaction.
+	var rejected bool
+	err = s.repo.WithTx(ctx, func(txCtx context.Context) error {
+		lc, err := s.repo.GetLatestLoginCodeForUpdate(txCtx, email)
+		if err != nil {
+			return err
+		}
+		if lc.ConsumedAt != nil || !now.Before(lc.ExpiresAt) {
+			return domain.ErrInvalidLoginCode
+		}
+		failed, err := s.repo.CountFailedLoginAttempts(txCtx, email, now.Add(-s.limits.Window))
+		if err != nil {
+			return err
+		}
+		if failed >= s.limits.FailedAttempts {
+			return domain.ErrLoginThrottled
+		}
+
+		if subtle.ConstantTimeCompare([]byte(hashLoginCode(lc.ID, code)), []byte(lc.CodeHash)) != 1 {
+			rejected = true
+			lc.Attempts++
+			if lc.Attempts >= s.maxAttempts {
+				lc.ConsumedAt = &now
+			}
+			return s.repo.UpdateLoginCode(txCtx, lc)
+		}
+
+		lc.ConsumedAt = &now
+		if err := s.repo.UpdateLoginCode(txCtx, lc); err != nil {
+			return err
+		}
+		customer, err := s.customers.EnsureCustomer(txCtx, email)
+		if err != nil {
+			return err
+		}
+		session := domain.Session{
+			TokenHash:  hashSessionToken(token),
+			CustomerID: customer.ID,
+			ExpiresAt:  now.Add(s.sessionTTL),
+			CreatedAt:  now,
+		}
+		if err := s.repo.CreateSession(txCtx, session); err != nil {
+			return err
+		}
+		res = LoginResult{Customer: customer, Token: token, ExpiresAt: session.ExpiresAt}
+		return nil
+	})
+	if err != nil {
+		return LoginResult{}, err
+	}
+	if rejected {
+		return LoginResult{}, domain.ErrInvalidLoginCode
+	}
+	return res, nil
+}
+
+// Authenticate returns the customer a session token belongs to.
+func (s *AuthService) Authenticate(ctx context.Context, token string) (domain.Customer, error) {
+	if token == "" {
+		return domain.Customer{}, domain.ErrInvalidSession
+	}
+	session, err := s.repo.GetSession(ctx, hashSessionToken(token))
+	if err != nil {
+		return domain.Customer{}, err
+	}
+	if session.RevokedAt != nil || !s.clock.Now().Before(session.ExpiresAt) {
+		return domain.Customer{}, domain.ErrInvalidSession
+	}
+	return s.customers.GetCustomer(ctx, session.CustomerID)
+}
+
+// Logout revokes the session token.
+func (s *AuthService) Logout(ctx context.Context, token string) error {
+	return s.repo.RevokeSession(ctx, hashSessionToken(token), s.clock.Now())
+}
+
+func newLoginCode() (string, error) {
+	limit := new(big.Int).Exp(big.NewInt(10), big.NewInt(loginCodeDigits), nil)
+	n, err := rand.Int(rand.Reader, limit)
+	if err != nil {
+		return "", fmt.Errorf("generate login code: %w", err)
+	}
+	return fmt.Sprintf("%0*d", loginCodeDigits, n), nil
+}
+
+func newSessionToken() (string, error) {
+	b := make([]byte, sessionTokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generate session token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashLoginCode salts the code with its ID so equal codes hash differently.
+func hashLoginCode(id, code string) string {
+	return hashSecret(id + ":" + code)
+}
+
+func hashSessionToken(token string) string {
+	return hashSecret(token)
+}
+
+func hashSecret(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
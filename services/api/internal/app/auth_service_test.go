@@ -0,0 +1,281 @@
+package app
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/cimillas/ultimate-ticket/services/api/internal/clock"
+	"github.com/cimillas/ultimate-ticket/services/api/internal/domain"
+)
+
+func TestAuthService_Login(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, 1, 2, 10, 0, 0, 0, time.UTC)
+	codePattern := regexp.MustCompile(`code is (\d{6})`)
+
+	newSvc := func(repo *fakeAuthRepo, customers *fakeCustomerRepo, mailer *stubMailer, at time.Time, opts ...AuthServiceOption) *AuthService {
+		clk := clock.NewFixed(at)
+		return NewAuthService(repo, NewCustomerService(customers, clk), mailer, clk, opts...)
+	}
+	requestCode := func(t *testing.T, svc *AuthService, mailer *stubMailer, email string) string {
+		t.Helper()
+		if err := svc.RequestLoginCode(context.Background(), email, "203.0.113.7"); err != nil {
+			t.Fatalf("request code: %v", err)
+		}
+		msg := mailer.sent[len(mailer.sent)-1]
+		m := codePattern.FindStringSubmatch(msg.Text)
+		if m == nil {
+			t.Fatalf("expected code in email, got %q", msg.Text)
+		}
+		return m[1]
+	}
+
+	t.Run("code is exchanged once for a session", func(t *testing.T) {
+		repo, customers, mailer := newFakeAuthRepo(), newFakeCustomerRepo(), &stubMailer{}
+		svc := newSvc(repo, customers, mailer, now)
+
+		code := requestCode(t, svc, mailer, "Fan@Example.com")
+		if mailer.sent[0].To != "fan@example.com" {
+			t.Fatalf("expected code sent to normalized address, got %q", mailer.sent[0].To)
+		}
+		if repo.codes[0].CodeHash == code {
+			t.Fatalf("expected code to be stored hashed")
+		}
+
+		res, err := svc.VerifyLoginCode(context.Background(), "fan@example.com", code)
+		if err != nil {
+			t.Fatalf("verify: %v", err)
+		}
+		if res.Token == "" || res.Customer.Email != "fan@example.com" || !res.ExpiresAt.Equal(now.Add(defaultSessionTTL)) {
+			t.Fatalf("unexpected login result %+v", res)
+		}
+		if _, ok := repo.sessions[res.Token]; ok {
+			t.Fatalf("expected session token to be stored hashed")
+		}
+
+		customer, err := svc.Authenticate(context.Background(), res.Token)
+		if err != nil || customer.ID != res.Customer.ID {
+			t.Fatalf("expected customer %s, got %+v (%v)", res.Customer.ID, customer, err)
+		}
+
+		if _, err := svc.VerifyLoginCode(context.Background(), "fan@example.com", code); err != domain.ErrInvalidLoginCode {
+			t.Fatalf("expected reused code to fail, got %v", err)
+		}
+
+		if err := svc.Logout(context.Background(), res.Token); err != nil {
+			t.Fatalf("logout: %v", err)
+		}
+		if _, err := svc.Authenticate(context.Background(), res.Token); err != domain.ErrInvalidSession {
+			t.Fatalf("expected ErrInvalidSession after logout, got %v", err)
+		}
+	})
+
+	t.Run("new code replaces the previous one", func(t *testing.T) {
+		repo, customers, mailer := newFakeAuthRepo(), newFakeCustomerRepo(), &stubMailer{}
+		svc := newSvc(repo, customers, mailer, now)
+
+		first := requestCode(t, svc, mailer, "fan@example.com")
+		second := requestCode(t, svc, mailer, "fan@example.com")
+		if first != second {
+			if _, err := svc.VerifyLoginCode(context.Background(), "fan@example.com", first); err != domain.ErrInvalidLoginCode {
+				t.Fatalf("expected first code to be invalid, got %v", err)
+			}
+		}
+		if _, err := svc.VerifyLoginCode(context.Background(), "fan@example.com", second); err != nil {
+			t.Fatalf("expected second code to work, got %v", err)
+		}
+	})
+
+	t.Run("expired code is rejected", func(t *testing.T) {
+		repo, customers, mailer := newFakeAuthRepo(), newFakeCustomerRepo(), &stubMailer{}
+		code := requestCode(t, newSvc(repo, customers, mailer, now), mailer, "fan@example.com")
+
+		later := newSvc(repo, customers, mailer, now.Add(defaultLoginCodeTTL))
+		if _, err := later.VerifyLoginCode(context.Background(), "fan@example.com", code); err != domain.ErrInvalidLoginCode {
+			t.Fatalf("expected ErrInvalidLoginCode, got %v", err)
+		}
+	})
+
+	t.Run("code is burned after too many wrong guesses", func(t *testing.T) {
+		repo, customers, mailer := newFakeAuthRepo(), newFakeCustomerRepo(), &stubMailer{}
+		svc := newSvc(repo, customers, mailer, now)
+		code := requestCode(t, svc, mailer, "fan@example.com")
+
+		wrong := "000000"
+		if code == wrong {
+			wrong = "111111"
+		}
+		for i := 0; i < defaultLoginCodeAttempts; i++ {
+			if _, err := svc.VerifyLoginCode(context.Background(), "fan@example.com", wrong); err != domain.ErrInvalidLoginCode {
+				t.Fatalf("attempt %d: expected ErrInvalidLoginCode, got %v", i, err)
+			}
+		}
+		if repo.codes[0].Attempts != defaultLoginCodeAttempts {
+			t.Fatalf("expected attempts recorded, got %d", repo.codes[0].Attempts)
+		}
+		if _, err := svc.VerifyLoginCode(context.Background(), "fan@example.com", code); err != domain.ErrInvalidLoginCode {
+			t.Fatalf("expected burned code to fail, got %v", err)
+		}
+	})
+
+	t.Run("code requests are throttled per address and per client", func(t *testing.T) {
+		repo, customers, mailer := newFakeAuthRepo(), newFakeCustomerRepo(), &stubMailer{}
+		svc := newSvc(repo, customers, mailer, now, WithLoginLimits(LoginLimits{CodesPerEmail: 2, CodesPerIP: 3}))
+
+		requestCode(t, svc, mailer, "fan@example.com")
+		requestCode(t, svc, mailer, "fan@example.com")
+		if err := svc.RequestLoginCode(context.Background(), "fan@example.com", "198.51.100.9"); err != domain.ErrLoginThrottled {
+			t.Fatalf("expected ErrLoginThrottled for the address, got %v", err)
+		}
+		requestCode(t, svc, mailer, "other@example.com")
+		if err := svc.RequestLoginCode(context.Background(), "third@example.com", "203.0.113.7"); err != domain.ErrLoginThrottled {
+			t.Fatalf("expected ErrLoginThrottled for the client, got %v", err)
+		}
+		if len(mailer.sent) != 3 {
+			t.Fatalf("expected 3 emails, got %d", len(mailer.sent))
+		}
+
+		later := newSvc(repo, customers, mailer, now.Add(defaultLoginWindow), WithLoginLimits(LoginLimits{CodesPerEmail: 2, CodesPerIP: 3}))
+		requestCode(t, later, mailer, "fan@example.com")
+	})
+
+	t.Run("wrong guesses are capped across codes", func(t *testing.T) {
+		repo, customers, mailer := newFakeAuthRepo(), newFakeCustomerRepo(), &stubMailer{}
+		svc := newSvc(repo, customers, mailer, now)
+
+		var code string
+		for i := 0; i < defaultLoginFailures/defaultLoginCodeAttempts; i++ {
+			code = requestCode(t, svc, mailer, "fan@example.com")
+			wrong := "000000"
+			if code == wrong {
+				wrong = "111111"
+			}
+			for j := 0; j < defaultLoginCodeAttempts; j++ {
+				if _, err := svc.VerifyLoginCode(context.Background(), "fan@example.com", wrong); err != domain.ErrInvalidLoginCode {
+					t.Fatalf("code %d attempt %d: expected ErrInvalidLoginCode, got %v", i, j, err)
+				}
+			}
+		}
+		code = requestCode(t, svc, mailer, "fan@example.com")
+		if _, err := svc.VerifyLoginCode(context.Background(), "fan@example.com", code); err != domain.ErrLoginThrottled {
+			t.Fatalf("expected ErrLoginThrottled, got %v", err)
+		}
+
+		later := newSvc(repo, customers, mailer, now.Add(defaultLoginWindow))
+		code = requestCode(t, later, mailer, "fan@example.com")
+		if _, err := later.VerifyLoginCode(context.Background(), "fan@example.com", code); err != nil {
+			t.Fatalf("expected sign-in after the window, got %v", err)
+		}
+	})
+
+	t.Run("expired session is rejected", func(t *testing.T) {
+		repo, customers, mailer := newFakeAuthRepo(), newFakeCustomerRepo(), &stubMailer{}
+		svc := newSvc(repo, customers, mailer, now)
+		code := requestCode(t, svc, mailer, "fan@example.com")
+		res, err := svc.VerifyLoginCode(context.Background(), "fan@example.com", code)
+		if err != nil {
+			t.Fatalf("verify: %v", err)
+		}
+
+		later := newSvc(repo, customers, mailer, res.ExpiresAt)
+		if _, err := later.Authenticate(context.Background(), res.Token); err != domain.ErrInvalidSession {
+			t.Fatalf("expected ErrInvalidSession, got %v", err)
+		}
+	})
+}
+
+type fakeAuthRepo struct {
+	codes    []domain.LoginCode
+	sessions map[string]domain.Session
+}
+
+func newFakeAuthRepo() *fakeAuthRepo {
+	return &fakeAuthRepo{sessions: make(map[string]domain.Session)}
+}
+
+func (f *fakeAuthRepo) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+func (f *fakeAuthRepo) ConsumeLoginCodes(_ context.Context, email string, now time.Time) error {
+	for i := range f.codes {
+		if f.codes[i].Email == email && f.codes[i].ConsumedAt == nil {
+			f.codes[i].ConsumedAt = &now
+		}
+	}
+	return nil
+}
+
+func (f *fakeAuthRepo) CreateLoginCode(_ context.Context, code domain.LoginCode) error {
+	f.codes = append(f.codes, code)
+	return nil
+}
+
+func (f *fakeAuthRepo) CountLoginCodes(_ context.Context, email, clientIP string, since time.Time) (int, int, error) {
+	var byEmail, byIP int
+	for _, c := range f.codes {
+		if !c.CreatedAt.After(since) {
+			continue
+		}
+		if c.Email == email {
+			byEmail++
+		}
+		if clientIP != "" && c.ClientIP == clientIP {
+			byIP++
+		}
+	}
+	return byEmail, byIP, nil
+}
+
+func (f *fakeAuthRepo) CountFailedLoginAttempts(_ context.Context, email string, since time.Time) (int, error) {
+	var n int
+	for _, c := range f.codes {
+		if c.Email == email && c.CreatedAt.After(since) {
+			n += c.Attempts
+		}
+	}
+	return n, nil
+}
+
+func (f *fakeAuthRepo) GetLatestLoginCodeForUpdate(_ context.Context, email string) (domain.LoginCode, error) {
+	for i := len(f.codes) - 1; i >= 0; i-- {
+		if f.codes[i].Email == email {
+			return f.codes[i], nil
+		}
+	}
+	return domain.LoginCode{}, domain.ErrInvalidLoginCode
+}
+
+func (f *fakeAuthRepo) UpdateLoginCode(_ context.Context, code domain.LoginCode) error {
+	for i := range f.codes {
+		if f.codes[i].ID == code.ID {
+			f.codes[i] = code
+			return nil
+		}
+	}
+	return domain.ErrInvalidLoginCode
+}
+
+func (f *fakeAuthRepo) CreateSession(_ context.Context, session domain.Session) error {
+	f.sessions[session.TokenHash] = session
+	return nil
+}
+
+func (f *fakeAuthRepo) GetSession(_ context.Context, tokenHash string) (domain.Session, error) {
+	session, ok := f.sessions[tokenHash]
+	if !ok {
+		return domain.Session{}, domain.ErrInvalidSession
+	}
+	return session, nil
+}
+
+func (f *fakeAuthRepo) RevokeSession(_ context.Context, tokenHash string, now time.Time) error {
+	if session, ok := f.sessions[tokenHash]; ok {
+		session.RevokedAt = &now
+		f.sessions[tokenHash] = session
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package app
+
+import (
+	"context"
+	"net/mail"
+	"strings"
+
+	"github.com/cimillas/ultimate-ticket/services/api/internal/clock"
+	"github.com/cimillas/ultimate-ticket/services/api/internal/domain"
+)
+
+type CustomerRepository interface {
+	// UpsertCustomer inserts the customer, or returns the existing one with
+	// the same email.
+	UpsertCustomer(ctx context.Context, customer domain.Customer) (domain.Customer, error)
+	GetCustomer(ctx context.Context, id string) (domain.Customer, error)
+	// ListCustomerOrders returns the customer's orders, newest first.
+	ListCustomerOrders(ctx context.Context, customerID string) ([]domain.CustomerOrder, error)
+	// GetCustomerOrder returns ErrOrderNotFound when the order belongs to
+	// someone else.
+	GetCustomerOrder(ctx context.Context, customerID, orderID string) (domain.CustomerOrder, error)
+}
+
+// CustomerService manages customer accounts and their order history.
+type CustomerService struct {
+	repo  CustomerRepository
+	clock clock.Clock
+}
+
+func NewCustomerService(repo CustomerRepository, clk clock.Clock) *CustomerService {
+	return &CustomerService{
+		repo:  repo,
+		clock: clk,
+	}
+}
+
+// EnsureCustomer returns the customer for email, creating it on first use.
+// Emails are compared case-insensitively.
+func (s *CustomerService) EnsureCustomer(ctx context.Context, email string) (domain.Customer, error) {
+	email, err := normalizeEmail(email)
+	if err != nil {
+		return domain.Customer{}, err
+	}
+	return s.repo.UpsertCustomer(ctx, domain.Customer{
+		ID:        newUUID(),
+		Email:     email,
+		CreatedAt: s.clock.Now(),
+	})
+}
+
+func (s *CustomerService) GetCustomer(ctx context.Context, id string) (domain.Customer, error) {
+	return s.repo.GetCustomer(ctx, id)
+}
+
+func (s *CustomerService) ListOrders(ctx context.Context, customerID string) ([]domain.CustomerOrder, error) {
+	return s.repo.ListCustomerOrders(ctx, customerID)
+}
+
+func (s *CustomerService) GetOrder(ctx context.Context, customerID, orderID string) (domain.CustomerOrder, error) {
+	return s.repo.GetCustomerOrder(ctx, customerID, orderID)
+}
+
+// normalizeEmail accepts a bare address and returns it lowercased.
+func normalizeEmail(email string) (string, error) {
+	addr, err := mail.ParseAddress(strings.TrimSpace(email))
+	if err != nil || addr.Name != "" {
+		return "", domain.ErrInvalidEmail
+	}
+	return strings.ToLower(addr.Address), nil
+}
@@ -0,0 +1,81 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cimillas/ultimate-ticket/services/api/internal/clock"
+	"github.com/cimillas/ultimate-ticket/services/api/internal/domain"
+)
+
+func TestCustomerService_EnsureCustomer(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, 1, 2, 10, 0, 0, 0, time.UTC)
+
+	t.Run("creates customer once per email", func(t *testing.T) {
+		repo := newFakeCustomerRepo()
+		svc := NewCustomerService(repo, clock.NewFixed(now))
+
+		first, err := svc.EnsureCustomer(context.Background(), "Ada@Example.com")
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if first.ID == "" || first.Email != "ada@example.com" || !first.CreatedAt.Equal(now) {
+			t.Fatalf("unexpected customer %+v", first)
+		}
+
+		second, err := svc.EnsureCustomer(context.Background(), " ada@example.com ")
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if second.ID != first.ID {
+			t.Fatalf("expected same customer, got %s and %s", first.ID, second.ID)
+		}
+	})
+
+	t.Run("rejects invalid email", func(t *testing.T) {
+		svc := NewCustomerService(newFakeCustomerRepo(), clock.NewFixed(now))
+
+		for _, email := range []string{"", "not-an-email", "Ada <ada@example.com>"} {
+			if _, err := svc.EnsureCustomer(context.Background(), email); !errors.Is(err, domain.ErrInvalidEmail) {
+				t.Fatalf("%q: expected ErrInvalidEmail, got %v", email, err)
+			}
+		}
+	})
+}
+
+type fakeCustomerRepo struct {
+	byEmail map[string]domain.Customer
+}
+
+func newFakeCustomerRepo() *fakeCustomerRepo {
+	return &fakeCustomerRepo{byEmail: make(map[string]domain.Customer)}
+}
+
+func (f *fakeCustomerRepo) UpsertCustomer(_ context.Context, customer domain.Customer) (domain.Customer, error) {
+	if existing, ok := f.byEmail[customer.Email]; ok {
+		return existing, nil
+	}
+	f.byEmail[customer.Email] = customer
+	return customer, nil
+}
+
+func (f *fakeCustomerRepo) GetCustomer(_ context.Context, id string) (domain.Customer, error) {
+	for _, c := range f.byEmail {
+		if c.ID == id {
+			return c, nil
+		}
+	}
+	return domain.Customer{}, domain.ErrCustomerNotFound
+}
+
+func (f *fakeCustomerRepo) ListCustomerOrders(_ context.Context, _ string) ([]domain.CustomerOrder, error) {
+	return nil, nil
+}
+
+func (f *fakeCustomerRepo) GetCustomerOrder(_ context.Context, _, _ string) (domain.CustomerOrder, error) {
+	return domain.CustomerOrder{}, domain.ErrOrderNotFound
+}
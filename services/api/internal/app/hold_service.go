@@ -6,21 +6,63 @@ import (
 
 	"github.com/cimillas/ultimate-ticket/services/api/internal/clock"
 	"github.com/cimillas/ultimate-ticket/services/api/internal/domain"
+	"github.com/cimillas/ultimate-ticket/services/api/internal/tracing"
 )
 
 type HoldRepository interface {
 	WithTx(ctx context.Context, fn func(ctx context.Context) error) error
 	GetZoneForUpdate(ctx context.Context, eventID, zoneID string) (domain.Zone, error)
+	// GetWaitingRoom returns ErrEventNotFound for unknown events.
+	GetWaitingRoom(ctx context.Context, eventID string) (domain.WaitingRoom, error)
 	FindHoldByIdempotencyKey(ctx context.Context, eventID, zoneID, key string) (*domain.Hold, error)
 	SumActiveHolds(ctx context.Context, eventID, zoneID string, now time.Time) (int, error)
 	SumConfirmed(ctx context.Context, eventID, zoneID string) (int, error)
+	// GetEventPurchaseLimit returns the tickets one customer may hold or buy
+	// for the event; 0 means unlimited.
+	GetEventPurchaseLimit(ctx context.Context, eventID string) (int, error)
+	// LockCustomer serializes hold creation for one customer across zones.
+	LockCustomer(ctx context.Context, customerID string) error
+	// SumCustomerTickets returns how many tickets the customer holds or has
+	// bought for the event, and for the zone within it. Active holds count
+	// while they reserve inventory, and confirmed holds always count; holds
+	// released by a failed or cancelled order do not.
+	SumCustomerTickets(ctx context.Context, customerID, eventID, zoneID string, now time.Time) (eventQty, zoneQty int, err error)
+	// LockQueueEntry serializes hold creation for one waiting-room admission.
+	LockQueueEntry(ctx context.Context, entryID string) error
+	// HasQueueEntryHold reports whether a hold made with the admission still
+	// reserves inventory or was confirmed.
+	HasQueueEntryHold(ctx context.Context, entryID string, now time.Time) (bool, error)
 	CreateHold(ctx context.Context, hold domain.Hold) error
+	// ExpireHolds marks up to limit lapsed active holds as expired and returns them.
+	ExpireHolds(ctx context.Context, now time.Time, limit int) ([]domain.Hold, error)
+	AppendOutboxEvent(ctx context.Context, event domain.OutboxEvent) error
 }
 
 type HoldService struct {
-	repo    HoldRepository
-	clock   clock.Clock
-	holdTTL time.Duration
+	repo      HoldRepository
+	clock     clock.Clock
+	holdTTL   time.Duration
+	admission AdmissionVerifier
+	rights    PurchaseRights
+	risk      RiskScorer
+	metrics   HoldMetrics
+}
+
+// AdmissionVerifier checks waiting-room admission tokens presented by
+// customerID and returns the queue entry they admit.
+type AdmissionVerifier interface {
+	VerifyAdmission(token, eventID, customerID string) (entryID string, err error)
+}
+
+// PurchaseRights redeems lottery purchase rights within the hold's
+// transaction. Zones not sold by lottery need no right.
+type PurchaseRights interface {
+	RedeemPurchaseRight(ctx context.Context, zoneID, customerID string, quantity int, holdID string, now time.Time) error
+}
+
+// RiskScorer decides whether a hold attempt may go ahead.
+type RiskScorer interface {
+	Assess(ctx context.Context, signals domain.RiskSignals) (domain.RiskAssessment, error)
 }
 
 const defaultHoldTTL = 15 * time.Minute
@@ -48,31 +90,106 @@ func WithHoldTTL(d time.Duration) HoldServiceOption {
 	}
 }
 
+// WithPurchaseRights requires holds on zones sold by lottery to redeem the
+// customer's purchase right.
+func WithPurchaseRights(r PurchaseRights) HoldServiceOption {
+	return func(s *HoldService) {
+		s.rights = r
+	}
+}
+
+// WithAdmissionControl requires holds on events with the waiting room
+// enabled to carry an admission token accepted by v. Each admission keeps at
+// most one hold at a time.
+func WithAdmissionControl(v AdmissionVerifier) HoldServiceOption {
+	return func(s *HoldService) {
+		s.admission = v
+	}
+}
+
+// WithRiskScoring consults r before every hold; attempts it challenges need
+// a solved proof of work and attempts it blocks are refused.
+func WithRiskScoring(r RiskScorer) HoldServiceOption {
+	return func(s *HoldService) {
+		s.risk = r
+	}
+}
+
+// WithHoldMetrics reports created, rejected and expired holds to m.
+func WithHoldMetrics(m HoldMetrics) HoldServiceOption {
+	return func(s *HoldService) {
+		s.metrics = m
+	}
+}
+
 type CreateHoldInput struct {
 	EventID        string
 	ZoneID         string
 	Quantity       int
 	IdempotencyKey string
+	// CustomerID is set when the hold is created by an authenticated customer.
+	CustomerID string
+	// AdmissionToken is required when the event's waiting room is enabled.
+	AdmissionToken string
+	// Request signals for risk scoring.
+	ClientIP  string
+	UserAgent string
+	// ProofOfWork is set when the request carried a solved challenge.
+	ProofOfWork bool
 }
 
 func (s *HoldService) CreateHold(ctx context.Context, in CreateHoldInput) (domain.Hold, error) {
+	ctx, span := tracing.Start(ctx, "HoldService.CreateHold")
+	defer span.End()
+	span.SetAttr("event_id", in.EventID)
+	span.SetAttr("zone_id", in.ZoneID)
+	span.SetAttr("quantity", in.Quantity)
+
+	hold, created, err := s.createHold(ctx, in)
+	span.SetError(err)
+	if s.metrics != nil {
+		switch {
+		case err != nil:
+			s.metrics.HoldRejected(rejectionReason(err))
+		case created:
+			s.metrics.HoldCreated()
+		}
+	}
+	return hold, err
+}
+
+// createHold also reports whether the hold is new rather than an idempotent
+// retry.
+func (s *HoldService) createHold(ctx context.Context, in CreateHoldInput) (domain.Hold, bool, error) {
 	if in.Quantity <= 0 {
-		return domain.Hold{}, domain.ErrInvalidQuantity
+		return domain.Hold{}, false, domain.ErrInvalidQuantity
 	}
 	if in.IdempotencyKey == "" {
-		return domain.Hold{}, domain.ErrIdempotencyKeyRequired
+		return domain.Hold{}, false, domain.ErrIdempotencyKeyRequired
+	}
+	// Replays are answered before the admission and risk checks, which the
+	// original request already passed and a retry may no longer pass.
+	if existing, err := s.findReplay(ctx, in); err != nil {
+		return domain.Hold{}, false, err
+	} else if existing != nil {
+		return *existing, false, nil
+	}
+	entryID, err := s.checkAdmission(ctx, in)
+	if err != nil {
+		return domain.Hold{}, false, err
+	}
+	if err := s.checkRisk(ctx, in); err != nil {
+		return domain.Hold{}, false, err
 	}
 
 	now := s.clock.Now()
 	var result domain.Hold
+	var created bool
 
-	err := s.repo.WithTx(ctx, func(txCtx context.Context) error {
-		if existing, err := s.repo.FindHoldByIdempotencyKey(txCtx, in.EventID, in.ZoneID, in.IdempotencyKey); err != nil {
+	err = s.repo.WithTx(ctx, func(txCtx context.Context) error {
+		if existing, err := s.findReplay(txCtx, in); err != nil {
 			return err
 		} else if existing != nil {
-			if existing.Quantity != in.Quantity {
-				return domain.ErrIdempotencyConflict
-			}
 			result = *existing
 			return nil
 		}
@@ -81,6 +198,9 @@ func (s *HoldService) CreateHold(ctx context.Context, in CreateHoldInput) (domai
 		if err != nil {
 			return err
 		}
+		if err := zone.CheckQuantity(in.Quantity); err != nil {
+			return err
+		}
 
 		activeQty, err := s.repo.SumActiveHolds(txCtx, in.EventID, in.ZoneID, now)
 		if err != nil {
@@ -95,6 +215,12 @@ func (s *HoldService) CreateHold(ctx context.Context, in CreateHoldInput) (domai
 		if in.Quantity > available {
 			return domain.ErrInsufficientCapacity
 		}
+		if err := s.checkPurchaseLimits(txCtx, in, zone, now); err != nil {
+			return err
+		}
+		if err := s.checkAdmissionUnused(txCtx, entryID, now); err != nil {
+			return err
+		}
 
 		hold := domain.Hold{
 			ID:             newUUID(),
@@ -104,33 +230,214 @@ func (s *HoldService) CreateHold(ctx context.Context, in CreateHoldInput) (domai
 			Status:         domain.HoldStatusActive,
 			ExpiresAt:      now.Add(s.holdTTL),
 			IdempotencyKey: in.IdempotencyKey,
+			CustomerID:     in.CustomerID,
+			QueueEntryID:   entryID,
 			CreatedAt:      now,
 		}
 
 		if err := s.repo.CreateHold(txCtx, hold); err != nil {
 			// Re-read on conflict to keep idempotent retries consistent under concurrency.
 			if err == domain.ErrIdempotencyConflict {
-				existing, err := s.repo.FindHoldByIdempotencyKey(txCtx, in.EventID, in.ZoneID, in.IdempotencyKey)
+				existing, err := s.findReplay(txCtx, in)
 				if err != nil {
 					return err
 				}
 				if existing != nil {
-					if existing.Quantity != in.Quantity {
-						return domain.ErrIdempotencyConflict
-					}
 					result = *existing
 					return nil
 				}
 			}
 			return err
 		}
+		// Redeem after the insert so a concurrent idempotent retry is answered
+		// with the existing hold above rather than a spent right.
+		if s.rights != nil {
+			if err := s.rights.RedeemPurchaseRight(txCtx, in.ZoneID, in.CustomerID, in.Quantity, hold.ID, now); err != nil {
+				return err
+			}
+		}
+
+		event, err := newHoldOutboxEvent(domain.OutboxHoldCreated, hold, now)
+		if err != nil {
+			return err
+		}
+		if err := s.repo.AppendOutboxEvent(txCtx, event); err != nil {
+			return err
+		}
 
 		result = hold
+		created = true
 		return nil
 	})
 	if err != nil {
-		return domain.Hold{}, err
+		return domain.Hold{}, false, err
 	}
 
-	return result, nil
+	return result, created, nil
+}
+
+// findReplay returns the hold an earlier request with the same idempotency
+// key created, or ErrIdempotencyConflict when that request asked for another
+// quantity or came from another customer.
+func (s *HoldService) findReplay(ctx context.Context, in CreateHoldInput) (*domain.Hold, error) {
+	existing, err := s.repo.FindHoldByIdempotencyKey(ctx, in.EventID, in.ZoneID, in.IdempotencyKey)
+	if err != nil || existing == nil {
+		return nil, err
+	}
+	if existing.Quantity != in.Quantity || existing.CustomerID != in.CustomerID {
+		return nil, domain.ErrIdempotencyConflict
+	}
+	return existing, nil
+}
+
+// checkAdmission lets a hold through when the event has no waiting room or
+// the buyer was admitted, and returns the admitted queue entry. Unknown events
+// fall through to the zone lookup.
+func (s *HoldService) checkAdmission(ctx context.Context, in CreateHoldInput) (string, error) {
+	if s.admission == nil {
+		return "", nil
+	}
+	room, err := s.repo.GetWaitingRoom(ctx, in.EventID)
+	if err == domain.ErrEventNotFound {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	if !room.Enabled {
+		return "", nil
+	}
+	return s.admission.VerifyAdmission(in.AdmissionToken, in.EventID, in.CustomerID)
+}
+
+// checkAdmissionUnused refuses a second hold on one admission while its first
+// hold still reserves inventory or was bought, so a shared token admits one
+// purchase. A hold that expired or was released frees the admission again.
+func (s *HoldService) checkAdmissionUnused(ctx context.Context, entryID string, now time.Time) error {
+	if entryID == "" {
+		return nil
+	}
+	if err := s.repo.LockQueueEntry(ctx, entryID); err != nil {
+		return err
+	}
+	used, err := s.repo.HasQueueEntryHold(ctx, entryID, now)
+	if err != nil {
+		return err
+	}
+	if used {
+		return domain.ErrAdmissionUsed
+	}
+	return nil
+}
+
+// checkRisk refuses attempts the risk scorer blocks, and attempts it
+// challenges unless they carry a proof of work. The decision is recorded
+// outside the hold's transaction so refused attempts are kept too.
+func (s *HoldService) checkRisk(ctx context.Context, in CreateHoldInput) error {
+	if s.risk == nil {
+		return nil
+	}
+	assessment, err := s.risk.Assess(ctx, domain.RiskSignals{
+		EventID:        in.EventID,
+		ZoneID:         in.ZoneID,
+		CustomerID:     in.CustomerID,
+		IP:             in.ClientIP,
+		UserAgent:      in.UserAgent,
+		IdempotencyKey: in.IdempotencyKey,
+		ProofOfWork:    in.ProofOfWork,
+	})
+	if err != nil {
+		return err
+	}
+	switch assessment.Decision {
+	case domain.RiskBlock:
+		return domain.ErrHoldBlocked
+	case domain.RiskChallenge:
+		if !in.ProofOfWork {
+			return domain.ErrChallengeRequired
+		}
+	}
+	return nil
+}
+
+// checkPurchaseLimits rejects a hold that would take the customer past the
+// event's or the zone's limit. Limited zones only sell to signed-in customers,
+// since an anonymous hold has no one to count against. The customer lock
+// keeps concurrent holds in other zones from slipping past the event limit.
+func (s *HoldService) checkPurchaseLimits(ctx context.Context, in CreateHoldInput, zone domain.Zone, now time.Time) error {
+	perEvent, err := s.repo.GetEventPurchaseLimit(ctx, in.EventID)
+	if err != nil {
+		return err
+	}
+	if perEvent == 0 && zone.PurchaseLimit == 0 {
+		return nil
+	}
+	if in.CustomerID == "" {
+		return domain.ErrSignInRequired
+	}
+	if err := s.repo.LockCustomer(ctx, in.CustomerID); err != nil {
+		return err
+	}
+	eventQty, zoneQty, err := s.repo.SumCustomerTickets(ctx, in.CustomerID, in.EventID, in.ZoneID, now)
+	if err != nil {
+		return err
+	}
+
+	var exceeded *domain.PurchaseLimitError
+	for _, l := range []struct {
+		scope string
+		max   int
+		used  int
+	}{
+		{domain.PurchaseLimitEvent, perEvent, eventQty},
+		{domain.PurchaseLimitZone, zone.PurchaseLimit, zoneQty},
+	} {
+		if l.max == 0 || l.used+in.Quantity <= l.max {
+			continue
+		}
+		remaining := max(l.max-l.used, 0)
+		// Report the tightest limit so the remaining allowance is usable.
+		if exceeded == nil || remaining < exceeded.Remaining {
+			exceeded = &domain.PurchaseLimitError{Scope: l.scope, Limit: l.max, Remaining: remaining}
+		}
+	}
+	if exceeded != nil {
+		return exceeded
+	}
+	return nil
+}
+
+const expireHoldsBatchSize = 500
+
+// ExpireHolds moves holds whose reservation lapsed to expired and emits a
+// hold.expired event for each. Holds with an open payment attempt stay active
+// until their grace window closes. It returns how many holds were expired.
+func (s *HoldService) ExpireHolds(ctx context.Context) (int, error) {
+	now := s.clock.Now()
+	expired := 0
+
+	err := s.repo.WithTx(ctx, func(txCtx context.Context) error {
+		holds, err := s.repo.ExpireHolds(txCtx, now, expireHoldsBatchSize)
+		if err != nil {
+			return err
+		}
+		for _, hold := range holds {
+			event, err := newHoldOutboxEvent(domain.OutboxHoldExpired, hold, now)
+			if err != nil {
+				return err
+			}
+			if err := s.repo.AppendOutboxEvent(txCtx, event); err != nil {
+				return err
+			}
+		}
+		expired = len(holds)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	if s.metrics != nil && expired > 0 {
+		s.metrics.HoldsExpired(expired)
+	}
+	return expired, nil
 }
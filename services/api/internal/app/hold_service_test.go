@@ -2,11 +2,13 @@ package app
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
 	"github.com/cimillas/ultimate-ticket/services/api/internal/clock"
 	"github.com/cimillas/ultimate-ticket/services/api/internal/domain"
+	"github.com/cimillas/ultimate-ticket/services/api/internal/tracing"
 )
 
 func TestHoldService_CreateHold(t *testing.T) {
@@ -52,6 +54,9 @@ func TestHoldService_CreateHold(t *testing.T) {
 		if len(repo.holds) != 3 {
 			t.Fatalf("expected 3 holds in repo, got %d", len(repo.holds))
 		}
+		if len(repo.outbox) != 1 || repo.outbox[0].Type != domain.OutboxHoldCreated || repo.outbox[0].AggregateID != hold.ID {
+			t.Fatalf("expected hold.created outbox event, got %+v", repo.outbox)
+		}
 	})
 
 	t.Run("returns existing hold on idempotency key", func(t *testing.T) {
@@ -164,6 +169,60 @@ func TestHoldService_CreateHold(t *testing.T) {
 		}
 	})
 
+	t.Run("holds with open payment keep capacity past expiry", func(t *testing.T) {
+		pendingUntil := now.Add(2 * time.Minute)
+		svc, _ := makeSvc(
+			[]domain.Zone{{ID: "zone-1", EventID: "event-1", Capacity: 100}},
+			[]domain.Hold{
+				{EventID: "event-1", ZoneID: "zone-1", Quantity: 80, Status: domain.HoldStatusActive, ExpiresAt: now.Add(-1 * time.Minute), PaymentPendingUntil: &pendingUntil},
+			},
+		)
+
+		_, err := svc.CreateHold(context.Background(), CreateHoldInput{
+			EventID:        "event-1",
+			ZoneID:         "zone-1",
+			Quantity:       50,
+			IdempotencyKey: "idem-4",
+		})
+		if err != domain.ErrInsufficientCapacity {
+			t.Fatalf("expected ErrInsufficientCapacity, got %v", err)
+		}
+	})
+
+	t.Run("idempotency key reused by another customer conflicts", func(t *testing.T) {
+		svc, _ := makeSvc(
+			[]domain.Zone{{ID: "zone-1", EventID: "event-1", Capacity: 100}},
+			[]domain.Hold{
+				{ID: "hold-1", EventID: "event-1", ZoneID: "zone-1", Quantity: 2, Status: domain.HoldStatusActive, ExpiresAt: now.Add(ttl), IdempotencyKey: "idem-5", CustomerID: "cust-1"},
+			},
+		)
+
+		_, err := svc.CreateHold(context.Background(), CreateHoldInput{
+			EventID:        "event-1",
+			ZoneID:         "zone-1",
+			Quantity:       2,
+			IdempotencyKey: "idem-5",
+			CustomerID:     "cust-2",
+		})
+		if err != domain.ErrIdempotencyConflict {
+			t.Fatalf("expected ErrIdempotencyConflict, got %v", err)
+		}
+
+		hold, err := svc.CreateHold(context.Background(), CreateHoldInput{
+			EventID:        "event-1",
+			ZoneID:         "zone-1",
+			Quantity:       2,
+			IdempotencyKey: "idem-5",
+			CustomerID:     "cust-1",
+		})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if hold.ID != "hold-1" || hold.CustomerID != "cust-1" {
+			t.Fatalf("expected existing customer hold, got %+v", hold)
+		}
+	})
+
 	t.Run("missing idempotency key returns error", func(t *testing.T) {
 		svc, _ := makeSvc(
 			[]domain.Zone{{ID: "zone-1", EventID: "event-1", Capacity: 100}},
@@ -182,9 +241,286 @@ func TestHoldService_CreateHold(t *testing.T) {
 	})
 }
 
+func TestHoldService_AdmissionControl(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	zones := []domain.Zone{
+		{ID: "zone-1", EventID: "queued", Capacity: 100},
+		{ID: "zone-2", EventID: "open", Capacity: 100},
+	}
+	rooms := NewWaitingRoomService(newFakeWaitingRoomRepo(nil), fakeTokens{}, clock.NewFixed(now))
+
+	tests := []struct {
+		name       string
+		eventID    string
+		zoneID     string
+		token      string
+		customerID string
+		wantErr    error
+	}{
+		{name: "admitted buyer", eventID: "queued", zoneID: "zone-1", token: "queued/entry-1"},
+		{name: "admitted customer", eventID: "queued", zoneID: "zone-1", token: "queued/entry-1/cust-1", customerID: "cust-1"},
+		{name: "another customer's token", eventID: "queued", zoneID: "zone-1", token: "queued/entry-1/cust-1", customerID: "cust-2", wantErr: domain.ErrInvalidAdmissionToken},
+		{name: "missing token", eventID: "queued", zoneID: "zone-1", wantErr: domain.ErrAdmissionRequired},
+		{name: "token for another event", eventID: "queued", zoneID: "zone-1", token: "open/entry-1", wantErr: domain.ErrInvalidAdmissionToken},
+		{name: "event without waiting room", eventID: "open", zoneID: "zone-2"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			repo := newFakeHoldRepo(zones, nil)
+			repo.rooms = map[string]domain.WaitingRoom{"queued": {Enabled: true, AdmitPerMinute: 10}}
+			svc := NewHoldService(repo, clock.NewFixed(now), WithAdmissionControl(rooms))
+
+			_, err := svc.CreateHold(context.Background(), CreateHoldInput{
+				EventID:        tt.eventID,
+				ZoneID:         tt.zoneID,
+				Quantity:       1,
+				IdempotencyKey: "idem-1",
+				AdmissionToken: tt.token,
+				CustomerID:     tt.customerID,
+			})
+			if err != tt.wantErr {
+				t.Fatalf("expected %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+
+	t.Run("an admission keeps one hold at a time", func(t *testing.T) {
+		t.Parallel()
+		repo := newFakeHoldRepo(append(zones, domain.Zone{ID: "zone-3", EventID: "queued", Capacity: 100}), nil)
+		repo.rooms = map[string]domain.WaitingRoom{"queued": {Enabled: true, AdmitPerMinute: 10}}
+		svc := NewHoldService(repo, clock.NewFixed(now), WithAdmissionControl(rooms))
+		ctx := context.Background()
+		in := CreateHoldInput{EventID: "queued", ZoneID: "zone-1", Quantity: 1, IdempotencyKey: "idem-1", AdmissionToken: "queued/entry-1"}
+
+		first, err := svc.CreateHold(ctx, in)
+		if err != nil {
+			t.Fatalf("create hold: %v", err)
+		}
+		if first.QueueEntryID != "entry-1" {
+			t.Fatalf("expected the hold to record its admission, got %q", first.QueueEntryID)
+		}
+		if retry, err := svc.CreateHold(ctx, in); err != nil || retry.ID != first.ID {
+			t.Fatalf("expected an idempotent retry to return the hold, got %+v (%v)", retry, err)
+		}
+		lapsed := in
+		lapsed.AdmissionToken = ""
+		if retry, err := svc.CreateHold(ctx, lapsed); err != nil || retry.ID != first.ID {
+			t.Fatalf("expected a retry after the admission lapsed to return the hold, got %+v (%v)", retry, err)
+		}
+
+		shared := in
+		shared.ZoneID, shared.IdempotencyKey = "zone-3", "idem-2"
+		if _, err := svc.CreateHold(ctx, shared); err != domain.ErrAdmissionUsed {
+			t.Fatalf("expected ErrAdmissionUsed for a second hold, got %v", err)
+		}
+
+		repo.holds[0].Status = domain.HoldStatusReleased
+		if _, err := svc.CreateHold(ctx, shared); err != nil {
+			t.Fatalf("expected a released hold to free the admission, got %v", err)
+		}
+	})
+}
+
+func TestHoldService_PurchaseRights(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	offerExpiresAt := now.Add(time.Hour)
+	lotteries := newFakeLotteryRepo()
+	lotteries.lotteries["lottery-1"] = domain.Lottery{ID: "lottery-1", ZoneID: "zone-1", Status: domain.LotteryDrawn}
+	lotteries.ballots = []domain.Ballot{
+		{ID: "ballot-1", LotteryID: "lottery-1", CustomerID: "winner", Quantity: 2, Status: domain.BallotOffered, OfferExpiresAt: &offerExpiresAt},
+	}
+	repo := newFakeHoldRepo([]domain.Zone{{ID: "zone-1", EventID: "event-1", Capacity: 10}}, nil)
+	svc := NewHoldService(repo, clock.NewFixed(now), WithPurchaseRights(NewLotteryService(lotteries, clock.NewFixed(now))))
+	ctx := context.Background()
+
+	_, err := svc.CreateHold(ctx, CreateHoldInput{EventID: "event-1", ZoneID: "zone-1", Quantity: 1, IdempotencyKey: "idem-1", CustomerID: "loser"})
+	if err != domain.ErrPurchaseRightRequired {
+		t.Fatalf("expected ErrPurchaseRightRequired, got %v", err)
+	}
+
+	hold, err := svc.CreateHold(ctx, CreateHoldInput{EventID: "event-1", ZoneID: "zone-1", Quantity: 2, IdempotencyKey: "idem-2", CustomerID: "winner"})
+	if err != nil {
+		t.Fatalf("expected the winner's hold, got %v", err)
+	}
+	if b := lotteries.ballot("ballot-1"); b.Status != domain.BallotRedeemed || b.HoldID != hold.ID {
+		t.Fatalf("expected the right to be redeemed by %s, got %+v", hold.ID, b)
+	}
+
+	retry, err := svc.CreateHold(ctx, CreateHoldInput{EventID: "event-1", ZoneID: "zone-1", Quantity: 2, IdempotencyKey: "idem-2", CustomerID: "winner"})
+	if err != nil || retry.ID != hold.ID {
+		t.Fatalf("expected an idempotent retry to return %s, got %+v (%v)", hold.ID, retry, err)
+	}
+}
+
+func TestHoldService_QuantityRules(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	zones := []domain.Zone{
+		{ID: "pairs", EventID: "event-1", Capacity: 100, MinQuantity: 2, MaxQuantity: 8, QuantityStep: 2},
+		{ID: "tables", EventID: "event-1", Capacity: 60, MinQuantity: 6, QuantityStep: 6},
+		{ID: "open", EventID: "event-1", Capacity: 100},
+	}
+
+	tests := []struct {
+		name     string
+		zoneID   string
+		quantity int
+		wantErr  error
+		wantMax  int
+		wantStep int
+	}{
+		{name: "pair", zoneID: "pairs", quantity: 4},
+		{name: "below minimum", zoneID: "pairs", quantity: 1, wantErr: domain.ErrQuantityBelowMinimum, wantMax: 8, wantStep: 2},
+		{name: "above maximum", zoneID: "pairs", quantity: 10, wantErr: domain.ErrQuantityAboveMaximum, wantMax: 8, wantStep: 2},
+		{name: "odd quantity", zoneID: "pairs", quantity: 5, wantErr: domain.ErrQuantityStepMismatch, wantMax: 8, wantStep: 2},
+		{name: "two tables", zoneID: "tables", quantity: 12},
+		{name: "partial table", zoneID: "tables", quantity: 9, wantErr: domain.ErrQuantityStepMismatch, wantStep: 6},
+		{name: "zone without rules", zoneID: "open", quantity: 7},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			repo := newFakeHoldRepo(zones, nil)
+			svc := NewHoldService(repo, clock.NewFixed(now))
+
+			_, err := svc.CreateHold(context.Background(), CreateHoldInput{
+				EventID:        "event-1",
+				ZoneID:         tt.zoneID,
+				Quantity:       tt.quantity,
+				IdempotencyKey: "idem-1",
+			})
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Fatalf("expected hold, got %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected %v, got %v", tt.wantErr, err)
+			}
+			var ruleErr *domain.QuantityRuleError
+			if !errors.As(err, &ruleErr) || ruleErr.Max != tt.wantMax || ruleErr.Step != tt.wantStep {
+				t.Fatalf("expected max %d and step %d, got %+v", tt.wantMax, tt.wantStep, ruleErr)
+			}
+			if len(repo.holds) != 0 {
+				t.Fatalf("expected no hold to be created")
+			}
+		})
+	}
+}
+
+func TestHoldService_PurchaseLimits(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	zones := []domain.Zone{
+		{ID: "zone-1", EventID: "event-1", Capacity: 100},
+		{ID: "zone-2", EventID: "event-1", Capacity: 100},
+	}
+	// cust-1 already has 2 tickets reserved in zone-1 and 3 bought in zone-2.
+	// Expired and released holds no longer count.
+	holds := []domain.Hold{
+		{EventID: "event-1", ZoneID: "zone-1", Quantity: 2, Status: domain.HoldStatusActive, ExpiresAt: now.Add(time.Minute), CustomerID: "cust-1"},
+		{EventID: "event-1", ZoneID: "zone-2", Quantity: 3, Status: domain.HoldStatusConfirmed, CustomerID: "cust-1"},
+		{EventID: "event-1", ZoneID: "zone-1", Quantity: 9, Status: domain.HoldStatusActive, ExpiresAt: now.Add(-time.Minute), CustomerID: "cust-1"},
+		{EventID: "event-1", ZoneID: "zone-1", Quantity: 9, Status: domain.HoldStatusReleased, CustomerID: "cust-1"},
+		{EventID: "event-1", ZoneID: "zone-1", Quantity: 9, Status: domain.HoldStatusActive, ExpiresAt: now.Add(time.Minute), CustomerID: "cust-2"},
+	}
+
+	tests := []struct {
+		name      string
+		perEvent  int
+		perZone   int
+		zoneID    string
+		customer  string
+		quantity  int
+		wantErr   error
+		wantScope string
+		wantLimit int
+		wantLeft  int
+	}{
+		{name: "within both limits", perEvent: 8, perZone: 4, zoneID: "zone-1", customer: "cust-1", quantity: 2},
+		{name: "zone limit", perEvent: 8, perZone: 4, zoneID: "zone-1", customer: "cust-1", quantity: 3, wantScope: domain.PurchaseLimitZone, wantLimit: 4, wantLeft: 2},
+		{name: "event limit across zones", perEvent: 6, perZone: 4, zoneID: "zone-1", customer: "cust-1", quantity: 2, wantScope: domain.PurchaseLimitEvent, wantLimit: 6, wantLeft: 1},
+		{name: "tightest limit is reported", perEvent: 7, perZone: 3, zoneID: "zone-1", customer: "cust-1", quantity: 3, wantScope: domain.PurchaseLimitZone, wantLimit: 3, wantLeft: 1},
+		{name: "limit already used up", perEvent: 5, zoneID: "zone-2", customer: "cust-1", quantity: 1, wantScope: domain.PurchaseLimitEvent, wantLimit: 5, wantLeft: 0},
+		{name: "other customers do not count", perEvent: 4, zoneID: "zone-1", customer: "cust-3", quantity: 4},
+		{name: "anonymous holds need sign-in on limited events", perEvent: 1, zoneID: "zone-1", quantity: 1, wantErr: domain.ErrSignInRequired},
+		{name: "anonymous holds need sign-in on limited zones", perZone: 1, zoneID: "zone-1", quantity: 1, wantErr: domain.ErrSignInRequired},
+		{name: "anonymous holds on unlimited events", zoneID: "zone-1", quantity: 10},
+		{name: "no limits configured", zoneID: "zone-1", customer: "cust-1", quantity: 50},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			limited := make([]domain.Zone, 0, len(zones))
+			for _, z := range zones {
+				z.PurchaseLimit = tt.perZone
+				limited = append(limited, z)
+			}
+			repo := newFakeHoldRepo(limited, holds)
+			repo.eventLimits = map[string]int{"event-1": tt.perEvent}
+			svc := NewHoldService(repo, clock.NewFixed(now))
+
+			_, err := svc.CreateHold(context.Background(), CreateHoldInput{
+				EventID:        "event-1",
+				ZoneID:         tt.zoneID,
+				Quantity:       tt.quantity,
+				IdempotencyKey: "idem-new",
+				CustomerID:     tt.customer,
+			})
+			if tt.wantErr != nil {
+				if err != tt.wantErr {
+					t.Fatalf("expected %v, got %v", tt.wantErr, err)
+				}
+				if len(repo.holds) != len(holds) {
+					t.Fatalf("expected no hold to be created")
+				}
+				return
+			}
+			if tt.wantScope == "" {
+				if err != nil {
+					t.Fatalf("expected hold, got %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, domain.ErrPurchaseLimitExceeded) {
+				t.Fatalf("expected ErrPurchaseLimitExceeded, got %v", err)
+			}
+			var limitErr *domain.PurchaseLimitError
+			if !errors.As(err, &limitErr) || limitErr.Scope != tt.wantScope || limitErr.Limit != tt.wantLimit || limitErr.Remaining != tt.wantLeft {
+				t.Fatalf("expected %s limit %d with %d left, got %+v", tt.wantScope, tt.wantLimit, tt.wantLeft, limitErr)
+			}
+			if len(repo.locked) != 1 || repo.locked[0] != tt.customer {
+				t.Fatalf("expected the customer to be locked, got %v", repo.locked)
+			}
+			if len(repo.holds) != len(holds) || len(repo.outbox) != 0 {
+				t.Fatalf("expected no hold to be created")
+			}
+		})
+	}
+}
+
 type fakeHoldRepo struct {
-	zones map[string]domain.Zone
-	holds []domain.Hold
+	zones  map[string]domain.Zone
+	holds  []domain.Hold
+	outbox []domain.OutboxEvent
+	locked []string
+	rooms  map[string]domain.WaitingRoom
+	// eventLimits holds per-event purchase limits; missing events are unlimited.
+	eventLimits map[string]int
 }
 
 func newFakeHoldRepo(zones []domain.Zone, holds []domain.Hold) *fakeHoldRepo {
@@ -229,7 +565,7 @@ func (f *fakeHoldRepo) SumActiveHolds(_ context.Context, eventID, zoneID string,
 		if h.Status != domain.HoldStatusActive {
 			continue
 		}
-		if !h.ExpiresAt.After(now) {
+		if !h.ReservedUntil().After(now) {
 			continue
 		}
 		total += h.Quantity
@@ -251,11 +587,146 @@ func (f *fakeHoldRepo) SumConfirmed(_ context.Context, eventID, zoneID string) (
 	return total, nil
 }
 
+func (f *fakeHoldRepo) GetWaitingRoom(_ context.Context, eventID string) (domain.WaitingRoom, error) {
+	return f.rooms[eventID], nil
+}
+
+func (f *fakeHoldRepo) GetEventPurchaseLimit(_ context.Context, eventID string) (int, error) {
+	return f.eventLimits[eventID], nil
+}
+
+func (f *fakeHoldRepo) LockCustomer(_ context.Context, customerID string) error {
+	f.locked = append(f.locked, customerID)
+	return nil
+}
+
+func (f *fakeHoldRepo) SumCustomerTickets(_ context.Context, customerID, eventID, zoneID string, now time.Time) (int, int, error) {
+	var eventQty, zoneQty int
+	for _, h := range f.holds {
+		if h.CustomerID != customerID || h.EventID != eventID {
+			continue
+		}
+		if h.Status != domain.HoldStatusConfirmed && (h.Status != domain.HoldStatusActive || !h.ReservedUntil().After(now)) {
+			continue
+		}
+		eventQty += h.Quantity
+		if h.ZoneID == zoneID {
+			zoneQty += h.Quantity
+		}
+	}
+	return eventQty, zoneQty, nil
+}
+
+func (f *fakeHoldRepo) LockQueueEntry(_ context.Context, entryID string) error {
+	f.locked = append(f.locked, entryID)
+	return nil
+}
+
+func (f *fakeHoldRepo) HasQueueEntryHold(_ context.Context, entryID string, now time.Time) (bool, error) {
+	for _, h := range f.holds {
+		if h.QueueEntryID != entryID {
+			continue
+		}
+		if h.Status == domain.HoldStatusConfirmed || (h.Status == domain.HoldStatusActive && h.ReservedUntil().After(now)) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
 func (f *fakeHoldRepo) CreateHold(_ context.Context, hold domain.Hold) error {
 	f.holds = append(f.holds, hold)
 	return nil
 }
 
+func (f *fakeHoldRepo) ExpireHolds(_ context.Context, now time.Time, limit int) ([]domain.Hold, error) {
+	var expired []domain.Hold
+	for i := range f.holds {
+		if len(expired) == limit {
+			break
+		}
+		if f.holds[i].Status != domain.HoldStatusActive || f.holds[i].ReservedUntil().After(now) {
+			continue
+		}
+		f.holds[i].Status = domain.HoldStatusExpired
+		f.holds[i].PaymentPendingUntil = nil
+		expired = append(expired, f.holds[i])
+	}
+	return expired, nil
+}
+
+func (f *fakeHoldRepo) AppendOutboxEvent(_ context.Context, event domain.OutboxEvent) error {
+	f.outbox = append(f.outbox, event)
+	return nil
+}
+
 func zoneKey(eventID, zoneID string) string {
 	return eventID + "|" + zoneID
 }
+
+func TestHoldService_ExpireHolds(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	pendingUntil := now.Add(time.Minute)
+	repo := newFakeHoldRepo(nil, []domain.Hold{
+		{ID: "lapsed", Status: domain.HoldStatusActive, ExpiresAt: now.Add(-time.Minute)},
+		{ID: "live", Status: domain.HoldStatusActive, ExpiresAt: now.Add(time.Minute)},
+		{ID: "paying", Status: domain.HoldStatusActive, ExpiresAt: now.Add(-time.Minute), PaymentPendingUntil: &pendingUntil},
+		{ID: "confirmed", Status: domain.HoldStatusConfirmed, ExpiresAt: now.Add(-time.Minute)},
+	})
+	svc := NewHoldService(repo, clock.NewFixed(now))
+
+	n, err := svc.ExpireHolds(context.Background())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if n != 1 {
+		t.Fatalf("expected 1 expired hold, got %d", n)
+	}
+	if repo.holds[0].Status != domain.HoldStatusExpired || repo.holds[2].Status != domain.HoldStatusActive {
+		t.Fatalf("unexpected statuses: %+v", repo.holds)
+	}
+	if len(repo.outbox) != 1 || repo.outbox[0].Type != domain.OutboxHoldExpired || repo.outbox[0].AggregateID != "lapsed" {
+		t.Fatalf("expected hold.expired outbox event, got %+v", repo.outbox)
+	}
+}
+
+type recordingSpanExporter struct {
+	spans []tracing.SpanData
+}
+
+func (r *recordingSpanExporter) Export(_ context.Context, spans []tracing.SpanData) error {
+	r.spans = append(r.spans, spans...)
+	return nil
+}
+
+func TestHoldService_Tracing(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	repo := newFakeHoldRepo([]domain.Zone{{ID: "zone-1", EventID: "event-1", Capacity: 1}}, nil)
+	svc := NewHoldService(repo, clock.NewFixed(now))
+	exp := &recordingSpanExporter{}
+	tracer := tracing.NewTracer(exp, clock.NewFixed(now))
+
+	ctx, root := tracer.StartRemote(context.Background(), "POST /holds", tracing.SpanKindServer, tracing.SpanContext{})
+	if _, err := svc.CreateHold(ctx, CreateHoldInput{EventID: "event-1", ZoneID: "zone-1", Quantity: 2, IdempotencyKey: "idem-1"}); err == nil {
+		t.Fatal("expected insufficient capacity")
+	}
+	root.End()
+	if _, err := tracer.Flush(context.Background()); err != nil {
+		t.Fatalf("flush: %v", err)
+	}
+
+	if len(exp.spans) != 2 {
+		t.Fatalf("expected 2 spans, got %d", len(exp.spans))
+	}
+	span := exp.spans[0]
+	if span.Name != "HoldService.CreateHold" || span.ParentID != root.Context().SpanID {
+		t.Fatalf("unexpected span %+v", span)
+	}
+	if span.Error != domain.ErrInsufficientCapacity.Error() {
+		t.Fatalf("expected the span to carry the error, got %q", span.Error)
+	}
+}
@@ -0,0 +1,405 @@
+package app
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cimillas/ultimate-ticket/services/api/internal/clock"
+	"github.com/cimillas/ultimate-ticket/services/api/internal/domain"
+)
+
+// LotteryRepository stores lotteries and their ballots.
+type LotteryRepository interface {
+	WithTx(ctx context.Context, fn func(ctx context.Context) error) error
+	// GetZone returns the zone with the organizer of its event.
+	GetZone(ctx context.Context, zoneID string) (domain.Zone, string, error)
+	// ZoneAvailable returns the zone's capacity minus active and confirmed holds.
+	ZoneAvailable(ctx context.Context, zoneID string, now time.Time) (int, error)
+	// CreateLottery returns ErrLotteryExists when the zone already has one.
+	CreateLottery(ctx context.Context, lottery domain.Lottery) error
+	// GetLottery fills in OrganizerID from the lottery's event.
+	GetLottery(ctx context.Context, id string) (domain.Lottery, error)
+	GetLotteryForUpdate(ctx context.Context, id string) (domain.Lottery, error)
+	// GetLotteryByZone returns nil when the zone is not sold by lottery.
+	GetLotteryByZone(ctx context.Context, zoneID string) (*domain.Lottery, error)
+	UpdateLottery(ctx context.Context, lottery domain.Lottery) error
+	// ListRolloverLotteries returns drawn lotteries with offers outstanding or
+	// runners-up waiting.
+	ListRolloverLotteries(ctx context.Context) ([]string, error)
+	// CreateBallot returns ErrBallotExists when the customer already entered.
+	CreateBallot(ctx context.Context, ballot domain.Ballot) error
+	GetBallot(ctx context.Context, lotteryID, customerID string) (domain.Ballot, error)
+	GetBallotForUpdate(ctx context.Context, lotteryID, customerID string) (domain.Ballot, error)
+	// ListBallots returns the lottery's ballots with the given status, in
+	// rank order.
+	ListBallots(ctx context.Context, lotteryID string, status domain.BallotStatus) ([]domain.Ballot, error)
+	UpdateBallot(ctx context.Context, ballot domain.Ballot) error
+	// SumOffered returns the tickets reserved by outstanding offers.
+	SumOffered(ctx context.Context, lotteryID string) (int, error)
+	// LapseOffers marks offers that expired by now as lapsed.
+	LapseOffers(ctx context.Context, lotteryID string, now time.Time) (int, error)
+	AppendAuditEntry(ctx context.Context, entry domain.AuditEntry) error
+}
+
+type LotteryService struct {
+	repo  LotteryRepository
+	clock clock.Clock
+}
+
+func NewLotteryService(repo LotteryRepository, clk clock.Clock) *LotteryService {
+	return &LotteryService{repo: repo, clock: clk}
+}
+
+type CreateLotteryInput struct {
+	OrganizerID          string
+	ZoneID               string
+	RegistrationOpensAt  time.Time
+	RegistrationClosesAt time.Time
+	// ClaimWindow is how long a winner has to buy before the right rolls over.
+	ClaimWindow time.Duration
+	// SeedHash optionally commits to a seed the organizer reveals at the
+	// draw. When empty, the server draws the seed now and keeps it secret.
+	SeedHash string
+}
+
+// CreateLottery puts a zone on ballot sale. From then on holds for the zone
+// need a purchase right. The lottery commits to its draw seed up front: its
+// SeedHash is published from creation, so the seed cannot be picked once the
+// ballots are known.
+func (s *LotteryService) CreateLottery(ctx context.Context, in CreateLotteryInput) (domain.Lottery, error) {
+	if in.ZoneID == "" {
+		return domain.Lottery{}, domain.ErrInvalidID
+	}
+	if !in.RegistrationClosesAt.After(in.RegistrationOpensAt) || in.ClaimWindow < time.Second {
+		return domain.Lottery{}, domain.ErrInvalidLotteryWindow
+	}
+	var seed string
+	seedHash := strings.ToLower(in.SeedHash)
+	if seedHash == "" {
+		var err error
+		if seed, err = newSeed(); err != nil {
+			return domain.Lottery{}, err
+		}
+		seedHash = domain.HashLotterySeed(seed)
+	} else if b, err := hex.DecodeString(seedHash); err != nil || len(b) != sha256.Size {
+		return domain.Lottery{}, domain.ErrInvalidSeedHash
+	}
+	zone, organizerID, err := s.repo.GetZone(ctx, in.ZoneID)
+	if err != nil {
+		return domain.Lottery{}, err
+	}
+	if organizerID != in.OrganizerID {
+		return domain.Lottery{}, domain.ErrZoneNotFound
+	}
+
+	lottery := domain.Lottery{
+		ID:                   newUUID(),
+		EventID:              zone.EventID,
+		ZoneID:               zone.ID,
+		OrganizerID:          organizerID,
+		RegistrationOpensAt:  in.RegistrationOpensAt,
+		RegistrationClosesAt: in.RegistrationClosesAt,
+		ClaimWindow:          in.ClaimWindow.Truncate(time.Second),
+		Status:               domain.LotteryOpen,
+		SeedHash:             seedHash,
+		Seed:                 seed,
+		CreatedAt:            s.clock.Now(),
+	}
+	err = s.repo.WithTx(ctx, func(txCtx context.Context) error {
+		if err := s.repo.CreateLottery(txCtx, lottery); err != nil {
+			return err
+		}
+		return s.audit(txCtx, lottery, domain.AuditLotteryCreated, nil, newLotterySnapshot(lottery))
+	})
+	if err != nil {
+		return domain.Lottery{}, err
+	}
+	return lottery, nil
+}
+
+// GetLottery returns one of the organizer's lotteries.
+func (s *LotteryService) GetLottery(ctx context.Context, organizerID, lotteryID string) (domain.Lottery, error) {
+	if lotteryID == "" {
+		return domain.Lottery{}, domain.ErrInvalidID
+	}
+	lottery, err := s.repo.GetLottery(ctx, lotteryID)
+	if err != nil {
+		return domain.Lottery{}, err
+	}
+	if lottery.OrganizerID != organizerID {
+		return domain.Lottery{}, domain.ErrLotteryNotFound
+	}
+	return lottery, nil
+}
+
+// Enter records a customer's ballot for quantity tickets while registration
+// is open. Each customer may enter a lottery once.
+func (s *LotteryService) Enter(ctx context.Context, lotteryID, customerID string, quantity int) (domain.Ballot, error) {
+	if lotteryID == "" {
+		return domain.Ballot{}, domain.ErrInvalidID
+	}
+	if quantity <= 0 {
+		return domain.Ballot{}, domain.ErrInvalidQuantity
+	}
+	now := s.clock.Now()
+	lottery, err := s.repo.GetLottery(ctx, lotteryID)
+	if err != nil {
+		return domain.Ballot{}, err
+	}
+	if !lottery.RegistrationOpen(now) {
+		return domain.Ballot{}, domain.ErrRegistrationClosed
+	}
+	zone, _, err := s.repo.GetZone(ctx, lottery.ZoneID)
+	if err != nil {
+		return domain.Ballot{}, err
+	}
+	if err := zone.CheckQuantity(quantity); err != nil {
+		return domain.Ballot{}, err
+	}
+
+	ballot := domain.Ballot{
+		ID:         newUUID(),
+		LotteryID:  lottery.ID,
+		CustomerID: customerID,
+		Quantity:   quantity,
+		Status:     domain.BallotEntered,
+		CreatedAt:  now,
+	}
+	if err := s.repo.CreateBallot(ctx, ballot); err != nil {
+		return domain.Ballot{}, err
+	}
+	return ballot, nil
+}
+
+// GetBallot returns the customer's ballot. An offer that ran out is reported
+// as lapsed even before the rollover job records it.
+func (s *LotteryService) GetBallot(ctx context.Context, lotteryID, customerID string) (domain.Ballot, error) {
+	if lotteryID == "" {
+		return domain.Ballot{}, domain.ErrInvalidID
+	}
+	ballot, err := s.repo.GetBallot(ctx, lotteryID, customerID)
+	if err != nil {
+		return domain.Ballot{}, err
+	}
+	if ballot.Status == domain.BallotOffered && !s.clock.Now().Before(*ballot.OfferExpiresAt) {
+		ballot.Status = domain.BallotLapsed
+	}
+	return ballot, nil
+}
+
+// DrawResult summarizes a draw.
+type DrawResult struct {
+	Lottery domain.Lottery
+	Ballots int
+	Offered int
+}
+
+// Draw ranks every ballot with a seeded shuffle and offers purchase rights in
+// rank order while the zone has tickets. The seed is the one committed when
+// the lottery was created: the server's own, or the one the organizer reveals
+// now, which must hash to the lottery's SeedHash (ErrLotterySeedMismatch
+// otherwise). The same seed and ballots always give the same ranking: ballots
+// are ordered by SHA-256 of "<seed>:<ballot id>".
+func (s *LotteryService) Draw(ctx context.Context, organizerID, lotteryID, seed string) (DrawResult, error) {
+	if lotteryID == "" {
+		return DrawResult{}, domain.ErrInvalidID
+	}
+
+	var result DrawResult
+	err := s.repo.WithTx(ctx, func(txCtx context.Context) error {
+		lottery, err := s.repo.GetLotteryForUpdate(txCtx, lotteryID)
+		if err != nil {
+			return err
+		}
+		if lottery.OrganizerID != organizerID {
+			return domain.ErrLotteryNotFound
+		}
+		if lottery.Status == domain.LotteryDrawn {
+			return domain.ErrLotteryAlreadyDrawn
+		}
+		now := s.clock.Now()
+		if now.Before(lottery.RegistrationClosesAt) {
+			return domain.ErrRegistrationStillOpen
+		}
+		if seed == "" {
+			seed = lottery.Seed
+		}
+		if seed == "" || domain.HashLotterySeed(seed) != lottery.SeedHash {
+			return domain.ErrLotterySeedMismatch
+		}
+
+		ballots, err := s.repo.ListBallots(txCtx, lottery.ID, domain.BallotEntered)
+		if err != nil {
+			return err
+		}
+		for i, ballot := range RankBallots(seed, ballots) {
+			ballot.Rank = i + 1
+			ballot.Status = domain.BallotWaitlisted
+			if err := s.repo.UpdateBallot(txCtx, ballot); err != nil {
+				return err
+			}
+		}
+
+		before := lottery
+		lottery.Status = domain.LotteryDrawn
+		lottery.Seed = seed
+		lottery.DrawnAt = &now
+		if err := s.repo.UpdateLottery(txCtx, lottery); err != nil {
+			return err
+		}
+		offered, err := s.offerRights(txCtx, lottery, now)
+		if err != nil {
+			return err
+		}
+		result = DrawResult{Lottery: lottery, Ballots: len(ballots), Offered: offered}
+		return s.audit(txCtx, lottery, domain.AuditLotteryDrawn, newLotterySnapshot(before), newLotterySnapshot(lottery))
+	})
+	if err != nil {
+		return DrawResult{}, err
+	}
+	return result, nil
+}
+
+// RankBallots returns ballots in draw order for seed.
+func RankBallots(seed string, ballots []domain.Ballot) []domain.Ballot {
+	keys := make(map[string]string, len(ballots))
+	for _, b := range ballots {
+		sum := sha256.Sum256([]byte(seed + ":" + b.ID))
+		keys[b.ID] = hex.EncodeToString(sum[:])
+	}
+	ranked := append([]domain.Ballot(nil), ballots...)
+	sort.Slice(ranked, func(i, j int) bool {
+		return keys[ranked[i].ID] < keys[ranked[j].ID]
+	})
+	return ranked
+}
+
+// RolloverDue lapses purchase rights that were not used in time and offers
+// the freed tickets to the next runners-up. It returns how many rights were
+// offered.
+func (s *LotteryService) RolloverDue(ctx context.Context) (int, error) {
+	lotteryIDs, err := s.repo.ListRolloverLotteries(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	offered := 0
+	for _, lotteryID := range lotteryIDs {
+		err := s.repo.WithTx(ctx, func(txCtx context.Context) error {
+			lottery, err := s.repo.GetLotteryForUpdate(txCtx, lotteryID)
+			if err != nil {
+				return err
+			}
+			now := s.clock.Now()
+			if _, err := s.repo.LapseOffers(txCtx, lottery.ID, now); err != nil {
+				return err
+			}
+			n, err := s.offerRights(txCtx, lottery, now)
+			if err != nil {
+				return err
+			}
+			offered += n
+			return nil
+		})
+		if err != nil {
+			return offered, err
+		}
+	}
+	return offered, nil
+}
+
+// offerRights offers purchase rights to waitlisted ballots in rank order
+// while the zone has tickets not already promised to outstanding offers. A
+// ballot too large for what is left is passed over but keeps its place for
+// later rollovers.
+func (s *LotteryService) offerRights(ctx context.Context, lottery domain.Lottery, now time.Time) (int, error) {
+	available, err := s.repo.ZoneAvailable(ctx, lottery.ZoneID, now)
+	if err != nil {
+		return 0, err
+	}
+	promised, err := s.repo.SumOffered(ctx, lottery.ID)
+	if err != nil {
+		return 0, err
+	}
+	free := available - promised
+	if free <= 0 {
+		return 0, nil
+	}
+
+	waitlist, err := s.repo.ListBallots(ctx, lottery.ID, domain.BallotWaitlisted)
+	if err != nil {
+		return 0, err
+	}
+	expiresAt := now.Add(lottery.ClaimWindow)
+	offered := 0
+	for _, ballot := range waitlist {
+		if free <= 0 {
+			break
+		}
+		if ballot.Quantity > free {
+			continue
+		}
+		ballot.Status = domain.BallotOffered
+		ballot.OfferExpiresAt = &expiresAt
+		if err := s.repo.UpdateBallot(ctx, ballot); err != nil {
+			return offered, err
+		}
+		free -= ballot.Quantity
+		offered++
+	}
+	return offered, nil
+}
+
+// RedeemPurchaseRight lets a hold through when its zone is not sold by
+// lottery, or uses up the customer's purchase right. It runs inside the hold's
+// transaction, so a failed hold leaves the right unused.
+func (s *LotteryService) RedeemPurchaseRight(ctx context.Context, zoneID, customerID string, quantity int, holdID string, now time.Time) error {
+	lottery, err := s.repo.GetLotteryByZone(ctx, zoneID)
+	if err != nil {
+		return err
+	}
+	if lottery == nil {
+		return nil
+	}
+	if customerID == "" {
+		return domain.ErrPurchaseRightRequired
+	}
+	ballot, err := s.repo.GetBallotForUpdate(ctx, lottery.ID, customerID)
+	if err == domain.ErrBallotNotFound {
+		return domain.ErrPurchaseRightRequired
+	}
+	if err != nil {
+		return err
+	}
+	if ballot.Status != domain.BallotOffered || !now.Before(*ballot.OfferExpiresAt) {
+		return domain.ErrPurchaseRightRequired
+	}
+	if quantity > ballot.Quantity {
+		return domain.ErrPurchaseRightExceeded
+	}
+	ballot.Status = domain.BallotRedeemed
+	ballot.HoldID = holdID
+	return s.repo.UpdateBallot(ctx, ballot)
+}
+
+func (s *LotteryService) audit(ctx context.Context, lottery domain.Lottery, action domain.AuditAction, before, after any) error {
+	entry, err := newAuditEntry(ctx, lottery.OrganizerID, action, domain.AuditTargetLottery, lottery.ID, before, after, s.clock.Now())
+	if err != nil {
+		return err
+	}
+	return s.repo.AppendAuditEntry(ctx, entry)
+}
+
+func newSeed() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generate lottery seed: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,521 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cimillas/ultimate-ticket/services/api/internal/clock"
+	"github.com/cimillas/ultimate-ticket/services/api/internal/domain"
+)
+
+func TestLotteryService_CreateLottery(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		in      CreateLotteryInput
+		wantErr error
+	}{
+		{
+			name: "creates",
+			in:   CreateLotteryInput{OrganizerID: "org-1", ZoneID: "zone-1", RegistrationOpensAt: now, RegistrationClosesAt: now.Add(time.Hour), ClaimWindow: 30 * time.Minute},
+		},
+		{
+			name:    "closes before it opens",
+			in:      CreateLotteryInput{OrganizerID: "org-1", ZoneID: "zone-1", RegistrationOpensAt: now, RegistrationClosesAt: now, ClaimWindow: 30 * time.Minute},
+			wantErr: domain.ErrInvalidLotteryWindow,
+		},
+		{
+			name:    "no claim window",
+			in:      CreateLotteryInput{OrganizerID: "org-1", ZoneID: "zone-1", RegistrationOpensAt: now, RegistrationClosesAt: now.Add(time.Hour)},
+			wantErr: domain.ErrInvalidLotteryWindow,
+		},
+		{
+			name:    "zone of another organizer",
+			in:      CreateLotteryInput{OrganizerID: "org-2", ZoneID: "zone-1", RegistrationOpensAt: now, RegistrationClosesAt: now.Add(time.Hour), ClaimWindow: time.Minute},
+			wantErr: domain.ErrZoneNotFound,
+		},
+		{
+			name: "organizer commits to a seed",
+			in: CreateLotteryInput{OrganizerID: "org-1", ZoneID: "zone-1", RegistrationOpensAt: now, RegistrationClosesAt: now.Add(time.Hour), ClaimWindow: time.Minute,
+				SeedHash: strings.ToUpper(domain.HashLotterySeed("organizer-seed"))},
+		},
+		{
+			name: "seed hash is not a digest",
+			in: CreateLotteryInput{OrganizerID: "org-1", ZoneID: "zone-1", RegistrationOpensAt: now, RegistrationClosesAt: now.Add(time.Hour), ClaimWindow: time.Minute,
+				SeedHash: "organizer-seed"},
+			wantErr: domain.ErrInvalidSeedHash,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			repo := newFakeLotteryRepo()
+			svc := NewLotteryService(repo, clock.NewFixed(now))
+
+			lottery, err := svc.CreateLottery(context.Background(), tt.in)
+			if err != tt.wantErr {
+				t.Fatalf("expected %v, got %v", tt.wantErr, err)
+			}
+			if err != nil {
+				return
+			}
+			if lottery.Status != domain.LotteryOpen || lottery.EventID != "event-1" || len(repo.audit) != 1 {
+				t.Fatalf("unexpected lottery %+v (audit %d)", lottery, len(repo.audit))
+			}
+			if tt.in.SeedHash == "" {
+				// The server commits to a seed of its own and keeps it until the draw.
+				if lottery.Seed == "" || lottery.SeedHash != domain.HashLotterySeed(lottery.Seed) {
+					t.Fatalf("expected a committed server seed, got %+v", lottery)
+				}
+				if strings.Contains(string(repo.audit[0].After), lottery.Seed) {
+					t.Fatalf("expected the audit snapshot to keep the seed secret, got %s", repo.audit[0].After)
+				}
+			} else if lottery.Seed != "" || lottery.SeedHash != domain.HashLotterySeed("organizer-seed") {
+				t.Fatalf("expected the organizer's commitment, got %+v", lottery)
+			}
+			if lottery.PublishedSeed() != "" {
+				t.Fatalf("expected no seed to be published before the draw, got %q", lottery.PublishedSeed())
+			}
+			if _, err := svc.CreateLottery(context.Background(), tt.in); err != domain.ErrLotteryExists {
+				t.Fatalf("expected ErrLotteryExists, got %v", err)
+			}
+		})
+	}
+}
+
+func TestLotteryService_Enter(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	repo := newFakeLotteryRepo()
+	repo.zones["zone-1"] = domain.Zone{ID: "zone-1", EventID: "event-1", Capacity: 10, MaxQuantity: 4}
+	repo.lotteries["open"] = domain.Lottery{ID: "open", ZoneID: "zone-1", Status: domain.LotteryOpen, RegistrationOpensAt: now.Add(-time.Hour), RegistrationClosesAt: now.Add(time.Hour)}
+	repo.lotteries["closed"] = domain.Lottery{ID: "closed", ZoneID: "zone-1", Status: domain.LotteryOpen, RegistrationOpensAt: now.Add(-2 * time.Hour), RegistrationClosesAt: now}
+	svc := NewLotteryService(repo, clock.NewFixed(now))
+	ctx := context.Background()
+
+	ballot, err := svc.Enter(ctx, "open", "cust-1", 2)
+	if err != nil {
+		t.Fatalf("enter: %v", err)
+	}
+	if ballot.Status != domain.BallotEntered || ballot.Quantity != 2 {
+		t.Fatalf("unexpected ballot %+v", ballot)
+	}
+	if _, err := svc.Enter(ctx, "open", "cust-1", 1); err != domain.ErrBallotExists {
+		t.Fatalf("expected ErrBallotExists, got %v", err)
+	}
+	if _, err := svc.Enter(ctx, "closed", "cust-2", 1); err != domain.ErrRegistrationClosed {
+		t.Fatalf("expected ErrRegistrationClosed, got %v", err)
+	}
+	if _, err := svc.Enter(ctx, "open", "cust-2", 5); err == nil {
+		t.Fatal("expected the zone's quantity rules to apply")
+	}
+	if _, err := svc.Enter(ctx, "missing", "cust-2", 1); err != domain.ErrLotteryNotFound {
+		t.Fatalf("expected ErrLotteryNotFound, got %v", err)
+	}
+}
+
+func TestLotteryService_Draw(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	newRepo := func() *fakeLotteryRepo {
+		repo := newFakeLotteryRepo()
+		repo.available["zone-1"] = 5
+		repo.lotteries["lottery-1"] = domain.Lottery{
+			ID: "lottery-1", ZoneID: "zone-1", OrganizerID: "org-1", Status: domain.LotteryOpen,
+			RegistrationOpensAt: now.Add(-2 * time.Hour), RegistrationClosesAt: now.Add(-time.Hour), ClaimWindow: 30 * time.Minute,
+			SeedHash: domain.HashLotterySeed("seed-1"),
+		}
+		for i, qty := range []int{2, 2, 2, 1, 3, 1} {
+			repo.ballots = append(repo.ballots, domain.Ballot{
+				ID: fmt.Sprintf("ballot-%d", i), LotteryID: "lottery-1", CustomerID: fmt.Sprintf("cust-%d", i),
+				Quantity: qty, Status: domain.BallotEntered,
+			})
+		}
+		return repo
+	}
+
+	for _, seed := range []string{"", "seed-2"} {
+		if _, err := NewLotteryService(newRepo(), clock.NewFixed(now)).Draw(context.Background(), "org-1", "lottery-1", seed); err != domain.ErrLotterySeedMismatch {
+			t.Fatalf("seed %q: expected ErrLotterySeedMismatch, got %v", seed, err)
+		}
+	}
+
+	repo := newRepo()
+	svc := NewLotteryService(repo, clock.NewFixed(now))
+	result, err := svc.Draw(context.Background(), "org-1", "lottery-1", "seed-1")
+	if err != nil {
+		t.Fatalf("draw: %v", err)
+	}
+	if result.Ballots != 6 || result.Lottery.Status != domain.LotteryDrawn || result.Lottery.Seed != "seed-1" {
+		t.Fatalf("unexpected result %+v", result)
+	}
+
+	// Rights go out in rank order until the five tickets are promised;
+	// ballots too large for what is left are passed over.
+	ranked := repo.byRank()
+	free, offered := 5, 0
+	for _, b := range ranked {
+		want := domain.BallotWaitlisted
+		if b.Quantity <= free {
+			want = domain.BallotOffered
+			free -= b.Quantity
+			offered++
+		}
+		if b.Status != want {
+			t.Fatalf("expected rank %d (%d tickets) to be %s, got %s", b.Rank, b.Quantity, want, b.Status)
+		}
+		if want == domain.BallotOffered && !b.OfferExpiresAt.Equal(now.Add(30*time.Minute)) {
+			t.Fatalf("expected offer to expire after the claim window, got %v", b.OfferExpiresAt)
+		}
+	}
+	if result.Offered != offered {
+		t.Fatalf("expected %d offered, got %d", offered, result.Offered)
+	}
+
+	// The same seed reproduces the ranking.
+	again := newRepo()
+	if _, err := NewLotteryService(again, clock.NewFixed(now)).Draw(context.Background(), "org-1", "lottery-1", "seed-1"); err != nil {
+		t.Fatalf("draw: %v", err)
+	}
+	for i, b := range again.byRank() {
+		if b.ID != ranked[i].ID {
+			t.Fatalf("expected the same ranking for the same seed, got %s at rank %d instead of %s", b.ID, i+1, ranked[i].ID)
+		}
+	}
+
+	if _, err := svc.Draw(context.Background(), "org-1", "lottery-1", ""); err != domain.ErrLotteryAlreadyDrawn {
+		t.Fatalf("expected ErrLotteryAlreadyDrawn, got %v", err)
+	}
+	if _, err := NewLotteryService(newRepo(), clock.NewFixed(now)).Draw(context.Background(), "org-2", "lottery-1", ""); err != domain.ErrLotteryNotFound {
+		t.Fatalf("expected ErrLotteryNotFound for another organizer, got %v", err)
+	}
+	early := NewLotteryService(newRepo(), clock.NewFixed(now.Add(-90*time.Minute)))
+	if _, err := early.Draw(context.Background(), "org-1", "lottery-1", ""); err != domain.ErrRegistrationStillOpen {
+		t.Fatalf("expected ErrRegistrationStillOpen, got %v", err)
+	}
+
+	// A server-held seed is revealed by the draw and cannot be replaced.
+	held := newRepo()
+	lottery := held.lotteries["lottery-1"]
+	lottery.Seed, lottery.SeedHash = "server-seed", domain.HashLotterySeed("server-seed")
+	held.lotteries["lottery-1"] = lottery
+	heldSvc := NewLotteryService(held, clock.NewFixed(now))
+	if _, err := heldSvc.Draw(context.Background(), "org-1", "lottery-1", "seed-1"); err != domain.ErrLotterySeedMismatch {
+		t.Fatalf("expected ErrLotterySeedMismatch for another seed, got %v", err)
+	}
+	result, err = heldSvc.Draw(context.Background(), "org-1", "lottery-1", "")
+	if err != nil || result.Lottery.PublishedSeed() != "server-seed" {
+		t.Fatalf("expected the server seed to be published, got %+v (%v)", result.Lottery, err)
+	}
+}
+
+func TestRankBallots_DependsOnSeed(t *testing.T) {
+	t.Parallel()
+
+	var ballots []domain.Ballot
+	for i := 0; i < 20; i++ {
+		ballots = append(ballots, domain.Ballot{ID: fmt.Sprintf("ballot-%d", i)})
+	}
+	a, b := RankBallots("seed-a", ballots), RankBallots("seed-b", ballots)
+	same := true
+	for i := range a {
+		if a[i].ID != b[i].ID {
+			same = false
+		}
+	}
+	if same {
+		t.Fatal("expected different seeds to give different rankings")
+	}
+	if ballots[0].ID != "ballot-0" {
+		t.Fatal("expected the input to be left in place")
+	}
+}
+
+func TestLotteryService_RolloverDue(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	expired := now.Add(-time.Minute)
+	valid := now.Add(time.Minute)
+	repo := newFakeLotteryRepo()
+	repo.lotteries["lottery-1"] = domain.Lottery{ID: "lottery-1", ZoneID: "zone-1", Status: domain.LotteryDrawn, ClaimWindow: 10 * time.Minute}
+	// Four tickets left: two promised to a valid offer, two freed by a lapsed one.
+	repo.available["zone-1"] = 4
+	repo.ballots = []domain.Ballot{
+		{ID: "winner", LotteryID: "lottery-1", Quantity: 2, Rank: 1, Status: domain.BallotOffered, OfferExpiresAt: &valid},
+		{ID: "no-show", LotteryID: "lottery-1", Quantity: 2, Rank: 2, Status: domain.BallotOffered, OfferExpiresAt: &expired},
+		{ID: "too-many", LotteryID: "lottery-1", Quantity: 3, Rank: 3, Status: domain.BallotWaitlisted},
+		{ID: "runner-up", LotteryID: "lottery-1", Quantity: 2, Rank: 4, Status: domain.BallotWaitlisted},
+		{ID: "last", LotteryID: "lottery-1", Quantity: 1, Rank: 5, Status: domain.BallotWaitlisted},
+	}
+	svc := NewLotteryService(repo, clock.NewFixed(now))
+
+	n, err := svc.RolloverDue(context.Background())
+	if err != nil {
+		t.Fatalf("rollover: %v", err)
+	}
+	if n != 1 {
+		t.Fatalf("expected 1 right offered, got %d", n)
+	}
+	for id, want := range map[string]domain.BallotStatus{
+		"winner":    domain.BallotOffered,
+		"no-show":   domain.BallotLapsed,
+		"too-many":  domain.BallotWaitlisted,
+		"runner-up": domain.BallotOffered,
+		"last":      domain.BallotWaitlisted,
+	} {
+		if got := repo.ballot(id).Status; got != want {
+			t.Fatalf("expected %s to be %s, got %s", id, want, got)
+		}
+	}
+	if got := repo.ballot("runner-up").OfferExpiresAt; !got.Equal(now.Add(10 * time.Minute)) {
+		t.Fatalf("expected the runner-up's offer to expire after the claim window, got %v", got)
+	}
+}
+
+func TestLotteryService_RedeemPurchaseRight(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	valid := now.Add(time.Minute)
+	expired := now.Add(-time.Minute)
+
+	tests := []struct {
+		name       string
+		zoneID     string
+		customerID string
+		quantity   int
+		wantErr    error
+	}{
+		{name: "zone without lottery", zoneID: "zone-2", quantity: 5},
+		{name: "winner", zoneID: "zone-1", customerID: "winner", quantity: 2},
+		{name: "winner buying fewer", zoneID: "zone-1", customerID: "winner", quantity: 1},
+		{name: "more than the right", zoneID: "zone-1", customerID: "winner", quantity: 3, wantErr: domain.ErrPurchaseRightExceeded},
+		{name: "anonymous", zoneID: "zone-1", quantity: 1, wantErr: domain.ErrPurchaseRightRequired},
+		{name: "no ballot", zoneID: "zone-1", customerID: "stranger", quantity: 1, wantErr: domain.ErrPurchaseRightRequired},
+		{name: "runner-up", zoneID: "zone-1", customerID: "waiting", quantity: 1, wantErr: domain.ErrPurchaseRightRequired},
+		{name: "expired offer", zoneID: "zone-1", customerID: "late", quantity: 1, wantErr: domain.ErrPurchaseRightRequired},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			repo := newFakeLotteryRepo()
+			repo.lotteries["lottery-1"] = domain.Lottery{ID: "lottery-1", ZoneID: "zone-1", Status: domain.LotteryDrawn}
+			repo.ballots = []domain.Ballot{
+				{ID: "b1", LotteryID: "lottery-1", CustomerID: "winner", Quantity: 2, Status: domain.BallotOffered, OfferExpiresAt: &valid},
+				{ID: "b2", LotteryID: "lottery-1", CustomerID: "waiting", Quantity: 1, Status: domain.BallotWaitlisted},
+				{ID: "b3", LotteryID: "lottery-1", CustomerID: "late", Quantity: 1, Status: domain.BallotOffered, OfferExpiresAt: &expired},
+			}
+			svc := NewLotteryService(repo, clock.NewFixed(now))
+
+			err := svc.RedeemPurchaseRight(context.Background(), tt.zoneID, tt.customerID, tt.quantity, "hold-1", now)
+			if err != tt.wantErr {
+				t.Fatalf("expected %v, got %v", tt.wantErr, err)
+			}
+			if err == nil && tt.customerID == "winner" {
+				if b := repo.ballot("b1"); b.Status != domain.BallotRedeemed || b.HoldID != "hold-1" {
+					t.Fatalf("expected the right to be redeemed by hold-1, got %+v", b)
+				}
+				if err := svc.RedeemPurchaseRight(context.Background(), tt.zoneID, tt.customerID, 1, "hold-2", now); err != domain.ErrPurchaseRightRequired {
+					t.Fatalf("expected a right to be redeemed once, got %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestLotteryService_GetBallotReportsLapsedOffer(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	expired := now.Add(-time.Second)
+	repo := newFakeLotteryRepo()
+	repo.ballots = []domain.Ballot{{ID: "b1", LotteryID: "lottery-1", CustomerID: "cust-1", Status: domain.BallotOffered, OfferExpiresAt: &expired}}
+	svc := NewLotteryService(repo, clock.NewFixed(now))
+
+	ballot, err := svc.GetBallot(context.Background(), "lottery-1", "cust-1")
+	if err != nil || ballot.Status != domain.BallotLapsed {
+		t.Fatalf("expected a lapsed ballot, got %+v (%v)", ballot, err)
+	}
+	if _, err := svc.GetBallot(context.Background(), "lottery-1", "cust-2"); err != domain.ErrBallotNotFound {
+		t.Fatalf("expected ErrBallotNotFound, got %v", err)
+	}
+}
+
+type fakeLotteryRepo struct {
+	zones     map[string]domain.Zone
+	available map[string]int
+	lotteries map[string]domain.Lottery
+	ballots   []domain.Ballot
+	audit     []domain.AuditEntry
+}
+
+// newFakeLotteryRepo starts with zone-1 of event-1, owned by org-1.
+func newFakeLotteryRepo() *fakeLotteryRepo {
+	return &fakeLotteryRepo{
+		zones:     map[string]domain.Zone{"zone-1": {ID: "zone-1", EventID: "event-1", Capacity: 10}},
+		available: map[string]int{},
+		lotteries: map[string]domain.Lottery{},
+	}
+}
+
+func (f *fakeLotteryRepo) ballot(id string) domain.Ballot {
+	for _, b := range f.ballots {
+		if b.ID == id {
+			return b
+		}
+	}
+	return domain.Ballot{}
+}
+
+func (f *fakeLotteryRepo) byRank() []domain.Ballot {
+	ranked := append([]domain.Ballot(nil), f.ballots...)
+	sort.Slice(ranked, func(i, j int) bool { return ranked[i].Rank < ranked[j].Rank })
+	return ranked
+}
+
+func (f *fakeLotteryRepo) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+func (f *fakeLotteryRepo) GetZone(_ context.Context, zoneID string) (domain.Zone, string, error) {
+	zone, ok := f.zones[zoneID]
+	if !ok {
+		return domain.Zone{}, "", domain.ErrZoneNotFound
+	}
+	return zone, "org-1", nil
+}
+
+func (f *fakeLotteryRepo) ZoneAvailable(_ context.Context, zoneID string, _ time.Time) (int, error) {
+	return f.available[zoneID], nil
+}
+
+func (f *fakeLotteryRepo) CreateLottery(_ context.Context, lottery domain.Lottery) error {
+	for _, l := range f.lotteries {
+		if l.ZoneID == lottery.ZoneID {
+			return domain.ErrLotteryExists
+		}
+	}
+	f.lotteries[lottery.ID] = lottery
+	return nil
+}
+
+func (f *fakeLotteryRepo) GetLottery(_ context.Context, id string) (domain.Lottery, error) {
+	lottery, ok := f.lotteries[id]
+	if !ok {
+		return domain.Lottery{}, domain.ErrLotteryNotFound
+	}
+	return lottery, nil
+}
+
+func (f *fakeLotteryRepo) GetLotteryForUpdate(ctx context.Context, id string) (domain.Lottery, error) {
+	return f.GetLottery(ctx, id)
+}
+
+func (f *fakeLotteryRepo) GetLotteryByZone(_ context.Context, zoneID string) (*domain.Lottery, error) {
+	for _, l := range f.lotteries {
+		if l.ZoneID == zoneID {
+			return &l, nil
+		}
+	}
+	return nil, nil
+}
+
+func (f *fakeLotteryRepo) UpdateLottery(_ context.Context, lottery domain.Lottery) error {
+	f.lotteries[lottery.ID] = lottery
+	return nil
+}
+
+func (f *fakeLotteryRepo) ListRolloverLotteries(_ context.Context) ([]string, error) {
+	var ids []string
+	for id, l := range f.lotteries {
+		if l.Status == domain.LotteryDrawn {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+func (f *fakeLotteryRepo) CreateBallot(_ context.Context, ballot domain.Ballot) error {
+	for _, b := range f.ballots {
+		if b.LotteryID == ballot.LotteryID && b.CustomerID == ballot.CustomerID {
+			return domain.ErrBallotExists
+		}
+	}
+	f.ballots = append(f.ballots, ballot)
+	return nil
+}
+
+func (f *fakeLotteryRepo) GetBallot(_ context.Context, lotteryID, customerID string) (domain.Ballot, error) {
+	for _, b := range f.ballots {
+		if b.LotteryID == lotteryID && b.CustomerID == customerID {
+			return b, nil
+		}
+	}
+	return domain.Ballot{}, domain.ErrBallotNotFound
+}
+
+func (f *fakeLotteryRepo) GetBallotForUpdate(ctx context.Context, lotteryID, customerID string) (domain.Ballot, error) {
+	return f.GetBallot(ctx, lotteryID, customerID)
+}
+
+func (f *fakeLotteryRepo) ListBallots(_ context.Context, lotteryID string, status domain.BallotStatus) ([]domain.Ballot, error) {
+	var ballots []domain.Ballot
+	for _, b := range f.byRank() {
+		if b.LotteryID == lotteryID && b.Status == status {
+			ballots = append(ballots, b)
+		}
+	}
+	return ballots, nil
+}
+
+func (f *fakeLotteryRepo) UpdateBallot(_ context.Context, ballot domain.Ballot) error {
+	for i := range f.ballots {
+		if f.ballots[i].ID == ballot.ID {
+			f.ballots[i] = ballot
+			return nil
+		}
+	}
+	return domain.ErrBallotNotFound
+}
+
+func (f *fakeLotteryRepo) SumOffered(_ context.Context, lotteryID string) (int, error) {
+	total := 0
+	for _, b := range f.ballots {
+		if b.LotteryID == lotteryID && b.Status == domain.BallotOffered {
+			total += b.Quantity
+		}
+	}
+	return total, nil
+}
+
+func (f *fakeLotteryRepo) LapseOffers(_ context.Context, lotteryID string, now time.Time) (int, error) {
+	n := 0
+	for i := range f.ballots {
+		b := &f.ballots[i]
+		if b.LotteryID == lotteryID && b.Status == domain.BallotOffered && !b.OfferExpiresAt.After(now) {
+			b.Status = domain.BallotLapsed
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (f *fakeLotteryRepo) AppendAuditEntry(_ context.Context, entry domain.AuditEntry) error {
+	f.audit = append(f.audit, entry)
+	return nil
+}
@@ -0,0 +1,73 @@
+package app
+
+import (
+	"errors"
+
+	"github.com/cimillas/ultimate-ticket/services/api/internal/domain"
+)
+
+// HoldMetrics counts hold outcomes for monitoring.
+type HoldMetrics interface {
+	HoldCreated()
+	// HoldRejected is called with the reason a hold was refused, such as
+	// "insufficient_capacity".
+	HoldRejected(reason string)
+	HoldsExpired(n int)
+}
+
+// OrderMetrics counts confirmation outcomes for monitoring.
+type OrderMetrics interface {
+	// Confirmation is called with "confirmed" for each new order, or with the
+	// reason a confirmation failed. Idempotent retries are not counted.
+	Confirmation(outcome string)
+}
+
+// OutboxMetrics counts outbox events that stopped being retried.
+type OutboxMetrics interface {
+	// OutboxEventDead is called with the type of each dead-lettered event.
+	OutboxEventDead(eventType string)
+}
+
+// outcomeConfirmed is the Confirmation outcome of a new order.
+const outcomeConfirmed = "confirmed"
+
+// rejectionReasons name the errors callers can cause; other errors are
+// reported as "error". The names match the API's error codes.
+var rejectionReasons = []struct {
+	err    error
+	reason string
+}{
+	{domain.ErrInvalidID, "invalid_id"},
+	{domain.ErrInvalidQuantity, "invalid_quantity"},
+	{domain.ErrIdempotencyKeyRequired, "idempotency_key_required"},
+	{domain.ErrIdempotencyConflict, "idempotency_conflict"},
+	{domain.ErrZoneNotFound, "zone_not_found"},
+	{domain.ErrInsufficientCapacity, "insufficient_capacity"},
+	{domain.ErrPurchaseLimitExceeded, "purchase_limit_exceeded"},
+	{domain.ErrSignInRequired, "sign_in_required"},
+	{domain.ErrQuantityBelowMinimum, "quantity_below_minimum"},
+	{domain.ErrQuantityAboveMaximum, "quantity_above_maximum"},
+	{domain.ErrQuantityStepMismatch, "quantity_step_mismatch"},
+	{domain.ErrAdmissionRequired, "admission_required"},
+	{domain.ErrInvalidAdmissionToken, "invalid_admission_token"},
+	{domain.ErrAdmissionUsed, "admission_used"},
+	{domain.ErrPurchaseRightRequired, "purchase_right_required"},
+	{domain.ErrPurchaseRightExceeded, "purchase_right_exceeded"},
+	{domain.ErrHoldBlocked, "hold_blocked"},
+	{domain.ErrChallengeRequired, "challenge_required"},
+	{domain.ErrInvalidEmail, "invalid_email"},
+	{domain.ErrHoldNotFound, "hold_not_found"},
+	{domain.ErrHoldExpired, "hold_expired"},
+	{domain.ErrHoldAlreadyConfirmed, "hold_already_confirmed"},
+	{domain.ErrPaymentDeclined, "payment_declined"},
+	{domain.ErrPaymentUnavailable, "payment_unavailable"},
+}
+
+func rejectionReason(err error) string {
+	for _, r := range rejectionReasons {
+		if errors.Is(err, r.err) {
+			return r.reason
+		}
+	}
+	return "error"
+}
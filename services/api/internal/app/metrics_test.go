@@ -0,0 +1,88 @@
+package app
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/cimillas/ultimate-ticket/services/api/internal/clock"
+	"github.com/cimillas/ultimate-ticket/services/api/internal/domain"
+)
+
+type fakeMetrics struct {
+	created  int
+	rejected []string
+	expired  int
+	outcomes []string
+}
+
+func (f *fakeMetrics) HoldCreated()                { f.created++ }
+func (f *fakeMetrics) HoldRejected(reason string)  { f.rejected = append(f.rejected, reason) }
+func (f *fakeMetrics) HoldsExpired(n int)          { f.expired += n }
+func (f *fakeMetrics) Confirmation(outcome string) { f.outcomes = append(f.outcomes, outcome) }
+
+func TestHoldService_Metrics(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	repo := newFakeHoldRepo([]domain.Zone{{ID: "zone-1", EventID: "event-1", Capacity: 2, MaxQuantity: 2}}, []domain.Hold{
+		{ID: "lapsed", Status: domain.HoldStatusActive, ExpiresAt: now.Add(-time.Minute)},
+	})
+	m := &fakeMetrics{}
+	svc := NewHoldService(repo, clock.NewFixed(now), WithHoldMetrics(m))
+	ctx := context.Background()
+
+	inputs := []CreateHoldInput{
+		{EventID: "event-1", ZoneID: "zone-1", Quantity: 2, IdempotencyKey: "idem-1"},
+		{EventID: "event-1", ZoneID: "zone-1", Quantity: 2, IdempotencyKey: "idem-1"},
+		{EventID: "event-1", ZoneID: "zone-1", Quantity: 1, IdempotencyKey: "idem-1"},
+		{EventID: "event-1", ZoneID: "zone-1", Quantity: 1, IdempotencyKey: "idem-2"},
+		{EventID: "event-1", ZoneID: "zone-1", Quantity: 3, IdempotencyKey: "idem-3"},
+		{EventID: "event-1", ZoneID: "zone-9", Quantity: 1, IdempotencyKey: "idem-4"},
+	}
+	for _, in := range inputs {
+		_, _ = svc.CreateHold(ctx, in)
+	}
+	if _, err := svc.ExpireHolds(ctx); err != nil {
+		t.Fatalf("expire: %v", err)
+	}
+
+	if m.created != 1 {
+		t.Fatalf("expected 1 created hold, the retry not counted; got %d", m.created)
+	}
+	want := []string{"idempotency_conflict", "insufficient_capacity", "quantity_above_maximum", "zone_not_found"}
+	if !reflect.DeepEqual(m.rejected, want) {
+		t.Fatalf("expected rejections %v, got %v", want, m.rejected)
+	}
+	if m.expired != 1 {
+		t.Fatalf("expected 1 expired hold, got %d", m.expired)
+	}
+}
+
+func TestOrderService_Metrics(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, 1, 2, 10, 0, 0, 0, time.UTC)
+	repo := newFakeOrderRepo(map[string]domain.Hold{
+		"hold-1": {ID: "hold-1", Quantity: 1, Status: domain.HoldStatusActive, ExpiresAt: now.Add(time.Minute)},
+		"hold-2": {ID: "hold-2", Quantity: 1, Status: domain.HoldStatusActive, ExpiresAt: now.Add(-time.Minute)},
+	})
+	m := &fakeMetrics{}
+	svc := NewOrderService(repo, clock.NewFixed(now), WithOrderMetrics(m))
+	ctx := context.Background()
+
+	for _, in := range []ConfirmHoldInput{
+		{HoldID: "hold-1", IdempotencyKey: "idem-1"},
+		{HoldID: "hold-1", IdempotencyKey: "idem-1"},
+		{HoldID: "hold-1", IdempotencyKey: "idem-2"},
+		{HoldID: "hold-2", IdempotencyKey: "idem-3"},
+	} {
+		_, _ = svc.ConfirmHold(ctx, in)
+	}
+
+	want := []string{"confirmed", "hold_already_confirmed", "hold_expired"}
+	if !reflect.DeepEqual(m.outcomes, want) {
+		t.Fatalf("expected outcomes %v, got %v", want, m.outcomes)
+	}
+}
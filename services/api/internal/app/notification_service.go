@@ -0,0 +1,294 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/cimillas/ultimate-ticket/services/api/internal/clock"
+	"github.com/cimillas/ultimate-ticket/services/api/internal/domain"
+)
+
+type NotificationRepository interface {
+	WithTx(ctx context.Context, fn func(ctx context.Context) error) error
+	GetOrderNotificationDetails(ctx context.Context, orderID string) (domain.OrderNotificationDetails, error)
+	// ListEventRecipients returns paid and fulfilled orders for the event that
+	// have a customer email.
+	ListEventRecipients(ctx context.Context, eventID string) ([]domain.OrderNotificationDetails, error)
+	// CreateNotification reports false if a notification with the same dedupe key exists.
+	CreateNotification(ctx context.Context, n domain.Notification) (bool, error)
+	// ClaimDueNotifications returns pending notifications due at now and
+	// pushes their next attempt to leaseUntil so concurrent senders skip them.
+	ClaimDueNotifications(ctx context.Context, now, leaseUntil time.Time, limit int) ([]domain.Notification, error)
+	GetNotificationForUpdate(ctx context.Context, id string) (domain.Notification, error)
+	UpdateNotification(ctx context.Context, n domain.Notification) error
+	ListNotifications(ctx context.Context, organizerID string, status domain.NotificationStatus) ([]domain.Notification, error)
+	AppendAuditEntry(ctx context.Context, entry domain.AuditEntry) error
+}
+
+// NotificationData is passed to templates.
+type NotificationData struct {
+	OrderID   string
+	EventName string
+	StartsAt  time.Time
+	ZoneName  string
+	Quantity  int
+	// PreviousEventName and PreviousStartsAt are set for event_changed.
+	PreviousEventName string
+	PreviousStartsAt  time.Time
+}
+
+type RenderedMessage struct {
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// NotificationRenderer renders the subject and bodies for a notification kind.
+type NotificationRenderer interface {
+	Render(kind domain.NotificationKind, data NotificationData) (RenderedMessage, error)
+}
+
+type MailMessage struct {
+	To      string
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// Mailer sends a single email.
+type Mailer interface {
+	Send(ctx context.Context, msg MailMessage) error
+}
+
+// NotificationService emails customers about their orders. It is an
+// OutboxPublisher: order.paid, order.cancelled and event.updated queue
+// rendered notifications, which SendDue delivers with retries.
+type NotificationService struct {
+	repo        NotificationRepository
+	renderer    NotificationRenderer
+	mailer      Mailer
+	clock       clock.Clock
+	retryDelay  time.Duration
+	maxDelay    time.Duration
+	maxAttempts int
+	lease       time.Duration
+	batchSize   int
+}
+
+const (
+	defaultNotificationRetryDelay  = 30 * time.Second
+	defaultNotificationMaxDelay    = time.Hour
+	defaultNotificationMaxAttempts = 6
+	defaultNotificationLease       = time.Minute
+	defaultNotificationBatchSize   = 50
+)
+
+var _ OutboxPublisher = (*NotificationService)(nil)
+
+func NewNotificationService(repo NotificationRepository, renderer NotificationRenderer, mailer Mailer, clk clock.Clock, opts ...NotificationServiceOption) *NotificationService {
+	svc := &NotificationService{
+		repo:        repo,
+		renderer:    renderer,
+		mailer:      mailer,
+		clock:       clk,
+		retryDelay:  defaultNotificationRetryDelay,
+		maxDelay:    defaultNotificationMaxDelay,
+		maxAttempts: defaultNotificationMaxAttempts,
+		lease:       defaultNotificationLease,
+		batchSize:   defaultNotificationBatchSize,
+	}
+	for _, opt := range opts {
+		opt(svc)
+	}
+	return svc
+}
+
+type NotificationServiceOption func(*NotificationService)
+
+// WithNotificationRetry sets the backoff between send attempts and how many
+// attempts a notification gets before it is marked failed.
+func WithNotificationRetry(initial, maxDelay time.Duration, maxAttempts int) NotificationServiceOption {
+	return func(s *NotificationService) {
+		if initial > 0 {
+			s.retryDelay = initial
+		}
+		if maxDelay >= s.retryDelay {
+			s.maxDelay = maxDelay
+		}
+		if maxAttempts > 0 {
+			s.maxAttempts = maxAttempts
+		}
+	}
+}
+
+// Publish queues notifications for the event. Orders without a customer
+// email are skipped; redelivered events are queued once per recipient.
+func (s *NotificationService) Publish(ctx context.Context, event domain.OutboxEvent) error {
+	switch event.Type {
+	case domain.OutboxOrderPaid:
+		return s.queueForOrder(ctx, event, domain.NotificationOrderConfirmation)
+	case domain.OutboxOrderCancelled:
+		return s.queueForOrder(ctx, event, domain.NotificationOrderCancellation)
+	case domain.OutboxEventUpdated:
+		return s.queueForEvent(ctx, event)
+	default:
+		return nil
+	}
+}
+
+func (s *NotificationService) queueForOrder(ctx context.Context, event domain.OutboxEvent, kind domain.NotificationKind) error {
+	return s.repo.WithTx(ctx, func(txCtx context.Context) error {
+		details, err := s.repo.GetOrderNotificationDetails(txCtx, event.AggregateID)
+		if err != nil {
+			return err
+		}
+		if details.Email == "" {
+			return nil
+		}
+		return s.queue(txCtx, event, kind, details, NotificationData{})
+	})
+}
+
+func (s *NotificationService) queueForEvent(ctx context.Context, event domain.OutboxEvent) error {
+	var payload eventUpdatedPayload
+	if err := json.Unmarshal(event.Payload, &payload); err != nil {
+		return fmt.Errorf("decode %s payload: %w", event.Type, err)
+	}
+	return s.repo.WithTx(ctx, func(txCtx context.Context) error {
+		recipients, err := s.repo.ListEventRecipients(txCtx, payload.EventID)
+		if err != nil {
+			return err
+		}
+		for _, details := range recipients {
+			err := s.queue(txCtx, event, domain.NotificationEventChanged, details, NotificationData{
+				PreviousEventName: payload.PreviousName,
+				PreviousStartsAt:  payload.PreviousStartsAt,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s *NotificationService) queue(ctx context.Context, event domain.OutboxEvent, kind domain.NotificationKind, details domain.OrderNotificationDetails, data NotificationData) error {
+	data.OrderID = details.OrderID
+	data.EventName = details.EventName
+	data.StartsAt = details.StartsAt
+	data.ZoneName = details.ZoneName
+	data.Quantity = details.Quantity
+
+	msg, err := s.renderer.Render(kind, data)
+	if err != nil {
+		return fmt.Errorf("render %s: %w", kind, err)
+	}
+	now := s.clock.Now()
+	_, err = s.repo.CreateNotification(ctx, domain.Notification{
+		ID:            newUUID(),
+		OrganizerID:   details.OrganizerID,
+		Kind:          kind,
+		Recipient:     details.Email,
+		Subject:       msg.Subject,
+		TextBody:      msg.Text,
+		HTMLBody:      msg.HTML,
+		Status:        domain.NotificationPending,
+		NextAttemptAt: now,
+		DedupeKey:     event.ID + ":" + details.OrderID,
+		CreatedAt:     now,
+	})
+	return err
+}
+
+// SendDue sends the notifications that are due and returns how many were
+// sent. Mail is sent outside any transaction; the claim lease keeps other
+// senders from picking up the same notification meanwhile.
+func (s *NotificationService) SendDue(ctx context.Context) (int, error) {
+	now := s.clock.Now()
+	var due []domain.Notification
+	err := s.repo.WithTx(ctx, func(txCtx context.Context) error {
+		var err error
+		due, err = s.repo.ClaimDueNotifications(txCtx, now, now.Add(s.lease), s.batchSize)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for _, n := range due {
+		sendErr := s.mailer.Send(ctx, MailMessage{
+			To:      n.Recipient,
+			Subject: n.Subject,
+			Text:    n.TextBody,
+			HTML:    n.HTMLBody,
+		})
+		finished := s.clock.Now()
+
+		n.Attempts++
+		if sendErr == nil {
+			n.Status = domain.NotificationSent
+			n.SentAt = &finished
+			n.LastError = ""
+			sent++
+		} else {
+			n.LastError = sendErr.Error()
+			if n.Attempts >= s.maxAttempts {
+				n.Status = domain.NotificationFailed
+			} else {
+				n.NextAttemptAt = finished.Add(backoff(s.retryDelay, s.maxDelay, n.Attempts))
+			}
+		}
+		if err := s.repo.UpdateNotification(ctx, n); err != nil {
+			return sent, err
+		}
+	}
+	return sent, nil
+}
+
+// ListNotifications returns an organizer's notifications, optionally filtered by status.
+func (s *NotificationService) ListNotifications(ctx context.Context, organizerID string, status domain.NotificationStatus) ([]domain.Notification, error) {
+	return s.repo.ListNotifications(ctx, organizerID, status)
+}
+
+// RetryNotification requeues a failed notification with a fresh retry budget.
+func (s *NotificationService) RetryNotification(ctx context.Context, organizerID, id string) (domain.Notification, error) {
+	if id == "" {
+		return domain.Notification{}, domain.ErrInvalidID
+	}
+
+	now := s.clock.Now()
+	var result domain.Notification
+	err := s.repo.WithTx(ctx, func(txCtx context.Context) error {
+		n, err := s.repo.GetNotificationForUpdate(txCtx, id)
+		if err != nil {
+			return err
+		}
+		if n.OrganizerID != organizerID {
+			return domain.ErrNotificationNotFound
+		}
+		if n.Status != domain.NotificationFailed {
+			return domain.ErrNotificationNotFailed
+		}
+		before := n
+		n.Status = domain.NotificationPending
+		n.Attempts = 0
+		n.NextAttemptAt = now
+		if err := s.repo.UpdateNotification(txCtx, n); err != nil {
+			return err
+		}
+		result = n
+		entry, err := newAuditEntry(txCtx, organizerID, domain.AuditNotificationRetried, domain.AuditTargetNotification, n.ID,
+			newNotificationSnapshot(before), newNotificationSnapshot(n), now)
+		if err != nil {
+			return err
+		}
+		return s.repo.AppendAuditEntry(txCtx, entry)
+	})
+	if err != nil {
+		return domain.Notification{}, err
+	}
+	return result, nil
+}
@@ -0,0 +1,289 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cimillas/ultimate-ticket/services/api/internal/clock"
+	"github.com/cimillas/ultimate-ticket/services/api/internal/domain"
+)
+
+func TestNotificationService_Publish(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, 1, 4, 8, 0, 0, 0, time.UTC)
+	startsAt := time.Date(2025, 6, 1, 20, 0, 0, 0, time.UTC)
+	newRepo := func() *fakeNotificationRepo {
+		return &fakeNotificationRepo{orders: []domain.OrderNotificationDetails{
+			{OrderID: "order-1", OrganizerID: "org-1", Email: "fan@example.com", EventID: "event-1", EventName: "Concert", StartsAt: startsAt, ZoneName: "Floor", Quantity: 2},
+			{OrderID: "order-2", Email: "", EventID: "event-1", EventName: "Concert", StartsAt: startsAt, ZoneName: "Floor", Quantity: 1},
+			{OrderID: "order-3", Email: "other@example.com", EventID: "event-1", EventName: "Concert", StartsAt: startsAt, ZoneName: "Balcony", Quantity: 1},
+		}}
+	}
+
+	t.Run("order.paid queues a confirmation once", func(t *testing.T) {
+		repo := newRepo()
+		renderer := &stubNotificationRenderer{}
+		svc := NewNotificationService(repo, renderer, &stubMailer{}, clock.NewFixed(now))
+		event := domain.OutboxEvent{ID: "evt-1", AggregateID: "order-1", Type: domain.OutboxOrderPaid}
+
+		for i := 0; i < 2; i++ {
+			if err := svc.Publish(context.Background(), event); err != nil {
+				t.Fatalf("publish: %v", err)
+			}
+		}
+		if len(repo.notifications) != 1 {
+			t.Fatalf("expected 1 notification, got %d", len(repo.notifications))
+		}
+		n := repo.notifications[0]
+		if n.Kind != domain.NotificationOrderConfirmation || n.Recipient != "fan@example.com" || n.OrganizerID != "org-1" || n.Status != domain.NotificationPending {
+			t.Fatalf("unexpected notification %+v", n)
+		}
+		if n.Subject != "order_confirmation Concert" || !n.NextAttemptAt.Equal(now) {
+			t.Fatalf("expected rendered subject due now, got %+v", n)
+		}
+		if renderer.last.ZoneName != "Floor" || renderer.last.Quantity != 2 {
+			t.Fatalf("unexpected template data %+v", renderer.last)
+		}
+	})
+
+	t.Run("orders without email are skipped", func(t *testing.T) {
+		repo := newRepo()
+		svc := NewNotificationService(repo, &stubNotificationRenderer{}, &stubMailer{}, clock.NewFixed(now))
+		event := domain.OutboxEvent{ID: "evt-2", AggregateID: "order-2", Type: domain.OutboxOrderCancelled}
+		if err := svc.Publish(context.Background(), event); err != nil {
+			t.Fatalf("publish: %v", err)
+		}
+		if len(repo.notifications) != 0 {
+			t.Fatalf("expected no notifications, got %+v", repo.notifications)
+		}
+	})
+
+	t.Run("event.updated notifies every recipient", func(t *testing.T) {
+		repo := newRepo()
+		renderer := &stubNotificationRenderer{}
+		svc := NewNotificationService(repo, renderer, &stubMailer{}, clock.NewFixed(now))
+		event := domain.OutboxEvent{
+			ID:          "evt-3",
+			AggregateID: "event-1",
+			Type:        domain.OutboxEventUpdated,
+			Payload:     []byte(`{"event_id":"event-1","name":"Concert","starts_at":"2025-06-01T20:00:00Z","previous_name":"Concert","previous_starts_at":"2025-05-31T20:00:00Z"}`),
+		}
+		if err := svc.Publish(context.Background(), event); err != nil {
+			t.Fatalf("publish: %v", err)
+		}
+		if len(repo.notifications) != 2 {
+			t.Fatalf("expected 2 notifications, got %d", len(repo.notifications))
+		}
+		for _, n := range repo.notifications {
+			if n.Kind != domain.NotificationEventChanged {
+				t.Fatalf("unexpected kind %s", n.Kind)
+			}
+		}
+		if !renderer.last.PreviousStartsAt.Equal(startsAt.Add(-24 * time.Hour)) {
+			t.Fatalf("expected previous start time in template data, got %+v", renderer.last)
+		}
+	})
+
+	t.Run("other events are ignored", func(t *testing.T) {
+		repo := newRepo()
+		svc := NewNotificationService(repo, &stubNotificationRenderer{}, &stubMailer{}, clock.NewFixed(now))
+		if err := svc.Publish(context.Background(), domain.OutboxEvent{ID: "evt-4", AggregateID: "hold-1", Type: domain.OutboxHoldCreated}); err != nil {
+			t.Fatalf("publish: %v", err)
+		}
+		if len(repo.notifications) != 0 {
+			t.Fatalf("expected no notifications, got %+v", repo.notifications)
+		}
+	})
+}
+
+func TestNotificationService_SendDue(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, 1, 4, 8, 0, 0, 0, time.UTC)
+	pending := domain.Notification{
+		ID:            "n-1",
+		OrganizerID:   "org-1",
+		Kind:          domain.NotificationOrderConfirmation,
+		Recipient:     "fan@example.com",
+		Subject:       "Your tickets",
+		TextBody:      "text",
+		HTMLBody:      "<p>html</p>",
+		Status:        domain.NotificationPending,
+		NextAttemptAt: now,
+	}
+
+	t.Run("successful send marks sent", func(t *testing.T) {
+		repo := &fakeNotificationRepo{notifications: []domain.Notification{pending}}
+		mailer := &stubMailer{}
+		svc := NewNotificationService(repo, &stubNotificationRenderer{}, mailer, clock.NewFixed(now))
+
+		n, err := svc.SendDue(context.Background())
+		if err != nil || n != 1 {
+			t.Fatalf("expected 1 sent, got %d (%v)", n, err)
+		}
+		if repo.notifications[0].Status != domain.NotificationSent || repo.notifications[0].SentAt == nil {
+			t.Fatalf("expected sent, got %+v", repo.notifications[0])
+		}
+		if len(mailer.sent) != 1 || mailer.sent[0].To != "fan@example.com" || mailer.sent[0].HTML != "<p>html</p>" {
+			t.Fatalf("unexpected mail %+v", mailer.sent)
+		}
+	})
+
+	t.Run("failures back off then fail", func(t *testing.T) {
+		repo := &fakeNotificationRepo{notifications: []domain.Notification{pending}}
+		mailer := &stubMailer{errs: []error{errors.New("421 try later"), errors.New("421 try later")}}
+		svc := NewNotificationService(repo, &stubNotificationRenderer{}, mailer, clock.NewFixed(now), WithNotificationRetry(time.Second, time.Minute, 2))
+
+		if _, err := svc.SendDue(context.Background()); err != nil {
+			t.Fatalf("send: %v", err)
+		}
+		got := repo.notifications[0]
+		if got.Status != domain.NotificationPending || got.Attempts != 1 || !got.NextAttemptAt.Equal(now.Add(time.Second)) || got.LastError != "421 try later" {
+			t.Fatalf("expected retry in 1s, got %+v", got)
+		}
+
+		repo.notifications[0].NextAttemptAt = now
+		if _, err := svc.SendDue(context.Background()); err != nil {
+			t.Fatalf("send: %v", err)
+		}
+		if repo.notifications[0].Status != domain.NotificationFailed {
+			t.Fatalf("expected failed, got %+v", repo.notifications[0])
+		}
+	})
+
+	t.Run("retry requeues failed notifications only", func(t *testing.T) {
+		repo := &fakeNotificationRepo{notifications: []domain.Notification{pending}}
+		svc := NewNotificationService(repo, &stubNotificationRenderer{}, &stubMailer{}, clock.NewFixed(now))
+
+		if _, err := svc.RetryNotification(context.Background(), "org-1", "n-1"); err != domain.ErrNotificationNotFailed {
+			t.Fatalf("expected ErrNotificationNotFailed, got %v", err)
+		}
+		repo.notifications[0].Status = domain.NotificationFailed
+		repo.notifications[0].Attempts = 6
+		if _, err := svc.RetryNotification(context.Background(), "org-2", "n-1"); err != domain.ErrNotificationNotFound {
+			t.Fatalf("expected another organizer's retry to fail with ErrNotificationNotFound, got %v", err)
+		}
+		got, err := svc.RetryNotification(context.Background(), "org-1", "n-1")
+		if err != nil {
+			t.Fatalf("retry: %v", err)
+		}
+		if got.Status != domain.NotificationPending || got.Attempts != 0 || !got.NextAttemptAt.Equal(now) {
+			t.Fatalf("expected pending notification due now, got %+v", got)
+		}
+		if _, err := svc.RetryNotification(context.Background(), "org-1", "missing"); err != domain.ErrNotificationNotFound {
+			t.Fatalf("expected ErrNotificationNotFound, got %v", err)
+		}
+	})
+}
+
+type stubNotificationRenderer struct {
+	last NotificationData
+}
+
+func (s *stubNotificationRenderer) Render(kind domain.NotificationKind, data NotificationData) (RenderedMessage, error) {
+	s.last = data
+	return RenderedMessage{Subject: string(kind) + " " + data.EventName, Text: "text", HTML: "<p>html</p>"}, nil
+}
+
+type stubMailer struct {
+	errs []error
+	sent []MailMessage
+}
+
+func (s *stubMailer) Send(_ context.Context, msg MailMessage) error {
+	i := len(s.sent)
+	s.sent = append(s.sent, msg)
+	if i < len(s.errs) {
+		return s.errs[i]
+	}
+	return nil
+}
+
+type fakeNotificationRepo struct {
+	orders        []domain.OrderNotificationDetails
+	notifications []domain.Notification
+	audit         []domain.AuditEntry
+}
+
+func (f *fakeNotificationRepo) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+func (f *fakeNotificationRepo) AppendAuditEntry(_ context.Context, entry domain.AuditEntry) error {
+	f.audit = append(f.audit, entry)
+	return nil
+}
+
+func (f *fakeNotificationRepo) GetOrderNotificationDetails(_ context.Context, orderID string) (domain.OrderNotificationDetails, error) {
+	for _, o := range f.orders {
+		if o.OrderID == orderID {
+			return o, nil
+		}
+	}
+	return domain.OrderNotificationDetails{}, domain.ErrOrderNotFound
+}
+
+func (f *fakeNotificationRepo) ListEventRecipients(_ context.Context, eventID string) ([]domain.OrderNotificationDetails, error) {
+	var out []domain.OrderNotificationDetails
+	for _, o := range f.orders {
+		if o.EventID == eventID && o.Email != "" {
+			out = append(out, o)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeNotificationRepo) CreateNotification(_ context.Context, n domain.Notification) (bool, error) {
+	for _, existing := range f.notifications {
+		if existing.DedupeKey == n.DedupeKey {
+			return false, nil
+		}
+	}
+	f.notifications = append(f.notifications, n)
+	return true, nil
+}
+
+func (f *fakeNotificationRepo) ClaimDueNotifications(_ context.Context, now, leaseUntil time.Time, limit int) ([]domain.Notification, error) {
+	var due []domain.Notification
+	for i := range f.notifications {
+		n := &f.notifications[i]
+		if len(due) == limit || n.Status != domain.NotificationPending || n.NextAttemptAt.After(now) {
+			continue
+		}
+		n.NextAttemptAt = leaseUntil
+		due = append(due, *n)
+	}
+	return due, nil
+}
+
+func (f *fakeNotificationRepo) GetNotificationForUpdate(_ context.Context, id string) (domain.Notification, error) {
+	for _, n := range f.notifications {
+		if n.ID == id {
+			return n, nil
+		}
+	}
+	return domain.Notification{}, domain.ErrNotificationNotFound
+}
+
+func (f *fakeNotificationRepo) UpdateNotification(_ context.Context, n domain.Notification) error {
+	for i := range f.notifications {
+		if f.notifications[i].ID == n.ID {
+			f.notifications[i] = n
+			return nil
+		}
+	}
+	return domain.ErrNotificationNotFound
+}
+
+func (f *fakeNotificationRepo) ListNotifications(_ context.Context, organizerID string, status domain.NotificationStatus) ([]domain.Notification, error) {
+	var out []domain.Notification
+	for _, n := range f.notifications {
+		if n.OrganizerID == organizerID && (status == "" || n.Status == status) {
+			out = append(out, n)
+		}
+	}
+	return out, nil
+}
@@ -2,34 +2,89 @@ package app
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"net/mail"
+	"time"
 
 	"github.com/cimillas/ultimate-ticket/services/api/internal/clock"
 	"github.com/cimillas/ultimate-ticket/services/api/internal/domain"
+	"github.com/cimillas/ultimate-ticket/services/api/internal/tracing"
 )
 
 type OrderRepository interface {
 	WithTx(ctx context.Context, fn func(ctx context.Context) error) error
 	GetHoldForUpdate(ctx context.Context, holdID string) (domain.Hold, error)
 	GetOrderByHoldID(ctx context.Context, holdID string) (*domain.Order, error)
+	GetOrderForUpdate(ctx context.Context, orderID string) (domain.Order, error)
 	CreateOrder(ctx context.Context, order domain.Order) error
+	UpdateOrderStatus(ctx context.Context, order domain.Order) error
 	UpdateHoldStatus(ctx context.Context, holdID string, status domain.HoldStatus) error
+	MarkHoldPaymentPending(ctx context.Context, holdID string, until time.Time) error
+	AppendOutboxEvent(ctx context.Context, event domain.OutboxEvent) error
+	AppendAuditEntry(ctx context.Context, entry domain.AuditEntry) error
 }
 
 type OrderService struct {
-	repo  OrderRepository
-	clock clock.Clock
+	repo         OrderRepository
+	clock        clock.Clock
+	payments     PaymentProvider
+	paymentGrace time.Duration
+	metrics      OrderMetrics
 }
 
-func NewOrderService(repo OrderRepository, clk clock.Clock) *OrderService {
-	return &OrderService{
-		repo:  repo,
-		clock: clk,
+const defaultPaymentGracePeriod = 5 * time.Minute
+
+func NewOrderService(repo OrderRepository, clk clock.Clock, opts ...OrderServiceOption) *OrderService {
+	svc := &OrderService{
+		repo:         repo,
+		clock:        clk,
+		paymentGrace: defaultPaymentGracePeriod,
+	}
+	for _, opt := range opts {
+		opt(svc)
+	}
+	return svc
+}
+
+type OrderServiceOption func(*OrderService)
+
+// WithPaymentProvider makes ConfirmHold authorize a payment before the order
+// is finalized. Without a provider, orders are marked paid on confirmation.
+func WithPaymentProvider(p PaymentProvider) OrderServiceOption {
+	return func(s *OrderService) {
+		s.payments = p
+	}
+}
+
+// WithPaymentGracePeriod overrides how long an open payment attempt keeps its
+// hold reserved after the attempt starts, even if the hold's TTL passes.
+func WithPaymentGracePeriod(d time.Duration) OrderServiceOption {
+	return func(s *OrderService) {
+		if d > 0 {
+			s.paymentGrace = d
+		}
+	}
+}
+
+// WithOrderMetrics reports confirmation outcomes to m.
+func WithOrderMetrics(m OrderMetrics) OrderServiceOption {
+	return func(s *OrderService) {
+		s.metrics = m
 	}
 }
 
 type ConfirmHoldInput struct {
 	HoldID         string
 	IdempotencyKey string
+	// AwaitPayment creates the order in pending_payment; the hold keeps its
+	// inventory reserved until the payment outcome is recorded.
+	AwaitPayment bool
+	// CustomerEmail is optional; when set, order notifications are sent to it.
+	CustomerEmail string
+	// CustomerID is the authenticated caller, if any. Holds owned by a
+	// customer can only be confirmed by that customer.
+	CustomerID string
 }
 
 type ConfirmHoldResult struct {
@@ -37,11 +92,213 @@ type ConfirmHoldResult struct {
 	Created bool
 }
 
+// ConfirmHold turns a hold into an order. With a payment provider configured
+// (and AwaitPayment unset) the payment is authorized and captured before the
+// order is marked paid; a declined payment fails the order and releases the hold.
 func (s *OrderService) ConfirmHold(ctx context.Context, in ConfirmHoldInput) (ConfirmHoldResult, error) {
+	ctx, span := tracing.Start(ctx, "OrderService.ConfirmHold")
+	defer span.End()
+	span.SetAttr("hold_id", in.HoldID)
+
+	res, err := s.confirmHold(ctx, in)
+	span.SetError(err)
+	if s.metrics != nil {
+		switch {
+		case err != nil:
+			s.metrics.Confirmation(rejectionReason(err))
+		case res.Created:
+			s.metrics.Confirmation(outcomeConfirmed)
+		}
+	}
+	return res, err
+}
+
+func (s *OrderService) confirmHold(ctx context.Context, in ConfirmHoldInput) (ConfirmHoldResult, error) {
 	if in.IdempotencyKey == "" {
 		return ConfirmHoldResult{}, domain.ErrIdempotencyKeyRequired
 	}
+	if in.CustomerEmail != "" {
+		addr, err := mail.ParseAddress(in.CustomerEmail)
+		if err != nil || addr.Name != "" {
+			return ConfirmHoldResult{}, domain.ErrInvalidEmail
+		}
+	}
+	if s.payments == nil || in.AwaitPayment {
+		return s.createOrder(ctx, in, !in.AwaitPayment)
+	}
+
+	res, err := s.createOrder(ctx, in, false)
+	if err != nil {
+		return ConfirmHoldResult{}, err
+	}
+	// Retries of a settled order return it as is; a pending one resumes payment.
+	if res.Order.Status != domain.OrderStatusPendingPayment {
+		return res, nil
+	}
 
+	order, err := s.pay(ctx, res.Order)
+	if err != nil {
+		return ConfirmHoldResult{}, err
+	}
+	return ConfirmHoldResult{Order: order, Created: res.Created}, nil
+}
+
+// pay authorizes and captures the payment, then records the result. Provider
+// calls happen before the transaction that marks the order paid, so row locks
+// are never held across network round trips; the idempotency key makes a retry
+// resume the same authorization and capture.
+func (s *OrderService) pay(ctx context.Context, order domain.Order) (domain.Order, error) {
+	hold, err := s.getHold(ctx, order.HoldID)
+	if err != nil {
+		return domain.Order{}, err
+	}
+
+	auth, err := s.payments.Authorize(ctx, PaymentRequest{
+		OrderID:        order.ID,
+		HoldID:         order.HoldID,
+		Quantity:       hold.Quantity,
+		IdempotencyKey: order.IdempotencyKey,
+	})
+	if err != nil {
+		if err == domain.ErrPaymentDeclined {
+			if _, failErr := s.transitionOrder(ctx, order.ID, domain.OrderStatusFailed, "", nil); failErr != nil && failErr != domain.ErrInvalidOrderTransition {
+				return domain.Order{}, failErr
+			}
+		}
+		// On timeouts the order stays pending; retrying with the same
+		// idempotency key resumes the same authorization.
+		return domain.Order{}, err
+	}
+
+	captureErr := domain.ErrHoldExpired
+	if hold.Status == domain.HoldStatusActive && !hold.IsExpiredAt(s.clock.Now()) {
+		captureErr = s.payments.Capture(ctx, PaymentOperation{
+			Reference:      auth.Reference,
+			IdempotencyKey: order.IdempotencyKey + ":capture",
+		})
+	}
+	if captureErr != nil {
+		// Best effort: uncaptured authorizations also lapse at the provider.
+		_ = s.payments.Void(ctx, PaymentOperation{
+			Reference:      auth.Reference,
+			IdempotencyKey: order.IdempotencyKey + ":void",
+		})
+		if _, failErr := s.transitionOrder(ctx, order.ID, domain.OrderStatusFailed, "", nil); failErr != nil && failErr != domain.ErrInvalidOrderTransition {
+			return domain.Order{}, failErr
+		}
+		return domain.Order{}, captureErr
+	}
+
+	paid, err := s.transitionOrder(ctx, order.ID, domain.OrderStatusPaid, "", func(_ context.Context, o *domain.Order) error {
+		o.PaymentReference = auth.Reference
+		return nil
+	})
+	switch err {
+	case nil:
+		return paid, nil
+	case domain.ErrHoldExpired, domain.ErrInvalidOrderTransition:
+		// The hold lapsed while the payment was being captured.
+		if _, failErr := s.failCapturedOrder(ctx, order.ID, auth.Reference); failErr != nil {
+			return domain.Order{}, failErr
+		}
+		return domain.Order{}, domain.ErrHoldExpired
+	default:
+		// The order stays pending; a retry captures idempotently and records again.
+		return domain.Order{}, err
+	}
+}
+
+// failCapturedOrder fails an order whose payment was captured after its hold
+// lapsed, or after the order ended unpaid, and requests the refund through the
+// outbox in the same transaction, so the refund is retried until the provider
+// accepts it.
+func (s *OrderService) failCapturedOrder(ctx context.Context, orderID, reference string) (domain.Order, error) {
+	var result domain.Order
+	err := s.repo.WithTx(ctx, func(txCtx context.Context) error {
+		before, err := s.repo.GetOrderForUpdate(txCtx, orderID)
+		if err != nil {
+			return err
+		}
+		order, err := s.transitionOrder(txCtx, orderID, domain.OrderStatusFailed, "", func(_ context.Context, o *domain.Order) error {
+			if o.PaymentReference == "" {
+				o.PaymentReference = reference
+			}
+			return nil
+		})
+		if err == domain.ErrInvalidOrderTransition {
+			// Cancelled in the meantime; the capture still has to be returned.
+			order, err = s.repo.GetOrderForUpdate(txCtx, orderID)
+		}
+		if err != nil {
+			return err
+		}
+		if order.PaymentReference == "" {
+			order.PaymentReference = reference
+		}
+		result = order
+		if s.payments == nil || order.PaymentReference == "" {
+			return nil
+		}
+		now := s.clock.Now()
+		if err := s.appendOrderEvent(txCtx, domain.OutboxOrderRefundRequested, order, now); err != nil {
+			return err
+		}
+		return s.audit(txCtx, domain.AuditOrderRefundRequested, before, order, now)
+	})
+	if err != nil {
+		return domain.Order{}, err
+	}
+	return result, nil
+}
+
+// organizerTransition applies a transition requested by an organizer. Orders
+// of other organizers are reported as missing.
+func (s *OrderService) organizerTransition(ctx context.Context, organizerID, orderID string, next domain.OrderStatus, action domain.AuditAction) (domain.Order, error) {
+	if orderID == "" {
+		return domain.Order{}, domain.ErrInvalidID
+	}
+	var result domain.Order
+	err := s.repo.WithTx(ctx, func(txCtx context.Context) error {
+		before, err := s.repo.GetOrderForUpdate(txCtx, orderID)
+		if err != nil {
+			return err
+		}
+		if before.OrganizerID != organizerID {
+			return domain.ErrOrderNotFound
+		}
+		order, err := s.transitionOrder(txCtx, orderID, next, action, nil)
+		if err != nil {
+			return err
+		}
+		result = order
+		if next != domain.OrderStatusCancelled || before.Status != domain.OrderStatusPaid ||
+			s.payments == nil || order.PaymentReference == "" {
+			return nil
+		}
+		now := s.clock.Now()
+		if err := s.appendOrderEvent(txCtx, domain.OutboxOrderRefundRequested, order, now); err != nil {
+			return err
+		}
+		return s.audit(txCtx, domain.AuditOrderRefundRequested, before, order, now)
+	})
+	if err != nil {
+		return domain.Order{}, err
+	}
+	return result, nil
+}
+
+func (s *OrderService) getHold(ctx context.Context, holdID string) (domain.Hold, error) {
+	var hold domain.Hold
+	err := s.repo.WithTx(ctx, func(txCtx context.Context) error {
+		var err error
+		hold, err = s.repo.GetHoldForUpdate(txCtx, holdID)
+		return err
+	})
+	return hold, err
+}
+
+// createOrder creates the order for a hold, either already paid or pending payment.
+func (s *OrderService) createOrder(ctx context.Context, in ConfirmHoldInput, paid bool) (ConfirmHoldResult, error) {
 	now := s.clock.Now()
 	var result ConfirmHoldResult
 
@@ -50,6 +307,10 @@ func (s *OrderService) ConfirmHold(ctx context.Context, in ConfirmHoldInput) (Co
 		if err != nil {
 			return err
 		}
+		// Another customer's hold is reported as missing rather than forbidden.
+		if hold.CustomerID != "" && hold.CustomerID != in.CustomerID {
+			return domain.ErrHoldNotFound
+		}
 
 		existing, err := s.repo.GetOrderByHoldID(txCtx, in.HoldID)
 		if err != nil {
@@ -66,7 +327,7 @@ func (s *OrderService) ConfirmHold(ctx context.Context, in ConfirmHoldInput) (Co
 		if hold.Status == domain.HoldStatusConfirmed {
 			return domain.ErrHoldAlreadyConfirmed
 		}
-		if hold.Status == domain.HoldStatusExpired || !hold.ExpiresAt.After(now) {
+		if hold.Status != domain.HoldStatusActive || hold.IsExpiredAt(now) {
 			return domain.ErrHoldExpired
 		}
 
@@ -74,7 +335,15 @@ func (s *OrderService) ConfirmHold(ctx context.Context, in ConfirmHoldInput) (Co
 			ID:             newUUID(),
 			HoldID:         in.HoldID,
 			IdempotencyKey: in.IdempotencyKey,
+			Status:         domain.OrderStatusPendingPayment,
+			CustomerID:     hold.CustomerID,
+			CustomerEmail:  in.CustomerEmail,
 			CreatedAt:      now,
+			UpdatedAt:      now,
+		}
+		if paid {
+			order.Status = domain.OrderStatusPaid
+			order.PaidAt = &now
 		}
 
 		if err := s.repo.CreateOrder(txCtx, order); err != nil {
@@ -91,8 +360,22 @@ func (s *OrderService) ConfirmHold(ctx context.Context, in ConfirmHoldInput) (Co
 			}
 			return err
 		}
-		if err := s.repo.UpdateHoldStatus(txCtx, in.HoldID, domain.HoldStatusConfirmed); err != nil {
-			return err
+		if order.Status == domain.OrderStatusPaid {
+			if err := s.repo.UpdateHoldStatus(txCtx, in.HoldID, domain.HoldStatusConfirmed); err != nil {
+				return err
+			}
+			if err := s.appendPaidEvents(txCtx, order, now); err != nil {
+				return err
+			}
+		} else {
+			if err := s.appendOrderEvent(txCtx, domain.OutboxOrderPendingPayment, order, now); err != nil {
+				return err
+			}
+			// Freeze expiry while the payment is in flight, but only for a
+			// bounded window so abandoned attempts still free the inventory.
+			if err := s.repo.MarkHoldPaymentPending(txCtx, in.HoldID, now.Add(s.paymentGrace)); err != nil {
+				return err
+			}
 		}
 
 		result = ConfirmHoldResult{Order: order, Created: true}
@@ -103,3 +386,267 @@ func (s *OrderService) ConfirmHold(ctx context.Context, in ConfirmHoldInput) (Co
 	}
 	return result, nil
 }
+
+// MarkOrderPaid finalizes a pending order and confirms its hold. It fails with
+// ErrHoldExpired if the hold stopped reserving inventory while payment was
+// pending, i.e. both its TTL and the payment grace window have passed.
+func (s *OrderService) MarkOrderPaid(ctx context.Context, orderID string) (domain.Order, error) {
+	return s.transitionOrder(ctx, orderID, domain.OrderStatusPaid, "", nil)
+}
+
+// MarkOrderFailed records a failed payment for one of the organizer's orders
+// and releases its hold.
+func (s *OrderService) MarkOrderFailed(ctx context.Context, organizerID, orderID string) (domain.Order, error) {
+	return s.organizerTransition(ctx, organizerID, orderID, domain.OrderStatusFailed, "")
+}
+
+// CancelOrder cancels one of the organizer's pending or paid orders and
+// releases its hold. The cancellation is recorded in the audit log. A paid
+// order is refunded through the outbox in the same transaction; a capture
+// that arrives for a cancelled pending order is refunded when it arrives.
+func (s *OrderService) CancelOrder(ctx context.Context, organizerID, orderID string) (domain.Order, error) {
+	return s.organizerTransition(ctx, organizerID, orderID, domain.OrderStatusCancelled, domain.AuditOrderCancelled)
+}
+
+// FulfillOrder marks one of the organizer's paid orders as delivered to the
+// customer.
+func (s *OrderService) FulfillOrder(ctx context.Context, organizerID, orderID string) (domain.Order, error) {
+	return s.organizerTransition(ctx, organizerID, orderID, domain.OrderStatusFulfilled, "")
+}
+
+// ApplyPaymentEvent drives the order with an outcome reported by the payment
+// processor and tells how the event was applied. Events that do not fit the
+// order's current state are ignored. A capture that arrives after the hold
+// lapsed, or after the order failed or was cancelled without being paid,
+// fails the order if it is still pending and requests a refund through the
+// outbox in the same transaction, so the refund is retried until the provider
+// accepts it.
+func (s *OrderService) ApplyPaymentEvent(ctx context.Context, orderID string, event domain.PaymentEvent) (domain.Order, domain.PaymentEventOutcome, error) {
+	var order domain.Order
+	var outcome domain.PaymentEventOutcome
+	err := s.repo.WithTx(ctx, func(txCtx context.Context) error {
+		var err error
+		order, outcome, err = s.applyPaymentEvent(txCtx, orderID, event)
+		return err
+	})
+	if err != nil {
+		return domain.Order{}, "", err
+	}
+	return order, outcome, nil
+}
+
+func (s *OrderService) applyPaymentEvent(ctx context.Context, orderID string, event domain.PaymentEvent) (domain.Order, domain.PaymentEventOutcome, error) {
+	setReference := func(_ context.Context, o *domain.Order) error {
+		if o.PaymentReference == "" {
+			o.PaymentReference = event.PaymentReference
+		}
+		return nil
+	}
+
+	var order domain.Order
+	var err error
+	switch event.Type {
+	case domain.PaymentEventAuthorized:
+		// Authorization alone does not finalize the order; remember the reference.
+		order, err = s.attachPaymentReference(ctx, orderID, event.PaymentReference)
+	case domain.PaymentEventCaptured:
+		order, err = s.transitionOrder(ctx, orderID, domain.OrderStatusPaid, "", setReference)
+		refund := err == domain.ErrHoldExpired
+		if err == domain.ErrInvalidOrderTransition {
+			if refund, err = s.endedUnpaid(ctx, orderID); err == nil && !refund {
+				err = domain.ErrInvalidOrderTransition
+			}
+		}
+		if refund {
+			order, err = s.failCapturedOrder(ctx, orderID, event.PaymentReference)
+			if err != nil {
+				return domain.Order{}, "", err
+			}
+			return order, domain.PaymentEventRejected, nil
+		}
+	case domain.PaymentEventFailed:
+		order, err = s.transitionOrder(ctx, orderID, domain.OrderStatusFailed, "", nil)
+	case domain.PaymentEventRefunded:
+		order, err = s.transitionOrder(ctx, orderID, domain.OrderStatusCancelled, domain.AuditOrderRefunded, nil)
+	}
+
+	if err == domain.ErrInvalidOrderTransition {
+		order, err = s.repo.GetOrderForUpdate(ctx, orderID)
+		if err != nil {
+			return domain.Order{}, "", err
+		}
+		return order, domain.PaymentEventIgnored, nil
+	}
+	if err != nil {
+		return domain.Order{}, "", err
+	}
+	return order, domain.PaymentEventProcessed, nil
+}
+
+// endedUnpaid reports whether the order failed or was cancelled without ever
+// being paid, so a capture for it has to be returned.
+func (s *OrderService) endedUnpaid(ctx context.Context, orderID string) (bool, error) {
+	order, err := s.repo.GetOrderForUpdate(ctx, orderID)
+	if err != nil {
+		return false, err
+	}
+	ended := order.Status == domain.OrderStatusFailed || order.Status == domain.OrderStatusCancelled
+	return ended && order.PaidAt == nil, nil
+}
+
+// Publish carries out the refunds requested through the outbox. A refund the
+// provider does not accept fails the delivery, so the relay retries it; the
+// idempotency key keeps the order from being refunded twice.
+func (s *OrderService) Publish(ctx context.Context, event domain.OutboxEvent) error {
+	if event.Type != domain.OutboxOrderRefundRequested || s.payments == nil {
+		return nil
+	}
+	var payload orderEventPayload
+	if err := json.Unmarshal(event.Payload, &payload); err != nil {
+		return fmt.Errorf("decode %s payload: %w", event.Type, err)
+	}
+	return s.payments.Refund(ctx, PaymentOperation{
+		Reference:      payload.PaymentReference,
+		IdempotencyKey: payload.OrderID + ":refund",
+	})
+}
+
+// attachPaymentReference stores the provider reference on a pending order.
+// Orders that already left pending_payment report ErrInvalidOrderTransition.
+func (s *OrderService) attachPaymentReference(ctx context.Context, orderID, reference string) (domain.Order, error) {
+	var result domain.Order
+	err := s.repo.WithTx(ctx, func(txCtx context.Context) error {
+		order, err := s.repo.GetOrderForUpdate(txCtx, orderID)
+		if err != nil {
+			return err
+		}
+		if order.Status != domain.OrderStatusPendingPayment {
+			return domain.ErrInvalidOrderTransition
+		}
+		if order.PaymentReference == "" && reference != "" {
+			order.PaymentReference = reference
+			order.UpdatedAt = s.clock.Now()
+			if err := s.repo.UpdateOrderStatus(txCtx, order); err != nil {
+				return err
+			}
+		}
+		result = order
+		return nil
+	})
+	if err != nil {
+		return domain.Order{}, err
+	}
+	return result, nil
+}
+
+// transitionOrder applies a lifecycle change and keeps the hold in sync with it.
+// Repeating a transition the order already went through is a no-op. The
+// optional apply callback runs inside the transaction once the transition is
+// known to be valid; returning an error aborts it. A non-empty action records
+// the change in the audit log in the same transaction.
+func (s *OrderService) transitionOrder(
+	ctx context.Context,
+	orderID string,
+	next domain.OrderStatus,
+	action domain.AuditAction,
+	apply func(ctx context.Context, order *domain.Order) error,
+) (domain.Order, error) {
+	if orderID == "" {
+		return domain.Order{}, domain.ErrInvalidID
+	}
+
+	now := s.clock.Now()
+	var result domain.Order
+
+	err := s.repo.WithTx(ctx, func(txCtx context.Context) error {
+		order, err := s.repo.GetOrderForUpdate(txCtx, orderID)
+		if err != nil {
+			return err
+		}
+		if order.Status == next {
+			result = order
+			return nil
+		}
+		if !order.Status.CanTransitionTo(next) {
+			return domain.ErrInvalidOrderTransition
+		}
+		before := order
+
+		hold, err := s.repo.GetHoldForUpdate(txCtx, order.HoldID)
+		if err != nil {
+			return err
+		}
+		if next == domain.OrderStatusPaid {
+			if hold.Status != domain.HoldStatusActive || hold.IsExpiredAt(now) {
+				return domain.ErrHoldExpired
+			}
+		}
+
+		if apply != nil {
+			if err := apply(txCtx, &order); err != nil {
+				return err
+			}
+		}
+		if err := order.TransitionTo(next, now); err != nil {
+			return err
+		}
+		if err := s.repo.UpdateOrderStatus(txCtx, order); err != nil {
+			return err
+		}
+		if next == domain.OrderStatusPaid {
+			err = s.appendPaidEvents(txCtx, order, now)
+		} else {
+			err = s.appendOrderEvent(txCtx, orderTransitionEvents[next], order, now)
+		}
+		if err != nil {
+			return err
+		}
+		if action != "" {
+			if err := s.audit(txCtx, action, before, order, now); err != nil {
+				return err
+			}
+		}
+
+		switch next {
+		case domain.OrderStatusPaid:
+			err = s.repo.UpdateHoldStatus(txCtx, hold.ID, domain.HoldStatusConfirmed)
+		case domain.OrderStatusFailed, domain.OrderStatusCancelled:
+			err = s.repo.UpdateHoldStatus(txCtx, hold.ID, domain.HoldStatusReleased)
+		}
+		if err != nil {
+			return err
+		}
+
+		result = order
+		return nil
+	})
+	if err != nil {
+		return domain.Order{}, err
+	}
+	return result, nil
+}
+
+// appendPaidEvents announces a paid order: it is confirmed, and paid. Orders
+// that never get paid are never announced as confirmed.
+func (s *OrderService) appendPaidEvents(ctx context.Context, order domain.Order, now time.Time) error {
+	if err := s.appendOrderEvent(ctx, domain.OutboxOrderConfirmed, order, now); err != nil {
+		return err
+	}
+	return s.appendOrderEvent(ctx, domain.OutboxOrderPaid, order, now)
+}
+
+func (s *OrderService) audit(ctx context.Context, action domain.AuditAction, before, after domain.Order, now time.Time) error {
+	entry, err := newAuditEntry(ctx, after.OrganizerID, action, domain.AuditTargetOrder, after.ID, newOrderSnapshot(before), newOrderSnapshot(after), now)
+	if err != nil {
+		return err
+	}
+	return s.repo.AppendAuditEntry(ctx, entry)
+}
+
+func (s *OrderService) appendOrderEvent(ctx context.Context, eventType domain.OutboxEventType, order domain.Order, now time.Time) error {
+	event, err := newOrderOutboxEvent(eventType, order, now)
+	if err != nil {
+		return err
+	}
+	return s.repo.AppendOutboxEvent(ctx, event)
+}
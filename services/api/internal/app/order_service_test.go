@@ -2,6 +2,8 @@ package app
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"testing"
 	"time"
 
@@ -43,6 +45,9 @@ func TestOrderService_ConfirmHold(t *testing.T) {
 		if res.Order.IdempotencyKey != "idem-1" {
 			t.Fatalf("expected idempotency key idem-1, got %s", res.Order.IdempotencyKey)
 		}
+		if res.Order.Status != domain.OrderStatusPaid || res.Order.PaidAt == nil {
+			t.Fatalf("expected paid order, got %+v", res.Order)
+		}
 
 		hold := repo.holds["hold-1"]
 		if hold.Status != domain.HoldStatusConfirmed {
@@ -51,6 +56,9 @@ func TestOrderService_ConfirmHold(t *testing.T) {
 		if _, ok := repo.orders["hold-1"]; !ok {
 			t.Fatalf("expected order persisted")
 		}
+		if want := []string{"order.confirmed", "order.paid"}; !equalStrings(repo.outboxTypes(), want) {
+			t.Fatalf("expected outbox events %v, got %v", want, repo.outboxTypes())
+		}
 	})
 
 	t.Run("idempotent confirm returns existing order", func(t *testing.T) {
@@ -84,6 +92,9 @@ func TestOrderService_ConfirmHold(t *testing.T) {
 		if res.Order.ID != existing.ID {
 			t.Fatalf("expected existing order ID %s, got %s", existing.ID, res.Order.ID)
 		}
+		if len(repo.outbox) != 0 {
+			t.Fatalf("expected no outbox events on replay, got %v", repo.outboxTypes())
+		}
 	})
 
 	t.Run("different idempotency key after confirmed returns error", func(t *testing.T) {
@@ -150,6 +161,50 @@ func TestOrderService_ConfirmHold(t *testing.T) {
 		}
 	})
 
+	t.Run("customer email is validated and stored", func(t *testing.T) {
+		repo := newFakeOrderRepo(map[string]domain.Hold{
+			"hold-7": {ID: "hold-7", Status: domain.HoldStatusActive, ExpiresAt: now.Add(10 * time.Minute)},
+		})
+		svc := NewOrderService(repo, clock.NewFixed(now))
+
+		for _, email := range []string{"not-an-email", "Fan <fan@example.com>"} {
+			_, err := svc.ConfirmHold(context.Background(), ConfirmHoldInput{HoldID: "hold-7", IdempotencyKey: "idem-7", CustomerEmail: email})
+			if err != domain.ErrInvalidEmail {
+				t.Fatalf("expected ErrInvalidEmail for %q, got %v", email, err)
+			}
+		}
+
+		res, err := svc.ConfirmHold(context.Background(), ConfirmHoldInput{HoldID: "hold-7", IdempotencyKey: "idem-7", CustomerEmail: "fan@example.com"})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if res.Order.CustomerEmail != "fan@example.com" || repo.orders["hold-7"].CustomerEmail != "fan@example.com" {
+			t.Fatalf("expected customer email stored, got %+v", res.Order)
+		}
+	})
+
+	t.Run("customer hold is confirmable only by its owner", func(t *testing.T) {
+		repo := newFakeOrderRepo(map[string]domain.Hold{
+			"hold-8": {ID: "hold-8", Status: domain.HoldStatusActive, ExpiresAt: now.Add(10 * time.Minute), CustomerID: "cust-1"},
+		})
+		svc := NewOrderService(repo, clock.NewFixed(now))
+
+		for _, customerID := range []string{"", "cust-2"} {
+			_, err := svc.ConfirmHold(context.Background(), ConfirmHoldInput{HoldID: "hold-8", IdempotencyKey: "idem-8", CustomerID: customerID})
+			if err != domain.ErrHoldNotFound {
+				t.Fatalf("expected ErrHoldNotFound for customer %q, got %v", customerID, err)
+			}
+		}
+
+		res, err := svc.ConfirmHold(context.Background(), ConfirmHoldInput{HoldID: "hold-8", IdempotencyKey: "idem-8", CustomerID: "cust-1"})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if res.Order.CustomerID != "cust-1" || repo.orders["hold-8"].CustomerID != "cust-1" {
+			t.Fatalf("expected order owned by cust-1, got %+v", res.Order)
+		}
+	})
+
 	t.Run("missing hold returns error", func(t *testing.T) {
 		repo := newFakeOrderRepo(nil)
 		svc := NewOrderService(repo, clock.NewFixed(now))
@@ -195,9 +250,489 @@ func TestOrderService_ConfirmHold(t *testing.T) {
 	})
 }
 
+func TestOrderService_AwaitPayment(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, 1, 2, 10, 0, 0, 0, time.UTC)
+
+	newPending := func(t *testing.T, expiresAt time.Time) (*fakeOrderRepo, domain.Order) {
+		t.Helper()
+		repo := newFakeOrderRepo(map[string]domain.Hold{
+			"hold-1": {
+				ID:        "hold-1",
+				Status:    domain.HoldStatusActive,
+				ExpiresAt: expiresAt,
+			},
+		})
+		svc := NewOrderService(repo, clock.NewFixed(now))
+		res, err := svc.ConfirmHold(context.Background(), ConfirmHoldInput{
+			HoldID:         "hold-1",
+			IdempotencyKey: "idem-1",
+			AwaitPayment:   true,
+		})
+		if err != nil {
+			t.Fatalf("confirm hold: %v", err)
+		}
+		return repo, res.Order
+	}
+
+	t.Run("creates pending order and keeps hold active", func(t *testing.T) {
+		repo, order := newPending(t, now.Add(10*time.Minute))
+
+		if order.Status != domain.OrderStatusPendingPayment {
+			t.Fatalf("expected pending_payment, got %s", order.Status)
+		}
+		if order.PaidAt != nil {
+			t.Fatalf("expected paid_at unset, got %v", order.PaidAt)
+		}
+		if repo.holds["hold-1"].Status != domain.HoldStatusActive {
+			t.Fatalf("expected hold to stay active, got %s", repo.holds["hold-1"].Status)
+		}
+	})
+
+	t.Run("transitions emit outbox events in order", func(t *testing.T) {
+		repo, order := newPending(t, now.Add(10*time.Minute))
+		svc := NewOrderService(repo, clock.NewFixed(now))
+
+		if _, err := svc.MarkOrderPaid(context.Background(), order.ID); err != nil {
+			t.Fatalf("mark paid: %v", err)
+		}
+		if _, err := svc.MarkOrderPaid(context.Background(), order.ID); err != nil {
+			t.Fatalf("repeat mark paid: %v", err)
+		}
+		if _, err := svc.FulfillOrder(context.Background(), "org-1", order.ID); err != nil {
+			t.Fatalf("fulfill: %v", err)
+		}
+
+		want := []string{"order.pending_payment", "order.confirmed", "order.paid", "order.fulfilled"}
+		if !equalStrings(repo.outboxTypes(), want) {
+			t.Fatalf("expected outbox events %v, got %v", want, repo.outboxTypes())
+		}
+		for _, e := range repo.outbox {
+			if e.AggregateType != domain.OutboxAggregateOrder || e.AggregateID != order.ID {
+				t.Fatalf("expected events for order %s, got %+v", order.ID, e)
+			}
+		}
+	})
+
+	t.Run("unpaid orders are never announced as confirmed", func(t *testing.T) {
+		repo, order := newPending(t, now.Add(10*time.Minute))
+		svc := NewOrderService(repo, clock.NewFixed(now))
+
+		if _, err := svc.MarkOrderFailed(context.Background(), "org-1", order.ID); err != nil {
+			t.Fatalf("mark failed: %v", err)
+		}
+		if want := []string{"order.pending_payment", "order.failed"}; !equalStrings(repo.outboxTypes(), want) {
+			t.Fatalf("expected outbox events %v, got %v", want, repo.outboxTypes())
+		}
+	})
+
+	t.Run("payment success confirms hold", func(t *testing.T) {
+		repo, order := newPending(t, now.Add(10*time.Minute))
+		svc := NewOrderService(repo, clock.NewFixed(now.Add(time.Minute)))
+
+		paid, err := svc.MarkOrderPaid(context.Background(), order.ID)
+		if err != nil {
+			t.Fatalf("mark paid: %v", err)
+		}
+		if paid.Status != domain.OrderStatusPaid || paid.PaidAt == nil || !paid.PaidAt.Equal(now.Add(time.Minute)) {
+			t.Fatalf("unexpected paid order: %+v", paid)
+		}
+		if repo.holds["hold-1"].Status != domain.HoldStatusConfirmed {
+			t.Fatalf("expected hold confirmed, got %s", repo.holds["hold-1"].Status)
+		}
+
+		again, err := svc.MarkOrderPaid(context.Background(), order.ID)
+		if err != nil {
+			t.Fatalf("repeat mark paid: %v", err)
+		}
+		if again.Status != domain.OrderStatusPaid {
+			t.Fatalf("expected repeat to be a no-op, got %s", again.Status)
+		}
+	})
+
+	t.Run("payment failure releases hold", func(t *testing.T) {
+		repo, order := newPending(t, now.Add(10*time.Minute))
+		svc := NewOrderService(repo, clock.NewFixed(now))
+
+		failed, err := svc.MarkOrderFailed(context.Background(), "org-1", order.ID)
+		if err != nil {
+			t.Fatalf("mark failed: %v", err)
+		}
+		if failed.Status != domain.OrderStatusFailed || failed.FailedAt == nil {
+			t.Fatalf("unexpected failed order: %+v", failed)
+		}
+		if repo.holds["hold-1"].Status != domain.HoldStatusReleased {
+			t.Fatalf("expected hold released, got %s", repo.holds["hold-1"].Status)
+		}
+
+		if _, err := svc.MarkOrderPaid(context.Background(), order.ID); err != domain.ErrInvalidOrderTransition {
+			t.Fatalf("expected ErrInvalidOrderTransition, got %v", err)
+		}
+	})
+
+	t.Run("pending payment freezes hold expiry within grace window", func(t *testing.T) {
+		repo, order := newPending(t, now.Add(time.Minute))
+
+		pendingUntil := repo.holds["hold-1"].PaymentPendingUntil
+		if pendingUntil == nil || !pendingUntil.Equal(now.Add(defaultPaymentGracePeriod)) {
+			t.Fatalf("expected payment pending marker at %v, got %v", now.Add(defaultPaymentGracePeriod), pendingUntil)
+		}
+
+		svc := NewOrderService(repo, clock.NewFixed(now.Add(2*time.Minute)))
+		if _, err := svc.MarkOrderPaid(context.Background(), order.ID); err != nil {
+			t.Fatalf("expected payment within grace window to succeed, got %v", err)
+		}
+		if repo.holds["hold-1"].PaymentPendingUntil != nil {
+			t.Fatalf("expected marker cleared once paid")
+		}
+	})
+
+	t.Run("payment after grace window is rejected", func(t *testing.T) {
+		repo, order := newPending(t, now.Add(time.Minute))
+		svc := NewOrderService(repo, clock.NewFixed(now.Add(defaultPaymentGracePeriod+time.Second)))
+
+		if _, err := svc.MarkOrderPaid(context.Background(), order.ID); err != domain.ErrHoldExpired {
+			t.Fatalf("expected ErrHoldExpired, got %v", err)
+		}
+		if repo.orders["hold-1"].Status != domain.OrderStatusPendingPayment {
+			t.Fatalf("expected order to stay pending, got %s", repo.orders["hold-1"].Status)
+		}
+	})
+
+	t.Run("grace window is configurable", func(t *testing.T) {
+		repo := newFakeOrderRepo(map[string]domain.Hold{
+			"hold-1": {ID: "hold-1", Status: domain.HoldStatusActive, ExpiresAt: now.Add(time.Minute)},
+		})
+		svc := NewOrderService(repo, clock.NewFixed(now), WithPaymentGracePeriod(30*time.Second))
+		if _, err := svc.ConfirmHold(context.Background(), ConfirmHoldInput{HoldID: "hold-1", IdempotencyKey: "idem-1", AwaitPayment: true}); err != nil {
+			t.Fatalf("confirm: %v", err)
+		}
+		// The grace deadline is earlier than the TTL, so the TTL still applies.
+		if got := repo.holds["hold-1"].ReservedUntil(); !got.Equal(now.Add(time.Minute)) {
+			t.Fatalf("expected reservation until TTL, got %v", got)
+		}
+	})
+
+	t.Run("paid order can be fulfilled but not failed", func(t *testing.T) {
+		repo, order := newPending(t, now.Add(10*time.Minute))
+		svc := NewOrderService(repo, clock.NewFixed(now))
+
+		if _, err := svc.MarkOrderPaid(context.Background(), order.ID); err != nil {
+			t.Fatalf("mark paid: %v", err)
+		}
+		if _, err := svc.MarkOrderFailed(context.Background(), "org-1", order.ID); err != domain.ErrInvalidOrderTransition {
+			t.Fatalf("expected ErrInvalidOrderTransition, got %v", err)
+		}
+		fulfilled, err := svc.FulfillOrder(context.Background(), "org-1", order.ID)
+		if err != nil {
+			t.Fatalf("fulfill: %v", err)
+		}
+		if fulfilled.Status != domain.OrderStatusFulfilled || fulfilled.FulfilledAt == nil {
+			t.Fatalf("unexpected fulfilled order: %+v", fulfilled)
+		}
+	})
+
+	t.Run("cancellation is audited", func(t *testing.T) {
+		repo, order := newPending(t, now.Add(10*time.Minute))
+		svc := NewOrderService(repo, clock.NewFixed(now))
+		ctx := WithAuditContext(context.Background(), AuditContext{Actor: "api_key:key-1", RequestID: "req-1"})
+
+		if _, err := svc.CancelOrder(ctx, "org-1", order.ID); err != nil {
+			t.Fatalf("cancel: %v", err)
+		}
+		if _, err := svc.CancelOrder(ctx, "org-1", order.ID); err != nil {
+			t.Fatalf("cancel again: %v", err)
+		}
+		if len(repo.audits) != 1 {
+			t.Fatalf("expected one audit entry, got %+v", repo.audits)
+		}
+		entry := repo.audits[0]
+		if entry.Action != domain.AuditOrderCancelled || entry.TargetType != domain.AuditTargetOrder || entry.TargetID != order.ID ||
+			entry.Actor != "api_key:key-1" || entry.RequestID != "req-1" {
+			t.Fatalf("unexpected audit entry: %+v", entry)
+		}
+		var before, after orderSnapshot
+		if err := json.Unmarshal(entry.Before, &before); err != nil || before.Status != string(domain.OrderStatusPendingPayment) {
+			t.Fatalf("expected a pending snapshot before, got %s (%v)", entry.Before, err)
+		}
+		if err := json.Unmarshal(entry.After, &after); err != nil || after.Status != string(domain.OrderStatusCancelled) || after.CancelledAt == nil {
+			t.Fatalf("expected a cancelled snapshot after, got %s (%v)", entry.After, err)
+		}
+	})
+
+	t.Run("cancelling a paid order requests a refund", func(t *testing.T) {
+		repo, order := newPending(t, now.Add(10*time.Minute))
+		svc := NewOrderService(repo, clock.NewFixed(now), WithPaymentProvider(&stubPaymentProvider{}))
+
+		if _, _, err := svc.ApplyPaymentEvent(context.Background(), order.ID, domain.PaymentEvent{Type: domain.PaymentEventCaptured, PaymentReference: "ref-1"}); err != nil {
+			t.Fatalf("capture: %v", err)
+		}
+		cancelled, err := svc.CancelOrder(context.Background(), "org-1", order.ID)
+		if err != nil {
+			t.Fatalf("cancel: %v", err)
+		}
+		if cancelled.Status != domain.OrderStatusCancelled || repo.holds["hold-1"].Status != domain.HoldStatusReleased {
+			t.Fatalf("unexpected cancelled order %+v with hold %s", cancelled, repo.holds["hold-1"].Status)
+		}
+		refund := repo.outbox[len(repo.outbox)-1]
+		if refund.Type != domain.OutboxOrderRefundRequested || refund.AggregateID != order.ID {
+			t.Fatalf("expected a refund request in the outbox, got %+v", refund)
+		}
+		if len(repo.audits) != 2 || repo.audits[0].Action != domain.AuditOrderCancelled || repo.audits[1].Action != domain.AuditOrderRefundRequested {
+			t.Fatalf("expected the cancellation and the refund request to be audited, got %+v", repo.audits)
+		}
+
+		if _, err := svc.CancelOrder(context.Background(), "org-1", order.ID); err != nil {
+			t.Fatalf("cancel again: %v", err)
+		}
+		if got := len(repo.outbox); repo.outbox[got-1].ID != refund.ID {
+			t.Fatalf("expected a repeated cancellation not to request another refund, got %v", repo.outboxTypes())
+		}
+	})
+
+	t.Run("other organizers' orders are not found", func(t *testing.T) {
+		repo, order := newPending(t, now.Add(10*time.Minute))
+		svc := NewOrderService(repo, clock.NewFixed(now))
+
+		if _, err := svc.CancelOrder(context.Background(), "org-2", order.ID); err != domain.ErrOrderNotFound {
+			t.Fatalf("expected ErrOrderNotFound, got %v", err)
+		}
+		if repo.orders["hold-1"].Status != domain.OrderStatusPendingPayment {
+			t.Fatalf("expected order to stay pending, got %s", repo.orders["hold-1"].Status)
+		}
+	})
+
+	t.Run("other transitions are not audited", func(t *testing.T) {
+		repo, order := newPending(t, now.Add(10*time.Minute))
+		svc := NewOrderService(repo, clock.NewFixed(now))
+
+		if _, err := svc.MarkOrderPaid(context.Background(), order.ID); err != nil {
+			t.Fatalf("mark paid: %v", err)
+		}
+		if _, err := svc.FulfillOrder(context.Background(), "org-1", order.ID); err != nil {
+			t.Fatalf("fulfill: %v", err)
+		}
+		if len(repo.audits) != 0 {
+			t.Fatalf("expected no audit entries, got %+v", repo.audits)
+		}
+	})
+
+	t.Run("unknown order returns error", func(t *testing.T) {
+		repo := newFakeOrderRepo(nil)
+		svc := NewOrderService(repo, clock.NewFixed(now))
+
+		if _, err := svc.CancelOrder(context.Background(), "org-1", "missing"); err != domain.ErrOrderNotFound {
+			t.Fatalf("expected ErrOrderNotFound, got %v", err)
+		}
+	})
+}
+
+func TestOrderService_ConfirmHoldWithPayment(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, 1, 2, 10, 0, 0, 0, time.UTC)
+
+	newRepo := func() *fakeOrderRepo {
+		return newFakeOrderRepo(map[string]domain.Hold{
+			"hold-1": {
+				ID:        "hold-1",
+				Quantity:  2,
+				Status:    domain.HoldStatusActive,
+				ExpiresAt: now.Add(10 * time.Minute),
+			},
+		})
+	}
+	confirm := func(svc *OrderService) (ConfirmHoldResult, error) {
+		return svc.ConfirmHold(context.Background(), ConfirmHoldInput{
+			HoldID:         "hold-1",
+			IdempotencyKey: "idem-1",
+		})
+	}
+
+	t.Run("authorizes and captures before marking paid", func(t *testing.T) {
+		repo := newRepo()
+		provider := &stubPaymentProvider{}
+		svc := NewOrderService(repo, clock.NewFixed(now), WithPaymentProvider(provider))
+
+		res, err := confirm(svc)
+		if err != nil {
+			t.Fatalf("confirm: %v", err)
+		}
+		if !res.Created || res.Order.Status != domain.OrderStatusPaid {
+			t.Fatalf("expected created paid order, got %+v", res)
+		}
+		if res.Order.PaymentReference != "auth-1" {
+			t.Fatalf("expected payment reference auth-1, got %q", res.Order.PaymentReference)
+		}
+		if repo.holds["hold-1"].Status != domain.HoldStatusConfirmed {
+			t.Fatalf("expected hold confirmed, got %s", repo.holds["hold-1"].Status)
+		}
+		want := []string{"authorize:idem-1", "capture:idem-1:capture"}
+		if !equalStrings(provider.calls, want) {
+			t.Fatalf("expected calls %v, got %v", want, provider.calls)
+		}
+		if provider.quantity != 2 {
+			t.Fatalf("expected quantity 2 sent to provider, got %d", provider.quantity)
+		}
+	})
+
+	t.Run("declined payment fails order and releases hold", func(t *testing.T) {
+		repo := newRepo()
+		provider := &stubPaymentProvider{authorizeErr: domain.ErrPaymentDeclined}
+		svc := NewOrderService(repo, clock.NewFixed(now), WithPaymentProvider(provider))
+
+		if _, err := confirm(svc); err != domain.ErrPaymentDeclined {
+			t.Fatalf("expected ErrPaymentDeclined, got %v", err)
+		}
+		if repo.orders["hold-1"].Status != domain.OrderStatusFailed {
+			t.Fatalf("expected order failed, got %s", repo.orders["hold-1"].Status)
+		}
+		if repo.holds["hold-1"].Status != domain.HoldStatusReleased {
+			t.Fatalf("expected hold released, got %s", repo.holds["hold-1"].Status)
+		}
+	})
+
+	t.Run("timeout keeps order pending until retried", func(t *testing.T) {
+		repo := newRepo()
+		provider := &stubPaymentProvider{authorizeErr: domain.ErrPaymentUnavailable}
+		svc := NewOrderService(repo, clock.NewFixed(now), WithPaymentProvider(provider))
+
+		if _, err := confirm(svc); err != domain.ErrPaymentUnavailable {
+			t.Fatalf("expected ErrPaymentUnavailable, got %v", err)
+		}
+		if repo.orders["hold-1"].Status != domain.OrderStatusPendingPayment {
+			t.Fatalf("expected order pending, got %s", repo.orders["hold-1"].Status)
+		}
+
+		provider.authorizeErr = nil
+		res, err := confirm(svc)
+		if err != nil {
+			t.Fatalf("retry confirm: %v", err)
+		}
+		if res.Created || res.Order.Status != domain.OrderStatusPaid {
+			t.Fatalf("expected existing order to be paid on retry, got %+v", res)
+		}
+	})
+
+	t.Run("capture failure voids authorization", func(t *testing.T) {
+		repo := newRepo()
+		captureErr := errors.New("capture failed")
+		provider := &stubPaymentProvider{captureErr: captureErr}
+		svc := NewOrderService(repo, clock.NewFixed(now), WithPaymentProvider(provider))
+
+		if _, err := confirm(svc); err != captureErr {
+			t.Fatalf("expected capture error, got %v", err)
+		}
+		want := []string{"authorize:idem-1", "capture:idem-1:capture", "void:idem-1:void"}
+		if !equalStrings(provider.calls, want) {
+			t.Fatalf("expected calls %v, got %v", want, provider.calls)
+		}
+		if repo.orders["hold-1"].Status != domain.OrderStatusFailed {
+			t.Fatalf("expected order failed, got %s", repo.orders["hold-1"].Status)
+		}
+	})
+
+	t.Run("captures outside the transaction", func(t *testing.T) {
+		repo := newRepo()
+		provider := &stubPaymentProvider{}
+		provider.onCapture = func() {
+			if repo.inTx != 0 {
+				t.Errorf("capture ran inside a transaction")
+			}
+		}
+		svc := NewOrderService(repo, clock.NewFixed(now), WithPaymentProvider(provider))
+
+		if _, err := confirm(svc); err != nil {
+			t.Fatalf("confirm: %v", err)
+		}
+	})
+
+	t.Run("hold lapsing during capture requests a refund", func(t *testing.T) {
+		repo := newRepo()
+		provider := &stubPaymentProvider{}
+		provider.onCapture = func() {
+			hold := repo.holds["hold-1"]
+			hold.Status = domain.HoldStatusExpired
+			repo.holds["hold-1"] = hold
+		}
+		svc := NewOrderService(repo, clock.NewFixed(now), WithPaymentProvider(provider))
+
+		if _, err := confirm(svc); err != domain.ErrHoldExpired {
+			t.Fatalf("expected ErrHoldExpired, got %v", err)
+		}
+		want := []string{"authorize:idem-1", "capture:idem-1:capture"}
+		if !equalStrings(provider.calls, want) {
+			t.Fatalf("expected calls %v, got %v", want, provider.calls)
+		}
+		order := repo.orders["hold-1"]
+		if order.Status != domain.OrderStatusFailed || order.PaymentReference != "auth-1" {
+			t.Fatalf("expected failed order with reference auth-1, got %+v", order)
+		}
+		types := repo.outboxTypes()
+		if len(types) == 0 || types[len(types)-1] != string(domain.OutboxOrderRefundRequested) {
+			t.Fatalf("expected refund requested through the outbox, got %v", types)
+		}
+		if len(repo.audits) != 1 || repo.audits[0].Action != domain.AuditOrderRefundRequested || repo.audits[0].Actor != domain.AuditActorSystem {
+			t.Fatalf("expected the refund request to be audited, got %+v", repo.audits)
+		}
+	})
+}
+
+type stubPaymentProvider struct {
+	authorizeErr error
+	captureErr   error
+	refundErr    error
+	onCapture    func()
+	calls        []string
+	quantity     int
+}
+
+func (p *stubPaymentProvider) Authorize(_ context.Context, req PaymentRequest) (PaymentAuthorization, error) {
+	p.calls = append(p.calls, "authorize:"+req.IdempotencyKey)
+	p.quantity = req.Quantity
+	if p.authorizeErr != nil {
+		return PaymentAuthorization{}, p.authorizeErr
+	}
+	return PaymentAuthorization{Reference: "auth-1"}, nil
+}
+
+func (p *stubPaymentProvider) Capture(_ context.Context, op PaymentOperation) error {
+	p.calls = append(p.calls, "capture:"+op.IdempotencyKey)
+	if p.onCapture != nil {
+		p.onCapture()
+	}
+	return p.captureErr
+}
+
+func (p *stubPaymentProvider) Void(_ context.Context, op PaymentOperation) error {
+	p.calls = append(p.calls, "void:"+op.IdempotencyKey)
+	return nil
+}
+
+func (p *stubPaymentProvider) Refund(_ context.Context, op PaymentOperation) error {
+	p.calls = append(p.calls, "refund:"+op.IdempotencyKey)
+	return p.refundErr
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
 type fakeOrderRepo struct {
 	holds  map[string]domain.Hold
 	orders map[string]domain.Order
+	outbox []domain.OutboxEvent
+	audits []domain.AuditEntry
+	inTx   int
 }
 
 func newFakeOrderRepo(holds map[string]domain.Hold) *fakeOrderRepo {
@@ -211,6 +746,8 @@ func newFakeOrderRepo(holds map[string]domain.Hold) *fakeOrderRepo {
 }
 
 func (f *fakeOrderRepo) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	f.inTx++
+	defer func() { f.inTx-- }()
 	return fn(ctx)
 }
 
@@ -235,6 +772,25 @@ func (f *fakeOrderRepo) CreateOrder(_ context.Context, order domain.Order) error
 	if _, exists := f.orders[order.HoldID]; exists {
 		return domain.ErrHoldAlreadyConfirmed
 	}
+	// Like the database, which copies it from the hold's event.
+	order.OrganizerID = "org-1"
+	f.orders[order.HoldID] = order
+	return nil
+}
+
+func (f *fakeOrderRepo) GetOrderForUpdate(_ context.Context, orderID string) (domain.Order, error) {
+	for _, order := range f.orders {
+		if order.ID == orderID {
+			return order, nil
+		}
+	}
+	return domain.Order{}, domain.ErrOrderNotFound
+}
+
+func (f *fakeOrderRepo) UpdateOrderStatus(_ context.Context, order domain.Order) error {
+	if _, ok := f.orders[order.HoldID]; !ok {
+		return domain.ErrOrderNotFound
+	}
 	f.orders[order.HoldID] = order
 	return nil
 }
@@ -245,10 +801,39 @@ func (f *fakeOrderRepo) UpdateHoldStatus(_ context.Context, holdID string, statu
 		return domain.ErrHoldNotFound
 	}
 	hold.Status = status
+	hold.PaymentPendingUntil = nil
+	f.holds[holdID] = hold
+	return nil
+}
+
+func (f *fakeOrderRepo) MarkHoldPaymentPending(_ context.Context, holdID string, until time.Time) error {
+	hold, ok := f.holds[holdID]
+	if !ok {
+		return domain.ErrHoldNotFound
+	}
+	hold.PaymentPendingUntil = &until
 	f.holds[holdID] = hold
 	return nil
 }
 
+func (f *fakeOrderRepo) AppendOutboxEvent(_ context.Context, event domain.OutboxEvent) error {
+	f.outbox = append(f.outbox, event)
+	return nil
+}
+
+func (f *fakeOrderRepo) AppendAuditEntry(_ context.Context, entry domain.AuditEntry) error {
+	f.audits = append(f.audits, entry)
+	return nil
+}
+
+func (f *fakeOrderRepo) outboxTypes() []string {
+	types := make([]string, 0, len(f.outbox))
+	for _, e := range f.outbox {
+		types = append(types, string(e.Type))
+	}
+	return types
+}
+
 type raceOrderRepo struct {
 	hold   domain.Hold
 	order  domain.Order
@@ -278,6 +863,26 @@ func (r *raceOrderRepo) CreateOrder(_ context.Context, _ domain.Order) error {
 	return domain.ErrHoldAlreadyConfirmed
 }
 
+func (r *raceOrderRepo) GetOrderForUpdate(_ context.Context, _ string) (domain.Order, error) {
+	return r.order, nil
+}
+
+func (r *raceOrderRepo) UpdateOrderStatus(_ context.Context, _ domain.Order) error {
+	return nil
+}
+
 func (r *raceOrderRepo) UpdateHoldStatus(_ context.Context, _ string, _ domain.HoldStatus) error {
 	return nil
 }
+
+func (r *raceOrderRepo) MarkHoldPaymentPending(_ context.Context, _ string, _ time.Time) error {
+	return nil
+}
+
+func (r *raceOrderRepo) AppendAuditEntry(_ context.Context, _ domain.AuditEntry) error {
+	return nil
+}
+
+func (r *raceOrderRepo) AppendOutboxEvent(_ context.Context, _ domain.OutboxEvent) error {
+	return nil
+}
@@ -0,0 +1,83 @@
+package app
+
+import (
+	"context"
+	"strings"
+
+	"github.com/cimillas/ultimate-ticket/services/api/internal/clock"
+	"github.com/cimillas/ultimate-ticket/services/api/internal/domain"
+)
+
+type OrganizerRepository interface {
+	WithTx(ctx context.Context, fn func(ctx context.Context) error) error
+	CreateOrganizer(ctx context.Context, organizer domain.Organizer) error
+	AppendAuditEntry(ctx context.Context, entry domain.AuditEntry) error
+}
+
+// OrganizerService onboards organizers (tenants). Each organizer starts with
+// one owner key, which can then manage the organizer's other keys.
+type OrganizerService struct {
+	repo  OrganizerRepository
+	keys  *APIKeyService
+	clock clock.Clock
+}
+
+func NewOrganizerService(repo OrganizerRepository, keys *APIKeyService, clk clock.Clock) *OrganizerService {
+	return &OrganizerService{
+		repo:  repo,
+		keys:  keys,
+		clock: clk,
+	}
+}
+
+type CreateOrganizerInput struct {
+	Name         string
+	OwnerKeyName string
+}
+
+type CreatedOrganizer struct {
+	Organizer domain.Organizer
+	OwnerKey  CreatedAPIKey
+}
+
+// CreateOrganizer creates the organizer and its first owner key together.
+func (s *OrganizerService) CreateOrganizer(ctx context.Context, in CreateOrganizerInput) (CreatedOrganizer, error) {
+	name := strings.TrimSpace(in.Name)
+	if name == "" {
+		return CreatedOrganizer{}, domain.ErrOrganizerNameRequired
+	}
+
+	organizer := domain.Organizer{
+		ID:        newUUID(),
+		Name:      name,
+		CreatedAt: s.clock.Now(),
+	}
+	var created CreatedOrganizer
+	err := s.repo.WithTx(ctx, func(txCtx context.Context) error {
+		if err := s.repo.CreateOrganizer(txCtx, organizer); err != nil {
+			return err
+		}
+		entry, err := newAuditEntry(txCtx, organizer.ID, domain.AuditOrganizerCreated, domain.AuditTargetOrganizer, organizer.ID,
+			nil, organizerSnapshot{ID: organizer.ID, Name: organizer.Name}, organizer.CreatedAt)
+		if err != nil {
+			return err
+		}
+		if err := s.repo.AppendAuditEntry(txCtx, entry); err != nil {
+			return err
+		}
+		key, err := s.keys.CreateKey(txCtx, CreateAPIKeyInput{
+			OrganizerID: organizer.ID,
+			Name:        in.OwnerKeyName,
+			Role:        domain.RoleOwner,
+		})
+		if err != nil {
+			return err
+		}
+		created = CreatedOrganizer{Organizer: organizer, OwnerKey: key}
+		return nil
+	})
+	if err != nil {
+		return CreatedOrganizer{}, err
+	}
+	return created, nil
+}
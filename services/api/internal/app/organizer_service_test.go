@@ -0,0 +1,71 @@
+package app
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cimillas/ultimate-ticket/services/api/internal/clock"
+	"github.com/cimillas/ultimate-ticket/services/api/internal/domain"
+)
+
+func TestOrganizerService_CreateOrganizer(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, 1, 2, 10, 0, 0, 0, time.UTC)
+	newSvc := func(repo *fakeOrganizerRepo, keys *fakeAPIKeyRepo) *OrganizerService {
+		clk := clock.NewFixed(now)
+		return NewOrganizerService(repo, NewAPIKeyService(keys, clk), clk)
+	}
+
+	t.Run("creates organizer with an owner key", func(t *testing.T) {
+		repo, keys := &fakeOrganizerRepo{}, newFakeAPIKeyRepo()
+		svc := newSvc(repo, keys)
+
+		created, err := svc.CreateOrganizer(context.Background(), CreateOrganizerInput{Name: " Acme Live ", OwnerKeyName: "acme owner"})
+		if err != nil {
+			t.Fatalf("create organizer: %v", err)
+		}
+		if created.Organizer.Name != "Acme Live" || created.Organizer.ID == "" || len(repo.organizers) != 1 {
+			t.Fatalf("unexpected organizer %+v", created.Organizer)
+		}
+		key := created.OwnerKey.Key
+		if key.OrganizerID != created.Organizer.ID || key.Role != domain.RoleOwner || created.OwnerKey.Secret == "" {
+			t.Fatalf("expected owner key bound to organizer, got %+v", key)
+		}
+		listed, err := svc.keys.ListKeys(context.Background(), created.Organizer.ID)
+		if err != nil || len(listed) != 1 {
+			t.Fatalf("expected one key for organizer, got %d (%v)", len(listed), err)
+		}
+	})
+
+	t.Run("validates names", func(t *testing.T) {
+		svc := newSvc(&fakeOrganizerRepo{}, newFakeAPIKeyRepo())
+
+		if _, err := svc.CreateOrganizer(context.Background(), CreateOrganizerInput{Name: " ", OwnerKeyName: "owner"}); err != domain.ErrOrganizerNameRequired {
+			t.Fatalf("expected ErrOrganizerNameRequired, got %v", err)
+		}
+		if _, err := svc.CreateOrganizer(context.Background(), CreateOrganizerInput{Name: "Acme"}); err != domain.ErrAPIKeyNameRequired {
+			t.Fatalf("expected ErrAPIKeyNameRequired, got %v", err)
+		}
+	})
+}
+
+type fakeOrganizerRepo struct {
+	organizers []domain.Organizer
+	audit      []domain.AuditEntry
+}
+
+func (f *fakeOrganizerRepo) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+func (f *fakeOrganizerRepo) AppendAuditEntry(_ context.Context, entry domain.AuditEntry) error {
+	f.audit = append(f.audit, entry)
+	return nil
+}
+
+func (f *fakeOrganizerRepo) CreateOrganizer(_ context.Context, organizer domain.Organizer) error {
+	f.organizers = append(f.organizers, organizer)
+	return nil
+}
@@ -0,0 +1,291 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/cimillas/ultimate-ticket/services/api/internal/clock"
+	"github.com/cimillas/ultimate-ticket/services/api/internal/domain"
+)
+
+// OutboxPublisher delivers outbox events to downstream systems. Delivery is
+// at least once: a publisher may see the same event ID again after a crash and
+// should deduplicate on it.
+type OutboxPublisher interface {
+	Publish(ctx context.Context, event domain.OutboxEvent) error
+}
+
+type OutboxRepository interface {
+	WithTx(ctx context.Context, fn func(ctx context.Context) error) error
+	// ClaimOutboxEvents returns up to limit pending events that are due at now,
+	// in Seq order, skipping those behind a not yet due event of the same
+	// aggregate, and pushes their availability to leaseUntil so later claims
+	// skip them and their aggregates until then. It reports false if another
+	// relay is claiming at the same time.
+	ClaimOutboxEvents(ctx context.Context, now, leaseUntil time.Time, limit int) ([]domain.OutboxEvent, bool, error)
+	MarkOutboxPublished(ctx context.Context, seq int64, at time.Time) error
+	MarkOutboxFailed(ctx context.Context, seq int64, lastError string, availableAt time.Time) error
+	// MarkOutboxDead dead-letters an event that used up its attempts.
+	MarkOutboxDead(ctx context.Context, seq int64, lastError string) error
+	// ReleaseOutboxEvent hands a claimed event back unpublished, due at
+	// availableAt, without counting an attempt.
+	ReleaseOutboxEvent(ctx context.Context, seq int64, availableAt time.Time) error
+}
+
+type holdEventPayload struct {
+	HoldID    string    `json:"hold_id"`
+	EventID   string    `json:"event_id"`
+	ZoneID    string    `json:"zone_id"`
+	Quantity  int       `json:"quantity"`
+	Status    string    `json:"status"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type orderEventPayload struct {
+	OrderID          string `json:"order_id"`
+	HoldID           string `json:"hold_id"`
+	Status           string `json:"status"`
+	PaymentReference string `json:"payment_reference,omitempty"`
+}
+
+func newHoldOutboxEvent(eventType domain.OutboxEventType, hold domain.Hold, now time.Time) (domain.OutboxEvent, error) {
+	return newOutboxEvent(domain.OutboxAggregateHold, hold.ID, eventType, holdEventPayload{
+		HoldID:    hold.ID,
+		EventID:   hold.EventID,
+		ZoneID:    hold.ZoneID,
+		Quantity:  hold.Quantity,
+		Status:    string(hold.Status),
+		ExpiresAt: hold.ExpiresAt,
+	}, now)
+}
+
+func newOrderOutboxEvent(eventType domain.OutboxEventType, order domain.Order, now time.Time) (domain.OutboxEvent, error) {
+	return newOutboxEvent(domain.OutboxAggregateOrder, order.ID, eventType, orderEventPayload{
+		OrderID:          order.ID,
+		HoldID:           order.HoldID,
+		Status:           string(order.Status),
+		PaymentReference: order.PaymentReference,
+	}, now)
+}
+
+func newOutboxEvent(aggregateType, aggregateID string, eventType domain.OutboxEventType, payload any, now time.Time) (domain.OutboxEvent, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return domain.OutboxEvent{}, fmt.Errorf("encode %s payload: %w", eventType, err)
+	}
+	return domain.OutboxEvent{
+		ID:            newUUID(),
+		AggregateType: aggregateType,
+		AggregateID:   aggregateID,
+		Type:          eventType,
+		Payload:       data,
+		CreatedAt:     now,
+		AvailableAt:   now,
+	}, nil
+}
+
+// orderTransitionEvents maps order lifecycle changes to the events they emit.
+// Paying an order also emits OutboxOrderConfirmed first.
+var orderTransitionEvents = map[domain.OrderStatus]domain.OutboxEventType{
+	domain.OrderStatusFailed:    domain.OutboxOrderFailed,
+	domain.OrderStatusCancelled: domain.OutboxOrderCancelled,
+	domain.OrderStatusFulfilled: domain.OutboxOrderFulfilled,
+}
+
+// OutboxRelay delivers outbox events through a publisher. Events of an
+// aggregate are delivered in order: when one fails, the aggregate's later
+// events wait until it has been retried successfully, or until it used up its
+// attempts and was dead-lettered.
+type OutboxRelay struct {
+	repo        OutboxRepository
+	publisher   OutboxPublisher
+	clock       clock.Clock
+	logger      *slog.Logger
+	metrics     OutboxMetrics
+	batchSize   int
+	lease       time.Duration
+	retryDelay  time.Duration
+	maxDelay    time.Duration
+	maxAttempts int
+}
+
+const (
+	defaultOutboxBatchSize   = 100
+	defaultOutboxLease       = time.Minute
+	defaultOutboxRetryDelay  = time.Second
+	defaultOutboxMaxDelay    = 5 * time.Minute
+	defaultOutboxMaxAttempts = 20
+)
+
+func NewOutboxRelay(repo OutboxRepository, publisher OutboxPublisher, clk clock.Clock, opts ...OutboxRelayOption) *OutboxRelay {
+	r := &OutboxRelay{
+		repo:        repo,
+		publisher:   publisher,
+		clock:       clk,
+		logger:      slog.Default(),
+		batchSize:   defaultOutboxBatchSize,
+		lease:       defaultOutboxLease,
+		retryDelay:  defaultOutboxRetryDelay,
+		maxDelay:    defaultOutboxMaxDelay,
+		maxAttempts: defaultOutboxMaxAttempts,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+type OutboxRelayOption func(*OutboxRelay)
+
+// WithOutboxBatchSize overrides how many events one relay pass reads.
+func WithOutboxBatchSize(n int) OutboxRelayOption {
+	return func(r *OutboxRelay) {
+		if n > 0 {
+			r.batchSize = n
+		}
+	}
+}
+
+// WithOutboxLease sets how long one relay pass may spend publishing the events
+// it claimed before other relays may claim them again.
+func WithOutboxLease(d time.Duration) OutboxRelayOption {
+	return func(r *OutboxRelay) {
+		if d > 0 {
+			r.lease = d
+		}
+	}
+}
+
+// WithOutboxLogger sets where dead-lettered events are reported; the default
+// is slog.Default().
+func WithOutboxLogger(l *slog.Logger) OutboxRelayOption {
+	return func(r *OutboxRelay) {
+		if l != nil {
+			r.logger = l
+		}
+	}
+}
+
+// WithOutboxMetrics reports dead-lettered events to m.
+func WithOutboxMetrics(m OutboxMetrics) OutboxRelayOption {
+	return func(r *OutboxRelay) {
+		r.metrics = m
+	}
+}
+
+// WithOutboxRetryDelay sets the first retry delay; it doubles per failed
+// attempt up to maxDelay.
+func WithOutboxRetryDelay(initial, maxDelay time.Duration) OutboxRelayOption {
+	return func(r *OutboxRelay) {
+		if initial > 0 {
+			r.retryDelay = initial
+		}
+		if maxDelay >= r.retryDelay {
+			r.maxDelay = maxDelay
+		}
+	}
+}
+
+// WithOutboxMaxAttempts sets how many deliveries an event gets before it is
+// dead-lettered.
+func WithOutboxMaxAttempts(n int) OutboxRelayOption {
+	return func(r *OutboxRelay) {
+		if n > 0 {
+			r.maxAttempts = n
+		}
+	}
+}
+
+// RelayOnce publishes the pending events that are due and returns how many
+// were delivered. Events are claimed with a lease in a short transaction and
+// published outside it, so no lock is held while publishers call other
+// systems. Events are marked published only after the publisher accepted
+// them, so a crash in between leads to a redelivery once the lease ends,
+// never a loss. Publishing stops when the lease runs out, and the events left
+// over are handed back.
+func (r *OutboxRelay) RelayOnce(ctx context.Context) (int, error) {
+	now := r.clock.Now()
+	leaseUntil := now.Add(r.lease)
+	var events []domain.OutboxEvent
+	err := r.repo.WithTx(ctx, func(txCtx context.Context) error {
+		var err error
+		events, _, err = r.repo.ClaimOutboxEvents(txCtx, now, leaseUntil, r.batchSize)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	published := 0
+	blocked := make(map[string]bool)
+	for _, event := range events {
+		key := event.AggregateType + ":" + event.AggregateID
+		remaining := leaseUntil.Sub(r.clock.Now())
+		if blocked[key] || remaining <= 0 {
+			if err := r.repo.ReleaseOutboxEvent(ctx, event.Seq, now); err != nil {
+				return published, err
+			}
+			continue
+		}
+
+		pubCtx, cancel := context.WithTimeout(ctx, remaining)
+		pubErr := r.publisher.Publish(pubCtx, event)
+		cancel()
+		if pubErr != nil {
+			if event.Attempts+1 >= r.maxAttempts {
+				// A poisoned event must not hold back its aggregate forever.
+				if err := r.repo.MarkOutboxDead(ctx, event.Seq, pubErr.Error()); err != nil {
+					return published, err
+				}
+				r.reportDead(event, pubErr)
+				continue
+			}
+			blocked[key] = true
+			next := r.clock.Now().Add(backoff(r.retryDelay, r.maxDelay, event.Attempts+1))
+			if err := r.repo.MarkOutboxFailed(ctx, event.Seq, pubErr.Error(), next); err != nil {
+				return published, err
+			}
+			continue
+		}
+		if err := r.repo.MarkOutboxPublished(ctx, event.Seq, r.clock.Now()); err != nil {
+			return published, err
+		}
+		published++
+	}
+	return published, nil
+}
+
+// reportDead logs a dead-lettered event at error level and counts it. A dead
+// refund request means a customer was charged and not refunded, so it is
+// called out for manual follow-up.
+func (r *OutboxRelay) reportDead(event domain.OutboxEvent, err error) {
+	if r.metrics != nil {
+		r.metrics.OutboxEventDead(string(event.Type))
+	}
+	msg := "outbox event dead-lettered"
+	if event.Type == domain.OutboxOrderRefundRequested {
+		msg = "refund request dead-lettered, refund the payment manually"
+	}
+	r.logger.Error(msg,
+		"event_id", event.ID,
+		"type", string(event.Type),
+		"aggregate_id", event.AggregateID,
+		"attempts", event.Attempts+1,
+		"error", err.Error())
+}
+
+// backoff returns the delay before retry number attempt: initial, doubling per
+// attempt and capped at maxDelay.
+func backoff(initial, maxDelay time.Duration, attempt int) time.Duration {
+	delay := initial
+	for i := 1; i < attempt && delay < maxDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxDelay {
+		delay = maxDelay
+	}
+	return delay
+}
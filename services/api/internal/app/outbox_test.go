@@ -0,0 +1,319 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cimillas/ultimate-ticket/services/api/internal/clock"
+	"github.com/cimillas/ultimate-ticket/services/api/internal/domain"
+)
+
+func TestOutboxRelay_RelayOnce(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, 1, 3, 9, 0, 0, 0, time.UTC)
+	event := func(seq int64, aggregateID string) domain.OutboxEvent {
+		return domain.OutboxEvent{
+			Seq:           seq,
+			ID:            aggregateID + "-" + string(rune('a'+seq)),
+			AggregateType: domain.OutboxAggregateOrder,
+			AggregateID:   aggregateID,
+			AvailableAt:   now,
+		}
+	}
+
+	t.Run("publishes events in order and marks them", func(t *testing.T) {
+		repo := &fakeOutboxRepo{owned: true, events: []domain.OutboxEvent{event(1, "o1"), event(2, "o2"), event(3, "o1")}}
+		pub := &stubOutboxPublisher{}
+		relay := NewOutboxRelay(repo, pub, clock.NewFixed(now))
+
+		n, err := relay.RelayOnce(context.Background())
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if n != 3 {
+			t.Fatalf("expected 3 published, got %d", n)
+		}
+		if want := []int64{1, 2, 3}; !equalSeqs(pub.published, want) || !equalSeqs(repo.published, want) {
+			t.Fatalf("expected %v published, got publisher %v repo %v", want, pub.published, repo.published)
+		}
+	})
+
+	t.Run("failure holds back later events of the same aggregate", func(t *testing.T) {
+		repo := &fakeOutboxRepo{owned: true, events: []domain.OutboxEvent{event(1, "o1"), event(2, "o2"), event(3, "o1")}}
+		pub := &stubOutboxPublisher{fail: map[int64]bool{1: true}}
+		relay := NewOutboxRelay(repo, pub, clock.NewFixed(now), WithOutboxRetryDelay(time.Second, time.Minute))
+
+		n, err := relay.RelayOnce(context.Background())
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if n != 1 || !equalSeqs(pub.published, []int64{2}) {
+			t.Fatalf("expected only seq 2 published, got %d %v", n, pub.published)
+		}
+		if len(repo.failed) != 1 || repo.failed[0].seq != 1 || !repo.failed[0].availableAt.Equal(now.Add(time.Second)) {
+			t.Fatalf("expected seq 1 rescheduled in 1s, got %+v", repo.failed)
+		}
+	})
+
+	t.Run("events not yet due block their aggregate", func(t *testing.T) {
+		delayed := event(1, "o1")
+		delayed.AvailableAt = now.Add(time.Minute)
+		repo := &fakeOutboxRepo{owned: true, events: []domain.OutboxEvent{delayed, event(2, "o1"), event(3, "o2")}}
+		pub := &stubOutboxPublisher{}
+		relay := NewOutboxRelay(repo, pub, clock.NewFixed(now))
+
+		if _, err := relay.RelayOnce(context.Background()); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if !equalSeqs(pub.published, []int64{3}) {
+			t.Fatalf("expected only seq 3 published, got %v", pub.published)
+		}
+	})
+
+	t.Run("a poisoned event neither stalls the relay nor its aggregate", func(t *testing.T) {
+		repo := &fakeOutboxRepo{owned: true, events: []domain.OutboxEvent{event(1, "o1"), event(2, "o1"), event(3, "o2"), event(4, "o3")}}
+		pub := &stubOutboxPublisher{fail: map[int64]bool{1: true}}
+		relayAt := func(at time.Time) int {
+			t.Helper()
+			relay := NewOutboxRelay(repo, pub, clock.NewFixed(at),
+				WithOutboxBatchSize(2), WithOutboxRetryDelay(time.Second, time.Minute), WithOutboxMaxAttempts(3))
+			n, err := relay.RelayOnce(context.Background())
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			return n
+		}
+
+		if n := relayAt(now); n != 0 {
+			t.Fatalf("expected the first batch to hold only o1, got %d published", n)
+		}
+		if n := relayAt(now); n != 2 || !equalSeqs(pub.published, []int64{3, 4}) {
+			t.Fatalf("expected the other aggregates to be published while seq 1 waits, got %d %v", n, pub.published)
+		}
+		relayAt(now.Add(time.Second))
+		if len(repo.dead) != 0 {
+			t.Fatalf("expected seq 1 to be retried, got dead %v", repo.dead)
+		}
+		if n := relayAt(now.Add(time.Hour)); n != 1 || !equalSeqs(repo.dead, []int64{1}) {
+			t.Fatalf("expected seq 1 dead-lettered after 3 attempts and seq 2 published, got %d dead %v", n, repo.dead)
+		}
+		if !equalSeqs(pub.published, []int64{3, 4, 2}) {
+			t.Fatalf("unexpected publish order %v", pub.published)
+		}
+	})
+
+	t.Run("claimed events are leased to the relay pass", func(t *testing.T) {
+		repo := &fakeOutboxRepo{owned: true, events: []domain.OutboxEvent{event(1, "o1"), event(2, "o1"), event(3, "o2")}}
+		pub := &stubOutboxPublisher{fail: map[int64]bool{1: true}}
+		pub.onPublish = func(ctx context.Context) {
+			// Publishers run outside the claim transaction, bounded by the lease.
+			if deadline, ok := ctx.Deadline(); !ok || time.Until(deadline) > time.Minute {
+				t.Errorf("expected the publish to be bounded by the lease, got %v %v", deadline, ok)
+			}
+			for _, e := range repo.events {
+				if e.Seq == 3 && !e.AvailableAt.Equal(now.Add(time.Minute)) {
+					t.Errorf("expected claimed events leased for a minute, got %v", e.AvailableAt)
+				}
+			}
+		}
+		relay := NewOutboxRelay(repo, pub, clock.NewFixed(now), WithOutboxLease(time.Minute))
+
+		if _, err := relay.RelayOnce(context.Background()); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if !equalSeqs(repo.released, []int64{2}) || !repo.events[1].AvailableAt.Equal(now) {
+			t.Fatalf("expected seq 2, held back by seq 1, to be released, got %v", repo.released)
+		}
+	})
+
+	t.Run("publishing stops when the lease runs out", func(t *testing.T) {
+		clk := &steppingClock{now: now, step: 40 * time.Second}
+		repo := &fakeOutboxRepo{owned: true, events: []domain.OutboxEvent{event(1, "o1"), event(2, "o2"), event(3, "o3")}}
+		pub := &stubOutboxPublisher{}
+		relay := NewOutboxRelay(repo, pub, clk, WithOutboxLease(time.Minute))
+
+		n, err := relay.RelayOnce(context.Background())
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if n != 1 || !equalSeqs(repo.released, []int64{2, 3}) {
+			t.Fatalf("expected one event published and the rest released, got %d released %v", n, repo.released)
+		}
+	})
+
+	t.Run("dead-lettered refunds are logged and counted", func(t *testing.T) {
+		refund := event(1, "o1")
+		refund.Type = domain.OutboxOrderRefundRequested
+		refund.Attempts = 2
+		repo := &fakeOutboxRepo{owned: true, events: []domain.OutboxEvent{refund}}
+		pub := &stubOutboxPublisher{fail: map[int64]bool{1: true}}
+		var logs bytes.Buffer
+		deaths := &recordingOutboxMetrics{}
+		relay := NewOutboxRelay(repo, pub, clock.NewFixed(now), WithOutboxMaxAttempts(3),
+			WithOutboxLogger(slog.New(slog.NewJSONHandler(&logs, nil))), WithOutboxMetrics(deaths))
+
+		if _, err := relay.RelayOnce(context.Background()); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if !equalSeqs(repo.dead, []int64{1}) || !equalStrings(deaths.dead, []string{"order.refund_requested"}) {
+			t.Fatalf("expected the refund dead-lettered and counted, got dead %v counted %v", repo.dead, deaths.dead)
+		}
+		if !strings.Contains(logs.String(), `"level":"ERROR"`) || !strings.Contains(logs.String(), "refund") {
+			t.Fatalf("expected an error log about the refund, got %s", logs.String())
+		}
+	})
+
+	t.Run("skips when another relay owns the outbox", func(t *testing.T) {
+		repo := &fakeOutboxRepo{owned: false, events: []domain.OutboxEvent{event(1, "o1")}}
+		pub := &stubOutboxPublisher{}
+		relay := NewOutboxRelay(repo, pub, clock.NewFixed(now))
+
+		n, err := relay.RelayOnce(context.Background())
+		if err != nil || n != 0 || len(pub.published) != 0 {
+			t.Fatalf("expected nothing published, got %d %v (%v)", n, pub.published, err)
+		}
+	})
+
+	t.Run("backoff doubles up to the maximum", func(t *testing.T) {
+		for attempt, want := range map[int]time.Duration{1: time.Second, 2: 2 * time.Second, 3: 4 * time.Second, 4: 5 * time.Second, 10: 5 * time.Second} {
+			if got := backoff(time.Second, 5*time.Second, attempt); got != want {
+				t.Fatalf("attempt %d: expected %v, got %v", attempt, want, got)
+			}
+		}
+	})
+}
+
+type failedOutboxEvent struct {
+	seq         int64
+	availableAt time.Time
+}
+
+type fakeOutboxRepo struct {
+	owned     bool
+	events    []domain.OutboxEvent
+	published []int64
+	failed    []failedOutboxEvent
+	dead      []int64
+	released  []int64
+}
+
+func (f *fakeOutboxRepo) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+// ClaimOutboxEvents mirrors the Postgres query: pending events that are due,
+// except those behind a not yet due event of their aggregate, leased until
+// leaseUntil.
+func (f *fakeOutboxRepo) ClaimOutboxEvents(_ context.Context, now, leaseUntil time.Time, limit int) ([]domain.OutboxEvent, bool, error) {
+	if !f.owned {
+		return nil, false, nil
+	}
+	var claimed []domain.OutboxEvent
+	waiting := make(map[string]bool)
+	for i, e := range f.events {
+		if slices.Contains(f.published, e.Seq) || slices.Contains(f.dead, e.Seq) {
+			continue
+		}
+		key := e.AggregateType + ":" + e.AggregateID
+		if e.AvailableAt.After(now) {
+			waiting[key] = true
+			continue
+		}
+		if !waiting[key] && len(claimed) < limit {
+			claimed = append(claimed, e)
+			f.events[i].AvailableAt = leaseUntil
+		}
+	}
+	return claimed, true, nil
+}
+
+func (f *fakeOutboxRepo) MarkOutboxPublished(_ context.Context, seq int64, _ time.Time) error {
+	f.published = append(f.published, seq)
+	return nil
+}
+
+func (f *fakeOutboxRepo) MarkOutboxFailed(_ context.Context, seq int64, _ string, availableAt time.Time) error {
+	f.failed = append(f.failed, failedOutboxEvent{seq: seq, availableAt: availableAt})
+	f.update(seq, func(e *domain.OutboxEvent) {
+		e.Attempts++
+		e.AvailableAt = availableAt
+	})
+	return nil
+}
+
+func (f *fakeOutboxRepo) MarkOutboxDead(_ context.Context, seq int64, _ string) error {
+	f.dead = append(f.dead, seq)
+	f.update(seq, func(e *domain.OutboxEvent) { e.Attempts++ })
+	return nil
+}
+
+func (f *fakeOutboxRepo) ReleaseOutboxEvent(_ context.Context, seq int64, availableAt time.Time) error {
+	f.released = append(f.released, seq)
+	f.update(seq, func(e *domain.OutboxEvent) { e.AvailableAt = availableAt })
+	return nil
+}
+
+func (f *fakeOutboxRepo) update(seq int64, fn func(e *domain.OutboxEvent)) {
+	for i := range f.events {
+		if f.events[i].Seq == seq {
+			fn(&f.events[i])
+		}
+	}
+}
+
+type stubOutboxPublisher struct {
+	fail      map[int64]bool
+	published []int64
+	// onPublish runs before each event is published.
+	onPublish func(ctx context.Context)
+}
+
+func (p *stubOutboxPublisher) Publish(ctx context.Context, event domain.OutboxEvent) error {
+	if p.onPublish != nil {
+		p.onPublish(ctx)
+	}
+	if p.fail[event.Seq] {
+		return errors.New("broker unavailable")
+	}
+	p.published = append(p.published, event.Seq)
+	return nil
+}
+
+type recordingOutboxMetrics struct {
+	dead []string
+}
+
+func (m *recordingOutboxMetrics) OutboxEventDead(eventType string) {
+	m.dead = append(m.dead, eventType)
+}
+
+// steppingClock advances by step every time it is read.
+type steppingClock struct {
+	now  time.Time
+	step time.Duration
+}
+
+func (c *steppingClock) Now() time.Time {
+	now := c.now
+	c.now = c.now.Add(c.step)
+	return now
+}
+
+func equalSeqs(a, b []int64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,35 @@
+package app
+
+import "context"
+
+// PaymentProvider authorizes and settles payments for orders.
+// Implementations must treat IdempotencyKey as a deduplication key: repeating a
+// call with the same key returns the original outcome without charging again.
+// Declines are reported as domain.ErrPaymentDeclined and timeouts or outages as
+// domain.ErrPaymentUnavailable. Providers that call out over HTTP pass the
+// trace on with tracing.Inject, so their requests join the order's trace.
+type PaymentProvider interface {
+	Authorize(ctx context.Context, req PaymentRequest) (PaymentAuthorization, error)
+	Capture(ctx context.Context, op PaymentOperation) error
+	Void(ctx context.Context, op PaymentOperation) error
+	Refund(ctx context.Context, op PaymentOperation) error
+}
+
+// PaymentRequest describes the order being paid. Pricing is not modelled yet,
+// so providers receive the order reference and ticket quantity.
+type PaymentRequest struct {
+	OrderID        string
+	HoldID         string
+	Quantity       int
+	IdempotencyKey string
+}
+
+type PaymentAuthorization struct {
+	Reference string
+}
+
+// PaymentOperation targets an existing authorization.
+type PaymentOperation struct {
+	Reference      string
+	IdempotencyKey string
+}
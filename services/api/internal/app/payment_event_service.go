@@ -0,0 +1,83 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"github.com/cimillas/ultimate-ticket/services/api/internal/clock"
+	"github.com/cimillas/ultimate-ticket/services/api/internal/domain"
+)
+
+type PaymentEventRepository interface {
+	WithTx(ctx context.Context, fn func(ctx context.Context) error) error
+	// RecordPaymentEvent stores the event id and reports false if it was already seen.
+	RecordPaymentEvent(ctx context.Context, event domain.PaymentEvent, receivedAt time.Time) (bool, error)
+	SetPaymentEventOutcome(ctx context.Context, eventID, orderID string, outcome domain.PaymentEventOutcome) error
+	FindOrderIDByPaymentReference(ctx context.Context, reference string) (string, error)
+}
+
+// PaymentEventService applies payment processor webhooks to orders.
+type PaymentEventService struct {
+	repo   PaymentEventRepository
+	orders *OrderService
+	clock  clock.Clock
+}
+
+func NewPaymentEventService(repo PaymentEventRepository, orders *OrderService, clk clock.Clock) *PaymentEventService {
+	return &PaymentEventService{
+		repo:   repo,
+		orders: orders,
+		clock:  clk,
+	}
+}
+
+type ApplyPaymentEventResult struct {
+	Outcome domain.PaymentEventOutcome
+	Order   domain.Order
+}
+
+// Apply records the event and drives the order state machine in the same
+// transaction, so a delivery is either fully applied or can be retried.
+func (s *PaymentEventService) Apply(ctx context.Context, event domain.PaymentEvent) (ApplyPaymentEventResult, error) {
+	if event.ID == "" || !event.Type.Valid() || (event.OrderID == "" && event.PaymentReference == "") {
+		return ApplyPaymentEventResult{}, domain.ErrPaymentEventInvalid
+	}
+
+	now := s.clock.Now()
+	var result ApplyPaymentEventResult
+
+	err := s.repo.WithTx(ctx, func(txCtx context.Context) error {
+		recorded, err := s.repo.RecordPaymentEvent(txCtx, event, now)
+		if err != nil {
+			return err
+		}
+		if !recorded {
+			result = ApplyPaymentEventResult{Outcome: domain.PaymentEventDuplicate}
+			return nil
+		}
+
+		orderID := event.OrderID
+		if orderID == "" {
+			orderID, err = s.repo.FindOrderIDByPaymentReference(txCtx, event.PaymentReference)
+			if err != nil {
+				return err
+			}
+		}
+
+		order, outcome, err := s.orders.ApplyPaymentEvent(txCtx, orderID, event)
+		if err != nil {
+			return err
+		}
+
+		if err := s.repo.SetPaymentEventOutcome(txCtx, event.ID, order.ID, outcome); err != nil {
+			return err
+		}
+		result = ApplyPaymentEventResult{Outcome: outcome, Order: order}
+		return nil
+	})
+	if err != nil {
+		return ApplyPaymentEventResult{}, err
+	}
+
+	return result, nil
+}
@@ -0,0 +1,247 @@
+package app
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cimillas/ultimate-ticket/services/api/internal/clock"
+	"github.com/cimillas/ultimate-ticket/services/api/internal/domain"
+)
+
+func TestPaymentEventService_Apply(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, 1, 7, 12, 0, 0, 0, time.UTC)
+
+	setup := func(t *testing.T, holdExpiresAt time.Time) (*PaymentEventService, *fakeOrderRepo, *stubPaymentProvider) {
+		t.Helper()
+		orders := newFakeOrderRepo(map[string]domain.Hold{
+			"hold-1": {ID: "hold-1", Quantity: 1, Status: domain.HoldStatusActive, ExpiresAt: holdExpiresAt},
+		})
+		orders.orders["hold-1"] = domain.Order{
+			ID:             "order-1",
+			HoldID:         "hold-1",
+			IdempotencyKey: "idem-1",
+			Status:         domain.OrderStatusPendingPayment,
+			CreatedAt:      now,
+		}
+		provider := &stubPaymentProvider{}
+		orderSvc := NewOrderService(orders, clock.NewFixed(now), WithPaymentProvider(provider))
+		svc := NewPaymentEventService(&fakePaymentEventRepo{orders: orders}, orderSvc, clock.NewFixed(now))
+		return svc, orders, provider
+	}
+
+	t.Run("authorized then captured marks order paid", func(t *testing.T) {
+		svc, orders, _ := setup(t, now.Add(10*time.Minute))
+		ctx := context.Background()
+
+		res, err := svc.Apply(ctx, domain.PaymentEvent{ID: "evt-1", Type: domain.PaymentEventAuthorized, OrderID: "order-1", PaymentReference: "ref-1"})
+		if err != nil {
+			t.Fatalf("apply authorized: %v", err)
+		}
+		if res.Outcome != domain.PaymentEventProcessed || res.Order.Status != domain.OrderStatusPendingPayment {
+			t.Fatalf("unexpected result: %+v", res)
+		}
+
+		res, err = svc.Apply(ctx, domain.PaymentEvent{ID: "evt-2", Type: domain.PaymentEventCaptured, PaymentReference: "ref-1"})
+		if err != nil {
+			t.Fatalf("apply captured: %v", err)
+		}
+		if res.Outcome != domain.PaymentEventProcessed || res.Order.Status != domain.OrderStatusPaid {
+			t.Fatalf("unexpected result: %+v", res)
+		}
+		if orders.holds["hold-1"].Status != domain.HoldStatusConfirmed {
+			t.Fatalf("expected hold confirmed, got %s", orders.holds["hold-1"].Status)
+		}
+	})
+
+	t.Run("duplicate delivery is not applied twice", func(t *testing.T) {
+		svc, _, _ := setup(t, now.Add(10*time.Minute))
+		ctx := context.Background()
+		event := domain.PaymentEvent{ID: "evt-1", Type: domain.PaymentEventFailed, OrderID: "order-1"}
+
+		if _, err := svc.Apply(ctx, event); err != nil {
+			t.Fatalf("apply: %v", err)
+		}
+		res, err := svc.Apply(ctx, event)
+		if err != nil {
+			t.Fatalf("apply duplicate: %v", err)
+		}
+		if res.Outcome != domain.PaymentEventDuplicate {
+			t.Fatalf("expected duplicate, got %s", res.Outcome)
+		}
+	})
+
+	t.Run("refund cancels paid order", func(t *testing.T) {
+		svc, orders, _ := setup(t, now.Add(10*time.Minute))
+		ctx := context.Background()
+
+		if _, err := svc.Apply(ctx, domain.PaymentEvent{ID: "evt-1", Type: domain.PaymentEventCaptured, OrderID: "order-1", PaymentReference: "ref-1"}); err != nil {
+			t.Fatalf("apply captured: %v", err)
+		}
+		res, err := svc.Apply(ctx, domain.PaymentEvent{ID: "evt-2", Type: domain.PaymentEventRefunded, OrderID: "order-1"})
+		if err != nil {
+			t.Fatalf("apply refunded: %v", err)
+		}
+		if res.Order.Status != domain.OrderStatusCancelled {
+			t.Fatalf("expected cancelled, got %s", res.Order.Status)
+		}
+		if orders.holds["hold-1"].Status != domain.HoldStatusReleased {
+			t.Fatalf("expected hold released, got %s", orders.holds["hold-1"].Status)
+		}
+		if len(orders.audits) != 1 || orders.audits[0].Action != domain.AuditOrderRefunded || orders.audits[0].TargetID != "order-1" {
+			t.Fatalf("expected the refund to be audited, got %+v", orders.audits)
+		}
+	})
+
+	t.Run("event not applicable to state is ignored", func(t *testing.T) {
+		svc, _, _ := setup(t, now.Add(10*time.Minute))
+		ctx := context.Background()
+
+		if _, err := svc.Apply(ctx, domain.PaymentEvent{ID: "evt-1", Type: domain.PaymentEventFailed, OrderID: "order-1"}); err != nil {
+			t.Fatalf("apply failed: %v", err)
+		}
+		res, err := svc.Apply(ctx, domain.PaymentEvent{ID: "evt-2", Type: domain.PaymentEventAuthorized, OrderID: "order-1", PaymentReference: "ref-1"})
+		if err != nil {
+			t.Fatalf("apply authorized: %v", err)
+		}
+		if res.Outcome != domain.PaymentEventIgnored || res.Order.Status != domain.OrderStatusFailed {
+			t.Fatalf("unexpected result: %+v", res)
+		}
+	})
+
+	t.Run("capture after failed payment is refunded", func(t *testing.T) {
+		svc, orders, _ := setup(t, now.Add(10*time.Minute))
+		ctx := context.Background()
+
+		if _, err := svc.Apply(ctx, domain.PaymentEvent{ID: "evt-1", Type: domain.PaymentEventFailed, OrderID: "order-1"}); err != nil {
+			t.Fatalf("apply failed: %v", err)
+		}
+		res, err := svc.Apply(ctx, domain.PaymentEvent{ID: "evt-2", Type: domain.PaymentEventCaptured, OrderID: "order-1", PaymentReference: "ref-1"})
+		if err != nil {
+			t.Fatalf("apply captured: %v", err)
+		}
+		if res.Outcome != domain.PaymentEventRejected || res.Order.Status != domain.OrderStatusFailed {
+			t.Fatalf("unexpected result: %+v", res)
+		}
+		refund := orders.outbox[len(orders.outbox)-1]
+		if refund.Type != domain.OutboxOrderRefundRequested || refund.AggregateID != "order-1" {
+			t.Fatalf("expected a refund request in the outbox, got %+v", refund)
+		}
+	})
+
+	t.Run("capture after refund is ignored", func(t *testing.T) {
+		svc, orders, _ := setup(t, now.Add(10*time.Minute))
+		ctx := context.Background()
+
+		if _, err := svc.Apply(ctx, domain.PaymentEvent{ID: "evt-1", Type: domain.PaymentEventCaptured, OrderID: "order-1", PaymentReference: "ref-1"}); err != nil {
+			t.Fatalf("apply captured: %v", err)
+		}
+		if _, err := svc.Apply(ctx, domain.PaymentEvent{ID: "evt-2", Type: domain.PaymentEventRefunded, OrderID: "order-1"}); err != nil {
+			t.Fatalf("apply refunded: %v", err)
+		}
+		res, err := svc.Apply(ctx, domain.PaymentEvent{ID: "evt-3", Type: domain.PaymentEventCaptured, OrderID: "order-1", PaymentReference: "ref-1"})
+		if err != nil {
+			t.Fatalf("apply captured again: %v", err)
+		}
+		if res.Outcome != domain.PaymentEventIgnored || res.Order.Status != domain.OrderStatusCancelled {
+			t.Fatalf("unexpected result: %+v", res)
+		}
+		for _, e := range orders.outbox {
+			if e.Type == domain.OutboxOrderRefundRequested {
+				t.Fatalf("expected no refund request for a refunded order, got %+v", e)
+			}
+		}
+	})
+
+	t.Run("capture after hold expiry fails order and refunds", func(t *testing.T) {
+		svc, orders, provider := setup(t, now.Add(-time.Minute))
+		ctx := context.Background()
+
+		res, err := svc.Apply(ctx, domain.PaymentEvent{ID: "evt-1", Type: domain.PaymentEventCaptured, OrderID: "order-1", PaymentReference: "ref-1"})
+		if err != nil {
+			t.Fatalf("apply captured: %v", err)
+		}
+		if res.Outcome != domain.PaymentEventRejected || res.Order.Status != domain.OrderStatusFailed {
+			t.Fatalf("unexpected result: %+v", res)
+		}
+		if len(provider.calls) != 0 {
+			t.Fatalf("expected the refund to wait for the outbox, got calls %v", provider.calls)
+		}
+		refund := orders.outbox[len(orders.outbox)-1]
+		if refund.Type != domain.OutboxOrderRefundRequested || refund.AggregateID != "order-1" {
+			t.Fatalf("expected a refund request in the outbox, got %+v", refund)
+		}
+		if len(orders.audits) != 1 || orders.audits[0].Action != domain.AuditOrderRefundRequested {
+			t.Fatalf("expected the refund request to be audited, got %+v", orders.audits)
+		}
+
+		provider.refundErr = domain.ErrPaymentUnavailable
+		if err := svc.orders.Publish(ctx, refund); err != domain.ErrPaymentUnavailable {
+			t.Fatalf("expected a failed refund to be retried, got %v", err)
+		}
+		provider.refundErr = nil
+		if err := svc.orders.Publish(ctx, refund); err != nil {
+			t.Fatalf("publish refund: %v", err)
+		}
+		want := []string{"refund:order-1:refund", "refund:order-1:refund"}
+		if !equalStrings(provider.calls, want) {
+			t.Fatalf("expected calls %v, got %v", want, provider.calls)
+		}
+	})
+
+	t.Run("invalid events are rejected", func(t *testing.T) {
+		svc, _, _ := setup(t, now.Add(10*time.Minute))
+		ctx := context.Background()
+
+		invalid := []domain.PaymentEvent{
+			{Type: domain.PaymentEventCaptured, OrderID: "order-1"},
+			{ID: "evt-1", Type: "payment.unknown", OrderID: "order-1"},
+			{ID: "evt-1", Type: domain.PaymentEventCaptured},
+		}
+		for _, event := range invalid {
+			if _, err := svc.Apply(ctx, event); err != domain.ErrPaymentEventInvalid {
+				t.Fatalf("expected ErrPaymentEventInvalid for %+v, got %v", event, err)
+			}
+		}
+
+		if _, err := svc.Apply(ctx, domain.PaymentEvent{ID: "evt-2", Type: domain.PaymentEventCaptured, PaymentReference: "unknown"}); err != domain.ErrOrderNotFound {
+			t.Fatalf("expected ErrOrderNotFound, got %v", err)
+		}
+	})
+}
+
+type fakePaymentEventRepo struct {
+	orders *fakeOrderRepo
+	seen   map[string]domain.PaymentEventOutcome
+}
+
+func (f *fakePaymentEventRepo) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+func (f *fakePaymentEventRepo) RecordPaymentEvent(_ context.Context, event domain.PaymentEvent, _ time.Time) (bool, error) {
+	if f.seen == nil {
+		f.seen = make(map[string]domain.PaymentEventOutcome)
+	}
+	if _, ok := f.seen[event.ID]; ok {
+		return false, nil
+	}
+	f.seen[event.ID] = "received"
+	return true, nil
+}
+
+func (f *fakePaymentEventRepo) SetPaymentEventOutcome(_ context.Context, eventID, _ string, outcome domain.PaymentEventOutcome) error {
+	f.seen[eventID] = outcome
+	return nil
+}
+
+func (f *fakePaymentEventRepo) FindOrderIDByPaymentReference(_ context.Context, reference string) (string, error) {
+	for _, order := range f.orders.orders {
+		if order.PaymentReference == reference {
+			return order.ID, nil
+		}
+	}
+	return "", domain.ErrOrderNotFound
+}
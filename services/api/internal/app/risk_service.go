@@ -0,0 +1,183 @@
+package app
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/cimillas/ultimate-ticket/services/api/internal/clock"
+	"github.com/cimillas/ultimate-ticket/services/api/internal/domain"
+)
+
+const (
+	// riskVelocityWindow is how far back velocity limits count attempts.
+	riskVelocityWindow = time.Minute
+	// maxRecordedUserAgent caps how much of a client-supplied user agent is
+	// kept per decision.
+	maxRecordedUserAgent = 512
+
+	defaultRiskDecisionLimit = 100
+	maxRiskDecisionLimit     = 500
+)
+
+type RiskRepository interface {
+	WithTx(ctx context.Context, fn func(ctx context.Context) error) error
+	// GetRiskRules returns the event's rules (domain.DefaultRiskRules when
+	// none were saved) and its organizer. Unknown events return
+	// ErrEventNotFound.
+	GetRiskRules(ctx context.Context, eventID string) (domain.RiskRules, string, error)
+	// GetRiskRulesForUpdate is GetRiskRules with the event row locked.
+	GetRiskRulesForUpdate(ctx context.Context, eventID string) (domain.RiskRules, string, error)
+	SaveRiskRules(ctx context.Context, eventID string, rules domain.RiskRules, now time.Time) error
+	// CountRecentAttempts counts the event's assessments since the given time
+	// from the signals' IP, customer and zone.
+	CountRecentAttempts(ctx context.Context, s domain.RiskSignals, since time.Time) (domain.RiskVelocity, error)
+	RecordRiskAssessment(ctx context.Context, a domain.RiskAssessment) error
+	// ListRiskAssessments returns matching assessments, newest first.
+	ListRiskAssessments(ctx context.Context, filter domain.RiskDecisionFilter) ([]domain.RiskAssessment, error)
+	AppendAuditEntry(ctx context.Context, entry domain.AuditEntry) error
+}
+
+// RiskService scores hold attempts against each event's risk rules and keeps
+// every decision for review.
+type RiskService struct {
+	repo         RiskRepository
+	clock        clock.Clock
+	noChallenges bool
+}
+
+func NewRiskService(repo RiskRepository, clk clock.Clock, opts ...RiskServiceOption) *RiskService {
+	svc := &RiskService{repo: repo, clock: clk}
+	for _, opt := range opts {
+		opt(svc)
+	}
+	return svc
+}
+
+type RiskServiceOption func(*RiskService)
+
+// WithoutChallenges allows attempts the rules would challenge, for
+// deployments that serve no proof-of-work challenges: such a challenge could
+// never be met and would refuse the attempt like a block. The decision is
+// recorded as allowed with the challenge_unavailable reason.
+func WithoutChallenges() RiskServiceOption {
+	return func(s *RiskService) {
+		s.noChallenges = true
+	}
+}
+
+// GetRiskRules returns an organizer's event rules.
+func (s *RiskService) GetRiskRules(ctx context.Context, organizerID, eventID string) (domain.RiskRules, error) {
+	rules, owner, err := s.repo.GetRiskRules(ctx, eventID)
+	if err != nil {
+		return domain.RiskRules{}, err
+	}
+	if owner != organizerID {
+		return domain.RiskRules{}, domain.ErrEventNotFound
+	}
+	return rules, nil
+}
+
+// UpdateRiskRules replaces an event's rules as a whole.
+func (s *RiskService) UpdateRiskRules(ctx context.Context, organizerID, eventID string, rules domain.RiskRules) (domain.RiskRules, error) {
+	if !rules.Valid() {
+		return domain.RiskRules{}, domain.ErrInvalidRiskRules
+	}
+	agents := make([]string, 0, len(rules.BlockedUserAgents))
+	for _, agent := range rules.BlockedUserAgents {
+		if agent = strings.TrimSpace(agent); agent != "" {
+			agents = append(agents, agent)
+		}
+	}
+	rules.BlockedUserAgents = agents
+
+	now := s.clock.Now()
+	err := s.repo.WithTx(ctx, func(txCtx context.Context) error {
+		current, owner, err := s.repo.GetRiskRulesForUpdate(txCtx, eventID)
+		if err != nil {
+			return err
+		}
+		if owner != organizerID {
+			return domain.ErrEventNotFound
+		}
+		if err := s.repo.SaveRiskRules(txCtx, eventID, rules, now); err != nil {
+			return err
+		}
+		entry, err := newAuditEntry(txCtx, organizerID, domain.AuditRiskRulesUpdated, domain.AuditTargetEvent, eventID,
+			newRiskRulesSnapshot(eventID, current), newRiskRulesSnapshot(eventID, rules), now)
+		if err != nil {
+			return err
+		}
+		return s.repo.AppendAuditEntry(txCtx, entry)
+	})
+	if err != nil {
+		return domain.RiskRules{}, err
+	}
+	return rules, nil
+}
+
+// Assess scores a hold attempt and records the decision. Events without
+// enabled rules, and events that do not exist, are allowed without a record;
+// the hold itself reports unknown events.
+func (s *RiskService) Assess(ctx context.Context, signals domain.RiskSignals) (domain.RiskAssessment, error) {
+	allow := domain.RiskAssessment{Decision: domain.RiskAllow}
+	rules, _, err := s.repo.GetRiskRules(ctx, signals.EventID)
+	if err == domain.ErrEventNotFound || err == domain.ErrInvalidID {
+		return allow, nil
+	}
+	if err != nil {
+		return domain.RiskAssessment{}, err
+	}
+	if !rules.Enabled {
+		return allow, nil
+	}
+
+	now := s.clock.Now()
+	velocity, err := s.repo.CountRecentAttempts(ctx, signals, now.Add(-riskVelocityWindow))
+	if err != nil {
+		return domain.RiskAssessment{}, err
+	}
+	score, reasons := rules.Score(signals, velocity)
+	decision := rules.Decide(score)
+	if decision == domain.RiskChallenge && s.noChallenges {
+		decision = domain.RiskAllow
+		reasons = append(reasons, domain.RiskReasonChallengeUnavailable)
+	}
+	assessment := domain.RiskAssessment{
+		ID:             newUUID(),
+		EventID:        signals.EventID,
+		ZoneID:         signals.ZoneID,
+		CustomerID:     signals.CustomerID,
+		IP:             signals.IP,
+		UserAgent:      truncateUTF8(signals.UserAgent, maxRecordedUserAgent),
+		IdempotencyKey: signals.IdempotencyKey,
+		ProofOfWork:    signals.ProofOfWork,
+		Score:          score,
+		Reasons:        reasons,
+		Decision:       decision,
+		CreatedAt:      now,
+	}
+	if err := s.repo.RecordRiskAssessment(ctx, assessment); err != nil {
+		return domain.RiskAssessment{}, err
+	}
+	return assessment, nil
+}
+
+func truncateUTF8(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return strings.ToValidUTF8(s[:n], "")
+}
+
+// ListDecisions returns an organizer's risk decisions, newest first. A zero
+// limit means the default; larger limits are capped.
+func (s *RiskService) ListDecisions(ctx context.Context, filter domain.RiskDecisionFilter) ([]domain.RiskAssessment, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = defaultRiskDecisionLimit
+	}
+	if filter.Limit > maxRiskDecisionLimit {
+		filter.Limit = maxRiskDecisionLimit
+	}
+	return s.repo.ListRiskAssessments(ctx, filter)
+}
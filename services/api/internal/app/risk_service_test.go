@@ -0,0 +1,405 @@
+package app
+
+import (
+	"context"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cimillas/ultimate-ticket/services/api/internal/clock"
+	"github.com/cimillas/ultimate-ticket/services/api/internal/domain"
+)
+
+const strongKey = "3f0c1d5e-8a4b-4c2e-9f61-0d7a2b8e5c13"
+
+func TestRiskRules_Score(t *testing.T) {
+	t.Parallel()
+
+	rules := domain.RiskRules{
+		Enabled: true, ChallengeScore: 50, BlockScore: 100,
+		MaxHoldsPerIP: 5, MaxHoldsPerCustomer: 3, MaxHoldsPerZone: 100,
+		BlockedUserAgents: []string{"curl"},
+	}
+	browser := domain.RiskSignals{CustomerID: "cust-1", IP: "203.0.113.7", UserAgent: "Mozilla/5.0", IdempotencyKey: strongKey}
+
+	tests := []struct {
+		name         string
+		signals      func(s domain.RiskSignals) domain.RiskSignals
+		velocity     domain.RiskVelocity
+		wantReasons  []string
+		wantDecision domain.RiskDecision
+	}{
+		{name: "signed-in browser", wantReasons: []string{}, wantDecision: domain.RiskAllow},
+		{
+			name:         "anonymous browser",
+			signals:      func(s domain.RiskSignals) domain.RiskSignals { s.CustomerID = ""; return s },
+			wantReasons:  []string{domain.RiskReasonAnonymous},
+			wantDecision: domain.RiskAllow,
+		},
+		{
+			name: "anonymous script without user agent",
+			signals: func(s domain.RiskSignals) domain.RiskSignals {
+				s.CustomerID, s.UserAgent, s.IdempotencyKey = "", "", "1"
+				return s
+			},
+			wantReasons:  []string{domain.RiskReasonMissingUserAgent, domain.RiskReasonAnonymous, domain.RiskReasonWeakIdempotencyKey},
+			wantDecision: domain.RiskChallenge,
+		},
+		{
+			name:         "blocked user agent",
+			signals:      func(s domain.RiskSignals) domain.RiskSignals { s.UserAgent = "Curl/8.4"; return s },
+			wantReasons:  []string{domain.RiskReasonBlockedUserAgent},
+			wantDecision: domain.RiskBlock,
+		},
+		{
+			name:         "counter idempotency keys",
+			signals:      func(s domain.RiskSignals) domain.RiskSignals { s.IdempotencyKey = "12345678901234567890"; return s },
+			wantReasons:  []string{domain.RiskReasonWeakIdempotencyKey},
+			wantDecision: domain.RiskAllow,
+		},
+		{
+			name:         "busy IP",
+			velocity:     domain.RiskVelocity{IP: 5},
+			wantReasons:  []string{domain.RiskReasonIPVelocity},
+			wantDecision: domain.RiskChallenge,
+		},
+		{
+			name:         "busy customer on a busy zone",
+			velocity:     domain.RiskVelocity{IP: 4, Customer: 3, Zone: 100},
+			wantReasons:  []string{domain.RiskReasonCustomerVelocity, domain.RiskReasonZoneVelocity},
+			wantDecision: domain.RiskChallenge,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			signals := browser
+			if tt.signals != nil {
+				signals = tt.signals(signals)
+			}
+			score, reasons := rules.Score(signals, tt.velocity)
+			if !reflect.DeepEqual(reasons, tt.wantReasons) {
+				t.Fatalf("expected reasons %v, got %v", tt.wantReasons, reasons)
+			}
+			if got := rules.Decide(score); got != tt.wantDecision {
+				t.Fatalf("expected %s for score %d, got %s", tt.wantDecision, score, got)
+			}
+		})
+	}
+}
+
+func TestRiskService_UpdateRiskRules(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		organizerID string
+		eventID     string
+		rules       domain.RiskRules
+		wantErr     error
+	}{
+		{
+			name:        "saves",
+			organizerID: "org-1",
+			eventID:     "event-1",
+			rules:       domain.RiskRules{Enabled: true, ChallengeScore: 40, BlockScore: 80, MaxHoldsPerIP: 10, BlockedUserAgents: []string{" curl ", ""}},
+		},
+		{name: "block below challenge", organizerID: "org-1", eventID: "event-1", rules: domain.RiskRules{ChallengeScore: 50, BlockScore: 40}, wantErr: domain.ErrInvalidRiskRules},
+		{name: "no challenge score", organizerID: "org-1", eventID: "event-1", rules: domain.RiskRules{BlockScore: 40}, wantErr: domain.ErrInvalidRiskRules},
+		{name: "negative limit", organizerID: "org-1", eventID: "event-1", rules: domain.RiskRules{ChallengeScore: 50, BlockScore: 100, MaxHoldsPerZone: -1}, wantErr: domain.ErrInvalidRiskRules},
+		{name: "event of another organizer", organizerID: "org-2", eventID: "event-1", rules: domain.DefaultRiskRules(), wantErr: domain.ErrEventNotFound},
+		{name: "unknown event", organizerID: "org-1", eventID: "missing", rules: domain.DefaultRiskRules(), wantErr: domain.ErrEventNotFound},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			repo := newFakeRiskRepo()
+			svc := NewRiskService(repo, clock.NewFixed(now))
+
+			rules, err := svc.UpdateRiskRules(context.Background(), tt.organizerID, tt.eventID, tt.rules)
+			if err != tt.wantErr {
+				t.Fatalf("expected %v, got %v", tt.wantErr, err)
+			}
+			if err != nil {
+				if len(repo.audit) != 0 {
+					t.Fatalf("expected no audit entry, got %d", len(repo.audit))
+				}
+				return
+			}
+			if !reflect.DeepEqual(rules.BlockedUserAgents, []string{"curl"}) {
+				t.Fatalf("expected trimmed user agents, got %q", rules.BlockedUserAgents)
+			}
+			got, err := svc.GetRiskRules(context.Background(), tt.organizerID, tt.eventID)
+			if err != nil || !reflect.DeepEqual(got, rules) {
+				t.Fatalf("expected saved rules %+v, got %+v (%v)", rules, got, err)
+			}
+			if len(repo.audit) != 1 || repo.audit[0].Action != domain.AuditRiskRulesUpdated {
+				t.Fatalf("expected one %s audit entry, got %+v", domain.AuditRiskRulesUpdated, repo.audit)
+			}
+		})
+	}
+}
+
+func TestRiskService_Assess(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	enabled := domain.RiskRules{Enabled: true, ChallengeScore: 50, BlockScore: 100, MaxHoldsPerIP: 2}
+	signals := domain.RiskSignals{EventID: "event-1", ZoneID: "zone-1", IP: "203.0.113.7", UserAgent: "Mozilla/5.0", IdempotencyKey: strongKey}
+
+	t.Run("disabled rules record nothing", func(t *testing.T) {
+		t.Parallel()
+		repo := newFakeRiskRepo()
+		svc := NewRiskService(repo, clock.NewFixed(now))
+		for _, eventID := range []string{"event-1", "missing"} {
+			s := signals
+			s.EventID = eventID
+			a, err := svc.Assess(context.Background(), s)
+			if err != nil || a.Decision != domain.RiskAllow {
+				t.Fatalf("%s: expected allow, got %+v (%v)", eventID, a, err)
+			}
+		}
+		if len(repo.decisions) != 0 {
+			t.Fatalf("expected no recorded decisions, got %d", len(repo.decisions))
+		}
+	})
+
+	t.Run("velocity escalates and every decision is kept", func(t *testing.T) {
+		t.Parallel()
+		repo := newFakeRiskRepo()
+		repo.rules["event-1"] = enabled
+		svc := NewRiskService(repo, clock.NewFixed(now))
+
+		want := []domain.RiskDecision{domain.RiskAllow, domain.RiskAllow, domain.RiskChallenge}
+		for i, decision := range want {
+			a, err := svc.Assess(context.Background(), signals)
+			if err != nil {
+				t.Fatalf("attempt %d: %v", i+1, err)
+			}
+			if a.Decision != decision {
+				t.Fatalf("attempt %d: expected %s, got %s (%v)", i+1, decision, a.Decision, a.Reasons)
+			}
+		}
+		if len(repo.decisions) != len(want) {
+			t.Fatalf("expected %d recorded decisions, got %d", len(want), len(repo.decisions))
+		}
+
+		// Attempts from another address are counted apart.
+		other := signals
+		other.IP = "203.0.113.8"
+		if a, err := svc.Assess(context.Background(), other); err != nil || a.Decision != domain.RiskAllow {
+			t.Fatalf("expected another IP to be allowed, got %+v (%v)", a, err)
+		}
+	})
+
+	t.Run("long user agents are truncated", func(t *testing.T) {
+		t.Parallel()
+		repo := newFakeRiskRepo()
+		repo.rules["event-1"] = enabled
+		svc := NewRiskService(repo, clock.NewFixed(now))
+		s := signals
+		s.UserAgent = strings.Repeat("é", maxRecordedUserAgent)
+		a, err := svc.Assess(context.Background(), s)
+		if err != nil {
+			t.Fatalf("assess: %v", err)
+		}
+		if len(a.UserAgent) > maxRecordedUserAgent || !strings.HasPrefix(s.UserAgent, a.UserAgent) {
+			t.Fatalf("expected a valid prefix of at most %d bytes, got %d", maxRecordedUserAgent, len(a.UserAgent))
+		}
+	})
+}
+
+func TestRiskService_ListDecisions(t *testing.T) {
+	t.Parallel()
+
+	repo := newFakeRiskRepo()
+	svc := NewRiskService(repo, clock.NewFixed(time.Now()))
+
+	if _, err := svc.ListDecisions(context.Background(), domain.RiskDecisionFilter{OrganizerID: "org-1"}); err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if repo.lastFilter.Limit != defaultRiskDecisionLimit {
+		t.Fatalf("expected default limit %d, got %d", defaultRiskDecisionLimit, repo.lastFilter.Limit)
+	}
+	if _, err := svc.ListDecisions(context.Background(), domain.RiskDecisionFilter{OrganizerID: "org-1", Limit: 10000}); err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if repo.lastFilter.Limit != maxRiskDecisionLimit {
+		t.Fatalf("expected capped limit %d, got %d", maxRiskDecisionLimit, repo.lastFilter.Limit)
+	}
+}
+
+func TestHoldService_RiskScoring(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name         string
+		userAgent    string
+		proofOfWork  bool
+		noChallenges bool
+		wantErr      error
+		wantReason   string
+	}{
+		{name: "allowed", userAgent: "Mozilla/5.0"},
+		{name: "challenged", wantErr: domain.ErrChallengeRequired},
+		{name: "challenge met", proofOfWork: true},
+		{name: "blocked", userAgent: "python-requests/2.31", proofOfWork: true, wantErr: domain.ErrHoldBlocked},
+		{name: "challenge allowed without proof of work", noChallenges: true, wantReason: domain.RiskReasonChallengeUnavailable},
+		{name: "still blocked without proof of work", userAgent: "python-requests/2.31", noChallenges: true, wantErr: domain.ErrHoldBlocked},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			risk := newFakeRiskRepo()
+			risk.rules["event-1"] = domain.RiskRules{
+				Enabled: true, ChallengeScore: 40, BlockScore: 100,
+				BlockedUserAgents: []string{"python-requests"},
+			}
+			var opts []RiskServiceOption
+			if tt.noChallenges {
+				opts = append(opts, WithoutChallenges())
+			}
+			repo := newFakeHoldRepo([]domain.Zone{{ID: "zone-1", EventID: "event-1", Capacity: 10}}, nil)
+			svc := NewHoldService(repo, clock.NewFixed(now), WithRiskScoring(NewRiskService(risk, clock.NewFixed(now), opts...)))
+
+			_, err := svc.CreateHold(context.Background(), CreateHoldInput{
+				EventID:        "event-1",
+				ZoneID:         "zone-1",
+				Quantity:       1,
+				IdempotencyKey: strongKey,
+				CustomerID:     "cust-1",
+				ClientIP:       "203.0.113.7",
+				UserAgent:      tt.userAgent,
+				ProofOfWork:    tt.proofOfWork,
+			})
+			if err != tt.wantErr {
+				t.Fatalf("expected %v, got %v", tt.wantErr, err)
+			}
+			if len(risk.decisions) != 1 {
+				t.Fatalf("expected the decision to be recorded, got %d", len(risk.decisions))
+			}
+			if tt.wantReason != "" && !slices.Contains(risk.decisions[0].Reasons, tt.wantReason) {
+				t.Fatalf("expected reason %s, got %v", tt.wantReason, risk.decisions[0].Reasons)
+			}
+			if created := len(repo.holds) == 1; created != (err == nil) {
+				t.Fatalf("expected a hold only when allowed, got %d", len(repo.holds))
+			}
+		})
+	}
+
+	t.Run("replays skip scoring", func(t *testing.T) {
+		t.Parallel()
+		risk := newFakeRiskRepo()
+		repo := newFakeHoldRepo([]domain.Zone{{ID: "zone-1", EventID: "event-1", Capacity: 10}}, nil)
+		svc := NewHoldService(repo, clock.NewFixed(now), WithRiskScoring(NewRiskService(risk, clock.NewFixed(now))))
+		in := CreateHoldInput{
+			EventID:        "event-1",
+			ZoneID:         "zone-1",
+			Quantity:       1,
+			IdempotencyKey: strongKey,
+			CustomerID:     "cust-1",
+			UserAgent:      "Mozilla/5.0",
+		}
+		first, err := svc.CreateHold(context.Background(), in)
+		if err != nil {
+			t.Fatalf("create: %v", err)
+		}
+
+		// Rules tightened after the hold was made must not refuse its retry.
+		risk.rules["event-1"] = domain.RiskRules{Enabled: true, ChallengeScore: 0, BlockScore: 0}
+		again, err := svc.CreateHold(context.Background(), in)
+		if err != nil || again.ID != first.ID {
+			t.Fatalf("expected the existing hold, got %+v (%v)", again, err)
+		}
+		if len(risk.decisions) != 0 {
+			t.Fatalf("expected no decision recorded for the replay, got %d", len(risk.decisions))
+		}
+	})
+}
+
+type fakeRiskRepo struct {
+	owners     map[string]string
+	rules      map[string]domain.RiskRules
+	decisions  []domain.RiskAssessment
+	audit      []domain.AuditEntry
+	lastFilter domain.RiskDecisionFilter
+}
+
+// newFakeRiskRepo starts with event-1, owned by org-1.
+func newFakeRiskRepo() *fakeRiskRepo {
+	return &fakeRiskRepo{
+		owners: map[string]string{"event-1": "org-1"},
+		rules:  map[string]domain.RiskRules{},
+	}
+}
+
+func (f *fakeRiskRepo) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+func (f *fakeRiskRepo) GetRiskRules(_ context.Context, eventID string) (domain.RiskRules, string, error) {
+	owner, ok := f.owners[eventID]
+	if !ok {
+		return domain.RiskRules{}, "", domain.ErrEventNotFound
+	}
+	rules, ok := f.rules[eventID]
+	if !ok {
+		rules = domain.DefaultRiskRules()
+	}
+	return rules, owner, nil
+}
+
+func (f *fakeRiskRepo) GetRiskRulesForUpdate(ctx context.Context, eventID string) (domain.RiskRules, string, error) {
+	return f.GetRiskRules(ctx, eventID)
+}
+
+func (f *fakeRiskRepo) SaveRiskRules(_ context.Context, eventID string, rules domain.RiskRules, _ time.Time) error {
+	f.rules[eventID] = rules
+	return nil
+}
+
+func (f *fakeRiskRepo) CountRecentAttempts(_ context.Context, s domain.RiskSignals, since time.Time) (domain.RiskVelocity, error) {
+	var v domain.RiskVelocity
+	for _, d := range f.decisions {
+		if d.EventID != s.EventID || !d.CreatedAt.After(since) {
+			continue
+		}
+		if s.IP != "" && d.IP == s.IP {
+			v.IP++
+		}
+		if s.CustomerID != "" && d.CustomerID == s.CustomerID {
+			v.Customer++
+		}
+		if d.ZoneID == s.ZoneID {
+			v.Zone++
+		}
+	}
+	return v, nil
+}
+
+func (f *fakeRiskRepo) RecordRiskAssessment(_ context.Context, a domain.RiskAssessment) error {
+	f.decisions = append(f.decisions, a)
+	return nil
+}
+
+func (f *fakeRiskRepo) ListRiskAssessments(_ context.Context, filter domain.RiskDecisionFilter) ([]domain.RiskAssessment, error) {
+	f.lastFilter = filter
+	return f.decisions, nil
+}
+
+func (f *fakeRiskRepo) AppendAuditEntry(_ context.Context, entry domain.AuditEntry) error {
+	f.audit = append(f.audit, entry)
+	return nil
+}
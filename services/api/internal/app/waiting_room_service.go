@@ -0,0 +1,327 @@
+package app
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/cimillas/ultimate-ticket/services/api/internal/clock"
+	"github.com/cimillas/ultimate-ticket/services/api/internal/domain"
+)
+
+const (
+	defaultAdmissionTTL = 10 * time.Minute
+	// Admission rates are cached briefly so that many buyers polling the same
+	// event share one read of the queue.
+	admissionRateTTL = 5 * time.Second
+	minPollInterval  = 2 * time.Second
+	maxPollInterval  = 30 * time.Second
+)
+
+// WaitingRoomRepository stores waiting-room queues. Positions are computed
+// from join order among entries still waiting.
+type WaitingRoomRepository interface {
+	WithTx(ctx context.Context, fn func(ctx context.Context) error) error
+	// GetWaitingRoom returns ErrEventNotFound for unknown events.
+	GetWaitingRoom(ctx context.Context, eventID string) (domain.WaitingRoom, error)
+	// GetWaitingRoomForUpdate locks the event so admissions are counted
+	// once across instances.
+	GetWaitingRoomForUpdate(ctx context.Context, eventID string) (domain.WaitingRoom, error)
+	// ListQueuedEvents returns events with the waiting room enabled and
+	// someone waiting.
+	ListQueuedEvents(ctx context.Context) ([]string, error)
+	// CreateQueueEntry returns ErrAlreadyQueued if the customer already has an
+	// entry waiting for the event.
+	CreateQueueEntry(ctx context.Context, entry domain.QueueEntry) error
+	GetQueueEntry(ctx context.Context, id string) (domain.QueueEntry, error)
+	// FindCustomerQueueEntry returns the customer's latest entry for the
+	// event, or nil if there is none.
+	FindCustomerQueueEntry(ctx context.Context, eventID, customerID string) (*domain.QueueEntry, error)
+	CountAdmittedSince(ctx context.Context, eventID string, since time.Time) (int, error)
+	// AdmitNext admits up to limit of the longest-waiting entries and
+	// returns how many were admitted.
+	AdmitNext(ctx context.Context, eventID string, limit int, now time.Time) (int, error)
+}
+
+// AdmissionTokens issues and verifies signed admission tokens. Tokens name
+// the queue entry and the customer (empty for anonymous buyers) they admit.
+type AdmissionTokens interface {
+	Issue(eventID, entryID, customerID string, expiresAt time.Time) string
+	Verify(token, eventID string) (entryID, customerID string, err error)
+}
+
+type WaitingRoomService struct {
+	repo         WaitingRoomRepository
+	tokens       AdmissionTokens
+	clock        clock.Clock
+	admissionTTL time.Duration
+
+	mu    sync.Mutex
+	rates map[string]admissionRate
+}
+
+// admissionRate is an event's recent admissions per minute, as cached at
+// fetchedAt. perMinute is 0 when the event admits nobody.
+type admissionRate struct {
+	perMinute float64
+	fetchedAt time.Time
+}
+
+type WaitingRoomServiceOption func(*WaitingRoomService)
+
+// WithAdmissionTTL sets how long an admitted buyer's token stays valid.
+func WithAdmissionTTL(ttl time.Duration) WaitingRoomServiceOption {
+	return func(s *WaitingRoomService) {
+		if ttl > 0 {
+			s.admissionTTL = ttl
+		}
+	}
+}
+
+func NewWaitingRoomService(repo WaitingRoomRepository, tokens AdmissionTokens, clk clock.Clock, opts ...WaitingRoomServiceOption) *WaitingRoomService {
+	s := &WaitingRoomService{
+		repo:         repo,
+		tokens:       tokens,
+		clock:        clk,
+		admissionTTL: defaultAdmissionTTL,
+		rates:        make(map[string]admissionRate),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// QueueStatus is a queue entry as seen by the buyer. AdmissionToken and
+// ExpiresAt are set while the entry is admitted. While it waits, Ahead counts
+// the buyers in front, EstimatedAdmissionAt is zero when no estimate can be
+// made, and RetryAfter suggests when to poll again. Created is set by Join
+// when it added a new entry rather than returning the customer's current one.
+type QueueStatus struct {
+	Entry                domain.QueueEntry
+	Created              bool
+	AdmissionToken       string
+	ExpiresAt            time.Time
+	Ahead                int
+	EstimatedAdmissionAt time.Time
+	RetryAfter           time.Duration
+}
+
+// Join adds a buyer to the event's queue. customerID is empty for anonymous
+// buyers. A customer who is still waiting or holds an admission that has not
+// expired gets that entry back instead of a second place in the queue.
+func (s *WaitingRoomService) Join(ctx context.Context, eventID, customerID string) (QueueStatus, error) {
+	if eventID == "" {
+		return QueueStatus{}, domain.ErrInvalidID
+	}
+	room, err := s.repo.GetWaitingRoom(ctx, eventID)
+	if err != nil {
+		return QueueStatus{}, err
+	}
+	if !room.Enabled {
+		return QueueStatus{}, domain.ErrWaitingRoomDisabled
+	}
+
+	if customerID != "" {
+		if status, ok, err := s.currentEntry(ctx, eventID, customerID); err != nil || ok {
+			return status, err
+		}
+	}
+
+	entry := domain.QueueEntry{
+		ID:         newUUID(),
+		EventID:    eventID,
+		CustomerID: customerID,
+		Status:     domain.QueueEntryWaiting,
+		JoinedAt:   s.clock.Now(),
+	}
+	var result QueueStatus
+	err = s.repo.WithTx(ctx, func(txCtx context.Context) error {
+		if err := s.repo.CreateQueueEntry(txCtx, entry); err != nil {
+			return err
+		}
+		created, err := s.repo.GetQueueEntry(txCtx, entry.ID)
+		if err != nil {
+			return err
+		}
+		result = QueueStatus{Entry: created, Created: true}
+		return nil
+	})
+	if err == domain.ErrAlreadyQueued {
+		// A concurrent join of the same customer won the race.
+		if status, ok, err := s.currentEntry(ctx, eventID, customerID); err != nil || ok {
+			return status, err
+		}
+	}
+	if err != nil {
+		return QueueStatus{}, err
+	}
+	if err := s.estimate(ctx, &result); err != nil {
+		return QueueStatus{}, err
+	}
+	return result, nil
+}
+
+// currentEntry reports the customer's entry for the event unless there is
+// none or its admission has expired.
+func (s *WaitingRoomService) currentEntry(ctx context.Context, eventID, customerID string) (QueueStatus, bool, error) {
+	entry, err := s.repo.FindCustomerQueueEntry(ctx, eventID, customerID)
+	if err != nil || entry == nil {
+		return QueueStatus{}, false, err
+	}
+	status, err := s.status(ctx, *entry)
+	if err != nil {
+		return QueueStatus{}, false, err
+	}
+	if status.Entry.Status == domain.QueueEntryExpired {
+		return QueueStatus{}, false, nil
+	}
+	return status, true, nil
+}
+
+// Status reports the entry's position, or its admission token once admitted.
+func (s *WaitingRoomService) Status(ctx context.Context, entryID string) (QueueStatus, error) {
+	if entryID == "" {
+		return QueueStatus{}, domain.ErrInvalidID
+	}
+	entry, err := s.repo.GetQueueEntry(ctx, entryID)
+	if err != nil {
+		return QueueStatus{}, err
+	}
+	return s.status(ctx, entry)
+}
+
+func (s *WaitingRoomService) status(ctx context.Context, entry domain.QueueEntry) (QueueStatus, error) {
+	if entry.Status == domain.QueueEntryWaiting {
+		status := QueueStatus{Entry: entry}
+		if err := s.estimate(ctx, &status); err != nil {
+			return QueueStatus{}, err
+		}
+		return status, nil
+	}
+	if entry.Status != domain.QueueEntryAdmitted || entry.AdmittedAt == nil {
+		return QueueStatus{Entry: entry}, nil
+	}
+
+	expiresAt := entry.AdmittedAt.Add(s.admissionTTL)
+	if !s.clock.Now().Before(expiresAt) {
+		entry.Status = domain.QueueEntryExpired
+		return QueueStatus{Entry: entry}, nil
+	}
+	return QueueStatus{
+		Entry:          entry,
+		AdmissionToken: s.tokens.Issue(entry.EventID, entry.ID, entry.CustomerID, expiresAt),
+		ExpiresAt:      expiresAt,
+	}, nil
+}
+
+// estimate fills in the wait for a waiting entry from the event's observed
+// admission rate. Polls are spread out for buyers far back in the queue.
+func (s *WaitingRoomService) estimate(ctx context.Context, status *QueueStatus) error {
+	if status.Entry.Position < 1 {
+		return nil
+	}
+	status.Ahead = status.Entry.Position - 1
+	status.RetryAfter = maxPollInterval
+
+	perMinute, err := s.admissionRate(ctx, status.Entry.EventID)
+	if err != nil {
+		return err
+	}
+	if perMinute <= 0 {
+		return nil
+	}
+	wait := time.Duration(float64(status.Entry.Position) / perMinute * float64(time.Minute))
+	status.EstimatedAdmissionAt = s.clock.Now().Add(wait).Truncate(time.Second)
+	status.RetryAfter = min(max(wait/4, minPollInterval), maxPollInterval).Round(time.Second)
+	return nil
+}
+
+// admissionRate returns how many buyers per minute the event admits: the
+// admissions over the last minute, or the configured rate when nobody was
+// admitted in that time (for example right after the queue opens).
+func (s *WaitingRoomService) admissionRate(ctx context.Context, eventID string) (float64, error) {
+	now := s.clock.Now()
+	s.mu.Lock()
+	cached, ok := s.rates[eventID]
+	s.mu.Unlock()
+	if ok && now.Sub(cached.fetchedAt) < admissionRateTTL {
+		return cached.perMinute, nil
+	}
+
+	room, err := s.repo.GetWaitingRoom(ctx, eventID)
+	if err != nil {
+		return 0, err
+	}
+	var perMinute float64
+	if room.Enabled && room.AdmitPerMinute > 0 {
+		recent, err := s.repo.CountAdmittedSince(ctx, eventID, now.Add(-time.Minute))
+		if err != nil {
+			return 0, err
+		}
+		perMinute = float64(room.AdmitPerMinute)
+		if recent > 0 {
+			perMinute = min(float64(recent), perMinute)
+		}
+	}
+
+	s.mu.Lock()
+	s.rates[eventID] = admissionRate{perMinute: perMinute, fetchedAt: now}
+	s.mu.Unlock()
+	return perMinute, nil
+}
+
+// VerifyAdmission checks a token presented by customerID (empty for anonymous
+// buyers) for a hold on eventID and returns the queue entry it admits. Tokens
+// of customers' entries are only accepted from the same customer; anonymous
+// tokens are limited by the one hold their entry may keep.
+func (s *WaitingRoomService) VerifyAdmission(token, eventID, customerID string) (string, error) {
+	if token == "" {
+		return "", domain.ErrAdmissionRequired
+	}
+	entryID, owner, err := s.tokens.Verify(token, eventID)
+	if err != nil || (owner != "" && owner != customerID) {
+		return "", domain.ErrInvalidAdmissionToken
+	}
+	return entryID, nil
+}
+
+// AdmitDue admits waiting buyers so that no event lets in more than its
+// AdmitPerMinute over any minute. It returns how many were admitted.
+func (s *WaitingRoomService) AdmitDue(ctx context.Context) (int, error) {
+	eventIDs, err := s.repo.ListQueuedEvents(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	admitted := 0
+	for _, eventID := range eventIDs {
+		err := s.repo.WithTx(ctx, func(txCtx context.Context) error {
+			room, err := s.repo.GetWaitingRoomForUpdate(txCtx, eventID)
+			if err != nil {
+				return err
+			}
+			if !room.Enabled || room.AdmitPerMinute <= 0 {
+				return nil
+			}
+			now := s.clock.Now()
+			recent, err := s.repo.CountAdmittedSince(txCtx, eventID, now.Add(-time.Minute))
+			if err != nil {
+				return err
+			}
+			if recent >= room.AdmitPerMinute {
+				return nil
+			}
+			n, err := s.repo.AdmitNext(txCtx, eventID, room.AdmitPerMinute-recent, now)
+			if err != nil {
+				return err
+			}
+			admitted += n
+			return nil
+		})
+		if err != nil {
+			return admitted, err
+		}
+	}
+	return admitted, nil
+}
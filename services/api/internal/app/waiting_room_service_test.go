@@ -0,0 +1,383 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cimillas/ultimate-ticket/services/api/internal/clock"
+	"github.com/cimillas/ultimate-ticket/services/api/internal/domain"
+)
+
+func TestWaitingRoomService_Join(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	repo := newFakeWaitingRoomRepo(map[string]domain.WaitingRoom{
+		"event-1": {Enabled: true, AdmitPerMinute: 10},
+		"event-2": {},
+	})
+	svc := NewWaitingRoomService(repo, fakeTokens{}, clock.NewFixed(now))
+	ctx := context.Background()
+
+	first, err := svc.Join(ctx, "event-1", "")
+	if err != nil {
+		t.Fatalf("join: %v", err)
+	}
+	second, err := svc.Join(ctx, "event-1", "cust-1")
+	if err != nil {
+		t.Fatalf("join: %v", err)
+	}
+	if first.Entry.Position != 1 || second.Entry.Position != 2 || second.Entry.CustomerID != "cust-1" {
+		t.Fatalf("expected positions 1 and 2, got %+v and %+v", first.Entry, second.Entry)
+	}
+	if first.Entry.Status != domain.QueueEntryWaiting || first.AdmissionToken != "" {
+		t.Fatalf("expected a waiting entry without token, got %+v", first)
+	}
+
+	if !first.Created || !second.Created {
+		t.Fatal("expected new entries to be reported as created")
+	}
+
+	again, err := svc.Join(ctx, "event-1", "cust-1")
+	if err != nil {
+		t.Fatalf("join again: %v", err)
+	}
+	if again.Created || again.Entry.ID != second.Entry.ID || again.Entry.Position != 2 {
+		t.Fatalf("expected the customer's waiting entry back, got %+v", again)
+	}
+	if anonymous, _ := svc.Join(ctx, "event-1", ""); !anonymous.Created || anonymous.Entry.Position != 3 {
+		t.Fatalf("expected anonymous buyers to get a new entry, got %+v", anonymous)
+	}
+
+	admittedAt := now.Add(-time.Minute)
+	repo.entries[1].Status = domain.QueueEntryAdmitted
+	repo.entries[1].AdmittedAt = &admittedAt
+	admitted, err := svc.Join(ctx, "event-1", "cust-1")
+	if err != nil {
+		t.Fatalf("join while admitted: %v", err)
+	}
+	if admitted.Created || admitted.AdmissionToken != "event-1/"+second.Entry.ID+"/cust-1" {
+		t.Fatalf("expected the admitted entry and its token back, got %+v", admitted)
+	}
+
+	lapsed := now.Add(-defaultAdmissionTTL)
+	repo.entries[1].AdmittedAt = &lapsed
+	rejoined, err := svc.Join(ctx, "event-1", "cust-1")
+	if err != nil {
+		t.Fatalf("rejoin: %v", err)
+	}
+	if !rejoined.Created || rejoined.Entry.ID == second.Entry.ID {
+		t.Fatalf("expected a new entry once the admission expired, got %+v", rejoined)
+	}
+
+	if _, err := svc.Join(ctx, "event-2", ""); err != domain.ErrWaitingRoomDisabled {
+		t.Fatalf("expected ErrWaitingRoomDisabled, got %v", err)
+	}
+	if _, err := svc.Join(ctx, "event-3", ""); err != domain.ErrEventNotFound {
+		t.Fatalf("expected ErrEventNotFound, got %v", err)
+	}
+}
+
+func TestWaitingRoomService_AdmitDue(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	repo := newFakeWaitingRoomRepo(map[string]domain.WaitingRoom{
+		"event-1": {Enabled: true, AdmitPerMinute: 3},
+	})
+	// One buyer was admitted 30s ago and still counts against this minute.
+	admittedAt := now.Add(-30 * time.Second)
+	repo.entries = append(repo.entries, domain.QueueEntry{ID: "early", EventID: "event-1", Status: domain.QueueEntryAdmitted, AdmittedAt: &admittedAt})
+	for _, id := range []string{"a", "b", "c", "d"} {
+		repo.entries = append(repo.entries, domain.QueueEntry{ID: id, EventID: "event-1", Status: domain.QueueEntryWaiting})
+	}
+
+	svc := NewWaitingRoomService(repo, fakeTokens{}, clock.NewFixed(now))
+	n, err := svc.AdmitDue(context.Background())
+	if err != nil {
+		t.Fatalf("admit: %v", err)
+	}
+	if n != 2 {
+		t.Fatalf("expected 2 admitted, got %d", n)
+	}
+	for id, want := range map[string]domain.QueueEntryStatus{"a": domain.QueueEntryAdmitted, "b": domain.QueueEntryAdmitted, "c": domain.QueueEntryWaiting} {
+		if got := repo.entry(id).Status; got != want {
+			t.Fatalf("expected %s to be %s, got %s", id, want, got)
+		}
+	}
+
+	if n, _ := svc.AdmitDue(context.Background()); n != 0 {
+		t.Fatalf("expected the minute's allowance to be used up, admitted %d", n)
+	}
+	later := NewWaitingRoomService(repo, fakeTokens{}, clock.NewFixed(now.Add(time.Minute)))
+	if n, _ := later.AdmitDue(context.Background()); n != 2 {
+		t.Fatalf("expected the rest to be admitted a minute later, got %d", n)
+	}
+}
+
+func TestWaitingRoomService_Status(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	ttl := 5 * time.Minute
+	fresh := now.Add(-time.Minute)
+	stale := now.Add(-ttl)
+	repo := newFakeWaitingRoomRepo(map[string]domain.WaitingRoom{"event-1": {Enabled: true, AdmitPerMinute: 10}})
+	repo.entries = []domain.QueueEntry{
+		{ID: "waiting", EventID: "event-1", Status: domain.QueueEntryWaiting},
+		{ID: "admitted", EventID: "event-1", Status: domain.QueueEntryAdmitted, AdmittedAt: &fresh},
+		{ID: "stale", EventID: "event-1", Status: domain.QueueEntryAdmitted, AdmittedAt: &stale},
+	}
+	svc := NewWaitingRoomService(repo, fakeTokens{}, clock.NewFixed(now), WithAdmissionTTL(ttl))
+	ctx := context.Background()
+
+	status, err := svc.Status(ctx, "waiting")
+	if err != nil || status.Entry.Position != 1 || status.AdmissionToken != "" {
+		t.Fatalf("expected position 1 without token, got %+v (%v)", status, err)
+	}
+
+	status, err = svc.Status(ctx, "admitted")
+	if err != nil {
+		t.Fatalf("status: %v", err)
+	}
+	if status.AdmissionToken != "event-1/admitted" || !status.ExpiresAt.Equal(fresh.Add(ttl)) {
+		t.Fatalf("expected a token until %v, got %+v", fresh.Add(ttl), status)
+	}
+
+	status, err = svc.Status(ctx, "stale")
+	if err != nil || status.Entry.Status != domain.QueueEntryExpired || status.AdmissionToken != "" {
+		t.Fatalf("expected an expired entry without token, got %+v (%v)", status, err)
+	}
+
+	if _, err := svc.Status(ctx, "missing"); err != domain.ErrQueueEntryNotFound {
+		t.Fatalf("expected ErrQueueEntryNotFound, got %v", err)
+	}
+}
+
+func TestWaitingRoomService_Estimate(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	recently := now.Add(-20 * time.Second)
+
+	tests := []struct {
+		name          string
+		room          domain.WaitingRoom
+		admitted      int
+		position      int
+		expectedWait  time.Duration
+		expectedRetry time.Duration
+	}{
+		{name: "configured rate before anyone is admitted", room: domain.WaitingRoom{Enabled: true, AdmitPerMinute: 10}, position: 20, expectedWait: 2 * time.Minute, expectedRetry: 30 * time.Second},
+		{name: "observed rate", room: domain.WaitingRoom{Enabled: true, AdmitPerMinute: 10}, admitted: 5, position: 5, expectedWait: time.Minute, expectedRetry: 15 * time.Second},
+		{name: "short wait polls often", room: domain.WaitingRoom{Enabled: true, AdmitPerMinute: 60}, position: 1, expectedWait: time.Second, expectedRetry: 2 * time.Second},
+		{name: "no admissions", room: domain.WaitingRoom{Enabled: false}, position: 3, expectedRetry: 30 * time.Second},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newFakeWaitingRoomRepo(map[string]domain.WaitingRoom{"event-1": tt.room})
+			for i := 0; i < tt.admitted; i++ {
+				repo.entries = append(repo.entries, domain.QueueEntry{ID: "admitted-" + string(rune('a'+i)), EventID: "event-1", Status: domain.QueueEntryAdmitted, AdmittedAt: &recently})
+			}
+			for i := 0; i < tt.position; i++ {
+				repo.entries = append(repo.entries, domain.QueueEntry{ID: "waiting-" + string(rune('a'+i)), EventID: "event-1", Status: domain.QueueEntryWaiting})
+			}
+			svc := NewWaitingRoomService(repo, fakeTokens{}, clock.NewFixed(now))
+
+			status, err := svc.Status(context.Background(), "waiting-"+string(rune('a'+tt.position-1)))
+			if err != nil {
+				t.Fatalf("status: %v", err)
+			}
+			if status.Ahead != tt.position-1 {
+				t.Fatalf("expected %d ahead, got %d", tt.position-1, status.Ahead)
+			}
+			var expectedAt time.Time
+			if tt.expectedWait > 0 {
+				expectedAt = now.Add(tt.expectedWait)
+			}
+			if !status.EstimatedAdmissionAt.Equal(expectedAt) {
+				t.Fatalf("expected admission at %v, got %v", expectedAt, status.EstimatedAdmissionAt)
+			}
+			if status.RetryAfter != tt.expectedRetry {
+				t.Fatalf("expected retry after %v, got %v", tt.expectedRetry, status.RetryAfter)
+			}
+		})
+	}
+}
+
+func TestWaitingRoomService_EstimateCachesRate(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	repo := newFakeWaitingRoomRepo(map[string]domain.WaitingRoom{"event-1": {Enabled: true, AdmitPerMinute: 10}})
+	repo.entries = []domain.QueueEntry{{ID: "waiting", EventID: "event-1", Status: domain.QueueEntryWaiting}}
+	clk := clock.NewFixed(now)
+	svc := NewWaitingRoomService(repo, fakeTokens{}, clk)
+	ctx := context.Background()
+
+	first, err := svc.Status(ctx, "waiting")
+	if err != nil {
+		t.Fatalf("status: %v", err)
+	}
+	repo.rooms["event-1"] = domain.WaitingRoom{Enabled: true, AdmitPerMinute: 1}
+	second, err := svc.Status(ctx, "waiting")
+	if err != nil {
+		t.Fatalf("status: %v", err)
+	}
+	if !second.EstimatedAdmissionAt.Equal(first.EstimatedAdmissionAt) {
+		t.Fatalf("expected the cached rate to be reused, got %v then %v", first.EstimatedAdmissionAt, second.EstimatedAdmissionAt)
+	}
+}
+
+func TestWaitingRoomService_VerifyAdmission(t *testing.T) {
+	svc := NewWaitingRoomService(newFakeWaitingRoomRepo(nil), fakeTokens{}, clock.NewFixed(time.Now()))
+
+	tests := []struct {
+		name       string
+		token      string
+		customerID string
+		wantEntry  string
+		wantErr    error
+	}{
+		{name: "missing", wantErr: domain.ErrAdmissionRequired},
+		{name: "other event", token: "event-2/entry", wantErr: domain.ErrInvalidAdmissionToken},
+		{name: "anonymous entry", token: "event-1/entry", wantEntry: "entry"},
+		{name: "anonymous entry used by a customer", token: "event-1/entry", customerID: "cust-1", wantEntry: "entry"},
+		{name: "customer's own entry", token: "event-1/entry/cust-1", customerID: "cust-1", wantEntry: "entry"},
+		{name: "customer's entry used by another customer", token: "event-1/entry/cust-1", customerID: "cust-2", wantErr: domain.ErrInvalidAdmissionToken},
+		{name: "customer's entry used anonymously", token: "event-1/entry/cust-1", wantErr: domain.ErrInvalidAdmissionToken},
+	}
+	for _, tt := range tests {
+		entryID, err := svc.VerifyAdmission(tt.token, "event-1", tt.customerID)
+		if err != tt.wantErr || entryID != tt.wantEntry {
+			t.Fatalf("%s: expected %q (%v), got %q (%v)", tt.name, tt.wantEntry, tt.wantErr, entryID, err)
+		}
+	}
+}
+
+// fakeTokens issues readable "<event>/<entry>" tokens, with "/<customer>"
+// appended for customers' entries.
+type fakeTokens struct{}
+
+func (fakeTokens) Issue(eventID, entryID, customerID string, _ time.Time) string {
+	if customerID == "" {
+		return eventID + "/" + entryID
+	}
+	return eventID + "/" + entryID + "/" + customerID
+}
+
+func (fakeTokens) Verify(token, eventID string) (string, string, error) {
+	parts := strings.Split(token, "/")
+	if len(parts) < 2 || len(parts) > 3 || parts[0] != eventID || parts[1] == "" {
+		return "", "", errors.New("bad token")
+	}
+	if len(parts) == 3 {
+		return parts[1], parts[2], nil
+	}
+	return parts[1], "", nil
+}
+
+type fakeWaitingRoomRepo struct {
+	rooms   map[string]domain.WaitingRoom
+	entries []domain.QueueEntry
+}
+
+func newFakeWaitingRoomRepo(rooms map[string]domain.WaitingRoom) *fakeWaitingRoomRepo {
+	return &fakeWaitingRoomRepo{rooms: rooms}
+}
+
+func (f *fakeWaitingRoomRepo) entry(id string) domain.QueueEntry {
+	for _, e := range f.entries {
+		if e.ID == id {
+			return e
+		}
+	}
+	return domain.QueueEntry{}
+}
+
+func (f *fakeWaitingRoomRepo) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+func (f *fakeWaitingRoomRepo) GetWaitingRoom(_ context.Context, eventID string) (domain.WaitingRoom, error) {
+	room, ok := f.rooms[eventID]
+	if !ok {
+		return domain.WaitingRoom{}, domain.ErrEventNotFound
+	}
+	return room, nil
+}
+
+func (f *fakeWaitingRoomRepo) GetWaitingRoomForUpdate(ctx context.Context, eventID string) (domain.WaitingRoom, error) {
+	return f.GetWaitingRoom(ctx, eventID)
+}
+
+func (f *fakeWaitingRoomRepo) ListQueuedEvents(_ context.Context) ([]string, error) {
+	seen := map[string]bool{}
+	var ids []string
+	for _, e := range f.entries {
+		if e.Status == domain.QueueEntryWaiting && f.rooms[e.EventID].Enabled && !seen[e.EventID] {
+			seen[e.EventID] = true
+			ids = append(ids, e.EventID)
+		}
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+func (f *fakeWaitingRoomRepo) CreateQueueEntry(_ context.Context, entry domain.QueueEntry) error {
+	for _, e := range f.entries {
+		if entry.CustomerID != "" && e.CustomerID == entry.CustomerID && e.EventID == entry.EventID && e.Status == domain.QueueEntryWaiting {
+			return domain.ErrAlreadyQueued
+		}
+	}
+	f.entries = append(f.entries, entry)
+	return nil
+}
+
+func (f *fakeWaitingRoomRepo) FindCustomerQueueEntry(ctx context.Context, eventID, customerID string) (*domain.QueueEntry, error) {
+	for i := len(f.entries) - 1; i >= 0; i-- {
+		if f.entries[i].EventID == eventID && f.entries[i].CustomerID == customerID {
+			entry, err := f.GetQueueEntry(ctx, f.entries[i].ID)
+			return &entry, err
+		}
+	}
+	return nil, nil
+}
+
+func (f *fakeWaitingRoomRepo) GetQueueEntry(_ context.Context, id string) (domain.QueueEntry, error) {
+	position := 0
+	for _, e := range f.entries {
+		if e.Status == domain.QueueEntryWaiting {
+			position++
+		}
+		if e.ID != id {
+			continue
+		}
+		if e.Status == domain.QueueEntryWaiting {
+			e.Position = position
+		}
+		return e, nil
+	}
+	return domain.QueueEntry{}, domain.ErrQueueEntryNotFound
+}
+
+func (f *fakeWaitingRoomRepo) CountAdmittedSince(_ context.Context, eventID string, since time.Time) (int, error) {
+	n := 0
+	for _, e := range f.entries {
+		if e.EventID == eventID && e.Status == domain.QueueEntryAdmitted && e.AdmittedAt.After(since) {
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (f *fakeWaitingRoomRepo) AdmitNext(_ context.Context, eventID string, limit int, now time.Time) (int, error) {
+	n := 0
+	for i := range f.entries {
+		if n == limit {
+			break
+		}
+		if f.entries[i].EventID == eventID && f.entries[i].Status == domain.QueueEntryWaiting {
+			f.entries[i].Status = domain.QueueEntryAdmitted
+			f.entries[i].AdmittedAt = &now
+			n++
+		}
+	}
+	return n, nil
+}
@@ -0,0 +1,394 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/cimillas/ultimate-ticket/services/api/internal/clock"
+	"github.com/cimillas/ultimate-ticket/services/api/internal/domain"
+	"github.com/cimillas/ultimate-ticket/services/api/internal/netguard"
+)
+
+// WebhookRepository stores subscriptions and deliveries. Subscriptions,
+// deliveries and attempts of other organizers are reported as not found.
+type WebhookRepository interface {
+	WithTx(ctx context.Context, fn func(ctx context.Context) error) error
+	CreateWebhookSubscription(ctx context.Context, sub domain.WebhookSubscription) error
+	ListWebhookSubscriptions(ctx context.Context, organizerID string) ([]domain.WebhookSubscription, error)
+	GetWebhookSubscriptionForUpdate(ctx context.Context, organizerID, id string) (domain.WebhookSubscription, error)
+	DeactivateWebhookSubscription(ctx context.Context, organizerID, id string) error
+	// FindAggregateOrganizer returns the organizer owning an outbox aggregate,
+	// or ErrOrganizerNotFound when the aggregate is unknown.
+	FindAggregateOrganizer(ctx context.Context, aggregateType, aggregateID string) (string, error)
+	// CreateWebhookDelivery reports false if the event was already queued for the subscription.
+	CreateWebhookDelivery(ctx context.Context, delivery domain.WebhookDelivery) (bool, error)
+	// ClaimDueWebhookDeliveries returns pending deliveries due at now and
+	// pushes their next attempt to leaseUntil so concurrent dispatchers skip them.
+	ClaimDueWebhookDeliveries(ctx context.Context, now, leaseUntil time.Time, limit int) ([]domain.WebhookDelivery, error)
+	GetWebhookDeliveryForUpdate(ctx context.Context, id string) (domain.WebhookDelivery, error)
+	UpdateWebhookDelivery(ctx context.Context, delivery domain.WebhookDelivery) error
+	RecordWebhookAttempt(ctx context.Context, attempt domain.WebhookAttempt) error
+	ListWebhookDeliveries(ctx context.Context, organizerID, subscriptionID string, status domain.WebhookDeliveryStatus) ([]domain.WebhookDelivery, error)
+	ListWebhookAttempts(ctx context.Context, organizerID, deliveryID string) ([]domain.WebhookAttempt, error)
+	AppendAuditEntry(ctx context.Context, entry domain.AuditEntry) error
+}
+
+// WebhookSender performs the signed HTTP request for a delivery and returns
+// the response status code. Any status outside 2xx counts as a failure.
+type WebhookSender interface {
+	Send(ctx context.Context, req WebhookRequest) (int, error)
+}
+
+type WebhookRequest struct {
+	URL       string
+	Secret    string
+	EventID   string
+	EventType domain.OutboxEventType
+	Body      []byte
+}
+
+// WebhookService manages organizer webhook subscriptions and delivers outbox
+// events to them with retries. It is an OutboxPublisher: publishing an event
+// queues one delivery per matching subscription.
+type WebhookService struct {
+	repo        WebhookRepository
+	sender      WebhookSender
+	clock       clock.Clock
+	retryDelay  time.Duration
+	maxDelay    time.Duration
+	maxAttempts int
+	lease       time.Duration
+	batchSize   int
+	// allowLocal accepts http URLs and non-public hosts, for development.
+	allowLocal bool
+}
+
+const (
+	defaultWebhookRetryDelay  = 10 * time.Second
+	defaultWebhookMaxDelay    = time.Hour
+	defaultWebhookMaxAttempts = 8
+	defaultWebhookLease       = time.Minute
+	defaultWebhookBatchSize   = 50
+)
+
+var _ OutboxPublisher = (*WebhookService)(nil)
+
+func NewWebhookService(repo WebhookRepository, sender WebhookSender, clk clock.Clock, opts ...WebhookServiceOption) *WebhookService {
+	svc := &WebhookService{
+		repo:        repo,
+		sender:      sender,
+		clock:       clk,
+		retryDelay:  defaultWebhookRetryDelay,
+		maxDelay:    defaultWebhookMaxDelay,
+		maxAttempts: defaultWebhookMaxAttempts,
+		lease:       defaultWebhookLease,
+		batchSize:   defaultWebhookBatchSize,
+	}
+	for _, opt := range opts {
+		opt(svc)
+	}
+	return svc
+}
+
+type WebhookServiceOption func(*WebhookService)
+
+// WithWebhookRetry sets the backoff between attempts and how many attempts a
+// delivery gets before it is dead-lettered.
+func WithWebhookRetry(initial, maxDelay time.Duration, maxAttempts int) WebhookServiceOption {
+	return func(s *WebhookService) {
+		if initial > 0 {
+			s.retryDelay = initial
+		}
+		if maxDelay >= s.retryDelay {
+			s.maxDelay = maxDelay
+		}
+		if maxAttempts > 0 {
+			s.maxAttempts = maxAttempts
+		}
+	}
+}
+
+// WithLocalWebhookURLs accepts plain http URLs and loopback or private hosts,
+// so webhooks can be pointed at a local receiver. Development only.
+func WithLocalWebhookURLs() WebhookServiceOption {
+	return func(s *WebhookService) {
+		s.allowLocal = true
+	}
+}
+
+type CreateWebhookInput struct {
+	OrganizerID string
+	URL         string
+	Secret      string
+	EventTypes  []string
+}
+
+func (s *WebhookService) CreateSubscription(ctx context.Context, in CreateWebhookInput) (domain.WebhookSubscription, error) {
+	if !s.validURL(in.URL) {
+		return domain.WebhookSubscription{}, domain.ErrInvalidWebhookURL
+	}
+	if in.Secret == "" {
+		return domain.WebhookSubscription{}, domain.ErrWebhookSecretRequired
+	}
+	types := make([]domain.OutboxEventType, 0, len(in.EventTypes))
+	for _, t := range in.EventTypes {
+		eventType := domain.OutboxEventType(t)
+		if !eventType.Valid() {
+			return domain.WebhookSubscription{}, domain.ErrInvalidWebhookEvent
+		}
+		types = append(types, eventType)
+	}
+
+	sub := domain.WebhookSubscription{
+		ID:          newUUID(),
+		OrganizerID: in.OrganizerID,
+		URL:         in.URL,
+		Secret:      in.Secret,
+		EventTypes:  types,
+		Active:      true,
+		CreatedAt:   s.clock.Now(),
+	}
+	err := s.repo.WithTx(ctx, func(txCtx context.Context) error {
+		if err := s.repo.CreateWebhookSubscription(txCtx, sub); err != nil {
+			return err
+		}
+		return s.audit(txCtx, in.OrganizerID, domain.AuditWebhookCreated, domain.AuditTargetWebhook, sub.ID, nil, newWebhookSnapshot(sub))
+	})
+	if err != nil {
+		return domain.WebhookSubscription{}, err
+	}
+	return sub, nil
+}
+
+// validURL accepts absolute https URLs to public hosts; names are checked
+// again against the addresses they resolve to when a delivery is sent.
+func (s *WebhookService) validURL(raw string) bool {
+	u, err := url.Parse(raw)
+	if err != nil || u.Hostname() == "" {
+		return false
+	}
+	if s.allowLocal {
+		return u.Scheme == "http" || u.Scheme == "https"
+	}
+	return u.Scheme == "https" && netguard.CheckHost(u.Hostname()) == nil
+}
+
+func (s *WebhookService) ListSubscriptions(ctx context.Context, organizerID string) ([]domain.WebhookSubscription, error) {
+	return s.repo.ListWebhookSubscriptions(ctx, organizerID)
+}
+
+// DeleteSubscription deactivates a subscription; its delivery history is kept.
+func (s *WebhookService) DeleteSubscription(ctx context.Context, organizerID, id string) error {
+	if id == "" {
+		return domain.ErrInvalidID
+	}
+	return s.repo.WithTx(ctx, func(txCtx context.Context) error {
+		sub, err := s.repo.GetWebhookSubscriptionForUpdate(txCtx, organizerID, id)
+		if err != nil {
+			return err
+		}
+		if err := s.repo.DeactivateWebhookSubscription(txCtx, organizerID, id); err != nil {
+			return err
+		}
+		deactivated := sub
+		deactivated.Active = false
+		return s.audit(txCtx, organizerID, domain.AuditWebhookDeleted, domain.AuditTargetWebhook, id,
+			newWebhookSnapshot(sub), newWebhookSnapshot(deactivated))
+	})
+}
+
+type webhookBody struct {
+	ID        string                 `json:"id"`
+	Type      domain.OutboxEventType `json:"type"`
+	CreatedAt time.Time              `json:"created_at"`
+	Data      json.RawMessage        `json:"data"`
+}
+
+// Publish queues the event for every subscription of the aggregate's
+// organizer that wants it. Redelivered events are queued once per subscription.
+func (s *WebhookService) Publish(ctx context.Context, event domain.OutboxEvent) error {
+	body, err := json.Marshal(webhookBody{
+		ID:        event.ID,
+		Type:      event.Type,
+		CreatedAt: event.CreatedAt,
+		Data:      json.RawMessage(event.Payload),
+	})
+	if err != nil {
+		return fmt.Errorf("encode webhook body: %w", err)
+	}
+
+	now := s.clock.Now()
+	return s.repo.WithTx(ctx, func(txCtx context.Context) error {
+		organizerID, err := s.repo.FindAggregateOrganizer(txCtx, event.AggregateType, event.AggregateID)
+		if err == domain.ErrOrganizerNotFound {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		subs, err := s.repo.ListWebhookSubscriptions(txCtx, organizerID)
+		if err != nil {
+			return err
+		}
+		for _, sub := range subs {
+			if !sub.Wants(event.Type) {
+				continue
+			}
+			_, err := s.repo.CreateWebhookDelivery(txCtx, domain.WebhookDelivery{
+				ID:             newUUID(),
+				OrganizerID:    organizerID,
+				SubscriptionID: sub.ID,
+				EventID:        event.ID,
+				EventType:      event.Type,
+				Payload:        body,
+				Status:         domain.WebhookDeliveryPending,
+				NextAttemptAt:  now,
+				CreatedAt:      now,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// DispatchDue sends the deliveries that are due and returns how many
+// succeeded. Requests are made outside any transaction; the claim lease keeps
+// other dispatchers from sending the same delivery meanwhile.
+func (s *WebhookService) DispatchDue(ctx context.Context) (int, error) {
+	now := s.clock.Now()
+	var due []domain.WebhookDelivery
+	err := s.repo.WithTx(ctx, func(txCtx context.Context) error {
+		var err error
+		due, err = s.repo.ClaimDueWebhookDeliveries(txCtx, now, now.Add(s.lease), s.batchSize)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	delivered := 0
+	for _, d := range due {
+		ok, err := s.deliver(ctx, d)
+		if err != nil {
+			return delivered, err
+		}
+		if ok {
+			delivered++
+		}
+	}
+	return delivered, nil
+}
+
+func (s *WebhookService) deliver(ctx context.Context, d domain.WebhookDelivery) (bool, error) {
+	started := s.clock.Now()
+	status, sendErr := s.sender.Send(ctx, WebhookRequest{
+		URL:       d.URL,
+		Secret:    d.Secret,
+		EventID:   d.EventID,
+		EventType: d.EventType,
+		Body:      d.Payload,
+	})
+	finished := s.clock.Now()
+
+	attempt := domain.WebhookAttempt{
+		DeliveryID:  d.ID,
+		AttemptedAt: started,
+		StatusCode:  status,
+		Duration:    finished.Sub(started),
+	}
+	if sendErr == nil && (status < 200 || status > 299) {
+		sendErr = fmt.Errorf("unexpected status %d", status)
+	}
+	if sendErr != nil {
+		attempt.Error = sendErr.Error()
+	}
+
+	d.Attempts++
+	if sendErr == nil {
+		d.Status = domain.WebhookDeliveryDelivered
+		d.DeliveredAt = &finished
+		d.LastError = ""
+	} else {
+		d.LastError = attempt.Error
+		if d.Attempts >= s.maxAttempts {
+			d.Status = domain.WebhookDeliveryDead
+		} else {
+			d.NextAttemptAt = finished.Add(backoff(s.retryDelay, s.maxDelay, d.Attempts))
+		}
+	}
+
+	err := s.repo.WithTx(ctx, func(txCtx context.Context) error {
+		if err := s.repo.RecordWebhookAttempt(txCtx, attempt); err != nil {
+			return err
+		}
+		return s.repo.UpdateWebhookDelivery(txCtx, d)
+	})
+	if err != nil {
+		return false, err
+	}
+	return sendErr == nil, nil
+}
+
+// ListDeliveries returns a subscription's deliveries, optionally filtered by status.
+func (s *WebhookService) ListDeliveries(ctx context.Context, organizerID, subscriptionID string, status domain.WebhookDeliveryStatus) ([]domain.WebhookDelivery, error) {
+	if subscriptionID == "" {
+		return nil, domain.ErrInvalidID
+	}
+	return s.repo.ListWebhookDeliveries(ctx, organizerID, subscriptionID, status)
+}
+
+func (s *WebhookService) ListAttempts(ctx context.Context, organizerID, deliveryID string) ([]domain.WebhookAttempt, error) {
+	if deliveryID == "" {
+		return nil, domain.ErrInvalidID
+	}
+	return s.repo.ListWebhookAttempts(ctx, organizerID, deliveryID)
+}
+
+// ReplayDelivery requeues a dead delivery with a fresh retry budget.
+func (s *WebhookService) ReplayDelivery(ctx context.Context, organizerID, deliveryID string) (domain.WebhookDelivery, error) {
+	if deliveryID == "" {
+		return domain.WebhookDelivery{}, domain.ErrInvalidID
+	}
+
+	now := s.clock.Now()
+	var result domain.WebhookDelivery
+	err := s.repo.WithTx(ctx, func(txCtx context.Context) error {
+		d, err := s.repo.GetWebhookDeliveryForUpdate(txCtx, deliveryID)
+		if err != nil {
+			return err
+		}
+		if d.OrganizerID != organizerID {
+			return domain.ErrDeliveryNotFound
+		}
+		if d.Status != domain.WebhookDeliveryDead {
+			return domain.ErrDeliveryNotReplayable
+		}
+		before := d
+		d.Status = domain.WebhookDeliveryPending
+		d.Attempts = 0
+		d.NextAttemptAt = now
+		if err := s.repo.UpdateWebhookDelivery(txCtx, d); err != nil {
+			return err
+		}
+		result = d
+		return s.audit(txCtx, organizerID, domain.AuditDeliveryReplayed, domain.AuditTargetDelivery, d.ID,
+			newDeliverySnapshot(before), newDeliverySnapshot(d))
+	})
+	if err != nil {
+		return domain.WebhookDelivery{}, err
+	}
+	return result, nil
+}
+
+func (s *WebhookService) audit(ctx context.Context, organizerID string, action domain.AuditAction, targetType, targetID string, before, after any) error {
+	entry, err := newAuditEntry(ctx, organizerID, action, targetType, targetID, before, after, s.clock.Now())
+	if err != nil {
+		return err
+	}
+	return s.repo.AppendAuditEntry(ctx, entry)
+}
@@ -0,0 +1,378 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cimillas/ultimate-ticket/services/api/internal/clock"
+	"github.com/cimillas/ultimate-ticket/services/api/internal/domain"
+)
+
+func TestWebhookService_CreateSubscription(t *testing.T) {
+	t.Parallel()
+
+	svc := NewWebhookService(newFakeWebhookRepo(), &stubWebhookSender{}, clock.NewFixed(time.Now()))
+
+	tests := []struct {
+		name    string
+		in      CreateWebhookInput
+		wantErr error
+	}{
+		{name: "valid", in: CreateWebhookInput{URL: "https://crm.example.com/hook", Secret: "s", EventTypes: []string{"order.paid"}}},
+		{name: "all events", in: CreateWebhookInput{URL: "https://crm.example.com", Secret: "s"}},
+		{name: "bad scheme", in: CreateWebhookInput{URL: "ftp://crm.example.com", Secret: "s"}, wantErr: domain.ErrInvalidWebhookURL},
+		{name: "plain http", in: CreateWebhookInput{URL: "http://crm.example.com/hook", Secret: "s"}, wantErr: domain.ErrInvalidWebhookURL},
+		{name: "loopback", in: CreateWebhookInput{URL: "https://localhost:9000", Secret: "s"}, wantErr: domain.ErrInvalidWebhookURL},
+		{name: "metadata endpoint", in: CreateWebhookInput{URL: "https://169.254.169.254/latest/meta-data", Secret: "s"}, wantErr: domain.ErrInvalidWebhookURL},
+		{name: "private address", in: CreateWebhookInput{URL: "https://10.0.0.5/hook", Secret: "s"}, wantErr: domain.ErrInvalidWebhookURL},
+		{name: "missing host", in: CreateWebhookInput{URL: "https://", Secret: "s"}, wantErr: domain.ErrInvalidWebhookURL},
+		{name: "missing secret", in: CreateWebhookInput{URL: "https://crm.example.com"}, wantErr: domain.ErrWebhookSecretRequired},
+		{name: "unknown event", in: CreateWebhookInput{URL: "https://crm.example.com", Secret: "s", EventTypes: []string{"order.shipped"}}, wantErr: domain.ErrInvalidWebhookEvent},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			sub, err := svc.CreateSubscription(context.Background(), tt.in)
+			if err != tt.wantErr {
+				t.Fatalf("expected %v, got %v", tt.wantErr, err)
+			}
+			if err == nil && (sub.ID == "" || !sub.Active) {
+				t.Fatalf("expected active subscription with id, got %+v", sub)
+			}
+		})
+	}
+
+	t.Run("local receivers in development", func(t *testing.T) {
+		local := NewWebhookService(newFakeWebhookRepo(), &stubWebhookSender{}, clock.NewFixed(time.Now()), WithLocalWebhookURLs())
+		if _, err := local.CreateSubscription(context.Background(), CreateWebhookInput{URL: "http://localhost:9000", Secret: "s"}); err != nil {
+			t.Fatalf("expected a local http receiver to be accepted, got %v", err)
+		}
+		if _, err := local.CreateSubscription(context.Background(), CreateWebhookInput{URL: "ftp://localhost", Secret: "s"}); err != domain.ErrInvalidWebhookURL {
+			t.Fatalf("expected ErrInvalidWebhookURL, got %v", err)
+		}
+	})
+}
+
+func TestWebhookService_Delivery(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, 1, 4, 8, 0, 0, 0, time.UTC)
+	event := domain.OutboxEvent{
+		ID:            "evt-1",
+		AggregateType: domain.OutboxAggregateOrder,
+		AggregateID:   "order-1",
+		Type:          domain.OutboxOrderPaid,
+		Payload:       []byte(`{"order_id":"order-1"}`),
+		CreatedAt:     now,
+	}
+
+	setup := func(t *testing.T, sender *stubWebhookSender) (*WebhookService, *fakeWebhookRepo, domain.WebhookSubscription) {
+		t.Helper()
+		repo := newFakeWebhookRepo()
+		repo.organizers["order:order-1"] = "org-1"
+		svc := NewWebhookService(repo, sender, clock.NewFixed(now), WithWebhookRetry(time.Second, time.Minute, 3))
+		sub, err := svc.CreateSubscription(context.Background(), CreateWebhookInput{
+			OrganizerID: "org-1",
+			URL:         "https://crm.example.com/hook",
+			Secret:      "shh",
+			EventTypes:  []string{string(domain.OutboxOrderPaid)},
+		})
+		if err != nil {
+			t.Fatalf("create subscription: %v", err)
+		}
+		for _, in := range []CreateWebhookInput{
+			{OrganizerID: "org-1", URL: "https://other.example.com/hook", Secret: "shh", EventTypes: []string{string(domain.OutboxHoldCreated)}},
+			{OrganizerID: "org-2", URL: "https://rival.example.com/hook", Secret: "shh", EventTypes: []string{string(domain.OutboxOrderPaid)}},
+		} {
+			if _, err := svc.CreateSubscription(context.Background(), in); err != nil {
+				t.Fatalf("create subscription: %v", err)
+			}
+		}
+		if err := svc.Publish(context.Background(), event); err != nil {
+			t.Fatalf("publish: %v", err)
+		}
+		return svc, repo, sub
+	}
+
+	t.Run("publish queues one delivery per matching subscription", func(t *testing.T) {
+		svc, repo, sub := setup(t, &stubWebhookSender{})
+		if err := svc.Publish(context.Background(), event); err != nil {
+			t.Fatalf("republish: %v", err)
+		}
+		if len(repo.deliveries) != 1 {
+			t.Fatalf("expected 1 delivery, got %d", len(repo.deliveries))
+		}
+		d := repo.deliveries[0]
+		if d.SubscriptionID != sub.ID || d.OrganizerID != "org-1" || d.Status != domain.WebhookDeliveryPending {
+			t.Fatalf("unexpected delivery %+v", d)
+		}
+		var body struct {
+			ID   string          `json:"id"`
+			Type string          `json:"type"`
+			Data json.RawMessage `json:"data"`
+		}
+		if err := json.Unmarshal(d.Payload, &body); err != nil {
+			t.Fatalf("decode body: %v", err)
+		}
+		if body.ID != "evt-1" || body.Type != "order.paid" || string(body.Data) != `{"order_id":"order-1"}` {
+			t.Fatalf("unexpected body %s", d.Payload)
+		}
+	})
+
+	t.Run("successful dispatch marks delivered", func(t *testing.T) {
+		sender := &stubWebhookSender{statuses: []int{204}}
+		svc, repo, _ := setup(t, sender)
+
+		n, err := svc.DispatchDue(context.Background())
+		if err != nil || n != 1 {
+			t.Fatalf("expected 1 delivered, got %d (%v)", n, err)
+		}
+		if repo.deliveries[0].Status != domain.WebhookDeliveryDelivered || repo.deliveries[0].DeliveredAt == nil {
+			t.Fatalf("expected delivered, got %+v", repo.deliveries[0])
+		}
+		if len(sender.requests) != 1 || sender.requests[0].Secret != "shh" || sender.requests[0].URL != "https://crm.example.com/hook" {
+			t.Fatalf("unexpected requests %+v", sender.requests)
+		}
+		if len(repo.attempts) != 1 || repo.attempts[0].StatusCode != 204 {
+			t.Fatalf("expected one recorded attempt, got %+v", repo.attempts)
+		}
+	})
+
+	t.Run("failures back off then dead-letter", func(t *testing.T) {
+		sender := &stubWebhookSender{statuses: []int{500, 0, 503}, errs: []error{nil, errors.New("connection refused"), nil}}
+		svc, repo, _ := setup(t, sender)
+
+		if _, err := svc.DispatchDue(context.Background()); err != nil {
+			t.Fatalf("dispatch: %v", err)
+		}
+		d := repo.deliveries[0]
+		if d.Status != domain.WebhookDeliveryPending || d.Attempts != 1 || !d.NextAttemptAt.Equal(now.Add(time.Second)) {
+			t.Fatalf("expected retry in 1s, got %+v", d)
+		}
+
+		repo.deliveries[0].NextAttemptAt = now
+		if _, err := svc.DispatchDue(context.Background()); err != nil {
+			t.Fatalf("dispatch: %v", err)
+		}
+		d = repo.deliveries[0]
+		if d.Attempts != 2 || !d.NextAttemptAt.Equal(now.Add(2*time.Second)) || d.LastError != "connection refused" {
+			t.Fatalf("expected retry in 2s, got %+v", d)
+		}
+
+		repo.deliveries[0].NextAttemptAt = now
+		if _, err := svc.DispatchDue(context.Background()); err != nil {
+			t.Fatalf("dispatch: %v", err)
+		}
+		if repo.deliveries[0].Status != domain.WebhookDeliveryDead {
+			t.Fatalf("expected dead delivery, got %+v", repo.deliveries[0])
+		}
+		if len(repo.attempts) != 3 {
+			t.Fatalf("expected 3 attempts, got %d", len(repo.attempts))
+		}
+	})
+
+	t.Run("replay requeues dead deliveries only", func(t *testing.T) {
+		svc, repo, _ := setup(t, &stubWebhookSender{})
+		id := repo.deliveries[0].ID
+
+		if _, err := svc.ReplayDelivery(context.Background(), "org-1", id); err != domain.ErrDeliveryNotReplayable {
+			t.Fatalf("expected ErrDeliveryNotReplayable, got %v", err)
+		}
+
+		repo.deliveries[0].Status = domain.WebhookDeliveryDead
+		repo.deliveries[0].Attempts = 3
+		if _, err := svc.ReplayDelivery(context.Background(), "org-2", id); err != domain.ErrDeliveryNotFound {
+			t.Fatalf("expected another organizer's replay to fail with ErrDeliveryNotFound, got %v", err)
+		}
+		d, err := svc.ReplayDelivery(context.Background(), "org-1", id)
+		if err != nil {
+			t.Fatalf("replay: %v", err)
+		}
+		if d.Status != domain.WebhookDeliveryPending || d.Attempts != 0 || !d.NextAttemptAt.Equal(now) {
+			t.Fatalf("expected pending delivery due now, got %+v", d)
+		}
+
+		if _, err := svc.ReplayDelivery(context.Background(), "org-1", "missing"); err != domain.ErrDeliveryNotFound {
+			t.Fatalf("expected ErrDeliveryNotFound, got %v", err)
+		}
+	})
+
+	t.Run("events of unknown aggregates are not delivered", func(t *testing.T) {
+		svc, repo, _ := setup(t, &stubWebhookSender{})
+		orphan := event
+		orphan.ID, orphan.AggregateID = "evt-2", "order-gone"
+		if err := svc.Publish(context.Background(), orphan); err != nil {
+			t.Fatalf("publish: %v", err)
+		}
+		if len(repo.deliveries) != 1 {
+			t.Fatalf("expected only the original delivery, got %d", len(repo.deliveries))
+		}
+	})
+}
+
+type stubWebhookSender struct {
+	statuses []int
+	errs     []error
+	requests []WebhookRequest
+}
+
+func (s *stubWebhookSender) Send(_ context.Context, req WebhookRequest) (int, error) {
+	i := len(s.requests)
+	s.requests = append(s.requests, req)
+	status := 200
+	if i < len(s.statuses) {
+		status = s.statuses[i]
+	}
+	var err error
+	if i < len(s.errs) {
+		err = s.errs[i]
+	}
+	return status, err
+}
+
+type fakeWebhookRepo struct {
+	subs       []domain.WebhookSubscription
+	deliveries []domain.WebhookDelivery
+	attempts   []domain.WebhookAttempt
+	// organizers maps "aggregate_type:aggregate_id" to its organizer.
+	organizers map[string]string
+	audit      []domain.AuditEntry
+}
+
+func newFakeWebhookRepo() *fakeWebhookRepo {
+	return &fakeWebhookRepo{organizers: make(map[string]string)}
+}
+
+func (f *fakeWebhookRepo) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+func (f *fakeWebhookRepo) CreateWebhookSubscription(_ context.Context, sub domain.WebhookSubscription) error {
+	f.subs = append(f.subs, sub)
+	return nil
+}
+
+func (f *fakeWebhookRepo) ListWebhookSubscriptions(_ context.Context, organizerID string) ([]domain.WebhookSubscription, error) {
+	var out []domain.WebhookSubscription
+	for _, sub := range f.subs {
+		if sub.OrganizerID == organizerID {
+			out = append(out, sub)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeWebhookRepo) GetWebhookSubscriptionForUpdate(_ context.Context, organizerID, id string) (domain.WebhookSubscription, error) {
+	for _, sub := range f.subs {
+		if sub.ID == id && sub.OrganizerID == organizerID {
+			return sub, nil
+		}
+	}
+	return domain.WebhookSubscription{}, domain.ErrWebhookNotFound
+}
+
+func (f *fakeWebhookRepo) DeactivateWebhookSubscription(_ context.Context, organizerID, id string) error {
+	for i := range f.subs {
+		if f.subs[i].ID == id && f.subs[i].OrganizerID == organizerID {
+			f.subs[i].Active = false
+			return nil
+		}
+	}
+	return domain.ErrWebhookNotFound
+}
+
+func (f *fakeWebhookRepo) AppendAuditEntry(_ context.Context, entry domain.AuditEntry) error {
+	f.audit = append(f.audit, entry)
+	return nil
+}
+
+func (f *fakeWebhookRepo) FindAggregateOrganizer(_ context.Context, aggregateType, aggregateID string) (string, error) {
+	organizerID, ok := f.organizers[aggregateType+":"+aggregateID]
+	if !ok {
+		return "", domain.ErrOrganizerNotFound
+	}
+	return organizerID, nil
+}
+
+func (f *fakeWebhookRepo) CreateWebhookDelivery(_ context.Context, d domain.WebhookDelivery) (bool, error) {
+	for _, existing := range f.deliveries {
+		if existing.SubscriptionID == d.SubscriptionID && existing.EventID == d.EventID {
+			return false, nil
+		}
+	}
+	f.deliveries = append(f.deliveries, d)
+	return true, nil
+}
+
+func (f *fakeWebhookRepo) ClaimDueWebhookDeliveries(_ context.Context, now, leaseUntil time.Time, limit int) ([]domain.WebhookDelivery, error) {
+	var due []domain.WebhookDelivery
+	for i := range f.deliveries {
+		d := &f.deliveries[i]
+		if len(due) == limit || d.Status != domain.WebhookDeliveryPending || d.NextAttemptAt.After(now) {
+			continue
+		}
+		d.NextAttemptAt = leaseUntil
+		claimed := *d
+		for _, sub := range f.subs {
+			if sub.ID == d.SubscriptionID {
+				claimed.URL, claimed.Secret = sub.URL, sub.Secret
+			}
+		}
+		due = append(due, claimed)
+	}
+	return due, nil
+}
+
+func (f *fakeWebhookRepo) GetWebhookDeliveryForUpdate(_ context.Context, id string) (domain.WebhookDelivery, error) {
+	for _, d := range f.deliveries {
+		if d.ID == id {
+			return d, nil
+		}
+	}
+	return domain.WebhookDelivery{}, domain.ErrDeliveryNotFound
+}
+
+func (f *fakeWebhookRepo) UpdateWebhookDelivery(_ context.Context, d domain.WebhookDelivery) error {
+	for i := range f.deliveries {
+		if f.deliveries[i].ID == d.ID {
+			f.deliveries[i] = d
+			return nil
+		}
+	}
+	return domain.ErrDeliveryNotFound
+}
+
+func (f *fakeWebhookRepo) RecordWebhookAttempt(_ context.Context, a domain.WebhookAttempt) error {
+	f.attempts = append(f.attempts, a)
+	return nil
+}
+
+func (f *fakeWebhookRepo) ListWebhookDeliveries(_ context.Context, organizerID, subscriptionID string, status domain.WebhookDeliveryStatus) ([]domain.WebhookDelivery, error) {
+	var out []domain.WebhookDelivery
+	for _, d := range f.deliveries {
+		if d.OrganizerID == organizerID && d.SubscriptionID == subscriptionID && (status == "" || d.Status == status) {
+			out = append(out, d)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeWebhookRepo) ListWebhookAttempts(_ context.Context, organizerID, deliveryID string) ([]domain.WebhookAttempt, error) {
+	owned := false
+	for _, d := range f.deliveries {
+		if d.ID == deliveryID && d.OrganizerID == organizerID {
+			owned = true
+		}
+	}
+	var out []domain.WebhookAttempt
+	for _, a := range f.attempts {
+		if owned && a.DeliveryID == deliveryID {
+			out = append(out, a)
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,37 @@
+package domain
+
+import "time"
+
+// Role decides which admin endpoints an API key may call.
+type Role string
+
+const (
+	// RoleOwner can do everything, including managing API keys.
+	RoleOwner        Role = "owner"
+	RoleEventManager Role = "event_manager"
+	RoleBoxOffice    Role = "box_office"
+	RoleScanner      Role = "scanner"
+	RoleReadOnly     Role = "read_only"
+)
+
+func (r Role) Valid() bool {
+	switch r {
+	case RoleOwner, RoleEventManager, RoleBoxOffice, RoleScanner, RoleReadOnly:
+		return true
+	}
+	return false
+}
+
+// APIKey authenticates admin tooling. The secret is shown once at creation;
+// only its hash is stored. Prefix is kept to tell keys apart in listings.
+type APIKey struct {
+	ID          string
+	OrganizerID string
+	Name        string
+	Prefix      string
+	KeyHash     string
+	Role        Role
+	CreatedAt   time.Time
+	LastUsedAt  *time.Time
+	RevokedAt   *time.Time
+}
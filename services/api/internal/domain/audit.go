@@ -0,0 +1,72 @@
+package domain
+
+import "time"
+
+type AuditAction string
+
+const (
+	AuditEventCreated         AuditAction = "event.created"
+	AuditEventUpdated         AuditAction = "event.updated"
+	AuditZoneCreated          AuditAction = "zone.created"
+	AuditWebhookCreated       AuditAction = "webhook.created"
+	AuditWebhookDeleted       AuditAction = "webhook.deleted"
+	AuditDeliveryReplayed     AuditAction = "webhook_delivery.replayed"
+	AuditNotificationRetried  AuditAction = "notification.retried"
+	AuditAPIKeyCreated        AuditAction = "api_key.created"
+	AuditAPIKeyRevoked        AuditAction = "api_key.revoked"
+	AuditOrganizerCreated     AuditAction = "organizer.created"
+	AuditLotteryCreated       AuditAction = "lottery.created"
+	AuditLotteryDrawn         AuditAction = "lottery.drawn"
+	AuditRiskRulesUpdated     AuditAction = "event.risk_rules_updated"
+	AuditOrderCancelled       AuditAction = "order.cancelled"
+	AuditOrderRefunded        AuditAction = "order.refunded"
+	AuditOrderRefundRequested AuditAction = "order.refund_requested"
+)
+
+// Audit target types name the kind of record an entry is about.
+const (
+	AuditTargetEvent        = "event"
+	AuditTargetZone         = "zone"
+	AuditTargetWebhook      = "webhook"
+	AuditTargetDelivery     = "webhook_delivery"
+	AuditTargetNotification = "notification"
+	AuditTargetAPIKey       = "api_key"
+	AuditTargetOrganizer    = "organizer"
+	AuditTargetLottery      = "lottery"
+	AuditTargetOrder        = "order"
+)
+
+// AuditActorSystem is recorded when a change is not made through an API key,
+// for example from the command line.
+const AuditActorSystem = "system"
+
+// AuditEntry records one administrative change. Entries are written in the
+// same transaction as the change and are never updated or deleted. Before
+// and After are JSON snapshots of the target; either is empty when the
+// target did not exist on that side of the change.
+type AuditEntry struct {
+	ID          string
+	OrganizerID string
+	// Actor identifies who made the change, such as "api_key:<id>".
+	Actor      string
+	Action     AuditAction
+	TargetType string
+	TargetID   string
+	Before     []byte
+	After      []byte
+	RequestID  string
+	CreatedAt  time.Time
+}
+
+// AuditFilter narrows an organizer's audit log. Empty fields match anything;
+// Since is inclusive and Until exclusive.
+type AuditFilter struct {
+	OrganizerID string
+	Actor       string
+	Action      AuditAction
+	TargetType  string
+	TargetID    string
+	Since       *time.Time
+	Until       *time.Time
+	Limit       int
+}
@@ -0,0 +1,26 @@
+package domain
+
+import "time"
+
+// LoginCode is a single-use code emailed to a customer to sign in. Only a
+// hash of the code is stored.
+type LoginCode struct {
+	ID         string
+	Email      string
+	ClientIP   string
+	CodeHash   string
+	Attempts   int
+	ExpiresAt  time.Time
+	ConsumedAt *time.Time
+	CreatedAt  time.Time
+}
+
+// Session is a customer's signed-in session. The bearer token is opaque;
+// only its hash is stored, so a database leak does not expose live tokens.
+type Session struct {
+	TokenHash  string
+	CustomerID string
+	ExpiresAt  time.Time
+	RevokedAt  *time.Time
+	CreatedAt  time.Time
+}
@@ -0,0 +1,22 @@
+package domain
+
+import "time"
+
+// Customer is a ticket buyer identified by email. Holds and orders created
+// while a customer is authenticated belong to that customer.
+type Customer struct {
+	ID        string
+	Email     string
+	CreatedAt time.Time
+}
+
+// CustomerOrder is an order as shown in a customer's order history.
+type CustomerOrder struct {
+	Order     Order
+	EventID   string
+	EventName string
+	StartsAt  time.Time
+	ZoneID    string
+	ZoneName  string
+	Quantity  int
+}
@@ -17,4 +17,102 @@ var (
 	ErrHoldExpired            = errors.New("hold expired")
 	ErrHoldAlreadyConfirmed   = errors.New("hold already confirmed")
 	ErrInvalidID              = errors.New("invalid id")
+	ErrOrderNotFound          = errors.New("order not found")
+	ErrInvalidOrderTransition = errors.New("invalid order transition")
+	ErrPaymentDeclined        = errors.New("payment declined")
+	ErrPaymentUnavailable     = errors.New("payment provider unavailable")
+	ErrPaymentEventInvalid    = errors.New("invalid payment event")
+	ErrInvalidWebhookURL      = errors.New("invalid webhook url")
+	ErrWebhookSecretRequired  = errors.New("webhook secret required")
+	ErrInvalidWebhookEvent    = errors.New("invalid webhook event type")
+	ErrWebhookNotFound        = errors.New("webhook subscription not found")
+	ErrDeliveryNotFound       = errors.New("webhook delivery not found")
+	ErrDeliveryNotReplayable  = errors.New("webhook delivery not replayable")
+	ErrInvalidEmail           = errors.New("invalid email")
+	ErrCustomerNotFound       = errors.New("customer not found")
+	ErrInvalidLoginCode       = errors.New("invalid or expired login code")
+	ErrLoginThrottled         = errors.New("too many sign-in attempts, try again later")
+	ErrInvalidSession         = errors.New("invalid or expired session")
+	ErrInvalidAPIKey          = errors.New("invalid api key")
+	ErrAPIKeyNotFound         = errors.New("api key not found")
+	ErrAPIKeyNameRequired     = errors.New("api key name required")
+	ErrInvalidRole            = errors.New("invalid role")
+	ErrAPIKeysExist           = errors.New("api keys already exist")
+	ErrOrganizerNameRequired  = errors.New("organizer name required")
+	ErrOrganizerNotFound      = errors.New("organizer not found")
+	ErrNotificationNotFound   = errors.New("notification not found")
+	ErrNotificationNotFailed  = errors.New("notification not failed")
+	ErrPurchaseLimitExceeded  = errors.New("purchase limit exceeded")
+	ErrInvalidPurchaseLimit   = errors.New("invalid purchase limit")
+	ErrSignInRequired         = errors.New("sign-in required for events with purchase limits")
+	ErrQuantityBelowMinimum   = errors.New("quantity below zone minimum")
+	ErrQuantityAboveMaximum   = errors.New("quantity above zone maximum")
+	ErrQuantityStepMismatch   = errors.New("quantity not a multiple of zone step")
+	ErrInvalidQuantityRules   = errors.New("invalid zone quantity rules")
+	ErrInvalidAdmissionRate   = errors.New("invalid waiting room admission rate")
+	ErrWaitingRoomDisabled    = errors.New("waiting room not enabled for event")
+	ErrQueueEntryNotFound     = errors.New("queue entry not found")
+	ErrAdmissionRequired      = errors.New("admission token required")
+	ErrInvalidAdmissionToken  = errors.New("invalid or expired admission token")
+	ErrAdmissionUsed          = errors.New("admission already used for a hold")
+	ErrAlreadyQueued          = errors.New("customer already waiting in queue")
+	ErrLotteryNotFound        = errors.New("lottery not found")
+	ErrLotteryExists          = errors.New("zone already has a lottery")
+	ErrInvalidLotteryWindow   = errors.New("invalid lottery registration or claim window")
+	ErrRegistrationClosed     = errors.New("lottery registration not open")
+	ErrRegistrationStillOpen  = errors.New("lottery registration still open")
+	ErrLotteryAlreadyDrawn    = errors.New("lottery already drawn")
+	ErrInvalidSeedHash        = errors.New("seed hash must be a hex SHA-256 digest")
+	ErrLotterySeedMismatch    = errors.New("seed does not match the lottery's seed hash")
+	ErrBallotExists           = errors.New("already entered lottery")
+	ErrBallotNotFound         = errors.New("lottery entry not found")
+	ErrPurchaseRightRequired  = errors.New("zone is sold by lottery; purchase right required")
+	ErrPurchaseRightExceeded  = errors.New("quantity exceeds purchase right")
+	ErrInvalidRiskRules       = errors.New("invalid risk rules")
+	ErrHoldBlocked            = errors.New("hold blocked by risk rules")
+	ErrChallengeRequired      = errors.New("proof of work required by risk rules")
 )
+
+// Purchase limit scopes.
+const (
+	PurchaseLimitEvent = "event"
+	PurchaseLimitZone  = "zone"
+)
+
+// PurchaseLimitError reports that a hold would take a customer past the
+// number of tickets they may hold or buy for an event or zone. It matches
+// ErrPurchaseLimitExceeded with errors.Is.
+type PurchaseLimitError struct {
+	Scope string
+	Limit int
+	// Remaining is how many more tickets the customer may still reserve in
+	// Scope; it is never negative.
+	Remaining int
+}
+
+func (e *PurchaseLimitError) Error() string {
+	return ErrPurchaseLimitExceeded.Error()
+}
+
+func (e *PurchaseLimitError) Is(target error) bool {
+	return target == ErrPurchaseLimitExceeded
+}
+
+// QuantityRuleError reports which of a zone's quantity rules a hold broke,
+// along with the rules themselves so clients can offer valid quantities. Err
+// is one of ErrQuantityBelowMinimum, ErrQuantityAboveMaximum or
+// ErrQuantityStepMismatch and is matched by errors.Is.
+type QuantityRuleError struct {
+	Err  error
+	Min  int
+	Max  int // 0 means no maximum
+	Step int
+}
+
+func (e *QuantityRuleError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *QuantityRuleError) Unwrap() error {
+	return e.Err
+}
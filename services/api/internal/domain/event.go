@@ -4,7 +4,12 @@ import "time"
 
 // Event represents a ticketed event (zone-based inventory).
 type Event struct {
-	ID       string
-	Name     string
-	StartsAt time.Time
+	ID          string
+	OrganizerID string
+	Name        string
+	StartsAt    time.Time
+	WaitingRoom WaitingRoom
+	// PurchaseLimit caps the tickets one customer may hold or buy for the
+	// event; 0 means unlimited.
+	PurchaseLimit int
 }
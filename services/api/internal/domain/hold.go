@@ -8,6 +8,7 @@ const (
 	HoldStatusActive    HoldStatus = "active"
 	HoldStatusConfirmed HoldStatus = "confirmed"
 	HoldStatusExpired   HoldStatus = "expired"
+	HoldStatusReleased  HoldStatus = "released"
 )
 
 // Hold represents reserved inventory for a limited time.
@@ -21,5 +22,28 @@ type Hold struct {
 	IdempotencyKey string
 	// IdempotencyHash can be stored when using hashed keys; not used in logic yet.
 	IdempotencyHash string
-	CreatedAt       time.Time
+	// CustomerID is the owning customer; empty for anonymous holds.
+	CustomerID string
+	// QueueEntryID is the waiting-room admission the hold was made with, if any.
+	QueueEntryID string
+	// PaymentPendingUntil is set while a payment attempt is open and keeps the
+	// hold reserving inventory past ExpiresAt, up to this bounded grace deadline.
+	PaymentPendingUntil *time.Time
+	CreatedAt           time.Time
+}
+
+// ReservedUntil returns when the hold stops reserving inventory.
+func (h Hold) ReservedUntil() time.Time {
+	if h.PaymentPendingUntil != nil && h.PaymentPendingUntil.After(h.ExpiresAt) {
+		return *h.PaymentPendingUntil
+	}
+	return h.ExpiresAt
+}
+
+// IsExpiredAt reports whether the hold no longer reserves inventory at now.
+func (h Hold) IsExpiredAt(now time.Time) bool {
+	if h.Status == HoldStatusExpired {
+		return true
+	}
+	return h.Status == HoldStatusActive && !h.ReservedUntil().After(now)
 }
@@ -0,0 +1,88 @@
+package domain
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+)
+
+type LotteryStatus string
+
+const (
+	LotteryOpen  LotteryStatus = "open"
+	LotteryDrawn LotteryStatus = "drawn"
+)
+
+// Lottery sells a zone by ballot instead of first come, first served.
+// Customers enter during the registration window; a seeded draw then ranks
+// every ballot, and purchase rights are offered in rank order while the zone
+// has tickets. Rights unused within ClaimWindow lapse and roll over to the
+// next runners-up.
+type Lottery struct {
+	ID      string
+	EventID string
+	ZoneID  string
+	// OrganizerID is the organizer of the lottery's event.
+	OrganizerID          string
+	RegistrationOpensAt  time.Time
+	RegistrationClosesAt time.Time
+	ClaimWindow          time.Duration
+	Status               LotteryStatus
+	// SeedHash commits to the draw's seed from the moment the lottery is
+	// created; it is the hex SHA-256 of Seed.
+	SeedHash string
+	// Seed ranks the ballots. It is drawn by the server at creation and kept
+	// secret until the draw, or revealed at the draw by an organizer who
+	// committed their own SeedHash. Drawn lotteries publish it so the ranking
+	// can be reproduced.
+	Seed      string
+	DrawnAt   *time.Time
+	CreatedAt time.Time
+}
+
+// HashLotterySeed returns the commitment stored in SeedHash for seed.
+func HashLotterySeed(seed string) string {
+	sum := sha256.Sum256([]byte(seed))
+	return hex.EncodeToString(sum[:])
+}
+
+// PublishedSeed returns the seed once the lottery is drawn and nothing before.
+func (l Lottery) PublishedSeed() string {
+	if l.Status != LotteryDrawn {
+		return ""
+	}
+	return l.Seed
+}
+
+// RegistrationOpen reports whether customers may enter at now.
+func (l Lottery) RegistrationOpen(now time.Time) bool {
+	return l.Status == LotteryOpen && !now.Before(l.RegistrationOpensAt) && now.Before(l.RegistrationClosesAt)
+}
+
+type BallotStatus string
+
+const (
+	// BallotEntered ballots await the draw.
+	BallotEntered BallotStatus = "entered"
+	// BallotWaitlisted ballots were drawn but have not been offered tickets yet.
+	BallotWaitlisted BallotStatus = "waitlisted"
+	// BallotOffered ballots hold a purchase right until OfferExpiresAt.
+	BallotOffered  BallotStatus = "offered"
+	BallotRedeemed BallotStatus = "redeemed"
+	BallotLapsed   BallotStatus = "lapsed"
+)
+
+// Ballot is one customer's entry in a lottery for Quantity tickets.
+type Ballot struct {
+	ID         string
+	LotteryID  string
+	CustomerID string
+	Quantity   int
+	Status     BallotStatus
+	// Rank is the 1-based place in the draw; 0 before the draw.
+	Rank           int
+	OfferExpiresAt *time.Time
+	// HoldID is the hold that redeemed the purchase right.
+	HoldID    string
+	CreatedAt time.Time
+}
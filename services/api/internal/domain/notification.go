@@ -0,0 +1,53 @@
+package domain
+
+import "time"
+
+type NotificationKind string
+
+const (
+	NotificationOrderConfirmation NotificationKind = "order_confirmation"
+	NotificationOrderCancellation NotificationKind = "order_cancellation"
+	NotificationEventChanged      NotificationKind = "event_changed"
+)
+
+type NotificationStatus string
+
+const (
+	NotificationPending NotificationStatus = "pending"
+	NotificationSent    NotificationStatus = "sent"
+	// NotificationFailed means retries were exhausted; it is only sent again
+	// when retried explicitly.
+	NotificationFailed NotificationStatus = "failed"
+)
+
+// Notification is a rendered email queued for a customer. Bodies are rendered
+// when the notification is queued, so retries send exactly the same message.
+type Notification struct {
+	ID            string
+	OrganizerID   string
+	Kind          NotificationKind
+	Recipient     string
+	Subject       string
+	TextBody      string
+	HTMLBody      string
+	Status        NotificationStatus
+	Attempts      int
+	NextAttemptAt time.Time
+	LastError     string
+	// DedupeKey makes queuing idempotent when the triggering event is redelivered.
+	DedupeKey string
+	CreatedAt time.Time
+	SentAt    *time.Time
+}
+
+// OrderNotificationDetails is what a customer email needs to know about an order.
+type OrderNotificationDetails struct {
+	OrderID     string
+	OrganizerID string
+	Email       string
+	EventID     string
+	EventName   string
+	StartsAt    time.Time
+	ZoneName    string
+	Quantity    int
+}
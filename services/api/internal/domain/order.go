@@ -2,10 +2,75 @@ package domain
 
 import "time"
 
-// Order represents a confirmed purchase derived from a hold.
+type OrderStatus string
+
+const (
+	OrderStatusPendingPayment OrderStatus = "pending_payment"
+	OrderStatusPaid           OrderStatus = "paid"
+	OrderStatusFulfilled      OrderStatus = "fulfilled"
+	OrderStatusFailed         OrderStatus = "failed"
+	OrderStatusCancelled      OrderStatus = "cancelled"
+)
+
+// orderTransitions lists the allowed next states for each order status.
+var orderTransitions = map[OrderStatus][]OrderStatus{
+	OrderStatusPendingPayment: {OrderStatusPaid, OrderStatusFailed, OrderStatusCancelled},
+	OrderStatusPaid:           {OrderStatusFulfilled, OrderStatusCancelled},
+}
+
+// CanTransitionTo reports whether an order in status s may move to next.
+func (s OrderStatus) CanTransitionTo(next OrderStatus) bool {
+	for _, allowed := range orderTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// IsFinal reports whether no further transitions are possible from s.
+func (s OrderStatus) IsFinal() bool {
+	return len(orderTransitions[s]) == 0
+}
+
+// Order represents a purchase derived from a hold.
 type Order struct {
-	ID             string
-	HoldID         string
+	ID     string
+	HoldID string
+	// OrganizerID is copied from the hold's event when the order is created.
+	OrganizerID    string
 	IdempotencyKey string
-	CreatedAt      time.Time
+	Status         OrderStatus
+	// PaymentReference identifies the authorization at the payment provider.
+	PaymentReference string
+	// CustomerID is the owning customer; empty for anonymous orders.
+	CustomerID string
+	// CustomerEmail receives order notifications; it is optional.
+	CustomerEmail string
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	PaidAt        *time.Time
+	FulfilledAt   *time.Time
+	FailedAt      *time.Time
+	CancelledAt   *time.Time
+}
+
+// TransitionTo moves the order to next and records when it happened.
+func (o *Order) TransitionTo(next OrderStatus, at time.Time) error {
+	if !o.Status.CanTransitionTo(next) {
+		return ErrInvalidOrderTransition
+	}
+	o.Status = next
+	o.UpdatedAt = at
+	switch next {
+	case OrderStatusPaid:
+		o.PaidAt = &at
+	case OrderStatusFulfilled:
+		o.FulfilledAt = &at
+	case OrderStatusFailed:
+		o.FailedAt = &at
+	case OrderStatusCancelled:
+		o.CancelledAt = &at
+	}
+	return nil
 }
@@ -0,0 +1,16 @@
+package domain
+
+import "time"
+
+// DefaultOrganizerID owns the data that existed before organizers were
+// introduced, so single-tenant installs keep working unchanged.
+const DefaultOrganizerID = "00000000-0000-0000-0000-000000000001"
+
+// Organizer is a promoter (tenant) using the platform. Events belong to one
+// organizer, and zones, holds and orders belong to it through their event.
+// Admin credentials are bound to an organizer and only see its data.
+type Organizer struct {
+	ID        string
+	Name      string
+	CreatedAt time.Time
+}
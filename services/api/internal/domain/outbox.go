@@ -0,0 +1,55 @@
+package domain
+
+import "time"
+
+type OutboxEventType string
+
+const (
+	OutboxHoldCreated OutboxEventType = "hold.created"
+	OutboxHoldExpired OutboxEventType = "hold.expired"
+	// OutboxOrderPendingPayment announces an order created before its payment
+	// settled; OutboxOrderConfirmed follows only once it is paid.
+	OutboxOrderPendingPayment OutboxEventType = "order.pending_payment"
+	OutboxOrderConfirmed      OutboxEventType = "order.confirmed"
+	OutboxOrderPaid           OutboxEventType = "order.paid"
+	OutboxOrderFailed         OutboxEventType = "order.failed"
+	OutboxOrderCancelled      OutboxEventType = "order.cancelled"
+	OutboxOrderFulfilled      OutboxEventType = "order.fulfilled"
+	// OutboxOrderRefundRequested asks for the order's payment to be refunded.
+	OutboxOrderRefundRequested OutboxEventType = "order.refund_requested"
+	OutboxEventUpdated         OutboxEventType = "event.updated"
+)
+
+// Valid reports whether t is an event type written to the outbox.
+func (t OutboxEventType) Valid() bool {
+	switch t {
+	case OutboxHoldCreated, OutboxHoldExpired, OutboxOrderPendingPayment, OutboxOrderConfirmed, OutboxOrderPaid,
+		OutboxOrderFailed, OutboxOrderCancelled, OutboxOrderFulfilled, OutboxOrderRefundRequested, OutboxEventUpdated:
+		return true
+	}
+	return false
+}
+
+// Aggregate types group outbox events that must be delivered in order.
+const (
+	OutboxAggregateHold  = "hold"
+	OutboxAggregateOrder = "order"
+	OutboxAggregateEvent = "event"
+)
+
+// OutboxEvent is a domain event stored in the same transaction as the change
+// it describes and delivered to downstream systems by the outbox relay.
+// Events of one aggregate are delivered in Seq order.
+type OutboxEvent struct {
+	Seq           int64
+	ID            string
+	AggregateType string
+	AggregateID   string
+	Type          OutboxEventType
+	Payload       []byte
+	CreatedAt     time.Time
+	// Attempts counts failed deliveries; AvailableAt delays the next one.
+	Attempts    int
+	AvailableAt time.Time
+	LastError   string
+}
@@ -0,0 +1,45 @@
+package domain
+
+import "time"
+
+type PaymentEventType string
+
+const (
+	PaymentEventAuthorized PaymentEventType = "payment.authorized"
+	PaymentEventCaptured   PaymentEventType = "payment.captured"
+	PaymentEventFailed     PaymentEventType = "payment.failed"
+	PaymentEventRefunded   PaymentEventType = "payment.refunded"
+)
+
+// Valid reports whether t is a payment event type the API understands.
+func (t PaymentEventType) Valid() bool {
+	switch t {
+	case PaymentEventAuthorized, PaymentEventCaptured, PaymentEventFailed, PaymentEventRefunded:
+		return true
+	}
+	return false
+}
+
+// PaymentEvent is an asynchronous notification from the payment processor.
+// The order is identified by OrderID or, failing that, by PaymentReference.
+type PaymentEvent struct {
+	ID               string
+	Type             PaymentEventType
+	OrderID          string
+	PaymentReference string
+	OccurredAt       time.Time
+}
+
+type PaymentEventOutcome string
+
+const (
+	// PaymentEventProcessed means the event moved the order through its lifecycle.
+	PaymentEventProcessed PaymentEventOutcome = "processed"
+	// PaymentEventDuplicate means the event id was delivered before.
+	PaymentEventDuplicate PaymentEventOutcome = "duplicate"
+	// PaymentEventIgnored means the event did not apply to the order's current state.
+	PaymentEventIgnored PaymentEventOutcome = "ignored"
+	// PaymentEventRejected means the capture arrived after the hold expired;
+	// the order failed and the payment is refunded.
+	PaymentEventRejected PaymentEventOutcome = "rejected"
+)
@@ -0,0 +1,66 @@
+package domain
+
+import (
+	"math"
+	"time"
+)
+
+// RateLimit is a token bucket that holds up to Burst requests and refills at
+// Requests per Per. A zero Burst means Requests.
+type RateLimit struct {
+	Requests int
+	Per      time.Duration
+	Burst    int
+}
+
+// Enabled reports whether the limit allows a finite rate.
+func (l RateLimit) Enabled() bool {
+	return l.Requests > 0 && l.Per > 0
+}
+
+func (l RateLimit) capacity() float64 {
+	if l.Burst > 0 {
+		return float64(l.Burst)
+	}
+	return float64(l.Requests)
+}
+
+func (l RateLimit) perToken() time.Duration {
+	return l.Per / time.Duration(l.Requests)
+}
+
+// TokenBucket is the stored state of one client's bucket.
+type TokenBucket struct {
+	Tokens    float64
+	UpdatedAt time.Time
+}
+
+// FullBucket returns a bucket with no requests taken yet.
+func (l RateLimit) FullBucket(now time.Time) TokenBucket {
+	return TokenBucket{Tokens: l.capacity(), UpdatedAt: now}
+}
+
+// Take refills the bucket up to now and spends one token. It returns the new
+// bucket and, when no token was left, how long until one is; the bucket is
+// then only refilled, not spent.
+func (l RateLimit) Take(b TokenBucket, now time.Time) (TokenBucket, time.Duration) {
+	if elapsed := now.Sub(b.UpdatedAt); elapsed > 0 {
+		b.Tokens = min(l.capacity(), b.Tokens+float64(elapsed)/float64(l.perToken()))
+	}
+	b.UpdatedAt = now
+	if b.Tokens < 1 {
+		return b, time.Duration(math.Ceil((1 - b.Tokens) * float64(l.perToken())))
+	}
+	b.Tokens--
+	return b, 0
+}
+
+// FullAt returns when the bucket will have refilled completely, after which
+// it can be forgotten.
+func (l RateLimit) FullAt(b TokenBucket) time.Time {
+	missing := l.capacity() - b.Tokens
+	if missing <= 0 {
+		return b.UpdatedAt
+	}
+	return b.UpdatedAt.Add(time.Duration(math.Ceil(missing * float64(l.perToken()))))
+}
@@ -0,0 +1,180 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+type RiskDecision string
+
+const (
+	RiskAllow     RiskDecision = "allow"
+	RiskChallenge RiskDecision = "challenge"
+	RiskBlock     RiskDecision = "block"
+)
+
+// Risk reasons name the signals that added to a score.
+const (
+	RiskReasonMissingUserAgent   = "missing_user_agent"
+	RiskReasonBlockedUserAgent   = "blocked_user_agent"
+	RiskReasonAnonymous          = "anonymous"
+	RiskReasonWeakIdempotencyKey = "weak_idempotency_key"
+	RiskReasonIPVelocity         = "ip_velocity"
+	RiskReasonCustomerVelocity   = "customer_velocity"
+	RiskReasonZoneVelocity       = "zone_velocity"
+	// RiskReasonChallengeUnavailable marks attempts allowed because they
+	// scored a challenge while no proof-of-work challenge can be served.
+	RiskReasonChallengeUnavailable = "challenge_unavailable"
+)
+
+// Points each signal adds to a hold's risk score.
+const (
+	riskPointsMissingUserAgent   = 40
+	riskPointsBlockedUserAgent   = 100
+	riskPointsAnonymous          = 10
+	riskPointsWeakIdempotencyKey = 20
+	riskPointsIPVelocity         = 60
+	riskPointsCustomerVelocity   = 60
+	riskPointsZoneVelocity       = 30
+)
+
+// minIdempotencyKeyLength is the shortest idempotency key that does not look
+// hand-rolled; clients are expected to send UUIDs or similar.
+const minIdempotencyKeyLength = 16
+
+// Default score thresholds for new rules.
+const (
+	DefaultRiskChallengeScore = 50
+	DefaultRiskBlockScore     = 100
+)
+
+// RiskRules configure hold risk scoring for one event. Holds scoring at least
+// ChallengeScore must carry a solved proof-of-work challenge, and holds
+// scoring at least BlockScore are refused. Velocity limits count hold
+// attempts over the last minute; zero leaves a limit unchecked.
+type RiskRules struct {
+	Enabled             bool
+	ChallengeScore      int
+	BlockScore          int
+	MaxHoldsPerIP       int
+	MaxHoldsPerCustomer int
+	MaxHoldsPerZone     int
+	BlockedUserAgents   []string
+}
+
+// DefaultRiskRules returns the disabled rules of an event that has none.
+func DefaultRiskRules() RiskRules {
+	return RiskRules{ChallengeScore: DefaultRiskChallengeScore, BlockScore: DefaultRiskBlockScore}
+}
+
+// Valid reports whether the thresholds are positive and ordered and the
+// limits are not negative.
+func (r RiskRules) Valid() bool {
+	return r.ChallengeScore > 0 && r.BlockScore >= r.ChallengeScore &&
+		r.MaxHoldsPerIP >= 0 && r.MaxHoldsPerCustomer >= 0 && r.MaxHoldsPerZone >= 0
+}
+
+// RiskSignals describe one hold attempt.
+type RiskSignals struct {
+	EventID        string
+	ZoneID         string
+	CustomerID     string
+	IP             string
+	UserAgent      string
+	IdempotencyKey string
+	// ProofOfWork is set when the attempt carried a solved challenge.
+	ProofOfWork bool
+}
+
+// RiskVelocity counts hold attempts for the event over the last minute, not
+// including the one being scored.
+type RiskVelocity struct {
+	IP       int
+	Customer int
+	Zone     int
+}
+
+// Score adds up the points of every signal that fired and names them.
+func (r RiskRules) Score(s RiskSignals, v RiskVelocity) (int, []string) {
+	score := 0
+	reasons := []string{}
+	add := func(points int, reason string) {
+		score += points
+		reasons = append(reasons, reason)
+	}
+
+	ua := strings.ToLower(strings.TrimSpace(s.UserAgent))
+	if ua == "" {
+		add(riskPointsMissingUserAgent, RiskReasonMissingUserAgent)
+	}
+	for _, pattern := range r.BlockedUserAgents {
+		if pattern = strings.ToLower(strings.TrimSpace(pattern)); pattern != "" && strings.Contains(ua, pattern) {
+			add(riskPointsBlockedUserAgent, RiskReasonBlockedUserAgent)
+			break
+		}
+	}
+	if s.CustomerID == "" {
+		add(riskPointsAnonymous, RiskReasonAnonymous)
+	}
+	if weakIdempotencyKey(s.IdempotencyKey) {
+		add(riskPointsWeakIdempotencyKey, RiskReasonWeakIdempotencyKey)
+	}
+	if r.MaxHoldsPerIP > 0 && s.IP != "" && v.IP >= r.MaxHoldsPerIP {
+		add(riskPointsIPVelocity, RiskReasonIPVelocity)
+	}
+	if r.MaxHoldsPerCustomer > 0 && s.CustomerID != "" && v.Customer >= r.MaxHoldsPerCustomer {
+		add(riskPointsCustomerVelocity, RiskReasonCustomerVelocity)
+	}
+	if r.MaxHoldsPerZone > 0 && v.Zone >= r.MaxHoldsPerZone {
+		add(riskPointsZoneVelocity, RiskReasonZoneVelocity)
+	}
+	return score, reasons
+}
+
+// Decide maps a score to a decision.
+func (r RiskRules) Decide(score int) RiskDecision {
+	switch {
+	case score >= r.BlockScore:
+		return RiskBlock
+	case score >= r.ChallengeScore:
+		return RiskChallenge
+	default:
+		return RiskAllow
+	}
+}
+
+// weakIdempotencyKey flags short or all-digit keys, which scripts tend to
+// generate from counters.
+func weakIdempotencyKey(key string) bool {
+	if len(key) < minIdempotencyKeyLength {
+		return true
+	}
+	return strings.Trim(key, "0123456789") == ""
+}
+
+// RiskAssessment is one scored hold attempt. Every assessment is kept for
+// review and feeds the velocity counts of later attempts.
+type RiskAssessment struct {
+	ID             string
+	EventID        string
+	ZoneID         string
+	CustomerID     string
+	IP             string
+	UserAgent      string
+	IdempotencyKey string
+	ProofOfWork    bool
+	Score          int
+	Reasons        []string
+	Decision       RiskDecision
+	CreatedAt      time.Time
+}
+
+// RiskDecisionFilter narrows an organizer's risk decisions. Empty fields
+// match anything; Since is inclusive.
+type RiskDecisionFilter struct {
+	OrganizerID string
+	EventID     string
+	Decision    RiskDecision
+	Since       *time.Time
+	Limit       int
+}
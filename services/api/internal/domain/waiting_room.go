@@ -0,0 +1,35 @@
+package domain
+
+import "time"
+
+// WaitingRoom controls admission to an event's sale. When enabled, buyers
+// join a queue and may only create holds with an admission token, which the
+// queue hands out to AdmitPerMinute buyers per minute in join order.
+type WaitingRoom struct {
+	Enabled        bool
+	AdmitPerMinute int
+}
+
+type QueueEntryStatus string
+
+const (
+	QueueEntryWaiting  QueueEntryStatus = "waiting"
+	QueueEntryAdmitted QueueEntryStatus = "admitted"
+	// QueueEntryExpired is reported, never stored, for admitted entries whose
+	// admission token has lapsed; the buyer has to join again.
+	QueueEntryExpired QueueEntryStatus = "expired"
+)
+
+// QueueEntry is one buyer's place in an event's waiting room. A customer has
+// at most one entry waiting per event, and an admission lets its entry keep
+// one hold at a time.
+type QueueEntry struct {
+	ID         string
+	EventID    string
+	CustomerID string
+	Status     QueueEntryStatus
+	// Position is the 1-based place among waiting entries; 0 once admitted.
+	Position   int
+	JoinedAt   time.Time
+	AdmittedAt *time.Time
+}
@@ -0,0 +1,72 @@
+package domain
+
+import "time"
+
+// WebhookSubscription is an organizer endpoint that receives signed copies of
+// domain events. An empty EventTypes list subscribes to every event type.
+type WebhookSubscription struct {
+	ID          string
+	OrganizerID string
+	URL         string
+	Secret      string
+	EventTypes  []OutboxEventType
+	Active      bool
+	CreatedAt   time.Time
+}
+
+// Wants reports whether the subscription should receive events of type t.
+func (s WebhookSubscription) Wants(t OutboxEventType) bool {
+	if !s.Active {
+		return false
+	}
+	if len(s.EventTypes) == 0 {
+		return true
+	}
+	for _, want := range s.EventTypes {
+		if want == t {
+			return true
+		}
+	}
+	return false
+}
+
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryPending   WebhookDeliveryStatus = "pending"
+	WebhookDeliveryDelivered WebhookDeliveryStatus = "delivered"
+	// WebhookDeliveryDead means retries were exhausted; the delivery is only
+	// sent again when replayed.
+	WebhookDeliveryDead WebhookDeliveryStatus = "dead"
+	// WebhookDeliveryCancelled means the subscription was deactivated before
+	// the delivery went out.
+	WebhookDeliveryCancelled WebhookDeliveryStatus = "cancelled"
+)
+
+// WebhookDelivery is one event sent to one subscription.
+type WebhookDelivery struct {
+	ID             string
+	OrganizerID    string
+	SubscriptionID string
+	EventID        string
+	EventType      OutboxEventType
+	Payload        []byte
+	Status         WebhookDeliveryStatus
+	Attempts       int
+	NextAttemptAt  time.Time
+	LastError      string
+	CreatedAt      time.Time
+	DeliveredAt    *time.Time
+	// URL and Secret are loaded from the subscription when the delivery is sent.
+	URL    string
+	Secret string
+}
+
+// WebhookAttempt records a single HTTP request made for a delivery.
+type WebhookAttempt struct {
+	DeliveryID  string
+	AttemptedAt time.Time
+	StatusCode  int
+	Error       string
+	Duration    time.Duration
+}
@@ -6,4 +6,41 @@ type Zone struct {
 	EventID  string
 	Name     string
 	Capacity int
+	// Quantity rules for a single hold. QuantityStep below 1 means 1,
+	// MinQuantity below 1 means one step, and MaxQuantity 0 means no maximum.
+	MinQuantity  int
+	MaxQuantity  int
+	QuantityStep int
+	// PurchaseLimit caps the tickets one customer may hold or buy in the
+	// zone; 0 means unlimited.
+	PurchaseLimit int
+}
+
+// QuantityRules returns the zone's minimum, maximum and step with defaults
+// applied. A zero maximum means there is none.
+func (z Zone) QuantityRules() (minQty, maxQty, step int) {
+	step = max(z.QuantityStep, 1)
+	minQty = z.MinQuantity
+	if minQty < 1 {
+		minQty = step
+	}
+	return minQty, max(z.MaxQuantity, 0), step
+}
+
+// CheckQuantity returns a *QuantityRuleError when a hold of quantity tickets
+// breaks one of the zone's rules.
+func (z Zone) CheckQuantity(quantity int) error {
+	minQty, maxQty, step := z.QuantityRules()
+	var rule error
+	switch {
+	case quantity < minQty:
+		rule = ErrQuantityBelowMinimum
+	case maxQty > 0 && quantity > maxQty:
+		rule = ErrQuantityAboveMaximum
+	case quantity%step != 0:
+		rule = ErrQuantityStepMismatch
+	default:
+		return nil
+	}
+	return &QuantityRuleError{Err: rule, Min: minQty, Max: maxQty, Step: step}
 }
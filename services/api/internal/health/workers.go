@@ -0,0 +1,104 @@
+// Package health tracks the background workers' heartbeats for readiness
+// checks.
+package health
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cimillas/ultimate-ticket/services/api/internal/clock"
+)
+
+// MinStallTimeout is the shortest silence after which a worker counts as
+// stalled; workers on short intervals still get time for a slow run.
+const MinStallTimeout = time.Minute
+
+// Workers records when each background worker last finished a run.
+type Workers struct {
+	clock clock.Clock
+
+	mu      sync.Mutex
+	workers map[string]*worker
+}
+
+type worker struct {
+	interval time.Duration
+	last     time.Time
+	stopped  bool
+}
+
+func NewWorkers(clk clock.Clock) *Workers {
+	return &Workers{clock: clk, workers: make(map[string]*worker)}
+}
+
+// Start registers a worker that runs every interval.
+func (w *Workers) Start(name string, interval time.Duration) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.workers[name] = &worker{interval: interval, last: w.clock.Now()}
+}
+
+// Beat records that the worker finished a run, whether or not it failed.
+func (w *Workers) Beat(name string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if wk, ok := w.workers[name]; ok {
+		wk.last = w.clock.Now()
+	}
+}
+
+// Stop records that the worker exited.
+func (w *Workers) Stop(name string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if wk, ok := w.workers[name]; ok {
+		wk.stopped = true
+	}
+}
+
+// StalledError reports workers that are still running but have not finished
+// a run in time. It is a warning: a slow run (a long lock wait, a slow
+// webhook endpoint) stalls the worker on every replica alike, so taking this
+// one out of rotation would not get the work done sooner.
+type StalledError struct {
+	// Workers lists "<name> stalled for <duration>", sorted.
+	Workers []string
+}
+
+func (e *StalledError) Error() string {
+	return "workers running late: " + strings.Join(e.Workers, ", ")
+}
+
+// Warning marks the error as one readiness reports without failing.
+func (e *StalledError) Warning() bool { return true }
+
+// Check fails when a worker has stopped. Workers that have not finished a run
+// within three intervals, or MinStallTimeout if that is longer, are reported
+// with a *StalledError instead.
+func (w *Workers) Check(context.Context) error {
+	now := w.clock.Now()
+	w.mu.Lock()
+	var stopped, stalled []string
+	for name, wk := range w.workers {
+		switch {
+		case wk.stopped:
+			stopped = append(stopped, name+" stopped")
+		case now.Sub(wk.last) > max(3*wk.interval, MinStallTimeout):
+			stalled = append(stalled, fmt.Sprintf("%s stalled for %s", name, now.Sub(wk.last).Truncate(time.Second)))
+		}
+	}
+	w.mu.Unlock()
+	sort.Strings(stopped)
+	sort.Strings(stalled)
+	if len(stopped) > 0 {
+		return fmt.Errorf("workers not running: %s", strings.Join(append(stopped, stalled...), ", "))
+	}
+	if len(stalled) > 0 {
+		return &StalledError{Workers: stalled}
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type stepClock struct {
+	now time.Time
+}
+
+func (c *stepClock) Now() time.Time { return c.now }
+
+func TestWorkers_Check(t *testing.T) {
+	t.Parallel()
+
+	clk := &stepClock{now: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)}
+	w := NewWorkers(clk)
+	w.Start("outbox relay", time.Second)
+	w.Start("hold expiry", 30*time.Second)
+
+	if err := w.Check(context.Background()); err != nil {
+		t.Fatalf("expected fresh workers to be running, got %v", err)
+	}
+
+	clk.now = clk.now.Add(80 * time.Second)
+	w.Beat("hold expiry")
+	err := w.Check(context.Background())
+	var stalled *StalledError
+	if !errors.As(err, &stalled) || !stalled.Warning() || err.Error() != "workers running late: outbox relay stalled for 1m20s" {
+		t.Fatalf("expected the silent worker to stall after MinStallTimeout, got %v", err)
+	}
+
+	w.Beat("outbox relay")
+	clk.now = clk.now.Add(80 * time.Second)
+	if err := w.Check(context.Background()); !errors.As(err, &stalled) {
+		t.Fatalf("expected outbox relay to stall again, got %v", err)
+	}
+	w.Stop("hold expiry")
+	err = w.Check(context.Background())
+	if errors.As(err, &stalled) || err == nil || err.Error() != "workers not running: hold expiry stopped, outbox relay stalled for 1m20s" {
+		t.Fatalf("expected a stopped worker to fail the check, got %v", err)
+	}
+	w.Start("hold expiry", 30*time.Second)
+	w.Beat("outbox relay")
+	if err := w.Check(context.Background()); err != nil {
+		t.Fatalf("expected hold expiry to get three intervals, got %v", err)
+	}
+
+	w.Stop("hold expiry")
+	err = w.Check(context.Background())
+	if err == nil || err.Error() != "workers not running: hold expiry stopped" {
+		t.Fatalf("expected a stopped worker, got %v", err)
+	}
+}
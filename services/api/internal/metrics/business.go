@@ -0,0 +1,72 @@
+package metrics
+
+import (
+	"strconv"
+	"time"
+)
+
+// Holds counts hold outcomes; it implements app.HoldMetrics.
+type Holds struct {
+	created  *Counter
+	rejected *CounterVec
+	expired  *Counter
+}
+
+func NewHolds(r *Registry) *Holds {
+	return &Holds{
+		created:  r.NewCounterVec("ticket_holds_created_total", "Holds created, not counting idempotent retries.").With(),
+		rejected: r.NewCounterVec("ticket_holds_rejected_total", "Hold attempts refused, by reason.", "reason"),
+		expired:  r.NewCounterVec("ticket_holds_expired_total", "Holds released by the expiry worker.").With(),
+	}
+}
+
+func (h *Holds) HoldCreated()               { h.created.Inc() }
+func (h *Holds) HoldRejected(reason string) { h.rejected.With(reason).Inc() }
+func (h *Holds) HoldsExpired(n int)         { h.expired.Add(float64(n)) }
+
+// Confirmations counts confirmation outcomes; it implements app.OrderMetrics.
+type Confirmations struct {
+	outcomes *CounterVec
+}
+
+func NewConfirmations(r *Registry) *Confirmations {
+	return &Confirmations{
+		outcomes: r.NewCounterVec("ticket_confirmations_total", `Hold confirmations, by outcome ("confirmed" or the reason they failed).`, "outcome"),
+	}
+}
+
+func (c *Confirmations) Confirmation(outcome string) { c.outcomes.With(outcome).Inc() }
+
+// Outbox counts dead-lettered outbox events; it implements app.OutboxMetrics.
+type Outbox struct {
+	dead *CounterVec
+}
+
+func NewOutbox(r *Registry) *Outbox {
+	return &Outbox{
+		dead: r.NewCounterVec("outbox_events_dead_total", "Outbox events that used up their attempts and were dead-lettered, by type.", "type"),
+	}
+}
+
+func (o *Outbox) OutboxEventDead(eventType string) { o.dead.With(eventType).Inc() }
+
+// HTTP counts requests and their latency by route, method and status.
+type HTTP struct {
+	requests *CounterVec
+	duration *HistogramVec
+}
+
+func NewHTTP(r *Registry) *HTTP {
+	return &HTTP{
+		requests: r.NewCounterVec("http_requests_total", "HTTP requests served.", "route", "method", "status"),
+		duration: r.NewHistogramVec("http_request_duration_seconds", "HTTP request latency.", DefaultBuckets, "route", "method", "status"),
+	}
+}
+
+// ObserveRequest records one request. route must come from a bounded set,
+// such as the mux pattern that served it.
+func (h *HTTP) ObserveRequest(route, method string, status int, took time.Duration) {
+	code := strconv.Itoa(status)
+	h.requests.With(route, method, code).Inc()
+	h.duration.With(route, method, code).ObserveDuration(took)
+}
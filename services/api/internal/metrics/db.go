@@ -0,0 +1,52 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// RegisterPool exposes the pool's connection and acquire statistics.
+func RegisterPool(r *Registry, pool *pgxpool.Pool) {
+	gauge := func(name, help string, fn func(s *pgxpool.Stat) float64) {
+		r.NewGaugeFunc(name, help, func() float64 { return fn(pool.Stat()) })
+	}
+	counter := func(name, help string, fn func(s *pgxpool.Stat) float64) {
+		r.NewCounterFunc(name, help, func() float64 { return fn(pool.Stat()) })
+	}
+	gauge("db_pool_max_conns", "Maximum size of the connection pool.",
+		func(s *pgxpool.Stat) float64 { return float64(s.MaxConns()) })
+	gauge("db_pool_total_conns", "Connections open in the pool.",
+		func(s *pgxpool.Stat) float64 { return float64(s.TotalConns()) })
+	gauge("db_pool_acquired_conns", "Connections currently in use.",
+		func(s *pgxpool.Stat) float64 { return float64(s.AcquiredConns()) })
+	gauge("db_pool_idle_conns", "Idle connections in the pool.",
+		func(s *pgxpool.Stat) float64 { return float64(s.IdleConns()) })
+	counter("db_pool_acquires_total", "Connections acquired from the pool.",
+		func(s *pgxpool.Stat) float64 { return float64(s.AcquireCount()) })
+	counter("db_pool_empty_acquires_total", "Acquires that had to wait for a connection.",
+		func(s *pgxpool.Stat) float64 { return float64(s.EmptyAcquireCount()) })
+	counter("db_pool_canceled_acquires_total", "Acquires canceled before a connection was available.",
+		func(s *pgxpool.Stat) float64 { return float64(s.CanceledAcquireCount()) })
+	counter("db_pool_acquire_duration_seconds_total", "Time spent acquiring connections.",
+		func(s *pgxpool.Stat) float64 { return s.AcquireDuration().Seconds() })
+}
+
+// Transactions records how long database transactions take, by outcome.
+type Transactions struct {
+	duration *HistogramVec
+}
+
+func NewTransactions(r *Registry) *Transactions {
+	return &Transactions{
+		duration: r.NewHistogramVec("db_transaction_duration_seconds", "Database transaction duration, from begin to commit or rollback.", DefaultBuckets, "outcome"),
+	}
+}
+
+func (t *Transactions) Observe(took time.Duration, committed bool) {
+	outcome := "rollback"
+	if committed {
+		outcome = "commit"
+	}
+	t.duration.With(outcome).ObserveDuration(took)
+}
@@ -0,0 +1,301 @@
+// Package metrics keeps counters, gauges and histograms in process and writes
+// them in the Prometheus text exposition format.
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultBuckets are latency buckets in seconds, from 5ms to 10s.
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// Registry holds metric families in registration order.
+type Registry struct {
+	mu       sync.Mutex
+	families []family
+	names    map[string]bool
+}
+
+type family interface {
+	name() string
+	write(w *bufio.Writer)
+}
+
+func NewRegistry() *Registry {
+	return &Registry{names: make(map[string]bool)}
+}
+
+func (r *Registry) register(f family) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.names[f.name()] {
+		panic("metrics: duplicate metric " + f.name())
+	}
+	r.names[f.name()] = true
+	r.families = append(r.families, f)
+}
+
+// WriteText writes every metric in the Prometheus text format, version 0.0.4.
+func (r *Registry) WriteText(w io.Writer) error {
+	r.mu.Lock()
+	families := append([]family(nil), r.families...)
+	r.mu.Unlock()
+
+	bw := bufio.NewWriter(w)
+	for _, f := range families {
+		f.write(bw)
+	}
+	return bw.Flush()
+}
+
+// ContentType is the media type of WriteText's output.
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+type meta struct {
+	metricName string
+	help       string
+	labels     []string
+}
+
+func (m meta) name() string { return m.metricName }
+
+func (m meta) header(w *bufio.Writer, kind string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", m.metricName, escapeHelp(m.help), m.metricName, kind)
+}
+
+// series is one labelled value set, keyed by its label values.
+type series[T any] struct {
+	mu     sync.Mutex
+	values map[string]*T
+	labels map[string][]string
+}
+
+func newSeries[T any]() series[T] {
+	return series[T]{values: make(map[string]*T), labels: make(map[string][]string)}
+}
+
+func (s *series[T]) get(m meta, values []string, init func() *T) *T {
+	if len(values) != len(m.labels) {
+		panic(fmt.Sprintf("metrics: %s takes %d label values, got %d", m.metricName, len(m.labels), len(values)))
+	}
+	key := strings.Join(values, "\xff")
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	v, ok := s.values[key]
+	if !ok {
+		v = init()
+		s.values[key] = v
+		s.labels[key] = append([]string(nil), values...)
+	}
+	return v
+}
+
+// each calls fn for every series in label order.
+func (s *series[T]) each(fn func(values []string, v *T)) {
+	s.mu.Lock()
+	keys := make([]string, 0, len(s.values))
+	for k := range s.values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	type entry struct {
+		values []string
+		v      *T
+	}
+	entries := make([]entry, 0, len(keys))
+	for _, k := range keys {
+		entries = append(entries, entry{s.labels[k], s.values[k]})
+	}
+	s.mu.Unlock()
+	for _, e := range entries {
+		fn(e.values, e.v)
+	}
+}
+
+// Counter is a value that only goes up.
+type Counter struct {
+	mu    sync.Mutex
+	value float64
+}
+
+func (c *Counter) Inc() { c.Add(1) }
+
+// Add increases the counter; negative deltas are ignored.
+func (c *Counter) Add(delta float64) {
+	if delta < 0 {
+		return
+	}
+	c.mu.Lock()
+	c.value += delta
+	c.mu.Unlock()
+}
+
+func (c *Counter) get() float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.value
+}
+
+// CounterVec is a family of counters told apart by label values.
+type CounterVec struct {
+	meta
+	series series[Counter]
+}
+
+// NewCounterVec registers a counter family. Without labels, With() returns
+// the single counter.
+func (r *Registry) NewCounterVec(name, help string, labels ...string) *CounterVec {
+	c := &CounterVec{meta: meta{name, help, labels}, series: newSeries[Counter]()}
+	r.register(c)
+	return c
+}
+
+// With returns the counter for the label values, given in registration order.
+func (c *CounterVec) With(values ...string) *Counter {
+	return c.series.get(c.meta, values, func() *Counter { return &Counter{} })
+}
+
+func (c *CounterVec) write(w *bufio.Writer) {
+	c.header(w, "counter")
+	c.series.each(func(values []string, v *Counter) {
+		writeSample(w, c.metricName, c.labels, values, "", "", v.get())
+	})
+}
+
+// Histogram counts observations into cumulative buckets.
+type Histogram struct {
+	mu      sync.Mutex
+	buckets []float64
+	counts  []uint64
+	sum     float64
+	count   uint64
+}
+
+func (h *Histogram) Observe(v float64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for i, upper := range h.buckets {
+		if v <= upper {
+			h.counts[i]++
+		}
+	}
+	h.sum += v
+	h.count++
+}
+
+// ObserveDuration records d in seconds.
+func (h *Histogram) ObserveDuration(d time.Duration) {
+	h.Observe(d.Seconds())
+}
+
+// HistogramVec is a family of histograms told apart by label values.
+type HistogramVec struct {
+	meta
+	buckets []float64
+	series  series[Histogram]
+}
+
+// NewHistogramVec registers a histogram family with the given upper bounds,
+// which must be sorted; the +Inf bucket is implied.
+func (r *Registry) NewHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	h := &HistogramVec{meta: meta{name, help, labels}, buckets: buckets, series: newSeries[Histogram]()}
+	r.register(h)
+	return h
+}
+
+// With returns the histogram for the label values, given in registration order.
+func (h *HistogramVec) With(values ...string) *Histogram {
+	return h.series.get(h.meta, values, func() *Histogram {
+		return &Histogram{buckets: h.buckets, counts: make([]uint64, len(h.buckets))}
+	})
+}
+
+func (h *HistogramVec) write(w *bufio.Writer) {
+	h.header(w, "histogram")
+	h.series.each(func(values []string, v *Histogram) {
+		v.mu.Lock()
+		counts := append([]uint64(nil), v.counts...)
+		sum, count := v.sum, v.count
+		v.mu.Unlock()
+		for i, upper := range h.buckets {
+			writeSample(w, h.metricName+"_bucket", h.labels, values, "le", formatFloat(upper), float64(counts[i]))
+		}
+		writeSample(w, h.metricName+"_bucket", h.labels, values, "le", "+Inf", float64(count))
+		writeSample(w, h.metricName+"_sum", h.labels, values, "", "", sum)
+		writeSample(w, h.metricName+"_count", h.labels, values, "", "", float64(count))
+	})
+}
+
+// funcMetric reads its value when scraped, for state kept elsewhere.
+type funcMetric struct {
+	meta
+	kind string
+	fn   func() float64
+}
+
+// NewGaugeFunc registers a gauge whose value is read from fn on each scrape.
+func (r *Registry) NewGaugeFunc(name, help string, fn func() float64) {
+	r.register(&funcMetric{meta: meta{metricName: name, help: help}, kind: "gauge", fn: fn})
+}
+
+// NewCounterFunc registers a counter whose running total is read from fn on
+// each scrape.
+func (r *Registry) NewCounterFunc(name, help string, fn func() float64) {
+	r.register(&funcMetric{meta: meta{metricName: name, help: help}, kind: "counter", fn: fn})
+}
+
+func (f *funcMetric) write(w *bufio.Writer) {
+	f.header(w, f.kind)
+	writeSample(w, f.metricName, nil, nil, "", "", f.fn())
+}
+
+func writeSample(w *bufio.Writer, name string, labels, values []string, extraLabel, extraValue string, v float64) {
+	w.WriteString(name)
+	if len(labels) > 0 || extraLabel != "" {
+		w.WriteByte('{')
+		for i, label := range labels {
+			if i > 0 {
+				w.WriteByte(',')
+			}
+			fmt.Fprintf(w, `%s="%s"`, label, escapeLabel(values[i]))
+		}
+		if extraLabel != "" {
+			if len(labels) > 0 {
+				w.WriteByte(',')
+			}
+			fmt.Fprintf(w, `%s="%s"`, extraLabel, extraValue)
+		}
+		w.WriteByte('}')
+	}
+	w.WriteByte(' ')
+	w.WriteString(formatFloat(v))
+	w.WriteByte('\n')
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+var (
+	labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+)
+
+func escapeLabel(s string) string { return labelEscaper.Replace(s) }
+func escapeHelp(s string) string  { return helpEscaper.Replace(s) }
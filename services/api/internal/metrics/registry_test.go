@@ -0,0 +1,72 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRegistry_WriteText(t *testing.T) {
+	t.Parallel()
+
+	reg := NewRegistry()
+	holds := NewHolds(reg)
+	holds.HoldCreated()
+	holds.HoldRejected("insufficient_capacity")
+	holds.HoldRejected("insufficient_capacity")
+	holds.HoldRejected("idempotency_conflict")
+	holds.HoldsExpired(3)
+
+	latency := reg.NewHistogramVec("test_latency_seconds", "Latency.", []float64{0.1, 1}, "route")
+	latency.With("/holds").ObserveDuration(50 * time.Millisecond)
+	latency.With("/holds").ObserveDuration(500 * time.Millisecond)
+	latency.With("/holds").ObserveDuration(2 * time.Second)
+
+	reg.NewCounterVec("test_escaped_total", "Help with a \\ backslash\nand a newline.", "value").With("a \"quoted\"\nvalue").Inc()
+	reg.NewGaugeFunc("test_gauge", "A gauge.", func() float64 { return 7 })
+
+	var out strings.Builder
+	if err := reg.WriteText(&out); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	want := `# HELP ticket_holds_created_total Holds created, not counting idempotent retries.
+# TYPE ticket_holds_created_total counter
+ticket_holds_created_total 1
+# HELP ticket_holds_rejected_total Hold attempts refused, by reason.
+# TYPE ticket_holds_rejected_total counter
+ticket_holds_rejected_total{reason="idempotency_conflict"} 1
+ticket_holds_rejected_total{reason="insufficient_capacity"} 2
+# HELP ticket_holds_expired_total Holds released by the expiry worker.
+# TYPE ticket_holds_expired_total counter
+ticket_holds_expired_total 3
+# HELP test_latency_seconds Latency.
+# TYPE test_latency_seconds histogram
+test_latency_seconds_bucket{route="/holds",le="0.1"} 1
+test_latency_seconds_bucket{route="/holds",le="1"} 2
+test_latency_seconds_bucket{route="/holds",le="+Inf"} 3
+test_latency_seconds_sum{route="/holds"} 2.55
+test_latency_seconds_count{route="/holds"} 3
+# HELP test_escaped_total Help with a \\ backslash\nand a newline.
+# TYPE test_escaped_total counter
+test_escaped_total{value="a \"quoted\"\nvalue"} 1
+# HELP test_gauge A gauge.
+# TYPE test_gauge gauge
+test_gauge 7
+`
+	if out.String() != want {
+		t.Fatalf("unexpected exposition:\n%s\nwant:\n%s", out.String(), want)
+	}
+}
+
+func TestRegistry_RejectsDuplicates(t *testing.T) {
+	t.Parallel()
+
+	reg := NewRegistry()
+	reg.NewCounterVec("dup_total", "First.")
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected a panic for a duplicate metric name")
+		}
+	}()
+	reg.NewCounterVec("dup_total", "Second.")
+}
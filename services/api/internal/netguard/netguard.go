@@ -0,0 +1,82 @@
+// Package netguard keeps outgoing requests to user-supplied URLs, such as
+// organizer webhooks, away from the API's own network: loopback, private,
+// link-local (including cloud metadata endpoints) and other special-purpose
+// addresses are refused.
+//
+// Hosts are checked twice: CheckHost when a URL is accepted, and Control when
+// a connection is dialed, after DNS resolution, so a name that resolves to an
+// internal address later is refused too.
+package netguard
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"strings"
+	"syscall"
+)
+
+// ErrNonPublicAddress reports a host or address outside the public internet.
+var ErrNonPublicAddress = errors.New("address is not public")
+
+// blocked lists the special-purpose ranges besides those netip classifies
+// (loopback, private, link-local, multicast and unspecified).
+var blocked = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),       // "this" network
+	netip.MustParsePrefix("100.64.0.0/10"),   // carrier-grade NAT
+	netip.MustParsePrefix("192.0.0.0/24"),    // IETF protocol assignments
+	netip.MustParsePrefix("192.0.2.0/24"),    // documentation
+	netip.MustParsePrefix("198.18.0.0/15"),   // benchmarking
+	netip.MustParsePrefix("198.51.100.0/24"), // documentation
+	netip.MustParsePrefix("203.0.113.0/24"),  // documentation
+	netip.MustParsePrefix("240.0.0.0/4"),     // reserved, and broadcast
+	netip.MustParsePrefix("64:ff9b::/96"),    // NAT64, can reach IPv4 ranges
+	netip.MustParsePrefix("2001:db8::/32"),   // documentation
+}
+
+// IsPublic reports whether addr is a routable public unicast address.
+func IsPublic(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsValid() || addr.IsLoopback() || addr.IsPrivate() || addr.IsUnspecified() ||
+		addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() || addr.IsInterfaceLocalMulticast() ||
+		addr.IsMulticast() {
+		return false
+	}
+	for _, p := range blocked {
+		if p.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// CheckHost refuses IP literals that are not public and names that always
+// point at the local machine. Other names are checked when they are dialed.
+func CheckHost(host string) error {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return ErrNonPublicAddress
+	}
+	if addr, err := netip.ParseAddr(strings.Trim(host, "[]")); err == nil && !IsPublic(addr) {
+		return ErrNonPublicAddress
+	}
+	return nil
+}
+
+// Control is a net.Dialer Control function that refuses connections to
+// addresses that are not public.
+func Control(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return err
+	}
+	if !IsPublic(addr) {
+		return fmt.Errorf("dial %s: %w", address, ErrNonPublicAddress)
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package netguard
+
+import (
+	"errors"
+	"net/netip"
+	"testing"
+)
+
+func TestIsPublic(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		addr string
+		want bool
+	}{
+		{addr: "93.184.216.34", want: true},
+		{addr: "2606:2800:220:1:248:1893:25c8:1946", want: true},
+		{addr: "127.0.0.1"},
+		{addr: "::1"},
+		{addr: "10.1.2.3"},
+		{addr: "172.16.0.1"},
+		{addr: "192.168.1.1"},
+		{addr: "169.254.169.254"},
+		{addr: "fe80::1"},
+		{addr: "fd00::1"},
+		{addr: "0.0.0.0"},
+		{addr: "100.64.0.1"},
+		{addr: "255.255.255.255"},
+		{addr: "224.0.0.1"},
+		{addr: "::ffff:127.0.0.1"},
+		{addr: "64:ff9b::a9fe:a9fe"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.addr, func(t *testing.T) {
+			t.Parallel()
+			if got := IsPublic(netip.MustParseAddr(tt.addr)); got != tt.want {
+				t.Fatalf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestCheckHost(t *testing.T) {
+	t.Parallel()
+
+	for host, want := range map[string]error{
+		"crm.example.com":    nil,
+		"93.184.216.34":      nil,
+		"localhost":          ErrNonPublicAddress,
+		"api.localhost.":     ErrNonPublicAddress,
+		"169.254.169.254":    ErrNonPublicAddress,
+		"[::1]":              ErrNonPublicAddress,
+		"[2606:2800:220::1]": nil,
+	} {
+		if err := CheckHost(host); err != want {
+			t.Fatalf("%s: expected %v, got %v", host, want, err)
+		}
+	}
+}
+
+func TestControl(t *testing.T) {
+	t.Parallel()
+
+	if err := Control("tcp4", "93.184.216.34:443", nil); err != nil {
+		t.Fatalf("expected a public address to be dialed, got %v", err)
+	}
+	for _, address := range []string{"127.0.0.1:8080", "[::1]:443", "169.254.169.254:80", "10.0.0.5:443"} {
+		if err := Control("tcp", address, nil); !errors.Is(err, ErrNonPublicAddress) {
+			t.Fatalf("%s: expected ErrNonPublicAddress, got %v", address, err)
+		}
+	}
+}
@@ -0,0 +1,26 @@
+package notify
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/cimillas/ultimate-ticket/services/api/internal/app"
+)
+
+// LogMailer writes emails to a logger instead of sending them. It lets login
+// codes reach a developer when SMTP is not configured; do not use it in
+// production, since message bodies may contain secrets.
+type LogMailer struct {
+	logger *slog.Logger
+}
+
+var _ app.Mailer = (*LogMailer)(nil)
+
+func NewLogMailer(logger *slog.Logger) *LogMailer {
+	return &LogMailer{logger: logger}
+}
+
+func (m *LogMailer) Send(ctx context.Context, msg app.MailMessage) error {
+	m.logger.InfoContext(ctx, "email", "to", msg.To, "subject", msg.Subject, "text", msg.Text)
+	return nil
+}
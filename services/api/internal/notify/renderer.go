@@ -0,0 +1,94 @@
+package notify
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"strings"
+	texttemplate "text/template"
+	"time"
+
+	"github.com/cimillas/ultimate-ticket/services/api/internal/app"
+	"github.com/cimillas/ultimate-ticket/services/api/internal/domain"
+)
+
+//go:embed templates/*.txt templates/*.html
+var templateFS embed.FS
+
+// dateLayout is used for event start times in emails.
+const dateLayout = "Mon, 02 Jan 2006 15:04 MST"
+
+var kinds = []domain.NotificationKind{
+	domain.NotificationOrderConfirmation,
+	domain.NotificationOrderCancellation,
+	domain.NotificationEventChanged,
+}
+
+// Renderer renders the embedded templates. Each kind has a <kind>.txt
+// template, which also defines the "subject" block, and a <kind>.html
+// template rendered with html/template so customer-controlled values are
+// escaped.
+type Renderer struct {
+	text map[domain.NotificationKind]*texttemplate.Template
+	html map[domain.NotificationKind]*htmltemplate.Template
+}
+
+var _ app.NotificationRenderer = (*Renderer)(nil)
+
+// NewRenderer parses the templates, formatting dates in loc (UTC if nil).
+func NewRenderer(loc *time.Location) (*Renderer, error) {
+	if loc == nil {
+		loc = time.UTC
+	}
+	date := func(t time.Time) string { return t.In(loc).Format(dateLayout) }
+
+	r := &Renderer{
+		text: make(map[domain.NotificationKind]*texttemplate.Template, len(kinds)),
+		html: make(map[domain.NotificationKind]*htmltemplate.Template, len(kinds)),
+	}
+	for _, kind := range kinds {
+		text, err := texttemplate.New(string(kind)+".txt").
+			Funcs(texttemplate.FuncMap{"date": date}).
+			ParseFS(templateFS, "templates/"+string(kind)+".txt")
+		if err != nil {
+			return nil, fmt.Errorf("parse %s text template: %w", kind, err)
+		}
+		if text.Lookup("subject") == nil {
+			return nil, fmt.Errorf("%s text template has no subject", kind)
+		}
+		html, err := htmltemplate.New(string(kind)+".html").
+			Funcs(htmltemplate.FuncMap{"date": date}).
+			ParseFS(templateFS, "templates/"+string(kind)+".html")
+		if err != nil {
+			return nil, fmt.Errorf("parse %s html template: %w", kind, err)
+		}
+		r.text[kind] = text
+		r.html[kind] = html
+	}
+	return r, nil
+}
+
+func (r *Renderer) Render(kind domain.NotificationKind, data app.NotificationData) (app.RenderedMessage, error) {
+	text, ok := r.text[kind]
+	if !ok {
+		return app.RenderedMessage{}, fmt.Errorf("unknown notification kind %q", kind)
+	}
+
+	var subject, textBody, htmlBody bytes.Buffer
+	if err := text.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return app.RenderedMessage{}, err
+	}
+	if err := text.Execute(&textBody, data); err != nil {
+		return app.RenderedMessage{}, err
+	}
+	if err := r.html[kind].Execute(&htmlBody, data); err != nil {
+		return app.RenderedMessage{}, err
+	}
+	return app.RenderedMessage{
+		// Subjects become a header line, so they must stay on one line.
+		Subject: strings.Join(strings.Fields(subject.String()), " "),
+		Text:    textBody.String(),
+		HTML:    htmlBody.String(),
+	}, nil
+}
@@ -0,0 +1,106 @@
+package notify
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cimillas/ultimate-ticket/services/api/internal/app"
+	"github.com/cimillas/ultimate-ticket/services/api/internal/domain"
+)
+
+func TestRenderer_Render(t *testing.T) {
+	t.Parallel()
+
+	r, err := NewRenderer(nil)
+	if err != nil {
+		t.Fatalf("new renderer: %v", err)
+	}
+	startsAt := time.Date(2025, 6, 1, 20, 0, 0, 0, time.UTC)
+	data := app.NotificationData{
+		OrderID:   "order-1",
+		EventName: "Rock & <Roll>",
+		StartsAt:  startsAt,
+		ZoneName:  "Floor",
+		Quantity:  2,
+	}
+
+	tests := []struct {
+		name        string
+		kind        domain.NotificationKind
+		data        func(app.NotificationData) app.NotificationData
+		wantSubject string
+		wantText    []string
+	}{
+		{
+			name:        "confirmation",
+			kind:        domain.NotificationOrderConfirmation,
+			wantSubject: "Your tickets for Rock & <Roll>",
+			wantText:    []string{"Tickets:  2", "Sun, 01 Jun 2025 20:00 UTC", "order-1"},
+		},
+		{
+			name:        "cancellation",
+			kind:        domain.NotificationOrderCancellation,
+			wantSubject: "Your order for Rock & <Roll> was cancelled",
+			wantText:    []string{"2 x Floor", "order-1"},
+		},
+		{
+			name: "event changed",
+			kind: domain.NotificationEventChanged,
+			data: func(d app.NotificationData) app.NotificationData {
+				d.PreviousEventName = d.EventName
+				d.PreviousStartsAt = startsAt.Add(-24 * time.Hour)
+				return d
+			},
+			wantSubject: "Update to Rock & <Roll>",
+			wantText:    []string{"Starts:  Sat, 31 May 2025 20:00 UTC -> Sun, 01 Jun 2025 20:00 UTC"},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			in := data
+			if tt.data != nil {
+				in = tt.data(in)
+			}
+			msg, err := r.Render(tt.kind, in)
+			if err != nil {
+				t.Fatalf("render: %v", err)
+			}
+			if msg.Subject != tt.wantSubject {
+				t.Fatalf("expected subject %q, got %q", tt.wantSubject, msg.Subject)
+			}
+			for _, want := range tt.wantText {
+				if !strings.Contains(msg.Text, want) {
+					t.Fatalf("expected text to contain %q, got:\n%s", want, msg.Text)
+				}
+			}
+			if !strings.Contains(msg.HTML, "Rock &amp; &lt;Roll&gt;") {
+				t.Fatalf("expected escaped event name in html, got:\n%s", msg.HTML)
+			}
+		})
+	}
+
+	t.Run("event changed omits unchanged fields", func(t *testing.T) {
+		t.Parallel()
+		in := data
+		in.PreviousEventName = "Old name"
+		in.PreviousStartsAt = startsAt
+		msg, err := r.Render(domain.NotificationEventChanged, in)
+		if err != nil {
+			t.Fatalf("render: %v", err)
+		}
+		if strings.Contains(msg.Text, "Starts:") || !strings.Contains(msg.Text, "Old name -> Rock & <Roll>") {
+			t.Fatalf("unexpected text:\n%s", msg.Text)
+		}
+	})
+
+	t.Run("unknown kind", func(t *testing.T) {
+		t.Parallel()
+		if _, err := r.Render("welcome", data); err == nil {
+			t.Fatalf("expected error for unknown kind")
+		}
+	})
+}
@@ -0,0 +1,250 @@
+package notify
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cimillas/ultimate-ticket/services/api/internal/app"
+	"github.com/cimillas/ultimate-ticket/services/api/internal/clock"
+)
+
+// fakeSMTP is a minimal in-process SMTP server that accepts every message.
+type fakeSMTP struct {
+	ln       net.Listener
+	received chan smtpEnvelope
+}
+
+type smtpEnvelope struct {
+	from string
+	to   []string
+	data string
+}
+
+func newFakeSMTP(t *testing.T) *fakeSMTP {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	s := &fakeSMTP{ln: ln, received: make(chan smtpEnvelope, 4)}
+	go s.serve()
+	t.Cleanup(func() { _ = ln.Close() })
+	return s
+}
+
+func (s *fakeSMTP) serve() {
+	for {
+		conn, err := s.ln.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *fakeSMTP) handle(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(line string) { _, _ = io.WriteString(conn, line+"\r\n") }
+
+	reply("220 fake ESMTP")
+	var env smtpEnvelope
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		cmd := strings.ToUpper(strings.TrimSpace(line))
+		switch {
+		case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+			reply("250 fake")
+		case strings.HasPrefix(cmd, "MAIL FROM:"):
+			env.from = strings.Trim(strings.TrimSpace(line)[len("MAIL FROM:"):], "<>")
+			reply("250 OK")
+		case strings.HasPrefix(cmd, "RCPT TO:"):
+			env.to = append(env.to, strings.Trim(strings.TrimSpace(line)[len("RCPT TO:"):], "<>"))
+			reply("250 OK")
+		case cmd == "DATA":
+			reply("354 end with <CRLF>.<CRLF>")
+			var data strings.Builder
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if l == ".\r\n" {
+					break
+				}
+				data.WriteString(strings.TrimPrefix(l, "."))
+			}
+			env.data = data.String()
+			s.received <- env
+			env = smtpEnvelope{}
+			reply("250 queued")
+		case cmd == "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("250 OK")
+		}
+	}
+}
+
+func TestSMTPMailer_Send(t *testing.T) {
+	t.Parallel()
+
+	server := newFakeSMTP(t)
+	mailer, err := NewSMTPMailer(SMTPConfig{
+		Addr: server.ln.Addr().String(),
+		From: "Tickets <tickets@example.com>",
+	}, clock.NewFixed(time.Date(2025, 1, 4, 8, 0, 0, 0, time.UTC)))
+	if err != nil {
+		t.Fatalf("new mailer: %v", err)
+	}
+
+	err = mailer.Send(context.Background(), app.MailMessage{
+		To:      "Fan <fan@example.com>",
+		Subject: "Your tickets for Café Tacuba",
+		Text:    "Thanks for your order!",
+		HTML:    "<p>Thanks for your order!</p>",
+	})
+	if err != nil {
+		t.Fatalf("send: %v", err)
+	}
+
+	var env smtpEnvelope
+	select {
+	case env = <-server.received:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timed out waiting for message")
+	}
+	if env.from != "tickets@example.com" || len(env.to) != 1 || env.to[0] != "fan@example.com" {
+		t.Fatalf("unexpected envelope from=%q to=%v", env.from, env.to)
+	}
+
+	msg, err := mail.ReadMessage(strings.NewReader(env.data))
+	if err != nil {
+		t.Fatalf("parse message: %v", err)
+	}
+	if got := msg.Header.Get("To"); got != `"Fan" <fan@example.com>` {
+		t.Fatalf("unexpected To header %q", got)
+	}
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	if err != nil || subject != "Your tickets for Café Tacuba" {
+		t.Fatalf("unexpected subject %q (%v)", subject, err)
+	}
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/alternative" {
+		t.Fatalf("unexpected content type %q (%v)", msg.Header.Get("Content-Type"), err)
+	}
+
+	parts := multipart.NewReader(msg.Body, params["boundary"])
+	var types []string
+	for {
+		part, err := parts.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("read part: %v", err)
+		}
+		body, _ := io.ReadAll(part)
+		contentType, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		types = append(types, contentType)
+		if !strings.Contains(string(body), "Thanks for your order!") {
+			t.Fatalf("unexpected %s body %q", contentType, body)
+		}
+	}
+	if strings.Join(types, ",") != "text/plain,text/html" {
+		t.Fatalf("expected text and html parts, got %v", types)
+	}
+}
+
+// silentSMTP accepts connections and never answers, like a stuck server.
+func silentSMTP(t *testing.T) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	var conns []net.Conn
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			conns = append(conns, conn)
+		}
+	}()
+	t.Cleanup(func() {
+		_ = ln.Close()
+		<-done
+		for _, conn := range conns {
+			_ = conn.Close()
+		}
+	})
+	return ln.Addr().String()
+}
+
+func TestSMTPMailer_SendGivesUpOnStuckServer(t *testing.T) {
+	t.Parallel()
+
+	msg := app.MailMessage{To: "fan@example.com", Subject: "Hi", Text: "Hi", HTML: "<p>Hi</p>"}
+
+	t.Run("timeout", func(t *testing.T) {
+		t.Parallel()
+		mailer, err := NewSMTPMailer(SMTPConfig{
+			Addr:    silentSMTP(t),
+			From:    "tickets@example.com",
+			Timeout: 100 * time.Millisecond,
+		}, clock.NewFixed(time.Now()))
+		if err != nil {
+			t.Fatalf("new mailer: %v", err)
+		}
+		start := time.Now()
+		if err := mailer.Send(context.Background(), msg); err == nil {
+			t.Fatalf("expected an error from a server that never answers")
+		}
+		if elapsed := time.Since(start); elapsed > 5*time.Second {
+			t.Fatalf("expected the timeout to end the delivery, took %v", elapsed)
+		}
+	})
+
+	t.Run("context cancelled", func(t *testing.T) {
+		t.Parallel()
+		mailer, err := NewSMTPMailer(SMTPConfig{
+			Addr: silentSMTP(t),
+			From: "tickets@example.com",
+		}, clock.NewFixed(time.Now()))
+		if err != nil {
+			t.Fatalf("new mailer: %v", err)
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(100*time.Millisecond, cancel)
+		if err := mailer.Send(ctx, msg); err != context.Canceled {
+			t.Fatalf("expected context.Canceled, got %v", err)
+		}
+	})
+}
+
+func TestNewSMTPMailer_ValidatesConfig(t *testing.T) {
+	t.Parallel()
+
+	if _, err := NewSMTPMailer(SMTPConfig{Addr: "localhost", From: "tickets@example.com"}, clock.NewFixed(time.Now())); err == nil {
+		t.Fatalf("expected error for address without port")
+	}
+	if _, err := NewSMTPMailer(SMTPConfig{Addr: "localhost:25", From: "not an address"}, clock.NewFixed(time.Now())); err == nil {
+		t.Fatalf("expected error for invalid from address")
+	}
+}
@@ -0,0 +1,31 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+
+	"github.com/cimillas/ultimate-ticket/services/api/internal/app"
+	"github.com/cimillas/ultimate-ticket/services/api/internal/domain"
+)
+
+// Fanout publishes each event to several publishers. If any of them fails the
+// event is retried for all, so every publisher must tolerate redelivery.
+type Fanout struct {
+	publishers []app.OutboxPublisher
+}
+
+var _ app.OutboxPublisher = (*Fanout)(nil)
+
+func NewFanout(publishers ...app.OutboxPublisher) *Fanout {
+	return &Fanout{publishers: publishers}
+}
+
+func (f *Fanout) Publish(ctx context.Context, event domain.OutboxEvent) error {
+	var errs []error
+	for _, p := range f.publishers {
+		if err := p.Publish(ctx, event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,32 @@
+// Package outbox contains publishers for the transactional outbox relay.
+package outbox
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/cimillas/ultimate-ticket/services/api/internal/app"
+	"github.com/cimillas/ultimate-ticket/services/api/internal/domain"
+)
+
+// LogPublisher writes each event to a logger. It is the default publisher
+// until a message broker is configured.
+type LogPublisher struct {
+	logger *slog.Logger
+}
+
+var _ app.OutboxPublisher = (*LogPublisher)(nil)
+
+func NewLogPublisher(logger *slog.Logger) *LogPublisher {
+	return &LogPublisher{logger: logger}
+}
+
+func (p *LogPublisher) Publish(ctx context.Context, event domain.OutboxEvent) error {
+	p.logger.InfoContext(ctx, "outbox event",
+		"id", event.ID,
+		"type", event.Type,
+		"aggregate_type", event.AggregateType,
+		"aggregate_id", event.AggregateID,
+		"payload", string(event.Payload))
+	return nil
+}
@@ -0,0 +1,153 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/cimillas/ultimate-ticket/services/api/internal/app"
+	"github.com/cimillas/ultimate-ticket/services/api/internal/domain"
+)
+
+// Outcome scripts the result of the next authorization on a Fake provider.
+type Outcome string
+
+const (
+	OutcomeApprove Outcome = "approve"
+	OutcomeDecline Outcome = "decline"
+	OutcomeTimeout Outcome = "timeout"
+)
+
+// ErrInvalidState is returned when an operation does not apply to the
+// authorization's current state (e.g. capturing a voided authorization).
+var ErrInvalidState = errors.New("invalid payment state")
+
+type authState string
+
+const (
+	stateAuthorized authState = "authorized"
+	stateCaptured   authState = "captured"
+	stateVoided     authState = "voided"
+	stateRefunded   authState = "refunded"
+)
+
+// Call records an operation received by the Fake provider.
+type Call struct {
+	Op             string
+	Reference      string
+	OrderID        string
+	IdempotencyKey string
+}
+
+// Fake is a deterministic in-process PaymentProvider. Authorizations consume
+// scripted outcomes in order and approve once the script is exhausted.
+// Operations are deduplicated by idempotency key like a real processor.
+type Fake struct {
+	mu      sync.Mutex
+	script  []Outcome
+	seq     int
+	auths   map[string]authState // reference -> state
+	byKey   map[string]string    // authorize idempotency key -> reference
+	results map[string]error     // operation idempotency key -> result
+	calls   []Call
+}
+
+var _ app.PaymentProvider = (*Fake)(nil)
+
+// NewFake returns a Fake that answers the next authorizations with outcomes.
+func NewFake(outcomes ...Outcome) *Fake {
+	return &Fake{
+		script:  append([]Outcome(nil), outcomes...),
+		auths:   make(map[string]authState),
+		byKey:   make(map[string]string),
+		results: make(map[string]error),
+	}
+}
+
+// Script appends outcomes for upcoming authorizations.
+func (f *Fake) Script(outcomes ...Outcome) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.script = append(f.script, outcomes...)
+}
+
+// Calls returns the operations received so far.
+func (f *Fake) Calls() []Call {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]Call(nil), f.calls...)
+}
+
+// State returns the state of an authorization, or "" if it is unknown.
+func (f *Fake) State(reference string) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return string(f.auths[reference])
+}
+
+func (f *Fake) Authorize(_ context.Context, req app.PaymentRequest) (app.PaymentAuthorization, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = append(f.calls, Call{Op: "authorize", OrderID: req.OrderID, IdempotencyKey: req.IdempotencyKey})
+
+	if ref, ok := f.byKey[req.IdempotencyKey]; ok {
+		return app.PaymentAuthorization{Reference: ref}, nil
+	}
+	if err, ok := f.results["authorize:"+req.IdempotencyKey]; ok {
+		return app.PaymentAuthorization{}, err
+	}
+
+	outcome := OutcomeApprove
+	if len(f.script) > 0 {
+		outcome = f.script[0]
+		f.script = f.script[1:]
+	}
+
+	switch outcome {
+	case OutcomeDecline:
+		f.results["authorize:"+req.IdempotencyKey] = domain.ErrPaymentDeclined
+		return app.PaymentAuthorization{}, domain.ErrPaymentDeclined
+	case OutcomeTimeout:
+		// Timeouts are not remembered so a retry can succeed.
+		return app.PaymentAuthorization{}, domain.ErrPaymentUnavailable
+	}
+
+	f.seq++
+	ref := fmt.Sprintf("fakepay_auth_%d", f.seq)
+	f.auths[ref] = stateAuthorized
+	f.byKey[req.IdempotencyKey] = ref
+	return app.PaymentAuthorization{Reference: ref}, nil
+}
+
+func (f *Fake) Capture(_ context.Context, op app.PaymentOperation) error {
+	return f.apply("capture", op, stateAuthorized, stateCaptured)
+}
+
+func (f *Fake) Void(_ context.Context, op app.PaymentOperation) error {
+	return f.apply("void", op, stateAuthorized, stateVoided)
+}
+
+func (f *Fake) Refund(_ context.Context, op app.PaymentOperation) error {
+	return f.apply("refund", op, stateCaptured, stateRefunded)
+}
+
+func (f *Fake) apply(name string, op app.PaymentOperation, from, to authState) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = append(f.calls, Call{Op: name, Reference: op.Reference, IdempotencyKey: op.IdempotencyKey})
+
+	key := name + ":" + op.IdempotencyKey
+	if err, ok := f.results[key]; ok {
+		return err
+	}
+
+	var err error
+	if state, ok := f.auths[op.Reference]; !ok || state != from {
+		err = ErrInvalidState
+	} else {
+		f.auths[op.Reference] = to
+	}
+	f.results[key] = err
+	return err
+}
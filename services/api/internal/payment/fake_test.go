@@ -0,0 +1,68 @@
+package payment
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cimillas/ultimate-ticket/services/api/internal/app"
+	"github.com/cimillas/ultimate-ticket/services/api/internal/domain"
+)
+
+func TestFake_ScriptedOutcomes(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	f := NewFake(OutcomeDecline, OutcomeTimeout)
+
+	if _, err := f.Authorize(ctx, app.PaymentRequest{OrderID: "o1", IdempotencyKey: "k1"}); err != domain.ErrPaymentDeclined {
+		t.Fatalf("expected ErrPaymentDeclined, got %v", err)
+	}
+	if _, err := f.Authorize(ctx, app.PaymentRequest{OrderID: "o1", IdempotencyKey: "k1"}); err != domain.ErrPaymentDeclined {
+		t.Fatalf("expected decline to be replayed for same key, got %v", err)
+	}
+	if _, err := f.Authorize(ctx, app.PaymentRequest{OrderID: "o2", IdempotencyKey: "k2"}); err != domain.ErrPaymentUnavailable {
+		t.Fatalf("expected ErrPaymentUnavailable, got %v", err)
+	}
+
+	auth, err := f.Authorize(ctx, app.PaymentRequest{OrderID: "o2", IdempotencyKey: "k2"})
+	if err != nil {
+		t.Fatalf("expected retry after timeout to approve, got %v", err)
+	}
+	if auth.Reference == "" {
+		t.Fatalf("expected reference")
+	}
+}
+
+func TestFake_IdempotentOperations(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	f := NewFake()
+
+	auth, err := f.Authorize(ctx, app.PaymentRequest{OrderID: "o1", IdempotencyKey: "k1"})
+	if err != nil {
+		t.Fatalf("authorize: %v", err)
+	}
+	again, err := f.Authorize(ctx, app.PaymentRequest{OrderID: "o1", IdempotencyKey: "k1"})
+	if err != nil || again.Reference != auth.Reference {
+		t.Fatalf("expected same authorization, got %+v (%v)", again, err)
+	}
+
+	capture := app.PaymentOperation{Reference: auth.Reference, IdempotencyKey: "k1:capture"}
+	if err := f.Capture(ctx, capture); err != nil {
+		t.Fatalf("capture: %v", err)
+	}
+	if err := f.Capture(ctx, capture); err != nil {
+		t.Fatalf("repeat capture: %v", err)
+	}
+	if err := f.Void(ctx, app.PaymentOperation{Reference: auth.Reference, IdempotencyKey: "k1:void"}); err != ErrInvalidState {
+		t.Fatalf("expected ErrInvalidState voiding a capture, got %v", err)
+	}
+	if err := f.Refund(ctx, app.PaymentOperation{Reference: auth.Reference, IdempotencyKey: "k1:refund"}); err != nil {
+		t.Fatalf("refund: %v", err)
+	}
+	if got := f.State(auth.Reference); got != string(stateRefunded) {
+		t.Fatalf("expected refunded, got %q", got)
+	}
+	if len(f.Calls()) != 6 {
+		t.Fatalf("expected 6 calls, got %d", len(f.Calls()))
+	}
+}
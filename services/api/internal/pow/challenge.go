@@ -0,0 +1,148 @@
+// Package pow issues and verifies proof-of-work challenges for anonymous
+// hold creation.
+//
+// A challenge has the form "<payload>.<mac>", both base64url encoded without
+// padding, like admission tokens. The payload is
+// "<id>.<difficulty>.<expiry unix seconds>" and the mac is its HMAC-SHA256, so
+// challenges need no storage until they are redeemed. A solution is any string
+// s for which SHA-256("<challenge>:<s>") starts with at least difficulty zero
+// bits; finding one takes about 2^difficulty hashes, checking it takes one.
+package pow
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/bits"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cimillas/ultimate-ticket/services/api/internal/clock"
+)
+
+var (
+	ErrInvalidChallenge = errors.New("invalid proof-of-work challenge")
+	ErrChallengeExpired = errors.New("proof-of-work challenge expired")
+	ErrInvalidSolution  = errors.New("proof-of-work solution does not meet the difficulty")
+)
+
+// DefaultTTL is how long a challenge may be solved and redeemed.
+const DefaultTTL = 2 * time.Minute
+
+var encoding = base64.RawURLEncoding
+
+// Challenge is an issued puzzle.
+type Challenge struct {
+	ID         string
+	Token      string
+	Difficulty int
+	ExpiresAt  time.Time
+}
+
+// Issuer issues and verifies challenges with a shared secret.
+type Issuer struct {
+	secret []byte
+	clock  clock.Clock
+	ttl    time.Duration
+}
+
+func NewIssuer(secret []byte, clk clock.Clock) *Issuer {
+	return &Issuer{secret: secret, clock: clk, ttl: DefaultTTL}
+}
+
+// Issue returns a new challenge of the given difficulty in bits.
+func (i *Issuer) Issue(difficulty int) (Challenge, error) {
+	raw := make([]byte, 16)
+	if _, err := rand.Read(raw); err != nil {
+		return Challenge{}, fmt.Errorf("generate challenge id: %w", err)
+	}
+	c := Challenge{
+		ID:         hex.EncodeToString(raw),
+		Difficulty: difficulty,
+		ExpiresAt:  i.clock.Now().Add(i.ttl).Truncate(time.Second),
+	}
+	payload := c.ID + "." + strconv.Itoa(difficulty) + "." + strconv.FormatInt(c.ExpiresAt.Unix(), 10)
+	c.Token = encoding.EncodeToString([]byte(payload)) + "." + encoding.EncodeToString(i.mac(payload))
+	return c, nil
+}
+
+// Verify checks that token was issued by this issuer, has not expired, and
+// that solution solves it. It does not detect replays; callers redeem the
+// returned challenge's ID once.
+func (i *Issuer) Verify(token, solution string) (Challenge, error) {
+	encPayload, encMAC, ok := strings.Cut(token, ".")
+	if !ok {
+		return Challenge{}, ErrInvalidChallenge
+	}
+	rawPayload, err := encoding.DecodeString(encPayload)
+	if err != nil {
+		return Challenge{}, ErrInvalidChallenge
+	}
+	got, err := encoding.DecodeString(encMAC)
+	if err != nil {
+		return Challenge{}, ErrInvalidChallenge
+	}
+	payload := string(rawPayload)
+	if !hmac.Equal(got, i.mac(payload)) {
+		return Challenge{}, ErrInvalidChallenge
+	}
+
+	parts := strings.Split(payload, ".")
+	if len(parts) != 3 {
+		return Challenge{}, ErrInvalidChallenge
+	}
+	difficulty, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return Challenge{}, ErrInvalidChallenge
+	}
+	expiry, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil {
+		return Challenge{}, ErrInvalidChallenge
+	}
+	c := Challenge{ID: parts[0], Token: token, Difficulty: difficulty, ExpiresAt: time.Unix(expiry, 0).UTC()}
+	if !i.clock.Now().Before(c.ExpiresAt) {
+		return Challenge{}, ErrChallengeExpired
+	}
+	if leadingZeroBits(hash(token, solution)) < difficulty {
+		return Challenge{}, ErrInvalidSolution
+	}
+	return c, nil
+}
+
+// Solve finds a solution by brute force. Clients do the same work; the API
+// only uses it in tests.
+func Solve(token string, difficulty int) string {
+	for n := 0; ; n++ {
+		solution := strconv.Itoa(n)
+		if leadingZeroBits(hash(token, solution)) >= difficulty {
+			return solution
+		}
+	}
+}
+
+func (i *Issuer) mac(payload string) []byte {
+	h := hmac.New(sha256.New, i.secret)
+	h.Write([]byte(payload))
+	return h.Sum(nil)
+}
+
+func hash(token, solution string) []byte {
+	sum := sha256.Sum256([]byte(token + ":" + solution))
+	return sum[:]
+}
+
+func leadingZeroBits(sum []byte) int {
+	n := 0
+	for _, b := range sum {
+		if b != 0 {
+			return n + bits.LeadingZeros8(b)
+		}
+		n += 8
+	}
+	return n
+}
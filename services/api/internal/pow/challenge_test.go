@@ -0,0 +1,123 @@
+package pow
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cimillas/ultimate-ticket/services/api/internal/clock"
+)
+
+func TestIssuer(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, 1, 7, 12, 0, 0, 0, time.UTC)
+	issuer := NewIssuer([]byte("pow_test"), clock.NewFixed(now))
+	valid, err := issuer.Issue(8)
+	if err != nil {
+		t.Fatalf("issue: %v", err)
+	}
+	solution := Solve(valid.Token, 8)
+	payload, _, _ := strings.Cut(valid.Token, ".")
+	other, _ := NewIssuer([]byte("other"), clock.NewFixed(now)).Issue(8)
+	easy, _ := issuer.Issue(0)
+
+	var wrong string
+	for n := 0; ; n++ {
+		wrong = strings.Repeat("x", n)
+		if leadingZeroBits(hash(valid.Token, wrong)) < 8 {
+			break
+		}
+	}
+
+	tests := []struct {
+		name     string
+		token    string
+		solution string
+		want     error
+	}{
+		{name: "valid", token: valid.Token, solution: solution},
+		{name: "wrong solution", token: valid.Token, solution: wrong, want: ErrInvalidSolution},
+		{name: "expired", token: valid.Token, solution: solution, want: ErrChallengeExpired},
+		{name: "wrong secret", token: other.Token, solution: Solve(other.Token, 8), want: ErrInvalidChallenge},
+		{name: "tampered difficulty", token: easy.Token[:strings.Index(easy.Token, ".")] + valid.Token[len(payload):], solution: "0", want: ErrInvalidChallenge},
+		{name: "malformed", token: "garbage", want: ErrInvalidChallenge},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			verifier := issuer
+			if tt.want == ErrChallengeExpired {
+				verifier = NewIssuer([]byte("pow_test"), clock.NewFixed(now.Add(DefaultTTL)))
+			}
+			got, err := verifier.Verify(tt.token, tt.solution)
+			if err != tt.want {
+				t.Fatalf("expected %v, got %v", tt.want, err)
+			}
+			if err == nil && (got.ID != valid.ID || got.Difficulty != 8 || !got.ExpiresAt.Equal(valid.ExpiresAt)) {
+				t.Fatalf("expected the issued challenge, got %+v", got)
+			}
+		})
+	}
+}
+
+func TestTuner_Difficulty(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, 1, 7, 12, 0, 0, 0, time.UTC)
+	tuner := NewTuner(10, 14, 5)
+	if got := tuner.Difficulty(now); got != 10 {
+		t.Fatalf("idle: expected base difficulty 10, got %d", got)
+	}
+
+	// 200 attempts over the window: 20/s is four times the threshold.
+	for i := 0; i < 200; i++ {
+		tuner.Observe(now.Add(-time.Duration(i%10) * time.Second))
+	}
+	if got := tuner.Difficulty(now); got != 12 {
+		t.Fatalf("busy: expected difficulty 12, got %d", got)
+	}
+
+	for i := 0; i < 2000; i++ {
+		tuner.Observe(now)
+	}
+	if got := tuner.Difficulty(now); got != 14 {
+		t.Fatalf("overloaded: expected max difficulty 14, got %d", got)
+	}
+
+	if got := tuner.Difficulty(now.Add(loadWindow)); got != 10 {
+		t.Fatalf("after the window: expected base difficulty 10, got %d", got)
+	}
+}
+
+func TestMemoryReplayStore(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	now := time.Date(2025, 1, 7, 12, 0, 0, 0, time.UTC)
+	store := NewMemoryReplayStore()
+	expiresAt := now.Add(DefaultTTL)
+	hold := Hold{EventID: "e1", ZoneID: "z1", IdempotencyKey: "key-1"}
+	otherZone := Hold{EventID: "e1", ZoneID: "z2", IdempotencyKey: "key-1"}
+
+	if ok, _ := store.Redeem(ctx, "c1", hold, expiresAt, now); !ok {
+		t.Fatalf("expected the first redemption to succeed")
+	}
+	if ok, _ := store.Redeem(ctx, "c1", hold, expiresAt, now); !ok {
+		t.Fatalf("expected a retry of the same hold to succeed")
+	}
+	if ok, _ := store.Redeem(ctx, "c1", Hold{EventID: "e1", ZoneID: "z1", IdempotencyKey: "key-2"}, expiresAt, now); ok {
+		t.Fatalf("expected a replay on another hold to fail")
+	}
+	if ok, _ := store.Redeem(ctx, "c1", otherZone, expiresAt, now); ok {
+		t.Fatalf("expected a replay in another zone with the same key to fail")
+	}
+
+	store.Redeem(ctx, "c2", hold, expiresAt, expiresAt.Add(time.Minute))
+	if _, ok := store.redeemed["c1"]; ok {
+		t.Fatalf("expected the expired redemption to be swept")
+	}
+}
@@ -0,0 +1,60 @@
+package pow
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// sweepInterval is how often the memory store forgets expired challenges.
+const sweepInterval = time.Minute
+
+// MemoryReplayStore remembers redeemed challenges in process. Each API
+// instance then rejects only its own replays; use a shared store across
+// instances.
+type MemoryReplayStore struct {
+	mu        sync.Mutex
+	redeemed  map[string]redemption
+	lastSweep time.Time
+}
+
+// Hold identifies the hold a challenge is spent on, the way holds are made
+// idempotent: by event, zone and idempotency key.
+type Hold struct {
+	EventID        string
+	ZoneID         string
+	IdempotencyKey string
+}
+
+type redemption struct {
+	hold      Hold
+	expiresAt time.Time
+}
+
+func NewMemoryReplayStore() *MemoryReplayStore {
+	return &MemoryReplayStore{redeemed: make(map[string]redemption)}
+}
+
+// Redeem records the challenge as spent on hold. It reports false if the
+// challenge was already spent on another hold, including one with the same
+// idempotency key in another zone; retrying the same hold may present the
+// same solution.
+func (s *MemoryReplayStore) Redeem(_ context.Context, challengeID string, hold Hold, expiresAt, now time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if now.Sub(s.lastSweep) >= sweepInterval {
+		for id, r := range s.redeemed {
+			if !r.expiresAt.After(now) {
+				delete(s.redeemed, id)
+			}
+		}
+		s.lastSweep = now
+	}
+
+	if r, ok := s.redeemed[challengeID]; ok {
+		return r.hold == hold, nil
+	}
+	s.redeemed[challengeID] = redemption{hold: hold, expiresAt: expiresAt}
+	return true, nil
+}
@@ -0,0 +1,68 @@
+package pow
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// loadWindow is how far back the tuner counts hold attempts.
+const loadWindow = 10 * time.Second
+
+// Tuner picks a challenge difficulty from the recent rate of hold attempts.
+// Up to threshold attempts per second it returns base; every doubling of the
+// rate above that adds one bit, doubling the work per hold, up to max.
+type Tuner struct {
+	base      int
+	max       int
+	threshold float64
+
+	mu    sync.Mutex
+	slots [int(loadWindow / time.Second)]loadSlot
+}
+
+type loadSlot struct {
+	second int64
+	count  int
+}
+
+func NewTuner(base, max int, threshold float64) *Tuner {
+	return &Tuner{base: base, max: max, threshold: threshold}
+}
+
+// Observe records one hold attempt.
+func (t *Tuner) Observe(now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	sec := now.Unix()
+	slot := &t.slots[sec%int64(len(t.slots))]
+	if slot.second != sec {
+		*slot = loadSlot{second: sec}
+	}
+	slot.count++
+}
+
+// Rate returns hold attempts per second over the last loadWindow.
+func (t *Tuner) Rate(now time.Time) float64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	sec := now.Unix()
+	total := 0
+	for _, slot := range t.slots {
+		if sec-slot.second < int64(len(t.slots)) && slot.second <= sec {
+			total += slot.count
+		}
+	}
+	return float64(total) / loadWindow.Seconds()
+}
+
+// Difficulty returns the number of leading zero bits to ask for now.
+func (t *Tuner) Difficulty(now time.Time) int {
+	rate := t.Rate(now)
+	if t.threshold <= 0 || rate <= t.threshold {
+		return t.base
+	}
+	return min(t.max, t.base+int(math.Ceil(math.Log2(rate/t.threshold))))
+}
@@ -5,38 +5,49 @@ import (
 	"fmt"
 
 	"github.com/cimillas/ultimate-ticket/services/api/internal/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type AdminRepository struct {
 	pool *pgxpool.Pool
+	cfg  repositoryConfig
 }
 
-func NewAdminRepository(pool *pgxpool.Pool) *AdminRepository {
-	return &AdminRepository{pool: pool}
+func NewAdminRepository(pool *pgxpool.Pool, opts ...RepositoryOption) *AdminRepository {
+	return &AdminRepository{pool: pool, cfg: newRepositoryConfig(opts)}
 }
 
 func (r *AdminRepository) CreateEvent(ctx context.Context, event domain.Event) error {
 	const stmt = `
-INSERT INTO events (id, name, starts_at)
-VALUES ($1, $2, $3)`
-	_, err := r.pool.Exec(ctx, stmt, event.ID, event.Name, event.StartsAt)
+INSERT INTO events (id, organizer_id, name, starts_at, waiting_room_enabled, admit_per_minute, purchase_limit)
+VALUES ($1, $2, $3, $4, $5, $6, $7)`
+	_, err := r.exec(ctx, stmt, event.ID, event.OrganizerID, event.Name, event.StartsAt,
+		event.WaitingRoom.Enabled, event.WaitingRoom.AdmitPerMinute, event.PurchaseLimit)
 	if err != nil {
 		if isInvalidUUID(err) {
 			return domain.ErrInvalidID
 		}
+		if isForeignKeyViolation(err) {
+			return domain.ErrOrganizerNotFound
+		}
 		return fmt.Errorf("create event: %w", err)
 	}
 	return nil
 }
 
-func (r *AdminRepository) ListEvents(ctx context.Context) ([]domain.Event, error) {
+func (r *AdminRepository) ListEvents(ctx context.Context, organizerID string) ([]domain.Event, error) {
 	const query = `
-SELECT id, name, starts_at
+SELECT id, organizer_id, name, starts_at, waiting_room_enabled, admit_per_minute, purchase_limit
 FROM events
+WHERE organizer_id = $1
 ORDER BY created_at ASC`
-	rows, err := r.pool.Query(ctx, query)
+	rows, err := r.pool.Query(ctx, query, organizerID)
 	if err != nil {
+		if isInvalidUUID(err) {
+			return nil, domain.ErrInvalidID
+		}
 		return nil, fmt.Errorf("list events: %w", err)
 	}
 	defer rows.Close()
@@ -44,7 +55,8 @@ ORDER BY created_at ASC`
 	var events []domain.Event
 	for rows.Next() {
 		var event domain.Event
-		if err := rows.Scan(&event.ID, &event.Name, &event.StartsAt); err != nil {
+		if err := rows.Scan(&event.ID, &event.OrganizerID, &event.Name, &event.StartsAt,
+			&event.WaitingRoom.Enabled, &event.WaitingRoom.AdmitPerMinute, &event.PurchaseLimit); err != nil {
 			return nil, fmt.Errorf("scan event: %w", err)
 		}
 		events = append(events, event)
@@ -55,11 +67,68 @@ ORDER BY created_at ASC`
 	return events, nil
 }
 
-func (r *AdminRepository) CreateZone(ctx context.Context, zone domain.Zone) error {
+func (r *AdminRepository) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return withTx(ctx, r.pool, r.cfg, fn)
+}
+
+func (r *AdminRepository) GetEventForUpdate(ctx context.Context, organizerID, eventID string) (domain.Event, error) {
+	const query = `
+SELECT id, organizer_id, name, starts_at, waiting_room_enabled, admit_per_minute, purchase_limit
+FROM events
+WHERE id = $1 AND organizer_id = $2
+FOR UPDATE`
+
+	var event domain.Event
+	if err := r.queryRow(ctx, query, eventID, organizerID).Scan(&event.ID, &event.OrganizerID, &event.Name, &event.StartsAt,
+		&event.WaitingRoom.Enabled, &event.WaitingRoom.AdmitPerMinute, &event.PurchaseLimit); err != nil {
+		if isInvalidUUID(err) {
+			return domain.Event{}, domain.ErrInvalidID
+		}
+		if err == pgx.ErrNoRows {
+			return domain.Event{}, domain.ErrEventNotFound
+		}
+		return domain.Event{}, fmt.Errorf("get event: %w", err)
+	}
+	return event, nil
+}
+
+func (r *AdminRepository) UpdateEvent(ctx context.Context, event domain.Event) error {
+	const stmt = `
+UPDATE events
+SET name = $3, starts_at = $4, waiting_room_enabled = $5, admit_per_minute = $6, purchase_limit = $7, updated_at = NOW()
+WHERE id = $1 AND organizer_id = $2`
+
+	tag, err := r.exec(ctx, stmt, event.ID, event.OrganizerID, event.Name, event.StartsAt,
+		event.WaitingRoom.Enabled, event.WaitingRoom.AdmitPerMinute, event.PurchaseLimit)
+	if err != nil {
+		if isInvalidUUID(err) {
+			return domain.ErrInvalidID
+		}
+		return fmt.Errorf("update event: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrEventNotFound
+	}
+	return nil
+}
+
+func (r *AdminRepository) AppendOutboxEvent(ctx context.Context, event domain.OutboxEvent) error {
+	return appendOutboxEvent(ctx, r.exec, event)
+}
+
+func (r *AdminRepository) AppendAuditEntry(ctx context.Context, entry domain.AuditEntry) error {
+	return appendAuditEntry(ctx, r.exec, entry)
+}
+
+// CreateZone only inserts when the event belongs to the organizer.
+func (r *AdminRepository) CreateZone(ctx context.Context, organizerID string, zone domain.Zone) error {
 	const stmt = `
-INSERT INTO zones (id, event_id, name, capacity)
-VALUES ($1, $2, $3, $4)`
-	_, err := r.pool.Exec(ctx, stmt, zone.ID, zone.EventID, zone.Name, zone.Capacity)
+INSERT INTO zones (id, event_id, name, capacity, min_quantity, max_quantity, quantity_step, purchase_limit)
+SELECT $1, e.id, $3, $4, $6, $7, $8, $9
+FROM events e
+WHERE e.id = $2 AND e.organizer_id = $5`
+	tag, err := r.exec(ctx, stmt, zone.ID, zone.EventID, zone.Name, zone.Capacity, organizerID,
+		zone.MinQuantity, zone.MaxQuantity, zone.QuantityStep, zone.PurchaseLimit)
 	if err != nil {
 		if isInvalidUUID(err) {
 			return domain.ErrInvalidID
@@ -72,13 +141,16 @@ VALUES ($1, $2, $3, $4)`
 		}
 		return fmt.Errorf("create zone: %w", err)
 	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrEventNotFound
+	}
 	return nil
 }
 
-func (r *AdminRepository) ListZonesByEvent(ctx context.Context, eventID string) ([]domain.Zone, error) {
-	const existsQuery = `SELECT EXISTS (SELECT 1 FROM events WHERE id = $1)`
+func (r *AdminRepository) ListZonesByEvent(ctx context.Context, organizerID, eventID string) ([]domain.Zone, error) {
+	const existsQuery = `SELECT EXISTS (SELECT 1 FROM events WHERE id = $1 AND organizer_id = $2)`
 	var exists bool
-	if err := r.pool.QueryRow(ctx, existsQuery, eventID).Scan(&exists); err != nil {
+	if err := r.pool.QueryRow(ctx, existsQuery, eventID, organizerID).Scan(&exists); err != nil {
 		if isInvalidUUID(err) {
 			return nil, domain.ErrInvalidID
 		}
@@ -89,7 +161,7 @@ func (r *AdminRepository) ListZonesByEvent(ctx context.Context, eventID string)
 	}
 
 	const query = `
-SELECT id, event_id, name, capacity
+SELECT id, event_id, name, capacity, min_quantity, max_quantity, quantity_step, purchase_limit
 FROM zones
 WHERE event_id = $1
 ORDER BY created_at ASC`
@@ -102,7 +174,8 @@ ORDER BY created_at ASC`
 	var zones []domain.Zone
 	for rows.Next() {
 		var zone domain.Zone
-		if err := rows.Scan(&zone.ID, &zone.EventID, &zone.Name, &zone.Capacity); err != nil {
+		if err := rows.Scan(&zone.ID, &zone.EventID, &zone.Name, &zone.Capacity, &zone.MinQuantity, &zone.MaxQuantity, &zone.QuantityStep,
+			&zone.PurchaseLimit); err != nil {
 			return nil, fmt.Errorf("scan zone: %w", err)
 		}
 		zones = append(zones, zone)
@@ -112,3 +185,17 @@ ORDER BY created_at ASC`
 	}
 	return zones, nil
 }
+
+func (r *AdminRepository) exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	if tx := txFromContext(ctx); tx != nil {
+		return tx.Exec(ctx, sql, args...)
+	}
+	return r.pool.Exec(ctx, sql, args...)
+}
+
+func (r *AdminRepository) queryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	if tx := txFromContext(ctx); tx != nil {
+		return tx.QueryRow(ctx, sql, args...)
+	}
+	return r.pool.QueryRow(ctx, sql, args...)
+}
@@ -18,24 +18,33 @@ func TestAdminRepository_CreateAndListEvents(t *testing.T) {
 	testutil.TruncateAll(t, ctx, pool)
 
 	event := domain.Event{
-		ID:       "00000000-0000-0000-0000-000000000010",
-		Name:     "Concert",
-		StartsAt: time.Date(2025, 1, 5, 10, 0, 0, 0, time.UTC),
+		ID:            "00000000-0000-0000-0000-000000000010",
+		OrganizerID:   domain.DefaultOrganizerID,
+		Name:          "Concert",
+		StartsAt:      time.Date(2025, 1, 5, 10, 0, 0, 0, time.UTC),
+		PurchaseLimit: 4,
 	}
 	if err := repo.CreateEvent(ctx, event); err != nil {
 		t.Fatalf("create event: %v", err)
 	}
 
-	events, err := repo.ListEvents(ctx)
+	events, err := repo.ListEvents(ctx, domain.DefaultOrganizerID)
 	if err != nil {
 		t.Fatalf("list events: %v", err)
 	}
 	if len(events) != 1 {
 		t.Fatalf("expected 1 event, got %d", len(events))
 	}
-	if events[0].ID != event.ID || events[0].Name != event.Name {
+	if events[0].ID != event.ID || events[0].Name != event.Name || events[0].OrganizerID != event.OrganizerID || events[0].PurchaseLimit != 4 {
 		t.Fatalf("unexpected event: %+v", events[0])
 	}
+
+	other := event
+	other.ID = "00000000-0000-0000-0000-000000000011"
+	other.OrganizerID = "00000000-0000-0000-0000-0000000000ff"
+	if err := repo.CreateEvent(ctx, other); err != domain.ErrOrganizerNotFound {
+		t.Fatalf("expected ErrOrganizerNotFound, got %v", err)
+	}
 }
 
 func TestAdminRepository_CreateAndListZones(t *testing.T) {
@@ -48,22 +57,32 @@ func TestAdminRepository_CreateAndListZones(t *testing.T) {
 
 	eventID, _ := testutil.InsertEventAndZone(t, ctx, pool, "Concert", 100)
 	zone := domain.Zone{
-		ID:       "00000000-0000-0000-0000-000000000020",
-		EventID:  eventID,
-		Name:     "Zone B",
-		Capacity: 50,
+		ID:            "00000000-0000-0000-0000-000000000020",
+		EventID:       eventID,
+		Name:          "Zone B",
+		Capacity:      50,
+		MinQuantity:   2,
+		MaxQuantity:   8,
+		QuantityStep:  2,
+		PurchaseLimit: 6,
 	}
-	if err := repo.CreateZone(ctx, zone); err != nil {
+	if err := repo.CreateZone(ctx, domain.DefaultOrganizerID, zone); err != nil {
 		t.Fatalf("create zone: %v", err)
 	}
 
-	zones, err := repo.ListZonesByEvent(ctx, eventID)
+	zones, err := repo.ListZonesByEvent(ctx, domain.DefaultOrganizerID, eventID)
 	if err != nil {
 		t.Fatalf("list zones: %v", err)
 	}
 	if len(zones) != 2 {
 		t.Fatalf("expected 2 zones, got %d", len(zones))
 	}
+	if z := zones[0]; z.MinQuantity != 1 || z.MaxQuantity != 0 || z.QuantityStep != 1 || z.PurchaseLimit != 0 {
+		t.Fatalf("expected default rules on existing zone, got %+v", z)
+	}
+	if z := zones[1]; z.MinQuantity != 2 || z.MaxQuantity != 8 || z.QuantityStep != 2 || z.PurchaseLimit != 6 {
+		t.Fatalf("expected stored rules, got %+v", z)
+	}
 }
 
 func TestAdminRepository_CreateZone_InvalidEvent(t *testing.T) {
@@ -80,12 +99,84 @@ func TestAdminRepository_CreateZone_InvalidEvent(t *testing.T) {
 		Name:     "Zone A",
 		Capacity: 10,
 	}
-	if err := repo.CreateZone(ctx, zone); err != domain.ErrEventNotFound {
+	if err := repo.CreateZone(ctx, domain.DefaultOrganizerID, zone); err != domain.ErrEventNotFound {
 		t.Fatalf("expected ErrEventNotFound, got %v", err)
 	}
 
-	_, err := repo.ListZonesByEvent(ctx, "not-a-uuid")
+	_, err := repo.ListZonesByEvent(ctx, domain.DefaultOrganizerID, "not-a-uuid")
 	if err != domain.ErrInvalidID {
 		t.Fatalf("expected ErrInvalidID, got %v", err)
 	}
 }
+
+func TestAdminRepository_UpdateEvent(t *testing.T) {
+	pool := testutil.NewTestPool(t)
+	testutil.ApplyMigrations(t, context.Background(), pool)
+	repo := NewAdminRepository(pool)
+
+	ctx := context.Background()
+	testutil.TruncateAll(t, ctx, pool)
+
+	eventID, _ := testutil.InsertEventAndZone(t, ctx, pool, "Concert", 100)
+	moved := time.Date(2025, 2, 1, 21, 0, 0, 0, time.UTC)
+
+	err := repo.WithTx(ctx, func(txCtx context.Context) error {
+		event, err := repo.GetEventForUpdate(txCtx, domain.DefaultOrganizerID, eventID)
+		if err != nil {
+			return err
+		}
+		event.Name = "Concert (rescheduled)"
+		event.StartsAt = moved
+		return repo.UpdateEvent(txCtx, event)
+	})
+	if err != nil {
+		t.Fatalf("update event: %v", err)
+	}
+
+	events, err := repo.ListEvents(ctx, domain.DefaultOrganizerID)
+	if err != nil {
+		t.Fatalf("list events: %v", err)
+	}
+	if len(events) != 1 || events[0].Name != "Concert (rescheduled)" || !events[0].StartsAt.Equal(moved) {
+		t.Fatalf("unexpected events: %+v", events)
+	}
+
+	if _, err := repo.GetEventForUpdate(ctx, domain.DefaultOrganizerID, "00000000-0000-0000-0000-000000000099"); err != domain.ErrEventNotFound {
+		t.Fatalf("expected ErrEventNotFound, got %v", err)
+	}
+}
+
+func TestAdminRepository_ScopesByOrganizer(t *testing.T) {
+	pool := testutil.NewTestPool(t)
+	testutil.ApplyMigrations(t, context.Background(), pool)
+	repo := NewAdminRepository(pool)
+
+	ctx := context.Background()
+	testutil.TruncateAll(t, ctx, pool)
+
+	eventID, _ := testutil.InsertEventAndZone(t, ctx, pool, "Concert", 100)
+	rival := testutil.InsertOrganizer(t, ctx, pool, "Rival")
+
+	events, err := repo.ListEvents(ctx, rival)
+	if err != nil || len(events) != 0 {
+		t.Fatalf("expected no events for another organizer, got %+v (%v)", events, err)
+	}
+	if _, err := repo.GetEventForUpdate(ctx, rival, eventID); err != domain.ErrEventNotFound {
+		t.Fatalf("expected ErrEventNotFound, got %v", err)
+	}
+	if err := repo.UpdateEvent(ctx, domain.Event{ID: eventID, OrganizerID: rival, Name: "Hijacked", StartsAt: time.Now()}); err != domain.ErrEventNotFound {
+		t.Fatalf("expected ErrEventNotFound on update, got %v", err)
+	}
+	zone := domain.Zone{ID: "00000000-0000-0000-0000-000000000040", EventID: eventID, Name: "Zone B", Capacity: 10}
+	if err := repo.CreateZone(ctx, rival, zone); err != domain.ErrEventNotFound {
+		t.Fatalf("expected ErrEventNotFound on zone create, got %v", err)
+	}
+	if _, err := repo.ListZonesByEvent(ctx, rival, eventID); err != domain.ErrEventNotFound {
+		t.Fatalf("expected ErrEventNotFound on zone list, got %v", err)
+	}
+
+	zones, err := repo.ListZonesByEvent(ctx, domain.DefaultOrganizerID, eventID)
+	if err != nil || len(zones) != 1 {
+		t.Fatalf("expected owner's zone untouched, got %+v (%v)", zones, err)
+	}
+}
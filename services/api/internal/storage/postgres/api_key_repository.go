@@ -0,0 +1,170 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/cimillas/ultimate-ticket/services/api/internal/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type APIKeyRepository struct {
+	pool *pgxpool.Pool
+	cfg  repositoryConfig
+}
+
+func NewAPIKeyRepository(pool *pgxpool.Pool, opts ...RepositoryOption) *APIKeyRepository {
+	return &APIKeyRepository{pool: pool, cfg: newRepositoryConfig(opts)}
+}
+
+func (r *APIKeyRepository) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return withTx(ctx, r.pool, r.cfg, fn)
+}
+
+const apiKeyColumns = `id, organizer_id, name, prefix, key_hash, role, created_at, last_used_at, revoked_at`
+
+func (r *APIKeyRepository) CreateAPIKey(ctx context.Context, key domain.APIKey) error {
+	const stmt = `
+INSERT INTO api_keys (id, organizer_id, name, prefix, key_hash, role, created_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7)`
+	if _, err := r.exec(ctx, stmt, key.ID, key.OrganizerID, key.Name, key.Prefix, key.KeyHash, key.Role, key.CreatedAt); err != nil {
+		if isInvalidUUID(err) {
+			return domain.ErrInvalidID
+		}
+		if isForeignKeyViolation(err) {
+			return domain.ErrOrganizerNotFound
+		}
+		return fmt.Errorf("create api key: %w", err)
+	}
+	return nil
+}
+
+func (r *APIKeyRepository) GetAPIKeyByHash(ctx context.Context, keyHash string) (domain.APIKey, error) {
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE key_hash = $1`
+	key, err := scanAPIKey(r.queryRow(ctx, query, keyHash))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return domain.APIKey{}, domain.ErrInvalidAPIKey
+		}
+		return domain.APIKey{}, fmt.Errorf("get api key: %w", err)
+	}
+	return key, nil
+}
+
+func (r *APIKeyRepository) ListAPIKeys(ctx context.Context, organizerID string) ([]domain.APIKey, error) {
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE organizer_id = $1 ORDER BY created_at ASC`
+	rows, err := r.query(ctx, query, organizerID)
+	if err != nil {
+		if isInvalidUUID(err) {
+			return nil, domain.ErrInvalidID
+		}
+		return nil, fmt.Errorf("list api keys: %w", err)
+	}
+	defer rows.Close()
+
+	var keys []domain.APIKey
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan api key: %w", err)
+		}
+		keys = append(keys, key)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate api keys: %w", err)
+	}
+	return keys, nil
+}
+
+// apiKeyBootstrapLock serializes bootstraps for the rest of the transaction.
+const apiKeyBootstrapLock int64 = 801234570
+
+func (r *APIKeyRepository) CountActiveAPIKeys(ctx context.Context) (int, error) {
+	if _, err := r.exec(ctx, `SELECT pg_advisory_xact_lock($1)`, apiKeyBootstrapLock); err != nil {
+		return 0, fmt.Errorf("lock api keys: %w", err)
+	}
+	var n int
+	if err := r.queryRow(ctx, `SELECT COUNT(*) FROM api_keys WHERE revoked_at IS NULL`).Scan(&n); err != nil {
+		return 0, fmt.Errorf("count api keys: %w", err)
+	}
+	return n, nil
+}
+
+func (r *APIKeyRepository) GetAPIKeyForUpdate(ctx context.Context, organizerID, id string) (domain.APIKey, error) {
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE id = $1 AND organizer_id = $2 FOR UPDATE`
+	key, err := scanAPIKey(r.queryRow(ctx, query, id, organizerID))
+	if err != nil {
+		if isInvalidUUID(err) {
+			return domain.APIKey{}, domain.ErrInvalidID
+		}
+		if err == pgx.ErrNoRows {
+			return domain.APIKey{}, domain.ErrAPIKeyNotFound
+		}
+		return domain.APIKey{}, fmt.Errorf("get api key: %w", err)
+	}
+	return key, nil
+}
+
+func (r *APIKeyRepository) RevokeAPIKey(ctx context.Context, organizerID, id string, now time.Time) (domain.APIKey, error) {
+	query := `
+UPDATE api_keys
+SET revoked_at = COALESCE(revoked_at, $3)
+WHERE id = $1 AND organizer_id = $2
+RETURNING ` + apiKeyColumns
+	key, err := scanAPIKey(r.queryRow(ctx, query, id, organizerID, now))
+	if err != nil {
+		if isInvalidUUID(err) {
+			return domain.APIKey{}, domain.ErrInvalidID
+		}
+		if err == pgx.ErrNoRows {
+			return domain.APIKey{}, domain.ErrAPIKeyNotFound
+		}
+		return domain.APIKey{}, fmt.Errorf("revoke api key: %w", err)
+	}
+	return key, nil
+}
+
+func (r *APIKeyRepository) AppendAuditEntry(ctx context.Context, entry domain.AuditEntry) error {
+	return appendAuditEntry(ctx, r.exec, entry)
+}
+
+func (r *APIKeyRepository) TouchAPIKey(ctx context.Context, id string, now time.Time) error {
+	if _, err := r.exec(ctx, `UPDATE api_keys SET last_used_at = $2 WHERE id = $1`, id, now); err != nil {
+		return fmt.Errorf("touch api key: %w", err)
+	}
+	return nil
+}
+
+func scanAPIKey(row pgx.Row) (domain.APIKey, error) {
+	var key domain.APIKey
+	var role string
+	if err := row.Scan(&key.ID, &key.OrganizerID, &key.Name, &key.Prefix, &key.KeyHash, &role, &key.CreatedAt, &key.LastUsedAt, &key.RevokedAt); err != nil {
+		return domain.APIKey{}, err
+	}
+	key.Role = domain.Role(role)
+	return key, nil
+}
+
+func (r *APIKeyRepository) exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	if tx := txFromContext(ctx); tx != nil {
+		return tx.Exec(ctx, sql, args...)
+	}
+	return r.pool.Exec(ctx, sql, args...)
+}
+
+func (r *APIKeyRepository) query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	if tx := txFromContext(ctx); tx != nil {
+		return tx.Query(ctx, sql, args...)
+	}
+	return r.pool.Query(ctx, sql, args...)
+}
+
+func (r *APIKeyRepository) queryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	if tx := txFromContext(ctx); tx != nil {
+		return tx.QueryRow(ctx, sql, args...)
+	}
+	return r.pool.QueryRow(ctx, sql, args...)
+}
@@ -0,0 +1,72 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cimillas/ultimate-ticket/services/api/internal/domain"
+	"github.com/cimillas/ultimate-ticket/services/api/internal/testutil"
+)
+
+func TestAPIKeyRepository(t *testing.T) {
+	pool := testutil.NewTestPool(t)
+	repo := NewAPIKeyRepository(pool)
+	testutil.ApplyMigrations(t, context.Background(), pool)
+
+	ctx := context.Background()
+	testutil.TruncateAll(t, ctx, pool)
+	now := time.Now().UTC().Truncate(time.Microsecond)
+
+	key := domain.APIKey{
+		ID:          "aaaaaaaa-1111-1111-1111-aaaaaaaaaaaa",
+		OrganizerID: domain.DefaultOrganizerID,
+		Name:        "ci",
+		Prefix:      "utk_abcdefgh",
+		KeyHash:     "hash-1",
+		Role:        domain.RoleEventManager,
+		CreatedAt:   now,
+	}
+	if err := repo.CreateAPIKey(ctx, key); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	got, err := repo.GetAPIKeyByHash(ctx, "hash-1")
+	if err != nil || got.ID != key.ID || got.OrganizerID != domain.DefaultOrganizerID || got.Role != domain.RoleEventManager || got.LastUsedAt != nil {
+		t.Fatalf("unexpected key %+v (%v)", got, err)
+	}
+	if _, err := repo.GetAPIKeyByHash(ctx, "missing"); err != domain.ErrInvalidAPIKey {
+		t.Fatalf("expected ErrInvalidAPIKey, got %v", err)
+	}
+
+	if err := repo.TouchAPIKey(ctx, key.ID, now); err != nil {
+		t.Fatalf("touch: %v", err)
+	}
+	if n, err := repo.CountActiveAPIKeys(ctx); err != nil || n != 1 {
+		t.Fatalf("expected 1 active key, got %d (%v)", n, err)
+	}
+
+	rival := testutil.InsertOrganizer(t, ctx, pool, "Rival")
+	if _, err := repo.RevokeAPIKey(ctx, rival, key.ID, now); err != domain.ErrAPIKeyNotFound {
+		t.Fatalf("expected another organizer's revoke to fail with ErrAPIKeyNotFound, got %v", err)
+	}
+	if keys, err := repo.ListAPIKeys(ctx, rival); err != nil || len(keys) != 0 {
+		t.Fatalf("expected no keys for another organizer, got %+v (%v)", keys, err)
+	}
+
+	revoked, err := repo.RevokeAPIKey(ctx, domain.DefaultOrganizerID, key.ID, now)
+	if err != nil || revoked.RevokedAt == nil || revoked.LastUsedAt == nil {
+		t.Fatalf("unexpected revoked key %+v (%v)", revoked, err)
+	}
+	if n, err := repo.CountActiveAPIKeys(ctx); err != nil || n != 0 {
+		t.Fatalf("expected no active keys, got %d (%v)", n, err)
+	}
+	if _, err := repo.RevokeAPIKey(ctx, domain.DefaultOrganizerID, "bbbbbbbb-2222-2222-2222-bbbbbbbbbbbb", now); err != domain.ErrAPIKeyNotFound {
+		t.Fatalf("expected ErrAPIKeyNotFound, got %v", err)
+	}
+
+	keys, err := repo.ListAPIKeys(ctx, domain.DefaultOrganizerID)
+	if err != nil || len(keys) != 1 {
+		t.Fatalf("expected 1 key, got %+v (%v)", keys, err)
+	}
+}
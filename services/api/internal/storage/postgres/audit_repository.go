@@ -0,0 +1,111 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cimillas/ultimate-ticket/services/api/internal/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const insertAuditEntry = `
+INSERT INTO audit_log (id, organizer_id, actor, action, target_type, target_id, before_snapshot, after_snapshot, request_id, created_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`
+
+// appendAuditEntry writes entry with the caller's exec helper so it joins the
+// transaction carried by ctx and is only kept if the change is.
+func appendAuditEntry(ctx context.Context, exec execFunc, entry domain.AuditEntry) error {
+	_, err := exec(ctx, insertAuditEntry,
+		entry.ID,
+		entry.OrganizerID,
+		entry.Actor,
+		entry.Action,
+		entry.TargetType,
+		entry.TargetID,
+		entry.Before,
+		entry.After,
+		entry.RequestID,
+		entry.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("append audit entry: %w", err)
+	}
+	return nil
+}
+
+// AuditRepository reads the audit log. The table rejects updates and
+// deletes, so entries can only be added by the repositories making changes.
+type AuditRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewAuditRepository(pool *pgxpool.Pool) *AuditRepository {
+	return &AuditRepository{pool: pool}
+}
+
+func (r *AuditRepository) ListAuditEntries(ctx context.Context, filter domain.AuditFilter) ([]domain.AuditEntry, error) {
+	const query = `
+SELECT id, organizer_id, actor, action, target_type, target_id, before_snapshot, after_snapshot, request_id, created_at
+FROM audit_log
+WHERE organizer_id = $1
+  AND ($2 = '' OR actor = $2)
+  AND ($3 = '' OR action = $3)
+  AND ($4 = '' OR target_type = $4)
+  AND ($5 = '' OR target_id = $5)
+  AND ($6::timestamptz IS NULL OR created_at >= $6)
+  AND ($7::timestamptz IS NULL OR created_at < $7)
+ORDER BY created_at DESC, id
+LIMIT $8`
+
+	rows, err := r.query(ctx, query,
+		filter.OrganizerID,
+		filter.Actor,
+		string(filter.Action),
+		filter.TargetType,
+		filter.TargetID,
+		filter.Since,
+		filter.Until,
+		filter.Limit,
+	)
+	if err != nil {
+		if isInvalidUUID(err) {
+			return nil, domain.ErrInvalidID
+		}
+		return nil, fmt.Errorf("list audit entries: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []domain.AuditEntry
+	for rows.Next() {
+		var entry domain.AuditEntry
+		var action string
+		if err := rows.Scan(
+			&entry.ID,
+			&entry.OrganizerID,
+			&entry.Actor,
+			&action,
+			&entry.TargetType,
+			&entry.TargetID,
+			&entry.Before,
+			&entry.After,
+			&entry.RequestID,
+			&entry.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("scan audit entry: %w", err)
+		}
+		entry.Action = domain.AuditAction(action)
+		entries = append(entries, entry)
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("iterate audit entries: %w", rows.Err())
+	}
+	return entries, nil
+}
+
+func (r *AuditRepository) query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	if tx := txFromContext(ctx); tx != nil {
+		return tx.Query(ctx, sql, args...)
+	}
+	return r.pool.Query(ctx, sql, args...)
+}
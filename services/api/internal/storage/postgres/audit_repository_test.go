@@ -0,0 +1,105 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cimillas/ultimate-ticket/services/api/internal/domain"
+	"github.com/cimillas/ultimate-ticket/services/api/internal/testutil"
+)
+
+func TestAuditRepository(t *testing.T) {
+	pool := testutil.NewTestPool(t)
+	repo := NewAuditRepository(pool)
+	admin := NewAdminRepository(pool)
+	testutil.ApplyMigrations(t, context.Background(), pool)
+
+	ctx := context.Background()
+	testutil.TruncateAll(t, ctx, pool)
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	rival := testutil.InsertOrganizer(t, ctx, pool, "Rival")
+
+	entries := []domain.AuditEntry{
+		{
+			ID: "aaaaaaaa-0000-0000-0000-000000000001", OrganizerID: domain.DefaultOrganizerID, Actor: "api_key:k1",
+			Action: domain.AuditEventCreated, TargetType: domain.AuditTargetEvent, TargetID: "e1",
+			After: []byte(`{"name": "Concert"}`), RequestID: "req-1", CreatedAt: now.Add(-time.Hour),
+		},
+		{
+			ID: "aaaaaaaa-0000-0000-0000-000000000002", OrganizerID: domain.DefaultOrganizerID, Actor: "api_key:k2",
+			Action: domain.AuditEventUpdated, TargetType: domain.AuditTargetEvent, TargetID: "e1",
+			Before: []byte(`{"name": "Concert"}`), After: []byte(`{"name": "Festival"}`), CreatedAt: now,
+		},
+		{
+			ID: "aaaaaaaa-0000-0000-0000-000000000003", OrganizerID: rival, Actor: "api_key:k3",
+			Action: domain.AuditEventCreated, TargetType: domain.AuditTargetEvent, TargetID: "e2", CreatedAt: now,
+		},
+	}
+	for _, entry := range entries {
+		if err := admin.AppendAuditEntry(ctx, entry); err != nil {
+			t.Fatalf("append: %v", err)
+		}
+	}
+
+	t.Run("lists newest first for the organizer", func(t *testing.T) {
+		got, err := repo.ListAuditEntries(ctx, domain.AuditFilter{OrganizerID: domain.DefaultOrganizerID, Limit: 10})
+		if err != nil {
+			t.Fatalf("list: %v", err)
+		}
+		if len(got) != 2 || got[0].Action != domain.AuditEventUpdated || got[1].Action != domain.AuditEventCreated {
+			t.Fatalf("unexpected entries %+v", got)
+		}
+		if got[1].Before != nil || string(got[1].After) != `{"name": "Concert"}` || got[1].RequestID != "req-1" {
+			t.Fatalf("unexpected snapshots %+v", got[1])
+		}
+	})
+
+	t.Run("filters", func(t *testing.T) {
+		since := now.Add(-time.Minute)
+		filters := []domain.AuditFilter{
+			{OrganizerID: domain.DefaultOrganizerID, Actor: "api_key:k2", Limit: 10},
+			{OrganizerID: domain.DefaultOrganizerID, Action: domain.AuditEventUpdated, Limit: 10},
+			{OrganizerID: domain.DefaultOrganizerID, TargetType: domain.AuditTargetEvent, TargetID: "e1", Since: &since, Limit: 10},
+			{OrganizerID: domain.DefaultOrganizerID, Limit: 1},
+		}
+		for _, f := range filters {
+			got, err := repo.ListAuditEntries(ctx, f)
+			if err != nil {
+				t.Fatalf("list %+v: %v", f, err)
+			}
+			if len(got) != 1 || got[0].ID != entries[1].ID {
+				t.Fatalf("filter %+v: unexpected entries %+v", f, got)
+			}
+		}
+	})
+
+	t.Run("rejects updates and deletes", func(t *testing.T) {
+		if _, err := pool.Exec(ctx, `UPDATE audit_log SET actor = 'someone else'`); err == nil {
+			t.Fatalf("expected update to be rejected")
+		}
+		if _, err := pool.Exec(ctx, `DELETE FROM audit_log`); err == nil {
+			t.Fatalf("expected delete to be rejected")
+		}
+	})
+
+	t.Run("is rolled back with the change", func(t *testing.T) {
+		errBoom := errors.New("boom")
+		err := admin.WithTx(ctx, func(txCtx context.Context) error {
+			entry := entries[0]
+			entry.ID = "aaaaaaaa-0000-0000-0000-000000000004"
+			if err := admin.AppendAuditEntry(txCtx, entry); err != nil {
+				return err
+			}
+			return errBoom
+		})
+		if err != errBoom {
+			t.Fatalf("expected boom, got %v", err)
+		}
+		got, err := repo.ListAuditEntries(ctx, domain.AuditFilter{OrganizerID: domain.DefaultOrganizerID, Limit: 10})
+		if err != nil || len(got) != 2 {
+			t.Fatalf("expected the rolled back entry to be gone, got %d (%v)", len(got), err)
+		}
+	})
+}
@@ -0,0 +1,149 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/cimillas/ultimate-ticket/services/api/internal/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type AuthRepository struct {
+	pool *pgxpool.Pool
+	cfg  repositoryConfig
+}
+
+func NewAuthRepository(pool *pgxpool.Pool, opts ...RepositoryOption) *AuthRepository {
+	return &AuthRepository{pool: pool, cfg: newRepositoryConfig(opts)}
+}
+
+func (r *AuthRepository) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return withTx(ctx, r.pool, r.cfg, fn)
+}
+
+func (r *AuthRepository) ConsumeLoginCodes(ctx context.Context, email string, now time.Time) error {
+	const stmt = `UPDATE login_codes SET consumed_at = $2 WHERE email = $1 AND consumed_at IS NULL`
+	if _, err := r.exec(ctx, stmt, email, now); err != nil {
+		return fmt.Errorf("consume login codes: %w", err)
+	}
+	return nil
+}
+
+func (r *AuthRepository) CreateLoginCode(ctx context.Context, code domain.LoginCode) error {
+	const stmt = `
+INSERT INTO login_codes (id, email, client_ip, code_hash, attempts, expires_at, created_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7)`
+	if _, err := r.exec(ctx, stmt, code.ID, code.Email, code.ClientIP, code.CodeHash, code.Attempts, code.ExpiresAt, code.CreatedAt); err != nil {
+		return fmt.Errorf("create login code: %w", err)
+	}
+	return nil
+}
+
+func (r *AuthRepository) GetLatestLoginCodeForUpdate(ctx context.Context, email string) (domain.LoginCode, error) {
+	const query = `
+SELECT id, email, client_ip, code_hash, attempts, expires_at, consumed_at, created_at
+FROM login_codes
+WHERE email = $1
+ORDER BY created_at DESC
+LIMIT 1
+FOR UPDATE`
+
+	var c domain.LoginCode
+	err := r.queryRow(ctx, query, email).
+		Scan(&c.ID, &c.Email, &c.ClientIP, &c.CodeHash, &c.Attempts, &c.ExpiresAt, &c.ConsumedAt, &c.CreatedAt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return domain.LoginCode{}, domain.ErrInvalidLoginCode
+		}
+		return domain.LoginCode{}, fmt.Errorf("get login code: %w", err)
+	}
+	return c, nil
+}
+
+func (r *AuthRepository) CountLoginCodes(ctx context.Context, email, clientIP string, since time.Time) (int, int, error) {
+	const query = `
+SELECT
+    (SELECT COUNT(*) FROM login_codes WHERE email = $1 AND created_at > $3),
+    (SELECT COUNT(*) FROM login_codes WHERE client_ip = $2 AND $2 <> '' AND created_at > $3)`
+
+	var byEmail, byIP int
+	if err := r.queryRow(ctx, query, email, clientIP, since).Scan(&byEmail, &byIP); err != nil {
+		return 0, 0, fmt.Errorf("count login codes: %w", err)
+	}
+	return byEmail, byIP, nil
+}
+
+func (r *AuthRepository) CountFailedLoginAttempts(ctx context.Context, email string, since time.Time) (int, error) {
+	const query = `SELECT COALESCE(SUM(attempts), 0) FROM login_codes WHERE email = $1 AND created_at > $2`
+
+	var n int
+	if err := r.queryRow(ctx, query, email, since).Scan(&n); err != nil {
+		return 0, fmt.Errorf("count failed login attempts: %w", err)
+	}
+	return n, nil
+}
+
+func (r *AuthRepository) UpdateLoginCode(ctx context.Context, code domain.LoginCode) error {
+	const stmt = `UPDATE login_codes SET attempts = $2, consumed_at = $3 WHERE id = $1`
+	tag, err := r.exec(ctx, stmt, code.ID, code.Attempts, code.ConsumedAt)
+	if err != nil {
+		return fmt.Errorf("update login code: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrInvalidLoginCode
+	}
+	return nil
+}
+
+func (r *AuthRepository) CreateSession(ctx context.Context, session domain.Session) error {
+	const stmt = `
+INSERT INTO sessions (token_hash, customer_id, expires_at, created_at)
+VALUES ($1, $2, $3, $4)`
+	if _, err := r.exec(ctx, stmt, session.TokenHash, session.CustomerID, session.ExpiresAt, session.CreatedAt); err != nil {
+		return fmt.Errorf("create session: %w", err)
+	}
+	return nil
+}
+
+func (r *AuthRepository) GetSession(ctx context.Context, tokenHash string) (domain.Session, error) {
+	const query = `
+SELECT token_hash, customer_id, expires_at, revoked_at, created_at
+FROM sessions
+WHERE token_hash = $1`
+
+	var s domain.Session
+	err := r.queryRow(ctx, query, tokenHash).
+		Scan(&s.TokenHash, &s.CustomerID, &s.ExpiresAt, &s.RevokedAt, &s.CreatedAt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return domain.Session{}, domain.ErrInvalidSession
+		}
+		return domain.Session{}, fmt.Errorf("get session: %w", err)
+	}
+	return s, nil
+}
+
+func (r *AuthRepository) RevokeSession(ctx context.Context, tokenHash string, now time.Time) error {
+	const stmt = `UPDATE sessions SET revoked_at = $2 WHERE token_hash = $1 AND revoked_at IS NULL`
+	if _, err := r.exec(ctx, stmt, tokenHash, now); err != nil {
+		return fmt.Errorf("revoke session: %w", err)
+	}
+	return nil
+}
+
+func (r *AuthRepository) exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	if tx := txFromContext(ctx); tx != nil {
+		return tx.Exec(ctx, sql, args...)
+	}
+	return r.pool.Exec(ctx, sql, args...)
+}
+
+func (r *AuthRepository) queryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	if tx := txFromContext(ctx); tx != nil {
+		return tx.QueryRow(ctx, sql, args...)
+	}
+	return r.pool.QueryRow(ctx, sql, args...)
+}
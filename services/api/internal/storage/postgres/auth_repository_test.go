@@ -0,0 +1,102 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cimillas/ultimate-ticket/services/api/internal/domain"
+	"github.com/cimillas/ultimate-ticket/services/api/internal/testutil"
+)
+
+func TestAuthRepository(t *testing.T) {
+	pool := testutil.NewTestPool(t)
+	repo := NewAuthRepository(pool)
+	customers := NewCustomerRepository(pool)
+	testutil.ApplyMigrations(t, context.Background(), pool)
+
+	t.Run("login codes", func(t *testing.T) {
+		ctx := context.Background()
+		testutil.TruncateAll(t, ctx, pool)
+		now := time.Now().UTC().Truncate(time.Microsecond)
+
+		if _, err := repo.GetLatestLoginCodeForUpdate(ctx, "fan@example.com"); err != domain.ErrInvalidLoginCode {
+			t.Fatalf("expected ErrInvalidLoginCode, got %v", err)
+		}
+		for i, id := range []string{"aaaaaaaa-1111-1111-1111-aaaaaaaaaaaa", "bbbbbbbb-2222-2222-2222-bbbbbbbbbbbb"} {
+			if err := repo.ConsumeLoginCodes(ctx, "fan@example.com", now); err != nil {
+				t.Fatalf("consume: %v", err)
+			}
+			if err := repo.CreateLoginCode(ctx, domain.LoginCode{
+				ID:        id,
+				Email:     "fan@example.com",
+				ClientIP:  "203.0.113.7",
+				CodeHash:  "hash",
+				ExpiresAt: now.Add(10 * time.Minute),
+				CreatedAt: now.Add(time.Duration(i) * time.Second),
+			}); err != nil {
+				t.Fatalf("create code: %v", err)
+			}
+		}
+
+		latest, err := repo.GetLatestLoginCodeForUpdate(ctx, "fan@example.com")
+		if err != nil {
+			t.Fatalf("get code: %v", err)
+		}
+		if latest.ID != "bbbbbbbb-2222-2222-2222-bbbbbbbbbbbb" || latest.ClientIP != "203.0.113.7" || latest.ConsumedAt != nil {
+			t.Fatalf("unexpected latest code %+v", latest)
+		}
+
+		latest.Attempts = 2
+		latest.ConsumedAt = &now
+		if err := repo.UpdateLoginCode(ctx, latest); err != nil {
+			t.Fatalf("update code: %v", err)
+		}
+		got, err := repo.GetLatestLoginCodeForUpdate(ctx, "fan@example.com")
+		if err != nil || got.Attempts != 2 || got.ConsumedAt == nil {
+			t.Fatalf("expected updated code, got %+v (%v)", got, err)
+		}
+
+		byEmail, byIP, err := repo.CountLoginCodes(ctx, "fan@example.com", "203.0.113.7", now.Add(-time.Minute))
+		if err != nil || byEmail != 2 || byIP != 2 {
+			t.Fatalf("expected 2 codes by email and by IP, got %d and %d (%v)", byEmail, byIP, err)
+		}
+		if byEmail, byIP, err := repo.CountLoginCodes(ctx, "other@example.com", "", now.Add(-time.Minute)); err != nil || byEmail != 0 || byIP != 0 {
+			t.Fatalf("expected no codes for another address without an IP, got %d and %d (%v)", byEmail, byIP, err)
+		}
+		if byEmail, _, err := repo.CountLoginCodes(ctx, "fan@example.com", "", now); err != nil || byEmail != 1 {
+			t.Fatalf("expected 1 code after the window start, got %d (%v)", byEmail, err)
+		}
+		if failed, err := repo.CountFailedLoginAttempts(ctx, "fan@example.com", now.Add(-time.Minute)); err != nil || failed != 2 {
+			t.Fatalf("expected 2 failed attempts, got %d (%v)", failed, err)
+		}
+	})
+
+	t.Run("sessions", func(t *testing.T) {
+		ctx := context.Background()
+		testutil.TruncateAll(t, ctx, pool)
+		now := time.Now().UTC().Truncate(time.Microsecond)
+
+		customer, err := customers.UpsertCustomer(ctx, domain.Customer{ID: "aaaaaaaa-1111-1111-1111-aaaaaaaaaaaa", Email: "fan@example.com", CreatedAt: now})
+		if err != nil {
+			t.Fatalf("upsert customer: %v", err)
+		}
+		if err := repo.CreateSession(ctx, domain.Session{TokenHash: "token-hash", CustomerID: customer.ID, ExpiresAt: now.Add(time.Hour), CreatedAt: now}); err != nil {
+			t.Fatalf("create session: %v", err)
+		}
+
+		session, err := repo.GetSession(ctx, "token-hash")
+		if err != nil || session.CustomerID != customer.ID || session.RevokedAt != nil {
+			t.Fatalf("unexpected session %+v (%v)", session, err)
+		}
+		if err := repo.RevokeSession(ctx, "token-hash", now); err != nil {
+			t.Fatalf("revoke: %v", err)
+		}
+		if session, err := repo.GetSession(ctx, "token-hash"); err != nil || session.RevokedAt == nil {
+			t.Fatalf("expected revoked session, got %+v (%v)", session, err)
+		}
+		if _, err := repo.GetSession(ctx, "missing"); err != domain.ErrInvalidSession {
+			t.Fatalf("expected ErrInvalidSession, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,53 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/cimillas/ultimate-ticket/services/api/internal/pow"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ChallengeStore remembers redeemed proof-of-work challenges in Postgres so
+// a solution is spent once across all API instances.
+type ChallengeStore struct {
+	pool *pgxpool.Pool
+}
+
+func NewChallengeStore(pool *pgxpool.Pool) *ChallengeStore {
+	return &ChallengeStore{pool: pool}
+}
+
+// Redeem reports false when the challenge was already spent on another hold:
+// one with another event, zone or idempotency key.
+func (s *ChallengeStore) Redeem(ctx context.Context, challengeID string, hold pow.Hold, expiresAt, _ time.Time) (bool, error) {
+	const insert = `
+INSERT INTO pow_redemptions (challenge_id, event_id, zone_id, idempotency_key, expires_at)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (challenge_id) DO NOTHING`
+	tag, err := s.pool.Exec(ctx, insert, challengeID, hold.EventID, hold.ZoneID, hold.IdempotencyKey, expiresAt)
+	if err != nil {
+		return false, fmt.Errorf("redeem challenge: %w", err)
+	}
+	if tag.RowsAffected() == 1 {
+		return true, nil
+	}
+
+	var spentOn pow.Hold
+	const query = `SELECT event_id, zone_id, idempotency_key FROM pow_redemptions WHERE challenge_id = $1`
+	if err := s.pool.QueryRow(ctx, query, challengeID).Scan(&spentOn.EventID, &spentOn.ZoneID, &spentOn.IdempotencyKey); err != nil {
+		return false, fmt.Errorf("get challenge redemption: %w", err)
+	}
+	return spentOn == hold, nil
+}
+
+// PruneExpired deletes redemptions of challenges that can no longer be
+// verified anyway.
+func (s *ChallengeStore) PruneExpired(ctx context.Context) (int, error) {
+	tag, err := s.pool.Exec(ctx, `DELETE FROM pow_redemptions WHERE expires_at <= now()`)
+	if err != nil {
+		return 0, fmt.Errorf("prune challenge redemptions: %w", err)
+	}
+	return int(tag.RowsAffected()), nil
+}
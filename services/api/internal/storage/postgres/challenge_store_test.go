@@ -0,0 +1,40 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cimillas/ultimate-ticket/services/api/internal/pow"
+	"github.com/cimillas/ultimate-ticket/services/api/internal/testutil"
+)
+
+func TestChallengeStore(t *testing.T) {
+	pool := testutil.NewTestPool(t)
+	ctx := context.Background()
+	testutil.ApplyMigrations(t, ctx, pool)
+	testutil.TruncateAll(t, ctx, pool)
+	store := NewChallengeStore(pool)
+
+	now := time.Now().UTC()
+	hold := pow.Hold{EventID: "e1", ZoneID: "z1", IdempotencyKey: "key-1"}
+	if ok, err := store.Redeem(ctx, "c1", hold, now.Add(time.Minute), now); err != nil || !ok {
+		t.Fatalf("expected the first redemption to succeed, got %v (%v)", ok, err)
+	}
+	if ok, err := store.Redeem(ctx, "c1", hold, now.Add(time.Minute), now); err != nil || !ok {
+		t.Fatalf("expected a retry of the same hold to succeed, got %v (%v)", ok, err)
+	}
+	if ok, err := store.Redeem(ctx, "c1", pow.Hold{EventID: "e1", ZoneID: "z1", IdempotencyKey: "key-2"}, now.Add(time.Minute), now); err != nil || ok {
+		t.Fatalf("expected a replay on another hold to fail, got %v (%v)", ok, err)
+	}
+	if ok, err := store.Redeem(ctx, "c1", pow.Hold{EventID: "e1", ZoneID: "z2", IdempotencyKey: "key-1"}, now.Add(time.Minute), now); err != nil || ok {
+		t.Fatalf("expected a replay in another zone with the same key to fail, got %v (%v)", ok, err)
+	}
+
+	if ok, err := store.Redeem(ctx, "c2", hold, now.Add(-time.Second), now); err != nil || !ok {
+		t.Fatalf("redeem: %v (%v)", ok, err)
+	}
+	if n, err := store.PruneExpired(ctx); err != nil || n != 1 {
+		t.Fatalf("expected 1 pruned redemption, got %d (%v)", n, err)
+	}
+}
@@ -0,0 +1,139 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cimillas/ultimate-ticket/services/api/internal/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type CustomerRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewCustomerRepository(pool *pgxpool.Pool) *CustomerRepository {
+	return &CustomerRepository{pool: pool}
+}
+
+func (r *CustomerRepository) UpsertCustomer(ctx context.Context, customer domain.Customer) (domain.Customer, error) {
+	// The no-op update makes RETURNING yield the existing row on conflict.
+	const stmt = `
+INSERT INTO customers (id, email, created_at)
+VALUES ($1, $2, $3)
+ON CONFLICT (email) DO UPDATE SET email = customers.email
+RETURNING id, email, created_at`
+
+	var c domain.Customer
+	if err := r.queryRow(ctx, stmt, customer.ID, customer.Email, customer.CreatedAt).Scan(&c.ID, &c.Email, &c.CreatedAt); err != nil {
+		return domain.Customer{}, fmt.Errorf("upsert customer: %w", err)
+	}
+	return c, nil
+}
+
+func (r *CustomerRepository) GetCustomer(ctx context.Context, id string) (domain.Customer, error) {
+	const query = `SELECT id, email, created_at FROM customers WHERE id = $1`
+
+	var c domain.Customer
+	if err := r.queryRow(ctx, query, id).Scan(&c.ID, &c.Email, &c.CreatedAt); err != nil {
+		if isInvalidUUID(err) {
+			return domain.Customer{}, domain.ErrInvalidID
+		}
+		if err == pgx.ErrNoRows {
+			return domain.Customer{}, domain.ErrCustomerNotFound
+		}
+		return domain.Customer{}, fmt.Errorf("get customer: %w", err)
+	}
+	return c, nil
+}
+
+const customerOrderQuery = `
+SELECT o.id, o.hold_id, o.idempotency_key, o.status, o.payment_reference, o.customer_id, o.customer_email,
+	o.created_at, o.updated_at, o.paid_at, o.fulfilled_at, o.failed_at, o.cancelled_at,
+	e.id, e.name, e.starts_at, z.id, z.name, h.quantity
+FROM orders o
+JOIN holds h ON h.id = o.hold_id
+JOIN events e ON e.id = h.event_id
+JOIN zones z ON z.id = h.zone_id
+WHERE o.customer_id = $1`
+
+func (r *CustomerRepository) ListCustomerOrders(ctx context.Context, customerID string) ([]domain.CustomerOrder, error) {
+	rows, err := r.query(ctx, customerOrderQuery+` ORDER BY o.created_at DESC`, customerID)
+	if err != nil {
+		if isInvalidUUID(err) {
+			return nil, domain.ErrInvalidID
+		}
+		return nil, fmt.Errorf("list customer orders: %w", err)
+	}
+	defer rows.Close()
+	return scanCustomerOrders(rows)
+}
+
+func (r *CustomerRepository) GetCustomerOrder(ctx context.Context, customerID, orderID string) (domain.CustomerOrder, error) {
+	rows, err := r.query(ctx, customerOrderQuery+` AND o.id = $2`, customerID, orderID)
+	if err != nil {
+		if isInvalidUUID(err) {
+			return domain.CustomerOrder{}, domain.ErrInvalidID
+		}
+		return domain.CustomerOrder{}, fmt.Errorf("get customer order: %w", err)
+	}
+	defer rows.Close()
+	orders, err := scanCustomerOrders(rows)
+	if err != nil {
+		if isInvalidUUID(err) {
+			return domain.CustomerOrder{}, domain.ErrInvalidID
+		}
+		return domain.CustomerOrder{}, err
+	}
+	if len(orders) == 0 {
+		return domain.CustomerOrder{}, domain.ErrOrderNotFound
+	}
+	return orders[0], nil
+}
+
+func scanCustomerOrders(rows pgx.Rows) ([]domain.CustomerOrder, error) {
+	var out []domain.CustomerOrder
+	for rows.Next() {
+		var co domain.CustomerOrder
+		var status string
+		var paymentRef, customerID, email *string
+		err := rows.Scan(
+			&co.Order.ID, &co.Order.HoldID, &co.Order.IdempotencyKey, &status, &paymentRef, &customerID, &email,
+			&co.Order.CreatedAt, &co.Order.UpdatedAt, &co.Order.PaidAt, &co.Order.FulfilledAt, &co.Order.FailedAt, &co.Order.CancelledAt,
+			&co.EventID, &co.EventName, &co.StartsAt, &co.ZoneID, &co.ZoneName, &co.Quantity,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scan customer order: %w", err)
+		}
+		co.Order.Status = domain.OrderStatus(status)
+		if paymentRef != nil {
+			co.Order.PaymentReference = *paymentRef
+		}
+		if customerID != nil {
+			co.Order.CustomerID = *customerID
+		}
+		if email != nil {
+			co.Order.CustomerEmail = *email
+		}
+		out = append(out, co)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate customer orders: %w", err)
+	}
+	return out, nil
+}
+
+func (r *CustomerRepository) query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	if tx := txFromContext(ctx); tx != nil {
+		return tx.Query(ctx, sql, args...)
+	}
+	return r.pool.Query(ctx, sql, args...)
+}
+
+func (r *CustomerRepository) queryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	if tx := txFromContext(ctx); tx != nil {
+		return tx.QueryRow(ctx, sql, args...)
+	}
+	return r.pool.QueryRow(ctx, sql, args...)
+}
@@ -0,0 +1,97 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cimillas/ultimate-ticket/services/api/internal/domain"
+	"github.com/cimillas/ultimate-ticket/services/api/internal/testutil"
+)
+
+func TestCustomerRepository(t *testing.T) {
+	pool := testutil.NewTestPool(t)
+	repo := NewCustomerRepository(pool)
+	orders := NewOrderRepository(pool)
+	testutil.ApplyMigrations(t, context.Background(), pool)
+
+	t.Run("upsert returns existing customer", func(t *testing.T) {
+		ctx := context.Background()
+		testutil.TruncateAll(t, ctx, pool)
+		now := time.Now().UTC().Truncate(time.Microsecond)
+
+		first, err := repo.UpsertCustomer(ctx, domain.Customer{ID: "aaaaaaaa-1111-1111-1111-aaaaaaaaaaaa", Email: "fan@example.com", CreatedAt: now})
+		if err != nil {
+			t.Fatalf("upsert: %v", err)
+		}
+		second, err := repo.UpsertCustomer(ctx, domain.Customer{ID: "bbbbbbbb-2222-2222-2222-bbbbbbbbbbbb", Email: "fan@example.com", CreatedAt: now})
+		if err != nil {
+			t.Fatalf("upsert again: %v", err)
+		}
+		if second.ID != first.ID {
+			t.Fatalf("expected existing customer %s, got %s", first.ID, second.ID)
+		}
+
+		got, err := repo.GetCustomer(ctx, first.ID)
+		if err != nil || got.Email != "fan@example.com" {
+			t.Fatalf("expected customer, got %+v (%v)", got, err)
+		}
+		if _, err := repo.GetCustomer(ctx, "cccccccc-3333-3333-3333-cccccccccccc"); err != domain.ErrCustomerNotFound {
+			t.Fatalf("expected ErrCustomerNotFound, got %v", err)
+		}
+	})
+
+	t.Run("orders are scoped to the customer", func(t *testing.T) {
+		ctx := context.Background()
+		testutil.TruncateAll(t, ctx, pool)
+		now := time.Now().UTC().Truncate(time.Microsecond)
+
+		owner, err := repo.UpsertCustomer(ctx, domain.Customer{ID: "aaaaaaaa-1111-1111-1111-aaaaaaaaaaaa", Email: "owner@example.com", CreatedAt: now})
+		if err != nil {
+			t.Fatalf("upsert: %v", err)
+		}
+		other, err := repo.UpsertCustomer(ctx, domain.Customer{ID: "bbbbbbbb-2222-2222-2222-bbbbbbbbbbbb", Email: "other@example.com", CreatedAt: now})
+		if err != nil {
+			t.Fatalf("upsert: %v", err)
+		}
+
+		eventID, zoneID := testutil.InsertEventAndZone(t, ctx, pool, "Concert", 10)
+		holdID := testutil.InsertHold(t, ctx, pool, eventID, zoneID, domain.Hold{
+			Quantity:       3,
+			Status:         domain.HoldStatusConfirmed,
+			ExpiresAt:      now.Add(time.Minute),
+			IdempotencyKey: "hold-1",
+		})
+		orderID := "dddddddd-4444-4444-4444-dddddddddddd"
+		if err := orders.CreateOrder(ctx, domain.Order{
+			ID:             orderID,
+			HoldID:         holdID,
+			IdempotencyKey: "order-1",
+			Status:         domain.OrderStatusPaid,
+			CustomerID:     owner.ID,
+			CreatedAt:      now,
+			UpdatedAt:      now,
+		}); err != nil {
+			t.Fatalf("create order: %v", err)
+		}
+
+		list, err := repo.ListCustomerOrders(ctx, owner.ID)
+		if err != nil {
+			t.Fatalf("list: %v", err)
+		}
+		if len(list) != 1 || list[0].Order.ID != orderID || list[0].Order.CustomerID != owner.ID ||
+			list[0].EventName != "Concert" || list[0].ZoneID != zoneID || list[0].Quantity != 3 {
+			t.Fatalf("unexpected orders %+v", list)
+		}
+
+		if _, err := repo.GetCustomerOrder(ctx, owner.ID, orderID); err != nil {
+			t.Fatalf("get own order: %v", err)
+		}
+		if _, err := repo.GetCustomerOrder(ctx, other.ID, orderID); err != domain.ErrOrderNotFound {
+			t.Fatalf("expected ErrOrderNotFound for other customer, got %v", err)
+		}
+		if list, err := repo.ListCustomerOrders(ctx, other.ID); err != nil || len(list) != 0 {
+			t.Fatalf("expected no orders for other customer, got %+v (%v)", list, err)
+		}
+	})
+}
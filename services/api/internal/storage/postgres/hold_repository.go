@@ -13,20 +13,26 @@ import (
 
 type HoldRepository struct {
 	pool *pgxpool.Pool
+	cfg  repositoryConfig
 }
 
-func NewHoldRepository(pool *pgxpool.Pool) *HoldRepository {
-	return &HoldRepository{pool: pool}
+func NewHoldRepository(pool *pgxpool.Pool, opts ...RepositoryOption) *HoldRepository {
+	return &HoldRepository{pool: pool, cfg: newRepositoryConfig(opts)}
 }
 
 func (r *HoldRepository) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
-	return withTx(ctx, r.pool, fn)
+	return withTx(ctx, r.pool, r.cfg, fn)
 }
 
 func (r *HoldRepository) GetZoneForUpdate(ctx context.Context, eventID, zoneID string) (domain.Zone, error) {
-	const query = `SELECT id, event_id, name, capacity FROM zones WHERE id = $1 AND event_id = $2 FOR UPDATE`
+	const query = `
+SELECT id, event_id, name, capacity, min_quantity, max_quantity, quantity_step, purchase_limit
+FROM zones
+WHERE id = $1 AND event_id = $2
+FOR UPDATE`
 	var z domain.Zone
-	err := r.queryRow(ctx, query, zoneID, eventID).Scan(&z.ID, &z.EventID, &z.Name, &z.Capacity)
+	err := r.queryRow(ctx, query, zoneID, eventID).Scan(&z.ID, &z.EventID, &z.Name, &z.Capacity, &z.MinQuantity, &z.MaxQuantity, &z.QuantityStep,
+		&z.PurchaseLimit)
 	if err != nil {
 		if isInvalidUUID(err) {
 			return domain.Zone{}, domain.ErrInvalidID
@@ -39,15 +45,20 @@ func (r *HoldRepository) GetZoneForUpdate(ctx context.Context, eventID, zoneID s
 	return z, nil
 }
 
+func (r *HoldRepository) GetWaitingRoom(ctx context.Context, eventID string) (domain.WaitingRoom, error) {
+	return getWaitingRoom(ctx, r.queryRow, eventID, false)
+}
+
 func (r *HoldRepository) FindHoldByIdempotencyKey(ctx context.Context, eventID, zoneID, key string) (*domain.Hold, error) {
 	const query = `
-SELECT id, event_id, zone_id, quantity, status, expires_at, idempotency_key, created_at
+SELECT id, event_id, zone_id, quantity, status, expires_at, payment_pending_until, idempotency_key, customer_id, created_at
 FROM holds
 WHERE event_id = $1 AND zone_id = $2 AND idempotency_key = $3`
 
 	var h domain.Hold
+	var customerID *string
 	err := r.queryRow(ctx, query, eventID, zoneID, key).
-		Scan(&h.ID, &h.EventID, &h.ZoneID, &h.Quantity, &h.Status, &h.ExpiresAt, &h.IdempotencyKey, &h.CreatedAt)
+		Scan(&h.ID, &h.EventID, &h.ZoneID, &h.Quantity, &h.Status, &h.ExpiresAt, &h.PaymentPendingUntil, &h.IdempotencyKey, &customerID, &h.CreatedAt)
 	if err != nil {
 		if isInvalidUUID(err) {
 			return nil, domain.ErrInvalidID
@@ -57,6 +68,9 @@ WHERE event_id = $1 AND zone_id = $2 AND idempotency_key = $3`
 		}
 		return nil, fmt.Errorf("find hold by idempotency key: %w", err)
 	}
+	if customerID != nil {
+		h.CustomerID = *customerID
+	}
 	return &h, nil
 }
 
@@ -64,7 +78,8 @@ func (r *HoldRepository) SumActiveHolds(ctx context.Context, eventID, zoneID str
 	const query = `
 SELECT COALESCE(SUM(quantity), 0)
 FROM holds
-WHERE event_id = $1 AND zone_id = $2 AND status = 'active' AND expires_at > $3`
+WHERE event_id = $1 AND zone_id = $2 AND status = 'active'
+  AND (expires_at > $3 OR payment_pending_until > $3)`
 
 	var total int
 	if err := r.queryRow(ctx, query, eventID, zoneID, now).Scan(&total); err != nil {
@@ -92,10 +107,86 @@ WHERE event_id = $1 AND zone_id = $2 AND status = 'confirmed'`
 	return total, nil
 }
 
+func (r *HoldRepository) GetEventPurchaseLimit(ctx context.Context, eventID string) (int, error) {
+	var limit int
+	if err := r.queryRow(ctx, `SELECT purchase_limit FROM events WHERE id = $1`, eventID).Scan(&limit); err != nil {
+		if isInvalidUUID(err) {
+			return 0, domain.ErrInvalidID
+		}
+		if err == pgx.ErrNoRows {
+			return 0, domain.ErrEventNotFound
+		}
+		return 0, fmt.Errorf("get event purchase limit: %w", err)
+	}
+	return limit, nil
+}
+
+func (r *HoldRepository) LockCustomer(ctx context.Context, customerID string) error {
+	var id string
+	if err := r.queryRow(ctx, `SELECT id FROM customers WHERE id = $1 FOR UPDATE`, customerID).Scan(&id); err != nil {
+		if isInvalidUUID(err) {
+			return domain.ErrInvalidID
+		}
+		if err == pgx.ErrNoRows {
+			return domain.ErrCustomerNotFound
+		}
+		return fmt.Errorf("lock customer: %w", err)
+	}
+	return nil
+}
+
+func (r *HoldRepository) SumCustomerTickets(ctx context.Context, customerID, eventID, zoneID string, now time.Time) (int, int, error) {
+	const query = `
+SELECT COALESCE(SUM(quantity), 0), COALESCE(SUM(quantity) FILTER (WHERE zone_id = $3), 0)
+FROM holds
+WHERE customer_id = $1 AND event_id = $2
+  AND (status = 'confirmed'
+       OR (status = 'active' AND (expires_at > $4 OR payment_pending_until > $4)))`
+
+	var eventQty, zoneQty int
+	if err := r.queryRow(ctx, query, customerID, eventID, zoneID, now).Scan(&eventQty, &zoneQty); err != nil {
+		if isInvalidUUID(err) {
+			return 0, 0, domain.ErrInvalidID
+		}
+		return 0, 0, fmt.Errorf("sum customer tickets: %w", err)
+	}
+	return eventQty, zoneQty, nil
+}
+
+func (r *HoldRepository) LockQueueEntry(ctx context.Context, entryID string) error {
+	var id string
+	if err := r.queryRow(ctx, `SELECT id FROM queue_entries WHERE id = $1 FOR UPDATE`, entryID).Scan(&id); err != nil {
+		// The entry was deleted with its event since the token was issued.
+		if isInvalidUUID(err) || err == pgx.ErrNoRows {
+			return domain.ErrInvalidAdmissionToken
+		}
+		return fmt.Errorf("lock queue entry: %w", err)
+	}
+	return nil
+}
+
+func (r *HoldRepository) HasQueueEntryHold(ctx context.Context, entryID string, now time.Time) (bool, error) {
+	const query = `
+SELECT EXISTS (
+    SELECT 1 FROM holds
+    WHERE queue_entry_id = $1
+      AND (status = 'confirmed'
+           OR (status = 'active' AND (expires_at > $2 OR payment_pending_until > $2)))
+)`
+
+	var used bool
+	if err := r.queryRow(ctx, query, entryID, now).Scan(&used); err != nil {
+		return false, fmt.Errorf("check queue entry holds: %w", err)
+	}
+	return used, nil
+}
+
+// CreateHold copies the event's organizer onto the hold; the composite foreign
+// key on (event_id, organizer_id) keeps the two in step.
 func (r *HoldRepository) CreateHold(ctx context.Context, hold domain.Hold) error {
 	const stmt = `
-INSERT INTO holds (id, event_id, zone_id, quantity, status, expires_at, idempotency_key, created_at)
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+INSERT INTO holds (id, event_id, zone_id, quantity, status, expires_at, idempotency_key, customer_id, queue_entry_id, created_at, organizer_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, (SELECT organizer_id FROM events WHERE id = $2))`
 
 	_, err := r.exec(ctx, stmt,
 		hold.ID,
@@ -105,6 +196,8 @@ VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
 		hold.Status,
 		hold.ExpiresAt,
 		hold.IdempotencyKey,
+		nullableString(hold.CustomerID),
+		nullableString(hold.QueueEntryID),
 		hold.CreatedAt,
 	)
 	if err != nil {
@@ -119,6 +212,45 @@ VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
 	return nil
 }
 
+// ExpireHolds skips rows locked by in-flight confirmations; they are picked up
+// on a later pass.
+func (r *HoldRepository) ExpireHolds(ctx context.Context, now time.Time, limit int) ([]domain.Hold, error) {
+	const stmt = `
+UPDATE holds SET status = 'expired', payment_pending_until = NULL
+WHERE id IN (
+	SELECT id FROM holds
+	WHERE status = 'active' AND expires_at <= $1
+	  AND (payment_pending_until IS NULL OR payment_pending_until <= $1)
+	ORDER BY expires_at
+	LIMIT $2
+	FOR UPDATE SKIP LOCKED
+)
+RETURNING id, event_id, zone_id, quantity, status, expires_at, idempotency_key, created_at`
+
+	rows, err := r.query(ctx, stmt, now, limit)
+	if err != nil {
+		return nil, fmt.Errorf("expire holds: %w", err)
+	}
+	defer rows.Close()
+
+	var holds []domain.Hold
+	for rows.Next() {
+		var h domain.Hold
+		if err := rows.Scan(&h.ID, &h.EventID, &h.ZoneID, &h.Quantity, &h.Status, &h.ExpiresAt, &h.IdempotencyKey, &h.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan expired hold: %w", err)
+		}
+		holds = append(holds, h)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("expire holds: %w", err)
+	}
+	return holds, nil
+}
+
+func (r *HoldRepository) AppendOutboxEvent(ctx context.Context, event domain.OutboxEvent) error {
+	return appendOutboxEvent(ctx, r.exec, event)
+}
+
 func (r *HoldRepository) exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
 	if tx := txFromContext(ctx); tx != nil {
 		return tx.Exec(ctx, sql, args...)
@@ -126,6 +258,13 @@ func (r *HoldRepository) exec(ctx context.Context, sql string, args ...any) (pgc
 	return r.pool.Exec(ctx, sql, args...)
 }
 
+func (r *HoldRepository) query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	if tx := txFromContext(ctx); tx != nil {
+		return tx.Query(ctx, sql, args...)
+	}
+	return r.pool.Query(ctx, sql, args...)
+}
+
 func (r *HoldRepository) queryRow(ctx context.Context, sql string, args ...any) pgx.Row {
 	if tx := txFromContext(ctx); tx != nil {
 		return tx.QueryRow(ctx, sql, args...)
@@ -104,6 +104,38 @@ func TestHoldRepository(t *testing.T) {
 		}
 	})
 
+	t.Run("SumActiveHolds includes expired holds with open payment", func(t *testing.T) {
+		ctx := context.Background()
+		testutil.TruncateAll(t, ctx, pool)
+		eventID, zoneID := testutil.InsertEventAndZone(t, ctx, pool, "Concert", 100)
+		now := time.Now().UTC()
+		pendingUntil := now.Add(2 * time.Minute)
+		lapsed := now.Add(-30 * time.Second)
+
+		testutil.InsertHold(t, ctx, pool, eventID, zoneID, domain.Hold{
+			Status:              domain.HoldStatusActive,
+			Quantity:            15,
+			ExpiresAt:           now.Add(-1 * time.Minute),
+			PaymentPendingUntil: &pendingUntil,
+			IdempotencyKey:      "pending",
+		})
+		testutil.InsertHold(t, ctx, pool, eventID, zoneID, domain.Hold{
+			Status:              domain.HoldStatusActive,
+			Quantity:            20,
+			ExpiresAt:           now.Add(-2 * time.Minute),
+			PaymentPendingUntil: &lapsed,
+			IdempotencyKey:      "lapsed",
+		})
+
+		total, err := repo.SumActiveHolds(ctx, eventID, zoneID, now)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if total != 15 {
+			t.Fatalf("expected active sum 15, got %d", total)
+		}
+	})
+
 	t.Run("SumConfirmed sums confirmed only", func(t *testing.T) {
 		ctx := context.Background()
 		testutil.TruncateAll(t, ctx, pool)
@@ -131,6 +163,129 @@ func TestHoldRepository(t *testing.T) {
 		}
 	})
 
+	t.Run("purchase limits are read from the event and the zone", func(t *testing.T) {
+		ctx := context.Background()
+		testutil.TruncateAll(t, ctx, pool)
+		eventID, zoneID := testutil.InsertEventAndZone(t, ctx, pool, "Concert", 100)
+
+		limit, err := repo.GetEventPurchaseLimit(ctx, eventID)
+		if err != nil || limit != 0 {
+			t.Fatalf("expected no limit by default, got %d (%v)", limit, err)
+		}
+		if _, err := pool.Exec(ctx, `UPDATE events SET purchase_limit = 6 WHERE id = $1`, eventID); err != nil {
+			t.Fatalf("set event limit: %v", err)
+		}
+		if _, err := pool.Exec(ctx, `UPDATE zones SET purchase_limit = 4 WHERE id = $1`, zoneID); err != nil {
+			t.Fatalf("set zone limit: %v", err)
+		}
+		if limit, err := repo.GetEventPurchaseLimit(ctx, eventID); err != nil || limit != 6 {
+			t.Fatalf("expected event limit 6, got %d (%v)", limit, err)
+		}
+		zone, err := repo.GetZoneForUpdate(ctx, eventID, zoneID)
+		if err != nil || zone.PurchaseLimit != 4 {
+			t.Fatalf("expected zone limit 4, got %+v (%v)", zone, err)
+		}
+		if _, err := repo.GetEventPurchaseLimit(ctx, "00000000-0000-0000-0000-000000000001"); err != domain.ErrEventNotFound {
+			t.Fatalf("expected ErrEventNotFound, got %v", err)
+		}
+	})
+
+	t.Run("SumCustomerTickets counts the customer's reserved and bought tickets", func(t *testing.T) {
+		ctx := context.Background()
+		testutil.TruncateAll(t, ctx, pool)
+		eventID, zoneID := testutil.InsertEventAndZone(t, ctx, pool, "Concert", 100)
+		var otherZoneID string
+		if err := pool.QueryRow(ctx, `INSERT INTO zones (event_id, name, capacity) VALUES ($1, 'Balcony', 100) RETURNING id`, eventID).Scan(&otherZoneID); err != nil {
+			t.Fatalf("insert zone: %v", err)
+		}
+		customerID := "aaaaaaaa-1111-1111-1111-aaaaaaaaaaaa"
+		if _, err := pool.Exec(ctx, `INSERT INTO customers (id, email, created_at) VALUES ($1, 'buyer@example.com', now())`, customerID); err != nil {
+			t.Fatalf("insert customer: %v", err)
+		}
+		now := time.Now().UTC()
+
+		for _, h := range []struct {
+			zoneID string
+			hold   domain.Hold
+		}{
+			{zoneID, domain.Hold{Status: domain.HoldStatusActive, Quantity: 2, ExpiresAt: now.Add(5 * time.Minute), IdempotencyKey: "active"}},
+			{otherZoneID, domain.Hold{Status: domain.HoldStatusConfirmed, Quantity: 3, ExpiresAt: now.Add(-time.Hour), IdempotencyKey: "bought"}},
+			{zoneID, domain.Hold{Status: domain.HoldStatusActive, Quantity: 7, ExpiresAt: now.Add(-time.Minute), IdempotencyKey: "expired"}},
+			{zoneID, domain.Hold{Status: domain.HoldStatusReleased, Quantity: 7, ExpiresAt: now.Add(5 * time.Minute), IdempotencyKey: "released"}},
+		} {
+			id := testutil.InsertHold(t, ctx, pool, eventID, h.zoneID, h.hold)
+			if _, err := pool.Exec(ctx, `UPDATE holds SET customer_id = $1 WHERE id = $2`, customerID, id); err != nil {
+				t.Fatalf("assign hold: %v", err)
+			}
+		}
+		testutil.InsertHold(t, ctx, pool, eventID, zoneID, domain.Hold{
+			Status:         domain.HoldStatusActive,
+			Quantity:       9,
+			ExpiresAt:      now.Add(5 * time.Minute),
+			IdempotencyKey: "anonymous",
+		})
+
+		err := repo.WithTx(ctx, func(txCtx context.Context) error {
+			if err := repo.LockCustomer(txCtx, customerID); err != nil {
+				t.Fatalf("lock customer: %v", err)
+			}
+			eventQty, zoneQty, err := repo.SumCustomerTickets(txCtx, customerID, eventID, zoneID, now)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if eventQty != 5 || zoneQty != 2 {
+				t.Fatalf("expected 5 for the event and 2 for the zone, got %d and %d", eventQty, zoneQty)
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("tx failed: %v", err)
+		}
+
+		if err := repo.LockCustomer(ctx, "bbbbbbbb-2222-2222-2222-bbbbbbbbbbbb"); err != domain.ErrCustomerNotFound {
+			t.Fatalf("expected ErrCustomerNotFound, got %v", err)
+		}
+	})
+
+	t.Run("HasQueueEntryHold reports live holds of an admission", func(t *testing.T) {
+		ctx := context.Background()
+		testutil.TruncateAll(t, ctx, pool)
+		eventID, zoneID := testutil.InsertEventAndZone(t, ctx, pool, "Concert", 100)
+		now := time.Now().UTC()
+		entryID := "aaaaaaaa-0000-0000-0000-000000000001"
+		if _, err := pool.Exec(ctx, `INSERT INTO queue_entries (id, event_id, status, joined_at, admitted_at, ticket) VALUES ($1, $2, 'admitted', $3, $3, 1)`, entryID, eventID, now); err != nil {
+			t.Fatalf("insert queue entry: %v", err)
+		}
+
+		hold := domain.Hold{
+			ID: "bbbbbbbb-0000-0000-0000-000000000001", EventID: eventID, ZoneID: zoneID, Quantity: 1,
+			Status: domain.HoldStatusActive, ExpiresAt: now.Add(time.Minute), IdempotencyKey: "admitted",
+			QueueEntryID: entryID, CreatedAt: now,
+		}
+		err := repo.WithTx(ctx, func(txCtx context.Context) error {
+			if err := repo.LockQueueEntry(txCtx, entryID); err != nil {
+				return err
+			}
+			if used, err := repo.HasQueueEntryHold(txCtx, entryID, now); err != nil || used {
+				t.Fatalf("expected the admission unused, got %v (%v)", used, err)
+			}
+			return repo.CreateHold(txCtx, hold)
+		})
+		if err != nil {
+			t.Fatalf("create hold: %v", err)
+		}
+
+		if used, err := repo.HasQueueEntryHold(ctx, entryID, now); err != nil || !used {
+			t.Fatalf("expected the admission used, got %v (%v)", used, err)
+		}
+		if used, err := repo.HasQueueEntryHold(ctx, entryID, now.Add(2*time.Minute)); err != nil || used {
+			t.Fatalf("expected an expired hold to free the admission, got %v (%v)", used, err)
+		}
+		if err := repo.LockQueueEntry(ctx, "aaaaaaaa-0000-0000-0000-000000000009"); err != domain.ErrInvalidAdmissionToken {
+			t.Fatalf("expected ErrInvalidAdmissionToken for a deleted entry, got %v", err)
+		}
+	})
+
 	t.Run("CreateHold inserts row", func(t *testing.T) {
 		ctx := context.Background()
 		testutil.TruncateAll(t, ctx, pool)
@@ -0,0 +1,318 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/cimillas/ultimate-ticket/services/api/internal/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type LotteryRepository struct {
+	pool *pgxpool.Pool
+	cfg  repositoryConfig
+}
+
+func NewLotteryRepository(pool *pgxpool.Pool, opts ...RepositoryOption) *LotteryRepository {
+	return &LotteryRepository{pool: pool, cfg: newRepositoryConfig(opts)}
+}
+
+func (r *LotteryRepository) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return withTx(ctx, r.pool, r.cfg, fn)
+}
+
+func (r *LotteryRepository) GetZone(ctx context.Context, zoneID string) (domain.Zone, string, error) {
+	const query = `
+SELECT z.id, z.event_id, z.name, z.capacity, z.min_quantity, z.max_quantity, z.quantity_step, e.organizer_id
+FROM zones z
+JOIN events e ON e.id = z.event_id
+WHERE z.id = $1`
+	var z domain.Zone
+	var organizerID string
+	err := r.queryRow(ctx, query, zoneID).Scan(&z.ID, &z.EventID, &z.Name, &z.Capacity, &z.MinQuantity, &z.MaxQuantity, &z.QuantityStep, &organizerID)
+	if err != nil {
+		if isInvalidUUID(err) {
+			return domain.Zone{}, "", domain.ErrInvalidID
+		}
+		if err == pgx.ErrNoRows {
+			return domain.Zone{}, "", domain.ErrZoneNotFound
+		}
+		return domain.Zone{}, "", fmt.Errorf("get zone: %w", err)
+	}
+	return z, organizerID, nil
+}
+
+func (r *LotteryRepository) ZoneAvailable(ctx context.Context, zoneID string, now time.Time) (int, error) {
+	const query = `
+SELECT z.capacity - COALESCE((
+    SELECT SUM(h.quantity) FROM holds h
+    WHERE h.zone_id = z.id
+      AND (h.status = 'confirmed'
+           OR (h.status = 'active' AND (h.expires_at > $2 OR h.payment_pending_until > $2)))
+), 0)
+FROM zones z
+WHERE z.id = $1`
+	var available int
+	if err := r.queryRow(ctx, query, zoneID, now).Scan(&available); err != nil {
+		if err == pgx.ErrNoRows {
+			return 0, domain.ErrZoneNotFound
+		}
+		return 0, fmt.Errorf("zone availability: %w", err)
+	}
+	return available, nil
+}
+
+func (r *LotteryRepository) CreateLottery(ctx context.Context, lottery domain.Lottery) error {
+	const stmt = `
+INSERT INTO lotteries (id, event_id, zone_id, registration_opens_at, registration_closes_at, claim_window_seconds, status, seed_hash, seed, created_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`
+	_, err := r.exec(ctx, stmt, lottery.ID, lottery.EventID, lottery.ZoneID, lottery.RegistrationOpensAt,
+		lottery.RegistrationClosesAt, int(lottery.ClaimWindow/time.Second), lottery.Status, lottery.SeedHash, lottery.Seed, lottery.CreatedAt)
+	if err != nil {
+		if isInvalidUUID(err) {
+			return domain.ErrInvalidID
+		}
+		if isUniqueViolation(err) {
+			return domain.ErrLotteryExists
+		}
+		if isForeignKeyViolation(err) {
+			return domain.ErrZoneNotFound
+		}
+		return fmt.Errorf("create lottery: %w", err)
+	}
+	return nil
+}
+
+const lotteryColumns = `l.id, l.event_id, l.zone_id, e.organizer_id, l.registration_opens_at, l.registration_closes_at,
+       l.claim_window_seconds, l.status, l.seed_hash, l.seed, l.drawn_at, l.created_at`
+
+func (r *LotteryRepository) GetLottery(ctx context.Context, id string) (domain.Lottery, error) {
+	return r.getLottery(ctx, `l.id = $1`, id, false)
+}
+
+func (r *LotteryRepository) GetLotteryForUpdate(ctx context.Context, id string) (domain.Lottery, error) {
+	return r.getLottery(ctx, `l.id = $1`, id, true)
+}
+
+func (r *LotteryRepository) GetLotteryByZone(ctx context.Context, zoneID string) (*domain.Lottery, error) {
+	lottery, err := r.getLottery(ctx, `l.zone_id = $1`, zoneID, false)
+	if err == domain.ErrLotteryNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &lottery, nil
+}
+
+func (r *LotteryRepository) getLottery(ctx context.Context, where, arg string, forUpdate bool) (domain.Lottery, error) {
+	query := `SELECT ` + lotteryColumns + `
+FROM lotteries l
+JOIN events e ON e.id = l.event_id
+WHERE ` + where
+	if forUpdate {
+		query += ` FOR UPDATE OF l`
+	}
+	var l domain.Lottery
+	var claimSeconds int
+	err := r.queryRow(ctx, query, arg).Scan(&l.ID, &l.EventID, &l.ZoneID, &l.OrganizerID, &l.RegistrationOpensAt,
+		&l.RegistrationClosesAt, &claimSeconds, &l.Status, &l.SeedHash, &l.Seed, &l.DrawnAt, &l.CreatedAt)
+	if err != nil {
+		if isInvalidUUID(err) {
+			return domain.Lottery{}, domain.ErrInvalidID
+		}
+		if err == pgx.ErrNoRows {
+			return domain.Lottery{}, domain.ErrLotteryNotFound
+		}
+		return domain.Lottery{}, fmt.Errorf("get lottery: %w", err)
+	}
+	l.ClaimWindow = time.Duration(claimSeconds) * time.Second
+	return l, nil
+}
+
+func (r *LotteryRepository) UpdateLottery(ctx context.Context, lottery domain.Lottery) error {
+	const stmt = `UPDATE lotteries SET status = $2, seed = $3, drawn_at = $4 WHERE id = $1`
+	tag, err := r.exec(ctx, stmt, lottery.ID, lottery.Status, lottery.Seed, lottery.DrawnAt)
+	if err != nil {
+		return fmt.Errorf("update lottery: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrLotteryNotFound
+	}
+	return nil
+}
+
+func (r *LotteryRepository) ListRolloverLotteries(ctx context.Context) ([]string, error) {
+	const query = `
+SELECT l.id
+FROM lotteries l
+WHERE l.status = 'drawn'
+  AND EXISTS (SELECT 1 FROM lottery_ballots b WHERE b.lottery_id = l.id AND b.status IN ('offered', 'waitlisted'))`
+	rows, err := r.query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("list rollover lotteries: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scan lottery: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("iterate lotteries: %w", rows.Err())
+	}
+	return ids, nil
+}
+
+func (r *LotteryRepository) CreateBallot(ctx context.Context, ballot domain.Ballot) error {
+	const stmt = `
+INSERT INTO lottery_ballots (id, lottery_id, customer_id, quantity, status, created_at)
+VALUES ($1, $2, $3, $4, $5, $6)`
+	_, err := r.exec(ctx, stmt, ballot.ID, ballot.LotteryID, ballot.CustomerID, ballot.Quantity, ballot.Status, ballot.CreatedAt)
+	if err != nil {
+		if isInvalidUUID(err) {
+			return domain.ErrInvalidID
+		}
+		if isUniqueViolation(err) {
+			return domain.ErrBallotExists
+		}
+		if isForeignKeyViolation(err) {
+			return domain.ErrLotteryNotFound
+		}
+		return fmt.Errorf("create ballot: %w", err)
+	}
+	return nil
+}
+
+const ballotColumns = `id, lottery_id, customer_id, quantity, status, rank, offer_expires_at, hold_id, created_at`
+
+func (r *LotteryRepository) GetBallot(ctx context.Context, lotteryID, customerID string) (domain.Ballot, error) {
+	return r.getBallot(ctx, lotteryID, customerID, false)
+}
+
+func (r *LotteryRepository) GetBallotForUpdate(ctx context.Context, lotteryID, customerID string) (domain.Ballot, error) {
+	return r.getBallot(ctx, lotteryID, customerID, true)
+}
+
+func (r *LotteryRepository) getBallot(ctx context.Context, lotteryID, customerID string, forUpdate bool) (domain.Ballot, error) {
+	query := `SELECT ` + ballotColumns + ` FROM lottery_ballots WHERE lottery_id = $1 AND customer_id = $2`
+	if forUpdate {
+		query += ` FOR UPDATE`
+	}
+	ballot, err := scanBallot(r.queryRow(ctx, query, lotteryID, customerID))
+	if err != nil {
+		if isInvalidUUID(err) {
+			return domain.Ballot{}, domain.ErrInvalidID
+		}
+		if err == pgx.ErrNoRows {
+			return domain.Ballot{}, domain.ErrBallotNotFound
+		}
+		return domain.Ballot{}, fmt.Errorf("get ballot: %w", err)
+	}
+	return ballot, nil
+}
+
+func (r *LotteryRepository) ListBallots(ctx context.Context, lotteryID string, status domain.BallotStatus) ([]domain.Ballot, error) {
+	const query = `SELECT ` + ballotColumns + `
+FROM lottery_ballots
+WHERE lottery_id = $1 AND status = $2
+ORDER BY rank, created_at, id`
+	rows, err := r.query(ctx, query, lotteryID, status)
+	if err != nil {
+		return nil, fmt.Errorf("list ballots: %w", err)
+	}
+	defer rows.Close()
+
+	var ballots []domain.Ballot
+	for rows.Next() {
+		ballot, err := scanBallot(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan ballot: %w", err)
+		}
+		ballots = append(ballots, ballot)
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("iterate ballots: %w", rows.Err())
+	}
+	return ballots, nil
+}
+
+func (r *LotteryRepository) UpdateBallot(ctx context.Context, ballot domain.Ballot) error {
+	const stmt = `
+UPDATE lottery_ballots
+SET status = $2, rank = $3, offer_expires_at = $4, hold_id = $5
+WHERE id = $1`
+	tag, err := r.exec(ctx, stmt, ballot.ID, ballot.Status, ballot.Rank, ballot.OfferExpiresAt, nullableString(ballot.HoldID))
+	if err != nil {
+		return fmt.Errorf("update ballot: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrBallotNotFound
+	}
+	return nil
+}
+
+func (r *LotteryRepository) SumOffered(ctx context.Context, lotteryID string) (int, error) {
+	const query = `SELECT COALESCE(SUM(quantity), 0) FROM lottery_ballots WHERE lottery_id = $1 AND status = 'offered'`
+	var total int
+	if err := r.queryRow(ctx, query, lotteryID).Scan(&total); err != nil {
+		return 0, fmt.Errorf("sum offered: %w", err)
+	}
+	return total, nil
+}
+
+func (r *LotteryRepository) LapseOffers(ctx context.Context, lotteryID string, now time.Time) (int, error) {
+	const stmt = `
+UPDATE lottery_ballots
+SET status = 'lapsed'
+WHERE lottery_id = $1 AND status = 'offered' AND offer_expires_at <= $2`
+	tag, err := r.exec(ctx, stmt, lotteryID, now)
+	if err != nil {
+		return 0, fmt.Errorf("lapse offers: %w", err)
+	}
+	return int(tag.RowsAffected()), nil
+}
+
+func (r *LotteryRepository) AppendAuditEntry(ctx context.Context, entry domain.AuditEntry) error {
+	return appendAuditEntry(ctx, r.exec, entry)
+}
+
+func scanBallot(row pgx.Row) (domain.Ballot, error) {
+	var b domain.Ballot
+	var holdID *string
+	if err := row.Scan(&b.ID, &b.LotteryID, &b.CustomerID, &b.Quantity, &b.Status, &b.Rank, &b.OfferExpiresAt, &holdID, &b.CreatedAt); err != nil {
+		return domain.Ballot{}, err
+	}
+	if holdID != nil {
+		b.HoldID = *holdID
+	}
+	return b, nil
+}
+
+func (r *LotteryRepository) exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	if tx := txFromContext(ctx); tx != nil {
+		return tx.Exec(ctx, sql, args...)
+	}
+	return r.pool.Exec(ctx, sql, args...)
+}
+
+func (r *LotteryRepository) query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	if tx := txFromContext(ctx); tx != nil {
+		return tx.Query(ctx, sql, args...)
+	}
+	return r.pool.Query(ctx, sql, args...)
+}
+
+func (r *LotteryRepository) queryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	if tx := txFromContext(ctx); tx != nil {
+		return tx.QueryRow(ctx, sql, args...)
+	}
+	return r.pool.QueryRow(ctx, sql, args...)
+}
@@ -0,0 +1,150 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cimillas/ultimate-ticket/services/api/internal/domain"
+	"github.com/cimillas/ultimate-ticket/services/api/internal/testutil"
+)
+
+func TestLotteryRepository(t *testing.T) {
+	pool := testutil.NewTestPool(t)
+	testutil.ApplyMigrations(t, context.Background(), pool)
+	repo := NewLotteryRepository(pool)
+
+	t.Run("ballots and offers", func(t *testing.T) {
+		ctx := context.Background()
+		testutil.TruncateAll(t, ctx, pool)
+		eventID, zoneID := testutil.InsertEventAndZone(t, ctx, pool, "Concert", 10)
+		now := time.Now().UTC().Truncate(time.Microsecond)
+
+		zone, organizerID, err := repo.GetZone(ctx, zoneID)
+		if err != nil || zone.EventID != eventID || organizerID != domain.DefaultOrganizerID {
+			t.Fatalf("unexpected zone %+v of %q (%v)", zone, organizerID, err)
+		}
+
+		lottery := domain.Lottery{
+			ID:                   "bbbbbbbb-0000-0000-0000-000000000001",
+			EventID:              eventID,
+			ZoneID:               zoneID,
+			RegistrationOpensAt:  now.Add(-time.Hour),
+			RegistrationClosesAt: now,
+			ClaimWindow:          30 * time.Minute,
+			Status:               domain.LotteryOpen,
+			SeedHash:             domain.HashLotterySeed("seed"),
+			Seed:                 "seed",
+			CreatedAt:            now,
+		}
+		if err := repo.CreateLottery(ctx, lottery); err != nil {
+			t.Fatalf("create lottery: %v", err)
+		}
+		dup := lottery
+		dup.ID = "bbbbbbbb-0000-0000-0000-000000000002"
+		if err := repo.CreateLottery(ctx, dup); err != domain.ErrLotteryExists {
+			t.Fatalf("expected ErrLotteryExists, got %v", err)
+		}
+		byZone, err := repo.GetLotteryByZone(ctx, zoneID)
+		if err != nil || byZone == nil || byZone.ID != lottery.ID || byZone.ClaimWindow != 30*time.Minute || byZone.OrganizerID != domain.DefaultOrganizerID ||
+			byZone.SeedHash != lottery.SeedHash || byZone.Seed != "seed" {
+			t.Fatalf("expected the zone's lottery, got %+v (%v)", byZone, err)
+		}
+
+		customers := []string{
+			"cccccccc-0000-0000-0000-000000000001",
+			"cccccccc-0000-0000-0000-000000000002",
+		}
+		for i, customerID := range customers {
+			if _, err := pool.Exec(ctx, `INSERT INTO customers (id, email, created_at) VALUES ($1, $2, now())`, customerID, customerID+"@example.com"); err != nil {
+				t.Fatalf("insert customer: %v", err)
+			}
+			ballot := domain.Ballot{ID: customerID, LotteryID: lottery.ID, CustomerID: customerID, Quantity: i + 2, Status: domain.BallotEntered, CreatedAt: now}
+			if err := repo.CreateBallot(ctx, ballot); err != nil {
+				t.Fatalf("create ballot: %v", err)
+			}
+		}
+		if err := repo.CreateBallot(ctx, domain.Ballot{ID: "cccccccc-0000-0000-0000-000000000009", LotteryID: lottery.ID, CustomerID: customers[0], Quantity: 1, Status: domain.BallotEntered, CreatedAt: now}); err != domain.ErrBallotExists {
+			t.Fatalf("expected ErrBallotExists, got %v", err)
+		}
+
+		expired := now.Add(-time.Second)
+		err = repo.WithTx(ctx, func(txCtx context.Context) error {
+			if _, err := repo.GetLotteryForUpdate(txCtx, lottery.ID); err != nil {
+				return err
+			}
+			first, err := repo.GetBallotForUpdate(txCtx, lottery.ID, customers[0])
+			if err != nil {
+				return err
+			}
+			first.Status, first.Rank, first.OfferExpiresAt = domain.BallotOffered, 1, &expired
+			if err := repo.UpdateBallot(txCtx, first); err != nil {
+				return err
+			}
+			second, err := repo.GetBallot(txCtx, lottery.ID, customers[1])
+			if err != nil {
+				return err
+			}
+			second.Status, second.Rank = domain.BallotWaitlisted, 2
+			if err := repo.UpdateBallot(txCtx, second); err != nil {
+				return err
+			}
+			drawnAt := now
+			lottery.Status, lottery.Seed, lottery.DrawnAt = domain.LotteryDrawn, "seed", &drawnAt
+			return repo.UpdateLottery(txCtx, lottery)
+		})
+		if err != nil {
+			t.Fatalf("draw: %v", err)
+		}
+
+		offered, err := repo.SumOffered(ctx, lottery.ID)
+		if err != nil || offered != 2 {
+			t.Fatalf("expected 2 tickets offered, got %d (%v)", offered, err)
+		}
+		pending, err := repo.ListRolloverLotteries(ctx)
+		if err != nil || len(pending) != 1 || pending[0] != lottery.ID {
+			t.Fatalf("expected the lottery to need rollover, got %v (%v)", pending, err)
+		}
+		if n, err := repo.LapseOffers(ctx, lottery.ID, now); err != nil || n != 1 {
+			t.Fatalf("expected 1 lapsed offer, got %d (%v)", n, err)
+		}
+		waitlist, err := repo.ListBallots(ctx, lottery.ID, domain.BallotWaitlisted)
+		if err != nil || len(waitlist) != 1 || waitlist[0].CustomerID != customers[1] || waitlist[0].Rank != 2 {
+			t.Fatalf("expected the second ballot waitlisted, got %+v (%v)", waitlist, err)
+		}
+
+		testutil.InsertHold(t, ctx, pool, eventID, zoneID, domain.Hold{
+			Quantity:       3,
+			Status:         domain.HoldStatusActive,
+			ExpiresAt:      now.Add(time.Minute),
+			IdempotencyKey: "k1",
+		})
+		available, err := repo.ZoneAvailable(ctx, zoneID, now)
+		if err != nil || available != 7 {
+			t.Fatalf("expected 7 tickets available, got %d (%v)", available, err)
+		}
+
+		got, err := repo.GetLottery(ctx, lottery.ID)
+		if err != nil || got.Status != domain.LotteryDrawn || got.Seed != "seed" || got.DrawnAt == nil {
+			t.Fatalf("expected a drawn lottery, got %+v (%v)", got, err)
+		}
+	})
+
+	t.Run("unknown lottery and ballot", func(t *testing.T) {
+		ctx := context.Background()
+		testutil.TruncateAll(t, ctx, pool)
+
+		if _, err := repo.GetLottery(ctx, "00000000-0000-0000-0000-000000000001"); err != domain.ErrLotteryNotFound {
+			t.Fatalf("expected ErrLotteryNotFound, got %v", err)
+		}
+		if _, err := repo.GetLottery(ctx, "not-a-uuid"); err != domain.ErrInvalidID {
+			t.Fatalf("expected ErrInvalidID, got %v", err)
+		}
+		if l, err := repo.GetLotteryByZone(ctx, "00000000-0000-0000-0000-000000000001"); err != nil || l != nil {
+			t.Fatalf("expected no lottery, got %+v (%v)", l, err)
+		}
+		if _, err := repo.GetBallot(ctx, "00000000-0000-0000-0000-000000000001", "00000000-0000-0000-0000-000000000002"); err != domain.ErrBallotNotFound {
+			t.Fatalf("expected ErrBallotNotFound, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,221 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/cimillas/ultimate-ticket/services/api/internal/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type NotificationRepository struct {
+	pool *pgxpool.Pool
+	cfg  repositoryConfig
+}
+
+func NewNotificationRepository(pool *pgxpool.Pool, opts ...RepositoryOption) *NotificationRepository {
+	return &NotificationRepository{pool: pool, cfg: newRepositoryConfig(opts)}
+}
+
+func (r *NotificationRepository) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return withTx(ctx, r.pool, r.cfg, fn)
+}
+
+const orderDetailsQuery = `
+SELECT o.id, o.organizer_id, COALESCE(o.customer_email, ''), e.id, e.name, e.starts_at, z.name, h.quantity
+FROM orders o
+JOIN holds h ON h.id = o.hold_id
+JOIN events e ON e.id = h.event_id
+JOIN zones z ON z.id = h.zone_id`
+
+func (r *NotificationRepository) GetOrderNotificationDetails(ctx context.Context, orderID string) (domain.OrderNotificationDetails, error) {
+	rows, err := r.query(ctx, orderDetailsQuery+` WHERE o.id = $1`, orderID)
+	if err != nil {
+		if isInvalidUUID(err) {
+			return domain.OrderNotificationDetails{}, domain.ErrInvalidID
+		}
+		return domain.OrderNotificationDetails{}, fmt.Errorf("get order notification details: %w", err)
+	}
+	defer rows.Close()
+	details, err := scanOrderDetails(rows)
+	if err != nil {
+		if isInvalidUUID(err) {
+			return domain.OrderNotificationDetails{}, domain.ErrInvalidID
+		}
+		return domain.OrderNotificationDetails{}, err
+	}
+	if len(details) == 0 {
+		return domain.OrderNotificationDetails{}, domain.ErrOrderNotFound
+	}
+	return details[0], nil
+}
+
+func (r *NotificationRepository) ListEventRecipients(ctx context.Context, eventID string) ([]domain.OrderNotificationDetails, error) {
+	query := orderDetailsQuery + `
+WHERE h.event_id = $1 AND o.status IN ('paid', 'fulfilled') AND o.customer_email IS NOT NULL
+ORDER BY o.created_at ASC`
+
+	rows, err := r.query(ctx, query, eventID)
+	if err != nil {
+		if isInvalidUUID(err) {
+			return nil, domain.ErrInvalidID
+		}
+		return nil, fmt.Errorf("list event recipients: %w", err)
+	}
+	defer rows.Close()
+	return scanOrderDetails(rows)
+}
+
+func scanOrderDetails(rows pgx.Rows) ([]domain.OrderNotificationDetails, error) {
+	var out []domain.OrderNotificationDetails
+	for rows.Next() {
+		var d domain.OrderNotificationDetails
+		if err := rows.Scan(&d.OrderID, &d.OrganizerID, &d.Email, &d.EventID, &d.EventName, &d.StartsAt, &d.ZoneName, &d.Quantity); err != nil {
+			return nil, fmt.Errorf("scan order notification details: %w", err)
+		}
+		out = append(out, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate order notification details: %w", err)
+	}
+	return out, nil
+}
+
+func (r *NotificationRepository) CreateNotification(ctx context.Context, n domain.Notification) (bool, error) {
+	const stmt = `
+INSERT INTO notifications (id, organizer_id, kind, recipient, subject, text_body, html_body, status, attempts, next_attempt_at, dedupe_key, created_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+ON CONFLICT (dedupe_key) DO NOTHING`
+
+	tag, err := r.exec(ctx, stmt, n.ID, n.OrganizerID, n.Kind, n.Recipient, n.Subject, n.TextBody, n.HTMLBody, n.Status, n.Attempts, n.NextAttemptAt, n.DedupeKey, n.CreatedAt)
+	if err != nil {
+		return false, fmt.Errorf("create notification: %w", err)
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+const notificationColumns = `id, organizer_id, kind, recipient, subject, text_body, html_body, status, attempts, next_attempt_at, last_error, dedupe_key, created_at, sent_at`
+
+func (r *NotificationRepository) ClaimDueNotifications(ctx context.Context, now, leaseUntil time.Time, limit int) ([]domain.Notification, error) {
+	query := `
+UPDATE notifications
+SET next_attempt_at = $2
+WHERE id IN (
+	SELECT id FROM notifications
+	WHERE status = 'pending' AND next_attempt_at <= $1
+	ORDER BY next_attempt_at
+	LIMIT $3
+	FOR UPDATE SKIP LOCKED
+)
+RETURNING ` + notificationColumns
+
+	rows, err := r.query(ctx, query, now, leaseUntil, limit)
+	if err != nil {
+		return nil, fmt.Errorf("claim notifications: %w", err)
+	}
+	defer rows.Close()
+	return scanNotifications(rows)
+}
+
+func (r *NotificationRepository) GetNotificationForUpdate(ctx context.Context, id string) (domain.Notification, error) {
+	query := `SELECT ` + notificationColumns + ` FROM notifications WHERE id = $1 FOR UPDATE`
+
+	rows, err := r.query(ctx, query, id)
+	if err != nil {
+		if isInvalidUUID(err) {
+			return domain.Notification{}, domain.ErrInvalidID
+		}
+		return domain.Notification{}, fmt.Errorf("get notification: %w", err)
+	}
+	defer rows.Close()
+	notifications, err := scanNotifications(rows)
+	if err != nil {
+		if isInvalidUUID(err) {
+			return domain.Notification{}, domain.ErrInvalidID
+		}
+		return domain.Notification{}, err
+	}
+	if len(notifications) == 0 {
+		return domain.Notification{}, domain.ErrNotificationNotFound
+	}
+	return notifications[0], nil
+}
+
+func (r *NotificationRepository) UpdateNotification(ctx context.Context, n domain.Notification) error {
+	const stmt = `
+UPDATE notifications
+SET status = $2, attempts = $3, next_attempt_at = $4, last_error = $5, sent_at = $6
+WHERE id = $1`
+
+	tag, err := r.exec(ctx, stmt, n.ID, n.Status, n.Attempts, n.NextAttemptAt, nullableString(n.LastError), n.SentAt)
+	if err != nil {
+		return fmt.Errorf("update notification: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrNotificationNotFound
+	}
+	return nil
+}
+
+func (r *NotificationRepository) ListNotifications(ctx context.Context, organizerID string, status domain.NotificationStatus) ([]domain.Notification, error) {
+	query := `
+SELECT ` + notificationColumns + `
+FROM notifications
+WHERE organizer_id = $1 AND ($2 = '' OR status = $2)
+ORDER BY created_at DESC
+LIMIT 500`
+
+	rows, err := r.query(ctx, query, organizerID, string(status))
+	if err != nil {
+		if isInvalidUUID(err) {
+			return nil, domain.ErrInvalidID
+		}
+		return nil, fmt.Errorf("list notifications: %w", err)
+	}
+	defer rows.Close()
+	return scanNotifications(rows)
+}
+
+func scanNotifications(rows pgx.Rows) ([]domain.Notification, error) {
+	var notifications []domain.Notification
+	for rows.Next() {
+		var n domain.Notification
+		var kind, status string
+		var lastError *string
+		if err := rows.Scan(&n.ID, &n.OrganizerID, &kind, &n.Recipient, &n.Subject, &n.TextBody, &n.HTMLBody, &status, &n.Attempts,
+			&n.NextAttemptAt, &lastError, &n.DedupeKey, &n.CreatedAt, &n.SentAt); err != nil {
+			return nil, fmt.Errorf("scan notification: %w", err)
+		}
+		n.Kind = domain.NotificationKind(kind)
+		n.Status = domain.NotificationStatus(status)
+		if lastError != nil {
+			n.LastError = *lastError
+		}
+		notifications = append(notifications, n)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate notifications: %w", err)
+	}
+	return notifications, nil
+}
+
+func (r *NotificationRepository) AppendAuditEntry(ctx context.Context, entry domain.AuditEntry) error {
+	return appendAuditEntry(ctx, r.exec, entry)
+}
+
+func (r *NotificationRepository) exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	if tx := txFromContext(ctx); tx != nil {
+		return tx.Exec(ctx, sql, args...)
+	}
+	return r.pool.Exec(ctx, sql, args...)
+}
+
+func (r *NotificationRepository) query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	if tx := txFromContext(ctx); tx != nil {
+		return tx.Query(ctx, sql, args...)
+	}
+	return r.pool.Query(ctx, sql, args...)
+}
@@ -0,0 +1,136 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cimillas/ultimate-ticket/services/api/internal/domain"
+	"github.com/cimillas/ultimate-ticket/services/api/internal/testutil"
+)
+
+func TestNotificationRepository(t *testing.T) {
+	pool := testutil.NewTestPool(t)
+	repo := NewNotificationRepository(pool)
+	orders := NewOrderRepository(pool)
+	testutil.ApplyMigrations(t, context.Background(), pool)
+
+	t.Run("order details and event recipients", func(t *testing.T) {
+		ctx := context.Background()
+		testutil.TruncateAll(t, ctx, pool)
+		now := time.Now().UTC().Truncate(time.Microsecond)
+
+		eventID, zoneID := testutil.InsertEventAndZone(t, ctx, pool, "Concert", 10)
+		insertOrder := func(id, email string, status domain.OrderStatus) {
+			t.Helper()
+			holdID := testutil.InsertHold(t, ctx, pool, eventID, zoneID, domain.Hold{
+				Quantity:       2,
+				Status:         domain.HoldStatusConfirmed,
+				ExpiresAt:      now.Add(time.Minute),
+				IdempotencyKey: "hold-" + id,
+			})
+			err := orders.CreateOrder(ctx, domain.Order{
+				ID:             id,
+				HoldID:         holdID,
+				IdempotencyKey: "order-" + id,
+				Status:         status,
+				CustomerEmail:  email,
+				CreatedAt:      now,
+				UpdatedAt:      now,
+			})
+			if err != nil {
+				t.Fatalf("create order: %v", err)
+			}
+		}
+		insertOrder("aaaaaaaa-1111-1111-1111-aaaaaaaaaaaa", "fan@example.com", domain.OrderStatusPaid)
+		insertOrder("bbbbbbbb-2222-2222-2222-bbbbbbbbbbbb", "", domain.OrderStatusPaid)
+		insertOrder("cccccccc-3333-3333-3333-cccccccccccc", "late@example.com", domain.OrderStatusPendingPayment)
+
+		details, err := repo.GetOrderNotificationDetails(ctx, "aaaaaaaa-1111-1111-1111-aaaaaaaaaaaa")
+		if err != nil {
+			t.Fatalf("get details: %v", err)
+		}
+		if details.Email != "fan@example.com" || details.OrganizerID != domain.DefaultOrganizerID || details.EventID != eventID || details.EventName != "Concert" || details.ZoneName != "Zone A" || details.Quantity != 2 {
+			t.Fatalf("unexpected details %+v", details)
+		}
+		if _, err := repo.GetOrderNotificationDetails(ctx, "dddddddd-4444-4444-4444-dddddddddddd"); err != domain.ErrOrderNotFound {
+			t.Fatalf("expected ErrOrderNotFound, got %v", err)
+		}
+
+		recipients, err := repo.ListEventRecipients(ctx, eventID)
+		if err != nil {
+			t.Fatalf("list recipients: %v", err)
+		}
+		if len(recipients) != 1 || recipients[0].Email != "fan@example.com" {
+			t.Fatalf("expected only the paid order with an email, got %+v", recipients)
+		}
+	})
+
+	t.Run("notifications are deduplicated, claimed and updated", func(t *testing.T) {
+		ctx := context.Background()
+		testutil.TruncateAll(t, ctx, pool)
+		now := time.Now().UTC().Truncate(time.Microsecond)
+
+		n := domain.Notification{
+			ID:            "eeeeeeee-5555-5555-5555-eeeeeeeeeeee",
+			OrganizerID:   domain.DefaultOrganizerID,
+			Kind:          domain.NotificationOrderConfirmation,
+			Recipient:     "fan@example.com",
+			Subject:       "Your tickets",
+			TextBody:      "text",
+			HTMLBody:      "<p>html</p>",
+			Status:        domain.NotificationPending,
+			NextAttemptAt: now,
+			DedupeKey:     "evt-1:order-1",
+			CreatedAt:     now,
+		}
+		created, err := repo.CreateNotification(ctx, n)
+		if err != nil || !created {
+			t.Fatalf("expected notification created, got %v (%v)", created, err)
+		}
+		duplicate := n
+		duplicate.ID = "ffffffff-6666-6666-6666-ffffffffffff"
+		if created, err := repo.CreateNotification(ctx, duplicate); err != nil || created {
+			t.Fatalf("expected duplicate skipped, got %v (%v)", created, err)
+		}
+
+		claimed, err := repo.ClaimDueNotifications(ctx, now, now.Add(time.Minute), 10)
+		if err != nil || len(claimed) != 1 {
+			t.Fatalf("expected one claimed notification, got %d (%v)", len(claimed), err)
+		}
+		if again, err := repo.ClaimDueNotifications(ctx, now, now.Add(time.Minute), 10); err != nil || len(again) != 0 {
+			t.Fatalf("expected leased notification to be skipped, got %d (%v)", len(again), err)
+		}
+
+		failed := claimed[0]
+		failed.Status = domain.NotificationFailed
+		failed.Attempts = 3
+		failed.LastError = "connection refused"
+		if err := repo.UpdateNotification(ctx, failed); err != nil {
+			t.Fatalf("update: %v", err)
+		}
+
+		list, err := repo.ListNotifications(ctx, domain.DefaultOrganizerID, domain.NotificationFailed)
+		if err != nil || len(list) != 1 {
+			t.Fatalf("expected one failed notification, got %d (%v)", len(list), err)
+		}
+		if list[0].LastError != "connection refused" || list[0].Attempts != 3 {
+			t.Fatalf("unexpected notification %+v", list[0])
+		}
+		if list, err := repo.ListNotifications(ctx, domain.DefaultOrganizerID, domain.NotificationSent); err != nil || len(list) != 0 {
+			t.Fatalf("expected no sent notifications, got %d (%v)", len(list), err)
+		}
+		rival := testutil.InsertOrganizer(t, ctx, pool, "Rival")
+		if list, err := repo.ListNotifications(ctx, rival, ""); err != nil || len(list) != 0 {
+			t.Fatalf("expected no notifications for another organizer, got %d (%v)", len(list), err)
+		}
+
+		locked, err := repo.GetNotificationForUpdate(ctx, n.ID)
+		if err != nil || locked.ID != n.ID || locked.OrganizerID != domain.DefaultOrganizerID {
+			t.Fatalf("get notification: %+v (%v)", locked, err)
+		}
+		if _, err := repo.GetNotificationForUpdate(ctx, "not-a-uuid"); err != domain.ErrInvalidID {
+			t.Fatalf("expected ErrInvalidID, got %v", err)
+		}
+	})
+}
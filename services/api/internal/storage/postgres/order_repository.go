@@ -3,6 +3,7 @@ package postgres
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/cimillas/ultimate-ticket/services/api/internal/domain"
 	"github.com/jackc/pgx/v5"
@@ -12,27 +13,29 @@ import (
 
 type OrderRepository struct {
 	pool *pgxpool.Pool
+	cfg  repositoryConfig
 }
 
-func NewOrderRepository(pool *pgxpool.Pool) *OrderRepository {
-	return &OrderRepository{pool: pool}
+func NewOrderRepository(pool *pgxpool.Pool, opts ...RepositoryOption) *OrderRepository {
+	return &OrderRepository{pool: pool, cfg: newRepositoryConfig(opts)}
 }
 
 func (r *OrderRepository) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
-	return withTx(ctx, r.pool, fn)
+	return withTx(ctx, r.pool, r.cfg, fn)
 }
 
 func (r *OrderRepository) GetHoldForUpdate(ctx context.Context, holdID string) (domain.Hold, error) {
 	const query = `
-SELECT id, event_id, zone_id, quantity, status, expires_at
+SELECT id, event_id, zone_id, quantity, status, expires_at, payment_pending_until, customer_id
 FROM holds
 WHERE id = $1
 FOR UPDATE`
 
 	var h domain.Hold
 	var status string
+	var customerID *string
 	err := r.queryRow(ctx, query, holdID).
-		Scan(&h.ID, &h.EventID, &h.ZoneID, &h.Quantity, &status, &h.ExpiresAt)
+		Scan(&h.ID, &h.EventID, &h.ZoneID, &h.Quantity, &status, &h.ExpiresAt, &h.PaymentPendingUntil, &customerID)
 	if err != nil {
 		if isInvalidUUID(err) {
 			return domain.Hold{}, domain.ErrInvalidID
@@ -43,15 +46,18 @@ FOR UPDATE`
 		return domain.Hold{}, fmt.Errorf("get hold: %w", err)
 	}
 	h.Status = domain.HoldStatus(status)
+	if customerID != nil {
+		h.CustomerID = *customerID
+	}
 	return h, nil
 }
 
+const orderColumns = `id, hold_id, organizer_id, idempotency_key, status, payment_reference, customer_id, customer_email, created_at, updated_at, paid_at, fulfilled_at, failed_at, cancelled_at`
+
 func (r *OrderRepository) GetOrderByHoldID(ctx context.Context, holdID string) (*domain.Order, error) {
-	const query = `SELECT id, hold_id, idempotency_key, created_at FROM orders WHERE hold_id = $1`
+	query := `SELECT ` + orderColumns + ` FROM orders WHERE hold_id = $1`
 
-	var o domain.Order
-	err := r.queryRow(ctx, query, holdID).
-		Scan(&o.ID, &o.HoldID, &o.IdempotencyKey, &o.CreatedAt)
+	o, err := scanOrder(r.queryRow(ctx, query, holdID))
 	if err != nil {
 		if err == pgx.ErrNoRows {
 			return nil, nil
@@ -61,12 +67,40 @@ func (r *OrderRepository) GetOrderByHoldID(ctx context.Context, holdID string) (
 	return &o, nil
 }
 
+func (r *OrderRepository) GetOrderForUpdate(ctx context.Context, orderID string) (domain.Order, error) {
+	query := `SELECT ` + orderColumns + ` FROM orders WHERE id = $1 FOR UPDATE`
+
+	o, err := scanOrder(r.queryRow(ctx, query, orderID))
+	if err != nil {
+		if isInvalidUUID(err) {
+			return domain.Order{}, domain.ErrInvalidID
+		}
+		if err == pgx.ErrNoRows {
+			return domain.Order{}, domain.ErrOrderNotFound
+		}
+		return domain.Order{}, fmt.Errorf("get order for update: %w", err)
+	}
+	return o, nil
+}
+
+// CreateOrder copies the hold's organizer onto the order; the composite
+// foreign key on (hold_id, organizer_id) keeps the two in step.
 func (r *OrderRepository) CreateOrder(ctx context.Context, order domain.Order) error {
 	const stmt = `
-INSERT INTO orders (id, hold_id, idempotency_key, created_at)
-VALUES ($1, $2, $3, $4)`
+INSERT INTO orders (id, hold_id, idempotency_key, status, customer_id, customer_email, created_at, updated_at, paid_at, organizer_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, (SELECT organizer_id FROM holds WHERE id = $2))`
 
-	_, err := r.exec(ctx, stmt, order.ID, order.HoldID, order.IdempotencyKey, order.CreatedAt)
+	_, err := r.exec(ctx, stmt,
+		order.ID,
+		order.HoldID,
+		order.IdempotencyKey,
+		order.Status,
+		nullableString(order.CustomerID),
+		nullableString(order.CustomerEmail),
+		order.CreatedAt,
+		order.UpdatedAt,
+		order.PaidAt,
+	)
 	if err != nil {
 		if isUniqueViolation(err) {
 			return domain.ErrHoldAlreadyConfirmed
@@ -76,8 +110,38 @@ VALUES ($1, $2, $3, $4)`
 	return nil
 }
 
+func (r *OrderRepository) AppendAuditEntry(ctx context.Context, entry domain.AuditEntry) error {
+	return appendAuditEntry(ctx, r.exec, entry)
+}
+
+func (r *OrderRepository) UpdateOrderStatus(ctx context.Context, order domain.Order) error {
+	const stmt = `
+UPDATE orders
+SET status = $2, payment_reference = $3, updated_at = $4, paid_at = $5, fulfilled_at = $6, failed_at = $7, cancelled_at = $8
+WHERE id = $1`
+
+	tag, err := r.exec(ctx, stmt,
+		order.ID,
+		order.Status,
+		nullableString(order.PaymentReference),
+		order.UpdatedAt,
+		order.PaidAt,
+		order.FulfilledAt,
+		order.FailedAt,
+		order.CancelledAt,
+	)
+	if err != nil {
+		return fmt.Errorf("update order status: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrOrderNotFound
+	}
+	return nil
+}
+
 func (r *OrderRepository) UpdateHoldStatus(ctx context.Context, holdID string, status domain.HoldStatus) error {
-	const stmt = `UPDATE holds SET status = $2 WHERE id = $1`
+	// Any status change settles the payment attempt, so the grace marker is cleared.
+	const stmt = `UPDATE holds SET status = $2, payment_pending_until = NULL WHERE id = $1`
 
 	tag, err := r.exec(ctx, stmt, holdID, status)
 	if err != nil {
@@ -89,6 +153,23 @@ func (r *OrderRepository) UpdateHoldStatus(ctx context.Context, holdID string, s
 	return nil
 }
 
+func (r *OrderRepository) MarkHoldPaymentPending(ctx context.Context, holdID string, until time.Time) error {
+	const stmt = `UPDATE holds SET payment_pending_until = $2 WHERE id = $1`
+
+	tag, err := r.exec(ctx, stmt, holdID, until)
+	if err != nil {
+		return fmt.Errorf("mark hold payment pending: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrHoldNotFound
+	}
+	return nil
+}
+
+func (r *OrderRepository) AppendOutboxEvent(ctx context.Context, event domain.OutboxEvent) error {
+	return appendOutboxEvent(ctx, r.exec, event)
+}
+
 func (r *OrderRepository) exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
 	if tx := txFromContext(ctx); tx != nil {
 		return tx.Exec(ctx, sql, args...)
@@ -102,3 +183,46 @@ func (r *OrderRepository) queryRow(ctx context.Context, sql string, args ...any)
 	}
 	return r.pool.QueryRow(ctx, sql, args...)
 }
+
+func scanOrder(row pgx.Row) (domain.Order, error) {
+	var o domain.Order
+	var status string
+	var paymentRef, customerID, email *string
+	err := row.Scan(
+		&o.ID,
+		&o.HoldID,
+		&o.OrganizerID,
+		&o.IdempotencyKey,
+		&status,
+		&paymentRef,
+		&customerID,
+		&email,
+		&o.CreatedAt,
+		&o.UpdatedAt,
+		&o.PaidAt,
+		&o.FulfilledAt,
+		&o.FailedAt,
+		&o.CancelledAt,
+	)
+	if err != nil {
+		return domain.Order{}, err
+	}
+	o.Status = domain.OrderStatus(status)
+	if paymentRef != nil {
+		o.PaymentReference = *paymentRef
+	}
+	if customerID != nil {
+		o.CustomerID = *customerID
+	}
+	if email != nil {
+		o.CustomerEmail = *email
+	}
+	return o, nil
+}
+
+func nullableString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
@@ -71,6 +71,7 @@ func TestOrderRepository(t *testing.T) {
 			ID:             "bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb",
 			HoldID:         holdID,
 			IdempotencyKey: "idem-order",
+			Status:         domain.OrderStatusPaid,
 			CreatedAt:      time.Now().UTC(),
 		}
 
@@ -88,11 +89,37 @@ func TestOrderRepository(t *testing.T) {
 		if got == nil {
 			t.Fatalf("expected order, got nil")
 		}
-		if got.HoldID != order.HoldID || got.IdempotencyKey != order.IdempotencyKey {
+		if got.HoldID != order.HoldID || got.IdempotencyKey != order.IdempotencyKey || got.OrganizerID != domain.DefaultOrganizerID {
 			t.Fatalf("unexpected order: %+v", got)
 		}
 	})
 
+	t.Run("AppendAuditEntry records order changes", func(t *testing.T) {
+		ctx := context.Background()
+		testutil.TruncateAll(t, ctx, pool)
+
+		entry := domain.AuditEntry{
+			ID:          "aaaaaaaa-0000-0000-0000-000000000001",
+			OrganizerID: domain.DefaultOrganizerID,
+			Actor:       domain.AuditActorSystem,
+			Action:      domain.AuditOrderCancelled,
+			TargetType:  domain.AuditTargetOrder,
+			TargetID:    "bbbbbbbb-0000-0000-0000-000000000001",
+			Before:      []byte(`{"status":"paid"}`),
+			After:       []byte(`{"status":"cancelled"}`),
+			CreatedAt:   time.Now().UTC(),
+		}
+		if err := repo.WithTx(ctx, func(txCtx context.Context) error {
+			return repo.AppendAuditEntry(txCtx, entry)
+		}); err != nil {
+			t.Fatalf("append audit entry: %v", err)
+		}
+		got, err := NewAuditRepository(pool).ListAuditEntries(ctx, domain.AuditFilter{OrganizerID: domain.DefaultOrganizerID, TargetType: domain.AuditTargetOrder, Limit: 10})
+		if err != nil || len(got) != 1 || got[0].Action != domain.AuditOrderCancelled {
+			t.Fatalf("expected the order audit entry, got %+v (%v)", got, err)
+		}
+	})
+
 	t.Run("UpdateHoldStatus updates status", func(t *testing.T) {
 		ctx := context.Background()
 		testutil.TruncateAll(t, ctx, pool)
@@ -119,4 +146,59 @@ func TestOrderRepository(t *testing.T) {
 			t.Fatalf("expected status confirmed, got %s", status)
 		}
 	})
+
+	t.Run("UpdateOrderStatus persists status and timestamps", func(t *testing.T) {
+		ctx := context.Background()
+		testutil.TruncateAll(t, ctx, pool)
+		eventID, zoneID := testutil.InsertEventAndZone(t, ctx, pool, "Concert", 100)
+		holdID := testutil.InsertHold(t, ctx, pool, eventID, zoneID, domain.Hold{
+			Status:         domain.HoldStatusActive,
+			Quantity:       1,
+			ExpiresAt:      time.Now().Add(5 * time.Minute),
+			IdempotencyKey: "idem-hold",
+		})
+
+		now := time.Now().UTC().Truncate(time.Microsecond)
+		order := domain.Order{
+			ID:             "cccccccccccccccccccccccccccccccc",
+			HoldID:         holdID,
+			IdempotencyKey: "idem-order",
+			Status:         domain.OrderStatusPendingPayment,
+			CreatedAt:      now,
+			UpdatedAt:      now,
+		}
+		if err := repo.CreateOrder(ctx, order); err != nil {
+			t.Fatalf("create order: %v", err)
+		}
+
+		err := repo.WithTx(ctx, func(txCtx context.Context) error {
+			locked, err := repo.GetOrderForUpdate(txCtx, "cccccccc-cccc-cccc-cccc-cccccccccccc")
+			if err != nil {
+				return err
+			}
+			if locked.Status != domain.OrderStatusPendingPayment {
+				t.Fatalf("expected pending_payment, got %s", locked.Status)
+			}
+			if err := locked.TransitionTo(domain.OrderStatusPaid, now.Add(time.Minute)); err != nil {
+				return err
+			}
+			return repo.UpdateOrderStatus(txCtx, locked)
+		})
+		if err != nil {
+			t.Fatalf("transition order: %v", err)
+		}
+
+		got, err := repo.GetOrderByHoldID(ctx, holdID)
+		if err != nil {
+			t.Fatalf("get order: %v", err)
+		}
+		if got == nil || got.Status != domain.OrderStatusPaid || got.PaidAt == nil || !got.PaidAt.Equal(now.Add(time.Minute)) {
+			t.Fatalf("unexpected order: %+v", got)
+		}
+
+		_, err = repo.GetOrderForUpdate(ctx, "00000000-0000-0000-0000-000000000001")
+		if err != domain.ErrOrderNotFound {
+			t.Fatalf("expected ErrOrderNotFound, got %v", err)
+		}
+	})
 }
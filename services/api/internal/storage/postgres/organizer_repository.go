@@ -0,0 +1,45 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cimillas/ultimate-ticket/services/api/internal/domain"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type OrganizerRepository struct {
+	pool *pgxpool.Pool
+	cfg  repositoryConfig
+}
+
+func NewOrganizerRepository(pool *pgxpool.Pool, opts ...RepositoryOption) *OrganizerRepository {
+	return &OrganizerRepository{pool: pool, cfg: newRepositoryConfig(opts)}
+}
+
+func (r *OrganizerRepository) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return withTx(ctx, r.pool, r.cfg, fn)
+}
+
+func (r *OrganizerRepository) CreateOrganizer(ctx context.Context, organizer domain.Organizer) error {
+	const stmt = `INSERT INTO organizers (id, name, created_at) VALUES ($1, $2, $3)`
+	if _, err := r.exec(ctx, stmt, organizer.ID, organizer.Name, organizer.CreatedAt); err != nil {
+		if isInvalidUUID(err) {
+			return domain.ErrInvalidID
+		}
+		return fmt.Errorf("create organizer: %w", err)
+	}
+	return nil
+}
+
+func (r *OrganizerRepository) AppendAuditEntry(ctx context.Context, entry domain.AuditEntry) error {
+	return appendAuditEntry(ctx, r.exec, entry)
+}
+
+func (r *OrganizerRepository) exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	if tx := txFromContext(ctx); tx != nil {
+		return tx.Exec(ctx, sql, args...)
+	}
+	return r.pool.Exec(ctx, sql, args...)
+}
@@ -0,0 +1,175 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/cimillas/ultimate-ticket/services/api/internal/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// outboxRelayLockID serializes claims across API instances so events of an
+// aggregate are never claimed concurrently or out of order.
+const outboxRelayLockID int64 = 801234569
+
+const insertOutboxEvent = `
+INSERT INTO outbox (id, aggregate_type, aggregate_id, type, payload, created_at, available_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7)`
+
+type execFunc func(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+
+// appendOutboxEvent writes event with the caller's exec helper so it joins the
+// transaction carried by ctx.
+func appendOutboxEvent(ctx context.Context, exec execFunc, event domain.OutboxEvent) error {
+	_, err := exec(ctx, insertOutboxEvent,
+		event.ID,
+		event.AggregateType,
+		event.AggregateID,
+		event.Type,
+		event.Payload,
+		event.CreatedAt,
+		event.AvailableAt,
+	)
+	if err != nil {
+		return fmt.Errorf("append outbox event: %w", err)
+	}
+	return nil
+}
+
+type OutboxRepository struct {
+	pool *pgxpool.Pool
+	cfg  repositoryConfig
+}
+
+func NewOutboxRepository(pool *pgxpool.Pool, opts ...RepositoryOption) *OutboxRepository {
+	return &OutboxRepository{pool: pool, cfg: newRepositoryConfig(opts)}
+}
+
+func (r *OutboxRepository) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return withTx(ctx, r.pool, r.cfg, fn)
+}
+
+// ClaimOutboxEvents takes a transaction-scoped advisory lock, so it must run
+// inside WithTx; the lock is released on commit. Events behind a pending event
+// of the same aggregate that is not due yet are left out to keep the order.
+// Claimed events become due at leaseUntil, which also holds back the rest of
+// their aggregate until they are published, retried or released.
+func (r *OutboxRepository) ClaimOutboxEvents(ctx context.Context, now, leaseUntil time.Time, limit int) ([]domain.OutboxEvent, bool, error) {
+	var owned bool
+	if err := r.queryRow(ctx, `SELECT pg_try_advisory_xact_lock($1)`, outboxRelayLockID).Scan(&owned); err != nil {
+		return nil, false, fmt.Errorf("lock outbox: %w", err)
+	}
+	if !owned {
+		return nil, false, nil
+	}
+
+	const query = `
+WITH claimed AS (
+  UPDATE outbox SET available_at = $3
+  WHERE seq IN (
+    SELECT o.seq FROM outbox o
+    WHERE o.status = 'pending'
+      AND o.available_at <= $2
+      AND NOT EXISTS (
+        SELECT 1 FROM outbox e
+        WHERE e.status = 'pending'
+          AND e.aggregate_type = o.aggregate_type
+          AND e.aggregate_id = o.aggregate_id
+          AND e.seq < o.seq
+          AND e.available_at > $2
+      )
+    ORDER BY o.seq
+    LIMIT $1
+  )
+  RETURNING seq, id, aggregate_type, aggregate_id, type, payload, created_at, available_at, attempts, last_error
+)
+SELECT * FROM claimed ORDER BY seq`
+
+	rows, err := r.query(ctx, query, limit, now, leaseUntil)
+	if err != nil {
+		return nil, false, fmt.Errorf("claim outbox events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []domain.OutboxEvent
+	for rows.Next() {
+		var e domain.OutboxEvent
+		var eventType string
+		var lastError *string
+		if err := rows.Scan(&e.Seq, &e.ID, &e.AggregateType, &e.AggregateID, &eventType, &e.Payload,
+			&e.CreatedAt, &e.AvailableAt, &e.Attempts, &lastError); err != nil {
+			return nil, false, fmt.Errorf("scan outbox event: %w", err)
+		}
+		e.Type = domain.OutboxEventType(eventType)
+		if lastError != nil {
+			e.LastError = *lastError
+		}
+		events = append(events, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, false, fmt.Errorf("claim outbox events: %w", err)
+	}
+	return events, true, nil
+}
+
+func (r *OutboxRepository) MarkOutboxPublished(ctx context.Context, seq int64, at time.Time) error {
+	const stmt = `UPDATE outbox SET status = 'published', published_at = $2, last_error = NULL WHERE seq = $1`
+
+	if _, err := r.exec(ctx, stmt, seq, at); err != nil {
+		return fmt.Errorf("mark outbox published: %w", err)
+	}
+	return nil
+}
+
+func (r *OutboxRepository) MarkOutboxFailed(ctx context.Context, seq int64, lastError string, availableAt time.Time) error {
+	const stmt = `UPDATE outbox SET attempts = attempts + 1, last_error = $2, available_at = $3 WHERE seq = $1`
+
+	if _, err := r.exec(ctx, stmt, seq, lastError, availableAt); err != nil {
+		return fmt.Errorf("mark outbox failed: %w", err)
+	}
+	return nil
+}
+
+// MarkOutboxDead records the last failed attempt and stops retrying the event.
+func (r *OutboxRepository) MarkOutboxDead(ctx context.Context, seq int64, lastError string) error {
+	const stmt = `UPDATE outbox SET status = 'dead', attempts = attempts + 1, last_error = $2 WHERE seq = $1`
+
+	if _, err := r.exec(ctx, stmt, seq, lastError); err != nil {
+		return fmt.Errorf("mark outbox dead: %w", err)
+	}
+	return nil
+}
+
+// ReleaseOutboxEvent makes a claimed event due again at availableAt.
+func (r *OutboxRepository) ReleaseOutboxEvent(ctx context.Context, seq int64, availableAt time.Time) error {
+	const stmt = `UPDATE outbox SET available_at = $2 WHERE seq = $1 AND status = 'pending'`
+
+	if _, err := r.exec(ctx, stmt, seq, availableAt); err != nil {
+		return fmt.Errorf("release outbox event: %w", err)
+	}
+	return nil
+}
+
+func (r *OutboxRepository) exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	if tx := txFromContext(ctx); tx != nil {
+		return tx.Exec(ctx, sql, args...)
+	}
+	return r.pool.Exec(ctx, sql, args...)
+}
+
+func (r *OutboxRepository) query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	if tx := txFromContext(ctx); tx != nil {
+		return tx.Query(ctx, sql, args...)
+	}
+	return r.pool.Query(ctx, sql, args...)
+}
+
+func (r *OutboxRepository) queryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	if tx := txFromContext(ctx); tx != nil {
+		return tx.QueryRow(ctx, sql, args...)
+	}
+	return r.pool.QueryRow(ctx, sql, args...)
+}
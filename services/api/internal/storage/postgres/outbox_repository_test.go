@@ -0,0 +1,209 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cimillas/ultimate-ticket/services/api/internal/domain"
+	"github.com/cimillas/ultimate-ticket/services/api/internal/testutil"
+)
+
+func TestOutboxRepository(t *testing.T) {
+	pool := testutil.NewTestPool(t)
+	repo := NewOutboxRepository(pool)
+	holds := NewHoldRepository(pool)
+	testutil.ApplyMigrations(t, context.Background(), pool)
+
+	t.Run("events join the caller's transaction", func(t *testing.T) {
+		ctx := context.Background()
+		testutil.TruncateAll(t, ctx, pool)
+		now := time.Now().UTC()
+		event := domain.OutboxEvent{
+			ID:            "eeeeeeee-eeee-eeee-eeee-eeeeeeeeeeee",
+			AggregateType: domain.OutboxAggregateHold,
+			AggregateID:   "hold-1",
+			Type:          domain.OutboxHoldCreated,
+			Payload:       []byte(`{"hold_id":"hold-1"}`),
+			CreatedAt:     now,
+			AvailableAt:   now,
+		}
+
+		rollback := holds.WithTx(ctx, func(txCtx context.Context) error {
+			if err := holds.AppendOutboxEvent(txCtx, event); err != nil {
+				t.Fatalf("append: %v", err)
+			}
+			return domain.ErrHoldExpired
+		})
+		if rollback != domain.ErrHoldExpired {
+			t.Fatalf("expected rollback error, got %v", rollback)
+		}
+		if err := holds.AppendOutboxEvent(ctx, event); err != nil {
+			t.Fatalf("append: %v", err)
+		}
+
+		var claimed []domain.OutboxEvent
+		err := repo.WithTx(ctx, func(txCtx context.Context) error {
+			var owned bool
+			var err error
+			claimed, owned, err = repo.ClaimOutboxEvents(txCtx, now, now, 10)
+			if err == nil && !owned {
+				t.Fatalf("expected relay to own the outbox")
+			}
+			return err
+		})
+		if err != nil {
+			t.Fatalf("claim: %v", err)
+		}
+		if len(claimed) != 1 || claimed[0].ID != event.ID || claimed[0].Type != domain.OutboxHoldCreated {
+			t.Fatalf("expected only the committed event, got %+v", claimed)
+		}
+
+		if err := repo.MarkOutboxFailed(ctx, claimed[0].Seq, "boom", now.Add(time.Minute)); err != nil {
+			t.Fatalf("mark failed: %v", err)
+		}
+		if err := repo.MarkOutboxPublished(ctx, claimed[0].Seq, now); err != nil {
+			t.Fatalf("mark published: %v", err)
+		}
+		var attempts int
+		var pending bool
+		if err := pool.QueryRow(ctx, `SELECT attempts, published_at IS NULL FROM outbox WHERE seq = $1`, claimed[0].Seq).Scan(&attempts, &pending); err != nil {
+			t.Fatalf("query outbox: %v", err)
+		}
+		if attempts != 1 || pending {
+			t.Fatalf("expected 1 attempt and published, got %d pending=%v", attempts, pending)
+		}
+	})
+
+	t.Run("claims only due events and keeps each aggregate in order", func(t *testing.T) {
+		ctx := context.Background()
+		testutil.TruncateAll(t, ctx, pool)
+		now := time.Now().UTC()
+		ids := []string{
+			"aaaaaaaa-0000-0000-0000-000000000001",
+			"aaaaaaaa-0000-0000-0000-000000000002",
+			"aaaaaaaa-0000-0000-0000-000000000003",
+			"aaaaaaaa-0000-0000-0000-000000000004",
+		}
+		aggregates := []string{"order-1", "order-1", "order-2", "order-3"}
+		for i, id := range ids {
+			err := holds.AppendOutboxEvent(ctx, domain.OutboxEvent{
+				ID: id, AggregateType: domain.OutboxAggregateOrder, AggregateID: aggregates[i], Type: domain.OutboxOrderPaid,
+				Payload: []byte(`{}`), CreatedAt: now, AvailableAt: now,
+			})
+			if err != nil {
+				t.Fatalf("append: %v", err)
+			}
+		}
+		claim := func(at, leaseUntil time.Time) []string {
+			t.Helper()
+			var claimed []domain.OutboxEvent
+			err := repo.WithTx(ctx, func(txCtx context.Context) error {
+				var err error
+				claimed, _, err = repo.ClaimOutboxEvents(txCtx, at, leaseUntil, 10)
+				return err
+			})
+			if err != nil {
+				t.Fatalf("claim: %v", err)
+			}
+			var got []string
+			for _, e := range claimed {
+				got = append(got, e.ID)
+			}
+			return got
+		}
+		seqOf := func(id string) int64 {
+			var seq int64
+			if err := pool.QueryRow(ctx, `SELECT seq FROM outbox WHERE id = $1`, id).Scan(&seq); err != nil {
+				t.Fatalf("query seq: %v", err)
+			}
+			return seq
+		}
+
+		if err := repo.MarkOutboxFailed(ctx, seqOf(ids[0]), "boom", now.Add(time.Minute)); err != nil {
+			t.Fatalf("mark failed: %v", err)
+		}
+		if got := claim(now, now); len(got) != 2 || got[0] != ids[2] || got[1] != ids[3] {
+			t.Fatalf("expected only the other aggregates' events, got %v", got)
+		}
+		later := now.Add(2 * time.Minute)
+		if got := claim(later, later); len(got) != 4 {
+			t.Fatalf("expected every event once the retry is due, got %v", got)
+		}
+
+		if err := repo.MarkOutboxDead(ctx, seqOf(ids[0]), "poisoned"); err != nil {
+			t.Fatalf("mark dead: %v", err)
+		}
+		if got := claim(later, later); len(got) != 3 || got[0] != ids[1] {
+			t.Fatalf("expected a dead event to stop holding back its aggregate, got %v", got)
+		}
+	})
+
+	t.Run("claimed events are leased until published or released", func(t *testing.T) {
+		ctx := context.Background()
+		testutil.TruncateAll(t, ctx, pool)
+		now := time.Now().UTC().Truncate(time.Microsecond)
+		for i, id := range []string{"bbbbbbbb-0000-0000-0000-000000000001", "bbbbbbbb-0000-0000-0000-000000000002"} {
+			err := holds.AppendOutboxEvent(ctx, domain.OutboxEvent{
+				ID: id, AggregateType: domain.OutboxAggregateOrder, AggregateID: "order-1", Type: domain.OutboxOrderPaid,
+				Payload: []byte(`{}`), CreatedAt: now, AvailableAt: now.Add(time.Duration(i) * time.Millisecond),
+			})
+			if err != nil {
+				t.Fatalf("append: %v", err)
+			}
+		}
+		claim := func(limit int) []domain.OutboxEvent {
+			t.Helper()
+			var claimed []domain.OutboxEvent
+			err := repo.WithTx(ctx, func(txCtx context.Context) error {
+				var err error
+				claimed, _, err = repo.ClaimOutboxEvents(txCtx, now.Add(time.Second), now.Add(time.Minute), limit)
+				return err
+			})
+			if err != nil {
+				t.Fatalf("claim: %v", err)
+			}
+			return claimed
+		}
+
+		first := claim(1)
+		if len(first) != 1 || !first[0].AvailableAt.Equal(now.Add(time.Minute)) {
+			t.Fatalf("expected the first event leased for a minute, got %+v", first)
+		}
+		if got := claim(10); len(got) != 0 {
+			t.Fatalf("expected a leased event to hold back its aggregate, got %+v", got)
+		}
+		if err := repo.ReleaseOutboxEvent(ctx, first[0].Seq, now); err != nil {
+			t.Fatalf("release: %v", err)
+		}
+		if got := claim(10); len(got) != 2 || got[0].Seq != first[0].Seq {
+			t.Fatalf("expected the released event to be claimed again in order, got %+v", got)
+		}
+	})
+
+	t.Run("ExpireHolds expires lapsed holds outside the payment grace window", func(t *testing.T) {
+		ctx := context.Background()
+		testutil.TruncateAll(t, ctx, pool)
+		eventID, zoneID := testutil.InsertEventAndZone(t, ctx, pool, "Concert", 100)
+		now := time.Now().UTC()
+		pendingUntil := now.Add(time.Minute)
+
+		lapsed := testutil.InsertHold(t, ctx, pool, eventID, zoneID, domain.Hold{
+			Status: domain.HoldStatusActive, Quantity: 1, ExpiresAt: now.Add(-time.Minute), IdempotencyKey: "lapsed",
+		})
+		testutil.InsertHold(t, ctx, pool, eventID, zoneID, domain.Hold{
+			Status: domain.HoldStatusActive, Quantity: 1, ExpiresAt: now.Add(-time.Minute), PaymentPendingUntil: &pendingUntil, IdempotencyKey: "paying",
+		})
+		testutil.InsertHold(t, ctx, pool, eventID, zoneID, domain.Hold{
+			Status: domain.HoldStatusActive, Quantity: 1, ExpiresAt: now.Add(time.Minute), IdempotencyKey: "live",
+		})
+
+		expired, err := holds.ExpireHolds(ctx, now, 10)
+		if err != nil {
+			t.Fatalf("expire holds: %v", err)
+		}
+		if len(expired) != 1 || expired[0].ID != lapsed || expired[0].Status != domain.HoldStatusExpired {
+			t.Fatalf("expected only %s expired, got %+v", lapsed, expired)
+		}
+	})
+}
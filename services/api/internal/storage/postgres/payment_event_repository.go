@@ -0,0 +1,74 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/cimillas/ultimate-ticket/services/api/internal/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type PaymentEventRepository struct {
+	pool *pgxpool.Pool
+	cfg  repositoryConfig
+}
+
+func NewPaymentEventRepository(pool *pgxpool.Pool, opts ...RepositoryOption) *PaymentEventRepository {
+	return &PaymentEventRepository{pool: pool, cfg: newRepositoryConfig(opts)}
+}
+
+func (r *PaymentEventRepository) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return withTx(ctx, r.pool, r.cfg, fn)
+}
+
+func (r *PaymentEventRepository) RecordPaymentEvent(ctx context.Context, event domain.PaymentEvent, receivedAt time.Time) (bool, error) {
+	const stmt = `
+INSERT INTO payment_events (id, type, outcome, received_at)
+VALUES ($1, $2, 'received', $3)
+ON CONFLICT (id) DO NOTHING`
+
+	tag, err := r.exec(ctx, stmt, event.ID, event.Type, receivedAt)
+	if err != nil {
+		return false, fmt.Errorf("record payment event: %w", err)
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+func (r *PaymentEventRepository) SetPaymentEventOutcome(ctx context.Context, eventID, orderID string, outcome domain.PaymentEventOutcome) error {
+	const stmt = `UPDATE payment_events SET order_id = $2, outcome = $3 WHERE id = $1`
+
+	if _, err := r.exec(ctx, stmt, eventID, orderID, outcome); err != nil {
+		return fmt.Errorf("set payment event outcome: %w", err)
+	}
+	return nil
+}
+
+func (r *PaymentEventRepository) FindOrderIDByPaymentReference(ctx context.Context, reference string) (string, error) {
+	const query = `SELECT id FROM orders WHERE payment_reference = $1`
+
+	var id string
+	if err := r.queryRow(ctx, query, reference).Scan(&id); err != nil {
+		if err == pgx.ErrNoRows {
+			return "", domain.ErrOrderNotFound
+		}
+		return "", fmt.Errorf("find order by payment reference: %w", err)
+	}
+	return id, nil
+}
+
+func (r *PaymentEventRepository) exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	if tx := txFromContext(ctx); tx != nil {
+		return tx.Exec(ctx, sql, args...)
+	}
+	return r.pool.Exec(ctx, sql, args...)
+}
+
+func (r *PaymentEventRepository) queryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	if tx := txFromContext(ctx); tx != nil {
+		return tx.QueryRow(ctx, sql, args...)
+	}
+	return r.pool.QueryRow(ctx, sql, args...)
+}
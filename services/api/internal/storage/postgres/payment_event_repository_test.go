@@ -0,0 +1,92 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cimillas/ultimate-ticket/services/api/internal/domain"
+	"github.com/cimillas/ultimate-ticket/services/api/internal/testutil"
+)
+
+func TestPaymentEventRepository(t *testing.T) {
+	pool := testutil.NewTestPool(t)
+	repo := NewPaymentEventRepository(pool)
+	orders := NewOrderRepository(pool)
+	testutil.ApplyMigrations(t, context.Background(), pool)
+
+	t.Run("RecordPaymentEvent deduplicates by id", func(t *testing.T) {
+		ctx := context.Background()
+		testutil.TruncateAll(t, ctx, pool)
+		event := domain.PaymentEvent{ID: "evt_1", Type: domain.PaymentEventCaptured}
+
+		recorded, err := repo.RecordPaymentEvent(ctx, event, time.Now().UTC())
+		if err != nil {
+			t.Fatalf("record: %v", err)
+		}
+		if !recorded {
+			t.Fatalf("expected first delivery to be recorded")
+		}
+		recorded, err = repo.RecordPaymentEvent(ctx, event, time.Now().UTC())
+		if err != nil {
+			t.Fatalf("record duplicate: %v", err)
+		}
+		if recorded {
+			t.Fatalf("expected duplicate delivery to be skipped")
+		}
+	})
+
+	t.Run("FindOrderIDByPaymentReference resolves orders", func(t *testing.T) {
+		ctx := context.Background()
+		testutil.TruncateAll(t, ctx, pool)
+		eventID, zoneID := testutil.InsertEventAndZone(t, ctx, pool, "Concert", 100)
+		holdID := testutil.InsertHold(t, ctx, pool, eventID, zoneID, domain.Hold{
+			Status:         domain.HoldStatusActive,
+			Quantity:       1,
+			ExpiresAt:      time.Now().Add(5 * time.Minute),
+			IdempotencyKey: "idem-hold",
+		})
+		now := time.Now().UTC()
+		order := domain.Order{
+			ID:             "dddddddd-dddd-dddd-dddd-dddddddddddd",
+			HoldID:         holdID,
+			IdempotencyKey: "idem-order",
+			Status:         domain.OrderStatusPendingPayment,
+			CreatedAt:      now,
+			UpdatedAt:      now,
+		}
+		if err := orders.CreateOrder(ctx, order); err != nil {
+			t.Fatalf("create order: %v", err)
+		}
+		order.PaymentReference = "ref_1"
+		if err := orders.UpdateOrderStatus(ctx, order); err != nil {
+			t.Fatalf("update order: %v", err)
+		}
+
+		id, err := repo.FindOrderIDByPaymentReference(ctx, "ref_1")
+		if err != nil {
+			t.Fatalf("find order: %v", err)
+		}
+		if id != order.ID {
+			t.Fatalf("expected order %s, got %s", order.ID, id)
+		}
+
+		if _, err := repo.FindOrderIDByPaymentReference(ctx, "missing"); err != domain.ErrOrderNotFound {
+			t.Fatalf("expected ErrOrderNotFound, got %v", err)
+		}
+
+		if _, err := repo.RecordPaymentEvent(ctx, domain.PaymentEvent{ID: "evt_2", Type: domain.PaymentEventCaptured}, now); err != nil {
+			t.Fatalf("record: %v", err)
+		}
+		if err := repo.SetPaymentEventOutcome(ctx, "evt_2", order.ID, domain.PaymentEventProcessed); err != nil {
+			t.Fatalf("set outcome: %v", err)
+		}
+		var outcome string
+		if err := pool.QueryRow(ctx, `SELECT outcome FROM payment_events WHERE id = 'evt_2'`).Scan(&outcome); err != nil {
+			t.Fatalf("query outcome: %v", err)
+		}
+		if outcome != string(domain.PaymentEventProcessed) {
+			t.Fatalf("expected processed, got %s", outcome)
+		}
+	})
+}
@@ -0,0 +1,80 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/cimillas/ultimate-ticket/services/api/internal/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// RateLimitStore keeps token buckets in Postgres so every API instance
+// spends from the same bucket.
+type RateLimitStore struct {
+	pool *pgxpool.Pool
+	cfg  repositoryConfig
+}
+
+func NewRateLimitStore(pool *pgxpool.Pool, opts ...RepositoryOption) *RateLimitStore {
+	return &RateLimitStore{pool: pool, cfg: newRepositoryConfig(opts)}
+}
+
+func (s *RateLimitStore) Take(ctx context.Context, key string, limit domain.RateLimit, now time.Time) (time.Duration, error) {
+	var wait time.Duration
+	err := withTx(ctx, s.pool, s.cfg, func(txCtx context.Context) error {
+		// Create the bucket full first so concurrent first requests queue on
+		// the same row lock instead of each starting from a full bucket.
+		full := limit.FullBucket(now)
+		const insert = `
+INSERT INTO rate_limit_buckets (key, tokens, updated_at, full_at)
+VALUES ($1, $2, $3, $3)
+ON CONFLICT (key) DO NOTHING`
+		if _, err := s.exec(txCtx, insert, key, full.Tokens, full.UpdatedAt); err != nil {
+			return fmt.Errorf("create rate limit bucket: %w", err)
+		}
+
+		var bucket domain.TokenBucket
+		const get = `SELECT tokens, updated_at FROM rate_limit_buckets WHERE key = $1 FOR UPDATE`
+		if err := s.queryRow(txCtx, get, key).Scan(&bucket.Tokens, &bucket.UpdatedAt); err != nil {
+			return fmt.Errorf("get rate limit bucket: %w", err)
+		}
+
+		bucket, wait = limit.Take(bucket, now)
+		const update = `UPDATE rate_limit_buckets SET tokens = $2, updated_at = $3, full_at = $4 WHERE key = $1`
+		if _, err := s.exec(txCtx, update, key, bucket.Tokens, bucket.UpdatedAt, limit.FullAt(bucket)); err != nil {
+			return fmt.Errorf("update rate limit bucket: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return wait, nil
+}
+
+// PruneFull deletes buckets that have refilled completely; a missing bucket
+// starts full, so clients do not notice.
+func (s *RateLimitStore) PruneFull(ctx context.Context) (int, error) {
+	tag, err := s.exec(ctx, `DELETE FROM rate_limit_buckets WHERE full_at <= now()`)
+	if err != nil {
+		return 0, fmt.Errorf("prune rate limit buckets: %w", err)
+	}
+	return int(tag.RowsAffected()), nil
+}
+
+func (s *RateLimitStore) exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	if tx := txFromContext(ctx); tx != nil {
+		return tx.Exec(ctx, sql, args...)
+	}
+	return s.pool.Exec(ctx, sql, args...)
+}
+
+func (s *RateLimitStore) queryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	if tx := txFromContext(ctx); tx != nil {
+		return tx.QueryRow(ctx, sql, args...)
+	}
+	return s.pool.QueryRow(ctx, sql, args...)
+}
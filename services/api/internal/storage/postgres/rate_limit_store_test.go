@@ -0,0 +1,40 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cimillas/ultimate-ticket/services/api/internal/domain"
+	"github.com/cimillas/ultimate-ticket/services/api/internal/testutil"
+)
+
+func TestRateLimitStore(t *testing.T) {
+	pool := testutil.NewTestPool(t)
+	ctx := context.Background()
+	testutil.ApplyMigrations(t, ctx, pool)
+	testutil.TruncateAll(t, ctx, pool)
+	store := NewRateLimitStore(pool)
+
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	limit := domain.RateLimit{Requests: 2, Per: time.Minute}
+
+	for i := 0; i < 2; i++ {
+		if wait, err := store.Take(ctx, "holds:ip:10.0.0.1", limit, now); err != nil || wait != 0 {
+			t.Fatalf("request %d: expected no wait, got %s (%v)", i+1, wait, err)
+		}
+	}
+	if wait, err := store.Take(ctx, "holds:ip:10.0.0.1", limit, now); err != nil || wait != 30*time.Second {
+		t.Fatalf("expected a 30s wait, got %s (%v)", wait, err)
+	}
+	if wait, err := store.Take(ctx, "holds:ip:10.0.0.2", limit, now); err != nil || wait != 0 {
+		t.Fatalf("expected another key to have its own bucket, got %s (%v)", wait, err)
+	}
+
+	if _, err := pool.Exec(ctx, `UPDATE rate_limit_buckets SET full_at = now() - interval '1 second' WHERE key = 'holds:ip:10.0.0.2'`); err != nil {
+		t.Fatalf("age bucket: %v", err)
+	}
+	if n, err := store.PruneFull(ctx); err != nil || n != 1 {
+		t.Fatalf("expected 1 pruned bucket, got %d (%v)", n, err)
+	}
+}
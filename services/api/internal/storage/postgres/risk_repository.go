@@ -0,0 +1,192 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/cimillas/ultimate-ticket/services/api/internal/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type RiskRepository struct {
+	pool *pgxpool.Pool
+	cfg  repositoryConfig
+}
+
+func NewRiskRepository(pool *pgxpool.Pool, opts ...RepositoryOption) *RiskRepository {
+	return &RiskRepository{pool: pool, cfg: newRepositoryConfig(opts)}
+}
+
+func (r *RiskRepository) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return withTx(ctx, r.pool, r.cfg, fn)
+}
+
+func (r *RiskRepository) GetRiskRules(ctx context.Context, eventID string) (domain.RiskRules, string, error) {
+	return r.getRiskRules(ctx, eventID, false)
+}
+
+func (r *RiskRepository) GetRiskRulesForUpdate(ctx context.Context, eventID string) (domain.RiskRules, string, error) {
+	return r.getRiskRules(ctx, eventID, true)
+}
+
+func (r *RiskRepository) getRiskRules(ctx context.Context, eventID string, forUpdate bool) (domain.RiskRules, string, error) {
+	query := `
+SELECT e.organizer_id, rr.enabled, rr.challenge_score, rr.block_score,
+       rr.max_holds_per_ip, rr.max_holds_per_customer, rr.max_holds_per_zone, rr.blocked_user_agents
+FROM events e
+LEFT JOIN event_risk_rules rr ON rr.event_id = e.id
+WHERE e.id = $1`
+	if forUpdate {
+		query += ` FOR UPDATE OF e`
+	}
+
+	var organizerID string
+	var enabled *bool
+	var challengeScore, blockScore, perIP, perCustomer, perZone *int
+	var agents []string
+	err := r.queryRow(ctx, query, eventID).Scan(&organizerID, &enabled, &challengeScore, &blockScore,
+		&perIP, &perCustomer, &perZone, &agents)
+	if err != nil {
+		if isInvalidUUID(err) {
+			return domain.RiskRules{}, "", domain.ErrInvalidID
+		}
+		if err == pgx.ErrNoRows {
+			return domain.RiskRules{}, "", domain.ErrEventNotFound
+		}
+		return domain.RiskRules{}, "", fmt.Errorf("get risk rules: %w", err)
+	}
+	if enabled == nil {
+		return domain.DefaultRiskRules(), organizerID, nil
+	}
+	return domain.RiskRules{
+		Enabled:             *enabled,
+		ChallengeScore:      *challengeScore,
+		BlockScore:          *blockScore,
+		MaxHoldsPerIP:       *perIP,
+		MaxHoldsPerCustomer: *perCustomer,
+		MaxHoldsPerZone:     *perZone,
+		BlockedUserAgents:   agents,
+	}, organizerID, nil
+}
+
+func (r *RiskRepository) SaveRiskRules(ctx context.Context, eventID string, rules domain.RiskRules, now time.Time) error {
+	const stmt = `
+INSERT INTO event_risk_rules (event_id, enabled, challenge_score, block_score,
+    max_holds_per_ip, max_holds_per_customer, max_holds_per_zone, blocked_user_agents, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+ON CONFLICT (event_id) DO UPDATE SET
+    enabled = EXCLUDED.enabled,
+    challenge_score = EXCLUDED.challenge_score,
+    block_score = EXCLUDED.block_score,
+    max_holds_per_ip = EXCLUDED.max_holds_per_ip,
+    max_holds_per_customer = EXCLUDED.max_holds_per_customer,
+    max_holds_per_zone = EXCLUDED.max_holds_per_zone,
+    blocked_user_agents = EXCLUDED.blocked_user_agents,
+    updated_at = EXCLUDED.updated_at`
+	agents := rules.BlockedUserAgents
+	if agents == nil {
+		agents = []string{}
+	}
+	if _, err := r.exec(ctx, stmt, eventID, rules.Enabled, rules.ChallengeScore, rules.BlockScore,
+		rules.MaxHoldsPerIP, rules.MaxHoldsPerCustomer, rules.MaxHoldsPerZone, agents, now); err != nil {
+		return fmt.Errorf("save risk rules: %w", err)
+	}
+	return nil
+}
+
+func (r *RiskRepository) CountRecentAttempts(ctx context.Context, s domain.RiskSignals, since time.Time) (domain.RiskVelocity, error) {
+	const query = `
+SELECT
+    COUNT(*) FILTER (WHERE $3 <> '' AND ip = $3),
+    COUNT(*) FILTER (WHERE customer_id IS NOT NULL AND customer_id::text = $4),
+    COUNT(*) FILTER (WHERE zone_id = $5)
+FROM risk_decisions
+WHERE event_id = $1 AND created_at > $2`
+	var v domain.RiskVelocity
+	if err := r.queryRow(ctx, query, s.EventID, since, s.IP, s.CustomerID, s.ZoneID).Scan(&v.IP, &v.Customer, &v.Zone); err != nil {
+		return domain.RiskVelocity{}, fmt.Errorf("count recent attempts: %w", err)
+	}
+	return v, nil
+}
+
+func (r *RiskRepository) RecordRiskAssessment(ctx context.Context, a domain.RiskAssessment) error {
+	const stmt = `
+INSERT INTO risk_decisions (id, event_id, zone_id, customer_id, ip, user_agent, idempotency_key,
+    proof_of_work, score, reasons, decision, created_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)`
+	reasons := a.Reasons
+	if reasons == nil {
+		reasons = []string{}
+	}
+	if _, err := r.exec(ctx, stmt, a.ID, a.EventID, a.ZoneID, nullableString(a.CustomerID), a.IP, a.UserAgent,
+		a.IdempotencyKey, a.ProofOfWork, a.Score, reasons, string(a.Decision), a.CreatedAt); err != nil {
+		return fmt.Errorf("record risk assessment: %w", err)
+	}
+	return nil
+}
+
+func (r *RiskRepository) ListRiskAssessments(ctx context.Context, filter domain.RiskDecisionFilter) ([]domain.RiskAssessment, error) {
+	const query = `
+SELECT d.id, d.event_id, d.zone_id, COALESCE(d.customer_id::text, ''), d.ip, d.user_agent, d.idempotency_key,
+       d.proof_of_work, d.score, d.reasons, d.decision, d.created_at
+FROM risk_decisions d
+JOIN events e ON e.id = d.event_id
+WHERE e.organizer_id = $1
+  AND ($2 = '' OR d.event_id::text = $2)
+  AND ($3 = '' OR d.decision = $3)
+  AND ($4::timestamptz IS NULL OR d.created_at >= $4)
+ORDER BY d.created_at DESC, d.id
+LIMIT $5`
+	rows, err := r.query(ctx, query, filter.OrganizerID, filter.EventID, string(filter.Decision), filter.Since, filter.Limit)
+	if err != nil {
+		if isInvalidUUID(err) {
+			return nil, domain.ErrInvalidID
+		}
+		return nil, fmt.Errorf("list risk decisions: %w", err)
+	}
+	defer rows.Close()
+
+	var assessments []domain.RiskAssessment
+	for rows.Next() {
+		var a domain.RiskAssessment
+		var decision string
+		if err := rows.Scan(&a.ID, &a.EventID, &a.ZoneID, &a.CustomerID, &a.IP, &a.UserAgent, &a.IdempotencyKey,
+			&a.ProofOfWork, &a.Score, &a.Reasons, &decision, &a.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan risk decision: %w", err)
+		}
+		a.Decision = domain.RiskDecision(decision)
+		assessments = append(assessments, a)
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("iterate risk decisions: %w", rows.Err())
+	}
+	return assessments, nil
+}
+
+func (r *RiskRepository) AppendAuditEntry(ctx context.Context, entry domain.AuditEntry) error {
+	return appendAuditEntry(ctx, r.exec, entry)
+}
+
+func (r *RiskRepository) exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	if tx := txFromContext(ctx); tx != nil {
+		return tx.Exec(ctx, sql, args...)
+	}
+	return r.pool.Exec(ctx, sql, args...)
+}
+
+func (r *RiskRepository) query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	if tx := txFromContext(ctx); tx != nil {
+		return tx.Query(ctx, sql, args...)
+	}
+	return r.pool.Query(ctx, sql, args...)
+}
+
+func (r *RiskRepository) queryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	if tx := txFromContext(ctx); tx != nil {
+		return tx.QueryRow(ctx, sql, args...)
+	}
+	return r.pool.QueryRow(ctx, sql, args...)
+}
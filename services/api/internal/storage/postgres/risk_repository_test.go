@@ -0,0 +1,105 @@
+package postgres
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/cimillas/ultimate-ticket/services/api/internal/domain"
+	"github.com/cimillas/ultimate-ticket/services/api/internal/testutil"
+)
+
+func TestRiskRepository_Rules(t *testing.T) {
+	pool := testutil.NewTestPool(t)
+	ctx := context.Background()
+	testutil.ApplyMigrations(t, ctx, pool)
+	testutil.TruncateAll(t, ctx, pool)
+	repo := NewRiskRepository(pool)
+
+	eventID, _ := testutil.InsertEventAndZone(t, ctx, pool, "Risky", 10)
+
+	rules, organizerID, err := repo.GetRiskRules(ctx, eventID)
+	if err != nil {
+		t.Fatalf("get rules: %v", err)
+	}
+	if !reflect.DeepEqual(rules, domain.DefaultRiskRules()) || organizerID != domain.DefaultOrganizerID {
+		t.Fatalf("expected default rules for %s, got %+v for %s", domain.DefaultOrganizerID, rules, organizerID)
+	}
+
+	want := domain.RiskRules{
+		Enabled: true, ChallengeScore: 40, BlockScore: 90,
+		MaxHoldsPerIP: 10, MaxHoldsPerCustomer: 5, MaxHoldsPerZone: 200,
+		BlockedUserAgents: []string{"curl", "python-requests"},
+	}
+	for i := 0; i < 2; i++ {
+		if err := repo.SaveRiskRules(ctx, eventID, want, time.Now()); err != nil {
+			t.Fatalf("save rules %d: %v", i+1, err)
+		}
+	}
+	if got, _, err := repo.GetRiskRulesForUpdate(ctx, eventID); err != nil || !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %+v, got %+v (%v)", want, got, err)
+	}
+
+	if _, _, err := repo.GetRiskRules(ctx, "00000000-0000-0000-0000-000000000000"); err != domain.ErrEventNotFound {
+		t.Fatalf("expected ErrEventNotFound, got %v", err)
+	}
+	if _, _, err := repo.GetRiskRules(ctx, "not-a-uuid"); err != domain.ErrInvalidID {
+		t.Fatalf("expected ErrInvalidID, got %v", err)
+	}
+}
+
+func TestRiskRepository_Decisions(t *testing.T) {
+	pool := testutil.NewTestPool(t)
+	ctx := context.Background()
+	testutil.ApplyMigrations(t, ctx, pool)
+	testutil.TruncateAll(t, ctx, pool)
+	repo := NewRiskRepository(pool)
+
+	eventID, zoneID := testutil.InsertEventAndZone(t, ctx, pool, "Risky", 10)
+	now := time.Now().UTC().Truncate(time.Microsecond)
+
+	record := func(id, ip string, decision domain.RiskDecision, at time.Time) {
+		t.Helper()
+		if err := repo.RecordRiskAssessment(ctx, domain.RiskAssessment{
+			ID: id, EventID: eventID, ZoneID: zoneID, IP: ip, UserAgent: "curl/8.4", IdempotencyKey: "k",
+			Score: 10, Reasons: []string{domain.RiskReasonAnonymous}, Decision: decision, CreatedAt: at,
+		}); err != nil {
+			t.Fatalf("record %s: %v", id, err)
+		}
+	}
+	record("11111111-1111-1111-1111-111111111111", "203.0.113.7", domain.RiskAllow, now.Add(-2*time.Minute))
+	record("22222222-2222-2222-2222-222222222222", "203.0.113.7", domain.RiskAllow, now.Add(-10*time.Second))
+	record("33333333-3333-3333-3333-333333333333", "203.0.113.8", domain.RiskBlock, now.Add(-5*time.Second))
+
+	velocity, err := repo.CountRecentAttempts(ctx, domain.RiskSignals{EventID: eventID, ZoneID: zoneID, IP: "203.0.113.7"}, now.Add(-time.Minute))
+	if err != nil {
+		t.Fatalf("count: %v", err)
+	}
+	if velocity != (domain.RiskVelocity{IP: 1, Customer: 0, Zone: 2}) {
+		t.Fatalf("unexpected velocity %+v", velocity)
+	}
+
+	all, err := repo.ListRiskAssessments(ctx, domain.RiskDecisionFilter{OrganizerID: domain.DefaultOrganizerID, Limit: 10})
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(all) != 3 || all[0].Decision != domain.RiskBlock || !reflect.DeepEqual(all[0].Reasons, []string{domain.RiskReasonAnonymous}) {
+		t.Fatalf("expected 3 decisions newest first, got %+v", all)
+	}
+
+	blocked, err := repo.ListRiskAssessments(ctx, domain.RiskDecisionFilter{OrganizerID: domain.DefaultOrganizerID, EventID: eventID, Decision: domain.RiskBlock, Limit: 10})
+	if err != nil || len(blocked) != 1 {
+		t.Fatalf("expected 1 blocked decision, got %d (%v)", len(blocked), err)
+	}
+	since := now.Add(-time.Minute)
+	recent, err := repo.ListRiskAssessments(ctx, domain.RiskDecisionFilter{OrganizerID: domain.DefaultOrganizerID, Since: &since, Limit: 10})
+	if err != nil || len(recent) != 2 {
+		t.Fatalf("expected 2 recent decisions, got %d (%v)", len(recent), err)
+	}
+
+	other := testutil.InsertOrganizer(t, ctx, pool, "Other")
+	if none, err := repo.ListRiskAssessments(ctx, domain.RiskDecisionFilter{OrganizerID: other, Limit: 10}); err != nil || len(none) != 0 {
+		t.Fatalf("expected no decisions for another organizer, got %d (%v)", len(none), err)
+	}
+}
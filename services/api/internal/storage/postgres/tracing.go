@@ -0,0 +1,39 @@
+package postgres
+
+import (
+	"context"
+	"strings"
+
+	"github.com/cimillas/ultimate-ticket/services/api/internal/tracing"
+	"github.com/jackc/pgx/v5"
+)
+
+// maxStatementLength caps the SQL kept on a query span.
+const maxStatementLength = 1024
+
+// QueryTracer adds a span for every query run on a traced request. Set it as
+// the pool's ConnConfig.Tracer.
+type QueryTracer struct{}
+
+var _ pgx.QueryTracer = QueryTracer{}
+
+func (QueryTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	if tracing.SpanFromContext(ctx) == nil {
+		return ctx
+	}
+	sql := strings.Join(strings.Fields(data.SQL), " ")
+	op, _, _ := strings.Cut(sql, " ")
+	ctx, span := tracing.Start(ctx, "db "+strings.ToUpper(op))
+	if len(sql) > maxStatementLength {
+		sql = sql[:maxStatementLength]
+	}
+	span.SetAttr("db.system", "postgresql")
+	span.SetAttr("db.statement", sql)
+	return ctx
+}
+
+func (QueryTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	span := tracing.SpanFromContext(ctx)
+	span.SetError(data.Err)
+	span.End()
+}
@@ -3,7 +3,9 @@ package postgres
 import (
 	"context"
 	"errors"
+	"time"
 
+	"github.com/cimillas/ultimate-ticket/services/api/internal/tracing"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -11,11 +13,51 @@ import (
 
 type txKey struct{}
 
-func withTx(ctx context.Context, pool *pgxpool.Pool, fn func(ctx context.Context) error) error {
+// TxObserver is told how long a transaction took and whether it committed.
+type TxObserver func(took time.Duration, committed bool)
+
+// RepositoryOption configures the repositories that run transactions.
+type RepositoryOption func(*repositoryConfig)
+
+type repositoryConfig struct {
+	observeTx TxObserver
+}
+
+// WithTxObserver reports every transaction the repository starts to fn. A
+// transaction joined from another repository is reported by the one that
+// started it.
+func WithTxObserver(fn TxObserver) RepositoryOption {
+	return func(c *repositoryConfig) {
+		c.observeTx = fn
+	}
+}
+
+func newRepositoryConfig(opts []RepositoryOption) repositoryConfig {
+	var cfg repositoryConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return cfg
+}
+
+func withTx(ctx context.Context, pool *pgxpool.Pool, cfg repositoryConfig, fn func(ctx context.Context) error) (err error) {
 	if txFromContext(ctx) != nil {
 		return fn(ctx)
 	}
 
+	ctx, span := tracing.Start(ctx, "db.transaction")
+	defer func() {
+		span.SetError(err)
+		span.End()
+	}()
+
+	if cfg.observeTx != nil {
+		start := time.Now()
+		defer func() {
+			cfg.observeTx(time.Since(start), err == nil)
+		}()
+	}
+
 	tx, err := pool.BeginTx(ctx, pgx.TxOptions{})
 	if err != nil {
 		return err
@@ -0,0 +1,45 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cimillas/ultimate-ticket/services/api/internal/testutil"
+)
+
+func TestWithTxObserver(t *testing.T) {
+	pool := testutil.NewTestPool(t)
+	testutil.ApplyMigrations(t, context.Background(), pool)
+
+	var committed []bool
+	observed := NewAuthRepository(pool, WithTxObserver(func(_ time.Duration, ok bool) {
+		committed = append(committed, ok)
+	}))
+	plain := NewAuthRepository(pool)
+	ctx := context.Background()
+
+	if err := observed.WithTx(ctx, func(context.Context) error { return nil }); err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+	failure := errors.New("boom")
+	if err := observed.WithTx(ctx, func(context.Context) error { return failure }); err != failure {
+		t.Fatalf("expected rollback error, got %v", err)
+	}
+	// A repository without the option, or one joining an observed
+	// transaction, reports nothing of its own.
+	if err := plain.WithTx(ctx, func(context.Context) error { return nil }); err != nil {
+		t.Fatalf("plain commit: %v", err)
+	}
+	err := observed.WithTx(ctx, func(txCtx context.Context) error {
+		return plain.WithTx(txCtx, func(context.Context) error { return nil })
+	})
+	if err != nil {
+		t.Fatalf("nested commit: %v", err)
+	}
+
+	if len(committed) != 3 || !committed[0] || committed[1] || !committed[2] {
+		t.Fatalf("expected commit, rollback, commit to be observed, got %v", committed)
+	}
+}
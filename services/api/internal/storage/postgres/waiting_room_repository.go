@@ -0,0 +1,218 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/cimillas/ultimate-ticket/services/api/internal/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type queryRowFunc func(ctx context.Context, sql string, args ...any) pgx.Row
+
+// getWaitingRoom reads an event's waiting room with the caller's queryRow
+// helper so it joins the transaction carried by ctx.
+func getWaitingRoom(ctx context.Context, queryRow queryRowFunc, eventID string, forUpdate bool) (domain.WaitingRoom, error) {
+	query := `SELECT waiting_room_enabled, admit_per_minute FROM events WHERE id = $1`
+	if forUpdate {
+		query += ` FOR UPDATE`
+	}
+	var room domain.WaitingRoom
+	if err := queryRow(ctx, query, eventID).Scan(&room.Enabled, &room.AdmitPerMinute); err != nil {
+		if isInvalidUUID(err) {
+			return domain.WaitingRoom{}, domain.ErrInvalidID
+		}
+		if err == pgx.ErrNoRows {
+			return domain.WaitingRoom{}, domain.ErrEventNotFound
+		}
+		return domain.WaitingRoom{}, fmt.Errorf("get waiting room: %w", err)
+	}
+	return room, nil
+}
+
+type WaitingRoomRepository struct {
+	pool *pgxpool.Pool
+	cfg  repositoryConfig
+}
+
+func NewWaitingRoomRepository(pool *pgxpool.Pool, opts ...RepositoryOption) *WaitingRoomRepository {
+	return &WaitingRoomRepository{pool: pool, cfg: newRepositoryConfig(opts)}
+}
+
+func (r *WaitingRoomRepository) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return withTx(ctx, r.pool, r.cfg, fn)
+}
+
+func (r *WaitingRoomRepository) GetWaitingRoom(ctx context.Context, eventID string) (domain.WaitingRoom, error) {
+	return getWaitingRoom(ctx, r.queryRow, eventID, false)
+}
+
+func (r *WaitingRoomRepository) GetWaitingRoomForUpdate(ctx context.Context, eventID string) (domain.WaitingRoom, error) {
+	return getWaitingRoom(ctx, r.queryRow, eventID, true)
+}
+
+func (r *WaitingRoomRepository) ListQueuedEvents(ctx context.Context) ([]string, error) {
+	const query = `
+SELECT e.id
+FROM events e
+WHERE e.waiting_room_enabled
+  AND EXISTS (SELECT 1 FROM queue_entries q WHERE q.event_id = e.id AND q.status = 'waiting')`
+	rows, err := r.query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("list queued events: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scan queued event: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("iterate queued events: %w", rows.Err())
+	}
+	return ids, nil
+}
+
+func (r *WaitingRoomRepository) CreateQueueEntry(ctx context.Context, entry domain.QueueEntry) error {
+	const stmt = `
+WITH counter AS (
+    INSERT INTO queue_counters (event_id, joined)
+    VALUES ($2, 1)
+    ON CONFLICT (event_id) DO UPDATE SET joined = queue_counters.joined + 1
+    RETURNING joined
+)
+INSERT INTO queue_entries (id, event_id, customer_id, status, joined_at, ticket)
+SELECT $1, $2, $3, $4, $5, joined FROM counter`
+	_, err := r.exec(ctx, stmt, entry.ID, entry.EventID, nullableString(entry.CustomerID), entry.Status, entry.JoinedAt)
+	if err != nil {
+		if isInvalidUUID(err) {
+			return domain.ErrInvalidID
+		}
+		if isForeignKeyViolation(err) {
+			return domain.ErrEventNotFound
+		}
+		if isUniqueViolation(err) {
+			return domain.ErrAlreadyQueued
+		}
+		return fmt.Errorf("create queue entry: %w", err)
+	}
+	return nil
+}
+
+// selectQueueEntry computes the position of a waiting entry from its ticket
+// and the event's admitted watermark. Entries are admitted in ticket order, so
+// every ticket up to the watermark has left the queue and the poll does not
+// have to count the entries ahead.
+const selectQueueEntry = `
+SELECT q.id, q.event_id, q.customer_id, q.status, q.joined_at, q.admitted_at,
+       CASE WHEN q.status = 'waiting' THEN q.ticket - c.admitted ELSE 0 END
+FROM queue_entries q
+JOIN queue_counters c ON c.event_id = q.event_id`
+
+func (r *WaitingRoomRepository) GetQueueEntry(ctx context.Context, id string) (domain.QueueEntry, error) {
+	return r.getQueueEntry(ctx, selectQueueEntry+` WHERE q.id = $1`, id)
+}
+
+func (r *WaitingRoomRepository) FindCustomerQueueEntry(ctx context.Context, eventID, customerID string) (*domain.QueueEntry, error) {
+	const where = ` WHERE q.event_id = $1 AND q.customer_id = $2 ORDER BY q.seq DESC LIMIT 1`
+	entry, err := r.getQueueEntry(ctx, selectQueueEntry+where, eventID, customerID)
+	if err == domain.ErrQueueEntryNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+func (r *WaitingRoomRepository) getQueueEntry(ctx context.Context, query string, args ...any) (domain.QueueEntry, error) {
+	var entry domain.QueueEntry
+	var customerID *string
+	err := r.queryRow(ctx, query, args...).Scan(
+		&entry.ID,
+		&entry.EventID,
+		&customerID,
+		&entry.Status,
+		&entry.JoinedAt,
+		&entry.AdmittedAt,
+		&entry.Position,
+	)
+	if err != nil {
+		if isInvalidUUID(err) {
+			return domain.QueueEntry{}, domain.ErrInvalidID
+		}
+		if err == pgx.ErrNoRows {
+			return domain.QueueEntry{}, domain.ErrQueueEntryNotFound
+		}
+		return domain.QueueEntry{}, fmt.Errorf("get queue entry: %w", err)
+	}
+	if customerID != nil {
+		entry.CustomerID = *customerID
+	}
+	return entry, nil
+}
+
+func (r *WaitingRoomRepository) CountAdmittedSince(ctx context.Context, eventID string, since time.Time) (int, error) {
+	const query = `SELECT COUNT(*) FROM queue_entries WHERE event_id = $1 AND status = 'admitted' AND admitted_at > $2`
+	var n int
+	if err := r.queryRow(ctx, query, eventID, since).Scan(&n); err != nil {
+		return 0, fmt.Errorf("count admitted: %w", err)
+	}
+	return n, nil
+}
+
+// AdmitNext admits the next waiting entries in ticket order and moves the
+// event's admitted watermark up to the last ticket it admitted.
+func (r *WaitingRoomRepository) AdmitNext(ctx context.Context, eventID string, limit int, now time.Time) (int, error) {
+	const stmt = `
+WITH admitted AS (
+    UPDATE queue_entries
+    SET status = 'admitted', admitted_at = $3
+    WHERE id IN (
+        SELECT id FROM queue_entries
+        WHERE event_id = $1 AND status = 'waiting'
+        ORDER BY ticket
+        LIMIT $2
+        FOR UPDATE
+    )
+    RETURNING ticket
+), watermark AS (
+    UPDATE queue_counters
+    SET admitted = GREATEST(admitted, (SELECT MAX(ticket) FROM admitted))
+    WHERE event_id = $1 AND EXISTS (SELECT 1 FROM admitted)
+)
+SELECT COUNT(*) FROM admitted`
+	var n int
+	if err := r.queryRow(ctx, stmt, eventID, limit, now).Scan(&n); err != nil {
+		return 0, fmt.Errorf("admit queue entries: %w", err)
+	}
+	return n, nil
+}
+
+func (r *WaitingRoomRepository) exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	if tx := txFromContext(ctx); tx != nil {
+		return tx.Exec(ctx, sql, args...)
+	}
+	return r.pool.Exec(ctx, sql, args...)
+}
+
+func (r *WaitingRoomRepository) query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	if tx := txFromContext(ctx); tx != nil {
+		return tx.Query(ctx, sql, args...)
+	}
+	return r.pool.Query(ctx, sql, args...)
+}
+
+func (r *WaitingRoomRepository) queryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	if tx := txFromContext(ctx); tx != nil {
+		return tx.QueryRow(ctx, sql, args...)
+	}
+	return r.pool.QueryRow(ctx, sql, args...)
+}
@@ -0,0 +1,181 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cimillas/ultimate-ticket/services/api/internal/domain"
+	"github.com/cimillas/ultimate-ticket/services/api/internal/testutil"
+)
+
+func TestWaitingRoomRepository(t *testing.T) {
+	pool := testutil.NewTestPool(t)
+	testutil.ApplyMigrations(t, context.Background(), pool)
+	repo := NewWaitingRoomRepository(pool)
+
+	t.Run("queue positions and admission", func(t *testing.T) {
+		ctx := context.Background()
+		testutil.TruncateAll(t, ctx, pool)
+		eventID, _ := testutil.InsertEventAndZone(t, ctx, pool, "Concert", 100)
+		if _, err := pool.Exec(ctx, `UPDATE events SET waiting_room_enabled = TRUE, admit_per_minute = 2 WHERE id = $1`, eventID); err != nil {
+			t.Fatalf("enable waiting room: %v", err)
+		}
+		now := time.Now().UTC().Truncate(time.Microsecond)
+
+		room, err := repo.GetWaitingRoom(ctx, eventID)
+		if err != nil || !room.Enabled || room.AdmitPerMinute != 2 {
+			t.Fatalf("expected enabled waiting room, got %+v (%v)", room, err)
+		}
+
+		ids := []string{
+			"aaaaaaaa-0000-0000-0000-000000000001",
+			"aaaaaaaa-0000-0000-0000-000000000002",
+			"aaaaaaaa-0000-0000-0000-000000000003",
+		}
+		for _, id := range ids {
+			entry := domain.QueueEntry{ID: id, EventID: eventID, Status: domain.QueueEntryWaiting, JoinedAt: now}
+			if err := repo.CreateQueueEntry(ctx, entry); err != nil {
+				t.Fatalf("create entry: %v", err)
+			}
+		}
+		third, err := repo.GetQueueEntry(ctx, ids[2])
+		if err != nil || third.Position != 3 {
+			t.Fatalf("expected position 3, got %+v (%v)", third, err)
+		}
+
+		queued, err := repo.ListQueuedEvents(ctx)
+		if err != nil || len(queued) != 1 || queued[0] != eventID {
+			t.Fatalf("expected the event to be queued, got %v (%v)", queued, err)
+		}
+
+		err = repo.WithTx(ctx, func(txCtx context.Context) error {
+			if _, err := repo.GetWaitingRoomForUpdate(txCtx, eventID); err != nil {
+				return err
+			}
+			n, err := repo.AdmitNext(txCtx, eventID, 2, now)
+			if err != nil {
+				return err
+			}
+			if n != 2 {
+				t.Fatalf("expected 2 admitted, got %d", n)
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("admit: %v", err)
+		}
+
+		first, err := repo.GetQueueEntry(ctx, ids[0])
+		if err != nil || first.Status != domain.QueueEntryAdmitted || first.AdmittedAt == nil || !first.AdmittedAt.Equal(now) || first.Position != 0 {
+			t.Fatalf("expected first entry admitted at %v, got %+v (%v)", now, first, err)
+		}
+		third, err = repo.GetQueueEntry(ctx, ids[2])
+		if err != nil || third.Position != 1 {
+			t.Fatalf("expected the third entry to move to position 1, got %+v (%v)", third, err)
+		}
+		recent, err := repo.CountAdmittedSince(ctx, eventID, now.Add(-time.Minute))
+		if err != nil || recent != 2 {
+			t.Fatalf("expected 2 recent admissions, got %d (%v)", recent, err)
+		}
+	})
+
+	t.Run("a customer waits once per event", func(t *testing.T) {
+		ctx := context.Background()
+		testutil.TruncateAll(t, ctx, pool)
+		eventID, _ := testutil.InsertEventAndZone(t, ctx, pool, "Concert", 100)
+		customerID := "cccccccc-0000-0000-0000-000000000001"
+		if _, err := pool.Exec(ctx, `INSERT INTO customers (id, email, created_at) VALUES ($1, 'fan@example.com', now())`, customerID); err != nil {
+			t.Fatalf("insert customer: %v", err)
+		}
+		now := time.Now().UTC()
+
+		if entry, err := repo.FindCustomerQueueEntry(ctx, eventID, customerID); err != nil || entry != nil {
+			t.Fatalf("expected no entry yet, got %+v (%v)", entry, err)
+		}
+		first := domain.QueueEntry{ID: "aaaaaaaa-0000-0000-0000-000000000001", EventID: eventID, CustomerID: customerID, Status: domain.QueueEntryWaiting, JoinedAt: now}
+		if err := repo.CreateQueueEntry(ctx, first); err != nil {
+			t.Fatalf("create entry: %v", err)
+		}
+		second := first
+		second.ID = "aaaaaaaa-0000-0000-0000-000000000002"
+		if err := repo.CreateQueueEntry(ctx, second); err != domain.ErrAlreadyQueued {
+			t.Fatalf("expected ErrAlreadyQueued, got %v", err)
+		}
+
+		entry, err := repo.FindCustomerQueueEntry(ctx, eventID, customerID)
+		if err != nil || entry == nil || entry.ID != first.ID || entry.Position != 1 {
+			t.Fatalf("expected the waiting entry at position 1, got %+v (%v)", entry, err)
+		}
+
+		if _, err := repo.AdmitNext(ctx, eventID, 1, now); err != nil {
+			t.Fatalf("admit: %v", err)
+		}
+		if err := repo.CreateQueueEntry(ctx, second); err != nil {
+			t.Fatalf("expected a new entry once admitted, got %v", err)
+		}
+		entry, err = repo.FindCustomerQueueEntry(ctx, eventID, customerID)
+		if err != nil || entry == nil || entry.ID != second.ID {
+			t.Fatalf("expected the latest entry, got %+v (%v)", entry, err)
+		}
+	})
+
+	t.Run("positions are counted per event", func(t *testing.T) {
+		ctx := context.Background()
+		testutil.TruncateAll(t, ctx, pool)
+		eventID, _ := testutil.InsertEventAndZone(t, ctx, pool, "Concert", 100)
+		otherID, _ := testutil.InsertEventAndZone(t, ctx, pool, "Festival", 100)
+		now := time.Now().UTC()
+
+		joins := []struct {
+			id      string
+			eventID string
+		}{
+			{"aaaaaaaa-0000-0000-0000-000000000001", eventID},
+			{"aaaaaaaa-0000-0000-0000-000000000002", otherID},
+			{"aaaaaaaa-0000-0000-0000-000000000003", otherID},
+			{"aaaaaaaa-0000-0000-0000-000000000004", eventID},
+			{"aaaaaaaa-0000-0000-0000-000000000005", eventID},
+		}
+		for _, j := range joins {
+			entry := domain.QueueEntry{ID: j.id, EventID: j.eventID, Status: domain.QueueEntryWaiting, JoinedAt: now}
+			if err := repo.CreateQueueEntry(ctx, entry); err != nil {
+				t.Fatalf("create entry: %v", err)
+			}
+		}
+		if _, err := repo.AdmitNext(ctx, otherID, 2, now); err != nil {
+			t.Fatalf("admit other event: %v", err)
+		}
+		if n, err := repo.AdmitNext(ctx, eventID, 1, now); err != nil || n != 1 {
+			t.Fatalf("expected 1 admitted, got %d (%v)", n, err)
+		}
+
+		for id, want := range map[string]int{
+			"aaaaaaaa-0000-0000-0000-000000000004": 1,
+			"aaaaaaaa-0000-0000-0000-000000000005": 2,
+		} {
+			entry, err := repo.GetQueueEntry(ctx, id)
+			if err != nil || entry.Position != want {
+				t.Fatalf("expected %s at position %d, got %+v (%v)", id, want, entry, err)
+			}
+		}
+		if n, err := repo.AdmitNext(ctx, otherID, 5, now); err != nil || n != 0 {
+			t.Fatalf("expected an empty queue to admit nobody, got %d (%v)", n, err)
+		}
+	})
+
+	t.Run("unknown event and entry", func(t *testing.T) {
+		ctx := context.Background()
+		testutil.TruncateAll(t, ctx, pool)
+
+		if _, err := repo.GetWaitingRoom(ctx, "00000000-0000-0000-0000-000000000001"); err != domain.ErrEventNotFound {
+			t.Fatalf("expected ErrEventNotFound, got %v", err)
+		}
+		if _, err := repo.GetQueueEntry(ctx, "00000000-0000-0000-0000-000000000001"); err != domain.ErrQueueEntryNotFound {
+			t.Fatalf("expected ErrQueueEntryNotFound, got %v", err)
+		}
+		if _, err := repo.GetQueueEntry(ctx, "not-a-uuid"); err != domain.ErrInvalidID {
+			t.Fatalf("expected ErrInvalidID, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,359 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/cimillas/ultimate-ticket/services/api/internal/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type WebhookRepository struct {
+	pool *pgxpool.Pool
+	cfg  repositoryConfig
+}
+
+func NewWebhookRepository(pool *pgxpool.Pool, opts ...RepositoryOption) *WebhookRepository {
+	return &WebhookRepository{pool: pool, cfg: newRepositoryConfig(opts)}
+}
+
+func (r *WebhookRepository) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return withTx(ctx, r.pool, r.cfg, fn)
+}
+
+func (r *WebhookRepository) CreateWebhookSubscription(ctx context.Context, sub domain.WebhookSubscription) error {
+	const stmt = `
+INSERT INTO webhook_subscriptions (id, organizer_id, url, secret, event_types, active, created_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7)`
+
+	types := make([]string, 0, len(sub.EventTypes))
+	for _, t := range sub.EventTypes {
+		types = append(types, string(t))
+	}
+	if _, err := r.exec(ctx, stmt, sub.ID, sub.OrganizerID, sub.URL, sub.Secret, types, sub.Active, sub.CreatedAt); err != nil {
+		return fmt.Errorf("create webhook subscription: %w", err)
+	}
+	return nil
+}
+
+func (r *WebhookRepository) ListWebhookSubscriptions(ctx context.Context, organizerID string) ([]domain.WebhookSubscription, error) {
+	const query = `
+SELECT id, organizer_id, url, secret, event_types, active, created_at
+FROM webhook_subscriptions
+WHERE organizer_id = $1
+ORDER BY created_at ASC`
+
+	rows, err := r.query(ctx, query, organizerID)
+	if err != nil {
+		if isInvalidUUID(err) {
+			return nil, domain.ErrInvalidID
+		}
+		return nil, fmt.Errorf("list webhook subscriptions: %w", err)
+	}
+	defer rows.Close()
+
+	var subs []domain.WebhookSubscription
+	for rows.Next() {
+		var sub domain.WebhookSubscription
+		var types []string
+		if err := rows.Scan(&sub.ID, &sub.OrganizerID, &sub.URL, &sub.Secret, &types, &sub.Active, &sub.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan webhook subscription: %w", err)
+		}
+		for _, t := range types {
+			sub.EventTypes = append(sub.EventTypes, domain.OutboxEventType(t))
+		}
+		subs = append(subs, sub)
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("iterate webhook subscriptions: %w", rows.Err())
+	}
+	return subs, nil
+}
+
+func (r *WebhookRepository) GetWebhookSubscriptionForUpdate(ctx context.Context, organizerID, id string) (domain.WebhookSubscription, error) {
+	const query = `
+SELECT id, organizer_id, url, secret, event_types, active, created_at
+FROM webhook_subscriptions
+WHERE id = $1 AND organizer_id = $2
+FOR UPDATE`
+
+	var sub domain.WebhookSubscription
+	var types []string
+	if err := r.queryRow(ctx, query, id, organizerID).Scan(&sub.ID, &sub.OrganizerID, &sub.URL, &sub.Secret, &types, &sub.Active, &sub.CreatedAt); err != nil {
+		if isInvalidUUID(err) {
+			return domain.WebhookSubscription{}, domain.ErrInvalidID
+		}
+		if err == pgx.ErrNoRows {
+			return domain.WebhookSubscription{}, domain.ErrWebhookNotFound
+		}
+		return domain.WebhookSubscription{}, fmt.Errorf("get webhook subscription: %w", err)
+	}
+	for _, t := range types {
+		sub.EventTypes = append(sub.EventTypes, domain.OutboxEventType(t))
+	}
+	return sub, nil
+}
+
+// DeactivateWebhookSubscription also cancels the subscription's pending
+// deliveries, so they neither go out nor linger in the dispatch queue.
+func (r *WebhookRepository) DeactivateWebhookSubscription(ctx context.Context, organizerID, id string) error {
+	const stmt = `UPDATE webhook_subscriptions SET active = FALSE WHERE id = $1 AND organizer_id = $2`
+	const cancel = `UPDATE webhook_deliveries SET status = 'cancelled' WHERE subscription_id = $1 AND status = 'pending'`
+
+	return withTx(ctx, r.pool, r.cfg, func(ctx context.Context) error {
+		tag, err := r.exec(ctx, stmt, id, organizerID)
+		if err != nil {
+			if isInvalidUUID(err) {
+				return domain.ErrInvalidID
+			}
+			return fmt.Errorf("deactivate webhook subscription: %w", err)
+		}
+		if tag.RowsAffected() == 0 {
+			return domain.ErrWebhookNotFound
+		}
+		if _, err := r.exec(ctx, cancel, id); err != nil {
+			return fmt.Errorf("cancel webhook deliveries: %w", err)
+		}
+		return nil
+	})
+}
+
+func (r *WebhookRepository) AppendAuditEntry(ctx context.Context, entry domain.AuditEntry) error {
+	return appendAuditEntry(ctx, r.exec, entry)
+}
+
+func (r *WebhookRepository) FindAggregateOrganizer(ctx context.Context, aggregateType, aggregateID string) (string, error) {
+	var query string
+	switch aggregateType {
+	case domain.OutboxAggregateEvent:
+		query = `SELECT organizer_id FROM events WHERE id = $1`
+	case domain.OutboxAggregateHold:
+		query = `SELECT organizer_id FROM holds WHERE id = $1`
+	case domain.OutboxAggregateOrder:
+		query = `SELECT organizer_id FROM orders WHERE id = $1`
+	default:
+		return "", domain.ErrOrganizerNotFound
+	}
+
+	var organizerID string
+	if err := r.queryRow(ctx, query, aggregateID).Scan(&organizerID); err != nil {
+		if err == pgx.ErrNoRows || isInvalidUUID(err) {
+			return "", domain.ErrOrganizerNotFound
+		}
+		return "", fmt.Errorf("find aggregate organizer: %w", err)
+	}
+	return organizerID, nil
+}
+
+func (r *WebhookRepository) CreateWebhookDelivery(ctx context.Context, d domain.WebhookDelivery) (bool, error) {
+	const stmt = `
+INSERT INTO webhook_deliveries (id, organizer_id, subscription_id, event_id, event_type, payload, status, attempts, next_attempt_at, created_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+ON CONFLICT (subscription_id, event_id) DO NOTHING`
+
+	tag, err := r.exec(ctx, stmt, d.ID, d.OrganizerID, d.SubscriptionID, d.EventID, d.EventType, d.Payload, d.Status, d.Attempts, d.NextAttemptAt, d.CreatedAt)
+	if err != nil {
+		return false, fmt.Errorf("create webhook delivery: %w", err)
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+const deliveryColumns = `d.id, d.organizer_id, d.subscription_id, d.event_id, d.event_type, d.payload, d.status, d.attempts, d.next_attempt_at, d.last_error, d.created_at, d.delivered_at, s.url, s.secret`
+
+func (r *WebhookRepository) ClaimDueWebhookDeliveries(ctx context.Context, now, leaseUntil time.Time, limit int) ([]domain.WebhookDelivery, error) {
+	query := `
+UPDATE webhook_deliveries d
+SET next_attempt_at = $2
+FROM webhook_subscriptions s
+WHERE s.id = d.subscription_id
+  AND d.id IN (
+	SELECT pd.id FROM webhook_deliveries pd
+	JOIN webhook_subscriptions ps ON ps.id = pd.subscription_id AND ps.active
+	WHERE pd.status = 'pending' AND pd.next_attempt_at <= $1
+	ORDER BY pd.next_attempt_at
+	LIMIT $3
+	FOR UPDATE OF pd SKIP LOCKED
+)
+RETURNING ` + deliveryColumns
+
+	rows, err := r.query(ctx, query, now, leaseUntil, limit)
+	if err != nil {
+		return nil, fmt.Errorf("claim webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+	return scanDeliveries(rows)
+}
+
+func (r *WebhookRepository) GetWebhookDeliveryForUpdate(ctx context.Context, id string) (domain.WebhookDelivery, error) {
+	query := `
+SELECT ` + deliveryColumns + `
+FROM webhook_deliveries d
+JOIN webhook_subscriptions s ON s.id = d.subscription_id
+WHERE d.id = $1
+FOR UPDATE OF d`
+
+	rows, err := r.query(ctx, query, id)
+	if err != nil {
+		if isInvalidUUID(err) {
+			return domain.WebhookDelivery{}, domain.ErrInvalidID
+		}
+		return domain.WebhookDelivery{}, fmt.Errorf("get webhook delivery: %w", err)
+	}
+	defer rows.Close()
+	deliveries, err := scanDeliveries(rows)
+	if err != nil {
+		if isInvalidUUID(err) {
+			return domain.WebhookDelivery{}, domain.ErrInvalidID
+		}
+		return domain.WebhookDelivery{}, err
+	}
+	if len(deliveries) == 0 {
+		return domain.WebhookDelivery{}, domain.ErrDeliveryNotFound
+	}
+	return deliveries[0], nil
+}
+
+func (r *WebhookRepository) UpdateWebhookDelivery(ctx context.Context, d domain.WebhookDelivery) error {
+	const stmt = `
+UPDATE webhook_deliveries
+SET status = $2, attempts = $3, next_attempt_at = $4, last_error = $5, delivered_at = $6
+WHERE id = $1`
+
+	tag, err := r.exec(ctx, stmt, d.ID, d.Status, d.Attempts, d.NextAttemptAt, nullableString(d.LastError), d.DeliveredAt)
+	if err != nil {
+		return fmt.Errorf("update webhook delivery: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrDeliveryNotFound
+	}
+	return nil
+}
+
+func (r *WebhookRepository) RecordWebhookAttempt(ctx context.Context, a domain.WebhookAttempt) error {
+	const stmt = `
+INSERT INTO webhook_attempts (delivery_id, attempted_at, status_code, error, duration_ms)
+VALUES ($1, $2, $3, $4, $5)`
+
+	var status *int
+	if a.StatusCode != 0 {
+		status = &a.StatusCode
+	}
+	if _, err := r.exec(ctx, stmt, a.DeliveryID, a.AttemptedAt, status, nullableString(a.Error), a.Duration.Milliseconds()); err != nil {
+		return fmt.Errorf("record webhook attempt: %w", err)
+	}
+	return nil
+}
+
+func (r *WebhookRepository) ListWebhookDeliveries(ctx context.Context, organizerID, subscriptionID string, status domain.WebhookDeliveryStatus) ([]domain.WebhookDelivery, error) {
+	query := `
+SELECT ` + deliveryColumns + `
+FROM webhook_deliveries d
+JOIN webhook_subscriptions s ON s.id = d.subscription_id
+WHERE d.subscription_id = $1 AND ($2 = '' OR d.status = $2) AND d.organizer_id = $3
+ORDER BY d.created_at DESC`
+
+	rows, err := r.query(ctx, query, subscriptionID, string(status), organizerID)
+	if err != nil {
+		if isInvalidUUID(err) {
+			return nil, domain.ErrInvalidID
+		}
+		return nil, fmt.Errorf("list webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+	deliveries, err := scanDeliveries(rows)
+	if err != nil && isInvalidUUID(err) {
+		return nil, domain.ErrInvalidID
+	}
+	return deliveries, err
+}
+
+func (r *WebhookRepository) ListWebhookAttempts(ctx context.Context, organizerID, deliveryID string) ([]domain.WebhookAttempt, error) {
+	const query = `
+SELECT a.delivery_id, a.attempted_at, a.status_code, a.error, a.duration_ms
+FROM webhook_attempts a
+JOIN webhook_deliveries d ON d.id = a.delivery_id
+WHERE a.delivery_id = $1 AND d.organizer_id = $2
+ORDER BY a.attempted_at ASC, a.id ASC`
+
+	rows, err := r.query(ctx, query, deliveryID, organizerID)
+	if err != nil {
+		if isInvalidUUID(err) {
+			return nil, domain.ErrInvalidID
+		}
+		return nil, fmt.Errorf("list webhook attempts: %w", err)
+	}
+	defer rows.Close()
+
+	var attempts []domain.WebhookAttempt
+	for rows.Next() {
+		var a domain.WebhookAttempt
+		var status *int
+		var errMsg *string
+		var durationMS int64
+		if err := rows.Scan(&a.DeliveryID, &a.AttemptedAt, &status, &errMsg, &durationMS); err != nil {
+			return nil, fmt.Errorf("scan webhook attempt: %w", err)
+		}
+		if status != nil {
+			a.StatusCode = *status
+		}
+		if errMsg != nil {
+			a.Error = *errMsg
+		}
+		a.Duration = time.Duration(durationMS) * time.Millisecond
+		attempts = append(attempts, a)
+	}
+	if rows.Err() != nil {
+		if isInvalidUUID(rows.Err()) {
+			return nil, domain.ErrInvalidID
+		}
+		return nil, fmt.Errorf("iterate webhook attempts: %w", rows.Err())
+	}
+	return attempts, nil
+}
+
+func scanDeliveries(rows pgx.Rows) ([]domain.WebhookDelivery, error) {
+	var deliveries []domain.WebhookDelivery
+	for rows.Next() {
+		var d domain.WebhookDelivery
+		var eventType, status string
+		var lastError *string
+		if err := rows.Scan(&d.ID, &d.OrganizerID, &d.SubscriptionID, &d.EventID, &eventType, &d.Payload, &status, &d.Attempts,
+			&d.NextAttemptAt, &lastError, &d.CreatedAt, &d.DeliveredAt, &d.URL, &d.Secret); err != nil {
+			return nil, fmt.Errorf("scan webhook delivery: %w", err)
+		}
+		d.EventType = domain.OutboxEventType(eventType)
+		d.Status = domain.WebhookDeliveryStatus(status)
+		if lastError != nil {
+			d.LastError = *lastError
+		}
+		deliveries = append(deliveries, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate webhook deliveries: %w", err)
+	}
+	return deliveries, nil
+}
+
+func (r *WebhookRepository) exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	if tx := txFromContext(ctx); tx != nil {
+		return tx.Exec(ctx, sql, args...)
+	}
+	return r.pool.Exec(ctx, sql, args...)
+}
+
+func (r *WebhookRepository) queryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	if tx := txFromContext(ctx); tx != nil {
+		return tx.QueryRow(ctx, sql, args...)
+	}
+	return r.pool.QueryRow(ctx, sql, args...)
+}
+
+func (r *WebhookRepository) query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	if tx := txFromContext(ctx); tx != nil {
+		return tx.Query(ctx, sql, args...)
+	}
+	return r.pool.Query(ctx, sql, args...)
+}
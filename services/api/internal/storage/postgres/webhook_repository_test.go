@@ -0,0 +1,272 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cimillas/ultimate-ticket/services/api/internal/domain"
+	"github.com/cimillas/ultimate-ticket/services/api/internal/testutil"
+)
+
+func TestWebhookRepository(t *testing.T) {
+	pool := testutil.NewTestPool(t)
+	repo := NewWebhookRepository(pool)
+	testutil.ApplyMigrations(t, context.Background(), pool)
+
+	t.Run("deliveries are deduplicated, claimed and updated", func(t *testing.T) {
+		ctx := context.Background()
+		testutil.TruncateAll(t, ctx, pool)
+		now := time.Now().UTC().Truncate(time.Microsecond)
+
+		org := domain.DefaultOrganizerID
+		sub := domain.WebhookSubscription{
+			ID:          "aaaaaaaa-1111-1111-1111-aaaaaaaaaaaa",
+			OrganizerID: org,
+			URL:         "https://crm.example.com/hook",
+			Secret:      "shh",
+			EventTypes:  []domain.OutboxEventType{domain.OutboxOrderPaid},
+			Active:      true,
+			CreatedAt:   now,
+		}
+		if err := repo.CreateWebhookSubscription(ctx, sub); err != nil {
+			t.Fatalf("create subscription: %v", err)
+		}
+		subs, err := repo.ListWebhookSubscriptions(ctx, org)
+		if err != nil {
+			t.Fatalf("list subscriptions: %v", err)
+		}
+		if len(subs) != 1 || len(subs[0].EventTypes) != 1 || subs[0].EventTypes[0] != domain.OutboxOrderPaid {
+			t.Fatalf("unexpected subscriptions %+v", subs)
+		}
+
+		delivery := domain.WebhookDelivery{
+			ID:             "bbbbbbbb-2222-2222-2222-bbbbbbbbbbbb",
+			OrganizerID:    org,
+			SubscriptionID: sub.ID,
+			EventID:        "cccccccc-3333-3333-3333-cccccccccccc",
+			EventType:      domain.OutboxOrderPaid,
+			Payload:        []byte(`{"id":"evt"}`),
+			Status:         domain.WebhookDeliveryPending,
+			NextAttemptAt:  now,
+			CreatedAt:      now,
+		}
+		created, err := repo.CreateWebhookDelivery(ctx, delivery)
+		if err != nil || !created {
+			t.Fatalf("expected delivery created, got %v (%v)", created, err)
+		}
+		duplicate := delivery
+		duplicate.ID = "dddddddd-4444-4444-4444-dddddddddddd"
+		if created, err := repo.CreateWebhookDelivery(ctx, duplicate); err != nil || created {
+			t.Fatalf("expected duplicate skipped, got %v (%v)", created, err)
+		}
+
+		claimed, err := repo.ClaimDueWebhookDeliveries(ctx, now, now.Add(time.Minute), 10)
+		if err != nil {
+			t.Fatalf("claim: %v", err)
+		}
+		if len(claimed) != 1 || claimed[0].URL != sub.URL || claimed[0].Secret != sub.Secret {
+			t.Fatalf("unexpected claim %+v", claimed)
+		}
+		again, err := repo.ClaimDueWebhookDeliveries(ctx, now, now.Add(time.Minute), 10)
+		if err != nil || len(again) != 0 {
+			t.Fatalf("expected leased delivery to be skipped, got %d (%v)", len(again), err)
+		}
+
+		d := claimed[0]
+		d.Attempts = 1
+		d.Status = domain.WebhookDeliveryDead
+		d.LastError = "unexpected status 500"
+		if err := repo.RecordWebhookAttempt(ctx, domain.WebhookAttempt{DeliveryID: d.ID, AttemptedAt: now, StatusCode: 500, Error: d.LastError, Duration: 15 * time.Millisecond}); err != nil {
+			t.Fatalf("record attempt: %v", err)
+		}
+		if err := repo.UpdateWebhookDelivery(ctx, d); err != nil {
+			t.Fatalf("update delivery: %v", err)
+		}
+
+		dead, err := repo.ListWebhookDeliveries(ctx, org, sub.ID, domain.WebhookDeliveryDead)
+		if err != nil || len(dead) != 1 || dead[0].LastError != d.LastError {
+			t.Fatalf("expected one dead delivery, got %+v (%v)", dead, err)
+		}
+		attempts, err := repo.ListWebhookAttempts(ctx, org, d.ID)
+		if err != nil || len(attempts) != 1 || attempts[0].StatusCode != 500 || attempts[0].Duration != 15*time.Millisecond {
+			t.Fatalf("unexpected attempts %+v (%v)", attempts, err)
+		}
+
+		if _, err := repo.GetWebhookDeliveryForUpdate(ctx, "not-a-uuid"); err != domain.ErrInvalidID {
+			t.Fatalf("expected ErrInvalidID, got %v", err)
+		}
+		if err := repo.DeactivateWebhookSubscription(ctx, org, "eeeeeeee-5555-5555-5555-eeeeeeeeeeee"); err != domain.ErrWebhookNotFound {
+			t.Fatalf("expected ErrWebhookNotFound, got %v", err)
+		}
+
+		rival := testutil.InsertOrganizer(t, ctx, pool, "Rival")
+		if subs, err := repo.ListWebhookSubscriptions(ctx, rival); err != nil || len(subs) != 0 {
+			t.Fatalf("expected no subscriptions for another organizer, got %+v (%v)", subs, err)
+		}
+		if list, err := repo.ListWebhookDeliveries(ctx, rival, sub.ID, ""); err != nil || len(list) != 0 {
+			t.Fatalf("expected no deliveries for another organizer, got %+v (%v)", list, err)
+		}
+		if list, err := repo.ListWebhookAttempts(ctx, rival, d.ID); err != nil || len(list) != 0 {
+			t.Fatalf("expected no attempts for another organizer, got %+v (%v)", list, err)
+		}
+		if err := repo.DeactivateWebhookSubscription(ctx, rival, sub.ID); err != domain.ErrWebhookNotFound {
+			t.Fatalf("expected ErrWebhookNotFound for another organizer, got %v", err)
+		}
+	})
+
+	t.Run("deliveries of inactive subscriptions do not block the queue", func(t *testing.T) {
+		ctx := context.Background()
+		testutil.TruncateAll(t, ctx, pool)
+		now := time.Now().UTC().Truncate(time.Microsecond)
+
+		org := domain.DefaultOrganizerID
+		active := domain.WebhookSubscription{ID: "aaaaaaaa-1111-1111-1111-aaaaaaaaaaaa", OrganizerID: org, URL: "https://a.example.com",
+			Secret: "a", EventTypes: []domain.OutboxEventType{domain.OutboxOrderPaid}, Active: true, CreatedAt: now}
+		retired := domain.WebhookSubscription{ID: "aaaaaaaa-2222-2222-2222-aaaaaaaaaaaa", OrganizerID: org, URL: "https://b.example.com",
+			Secret: "b", EventTypes: []domain.OutboxEventType{domain.OutboxOrderPaid}, Active: true, CreatedAt: now}
+		for _, sub := range []domain.WebhookSubscription{active, retired} {
+			if err := repo.CreateWebhookSubscription(ctx, sub); err != nil {
+				t.Fatalf("create subscription: %v", err)
+			}
+		}
+
+		delivery := func(id, subID, eventID string, due time.Time) domain.WebhookDelivery {
+			return domain.WebhookDelivery{ID: id, OrganizerID: org, SubscriptionID: subID, EventID: eventID,
+				EventType: domain.OutboxOrderPaid, Payload: []byte(`{}`), Status: domain.WebhookDeliveryPending,
+				NextAttemptAt: due, CreatedAt: now}
+		}
+		stale := []domain.WebhookDelivery{
+			delivery("bbbbbbbb-0001-0001-0001-bbbbbbbbbbbb", retired.ID, "cccccccc-0001-0000-0000-cccccccccccc", now.Add(-time.Hour)),
+			delivery("bbbbbbbb-0002-0002-0002-bbbbbbbbbbbb", retired.ID, "cccccccc-0002-0000-0000-cccccccccccc", now.Add(-time.Hour)),
+			delivery("bbbbbbbb-0003-0003-0003-bbbbbbbbbbbb", retired.ID, "cccccccc-0003-0000-0000-cccccccccccc", now.Add(-time.Hour)),
+		}
+		for _, d := range stale {
+			if _, err := repo.CreateWebhookDelivery(ctx, d); err != nil {
+				t.Fatalf("create delivery: %v", err)
+			}
+		}
+		if err := repo.DeactivateWebhookSubscription(ctx, org, retired.ID); err != nil {
+			t.Fatalf("deactivate: %v", err)
+		}
+		cancelled, err := repo.ListWebhookDeliveries(ctx, org, retired.ID, domain.WebhookDeliveryCancelled)
+		if err != nil || len(cancelled) != len(stale) {
+			t.Fatalf("expected %d cancelled deliveries, got %d (%v)", len(stale), len(cancelled), err)
+		}
+
+		// Rows left pending by an inactive subscription, e.g. from before
+		// deactivation cancelled them, must not fill the batch either.
+		if _, err := pool.Exec(ctx, `UPDATE webhook_deliveries SET status = 'pending' WHERE subscription_id = $1`, retired.ID); err != nil {
+			t.Fatalf("reset deliveries: %v", err)
+		}
+		wanted := delivery("bbbbbbbb-0009-0009-0009-bbbbbbbbbbbb", active.ID, "cccccccc-0009-0000-0000-cccccccccccc", now)
+		if _, err := repo.CreateWebhookDelivery(ctx, wanted); err != nil {
+			t.Fatalf("create delivery: %v", err)
+		}
+
+		claimed, err := repo.ClaimDueWebhookDeliveries(ctx, now, now.Add(time.Minute), 2)
+		if err != nil {
+			t.Fatalf("claim: %v", err)
+		}
+		if len(claimed) != 1 || claimed[0].ID != wanted.ID {
+			t.Fatalf("expected only the active subscription's delivery, got %+v", claimed)
+		}
+	})
+
+	t.Run("aggregates resolve to their event's organizer", func(t *testing.T) {
+		ctx := context.Background()
+		testutil.TruncateAll(t, ctx, pool)
+		now := time.Now().UTC().Truncate(time.Microsecond)
+
+		eventID, zoneID := testutil.InsertEventAndZone(t, ctx, pool, "Concert", 10)
+		holdID := testutil.InsertHold(t, ctx, pool, eventID, zoneID, domain.Hold{
+			Quantity:       1,
+			Status:         domain.HoldStatusConfirmed,
+			ExpiresAt:      now.Add(time.Minute),
+			IdempotencyKey: "hold-1",
+		})
+		orderID := "ffffffff-6666-6666-6666-ffffffffffff"
+		if err := NewOrderRepository(pool).CreateOrder(ctx, domain.Order{
+			ID:             orderID,
+			HoldID:         holdID,
+			IdempotencyKey: "order-1",
+			Status:         domain.OrderStatusPaid,
+			CreatedAt:      now,
+			UpdatedAt:      now,
+		}); err != nil {
+			t.Fatalf("create order: %v", err)
+		}
+
+		for _, agg := range []struct{ typ, id string }{
+			{domain.OutboxAggregateEvent, eventID},
+			{domain.OutboxAggregateHold, holdID},
+			{domain.OutboxAggregateOrder, orderID},
+		} {
+			got, err := repo.FindAggregateOrganizer(ctx, agg.typ, agg.id)
+			if err != nil || got != domain.DefaultOrganizerID {
+				t.Fatalf("%s: expected default organizer, got %q (%v)", agg.typ, got, err)
+			}
+		}
+		if _, err := repo.FindAggregateOrganizer(ctx, domain.OutboxAggregateOrder, "00000000-0000-0000-0000-0000000000aa"); err != domain.ErrOrganizerNotFound {
+			t.Fatalf("expected ErrOrganizerNotFound, got %v", err)
+		}
+	})
+
+	t.Run("holds and orders carry their event's organizer", func(t *testing.T) {
+		ctx := context.Background()
+		testutil.TruncateAll(t, ctx, pool)
+		now := time.Now().UTC().Truncate(time.Microsecond)
+
+		organizerID := "0a0a0a0a-0000-0000-0000-000000000002"
+		if err := NewOrganizerRepository(pool).CreateOrganizer(ctx, domain.Organizer{ID: organizerID, Name: "Other", CreatedAt: now}); err != nil {
+			t.Fatalf("create organizer: %v", err)
+		}
+		t.Cleanup(func() {
+			_, _ = pool.Exec(context.Background(), `DELETE FROM events WHERE organizer_id = $1`, organizerID)
+			_, _ = pool.Exec(context.Background(), `DELETE FROM organizers WHERE id = $1`, organizerID)
+		})
+		eventID, zoneID := testutil.InsertEventAndZone(t, ctx, pool, "Concert", 10)
+		if _, err := pool.Exec(ctx, `UPDATE events SET organizer_id = $2 WHERE id = $1`, eventID, organizerID); err != nil {
+			t.Fatalf("move event: %v", err)
+		}
+
+		holdID := "dddddddd-0000-0000-0000-000000000001"
+		if err := NewHoldRepository(pool).CreateHold(ctx, domain.Hold{
+			ID:             holdID,
+			EventID:        eventID,
+			ZoneID:         zoneID,
+			Quantity:       1,
+			Status:         domain.HoldStatusActive,
+			ExpiresAt:      now.Add(time.Minute),
+			IdempotencyKey: "hold-1",
+			CreatedAt:      now,
+		}); err != nil {
+			t.Fatalf("create hold: %v", err)
+		}
+		orderID := "ffffffff-6666-6666-6666-ffffffffffff"
+		if err := NewOrderRepository(pool).CreateOrder(ctx, domain.Order{
+			ID:             orderID,
+			HoldID:         holdID,
+			IdempotencyKey: "order-1",
+			Status:         domain.OrderStatusPendingPayment,
+			CreatedAt:      now,
+			UpdatedAt:      now,
+		}); err != nil {
+			t.Fatalf("create order: %v", err)
+		}
+
+		var holdOrganizer, orderOrganizer string
+		if err := pool.QueryRow(ctx, `SELECT organizer_id FROM holds WHERE id = $1`, holdID).Scan(&holdOrganizer); err != nil || holdOrganizer != organizerID {
+			t.Fatalf("expected the hold to belong to %s, got %q (%v)", organizerID, holdOrganizer, err)
+		}
+		if err := pool.QueryRow(ctx, `SELECT organizer_id FROM orders WHERE id = $1`, orderID).Scan(&orderOrganizer); err != nil || orderOrganizer != organizerID {
+			t.Fatalf("expected the order to belong to %s, got %q (%v)", organizerID, orderOrganizer, err)
+		}
+		if _, err := pool.Exec(ctx, `UPDATE orders SET organizer_id = $2 WHERE id = $1`, orderID, domain.DefaultOrganizerID); !isForeignKeyViolation(err) {
+			t.Fatalf("expected an order moved to another organizer to be rejected, got %v", err)
+		}
+		if _, err := pool.Exec(ctx, `UPDATE events SET organizer_id = $2 WHERE id = $1`, eventID, domain.DefaultOrganizerID); !isForeignKeyViolation(err) {
+			t.Fatalf("expected an event with holds to keep its organizer, got %v", err)
+		}
+	})
+}
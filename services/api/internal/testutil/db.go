@@ -60,10 +60,26 @@ func ApplyMigrations(t *testing.T, ctx context.Context, pool *pgxpool.Pool) {
 
 func TruncateAll(t *testing.T, ctx context.Context, pool *pgxpool.Pool) {
 	t.Helper()
-	_, err := pool.Exec(ctx, `TRUNCATE orders, holds, zones, events RESTART IDENTITY CASCADE`)
+	_, err := pool.Exec(ctx, `TRUNCATE risk_decisions, event_risk_rules, pow_redemptions, rate_limit_buckets, audit_log, lottery_ballots, lotteries, queue_entries, queue_counters, api_keys, sessions, login_codes, notifications, webhook_attempts, webhook_deliveries, webhook_subscriptions, outbox, payment_events, orders, holds, customers, zones, events RESTART IDENTITY CASCADE`)
 	if err != nil {
 		t.Fatalf("truncate: %v", err)
 	}
+	// The default organizer is seeded by a migration and must survive.
+	if _, err := pool.Exec(ctx, `DELETE FROM organizers WHERE id <> $1`, domain.DefaultOrganizerID); err != nil {
+		t.Fatalf("delete organizers: %v", err)
+	}
+}
+
+func InsertOrganizer(t *testing.T, ctx context.Context, pool *pgxpool.Pool, name string) string {
+	t.Helper()
+	var id string
+	if err := pool.QueryRow(ctx,
+		`INSERT INTO organizers (id, name) VALUES (gen_random_uuid(), $1) RETURNING id`,
+		name,
+	).Scan(&id); err != nil {
+		t.Fatalf("insert organizer: %v", err)
+	}
+	return id
 }
 
 func InsertEventAndZone(t *testing.T, ctx context.Context, pool *pgxpool.Pool, name string, capacity int) (eventID, zoneID string) {
@@ -87,10 +103,10 @@ func InsertHold(t *testing.T, ctx context.Context, pool *pgxpool.Pool, eventID,
 	t.Helper()
 	var id string
 	err := pool.QueryRow(ctx, `
-INSERT INTO holds (event_id, zone_id, quantity, status, expires_at, idempotency_key)
-VALUES ($1, $2, $3, $4, $5, $6)
+INSERT INTO holds (event_id, zone_id, quantity, status, expires_at, payment_pending_until, idempotency_key, organizer_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7, (SELECT organizer_id FROM events WHERE id = $1))
 RETURNING id`,
-		eventID, zoneID, hold.Quantity, hold.Status, hold.ExpiresAt, hold.IdempotencyKey,
+		eventID, zoneID, hold.Quantity, hold.Status, hold.ExpiresAt, hold.PaymentPendingUntil, hold.IdempotencyKey,
 	).Scan(&id)
 	if err != nil {
 		t.Fatalf("insert hold: %v", err)
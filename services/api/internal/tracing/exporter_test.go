@@ -0,0 +1,106 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testSpans() []SpanData {
+	parent, _ := ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	return []SpanData{{
+		Name:       "POST /holds",
+		Kind:       SpanKindServer,
+		TraceID:    parent.TraceID,
+		SpanID:     SpanID{1, 2, 3, 4, 5, 6, 7, 8},
+		ParentID:   parent.SpanID,
+		Start:      start,
+		End:        start.Add(25 * time.Millisecond),
+		Attributes: []Attribute{{Key: "http.route", Value: "/holds"}, {Key: "http.status_code", Value: 409}},
+		Error:      "insufficient capacity",
+	}}
+}
+
+func TestWriterExporter(t *testing.T) {
+	t.Parallel()
+
+	var out bytes.Buffer
+	if err := NewWriterExporter(&out).Export(context.Background(), testSpans()); err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	want := `{"trace_id":"4bf92f3577b34da6a3ce929d0e0e4736","span_id":"0102030405060708","parent_span_id":"00f067aa0ba902b7",` +
+		`"name":"POST /holds","start":"2025-01-01T12:00:00Z","duration_ms":25,` +
+		`"attributes":{"http.route":"/holds","http.status_code":409},"error":"insufficient capacity"}` + "\n"
+	if out.String() != want {
+		t.Fatalf("unexpected output:\n%s\nwant:\n%s", out.String(), want)
+	}
+}
+
+func TestOTLPExporter(t *testing.T) {
+	t.Parallel()
+
+	var got otlpRequest
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/v1/traces" || r.Header.Get("Content-Type") != "application/json" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer collector.Close()
+
+	exp := NewOTLPExporter(collector.Client(), collector.URL+"/v1/traces", "ultimate-ticket-api")
+	if err := exp.Export(context.Background(), testSpans()); err != nil {
+		t.Fatalf("export: %v", err)
+	}
+
+	if len(got.ResourceSpans) != 1 || len(got.ResourceSpans[0].ScopeSpans) != 1 {
+		t.Fatalf("unexpected request %+v", got)
+	}
+	if attrs := got.ResourceSpans[0].Resource.Attributes; len(attrs) != 1 || attrs[0].Key != "service.name" ||
+		*attrs[0].Value.StringValue != "ultimate-ticket-api" {
+		t.Fatalf("unexpected resource %+v", attrs)
+	}
+	spans := got.ResourceSpans[0].ScopeSpans[0].Spans
+	if len(spans) != 1 {
+		t.Fatalf("expected 1 span, got %d", len(spans))
+	}
+	span := spans[0]
+	if span.TraceID != "4bf92f3577b34da6a3ce929d0e0e4736" || span.SpanID != "0102030405060708" ||
+		span.ParentSpanID != "00f067aa0ba902b7" || span.Kind != otlpKindServer {
+		t.Fatalf("unexpected span ids %+v", span)
+	}
+	if span.StartTimeUnixNano != "1735732800000000000" || span.EndTimeUnixNano != "1735732800025000000" {
+		t.Fatalf("unexpected times %s %s", span.StartTimeUnixNano, span.EndTimeUnixNano)
+	}
+	if span.Status.Code != otlpStatusError || span.Status.Message != "insufficient capacity" {
+		t.Fatalf("unexpected status %+v", span.Status)
+	}
+	if len(span.Attributes) != 2 || *span.Attributes[0].Value.StringValue != "/holds" || *span.Attributes[1].Value.IntValue != "409" {
+		t.Fatalf("unexpected attributes %+v", span.Attributes)
+	}
+}
+
+func TestOTLPExporter_CollectorError(t *testing.T) {
+	t.Parallel()
+
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer collector.Close()
+
+	err := NewOTLPExporter(collector.Client(), collector.URL, "api").Export(context.Background(), testSpans())
+	if err == nil || !strings.Contains(err.Error(), "503") {
+		t.Fatalf("expected a status error, got %v", err)
+	}
+}
@@ -0,0 +1,315 @@
+// Package tracing records spans across the HTTP handlers, services and
+// database calls of a request, propagates them with W3C traceparent headers
+// and hands finished spans to an Exporter in batches.
+package tracing
+
+import (
+	"context"
+	"encoding/hex"
+	"math/rand/v2"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cimillas/ultimate-ticket/services/api/internal/clock"
+)
+
+// TraceparentHeader carries the W3C trace context between services.
+const TraceparentHeader = "traceparent"
+
+// DefaultMaxQueue is how many finished spans wait for the next export before
+// new ones are dropped.
+const DefaultMaxQueue = 2048
+
+type TraceID [16]byte
+
+func (id TraceID) String() string { return hex.EncodeToString(id[:]) }
+func (id TraceID) IsValid() bool  { return id != TraceID{} }
+
+type SpanID [8]byte
+
+func (id SpanID) String() string { return hex.EncodeToString(id[:]) }
+func (id SpanID) IsValid() bool  { return id != SpanID{} }
+
+// SpanContext identifies a span across process boundaries.
+type SpanContext struct {
+	TraceID TraceID
+	SpanID  SpanID
+	Sampled bool
+}
+
+func (sc SpanContext) IsValid() bool { return sc.TraceID.IsValid() && sc.SpanID.IsValid() }
+
+// ParseTraceparent reads a W3C traceparent header, version 00 or later.
+func ParseTraceparent(header string) (SpanContext, bool) {
+	parts := strings.Split(strings.TrimSpace(header), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" {
+		return SpanContext{}, false
+	}
+	if parts[0] == "00" && len(parts) != 4 {
+		return SpanContext{}, false
+	}
+	var sc SpanContext
+	var flags [1]byte
+	if !decodeHex(sc.TraceID[:], parts[1]) || !decodeHex(sc.SpanID[:], parts[2]) || !decodeHex(flags[:], parts[3]) {
+		return SpanContext{}, false
+	}
+	if _, err := hex.DecodeString(parts[0]); err != nil || !sc.IsValid() {
+		return SpanContext{}, false
+	}
+	sc.Sampled = flags[0]&1 == 1
+	return sc, true
+}
+
+// decodeHex decodes lowercase hex of exactly len(dst) bytes.
+func decodeHex(dst []byte, s string) bool {
+	if len(s) != 2*len(dst) || strings.ToLower(s) != s {
+		return false
+	}
+	_, err := hex.Decode(dst, []byte(s))
+	return err == nil
+}
+
+// Traceparent formats the span context as a version 00 traceparent header.
+func (sc SpanContext) Traceparent() string {
+	flags := "00"
+	if sc.Sampled {
+		flags = "01"
+	}
+	return "00-" + sc.TraceID.String() + "-" + sc.SpanID.String() + "-" + flags
+}
+
+type SpanKind int
+
+const (
+	SpanKindInternal SpanKind = iota
+	SpanKindServer
+	SpanKindClient
+)
+
+// Attribute is a span attribute; values are strings, ints, floats or bools.
+type Attribute struct {
+	Key   string
+	Value any
+}
+
+// SpanData is a finished span as handed to an Exporter.
+type SpanData struct {
+	Name       string
+	Kind       SpanKind
+	TraceID    TraceID
+	SpanID     SpanID
+	ParentID   SpanID
+	Start      time.Time
+	End        time.Time
+	Attributes []Attribute
+	// Error is the message of the error that failed the span, if any.
+	Error string
+}
+
+// Exporter sends finished spans somewhere.
+type Exporter interface {
+	Export(ctx context.Context, spans []SpanData) error
+}
+
+// Tracer starts spans and queues them for export once they end.
+type Tracer struct {
+	exporter    Exporter
+	clock       clock.Clock
+	sampleRatio float64
+	maxQueue    int
+
+	mu      sync.Mutex
+	pending []SpanData
+}
+
+type Option func(*Tracer)
+
+// WithSampleRatio records only this share of new traces, between 0 and 1.
+// Traces started elsewhere keep the caller's sampling decision.
+func WithSampleRatio(ratio float64) Option {
+	return func(t *Tracer) {
+		t.sampleRatio = min(max(ratio, 0), 1)
+	}
+}
+
+// WithMaxQueue bounds the spans kept between exports.
+func WithMaxQueue(n int) Option {
+	return func(t *Tracer) {
+		if n > 0 {
+			t.maxQueue = n
+		}
+	}
+}
+
+func NewTracer(exporter Exporter, clk clock.Clock, opts ...Option) *Tracer {
+	t := &Tracer{exporter: exporter, clock: clk, sampleRatio: 1, maxQueue: DefaultMaxQueue}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t
+}
+
+// StartRemote starts the local root span of a request, continuing the trace
+// in parent when it is valid and starting a new one otherwise.
+func (t *Tracer) StartRemote(ctx context.Context, name string, kind SpanKind, parent SpanContext) (context.Context, *Span) {
+	sc := SpanContext{SpanID: newSpanID()}
+	var parentID SpanID
+	if parent.IsValid() {
+		sc.TraceID, sc.Sampled, parentID = parent.TraceID, parent.Sampled, parent.SpanID
+	} else {
+		sc.TraceID = newTraceID()
+		sc.Sampled = t.sample(sc.TraceID)
+	}
+	span := &Span{tracer: t, sc: sc, data: SpanData{
+		Name: name, Kind: kind, TraceID: sc.TraceID, SpanID: sc.SpanID, ParentID: parentID, Start: t.clock.Now(),
+	}}
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+// sample keeps a trace when the low 63 bits of its id fall under the ratio, so
+// every service sampling the same trace id at the same ratio agrees.
+func (t *Tracer) sample(id TraceID) bool {
+	var low uint64
+	for _, b := range id[8:] {
+		low = low<<8 | uint64(b)
+	}
+	return float64(low>>1) < t.sampleRatio*(1<<63)
+}
+
+func (t *Tracer) record(data SpanData) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(t.pending) < t.maxQueue {
+		t.pending = append(t.pending, data)
+	}
+}
+
+// Flush exports the spans that ended since the last flush and reports how many
+// were sent.
+func (t *Tracer) Flush(ctx context.Context) (int, error) {
+	t.mu.Lock()
+	spans := t.pending
+	t.pending = nil
+	t.mu.Unlock()
+	if len(spans) == 0 {
+		return 0, nil
+	}
+	if err := t.exporter.Export(ctx, spans); err != nil {
+		return 0, err
+	}
+	return len(spans), nil
+}
+
+type spanKey struct{}
+
+// Start starts a child of the span in ctx. Without one it returns ctx and a nil
+// span, whose methods do nothing, so untraced calls cost a context lookup.
+func Start(ctx context.Context, name string) (context.Context, *Span) {
+	parent := SpanFromContext(ctx)
+	if parent == nil {
+		return ctx, nil
+	}
+	t := parent.tracer
+	sc := SpanContext{TraceID: parent.sc.TraceID, SpanID: newSpanID(), Sampled: parent.sc.Sampled}
+	span := &Span{tracer: t, sc: sc, data: SpanData{
+		Name: name, TraceID: sc.TraceID, SpanID: sc.SpanID, ParentID: parent.sc.SpanID, Start: t.clock.Now(),
+	}}
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+// Inject sets the traceparent header of an outgoing request to the span in
+// ctx, so the service called can continue the trace. Without a span it leaves
+// h alone.
+func Inject(ctx context.Context, h http.Header) {
+	if sc := SpanFromContext(ctx).Context(); sc.IsValid() {
+		h.Set(TraceparentHeader, sc.Traceparent())
+	}
+}
+
+// SpanFromContext returns the current span, or nil.
+func SpanFromContext(ctx context.Context) *Span {
+	span, _ := ctx.Value(spanKey{}).(*Span)
+	return span
+}
+
+// Span is an operation in progress. A nil Span is valid and does nothing.
+type Span struct {
+	tracer *Tracer
+	sc     SpanContext
+
+	mu    sync.Mutex
+	data  SpanData
+	ended bool
+}
+
+func (s *Span) Context() SpanContext {
+	if s == nil {
+		return SpanContext{}
+	}
+	return s.sc
+}
+
+func (s *Span) SetAttr(key string, value any) {
+	if s == nil || !s.sc.Sampled {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data.Attributes = append(s.data.Attributes, Attribute{Key: key, Value: value})
+}
+
+// SetError marks the span failed; nil errors are ignored.
+func (s *Span) SetError(err error) {
+	if s == nil || err == nil || !s.sc.Sampled {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data.Error = err.Error()
+}
+
+// End finishes the span and queues it for export if it is sampled. Later calls
+// do nothing.
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.data.End = s.tracer.clock.Now()
+	data := s.data
+	s.mu.Unlock()
+	if s.sc.Sampled {
+		s.tracer.record(data)
+	}
+}
+
+func newTraceID() TraceID {
+	var id TraceID
+	for !id.IsValid() {
+		putUint64(id[:8], rand.Uint64())
+		putUint64(id[8:], rand.Uint64())
+	}
+	return id
+}
+
+func newSpanID() SpanID {
+	var id SpanID
+	for !id.IsValid() {
+		putUint64(id[:], rand.Uint64())
+	}
+	return id
+}
+
+func putUint64(b []byte, v uint64) {
+	for i := 7; i >= 0; i-- {
+		b[i] = byte(v)
+		v >>= 8
+	}
+}
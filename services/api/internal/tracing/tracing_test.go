@@ -0,0 +1,187 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/cimillas/ultimate-ticket/services/api/internal/clock"
+)
+
+type recordingExporter struct {
+	spans []SpanData
+}
+
+func (r *recordingExporter) Export(_ context.Context, spans []SpanData) error {
+	r.spans = append(r.spans, spans...)
+	return nil
+}
+
+func TestParseTraceparent(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		header  string
+		ok      bool
+		sampled bool
+	}{
+		{name: "sampled", header: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", ok: true, sampled: true},
+		{name: "not sampled", header: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00", ok: true},
+		{name: "future version with extra field", header: "01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra", ok: true, sampled: true},
+		{name: "version 00 with extra field", header: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra"},
+		{name: "invalid version", header: "ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"},
+		{name: "zero trace id", header: "00-00000000000000000000000000000000-00f067aa0ba902b7-01"},
+		{name: "zero span id", header: "00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01"},
+		{name: "uppercase", header: "00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01"},
+		{name: "short trace id", header: "00-4bf92f3577b34da6-00f067aa0ba902b7-01"},
+		{name: "empty", header: ""},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			sc, ok := ParseTraceparent(tt.header)
+			if ok != tt.ok {
+				t.Fatalf("expected ok=%v, got %v", tt.ok, ok)
+			}
+			if !ok {
+				return
+			}
+			if sc.Sampled != tt.sampled {
+				t.Fatalf("expected sampled=%v, got %v", tt.sampled, sc.Sampled)
+			}
+			if sc.TraceID.String() != "4bf92f3577b34da6a3ce929d0e0e4736" || sc.SpanID.String() != "00f067aa0ba902b7" {
+				t.Fatalf("unexpected ids %s %s", sc.TraceID, sc.SpanID)
+			}
+		})
+	}
+
+	sc, _ := ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	if got := sc.Traceparent(); got != "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01" {
+		t.Fatalf("unexpected traceparent %q", got)
+	}
+}
+
+func TestTracer_SpansAndFlush(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	exp := &recordingExporter{}
+	tracer := NewTracer(exp, clock.NewFixed(now))
+	parent, _ := ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+	ctx, root := tracer.StartRemote(context.Background(), "POST /holds", SpanKindServer, parent)
+	childCtx, child := Start(ctx, "HoldService.CreateHold")
+	child.SetAttr("quantity", 2)
+	child.SetError(errors.New("insufficient capacity"))
+	_, grandchild := Start(childCtx, "db.transaction")
+	grandchild.End()
+	child.End()
+	child.End()
+	root.End()
+
+	n, err := tracer.Flush(context.Background())
+	if err != nil || n != 3 {
+		t.Fatalf("expected 3 spans flushed, got %d, %v", n, err)
+	}
+	if n, _ := tracer.Flush(context.Background()); n != 0 {
+		t.Fatalf("expected nothing left to flush, got %d", n)
+	}
+
+	byName := make(map[string]SpanData)
+	for _, s := range exp.spans {
+		if s.TraceID != parent.TraceID {
+			t.Fatalf("span %q left the caller's trace: %s", s.Name, s.TraceID)
+		}
+		byName[s.Name] = s
+	}
+	if got := byName["POST /holds"]; got.ParentID != parent.SpanID || got.Kind != SpanKindServer {
+		t.Fatalf("root should continue the remote span, got %+v", got)
+	}
+	if got := byName["HoldService.CreateHold"]; got.ParentID != root.Context().SpanID || got.Error != "insufficient capacity" ||
+		len(got.Attributes) != 1 || got.Attributes[0] != (Attribute{Key: "quantity", Value: 2}) {
+		t.Fatalf("unexpected child span %+v", got)
+	}
+	if got := byName["db.transaction"]; got.ParentID != child.Context().SpanID {
+		t.Fatalf("unexpected grandchild span %+v", got)
+	}
+}
+
+func TestTracer_Sampling(t *testing.T) {
+	t.Parallel()
+
+	exp := &recordingExporter{}
+	tracer := NewTracer(exp, clock.NewSystem(), WithSampleRatio(0))
+
+	ctx, root := tracer.StartRemote(context.Background(), "GET /health", SpanKindServer, SpanContext{})
+	_, child := Start(ctx, "work")
+	if !root.Context().IsValid() || root.Context().Sampled {
+		t.Fatalf("expected a valid unsampled context, got %+v", root.Context())
+	}
+	if child.Context().TraceID != root.Context().TraceID {
+		t.Fatal("expected unsampled spans to keep propagating the trace id")
+	}
+	child.End()
+	root.End()
+
+	sampled, _ := ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	_, remote := tracer.StartRemote(context.Background(), "POST /holds", SpanKindServer, sampled)
+	remote.End()
+
+	if _, err := tracer.Flush(context.Background()); err != nil {
+		t.Fatalf("flush: %v", err)
+	}
+	if len(exp.spans) != 1 || exp.spans[0].Name != "POST /holds" {
+		t.Fatalf("expected only the caller-sampled span, got %+v", exp.spans)
+	}
+}
+
+func TestStart_WithoutSpan(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	got, span := Start(ctx, "untraced")
+	if span != nil || got != ctx {
+		t.Fatal("expected no span outside a trace")
+	}
+	span.SetAttr("key", "value")
+	span.SetError(errors.New("boom"))
+	span.End()
+}
+
+func TestInject(t *testing.T) {
+	t.Parallel()
+
+	h := http.Header{}
+	Inject(context.Background(), h)
+	if got := h.Get(TraceparentHeader); got != "" {
+		t.Fatalf("expected no traceparent outside a trace, got %q", got)
+	}
+
+	tracer := NewTracer(&recordingExporter{}, clock.NewFixed(time.Now()))
+	ctx, root := tracer.StartRemote(context.Background(), "request", SpanKindServer, SpanContext{})
+	ctx, child := Start(ctx, "call")
+	Inject(ctx, h)
+	got, ok := ParseTraceparent(h.Get(TraceparentHeader))
+	if !ok || got != child.Context() || got.TraceID != root.Context().TraceID {
+		t.Fatalf("expected the current span's context, got %q", h.Get(TraceparentHeader))
+	}
+}
+
+func TestTracer_MaxQueue(t *testing.T) {
+	t.Parallel()
+
+	exp := &recordingExporter{}
+	tracer := NewTracer(exp, clock.NewSystem(), WithMaxQueue(2))
+	for i := 0; i < 3; i++ {
+		_, span := tracer.StartRemote(context.Background(), "span", SpanKindServer, SpanContext{})
+		span.End()
+	}
+	if n, _ := tracer.Flush(context.Background()); n != 2 {
+		t.Fatalf("expected the queue to keep 2 spans, got %d", n)
+	}
+}
@@ -3,6 +3,7 @@ package http
 import (
 	"context"
 	"encoding/json"
+	"log/slog"
 	"net/http"
 	"strings"
 	"time"
@@ -14,13 +15,18 @@ import (
 // AdminEventService is the minimal interface needed for admin event endpoints.
 type AdminEventService interface {
 	CreateEvent(ctx context.Context, in app.CreateEventInput) (domain.Event, error)
-	ListEvents(ctx context.Context) ([]domain.Event, error)
+	ListEvents(ctx context.Context, organizerID string) ([]domain.Event, error)
+}
+
+// AdminEventUpdater is the minimal interface needed to update an event.
+type AdminEventUpdater interface {
+	UpdateEvent(ctx context.Context, in app.UpdateEventInput) (domain.Event, error)
 }
 
 // AdminZoneService is the minimal interface needed for admin zone endpoints.
 type AdminZoneService interface {
 	CreateZone(ctx context.Context, in app.CreateZoneInput) (domain.Zone, error)
-	ListZones(ctx context.Context, eventID string) ([]domain.Zone, error)
+	ListZones(ctx context.Context, organizerID, eventID string) ([]domain.Zone, error)
 }
 
 // HandleAdminEvents returns an HTTP handler for admin event creation/listing.
@@ -28,23 +34,27 @@ func HandleAdminEvents(svc AdminEventService) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		switch r.Method {
 		case http.MethodGet:
-			events, err := svc.ListEvents(r.Context())
+			organizerID, ok := adminOrganizer(w, r)
+			if !ok {
+				return
+			}
+			events, err := svc.ListEvents(r.Context(), organizerID)
 			if err != nil {
-				writeError(w, http.StatusInternalServerError, codeInternalError, "internal error")
+				writeInternalError(w, r, err)
 				return
 			}
 			resp := make([]eventResponse, 0, len(events))
 			for _, event := range events {
-				resp = append(resp, eventResponse{
-					ID:       event.ID,
-					Name:     event.Name,
-					StartsAt: event.StartsAt,
-				})
+				resp = append(resp, newEventResponse(event))
 			}
 			w.Header().Set("Content-Type", "application/json")
 			_ = json.NewEncoder(w).Encode(resp)
 			return
 		case http.MethodPost:
+			organizerID, ok := adminOrganizer(w, r)
+			if !ok {
+				return
+			}
 			var req createEventRequest
 			dec := json.NewDecoder(r.Body)
 			dec.DisallowUnknownFields()
@@ -67,25 +77,31 @@ func HandleAdminEvents(svc AdminEventService) http.HandlerFunc {
 				startsAt = &parsed
 			}
 
-			event, err := svc.CreateEvent(r.Context(), app.CreateEventInput{
-				Name:     req.Name,
-				StartsAt: startsAt,
-			})
+			in := app.CreateEventInput{
+				OrganizerID:   organizerID,
+				Name:          req.Name,
+				StartsAt:      startsAt,
+				PurchaseLimit: req.PurchaseLimit,
+			}
+			if req.WaitingRoom != nil {
+				in.WaitingRoom = req.WaitingRoom.toDomain()
+			}
+			event, err := svc.CreateEvent(r.Context(), in)
 			if err != nil {
 				switch err {
 				case domain.ErrEventNameRequired:
 					writeError(w, http.StatusBadRequest, codeEventNameRequired, err.Error())
+				case domain.ErrInvalidAdmissionRate:
+					writeError(w, http.StatusBadRequest, codeInvalidAdmissionRate, err.Error())
+				case domain.ErrInvalidPurchaseLimit:
+					writeError(w, http.StatusBadRequest, codeInvalidPurchaseLimit, err.Error())
 				default:
-					writeError(w, http.StatusInternalServerError, codeInternalError, "internal error")
+					writeInternalError(w, r, err)
 				}
 				return
 			}
 
-			resp := eventResponse{
-				ID:       event.ID,
-				Name:     event.Name,
-				StartsAt: event.StartsAt,
-			}
+			resp := newEventResponse(event)
 			w.Header().Set("Content-Type", "application/json")
 			w.WriteHeader(http.StatusCreated)
 			_ = json.NewEncoder(w).Encode(resp)
@@ -97,6 +113,71 @@ func HandleAdminEvents(svc AdminEventService) http.HandlerFunc {
 	}
 }
 
+// HandleAdminEvent returns an HTTP handler for PATCH /admin/events/{id}.
+// Other paths under /admin/events/ are passed to next.
+func HandleAdminEvent(svc AdminEventUpdater, next http.Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		eventID, ok := parseAdminEventPath(r.URL.Path)
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+		logAttrs(r, slog.String("event_id", eventID))
+		if r.Method != http.MethodPatch {
+			writeError(w, http.StatusMethodNotAllowed, codeMethodNotAllowed, "method not allowed")
+			return
+		}
+		organizerID, ok := adminOrganizer(w, r)
+		if !ok {
+			return
+		}
+
+		var req updateEventRequest
+		dec := json.NewDecoder(r.Body)
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, codeInvalidRequestBody, "invalid request body")
+			return
+		}
+
+		in := app.UpdateEventInput{OrganizerID: organizerID, EventID: eventID, Name: req.Name, PurchaseLimit: req.PurchaseLimit}
+		if req.StartsAt != nil {
+			parsed, err := time.Parse(time.RFC3339, *req.StartsAt)
+			if err != nil {
+				writeError(w, http.StatusBadRequest, codeInvalidStartsAt, "invalid starts_at format")
+				return
+			}
+			in.StartsAt = &parsed
+		}
+		if req.WaitingRoom != nil {
+			room := req.WaitingRoom.toDomain()
+			in.WaitingRoom = &room
+		}
+
+		event, err := svc.UpdateEvent(r.Context(), in)
+		if err != nil {
+			switch err {
+			case domain.ErrInvalidID:
+				writeError(w, http.StatusNotFound, codeInvalidID, err.Error())
+			case domain.ErrEventNotFound:
+				writeError(w, http.StatusNotFound, codeEventNotFound, err.Error())
+			case domain.ErrEventNameRequired:
+				writeError(w, http.StatusBadRequest, codeEventNameRequired, err.Error())
+			case domain.ErrInvalidAdmissionRate:
+				writeError(w, http.StatusBadRequest, codeInvalidAdmissionRate, err.Error())
+			case domain.ErrInvalidPurchaseLimit:
+				writeError(w, http.StatusBadRequest, codeInvalidPurchaseLimit, err.Error())
+			default:
+				writeInternalError(w, r, err)
+			}
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(newEventResponse(event))
+	}
+}
+
 // HandleAdminZones returns an HTTP handler for admin zone creation/listing.
 func HandleAdminZones(svc AdminZoneService) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -105,10 +186,15 @@ func HandleAdminZones(svc AdminZoneService) http.HandlerFunc {
 			writeError(w, http.StatusNotFound, codeNotFound, "not found")
 			return
 		}
+		logAttrs(r, slog.String("event_id", eventID))
 
 		switch r.Method {
 		case http.MethodGet:
-			zones, err := svc.ListZones(r.Context(), eventID)
+			organizerID, ok := adminOrganizer(w, r)
+			if !ok {
+				return
+			}
+			zones, err := svc.ListZones(r.Context(), organizerID, eventID)
 			if err != nil {
 				switch err {
 				case domain.ErrInvalidID:
@@ -116,23 +202,22 @@ func HandleAdminZones(svc AdminZoneService) http.HandlerFunc {
 				case domain.ErrEventNotFound:
 					writeError(w, http.StatusNotFound, codeEventNotFound, err.Error())
 				default:
-					writeError(w, http.StatusInternalServerError, codeInternalError, "internal error")
+					writeInternalError(w, r, err)
 				}
 				return
 			}
 			resp := make([]zoneResponse, 0, len(zones))
 			for _, zone := range zones {
-				resp = append(resp, zoneResponse{
-					ID:       zone.ID,
-					EventID:  zone.EventID,
-					Name:     zone.Name,
-					Capacity: zone.Capacity,
-				})
+				resp = append(resp, newZoneResponse(zone))
 			}
 			w.Header().Set("Content-Type", "application/json")
 			_ = json.NewEncoder(w).Encode(resp)
 			return
 		case http.MethodPost:
+			organizerID, ok := adminOrganizer(w, r)
+			if !ok {
+				return
+			}
 			var req createZoneRequest
 			dec := json.NewDecoder(r.Body)
 			dec.DisallowUnknownFields()
@@ -150,9 +235,14 @@ func HandleAdminZones(svc AdminZoneService) http.HandlerFunc {
 			}
 
 			zone, err := svc.CreateZone(r.Context(), app.CreateZoneInput{
-				EventID:  eventID,
-				Name:     req.Name,
-				Capacity: req.Capacity,
+				OrganizerID:   organizerID,
+				EventID:       eventID,
+				Name:          req.Name,
+				Capacity:      req.Capacity,
+				MinQuantity:   req.MinQuantity,
+				MaxQuantity:   req.MaxQuantity,
+				QuantityStep:  req.QuantityStep,
+				PurchaseLimit: req.PurchaseLimit,
 			})
 			if err != nil {
 				switch err {
@@ -164,22 +254,21 @@ func HandleAdminZones(svc AdminZoneService) http.HandlerFunc {
 						code = codeZoneNameRequired
 					}
 					writeError(w, http.StatusBadRequest, code, err.Error())
+				case domain.ErrInvalidQuantityRules:
+					writeError(w, http.StatusBadRequest, codeInvalidQuantityRules, err.Error())
+				case domain.ErrInvalidPurchaseLimit:
+					writeError(w, http.StatusBadRequest, codeInvalidPurchaseLimit, err.Error())
 				case domain.ErrEventNotFound:
 					writeError(w, http.StatusNotFound, codeEventNotFound, err.Error())
 				case domain.ErrZoneAlreadyExists:
 					writeError(w, http.StatusConflict, codeZoneAlreadyExists, err.Error())
 				default:
-					writeError(w, http.StatusInternalServerError, codeInternalError, "internal error")
+					writeInternalError(w, r, err)
 				}
 				return
 			}
 
-			resp := zoneResponse{
-				ID:       zone.ID,
-				EventID:  zone.EventID,
-				Name:     zone.Name,
-				Capacity: zone.Capacity,
-			}
+			resp := newZoneResponse(zone)
 			w.Header().Set("Content-Type", "application/json")
 			w.WriteHeader(http.StatusCreated)
 			_ = json.NewEncoder(w).Encode(resp)
@@ -192,26 +281,92 @@ func HandleAdminZones(svc AdminZoneService) http.HandlerFunc {
 }
 
 type createEventRequest struct {
-	Name     string `json:"name"`
-	StartsAt string `json:"starts_at,omitempty"`
+	Name        string               `json:"name"`
+	StartsAt    string               `json:"starts_at,omitempty"`
+	WaitingRoom *waitingRoomSettings `json:"waiting_room,omitempty"`
+	// PurchaseLimit caps the tickets per customer; 0 or omitted means
+	// unlimited.
+	PurchaseLimit int `json:"purchase_limit,omitempty"`
+}
+
+// updateEventRequest fields are optional; omitted fields are left unchanged.
+type updateEventRequest struct {
+	Name     *string `json:"name,omitempty"`
+	StartsAt *string `json:"starts_at,omitempty"`
+	// WaitingRoom replaces the event's waiting room settings as a whole.
+	WaitingRoom *waitingRoomSettings `json:"waiting_room,omitempty"`
+	// PurchaseLimit 0 removes the event's limit.
+	PurchaseLimit *int `json:"purchase_limit,omitempty"`
+}
+
+type waitingRoomSettings struct {
+	Enabled        bool `json:"enabled"`
+	AdmitPerMinute int  `json:"admit_per_minute"`
+}
+
+func (s waitingRoomSettings) toDomain() domain.WaitingRoom {
+	return domain.WaitingRoom{Enabled: s.Enabled, AdmitPerMinute: s.AdmitPerMinute}
 }
 
 type eventResponse struct {
-	ID       string    `json:"id"`
-	Name     string    `json:"name"`
-	StartsAt time.Time `json:"starts_at"`
+	ID          string              `json:"id"`
+	Name        string              `json:"name"`
+	StartsAt    time.Time           `json:"starts_at"`
+	WaitingRoom waitingRoomSettings `json:"waiting_room"`
+	// PurchaseLimit is 0 when customers are not limited.
+	PurchaseLimit int `json:"purchase_limit"`
+}
+
+func newEventResponse(event domain.Event) eventResponse {
+	return eventResponse{
+		ID:       event.ID,
+		Name:     event.Name,
+		StartsAt: event.StartsAt,
+		WaitingRoom: waitingRoomSettings{
+			Enabled:        event.WaitingRoom.Enabled,
+			AdmitPerMinute: event.WaitingRoom.AdmitPerMinute,
+		},
+		PurchaseLimit: event.PurchaseLimit,
+	}
 }
 
+// createZoneRequest quantity rules are optional; the minimum defaults to one
+// step and the step to 1, with no maximum. The purchase limit defaults to
+// none.
 type createZoneRequest struct {
-	Name     string `json:"name"`
-	Capacity int    `json:"capacity"`
+	Name          string `json:"name"`
+	Capacity      int    `json:"capacity"`
+	MinQuantity   int    `json:"min_quantity,omitempty"`
+	MaxQuantity   int    `json:"max_quantity,omitempty"`
+	QuantityStep  int    `json:"quantity_step,omitempty"`
+	PurchaseLimit int    `json:"purchase_limit,omitempty"`
 }
 
+// zoneResponse reports max_quantity and purchase_limit 0 when a zone has no
+// maximum or limit.
 type zoneResponse struct {
-	ID       string `json:"id"`
-	EventID  string `json:"event_id"`
-	Name     string `json:"name"`
-	Capacity int    `json:"capacity"`
+	ID            string `json:"id"`
+	EventID       string `json:"event_id"`
+	Name          string `json:"name"`
+	Capacity      int    `json:"capacity"`
+	MinQuantity   int    `json:"min_quantity"`
+	MaxQuantity   int    `json:"max_quantity"`
+	QuantityStep  int    `json:"quantity_step"`
+	PurchaseLimit int    `json:"purchase_limit"`
+}
+
+func newZoneResponse(zone domain.Zone) zoneResponse {
+	minQty, maxQty, step := zone.QuantityRules()
+	return zoneResponse{
+		ID:            zone.ID,
+		EventID:       zone.EventID,
+		Name:          zone.Name,
+		Capacity:      zone.Capacity,
+		MinQuantity:   minQty,
+		MaxQuantity:   maxQty,
+		QuantityStep:  step,
+		PurchaseLimit: zone.PurchaseLimit,
+	}
 }
 
 func parseAdminEventZonesPath(path string) (string, bool) {
@@ -227,3 +382,11 @@ func parseAdminEventZonesPath(path string) (string, bool) {
 	}
 	return parts[2], true
 }
+
+func parseAdminEventPath(path string) (string, bool) {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	if len(parts) != 3 || parts[0] != "admin" || parts[1] != "events" || parts[2] == "" {
+		return "", false
+	}
+	return parts[2], true
+}
@@ -11,6 +11,7 @@ import (
 
 	"github.com/cimillas/ultimate-ticket/services/api/internal/app"
 	"github.com/cimillas/ultimate-ticket/services/api/internal/clock"
+	"github.com/cimillas/ultimate-ticket/services/api/internal/domain"
 	"github.com/cimillas/ultimate-ticket/services/api/internal/storage/postgres"
 	"github.com/cimillas/ultimate-ticket/services/api/internal/testutil"
 )
@@ -33,7 +34,7 @@ func TestAdminEvents_HTTPIntegration(t *testing.T) {
 	handler := HandleAdminEvents(svc)
 
 	reqBody := []byte(`{"name":"Concert","starts_at":"2025-02-01T10:00:00Z"}`)
-	req := httptest.NewRequest(http.MethodPost, "/admin/events", bytes.NewBuffer(reqBody))
+	req := withAdminKey(httptest.NewRequest(http.MethodPost, "/admin/events", bytes.NewBuffer(reqBody)), domain.DefaultOrganizerID)
 	rec := httptest.NewRecorder()
 	handler.ServeHTTP(rec, req)
 
@@ -49,7 +50,7 @@ func TestAdminEvents_HTTPIntegration(t *testing.T) {
 		t.Fatalf("expected event id to be set")
 	}
 
-	listReq := httptest.NewRequest(http.MethodGet, "/admin/events", nil)
+	listReq := withAdminKey(httptest.NewRequest(http.MethodGet, "/admin/events", nil), domain.DefaultOrganizerID)
 	listRec := httptest.NewRecorder()
 	handler.ServeHTTP(listRec, listReq)
 
@@ -81,7 +82,7 @@ func TestAdminZones_HTTPIntegration(t *testing.T) {
 	handler := HandleAdminZones(svc)
 
 	reqBody := []byte(`{"name":"Zone B","capacity":40}`)
-	req := httptest.NewRequest(http.MethodPost, "/admin/events/"+eventID+"/zones", bytes.NewBuffer(reqBody))
+	req := withAdminKey(httptest.NewRequest(http.MethodPost, "/admin/events/"+eventID+"/zones", bytes.NewBuffer(reqBody)), domain.DefaultOrganizerID)
 	rec := httptest.NewRecorder()
 	handler.ServeHTTP(rec, req)
 
@@ -97,7 +98,7 @@ func TestAdminZones_HTTPIntegration(t *testing.T) {
 		t.Fatalf("expected event id %s, got %s", eventID, created.EventID)
 	}
 
-	listReq := httptest.NewRequest(http.MethodGet, "/admin/events/"+eventID+"/zones", nil)
+	listReq := withAdminKey(httptest.NewRequest(http.MethodGet, "/admin/events/"+eventID+"/zones", nil), domain.DefaultOrganizerID)
 	listRec := httptest.NewRecorder()
 	handler.ServeHTTP(listRec, listReq)
 
@@ -113,7 +114,7 @@ func TestAdminZones_HTTPIntegration(t *testing.T) {
 		t.Fatalf("expected 2 zones, got %d", len(zones))
 	}
 
-	invalidReq := httptest.NewRequest(http.MethodGet, "/admin/events/not-a-uuid/zones", nil)
+	invalidReq := withAdminKey(httptest.NewRequest(http.MethodGet, "/admin/events/not-a-uuid/zones", nil), domain.DefaultOrganizerID)
 	invalidRec := httptest.NewRecorder()
 	handler.ServeHTTP(invalidRec, invalidReq)
 
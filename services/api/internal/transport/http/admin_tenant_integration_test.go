@@ -0,0 +1,200 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cimillas/ultimate-ticket/services/api/internal/app"
+	"github.com/cimillas/ultimate-ticket/services/api/internal/clock"
+	"github.com/cimillas/ultimate-ticket/services/api/internal/domain"
+	"github.com/cimillas/ultimate-ticket/services/api/internal/notify"
+	"github.com/cimillas/ultimate-ticket/services/api/internal/storage/postgres"
+	"github.com/cimillas/ultimate-ticket/services/api/internal/testutil"
+)
+
+// TestAdminTenantIsolation_HTTPIntegration seeds data for one organizer and
+// checks that another organizer's owner key can neither read nor change it
+// through any /admin route.
+func TestAdminTenantIsolation_HTTPIntegration(t *testing.T) {
+	pool := testutil.NewTestPool(t)
+	testutil.ApplyMigrations(t, context.Background(), pool)
+
+	ctx := context.Background()
+	testutil.TruncateAll(t, ctx, pool)
+	now := time.Date(2025, 1, 6, 10, 0, 0, 0, time.UTC)
+	clk := clock.NewFixed(now)
+
+	apiKeySvc := app.NewAPIKeyService(postgres.NewAPIKeyRepository(pool), clk)
+	organizerSvc := app.NewOrganizerService(postgres.NewOrganizerRepository(pool), apiKeySvc, clk)
+	adminSvc := app.NewAdminService(postgres.NewAdminRepository(pool), clk)
+	auditSvc := app.NewAuditService(postgres.NewAuditRepository(pool))
+	webhookSvc := app.NewWebhookService(postgres.NewWebhookRepository(pool), nil, clk)
+	renderer, err := notify.NewRenderer(time.UTC)
+	if err != nil {
+		t.Fatalf("renderer: %v", err)
+	}
+	notificationSvc := app.NewNotificationService(postgres.NewNotificationRepository(pool), renderer,
+		notify.NewLogMailer(slog.New(slog.NewTextHandler(io.Discard, nil))), clk)
+
+	owner := AdminAccess{}
+	mux := http.NewServeMux()
+	admin := func(h http.Handler) http.Handler { return RequireAPIKey(apiKeySvc, owner, h) }
+	mux.Handle("/admin/events", admin(HandleAdminEvents(adminSvc)))
+	mux.Handle("/admin/events/", admin(HandleAdminEvent(adminSvc, HandleAdminZones(adminSvc))))
+	mux.Handle("/admin/webhooks", admin(HandleAdminWebhooks(webhookSvc)))
+	mux.Handle("/admin/webhooks/", admin(HandleAdminWebhook(webhookSvc)))
+	mux.Handle("/admin/notifications", admin(HandleAdminNotifications(notificationSvc)))
+	mux.Handle("/admin/notifications/", admin(HandleAdminNotification(notificationSvc)))
+	mux.Handle("/admin/api-keys", admin(HandleAdminAPIKeys(apiKeySvc)))
+	mux.Handle("/admin/api-keys/", admin(HandleAdminAPIKey(apiKeySvc)))
+	mux.Handle("/admin/audit", admin(HandleAdminAudit(auditSvc)))
+
+	acme, err := organizerSvc.CreateOrganizer(ctx, app.CreateOrganizerInput{Name: "Acme", OwnerKeyName: "acme owner"})
+	if err != nil {
+		t.Fatalf("create organizer: %v", err)
+	}
+	rival, err := organizerSvc.CreateOrganizer(ctx, app.CreateOrganizerInput{Name: "Rival", OwnerKeyName: "rival owner"})
+	if err != nil {
+		t.Fatalf("create organizer: %v", err)
+	}
+
+	do := func(secret, method, path, body string) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set(APIKeyHeader, secret)
+		req.Header.Set(RequestIDHeader, "req-tenant-test")
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		return rec
+	}
+	decode := func(rec *httptest.ResponseRecorder, v any) {
+		t.Helper()
+		if err := json.NewDecoder(rec.Body).Decode(v); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+	}
+
+	// Acme's data, created through the API where possible.
+	rec := do(acme.OwnerKey.Secret, http.MethodPost, "/admin/events", `{"name":"Acme Night","starts_at":"2025-03-01T20:00:00Z"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create event: %d %s", rec.Code, rec.Body.String())
+	}
+	var event eventResponse
+	decode(rec, &event)
+	if rec := do(acme.OwnerKey.Secret, http.MethodPost, "/admin/events/"+event.ID+"/zones", `{"name":"Floor","capacity":100}`); rec.Code != http.StatusCreated {
+		t.Fatalf("create zone: %d %s", rec.Code, rec.Body.String())
+	}
+	rec = do(acme.OwnerKey.Secret, http.MethodPost, "/admin/webhooks", `{"url":"https://acme.example.com/hook","secret":"shh"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create webhook: %d %s", rec.Code, rec.Body.String())
+	}
+	var sub webhookSubscriptionResponse
+	decode(rec, &sub)
+
+	const deliveryID = "dddddddd-0000-0000-0000-000000000001"
+	const notificationID = "eeeeeeee-0000-0000-0000-000000000001"
+	if _, err := pool.Exec(ctx, `
+INSERT INTO webhook_deliveries (id, organizer_id, subscription_id, event_id, event_type, payload, status, attempts, next_attempt_at, created_at)
+VALUES ($1, $2, $3, gen_random_uuid(), 'order.paid', '{}', 'dead', 8, $4, $4)`,
+		deliveryID, acme.Organizer.ID, sub.ID, now); err != nil {
+		t.Fatalf("insert delivery: %v", err)
+	}
+	if _, err := pool.Exec(ctx, `
+INSERT INTO webhook_attempts (delivery_id, attempted_at, status_code, duration_ms) VALUES ($1, $2, 500, 10)`,
+		deliveryID, now); err != nil {
+		t.Fatalf("insert attempt: %v", err)
+	}
+	if _, err := pool.Exec(ctx, `
+INSERT INTO notifications (id, organizer_id, kind, recipient, subject, text_body, html_body, status, attempts, next_attempt_at, dedupe_key, created_at)
+VALUES ($1, $2, 'order_confirmation', 'fan@example.com', 's', 't', 'h', 'failed', 6, $3, 'evt:order', $3)`,
+		notificationID, acme.Organizer.ID, now); err != nil {
+		t.Fatalf("insert notification: %v", err)
+	}
+
+	// Every route, called with Rival's owner key.
+	tests := []struct {
+		method     string
+		path       string
+		body       string
+		wantStatus int
+		// wantEmpty expects a 200 with an empty JSON list.
+		wantEmpty bool
+	}{
+		{method: http.MethodGet, path: "/admin/events", wantStatus: http.StatusOK, wantEmpty: true},
+		{method: http.MethodPatch, path: "/admin/events/" + event.ID, body: `{"name":"Hijacked"}`, wantStatus: http.StatusNotFound},
+		{method: http.MethodGet, path: "/admin/events/" + event.ID + "/zones", wantStatus: http.StatusNotFound},
+		{method: http.MethodPost, path: "/admin/events/" + event.ID + "/zones", body: `{"name":"Stolen","capacity":1}`, wantStatus: http.StatusNotFound},
+		{method: http.MethodGet, path: "/admin/webhooks", wantStatus: http.StatusOK, wantEmpty: true},
+		{method: http.MethodDelete, path: "/admin/webhooks/" + sub.ID, wantStatus: http.StatusNotFound},
+		{method: http.MethodGet, path: "/admin/webhooks/" + sub.ID + "/deliveries", wantStatus: http.StatusOK, wantEmpty: true},
+		{method: http.MethodGet, path: "/admin/webhooks/deliveries/" + deliveryID + "/attempts", wantStatus: http.StatusOK, wantEmpty: true},
+		{method: http.MethodPost, path: "/admin/webhooks/deliveries/" + deliveryID + "/replay", wantStatus: http.StatusNotFound},
+		{method: http.MethodGet, path: "/admin/notifications", wantStatus: http.StatusOK, wantEmpty: true},
+		{method: http.MethodPost, path: "/admin/notifications/" + notificationID + "/retry", wantStatus: http.StatusNotFound},
+		{method: http.MethodDelete, path: "/admin/api-keys/" + acme.OwnerKey.Key.ID, wantStatus: http.StatusNotFound},
+		{method: http.MethodGet, path: "/admin/audit?target_id=" + event.ID, wantStatus: http.StatusOK, wantEmpty: true},
+	}
+	for _, tt := range tests {
+		rec := do(rival.OwnerKey.Secret, tt.method, tt.path, tt.body)
+		if rec.Code != tt.wantStatus {
+			t.Fatalf("%s %s: expected %d, got %d (%s)", tt.method, tt.path, tt.wantStatus, rec.Code, rec.Body.String())
+		}
+		if tt.wantEmpty && strings.TrimSpace(rec.Body.String()) != "[]" {
+			t.Fatalf("%s %s: expected empty list, got %s", tt.method, tt.path, rec.Body.String())
+		}
+	}
+
+	rec = do(rival.OwnerKey.Secret, http.MethodGet, "/admin/api-keys", "")
+	var keys []apiKeyResponse
+	decode(rec, &keys)
+	if len(keys) != 1 || keys[0].ID != rival.OwnerKey.Key.ID {
+		t.Fatalf("expected only rival's own key, got %+v", keys)
+	}
+
+	// Acme's data is untouched and still visible to Acme.
+	rec = do(acme.OwnerKey.Secret, http.MethodGet, "/admin/events", "")
+	var events []eventResponse
+	decode(rec, &events)
+	if len(events) != 1 || events[0].Name != "Acme Night" {
+		t.Fatalf("expected acme's event unchanged, got %+v", events)
+	}
+	rec = do(acme.OwnerKey.Secret, http.MethodGet, "/admin/events/"+event.ID+"/zones", "")
+	var zones []zoneResponse
+	decode(rec, &zones)
+	if len(zones) != 1 || zones[0].Name != "Floor" {
+		t.Fatalf("expected only acme's zone, got %+v", zones)
+	}
+	rec = do(acme.OwnerKey.Secret, http.MethodGet, "/admin/webhooks", "")
+	var subs []webhookSubscriptionResponse
+	decode(rec, &subs)
+	if len(subs) != 1 || !subs[0].Active {
+		t.Fatalf("expected acme's webhook still active, got %+v", subs)
+	}
+	rec = do(acme.OwnerKey.Secret, http.MethodGet, "/admin/notifications?status=failed", "")
+	var notifications []notificationResponse
+	decode(rec, &notifications)
+	if len(notifications) != 1 {
+		t.Fatalf("expected acme's failed notification untouched, got %+v", notifications)
+	}
+	if rec := do(acme.OwnerKey.Secret, http.MethodPost, "/admin/webhooks/deliveries/"+deliveryID+"/replay", ""); rec.Code != http.StatusAccepted {
+		t.Fatalf("expected acme to replay its own delivery, got %d (%s)", rec.Code, rec.Body.String())
+	}
+	rec = do(acme.OwnerKey.Secret, http.MethodGet, "/admin/audit?target_id="+event.ID, "")
+	var audit []auditEntryResponse
+	decode(rec, &audit)
+	if len(audit) != 1 || audit[0].Action != string(domain.AuditEventCreated) ||
+		audit[0].Actor != "api_key:"+acme.OwnerKey.Key.ID || audit[0].RequestID != "req-tenant-test" {
+		t.Fatalf("expected acme's event creation to be audited, got %+v", audit)
+	}
+	if _, err := apiKeySvc.Authenticate(ctx, acme.OwnerKey.Secret); err != nil {
+		t.Fatalf("expected acme's key to remain valid, got %v", err)
+	}
+}
@@ -0,0 +1,239 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cimillas/ultimate-ticket/services/api/internal/app"
+	"github.com/cimillas/ultimate-ticket/services/api/internal/domain"
+)
+
+func TestHandleAdminEvent(t *testing.T) {
+	t.Parallel()
+
+	event := domain.Event{ID: "event-1", Name: "Concert", StartsAt: time.Date(2025, 6, 2, 20, 0, 0, 0, time.UTC)}
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		body           string
+		serviceErr     error
+		expectedStatus int
+		expectedSubstr string
+	}{
+		{
+			name:           "update starts_at",
+			method:         http.MethodPatch,
+			path:           "/admin/events/event-1",
+			body:           `{"starts_at":"2025-06-02T20:00:00Z"}`,
+			expectedStatus: http.StatusOK,
+			expectedSubstr: `"starts_at":"2025-06-02T20:00:00Z"`,
+		},
+		{
+			name:           "invalid starts_at",
+			method:         http.MethodPatch,
+			path:           "/admin/events/event-1",
+			body:           `{"starts_at":"tomorrow"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedSubstr: `"code":"invalid_starts_at"`,
+		},
+		{
+			name:           "empty name",
+			method:         http.MethodPatch,
+			path:           "/admin/events/event-1",
+			body:           `{"name":""}`,
+			serviceErr:     domain.ErrEventNameRequired,
+			expectedStatus: http.StatusBadRequest,
+			expectedSubstr: `"code":"event_name_required"`,
+		},
+		{
+			name:           "event not found",
+			method:         http.MethodPatch,
+			path:           "/admin/events/event-2",
+			body:           `{"name":"Concert"}`,
+			serviceErr:     domain.ErrEventNotFound,
+			expectedStatus: http.StatusNotFound,
+			expectedSubstr: `"code":"event_not_found"`,
+		},
+		{
+			name:           "update waiting room",
+			method:         http.MethodPatch,
+			path:           "/admin/events/event-1",
+			body:           `{"waiting_room":{"enabled":true,"admit_per_minute":120}}`,
+			expectedStatus: http.StatusOK,
+			expectedSubstr: `"waiting_room":{"enabled":true,"admit_per_minute":120}`,
+		},
+		{
+			name:           "invalid admission rate",
+			method:         http.MethodPatch,
+			path:           "/admin/events/event-1",
+			body:           `{"waiting_room":{"enabled":true}}`,
+			serviceErr:     domain.ErrInvalidAdmissionRate,
+			expectedStatus: http.StatusBadRequest,
+			expectedSubstr: `"code":"invalid_admission_rate"`,
+		},
+		{
+			name:           "update purchase limit",
+			method:         http.MethodPatch,
+			path:           "/admin/events/event-1",
+			body:           `{"purchase_limit":4}`,
+			expectedStatus: http.StatusOK,
+			expectedSubstr: `"purchase_limit":4`,
+		},
+		{
+			name:           "invalid purchase limit",
+			method:         http.MethodPatch,
+			path:           "/admin/events/event-1",
+			body:           `{"purchase_limit":-1}`,
+			serviceErr:     domain.ErrInvalidPurchaseLimit,
+			expectedStatus: http.StatusBadRequest,
+			expectedSubstr: `"code":"invalid_purchase_limit"`,
+		},
+		{
+			name:           "method not allowed",
+			method:         http.MethodDelete,
+			path:           "/admin/events/event-1",
+			expectedStatus: http.StatusMethodNotAllowed,
+		},
+		{
+			name:           "zones are passed through",
+			method:         http.MethodGet,
+			path:           "/admin/events/event-1/zones",
+			expectedStatus: http.StatusTeapot,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			svc := &stubAdminEventUpdater{event: event, err: tt.serviceErr}
+			next := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusTeapot)
+			})
+
+			req := withAdminKey(httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body)), "org-1")
+			rec := httptest.NewRecorder()
+			HandleAdminEvent(svc, next).ServeHTTP(rec, req)
+
+			if rec.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d", tt.expectedStatus, rec.Code)
+			}
+			if tt.expectedSubstr != "" && !strings.Contains(rec.Body.String(), tt.expectedSubstr) {
+				t.Fatalf("expected response to contain %q, got %q", tt.expectedSubstr, rec.Body.String())
+			}
+		})
+	}
+}
+
+type stubAdminEventUpdater struct {
+	event domain.Event
+	err   error
+}
+
+func (s *stubAdminEventUpdater) UpdateEvent(_ context.Context, in app.UpdateEventInput) (domain.Event, error) {
+	event := s.event
+	if in.WaitingRoom != nil {
+		event.WaitingRoom = *in.WaitingRoom
+	}
+	if in.PurchaseLimit != nil {
+		event.PurchaseLimit = *in.PurchaseLimit
+	}
+	return event, s.err
+}
+
+type stubAdminEventService struct{}
+
+func (s *stubAdminEventService) CreateEvent(_ context.Context, in app.CreateEventInput) (domain.Event, error) {
+	return domain.Event{ID: "event-1", OrganizerID: in.OrganizerID, Name: in.Name}, nil
+}
+
+func (s *stubAdminEventService) ListEvents(_ context.Context, _ string) ([]domain.Event, error) {
+	return nil, nil
+}
+
+func TestHandleAdminZones_QuantityRules(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name           string
+		body           string
+		serviceErr     error
+		expectedStatus int
+		expectedSubstr string
+	}{
+		{
+			name:           "rules are stored",
+			body:           `{"name":"Tables","capacity":60,"min_quantity":6,"quantity_step":6}`,
+			expectedStatus: http.StatusCreated,
+			expectedSubstr: `"min_quantity":6,"max_quantity":0,"quantity_step":6`,
+		},
+		{
+			name:           "defaults are reported",
+			body:           `{"name":"Floor","capacity":100}`,
+			expectedStatus: http.StatusCreated,
+			expectedSubstr: `"min_quantity":1,"max_quantity":0,"quantity_step":1`,
+		},
+		{
+			name:           "purchase limit is stored",
+			body:           `{"name":"Floor","capacity":100,"purchase_limit":4}`,
+			expectedStatus: http.StatusCreated,
+			expectedSubstr: `"purchase_limit":4`,
+		},
+		{
+			name:           "invalid rules",
+			body:           `{"name":"Floor","capacity":100,"min_quantity":4,"max_quantity":2}`,
+			serviceErr:     domain.ErrInvalidQuantityRules,
+			expectedStatus: http.StatusBadRequest,
+			expectedSubstr: `"code":"invalid_quantity_rules"`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			svc := &stubAdminZoneService{err: tt.serviceErr}
+			req := withAdminKey(httptest.NewRequest(http.MethodPost, "/admin/events/event-1/zones", strings.NewReader(tt.body)), "org-1")
+			rec := httptest.NewRecorder()
+
+			HandleAdminZones(svc).ServeHTTP(rec, req)
+
+			if rec.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d (%s)", tt.expectedStatus, rec.Code, rec.Body.String())
+			}
+			if !strings.Contains(rec.Body.String(), tt.expectedSubstr) {
+				t.Fatalf("expected response to contain %q, got %q", tt.expectedSubstr, rec.Body.String())
+			}
+		})
+	}
+}
+
+type stubAdminZoneService struct {
+	err error
+}
+
+func (s *stubAdminZoneService) CreateZone(_ context.Context, in app.CreateZoneInput) (domain.Zone, error) {
+	if s.err != nil {
+		return domain.Zone{}, s.err
+	}
+	return domain.Zone{
+		ID:            "zone-1",
+		EventID:       in.EventID,
+		Name:          in.Name,
+		Capacity:      in.Capacity,
+		MinQuantity:   in.MinQuantity,
+		MaxQuantity:   in.MaxQuantity,
+		QuantityStep:  in.QuantityStep,
+		PurchaseLimit: in.PurchaseLimit,
+	}, nil
+}
+
+func (s *stubAdminZoneService) ListZones(_ context.Context, _, _ string) ([]domain.Zone, error) {
+	return nil, nil
+}
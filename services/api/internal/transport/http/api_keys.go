@@ -0,0 +1,243 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/cimillas/ultimate-ticket/services/api/internal/app"
+	"github.com/cimillas/ultimate-ticket/services/api/internal/domain"
+)
+
+// APIKeyHeader carries admin API keys. It is separate from Authorization so
+// admin tooling never collides with customer session tokens.
+const APIKeyHeader = "X-API-Key"
+
+type apiKeyContextKey struct{}
+
+// WithAPIKey returns a context carrying the authenticated API key.
+func WithAPIKey(ctx context.Context, key domain.APIKey) context.Context {
+	return context.WithValue(ctx, apiKeyContextKey{}, key)
+}
+
+// APIKeyFromContext returns the authenticated API key, if any.
+func APIKeyFromContext(ctx context.Context) (domain.APIKey, bool) {
+	key, ok := ctx.Value(apiKeyContextKey{}).(domain.APIKey)
+	return key, ok
+}
+
+// adminOrganizer returns the organizer the request's API key belongs to.
+// Admin handlers scope every read and write to it; without a key it writes
+// 401 and returns false.
+func adminOrganizer(w http.ResponseWriter, r *http.Request) (string, bool) {
+	key, ok := APIKeyFromContext(r.Context())
+	if !ok || key.OrganizerID == "" {
+		writeError(w, http.StatusUnauthorized, codeUnauthorized, "api key required")
+		return "", false
+	}
+	return key.OrganizerID, true
+}
+
+// APIKeyAuthenticator resolves an API key secret.
+type APIKeyAuthenticator interface {
+	Authenticate(ctx context.Context, secret string) (domain.APIKey, error)
+}
+
+// AdminAccess lists the roles allowed on a route. GET and HEAD need a Read
+// role; other methods need a Write role. Owners are always allowed.
+type AdminAccess struct {
+	Read  []domain.Role
+	Write []domain.Role
+}
+
+func (a AdminAccess) allows(role domain.Role, method string) bool {
+	if role == domain.RoleOwner {
+		return true
+	}
+	roles := a.Write
+	if method == http.MethodGet || method == http.MethodHead {
+		roles = a.Read
+	}
+	for _, r := range roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// RequireAPIKey rejects requests without a valid API key whose role is
+// allowed by access, and attaches the key to the request context. Changes
+// made by the request are audited as the key, with the caller's request ID.
+func RequireAPIKey(auth APIKeyAuthenticator, access AdminAccess, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		secret := r.Header.Get(APIKeyHeader)
+		if secret == "" {
+			writeError(w, http.StatusUnauthorized, codeUnauthorized, "api key required")
+			return
+		}
+		key, err := auth.Authenticate(r.Context(), secret)
+		if err != nil {
+			switch err {
+			case domain.ErrInvalidAPIKey:
+				writeError(w, http.StatusUnauthorized, codeInvalidAPIKey, err.Error())
+			default:
+				writeInternalError(w, r, err)
+			}
+			return
+		}
+		if !access.allows(key.Role, r.Method) {
+			writeError(w, http.StatusForbidden, codeInsufficientRole, "api key role does not allow this request")
+			return
+		}
+		ctx := WithAPIKey(r.Context(), key)
+		ctx = app.WithAuditContext(ctx, app.AuditContext{
+			Actor:     "api_key:" + key.ID,
+			RequestID: requestID(r),
+		})
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// AdminAPIKeyService is the minimal interface needed for API key management.
+type AdminAPIKeyService interface {
+	CreateKey(ctx context.Context, in app.CreateAPIKeyInput) (app.CreatedAPIKey, error)
+	ListKeys(ctx context.Context, organizerID string) ([]domain.APIKey, error)
+	RevokeKey(ctx context.Context, organizerID, id string) (domain.APIKey, error)
+}
+
+// HandleAdminAPIKeys returns an HTTP handler for creating and listing API
+// keys. New keys belong to the caller's organizer.
+func HandleAdminAPIKeys(svc AdminAPIKeyService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		organizerID, ok := adminOrganizer(w, r)
+		if !ok {
+			return
+		}
+		switch r.Method {
+		case http.MethodGet:
+			keys, err := svc.ListKeys(r.Context(), organizerID)
+			if err != nil {
+				writeInternalError(w, r, err)
+				return
+			}
+			resp := make([]apiKeyResponse, 0, len(keys))
+			for _, key := range keys {
+				resp = append(resp, newAPIKeyResponse(key))
+			}
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(resp)
+		case http.MethodPost:
+			var req createAPIKeyRequest
+			dec := json.NewDecoder(r.Body)
+			dec.DisallowUnknownFields()
+			if err := dec.Decode(&req); err != nil {
+				writeError(w, http.StatusBadRequest, codeInvalidRequestBody, "invalid request body")
+				return
+			}
+
+			created, err := svc.CreateKey(r.Context(), app.CreateAPIKeyInput{
+				OrganizerID: organizerID,
+				Name:        req.Name,
+				Role:        domain.Role(req.Role),
+			})
+			if err != nil {
+				switch err {
+				case domain.ErrAPIKeyNameRequired:
+					writeError(w, http.StatusBadRequest, codeAPIKeyNameRequired, err.Error())
+				case domain.ErrInvalidRole:
+					writeError(w, http.StatusBadRequest, codeInvalidRole, err.Error())
+				default:
+					writeInternalError(w, r, err)
+				}
+				return
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("Cache-Control", "no-store")
+			w.WriteHeader(http.StatusCreated)
+			_ = json.NewEncoder(w).Encode(createdAPIKeyResponse{
+				apiKeyResponse: newAPIKeyResponse(created.Key),
+				Key:            created.Secret,
+			})
+		default:
+			writeError(w, http.StatusMethodNotAllowed, codeMethodNotAllowed, "method not allowed")
+		}
+	}
+}
+
+// HandleAdminAPIKey returns an HTTP handler for DELETE /admin/api-keys/{id}.
+func HandleAdminAPIKey(svc AdminAPIKeyService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := parseAdminAPIKeyPath(r.URL.Path)
+		if !ok {
+			writeError(w, http.StatusNotFound, codeNotFound, "not found")
+			return
+		}
+		if r.Method != http.MethodDelete {
+			writeError(w, http.StatusMethodNotAllowed, codeMethodNotAllowed, "method not allowed")
+			return
+		}
+
+		organizerID, ok := adminOrganizer(w, r)
+		if !ok {
+			return
+		}
+
+		if _, err := svc.RevokeKey(r.Context(), organizerID, id); err != nil {
+			switch err {
+			case domain.ErrInvalidID:
+				writeError(w, http.StatusNotFound, codeInvalidID, err.Error())
+			case domain.ErrAPIKeyNotFound:
+				writeError(w, http.StatusNotFound, codeAPIKeyNotFound, err.Error())
+			default:
+				writeInternalError(w, r, err)
+			}
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func parseAdminAPIKeyPath(path string) (string, bool) {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	if len(parts) != 3 || parts[0] != "admin" || parts[1] != "api-keys" || parts[2] == "" {
+		return "", false
+	}
+	return parts[2], true
+}
+
+type createAPIKeyRequest struct {
+	Name string `json:"name"`
+	Role string `json:"role"`
+}
+
+type apiKeyResponse struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Role       string     `json:"role"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+// createdAPIKeyResponse is the only response that includes the secret.
+type createdAPIKeyResponse struct {
+	apiKeyResponse
+	Key string `json:"key"`
+}
+
+func newAPIKeyResponse(key domain.APIKey) apiKeyResponse {
+	return apiKeyResponse{
+		ID:         key.ID,
+		Name:       key.Name,
+		Prefix:     key.Prefix,
+		Role:       string(key.Role),
+		CreatedAt:  key.CreatedAt,
+		LastUsedAt: key.LastUsedAt,
+		RevokedAt:  key.RevokedAt,
+	}
+}
@@ -0,0 +1,291 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cimillas/ultimate-ticket/services/api/internal/app"
+	"github.com/cimillas/ultimate-ticket/services/api/internal/domain"
+)
+
+func TestRequireAPIKey(t *testing.T) {
+	t.Parallel()
+
+	access := AdminAccess{
+		Read:  []domain.Role{domain.RoleEventManager, domain.RoleReadOnly},
+		Write: []domain.Role{domain.RoleEventManager},
+	}
+
+	tests := []struct {
+		name           string
+		method         string
+		secret         string
+		role           domain.Role
+		authErr        error
+		expectedStatus int
+		expectedSubstr string
+	}{
+		{
+			name:           "missing key",
+			method:         http.MethodGet,
+			expectedStatus: http.StatusUnauthorized,
+			expectedSubstr: `"code":"unauthorized"`,
+		},
+		{
+			name:           "invalid key",
+			method:         http.MethodGet,
+			secret:         "utk_bad",
+			authErr:        domain.ErrInvalidAPIKey,
+			expectedStatus: http.StatusUnauthorized,
+			expectedSubstr: `"code":"invalid_api_key"`,
+		},
+		{
+			name:           "lookup failure",
+			method:         http.MethodGet,
+			secret:         "utk_good",
+			authErr:        errors.New("db down"),
+			expectedStatus: http.StatusInternalServerError,
+		},
+		{
+			name:           "read-only can read",
+			method:         http.MethodGet,
+			secret:         "utk_good",
+			role:           domain.RoleReadOnly,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "read-only cannot write",
+			method:         http.MethodPost,
+			secret:         "utk_good",
+			role:           domain.RoleReadOnly,
+			expectedStatus: http.StatusForbidden,
+			expectedSubstr: `"code":"insufficient_role"`,
+		},
+		{
+			name:           "scanner cannot read",
+			method:         http.MethodGet,
+			secret:         "utk_good",
+			role:           domain.RoleScanner,
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "event manager can write",
+			method:         http.MethodPost,
+			secret:         "utk_good",
+			role:           domain.RoleEventManager,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "owner can do anything",
+			method:         http.MethodDelete,
+			secret:         "utk_good",
+			role:           domain.RoleOwner,
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			auth := &stubAPIKeyService{key: domain.APIKey{ID: "key-1", Role: tt.role}, err: tt.authErr}
+
+			var got domain.APIKey
+			var audit app.AuditContext
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got, _ = APIKeyFromContext(r.Context())
+				audit = app.AuditContextFrom(r.Context())
+			})
+
+			req := httptest.NewRequest(tt.method, "/admin/events", nil)
+			if tt.secret != "" {
+				req.Header.Set(APIKeyHeader, tt.secret)
+			}
+			req.Header.Set(RequestIDHeader, "req-1")
+			rec := httptest.NewRecorder()
+
+			RequireAPIKey(auth, access, next).ServeHTTP(rec, req)
+
+			if rec.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d (%s)", tt.expectedStatus, rec.Code, rec.Body.String())
+			}
+			if tt.expectedSubstr != "" && !strings.Contains(rec.Body.String(), tt.expectedSubstr) {
+				t.Fatalf("expected response to contain %q, got %q", tt.expectedSubstr, rec.Body.String())
+			}
+			if tt.expectedStatus == http.StatusOK && got.ID != "key-1" {
+				t.Fatalf("expected key in context, got %+v", got)
+			}
+			if tt.expectedStatus == http.StatusOK && (audit.Actor != "api_key:key-1" || audit.RequestID != "req-1") {
+				t.Fatalf("expected audit context for the key, got %+v", audit)
+			}
+		})
+	}
+}
+
+func TestHandleAdminAPIKeys(t *testing.T) {
+	t.Parallel()
+
+	key := domain.APIKey{
+		ID:        "key-1",
+		Name:      "ci",
+		Prefix:    "utk_abcdefgh",
+		KeyHash:   "secret-hash",
+		Role:      domain.RoleEventManager,
+		CreatedAt: time.Date(2025, 1, 4, 8, 0, 0, 0, time.UTC),
+	}
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		body           string
+		serviceErr     error
+		expectedStatus int
+		expectedSubstr string
+	}{
+		{
+			name:           "create",
+			method:         http.MethodPost,
+			path:           "/admin/api-keys",
+			body:           `{"name":"ci","role":"event_manager"}`,
+			expectedStatus: http.StatusCreated,
+			expectedSubstr: `"key":"utk_abcdefgh-secret"`,
+		},
+		{
+			name:           "create invalid role",
+			method:         http.MethodPost,
+			path:           "/admin/api-keys",
+			body:           `{"name":"ci","role":"admin"}`,
+			serviceErr:     domain.ErrInvalidRole,
+			expectedStatus: http.StatusBadRequest,
+			expectedSubstr: `"code":"invalid_role"`,
+		},
+		{
+			name:           "list",
+			method:         http.MethodGet,
+			path:           "/admin/api-keys",
+			expectedStatus: http.StatusOK,
+			expectedSubstr: `"prefix":"utk_abcdefgh"`,
+		},
+		{
+			name:           "revoke",
+			method:         http.MethodDelete,
+			path:           "/admin/api-keys/key-1",
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name:           "revoke missing",
+			method:         http.MethodDelete,
+			path:           "/admin/api-keys/key-2",
+			serviceErr:     domain.ErrAPIKeyNotFound,
+			expectedStatus: http.StatusNotFound,
+			expectedSubstr: `"code":"api_key_not_found"`,
+		},
+		{
+			name:           "revoke wrong method",
+			method:         http.MethodGet,
+			path:           "/admin/api-keys/key-1",
+			expectedStatus: http.StatusMethodNotAllowed,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			svc := &stubAPIKeyService{key: key, err: tt.serviceErr}
+
+			req := withAdminKey(httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body)), "org-1")
+			rec := httptest.NewRecorder()
+
+			if tt.path == "/admin/api-keys" {
+				HandleAdminAPIKeys(svc).ServeHTTP(rec, req)
+			} else {
+				HandleAdminAPIKey(svc).ServeHTTP(rec, req)
+			}
+
+			if rec.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d (%s)", tt.expectedStatus, rec.Code, rec.Body.String())
+			}
+			if tt.expectedSubstr != "" && !strings.Contains(rec.Body.String(), tt.expectedSubstr) {
+				t.Fatalf("expected response to contain %q, got %q", tt.expectedSubstr, rec.Body.String())
+			}
+			if strings.Contains(rec.Body.String(), key.KeyHash) {
+				t.Fatalf("response leaked key hash: %s", rec.Body.String())
+			}
+			if svc.organizerID != "" && svc.organizerID != "org-1" {
+				t.Fatalf("expected calls scoped to org-1, got %q", svc.organizerID)
+			}
+		})
+	}
+}
+
+func TestAdminHandlersRequireOrganizerKey(t *testing.T) {
+	t.Parallel()
+
+	handlers := map[string]http.Handler{
+		"GET /admin/events":                  HandleAdminEvents(&stubAdminEventService{}),
+		"POST /admin/events":                 HandleAdminEvents(&stubAdminEventService{}),
+		"PATCH /admin/events/e1":             HandleAdminEvent(&stubAdminEventUpdater{}, http.NotFoundHandler()),
+		"GET /admin/events/e1/zones":         HandleAdminZones(&stubAdminZoneService{}),
+		"POST /admin/events/e1/zones":        HandleAdminZones(&stubAdminZoneService{}),
+		"GET /admin/webhooks":                HandleAdminWebhooks(&stubAdminWebhookService{}),
+		"DELETE /admin/webhooks/w1":          HandleAdminWebhook(&stubAdminWebhookService{}),
+		"GET /admin/notifications":           HandleAdminNotifications(&stubAdminNotificationService{}),
+		"POST /admin/notifications/n1/retry": HandleAdminNotification(&stubAdminNotificationService{}),
+		"GET /admin/api-keys":                HandleAdminAPIKeys(&stubAPIKeyService{}),
+		"DELETE /admin/api-keys/k1":          HandleAdminAPIKey(&stubAPIKeyService{}),
+		"GET /admin/audit":                   HandleAdminAudit(&stubAdminAuditService{}),
+	}
+	for route, h := range handlers {
+		method, path, _ := strings.Cut(route, " ")
+		req := httptest.NewRequest(method, path, strings.NewReader(`{}`))
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if rec.Code != http.StatusUnauthorized {
+			t.Fatalf("%s: expected 401 without an api key, got %d", route, rec.Code)
+		}
+	}
+}
+
+// withAdminKey attaches an owner key of the organizer, as RequireAPIKey would.
+func withAdminKey(req *http.Request, organizerID string) *http.Request {
+	key := domain.APIKey{ID: "key-" + organizerID, OrganizerID: organizerID, Role: domain.RoleOwner}
+	return req.WithContext(WithAPIKey(req.Context(), key))
+}
+
+type stubAPIKeyService struct {
+	key         domain.APIKey
+	err         error
+	organizerID string
+}
+
+func (s *stubAPIKeyService) Authenticate(_ context.Context, _ string) (domain.APIKey, error) {
+	if s.err != nil {
+		return domain.APIKey{}, s.err
+	}
+	return s.key, nil
+}
+
+func (s *stubAPIKeyService) CreateKey(_ context.Context, in app.CreateAPIKeyInput) (app.CreatedAPIKey, error) {
+	s.organizerID = in.OrganizerID
+	if s.err != nil {
+		return app.CreatedAPIKey{}, s.err
+	}
+	return app.CreatedAPIKey{Key: s.key, Secret: s.key.Prefix + "-secret"}, nil
+}
+
+func (s *stubAPIKeyService) ListKeys(_ context.Context, organizerID string) ([]domain.APIKey, error) {
+	s.organizerID = organizerID
+	return []domain.APIKey{s.key}, s.err
+}
+
+func (s *stubAPIKeyService) RevokeKey(_ context.Context, organizerID, _ string) (domain.APIKey, error) {
+	s.organizerID = organizerID
+	return s.key, s.err
+}
@@ -0,0 +1,104 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/cimillas/ultimate-ticket/services/api/internal/domain"
+)
+
+// AdminAuditService is the minimal interface needed for the audit log endpoint.
+type AdminAuditService interface {
+	ListEntries(ctx context.Context, filter domain.AuditFilter) ([]domain.AuditEntry, error)
+}
+
+// HandleAdminAudit returns an HTTP handler for
+// GET /admin/audit[?actor=&action=&target_type=&target_id=&since=&until=&limit=].
+// since and until are RFC 3339 timestamps.
+func HandleAdminAudit(svc AdminAuditService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			writeError(w, http.StatusMethodNotAllowed, codeMethodNotAllowed, "method not allowed")
+			return
+		}
+		q := r.URL.Query()
+		filter := domain.AuditFilter{
+			Actor:      q.Get("actor"),
+			Action:     domain.AuditAction(q.Get("action")),
+			TargetType: q.Get("target_type"),
+			TargetID:   q.Get("target_id"),
+		}
+		for _, bound := range []struct {
+			name string
+			dst  **time.Time
+		}{
+			{"since", &filter.Since},
+			{"until", &filter.Until},
+		} {
+			raw := q.Get(bound.name)
+			if raw == "" {
+				continue
+			}
+			t, err := time.Parse(time.RFC3339, raw)
+			if err != nil {
+				writeError(w, http.StatusBadRequest, codeInvalidAuditFilter, "invalid "+bound.name)
+				return
+			}
+			*bound.dst = &t
+		}
+		if raw := q.Get("limit"); raw != "" {
+			limit, err := strconv.Atoi(raw)
+			if err != nil || limit <= 0 {
+				writeError(w, http.StatusBadRequest, codeInvalidAuditFilter, "invalid limit")
+				return
+			}
+			filter.Limit = limit
+		}
+		organizerID, ok := adminOrganizer(w, r)
+		if !ok {
+			return
+		}
+		filter.OrganizerID = organizerID
+
+		entries, err := svc.ListEntries(r.Context(), filter)
+		if err != nil {
+			writeInternalError(w, r, err)
+			return
+		}
+		resp := make([]auditEntryResponse, 0, len(entries))
+		for _, entry := range entries {
+			resp = append(resp, newAuditEntryResponse(entry))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(resp)
+	}
+}
+
+type auditEntryResponse struct {
+	ID         string          `json:"id"`
+	Actor      string          `json:"actor"`
+	Action     string          `json:"action"`
+	TargetType string          `json:"target_type"`
+	TargetID   string          `json:"target_id"`
+	Before     json.RawMessage `json:"before,omitempty"`
+	After      json.RawMessage `json:"after,omitempty"`
+	RequestID  string          `json:"request_id,omitempty"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+func newAuditEntryResponse(entry domain.AuditEntry) auditEntryResponse {
+	return auditEntryResponse{
+		ID:         entry.ID,
+		Actor:      entry.Actor,
+		Action:     string(entry.Action),
+		TargetType: entry.TargetType,
+		TargetID:   entry.TargetID,
+		Before:     entry.Before,
+		After:      entry.After,
+		RequestID:  entry.RequestID,
+		CreatedAt:  entry.CreatedAt,
+	}
+}
@@ -0,0 +1,152 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cimillas/ultimate-ticket/services/api/internal/domain"
+)
+
+func TestHandleAdminAudit(t *testing.T) {
+	t.Parallel()
+
+	createdAt := time.Date(2025, 1, 5, 10, 0, 0, 0, time.UTC)
+	since := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		method         string
+		query          string
+		svcErr         error
+		expectedStatus int
+		expectedSubstr string
+		expectedFilter domain.AuditFilter
+	}{
+		{
+			name:           "lists entries",
+			method:         http.MethodGet,
+			expectedStatus: http.StatusOK,
+			expectedSubstr: `"before":{"name":"Concert"}`,
+			expectedFilter: domain.AuditFilter{OrganizerID: "org-1"},
+		},
+		{
+			name:           "passes filters",
+			method:         http.MethodGet,
+			query:          "?actor=api_key:k1&action=event.updated&target_type=event&target_id=e1&since=2025-01-01T00:00:00Z&limit=20",
+			expectedStatus: http.StatusOK,
+			expectedFilter: domain.AuditFilter{
+				OrganizerID: "org-1",
+				Actor:       "api_key:k1",
+				Action:      domain.AuditEventUpdated,
+				TargetType:  "event",
+				TargetID:    "e1",
+				Since:       &since,
+				Limit:       20,
+			},
+		},
+		{
+			name:           "invalid since",
+			method:         http.MethodGet,
+			query:          "?since=yesterday",
+			expectedStatus: http.StatusBadRequest,
+			expectedSubstr: `"code":"invalid_audit_filter"`,
+		},
+		{
+			name:           "invalid limit",
+			method:         http.MethodGet,
+			query:          "?limit=-1",
+			expectedStatus: http.StatusBadRequest,
+			expectedSubstr: `"code":"invalid_audit_filter"`,
+		},
+		{
+			name:           "internal error",
+			method:         http.MethodGet,
+			svcErr:         errors.New("boom"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedSubstr: `"code":"internal_error"`,
+		},
+		{
+			name:           "method not allowed",
+			method:         http.MethodPost,
+			expectedStatus: http.StatusMethodNotAllowed,
+			expectedSubstr: `"code":"method_not_allowed"`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			svc := &stubAdminAuditService{
+				err: tt.svcErr,
+				entries: []domain.AuditEntry{{
+					ID:         "a1",
+					Actor:      "api_key:k1",
+					Action:     domain.AuditEventUpdated,
+					TargetType: "event",
+					TargetID:   "e1",
+					Before:     []byte(`{"name":"Concert"}`),
+					After:      []byte(`{"name":"Festival"}`),
+					RequestID:  "req-1",
+					CreatedAt:  createdAt,
+				}},
+			}
+			req := withAdminKey(httptest.NewRequest(tt.method, "/admin/audit"+tt.query, nil), "org-1")
+			rec := httptest.NewRecorder()
+
+			HandleAdminAudit(svc).ServeHTTP(rec, req)
+
+			if rec.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d (%s)", tt.expectedStatus, rec.Code, rec.Body.String())
+			}
+			if tt.expectedSubstr != "" && !strings.Contains(rec.Body.String(), tt.expectedSubstr) {
+				t.Fatalf("expected response to contain %q, got %q", tt.expectedSubstr, rec.Body.String())
+			}
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+			if !sameAuditFilter(svc.filter, tt.expectedFilter) {
+				t.Fatalf("expected filter %+v, got %+v", tt.expectedFilter, svc.filter)
+			}
+			var resp []auditEntryResponse
+			if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if len(resp) != 1 || resp[0].Action != "event.updated" || resp[0].RequestID != "req-1" {
+				t.Fatalf("unexpected response %+v", resp)
+			}
+		})
+	}
+}
+
+func sameAuditFilter(a, b domain.AuditFilter) bool {
+	sameTime := func(x, y *time.Time) bool {
+		if x == nil || y == nil {
+			return x == y
+		}
+		return x.Equal(*y)
+	}
+	return a.OrganizerID == b.OrganizerID && a.Actor == b.Actor && a.Action == b.Action &&
+		a.TargetType == b.TargetType && a.TargetID == b.TargetID && a.Limit == b.Limit &&
+		sameTime(a.Since, b.Since) && sameTime(a.Until, b.Until)
+}
+
+type stubAdminAuditService struct {
+	entries []domain.AuditEntry
+	err     error
+	filter  domain.AuditFilter
+}
+
+func (s *stubAdminAuditService) ListEntries(_ context.Context, filter domain.AuditFilter) ([]domain.AuditEntry, error) {
+	s.filter = filter
+	if s.err != nil {
+		return nil, s.err
+	}
+	return s.entries, nil
+}
@@ -0,0 +1,178 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/cimillas/ultimate-ticket/services/api/internal/app"
+	"github.com/cimillas/ultimate-ticket/services/api/internal/domain"
+)
+
+// SessionAuthenticator resolves a session token to its customer.
+type SessionAuthenticator interface {
+	Authenticate(ctx context.Context, token string) (domain.Customer, error)
+}
+
+// LoginService is the minimal interface needed for the login endpoints.
+type LoginService interface {
+	RequestLoginCode(ctx context.Context, email, clientIP string) error
+	VerifyLoginCode(ctx context.Context, email, code string) (app.LoginResult, error)
+	Logout(ctx context.Context, token string) error
+}
+
+// Authenticate attaches the customer named by an `Authorization: Bearer`
+// session token to the request context. Requests without a token pass
+// through anonymously; a token that is invalid or expired is rejected so
+// clients notice they have been signed out.
+func Authenticate(auth SessionAuthenticator, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := bearerToken(r)
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+		customer, err := auth.Authenticate(r.Context(), token)
+		if err != nil {
+			switch err {
+			case domain.ErrInvalidSession, domain.ErrCustomerNotFound:
+				writeError(w, http.StatusUnauthorized, codeInvalidSession, domain.ErrInvalidSession.Error())
+			default:
+				writeInternalError(w, r, err)
+			}
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(WithCustomer(r.Context(), customer)))
+	})
+}
+
+// HandleLogin returns an HTTP handler for POST /auth/login, which emails a
+// sign-in code. It answers 202 whether or not the address has an account.
+func HandleLogin(svc LoginService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			writeError(w, http.StatusMethodNotAllowed, codeMethodNotAllowed, "method not allowed")
+			return
+		}
+
+		var req loginRequest
+		dec := json.NewDecoder(r.Body)
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, codeInvalidRequestBody, "invalid request body")
+			return
+		}
+
+		if err := svc.RequestLoginCode(r.Context(), req.Email, clientIP(r)); err != nil {
+			switch err {
+			case domain.ErrInvalidEmail:
+				writeError(w, http.StatusBadRequest, codeInvalidEmail, err.Error())
+			case domain.ErrLoginThrottled:
+				writeError(w, http.StatusTooManyRequests, codeLoginThrottled, err.Error())
+			default:
+				writeInternalError(w, r, err)
+			}
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}
+}
+
+// HandleVerifyLogin returns an HTTP handler for POST /auth/verify, which
+// exchanges a sign-in code for a session token.
+func HandleVerifyLogin(svc LoginService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			writeError(w, http.StatusMethodNotAllowed, codeMethodNotAllowed, "method not allowed")
+			return
+		}
+
+		var req verifyLoginRequest
+		dec := json.NewDecoder(r.Body)
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, codeInvalidRequestBody, "invalid request body")
+			return
+		}
+		if req.Email == "" || req.Code == "" {
+			writeError(w, http.StatusBadRequest, codeMissingRequiredField, "email and code are required")
+			return
+		}
+
+		res, err := svc.VerifyLoginCode(r.Context(), req.Email, req.Code)
+		if err != nil {
+			switch err {
+			case domain.ErrInvalidLoginCode:
+				writeError(w, http.StatusUnauthorized, codeInvalidLoginCode, err.Error())
+			case domain.ErrLoginThrottled:
+				writeError(w, http.StatusTooManyRequests, codeLoginThrottled, err.Error())
+			default:
+				writeInternalError(w, r, err)
+			}
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		_ = json.NewEncoder(w).Encode(sessionResponse{
+			Token:     res.Token,
+			ExpiresAt: res.ExpiresAt,
+			Customer: customerResponse{
+				ID:    res.Customer.ID,
+				Email: res.Customer.Email,
+			},
+		})
+	}
+}
+
+// HandleLogout returns an HTTP handler for POST /auth/logout, which revokes
+// the bearer session token.
+func HandleLogout(svc LoginService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			writeError(w, http.StatusMethodNotAllowed, codeMethodNotAllowed, "method not allowed")
+			return
+		}
+		token, ok := bearerToken(r)
+		if !ok {
+			writeError(w, http.StatusUnauthorized, codeUnauthorized, "authentication required")
+			return
+		}
+		if err := svc.Logout(r.Context(), token); err != nil {
+			writeInternalError(w, r, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func bearerToken(r *http.Request) (string, bool) {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
+type loginRequest struct {
+	Email string `json:"email"`
+}
+
+type verifyLoginRequest struct {
+	Email string `json:"email"`
+	Code  string `json:"code"`
+}
+
+type customerResponse struct {
+	ID    string `json:"id"`
+	Email string `json:"email"`
+}
+
+type sessionResponse struct {
+	Token     string           `json:"token"`
+	ExpiresAt time.Time        `json:"expires_at"`
+	Customer  customerResponse `json:"customer"`
+}
@@ -0,0 +1,257 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cimillas/ultimate-ticket/services/api/internal/app"
+	"github.com/cimillas/ultimate-ticket/services/api/internal/domain"
+)
+
+func TestAuthenticate(t *testing.T) {
+	t.Parallel()
+
+	customer := domain.Customer{ID: "cust-1", Email: "fan@example.com"}
+
+	tests := []struct {
+		name           string
+		authorization  string
+		authErr        error
+		expectedStatus int
+		expectedSubstr string
+		wantCustomer   bool
+	}{
+		{
+			name:           "anonymous",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "valid token",
+			authorization:  "Bearer good",
+			expectedStatus: http.StatusOK,
+			wantCustomer:   true,
+		},
+		{
+			name:           "other scheme is ignored",
+			authorization:  "Basic Zm9vOmJhcg==",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "invalid token",
+			authorization:  "Bearer bad",
+			authErr:        domain.ErrInvalidSession,
+			expectedStatus: http.StatusUnauthorized,
+			expectedSubstr: `"code":"invalid_session"`,
+		},
+		{
+			name:           "lookup failure",
+			authorization:  "Bearer good",
+			authErr:        errors.New("db down"),
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			auth := &stubLoginService{customer: customer, err: tt.authErr}
+
+			var got domain.Customer
+			var gotOK bool
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got, gotOK = CustomerFromContext(r.Context())
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/me/orders", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			rec := httptest.NewRecorder()
+
+			Authenticate(auth, next).ServeHTTP(rec, req)
+
+			if rec.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d", tt.expectedStatus, rec.Code)
+			}
+			if tt.expectedSubstr != "" && !strings.Contains(rec.Body.String(), tt.expectedSubstr) {
+				t.Fatalf("expected response to contain %q, got %q", tt.expectedSubstr, rec.Body.String())
+			}
+			if gotOK != tt.wantCustomer || (tt.wantCustomer && got.ID != customer.ID) {
+				t.Fatalf("expected customer=%v, got %+v (%v)", tt.wantCustomer, got, gotOK)
+			}
+		})
+	}
+}
+
+func TestHandleLoginEndpoints(t *testing.T) {
+	t.Parallel()
+
+	result := app.LoginResult{
+		Customer:  domain.Customer{ID: "cust-1", Email: "fan@example.com"},
+		Token:     "session-token",
+		ExpiresAt: time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	tests := []struct {
+		name           string
+		path           string
+		method         string
+		body           string
+		authorization  string
+		serviceErr     error
+		expectedStatus int
+		expectedSubstr string
+	}{
+		{
+			name:           "request code",
+			path:           "/auth/login",
+			method:         http.MethodPost,
+			body:           `{"email":"fan@example.com"}`,
+			expectedStatus: http.StatusAccepted,
+		},
+		{
+			name:           "request code invalid email",
+			path:           "/auth/login",
+			method:         http.MethodPost,
+			body:           `{"email":"nope"}`,
+			serviceErr:     domain.ErrInvalidEmail,
+			expectedStatus: http.StatusBadRequest,
+			expectedSubstr: `"code":"invalid_email"`,
+		},
+		{
+			name:           "request code throttled",
+			path:           "/auth/login",
+			method:         http.MethodPost,
+			body:           `{"email":"fan@example.com"}`,
+			serviceErr:     domain.ErrLoginThrottled,
+			expectedStatus: http.StatusTooManyRequests,
+			expectedSubstr: `"code":"login_throttled"`,
+		},
+		{
+			name:           "request code wrong method",
+			path:           "/auth/login",
+			method:         http.MethodGet,
+			expectedStatus: http.StatusMethodNotAllowed,
+		},
+		{
+			name:           "verify",
+			path:           "/auth/verify",
+			method:         http.MethodPost,
+			body:           `{"email":"fan@example.com","code":"123456"}`,
+			expectedStatus: http.StatusOK,
+			expectedSubstr: `"token":"session-token"`,
+		},
+		{
+			name:           "verify missing code",
+			path:           "/auth/verify",
+			method:         http.MethodPost,
+			body:           `{"email":"fan@example.com"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedSubstr: `"code":"missing_required_field"`,
+		},
+		{
+			name:           "verify wrong code",
+			path:           "/auth/verify",
+			method:         http.MethodPost,
+			body:           `{"email":"fan@example.com","code":"000000"}`,
+			serviceErr:     domain.ErrInvalidLoginCode,
+			expectedStatus: http.StatusUnauthorized,
+			expectedSubstr: `"code":"invalid_login_code"`,
+		},
+		{
+			name:           "verify throttled",
+			path:           "/auth/verify",
+			method:         http.MethodPost,
+			body:           `{"email":"fan@example.com","code":"123456"}`,
+			serviceErr:     domain.ErrLoginThrottled,
+			expectedStatus: http.StatusTooManyRequests,
+			expectedSubstr: `"code":"login_throttled"`,
+		},
+		{
+			name:           "logout",
+			path:           "/auth/logout",
+			method:         http.MethodPost,
+			authorization:  "Bearer session-token",
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name:           "logout without token",
+			path:           "/auth/logout",
+			method:         http.MethodPost,
+			expectedStatus: http.StatusUnauthorized,
+			expectedSubstr: `"code":"unauthorized"`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			svc := &stubLoginService{result: result, err: tt.serviceErr}
+
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			rec := httptest.NewRecorder()
+
+			var handler http.Handler
+			switch tt.path {
+			case "/auth/login":
+				handler = HandleLogin(svc)
+			case "/auth/verify":
+				handler = HandleVerifyLogin(svc)
+			default:
+				handler = HandleLogout(svc)
+			}
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d (%s)", tt.expectedStatus, rec.Code, rec.Body.String())
+			}
+			if tt.expectedSubstr != "" && !strings.Contains(rec.Body.String(), tt.expectedSubstr) {
+				t.Fatalf("expected response to contain %q, got %q", tt.expectedSubstr, rec.Body.String())
+			}
+			if tt.path == "/auth/logout" && tt.expectedStatus == http.StatusNoContent && svc.token != "session-token" {
+				t.Fatalf("expected logout of session-token, got %q", svc.token)
+			}
+		})
+	}
+}
+
+type stubLoginService struct {
+	customer domain.Customer
+	result   app.LoginResult
+	err      error
+	token    string
+}
+
+func (s *stubLoginService) Authenticate(_ context.Context, token string) (domain.Customer, error) {
+	s.token = token
+	if s.err != nil {
+		return domain.Customer{}, s.err
+	}
+	return s.customer, nil
+}
+
+func (s *stubLoginService) RequestLoginCode(_ context.Context, _, _ string) error {
+	return s.err
+}
+
+func (s *stubLoginService) VerifyLoginCode(_ context.Context, _, _ string) (app.LoginResult, error) {
+	if s.err != nil {
+		return app.LoginResult{}, s.err
+	}
+	return s.result, nil
+}
+
+func (s *stubLoginService) Logout(_ context.Context, token string) error {
+	s.token = token
+	return s.err
+}
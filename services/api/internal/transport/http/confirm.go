@@ -3,6 +3,8 @@ package http
 import (
 	"context"
 	"encoding/json"
+	"io"
+	"log/slog"
 	"net/http"
 	"strings"
 	"time"
@@ -31,6 +33,7 @@ func HandleConfirmHold(svc HoldConfirmer) http.HandlerFunc {
 			writeError(w, http.StatusNotFound, codeNotFound, "not found")
 			return
 		}
+		logAttrs(r, slog.String("hold_id", holdID))
 
 		key := r.Header.Get(idempotencyHeader)
 		if key == "" {
@@ -38,10 +41,29 @@ func HandleConfirmHold(svc HoldConfirmer) http.HandlerFunc {
 			return
 		}
 
-		res, err := svc.ConfirmHold(r.Context(), app.ConfirmHoldInput{
+		// The body is optional; an empty body confirms without a customer email.
+		var req confirmHoldRequest
+		dec := json.NewDecoder(r.Body)
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&req); err != nil && err != io.EOF {
+			writeError(w, http.StatusBadRequest, codeInvalidRequestBody, "invalid request body")
+			return
+		}
+
+		in := app.ConfirmHoldInput{
 			HoldID:         holdID,
 			IdempotencyKey: key,
-		})
+			CustomerEmail:  req.Email,
+		}
+		// Authenticated customers get notifications at their account email
+		// unless the request names another address.
+		if customer, ok := CustomerFromContext(r.Context()); ok {
+			in.CustomerID = customer.ID
+			if in.CustomerEmail == "" {
+				in.CustomerEmail = customer.Email
+			}
+		}
+		res, err := svc.ConfirmHold(r.Context(), in)
 		if err != nil {
 			switch err {
 			case domain.ErrHoldNotFound:
@@ -60,8 +82,18 @@ func HandleConfirmHold(svc HoldConfirmer) http.HandlerFunc {
 			case domain.ErrIdempotencyKeyRequired:
 				writeError(w, http.StatusBadRequest, codeIdempotencyRequired, err.Error())
 				return
+			case domain.ErrInvalidEmail:
+				writeError(w, http.StatusBadRequest, codeInvalidEmail, err.Error())
+				return
+			case domain.ErrPaymentDeclined:
+				writeError(w, http.StatusPaymentRequired, codePaymentDeclined, err.Error())
+				return
+			case domain.ErrPaymentUnavailable:
+				logError(r, err)
+				writeError(w, http.StatusServiceUnavailable, codePaymentUnavailable, err.Error())
+				return
 			default:
-				writeError(w, http.StatusInternalServerError, codeInternalError, "internal error")
+				writeInternalError(w, r, err)
 				return
 			}
 		}
@@ -69,7 +101,7 @@ func HandleConfirmHold(svc HoldConfirmer) http.HandlerFunc {
 		resp := confirmHoldResponse{
 			ID:        res.Order.ID,
 			HoldID:    res.Order.HoldID,
-			Status:    "confirmed",
+			Status:    string(res.Order.Status),
 			CreatedAt: res.Order.CreatedAt,
 		}
 
@@ -97,6 +129,10 @@ func parseConfirmHoldPath(path string) (string, bool) {
 	return parts[1], true
 }
 
+type confirmHoldRequest struct {
+	Email string `json:"email"`
+}
+
 type confirmHoldResponse struct {
 	ID        string    `json:"id"`
 	HoldID    string    `json:"hold_id"`
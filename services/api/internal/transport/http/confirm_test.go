@@ -20,6 +20,7 @@ func TestHandleConfirmHold(t *testing.T) {
 		ID:             "order-1",
 		HoldID:         "hold-1",
 		IdempotencyKey: "idem-1",
+		Status:         domain.OrderStatusPaid,
 		CreatedAt:      now,
 	}
 
@@ -27,6 +28,10 @@ func TestHandleConfirmHold(t *testing.T) {
 		name           string
 		path           string
 		idempotencyKey string
+		body           string
+		customer       *domain.Customer
+		wantEmail      string
+		wantCustomerID string
 		result         app.ConfirmHoldResult
 		serviceErr     error
 		expectedStatus int
@@ -46,6 +51,7 @@ func TestHandleConfirmHold(t *testing.T) {
 			idempotencyKey: "idem-1",
 			result:         app.ConfirmHoldResult{Order: order, Created: false},
 			expectedStatus: http.StatusOK,
+			expectedSubstr: `"status":"paid"`,
 		},
 		{
 			name:           "missing idempotency header",
@@ -73,6 +79,59 @@ func TestHandleConfirmHold(t *testing.T) {
 			serviceErr:     domain.ErrHoldExpired,
 			expectedStatus: http.StatusConflict,
 		},
+		{
+			name:           "payment declined",
+			path:           "/holds/hold-1/confirm",
+			idempotencyKey: "idem-1",
+			serviceErr:     domain.ErrPaymentDeclined,
+			expectedStatus: http.StatusPaymentRequired,
+			expectedSubstr: `"code":"payment_declined"`,
+		},
+		{
+			name:           "payment unavailable",
+			path:           "/holds/hold-1/confirm",
+			idempotencyKey: "idem-1",
+			serviceErr:     domain.ErrPaymentUnavailable,
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedSubstr: `"code":"payment_unavailable"`,
+		},
+		{
+			name:           "with customer email",
+			path:           "/holds/hold-1/confirm",
+			idempotencyKey: "idem-1",
+			body:           `{"email":"fan@example.com"}`,
+			wantEmail:      "fan@example.com",
+			result:         app.ConfirmHoldResult{Order: order, Created: true},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "authenticated customer defaults email",
+			path:           "/holds/hold-1/confirm",
+			idempotencyKey: "idem-1",
+			customer:       &domain.Customer{ID: "cust-1", Email: "me@example.com"},
+			wantEmail:      "me@example.com",
+			wantCustomerID: "cust-1",
+			result:         app.ConfirmHoldResult{Order: order, Created: true},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "invalid email",
+			path:           "/holds/hold-1/confirm",
+			idempotencyKey: "idem-1",
+			body:           `{"email":"not-an-email"}`,
+			wantEmail:      "not-an-email",
+			serviceErr:     domain.ErrInvalidEmail,
+			expectedStatus: http.StatusBadRequest,
+			expectedSubstr: `"code":"invalid_email"`,
+		},
+		{
+			name:           "malformed body",
+			path:           "/holds/hold-1/confirm",
+			idempotencyKey: "idem-1",
+			body:           `{"mail":"fan@example.com"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedSubstr: `"code":"invalid_request_body"`,
+		},
 		{
 			name:           "invalid path",
 			path:           "/holds/hold-1",
@@ -90,10 +149,13 @@ func TestHandleConfirmHold(t *testing.T) {
 				err:    tt.serviceErr,
 			}
 
-			req := httptest.NewRequest(http.MethodPost, tt.path, nil)
+			req := httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(tt.body))
 			if tt.idempotencyKey != "" {
 				req.Header.Set(idempotencyHeader, tt.idempotencyKey)
 			}
+			if tt.customer != nil {
+				req = req.WithContext(WithCustomer(req.Context(), *tt.customer))
+			}
 			rec := httptest.NewRecorder()
 
 			HandleConfirmHold(svc).ServeHTTP(rec, req)
@@ -108,6 +170,12 @@ func TestHandleConfirmHold(t *testing.T) {
 					t.Fatalf("expected response to contain %q, got %q", tt.expectedSubstr, body)
 				}
 			}
+			if svc.in.CustomerEmail != tt.wantEmail {
+				t.Fatalf("expected customer email %q, got %q", tt.wantEmail, svc.in.CustomerEmail)
+			}
+			if svc.in.CustomerID != tt.wantCustomerID {
+				t.Fatalf("expected customer id %q, got %q", tt.wantCustomerID, svc.in.CustomerID)
+			}
 		})
 	}
 }
@@ -115,8 +183,10 @@ func TestHandleConfirmHold(t *testing.T) {
 type stubHoldConfirmer struct {
 	result app.ConfirmHoldResult
 	err    error
+	in     app.ConfirmHoldInput
 }
 
-func (s *stubHoldConfirmer) ConfirmHold(_ context.Context, _ app.ConfirmHoldInput) (app.ConfirmHoldResult, error) {
+func (s *stubHoldConfirmer) ConfirmHold(_ context.Context, in app.ConfirmHoldInput) (app.ConfirmHoldResult, error) {
+	s.in = in
 	return s.result, s.err
 }
@@ -47,10 +47,11 @@ func CORS(allowedOrigins []string, next http.Handler) http.Handler {
 			w.Header().Set("Access-Control-Allow-Origin", origin)
 			w.Header().Add("Vary", "Origin")
 		}
+		w.Header().Set("Access-Control-Expose-Headers", "Retry-After, X-Request-ID")
 
 		if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
 			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
-			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Idempotency-Key")
+			w.Header().Set("Access-Control-Allow-Headers", "Authorization, Content-Type, Idempotency-Key, Admission-Token, Pow-Challenge, Pow-Solution, X-API-Key, X-Request-ID")
 			w.WriteHeader(http.StatusNoContent)
 			return
 		}
@@ -26,6 +26,22 @@ func TestCORS_PreflightAllowed(t *testing.T) {
 	}
 }
 
+func TestCORS_ExposesRetryAfterAndRequestID(t *testing.T) {
+	handler := CORS([]string{"http://localhost:5173"}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/queue/entry-1", nil)
+	req.Header.Set("Origin", "http://localhost:5173")
+	rec := httptest.NewRecorder()
+
+	handler.ServeHTTP(rec, req)
+
+	if got := rec.Header().Get("Access-Control-Expose-Headers"); got != "Retry-After, X-Request-ID" {
+		t.Fatalf("expected Retry-After and X-Request-ID to be exposed, got %q", got)
+	}
+}
+
 func TestCORS_PreflightForbidden(t *testing.T) {
 	handler := CORS([]string{"http://localhost:5173"}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusTeapot)
@@ -0,0 +1,131 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/cimillas/ultimate-ticket/services/api/internal/domain"
+)
+
+type customerContextKey struct{}
+
+// WithCustomer returns a context carrying the authenticated customer.
+func WithCustomer(ctx context.Context, customer domain.Customer) context.Context {
+	return context.WithValue(ctx, customerContextKey{}, customer)
+}
+
+// CustomerFromContext returns the authenticated customer, if any.
+func CustomerFromContext(ctx context.Context) (domain.Customer, bool) {
+	customer, ok := ctx.Value(customerContextKey{}).(domain.Customer)
+	return customer, ok
+}
+
+// CustomerOrderService is the minimal interface needed for customer order endpoints.
+type CustomerOrderService interface {
+	ListOrders(ctx context.Context, customerID string) ([]domain.CustomerOrder, error)
+	GetOrder(ctx context.Context, customerID, orderID string) (domain.CustomerOrder, error)
+}
+
+// HandleMyOrders returns an HTTP handler for GET /me/orders.
+func HandleMyOrders(svc CustomerOrderService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			writeError(w, http.StatusMethodNotAllowed, codeMethodNotAllowed, "method not allowed")
+			return
+		}
+		customer, ok := CustomerFromContext(r.Context())
+		if !ok {
+			writeError(w, http.StatusUnauthorized, codeUnauthorized, "authentication required")
+			return
+		}
+
+		orders, err := svc.ListOrders(r.Context(), customer.ID)
+		if err != nil {
+			writeInternalError(w, r, err)
+			return
+		}
+		resp := make([]customerOrderResponse, 0, len(orders))
+		for _, order := range orders {
+			resp = append(resp, newCustomerOrderResponse(order))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(resp)
+	}
+}
+
+// HandleOrder returns an HTTP handler for GET /orders/{id}. Orders owned by
+// other customers are reported as not found.
+func HandleOrder(svc CustomerOrderService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		orderID, ok := parseOrderPath(r.URL.Path)
+		if !ok {
+			writeError(w, http.StatusNotFound, codeNotFound, "not found")
+			return
+		}
+		if r.Method != http.MethodGet {
+			writeError(w, http.StatusMethodNotAllowed, codeMethodNotAllowed, "method not allowed")
+			return
+		}
+		customer, ok := CustomerFromContext(r.Context())
+		if !ok {
+			writeError(w, http.StatusUnauthorized, codeUnauthorized, "authentication required")
+			return
+		}
+
+		order, err := svc.GetOrder(r.Context(), customer.ID, orderID)
+		if err != nil {
+			switch err {
+			case domain.ErrInvalidID:
+				writeError(w, http.StatusNotFound, codeInvalidID, err.Error())
+			case domain.ErrOrderNotFound:
+				writeError(w, http.StatusNotFound, codeOrderNotFound, err.Error())
+			default:
+				writeInternalError(w, r, err)
+			}
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(newCustomerOrderResponse(order))
+	}
+}
+
+func parseOrderPath(path string) (string, bool) {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	if len(parts) != 2 || parts[0] != "orders" || parts[1] == "" {
+		return "", false
+	}
+	return parts[1], true
+}
+
+type customerOrderResponse struct {
+	ID        string     `json:"id"`
+	HoldID    string     `json:"hold_id"`
+	Status    string     `json:"status"`
+	EventID   string     `json:"event_id"`
+	EventName string     `json:"event_name"`
+	StartsAt  time.Time  `json:"starts_at"`
+	ZoneID    string     `json:"zone_id"`
+	ZoneName  string     `json:"zone_name"`
+	Quantity  int        `json:"quantity"`
+	CreatedAt time.Time  `json:"created_at"`
+	PaidAt    *time.Time `json:"paid_at,omitempty"`
+}
+
+func newCustomerOrderResponse(co domain.CustomerOrder) customerOrderResponse {
+	return customerOrderResponse{
+		ID:        co.Order.ID,
+		HoldID:    co.Order.HoldID,
+		Status:    string(co.Order.Status),
+		EventID:   co.EventID,
+		EventName: co.EventName,
+		StartsAt:  co.StartsAt,
+		ZoneID:    co.ZoneID,
+		ZoneName:  co.ZoneName,
+		Quantity:  co.Quantity,
+		CreatedAt: co.Order.CreatedAt,
+		PaidAt:    co.Order.PaidAt,
+	}
+}
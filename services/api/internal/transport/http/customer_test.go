@@ -0,0 +1,148 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cimillas/ultimate-ticket/services/api/internal/domain"
+)
+
+func TestHandleCustomerOrders(t *testing.T) {
+	t.Parallel()
+
+	customer := domain.Customer{ID: "cust-1", Email: "fan@example.com"}
+	order := domain.CustomerOrder{
+		Order: domain.Order{
+			ID:         "order-1",
+			HoldID:     "hold-1",
+			Status:     domain.OrderStatusPaid,
+			CustomerID: "cust-1",
+			CreatedAt:  time.Date(2025, 1, 3, 12, 0, 0, 0, time.UTC),
+		},
+		EventID:   "event-1",
+		EventName: "Concert",
+		ZoneID:    "zone-1",
+		ZoneName:  "Floor",
+		Quantity:  2,
+	}
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		customer       *domain.Customer
+		serviceErr     error
+		expectedStatus int
+		expectedSubstr string
+	}{
+		{
+			name:           "list",
+			method:         http.MethodGet,
+			path:           "/me/orders",
+			customer:       &customer,
+			expectedStatus: http.StatusOK,
+			expectedSubstr: `"event_name":"Concert"`,
+		},
+		{
+			name:           "list unauthenticated",
+			method:         http.MethodGet,
+			path:           "/me/orders",
+			expectedStatus: http.StatusUnauthorized,
+			expectedSubstr: `"code":"unauthorized"`,
+		},
+		{
+			name:           "list wrong method",
+			method:         http.MethodPost,
+			path:           "/me/orders",
+			customer:       &customer,
+			expectedStatus: http.StatusMethodNotAllowed,
+		},
+		{
+			name:           "get",
+			method:         http.MethodGet,
+			path:           "/orders/order-1",
+			customer:       &customer,
+			expectedStatus: http.StatusOK,
+			expectedSubstr: `"id":"order-1"`,
+		},
+		{
+			name:           "get unauthenticated",
+			method:         http.MethodGet,
+			path:           "/orders/order-1",
+			expectedStatus: http.StatusUnauthorized,
+			expectedSubstr: `"code":"unauthorized"`,
+		},
+		{
+			name:           "get other customer's order",
+			method:         http.MethodGet,
+			path:           "/orders/order-2",
+			customer:       &customer,
+			serviceErr:     domain.ErrOrderNotFound,
+			expectedStatus: http.StatusNotFound,
+			expectedSubstr: `"code":"order_not_found"`,
+		},
+		{
+			name:           "unknown path",
+			method:         http.MethodGet,
+			path:           "/orders/order-1/tickets",
+			customer:       &customer,
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			svc := &stubCustomerOrderService{order: order, err: tt.serviceErr}
+
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.customer != nil {
+				req = req.WithContext(WithCustomer(req.Context(), *tt.customer))
+			}
+			rec := httptest.NewRecorder()
+
+			if strings.HasPrefix(tt.path, "/me/") {
+				HandleMyOrders(svc).ServeHTTP(rec, req)
+			} else {
+				HandleOrder(svc).ServeHTTP(rec, req)
+			}
+
+			if rec.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d (%s)", tt.expectedStatus, rec.Code, rec.Body.String())
+			}
+			if tt.expectedSubstr != "" && !strings.Contains(rec.Body.String(), tt.expectedSubstr) {
+				t.Fatalf("expected response to contain %q, got %q", tt.expectedSubstr, rec.Body.String())
+			}
+			if tt.customer != nil && svc.customerID != "" && svc.customerID != tt.customer.ID {
+				t.Fatalf("expected lookup for %s, got %s", tt.customer.ID, svc.customerID)
+			}
+		})
+	}
+}
+
+type stubCustomerOrderService struct {
+	order      domain.CustomerOrder
+	err        error
+	customerID string
+}
+
+func (s *stubCustomerOrderService) ListOrders(_ context.Context, customerID string) ([]domain.CustomerOrder, error) {
+	s.customerID = customerID
+	if s.err != nil {
+		return nil, s.err
+	}
+	return []domain.CustomerOrder{s.order}, nil
+}
+
+func (s *stubCustomerOrderService) GetOrder(_ context.Context, customerID, _ string) (domain.CustomerOrder, error) {
+	s.customerID = customerID
+	if s.err != nil {
+		return domain.CustomerOrder{}, s.err
+	}
+	return s.order, nil
+}
@@ -6,41 +6,112 @@ import (
 )
 
 const (
-	codeMethodNotAllowed     = "method_not_allowed"
-	codeNotFound             = "not_found"
-	codeInvalidRequestBody   = "invalid_request_body"
-	codeMissingRequiredField = "missing_required_field"
-	codeInvalidStartsAt      = "invalid_starts_at"
-	codeInvalidID            = "invalid_id"
-	codeEventNameRequired    = "event_name_required"
-	codeZoneNameRequired     = "zone_name_required"
-	codeInvalidQuantity      = "invalid_quantity"
-	codeInvalidCapacity      = "invalid_capacity"
-	codeIdempotencyRequired  = "idempotency_key_required"
-	codeIdempotencyConflict  = "idempotency_conflict"
-	codeInsufficientCapacity = "insufficient_capacity"
-	codeZoneNotFound         = "zone_not_found"
-	codeEventNotFound        = "event_not_found"
-	codeZoneAlreadyExists    = "zone_already_exists"
-	codeHoldNotFound         = "hold_not_found"
-	codeHoldExpired          = "hold_expired"
-	codeHoldAlreadyConfirmed = "hold_already_confirmed"
-	codeForbidden            = "forbidden"
-	codeInternalError        = "internal_error"
+	codeMethodNotAllowed          = "method_not_allowed"
+	codeNotFound                  = "not_found"
+	codeInvalidRequestBody        = "invalid_request_body"
+	codeMissingRequiredField      = "missing_required_field"
+	codeInvalidStartsAt           = "invalid_starts_at"
+	codeInvalidID                 = "invalid_id"
+	codeEventNameRequired         = "event_name_required"
+	codeZoneNameRequired          = "zone_name_required"
+	codeInvalidQuantity           = "invalid_quantity"
+	codeInvalidCapacity           = "invalid_capacity"
+	codeIdempotencyRequired       = "idempotency_key_required"
+	codeIdempotencyConflict       = "idempotency_conflict"
+	codeInsufficientCapacity      = "insufficient_capacity"
+	codeZoneNotFound              = "zone_not_found"
+	codeEventNotFound             = "event_not_found"
+	codeZoneAlreadyExists         = "zone_already_exists"
+	codeHoldNotFound              = "hold_not_found"
+	codeHoldExpired               = "hold_expired"
+	codeHoldAlreadyConfirmed      = "hold_already_confirmed"
+	codePaymentDeclined           = "payment_declined"
+	codePaymentUnavailable        = "payment_unavailable"
+	codeOrderNotFound             = "order_not_found"
+	codeInvalidOrderTransition    = "invalid_order_transition"
+	codeInvalidSignature          = "invalid_signature"
+	codeInvalidPaymentEvent       = "invalid_payment_event"
+	codeInvalidWebhookURL         = "invalid_webhook_url"
+	codeWebhookSecretRequired     = "webhook_secret_required"
+	codeInvalidWebhookEvent       = "invalid_webhook_event"
+	codeWebhookNotFound           = "webhook_not_found"
+	codeDeliveryNotFound          = "delivery_not_found"
+	codeDeliveryNotReplayable     = "delivery_not_replayable"
+	codeInvalidDeliveryStatus     = "invalid_delivery_status"
+	codeInvalidEmail              = "invalid_email"
+	codeNotificationNotFound      = "notification_not_found"
+	codeNotificationNotFailed     = "notification_not_failed"
+	codeInvalidNotificationStatus = "invalid_notification_status"
+	codeUnauthorized              = "unauthorized"
+	codeInvalidLoginCode          = "invalid_login_code"
+	codeLoginThrottled            = "login_throttled"
+	codeInvalidSession            = "invalid_session"
+	codeInvalidAPIKey             = "invalid_api_key"
+	codeInsufficientRole          = "insufficient_role"
+	codeAPIKeyNameRequired        = "api_key_name_required"
+	codeInvalidRole               = "invalid_role"
+	codeAPIKeyNotFound            = "api_key_not_found"
+	codeInvalidAuditFilter        = "invalid_audit_filter"
+	codePurchaseLimitExceeded     = "purchase_limit_exceeded"
+	codeInvalidPurchaseLimit      = "invalid_purchase_limit"
+	codeSignInRequired            = "sign_in_required"
+	codeQuantityBelowMinimum      = "quantity_below_minimum"
+	codeQuantityAboveMaximum      = "quantity_above_maximum"
+	codeQuantityStepMismatch      = "quantity_step_mismatch"
+	codeInvalidQuantityRules      = "invalid_quantity_rules"
+	codeInvalidAdmissionRate      = "invalid_admission_rate"
+	codeWaitingRoomDisabled       = "waiting_room_disabled"
+	codeQueueEntryNotFound        = "queue_entry_not_found"
+	codeAdmissionRequired         = "admission_required"
+	codeInvalidAdmissionToken     = "invalid_admission_token"
+	codeAdmissionUsed             = "admission_used"
+	codeLotteryNotFound           = "lottery_not_found"
+	codeLotteryExists             = "lottery_exists"
+	codeInvalidLotteryWindow      = "invalid_lottery_window"
+	codeRegistrationClosed        = "registration_closed"
+	codeRegistrationStillOpen     = "registration_still_open"
+	codeLotteryAlreadyDrawn       = "lottery_already_drawn"
+	codeInvalidSeedHash           = "invalid_seed_hash"
+	codeLotterySeedMismatch       = "lottery_seed_mismatch"
+	codeBallotExists              = "ballot_exists"
+	codeBallotNotFound            = "ballot_not_found"
+	codePurchaseRightRequired     = "purchase_right_required"
+	codePurchaseRightExceeded     = "purchase_right_exceeded"
+	codeRateLimited               = "rate_limited"
+	codeProofOfWorkRequired       = "proof_of_work_required"
+	codeInvalidProofOfWork        = "invalid_proof_of_work"
+	codeProofOfWorkReused         = "proof_of_work_reused"
+	codeHoldBlocked               = "hold_blocked"
+	codeChallengeRequired         = "challenge_required"
+	codeInvalidRiskRules          = "invalid_risk_rules"
+	codeInvalidRiskFilter         = "invalid_risk_filter"
+	codeOverloaded                = "overloaded"
+	codeForbidden                 = "forbidden"
+	codeInternalError             = "internal_error"
 )
 
 type errorResponse struct {
 	Error string `json:"error"`
 	Code  string `json:"code"`
+	// Details carries machine-readable context for codes that need it.
+	Details any `json:"details,omitempty"`
+	// RequestID quotes the X-Request-ID set by the RequestID middleware.
+	RequestID string `json:"request_id,omitempty"`
 }
 
 func writeError(w http.ResponseWriter, status int, code, msg string) {
+	writeErrorDetails(w, status, code, msg, nil)
+}
+
+func writeErrorDetails(w http.ResponseWriter, status int, code, msg string, details any) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
 
 	payload, err := json.Marshal(errorResponse{
-		Error: msg,
-		Code:  code,
+		Error:     msg,
+		Code:      code,
+		Details:   details,
+		RequestID: w.Header().Get(RequestIDHeader),
 	})
 	if err != nil {
 		_, _ = w.Write([]byte(`{"error":"internal error","code":"internal_error"}`))
@@ -48,3 +119,10 @@ func writeError(w http.ResponseWriter, status int, code, msg string) {
 	}
 	_, _ = w.Write(payload)
 }
+
+// writeInternalError hides err from the caller but keeps it for the request
+// log.
+func writeInternalError(w http.ResponseWriter, r *http.Request, err error) {
+	logError(r, err)
+	writeError(w, http.StatusInternalServerError, codeInternalError, "internal error")
+}
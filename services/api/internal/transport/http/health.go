@@ -1,7 +1,13 @@
 package http
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	stdhttp "net/http"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
 // HealthHandler reports basic liveness for the service.
@@ -10,3 +16,131 @@ func HealthHandler(w stdhttp.ResponseWriter, r *stdhttp.Request) {
 	w.WriteHeader(stdhttp.StatusOK)
 	_, _ = w.Write([]byte("ok"))
 }
+
+// DefaultReadinessTimeout bounds all readiness checks of one request.
+const DefaultReadinessTimeout = 2 * time.Second
+
+// Check statuses in readiness reports.
+const (
+	checkOK   = "ok"
+	checkWarn = "warn"
+	checkFail = "fail"
+)
+
+// ReadinessCheck reports whether one dependency is ready; a nil error means
+// ready. An error with a Warning method that returns true is reported with
+// status warn and leaves the service ready.
+type ReadinessCheck struct {
+	Name  string
+	Check func(ctx context.Context) error
+}
+
+// Readiness runs its checks on GET /readyz and reports not ready once the
+// server starts shutting down, so load balancers stop sending traffic before
+// the listener closes.
+type Readiness struct {
+	checks       []ReadinessCheck
+	timeout      time.Duration
+	shuttingDown atomic.Bool
+}
+
+func NewReadiness(timeout time.Duration, checks ...ReadinessCheck) *Readiness {
+	if timeout <= 0 {
+		timeout = DefaultReadinessTimeout
+	}
+	return &Readiness{checks: checks, timeout: timeout}
+}
+
+// ShuttingDown turns readiness off for good.
+func (rd *Readiness) ShuttingDown() {
+	rd.shuttingDown.Store(true)
+}
+
+type readinessResponse struct {
+	Status string        `json:"status"`
+	Checks []checkResult `json:"checks"`
+}
+
+type checkResult struct {
+	Name       string  `json:"name"`
+	Status     string  `json:"status"`
+	DurationMS float64 `json:"duration_ms"`
+	Error      string  `json:"error,omitempty"`
+}
+
+func (rd *Readiness) ServeHTTP(w stdhttp.ResponseWriter, r *stdhttp.Request) {
+	if r.Method != stdhttp.MethodGet {
+		writeError(w, stdhttp.StatusMethodNotAllowed, codeMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	var resp readinessResponse
+	if rd.shuttingDown.Load() {
+		resp.Checks = []checkResult{{Name: "shutdown", Status: checkFail, Error: "server is shutting down"}}
+	} else {
+		resp.Checks = rd.run(r.Context())
+	}
+
+	resp.Status = "ready"
+	status := stdhttp.StatusOK
+	for _, c := range resp.Checks {
+		if c.Status == checkFail {
+			resp.Status = "not_ready"
+			status = stdhttp.StatusServiceUnavailable
+		}
+	}
+	writeHealthJSON(w, status, resp)
+}
+
+// run runs the checks concurrently under the readiness timeout and reports
+// them in registration order.
+func (rd *Readiness) run(ctx context.Context) []checkResult {
+	ctx, cancel := context.WithTimeout(ctx, rd.timeout)
+	defer cancel()
+
+	results := make([]checkResult, len(rd.checks))
+	var wg sync.WaitGroup
+	for i, c := range rd.checks {
+		wg.Add(1)
+		go func(i int, c ReadinessCheck) {
+			defer wg.Done()
+			start := time.Now()
+			done := make(chan error, 1)
+			go func() { done <- c.Check(ctx) }()
+			var err error
+			select {
+			case err = <-done:
+			case <-ctx.Done():
+				err = ctx.Err()
+			}
+			results[i] = checkResult{Name: c.Name, Status: checkOK, DurationMS: float64(time.Since(start)) / float64(time.Millisecond)}
+			if err != nil {
+				results[i].Status = checkFail
+				results[i].Error = err.Error()
+				var w interface{ Warning() bool }
+				if errors.As(err, &w) && w.Warning() {
+					results[i].Status = checkWarn
+				}
+			}
+		}(i, c)
+	}
+	wg.Wait()
+	return results
+}
+
+// HandleLivez reports that the process is up and serving. It checks no
+// dependencies, so a database outage makes the API unready, not restarted.
+func HandleLivez(w stdhttp.ResponseWriter, r *stdhttp.Request) {
+	if r.Method != stdhttp.MethodGet {
+		writeError(w, stdhttp.StatusMethodNotAllowed, codeMethodNotAllowed, "method not allowed")
+		return
+	}
+	writeHealthJSON(w, stdhttp.StatusOK, map[string]string{"status": "ok"})
+}
+
+func writeHealthJSON(w stdhttp.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
@@ -1,8 +1,15 @@
 package http
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestHealthHandler_OK(t *testing.T) {
@@ -23,3 +30,99 @@ func TestHealthHandler_OK(t *testing.T) {
 		t.Fatalf("expected body %q, got %q", "ok", body)
 	}
 }
+
+func TestHandleLivez(t *testing.T) {
+	t.Parallel()
+
+	rec := httptest.NewRecorder()
+	HandleLivez(rec, httptest.NewRequest(http.MethodGet, "/livez", nil))
+	if rec.Code != http.StatusOK || strings.TrimSpace(rec.Body.String()) != `{"status":"ok"}` {
+		t.Fatalf("expected 200 ok, got %d %s", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	HandleLivez(rec, httptest.NewRequest(http.MethodPost, "/livez", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("expected 405, got %d", rec.Code)
+	}
+}
+
+func TestReadiness(t *testing.T) {
+	t.Parallel()
+
+	ok := ReadinessCheck{Name: "database", Check: func(context.Context) error { return nil }}
+	pending := ReadinessCheck{Name: "migrations", Check: func(context.Context) error { return errors.New("1 pending: 0022_next.sql") }}
+	hung := ReadinessCheck{Name: "workers", Check: func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}}
+
+	serve := func(rd *Readiness) (int, readinessResponse) {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		rd.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+		var resp readinessResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		return rec.Code, resp
+	}
+
+	t.Run("ready", func(t *testing.T) {
+		t.Parallel()
+		code, resp := serve(NewReadiness(time.Second, ok))
+		if code != http.StatusOK || resp.Status != "ready" || len(resp.Checks) != 1 || resp.Checks[0].Status != checkOK {
+			t.Fatalf("expected ready, got %d %+v", code, resp)
+		}
+	})
+
+	t.Run("failing and timed out checks", func(t *testing.T) {
+		t.Parallel()
+		code, resp := serve(NewReadiness(20*time.Millisecond, ok, pending, hung))
+		if code != http.StatusServiceUnavailable || resp.Status != "not_ready" {
+			t.Fatalf("expected 503 not_ready, got %d %+v", code, resp)
+		}
+		want := []checkResult{
+			{Name: "database", Status: checkOK},
+			{Name: "migrations", Status: checkFail, Error: "1 pending: 0022_next.sql"},
+			{Name: "workers", Status: checkFail, Error: context.DeadlineExceeded.Error()},
+		}
+		for i := range want {
+			got := resp.Checks[i]
+			got.DurationMS = 0
+			if got != want[i] {
+				t.Fatalf("check %d: expected %+v, got %+v", i, want[i], got)
+			}
+		}
+	})
+
+	t.Run("warnings keep the service ready", func(t *testing.T) {
+		t.Parallel()
+		late := ReadinessCheck{Name: "workers", Check: func(context.Context) error {
+			return fmt.Errorf("check: %w", lateWorkers{})
+		}}
+		code, resp := serve(NewReadiness(time.Second, ok, late))
+		if code != http.StatusOK || resp.Status != "ready" {
+			t.Fatalf("expected 200 ready, got %d %+v", code, resp)
+		}
+		got := resp.Checks[1]
+		if got.Status != checkWarn || got.Error != "check: outbox relay stalled for 2m0s" {
+			t.Fatalf("expected the warning in the report, got %+v", got)
+		}
+	})
+
+	t.Run("shutting down", func(t *testing.T) {
+		t.Parallel()
+		rd := NewReadiness(time.Second, ok)
+		rd.ShuttingDown()
+		code, resp := serve(rd)
+		if code != http.StatusServiceUnavailable || len(resp.Checks) != 1 || resp.Checks[0].Name != "shutdown" {
+			t.Fatalf("expected not ready while shutting down, got %d %+v", code, resp)
+		}
+	})
+}
+
+type lateWorkers struct{}
+
+func (lateWorkers) Error() string { return "outbox relay stalled for 2m0s" }
+func (lateWorkers) Warning() bool { return true }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"log/slog"
 	"net/http"
 	"time"
 
@@ -16,13 +17,35 @@ type HoldCreator interface {
 	CreateHold(rctx context.Context, in app.CreateHoldInput) (domain.Hold, error)
 }
 
+type holdHandler struct {
+	pow *ProofOfWork
+}
+
+type HoldHandlerOption func(*holdHandler)
+
+// WithProofOfWork requires holds from clients that are not signed in to carry
+// a solved challenge. Signed-in customers may send one anyway to satisfy a
+// risk challenge.
+func WithProofOfWork(p *ProofOfWork) HoldHandlerOption {
+	return func(h *holdHandler) {
+		h.pow = p
+	}
+}
+
 // HandleCreateHold returns an HTTP handler for creating holds.
-func HandleCreateHold(svc HoldCreator) http.HandlerFunc {
+func HandleCreateHold(svc HoldCreator, opts ...HoldHandlerOption) http.HandlerFunc {
+	var h holdHandler
+	for _, opt := range opts {
+		opt(&h)
+	}
 	return func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodPost {
 			writeError(w, http.StatusMethodNotAllowed, codeMethodNotAllowed, "method not allowed")
 			return
 		}
+		if h.pow != nil {
+			h.pow.observe()
+		}
 
 		var req createHoldRequest
 		dec := json.NewDecoder(r.Body)
@@ -45,12 +68,38 @@ func HandleCreateHold(svc HoldCreator) http.HandlerFunc {
 			return
 		}
 
-		hold, err := svc.CreateHold(r.Context(), app.CreateHoldInput{
+		in := app.CreateHoldInput{
 			EventID:        req.EventID,
 			ZoneID:         req.ZoneID,
 			Quantity:       req.Quantity,
 			IdempotencyKey: req.IdempotencyKey,
-		})
+			AdmissionToken: r.Header.Get(AdmissionTokenHeader),
+			ClientIP:       clientIP(r),
+			UserAgent:      r.UserAgent(),
+		}
+		if customer, ok := CustomerFromContext(r.Context()); ok {
+			in.CustomerID = customer.ID
+		}
+		logAttrs(r, slog.String("event_id", in.EventID), slog.String("zone_id", in.ZoneID))
+		if h.pow != nil && (in.CustomerID == "" || r.Header.Get(ChallengeHeader) != "") {
+			if !h.pow.verify(w, r, req) {
+				return
+			}
+			in.ProofOfWork = true
+		}
+		hold, err := svc.CreateHold(r.Context(), in)
+		var limitErr *domain.PurchaseLimitError
+		if errors.As(err, &limitErr) {
+			writeErrorDetails(w, http.StatusConflict, codePurchaseLimitExceeded, limitErr.Error(), purchaseLimitDetails{
+				Scope:     limitErr.Scope,
+				Limit:     limitErr.Limit,
+				Remaining: limitErr.Remaining,
+			})
+			return
+		}
+		if writeQuantityRuleError(w, err) {
+			return
+		}
 		if err != nil {
 			switch err {
 			case domain.ErrInvalidQuantity:
@@ -71,8 +120,32 @@ func HandleCreateHold(svc HoldCreator) http.HandlerFunc {
 			case domain.ErrInsufficientCapacity:
 				writeError(w, http.StatusConflict, codeInsufficientCapacity, err.Error())
 				return
+			case domain.ErrSignInRequired:
+				writeError(w, http.StatusUnauthorized, codeSignInRequired, err.Error())
+				return
+			case domain.ErrAdmissionRequired:
+				writeError(w, http.StatusForbidden, codeAdmissionRequired, err.Error())
+				return
+			case domain.ErrInvalidAdmissionToken:
+				writeError(w, http.StatusForbidden, codeInvalidAdmissionToken, err.Error())
+				return
+			case domain.ErrAdmissionUsed:
+				writeError(w, http.StatusConflict, codeAdmissionUsed, err.Error())
+				return
+			case domain.ErrPurchaseRightRequired:
+				writeError(w, http.StatusForbidden, codePurchaseRightRequired, err.Error())
+				return
+			case domain.ErrPurchaseRightExceeded:
+				writeError(w, http.StatusConflict, codePurchaseRightExceeded, err.Error())
+				return
+			case domain.ErrHoldBlocked:
+				writeError(w, http.StatusForbidden, codeHoldBlocked, err.Error())
+				return
+			case domain.ErrChallengeRequired:
+				writeError(w, http.StatusForbidden, codeChallengeRequired, err.Error())
+				return
 			default:
-				writeError(w, http.StatusInternalServerError, codeInternalError, "internal error")
+				writeInternalError(w, r, err)
 				return
 			}
 		}
@@ -111,8 +184,43 @@ func (r createHoldRequest) validate() error {
 	return nil
 }
 
+type purchaseLimitDetails struct {
+	Scope     string `json:"scope"`
+	Limit     int    `json:"limit"`
+	Remaining int    `json:"remaining"`
+}
+
 type createHoldResponse struct {
 	ID        string    `json:"id"`
 	Status    string    `json:"status"`
 	ExpiresAt time.Time `json:"expires_at"`
 }
+
+// quantityRuleDetails lists the zone's rules; max is omitted when there is none.
+type quantityRuleDetails struct {
+	Min  int `json:"min"`
+	Max  int `json:"max,omitempty"`
+	Step int `json:"step"`
+}
+
+// writeQuantityRuleError writes a 400 with the zone's rules when err is a
+// *domain.QuantityRuleError and reports whether it did.
+func writeQuantityRuleError(w http.ResponseWriter, err error) bool {
+	var ruleErr *domain.QuantityRuleError
+	if !errors.As(err, &ruleErr) {
+		return false
+	}
+	code := codeQuantityStepMismatch
+	switch ruleErr.Err {
+	case domain.ErrQuantityBelowMinimum:
+		code = codeQuantityBelowMinimum
+	case domain.ErrQuantityAboveMaximum:
+		code = codeQuantityAboveMaximum
+	}
+	writeErrorDetails(w, http.StatusBadRequest, code, ruleErr.Error(), quantityRuleDetails{
+		Min:  ruleErr.Min,
+		Max:  ruleErr.Max,
+		Step: ruleErr.Step,
+	})
+	return true
+}
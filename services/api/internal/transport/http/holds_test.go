@@ -76,6 +76,83 @@ func TestHandleCreateHold(t *testing.T) {
 			serviceErr:     domain.ErrInsufficientCapacity,
 			expectedStatus: http.StatusConflict,
 		},
+		{
+			name:           "admission required",
+			body:           `{"event_id":"e1","zone_id":"z1","quantity":1,"idempotency_key":"k1"}`,
+			serviceErr:     domain.ErrAdmissionRequired,
+			expectedStatus: http.StatusForbidden,
+			expectedSubstr: `"code":"admission_required"`,
+		},
+		{
+			name:           "invalid admission token",
+			body:           `{"event_id":"e1","zone_id":"z1","quantity":1,"idempotency_key":"k1"}`,
+			serviceErr:     domain.ErrInvalidAdmissionToken,
+			expectedStatus: http.StatusForbidden,
+			expectedSubstr: `"code":"invalid_admission_token"`,
+		},
+		{
+			name:           "purchase right required",
+			body:           `{"event_id":"e1","zone_id":"z1","quantity":1,"idempotency_key":"k1"}`,
+			serviceErr:     domain.ErrPurchaseRightRequired,
+			expectedStatus: http.StatusForbidden,
+			expectedSubstr: `"code":"purchase_right_required"`,
+		},
+		{
+			name:           "purchase right exceeded",
+			body:           `{"event_id":"e1","zone_id":"z1","quantity":3,"idempotency_key":"k1"}`,
+			serviceErr:     domain.ErrPurchaseRightExceeded,
+			expectedStatus: http.StatusConflict,
+			expectedSubstr: `"code":"purchase_right_exceeded"`,
+		},
+		{
+			name:           "below zone minimum",
+			body:           `{"event_id":"e1","zone_id":"z1","quantity":1,"idempotency_key":"k1"}`,
+			serviceErr:     &domain.QuantityRuleError{Err: domain.ErrQuantityBelowMinimum, Min: 2, Max: 8, Step: 2},
+			expectedStatus: http.StatusBadRequest,
+			expectedSubstr: `"code":"quantity_below_minimum","details":{"min":2,"max":8,"step":2}`,
+		},
+		{
+			name:           "above zone maximum",
+			body:           `{"event_id":"e1","zone_id":"z1","quantity":10,"idempotency_key":"k1"}`,
+			serviceErr:     &domain.QuantityRuleError{Err: domain.ErrQuantityAboveMaximum, Min: 2, Max: 8, Step: 2},
+			expectedStatus: http.StatusBadRequest,
+			expectedSubstr: `"code":"quantity_above_maximum"`,
+		},
+		{
+			name:           "off zone step",
+			body:           `{"event_id":"e1","zone_id":"z1","quantity":9,"idempotency_key":"k1"}`,
+			serviceErr:     &domain.QuantityRuleError{Err: domain.ErrQuantityStepMismatch, Min: 6, Step: 6},
+			expectedStatus: http.StatusBadRequest,
+			expectedSubstr: `"code":"quantity_step_mismatch","details":{"min":6,"step":6}`,
+		},
+		{
+			name:           "sign-in required by purchase limits",
+			body:           `{"event_id":"e1","zone_id":"z1","quantity":1,"idempotency_key":"k1"}`,
+			serviceErr:     domain.ErrSignInRequired,
+			expectedStatus: http.StatusUnauthorized,
+			expectedSubstr: `"code":"sign_in_required"`,
+		},
+		{
+			name:           "purchase limit exceeded",
+			body:           `{"event_id":"e1","zone_id":"z1","quantity":3,"idempotency_key":"k1"}`,
+			serviceErr:     &domain.PurchaseLimitError{Scope: domain.PurchaseLimitEvent, Limit: 4, Remaining: 1},
+			expectedStatus: http.StatusConflict,
+			expectedSubstr: `"code":"purchase_limit_exceeded","details":{"scope":"event","limit":4,"remaining":1}`,
+		},
+		{
+			name:           "blocked by risk rules",
+			body:           `{"event_id":"e1","zone_id":"z1","quantity":1,"idempotency_key":"k1"}`,
+			serviceErr:     domain.ErrHoldBlocked,
+			expectedStatus: http.StatusForbidden,
+			expectedSubstr: `"code":"hold_blocked"`,
+		},
+		{
+			name:           "challenged by risk rules",
+			body:           `{"event_id":"e1","zone_id":"z1","quantity":1,"idempotency_key":"k1"}`,
+			serviceErr:     domain.ErrChallengeRequired,
+			expectedStatus: http.StatusForbidden,
+			expectedSubstr: `"code":"challenge_required"`,
+		},
 		{
 			name:           "internal error",
 			body:           `{"event_id":"e1","zone_id":"z1","quantity":1,"idempotency_key":"k1"}`,
@@ -115,8 +192,48 @@ func TestHandleCreateHold(t *testing.T) {
 type stubHoldService struct {
 	hold domain.Hold
 	err  error
+	in   app.CreateHoldInput
 }
 
-func (s *stubHoldService) CreateHold(_ context.Context, _ app.CreateHoldInput) (domain.Hold, error) {
+func (s *stubHoldService) CreateHold(_ context.Context, in app.CreateHoldInput) (domain.Hold, error) {
+	s.in = in
 	return s.hold, s.err
 }
+
+func TestHandleCreateHold_PassesAdmissionToken(t *testing.T) {
+	t.Parallel()
+
+	svc := &stubHoldService{}
+	req := httptest.NewRequest(http.MethodPost, "/holds", bytes.NewBufferString(`{"event_id":"e1","zone_id":"z1","quantity":1,"idempotency_key":"k1"}`))
+	req.Header.Set(AdmissionTokenHeader, "signed-token")
+	rec := httptest.NewRecorder()
+
+	HandleCreateHold(svc).ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected status %d, got %d", http.StatusCreated, rec.Code)
+	}
+	if svc.in.AdmissionToken != "signed-token" {
+		t.Fatalf("expected admission token to be passed, got %q", svc.in.AdmissionToken)
+	}
+}
+
+func TestHandleCreateHold_PassesRiskSignals(t *testing.T) {
+	t.Parallel()
+
+	svc := &stubHoldService{}
+	req := httptest.NewRequest(http.MethodPost, "/holds", bytes.NewBufferString(`{"event_id":"e1","zone_id":"z1","quantity":1,"idempotency_key":"k1"}`))
+	req.RemoteAddr = "10.0.0.1:1234"
+	req.Header.Set("X-Forwarded-For", "203.0.113.7")
+	req.Header.Set("User-Agent", "Mozilla/5.0")
+	rec := httptest.NewRecorder()
+
+	ClientIP(true, HandleCreateHold(svc)).ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected status %d, got %d", http.StatusCreated, rec.Code)
+	}
+	if svc.in.ClientIP != "203.0.113.7" || svc.in.UserAgent != "Mozilla/5.0" || svc.in.ProofOfWork {
+		t.Fatalf("unexpected signals %+v", svc.in)
+	}
+}
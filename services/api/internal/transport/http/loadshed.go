@@ -0,0 +1,147 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/cimillas/ultimate-ticket/services/api/internal/app"
+	"github.com/cimillas/ultimate-ticket/services/api/internal/clock"
+	"github.com/cimillas/ultimate-ticket/services/api/internal/domain"
+)
+
+const (
+	// loadShedLatencyWeight is how much each finished request moves the
+	// latency average.
+	loadShedLatencyWeight = 0.2
+	// maxShedRetryAfter caps the Retry-After sent with a shed request.
+	maxShedRetryAfter = 30 * time.Second
+)
+
+// LoadShedder turns hold attempts away while the database is saturated, so
+// requests already queued behind the zone locks can finish. It tracks the
+// requests in flight on the routes it wraps and a moving average of how long
+// the hold and confirm service calls take. While the average stays under the
+// target latency, up to maxInFlight requests run at once; above it the
+// allowance shrinks in proportion, down to one request at a time.
+//
+// The latency comes from the services wrapped with Holds and Confirms, not
+// from the routes, so requests turned away by the rate limit, proof of work
+// or validation do not pull the average down.
+type LoadShedder struct {
+	clock         clock.Clock
+	maxInFlight   int
+	targetLatency time.Duration
+
+	mu       sync.Mutex
+	inFlight int
+	latency  time.Duration
+}
+
+// NewLoadShedder returns a shedder allowing up to maxInFlight requests at the
+// target latency. A zero maxInFlight only tracks load and never sheds.
+func NewLoadShedder(maxInFlight int, targetLatency time.Duration, clk clock.Clock) *LoadShedder {
+	return &LoadShedder{clock: clk, maxInFlight: maxInFlight, targetLatency: targetLatency}
+}
+
+// Shed wraps a handler whose requests are refused with 503 while the load is
+// over the allowance.
+func (s *LoadShedder) Shed(next http.Handler) http.Handler {
+	return s.guard(true, next)
+}
+
+// Track wraps a handler whose requests are always served but count towards
+// the load, such as confirmations of seats already held.
+func (s *LoadShedder) Track(next http.Handler) http.Handler {
+	return s.guard(false, next)
+}
+
+func (s *LoadShedder) guard(sheddable bool, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		retryAfter, ok := s.acquire(sheddable)
+		if !ok {
+			setRetryAfter(w, retryAfter)
+			writeError(w, http.StatusServiceUnavailable, codeOverloaded, "server overloaded, retry later")
+			return
+		}
+		defer s.release()
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Holds wraps svc so its calls feed the latency average.
+func (s *LoadShedder) Holds(svc HoldCreator) HoldCreator {
+	return shedHoldCreator{shedder: s, svc: svc}
+}
+
+// Confirms wraps svc so its calls feed the latency average.
+func (s *LoadShedder) Confirms(svc HoldConfirmer) HoldConfirmer {
+	return shedHoldConfirmer{shedder: s, svc: svc}
+}
+
+type shedHoldCreator struct {
+	shedder *LoadShedder
+	svc     HoldCreator
+}
+
+func (c shedHoldCreator) CreateHold(ctx context.Context, in app.CreateHoldInput) (domain.Hold, error) {
+	defer c.shedder.observe(c.shedder.clock.Now())
+	return c.svc.CreateHold(ctx, in)
+}
+
+type shedHoldConfirmer struct {
+	shedder *LoadShedder
+	svc     HoldConfirmer
+}
+
+func (c shedHoldConfirmer) ConfirmHold(ctx context.Context, in app.ConfirmHoldInput) (app.ConfirmHoldResult, error) {
+	defer c.shedder.observe(c.shedder.clock.Now())
+	return c.svc.ConfirmHold(ctx, in)
+}
+
+// acquire counts a request in, unless it is sheddable and the allowance is
+// used up; then it returns how long the client should wait.
+func (s *LoadShedder) acquire(sheddable bool) (time.Duration, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if sheddable && s.maxInFlight > 0 && s.inFlight >= s.allowance() {
+		return s.retryAfter(), false
+	}
+	s.inFlight++
+	return 0, true
+}
+
+func (s *LoadShedder) release() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.inFlight--
+}
+
+// observe moves the latency average by a service call that started at start.
+func (s *LoadShedder) observe(start time.Time) {
+	took := s.clock.Now().Sub(start)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.latency == 0 {
+		s.latency = took
+		return
+	}
+	s.latency += time.Duration(loadShedLatencyWeight * float64(took-s.latency))
+}
+
+// allowance is maxInFlight scaled down by how far the average latency is over
+// the target. One request is always allowed so the average keeps moving.
+func (s *LoadShedder) allowance() int {
+	if s.latency <= s.targetLatency {
+		return s.maxInFlight
+	}
+	return max(1, int(float64(s.maxInFlight)*float64(s.targetLatency)/float64(s.latency)))
+}
+
+// retryAfter suggests waiting about as long as a request currently takes,
+// rounded up to whole seconds.
+func (s *LoadShedder) retryAfter() time.Duration {
+	wait := (s.latency + time.Second - 1).Truncate(time.Second)
+	return min(max(wait, time.Second), maxShedRetryAfter)
+}
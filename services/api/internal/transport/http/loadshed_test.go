@@ -0,0 +1,149 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cimillas/ultimate-ticket/services/api/internal/app"
+	"github.com/cimillas/ultimate-ticket/services/api/internal/clock"
+)
+
+// stepClock moves forward by step on every reading, so each service call
+// wrapped by a LoadShedder takes exactly step.
+type stepClock struct {
+	mu   sync.Mutex
+	now  time.Time
+	step time.Duration
+}
+
+func (c *stepClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(c.step)
+	return c.now
+}
+
+func TestLoadShedder(t *testing.T) {
+	t.Parallel()
+
+	ok := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	serve := func(h http.Handler) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/holds", nil))
+		return rec
+	}
+
+	t.Run("sheds holds over the in-flight limit but not confirmations", func(t *testing.T) {
+		t.Parallel()
+		shedder := NewLoadShedder(2, time.Second, clock.NewFixed(time.Now()))
+
+		started, release := make(chan struct{}), make(chan struct{})
+		blocking := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			started <- struct{}{}
+			<-release
+			w.WriteHeader(http.StatusNoContent)
+		})
+		var wg sync.WaitGroup
+		for i := 0; i < 2; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				serve(shedder.Shed(blocking))
+			}()
+			<-started
+		}
+
+		rec := serve(shedder.Shed(ok))
+		if rec.Code != http.StatusServiceUnavailable {
+			t.Fatalf("expected 503, got %d", rec.Code)
+		}
+		if got := rec.Header().Get("Retry-After"); got != "1" {
+			t.Fatalf("expected Retry-After 1, got %q", got)
+		}
+		if !strings.Contains(rec.Body.String(), `"code":"overloaded"`) {
+			t.Fatalf("expected code overloaded, got %s", rec.Body.String())
+		}
+		if rec := serve(shedder.Track(ok)); rec.Code != http.StatusNoContent {
+			t.Fatalf("expected confirmations through, got %d", rec.Code)
+		}
+
+		close(release)
+		wg.Wait()
+		if rec := serve(shedder.Shed(ok)); rec.Code != http.StatusNoContent {
+			t.Fatalf("expected holds through once load drops, got %d", rec.Code)
+		}
+	})
+
+	// holding calls the hold service through the shedder, as HandleCreateHold
+	// does once a request is valid.
+	holding := func(shedder *LoadShedder) http.Handler {
+		svc := shedder.Holds(&stubHoldService{})
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if _, err := svc.CreateHold(r.Context(), app.CreateHoldInput{}); err != nil {
+				t.Errorf("create hold: %v", err)
+			}
+			w.WriteHeader(http.StatusNoContent)
+		})
+	}
+
+	t.Run("slow service calls shrink the allowance", func(t *testing.T) {
+		t.Parallel()
+		clk := &stepClock{now: time.Now(), step: 4 * time.Second}
+		shedder := NewLoadShedder(8, time.Second, clk)
+
+		if rec := serve(shedder.Shed(holding(shedder))); rec.Code != http.StatusNoContent {
+			t.Fatalf("expected 204, got %d", rec.Code)
+		}
+		if got := shedder.allowance(); got != 2 {
+			t.Fatalf("expected an allowance of 2 at four times the target latency, got %d", got)
+		}
+		shedder.inFlight = 2
+		rec := serve(shedder.Shed(ok))
+		if rec.Code != http.StatusServiceUnavailable || rec.Header().Get("Retry-After") != "4" {
+			t.Fatalf("expected 503 with Retry-After 4, got %d %q", rec.Code, rec.Header().Get("Retry-After"))
+		}
+		shedder.inFlight = 0
+
+		// Fast requests bring the average, and the allowance, back.
+		clk.step = 10 * time.Millisecond
+		for i := 0; i < 30; i++ {
+			serve(shedder.Shed(holding(shedder)))
+		}
+		if got := shedder.allowance(); got != 8 {
+			t.Fatalf("expected the full allowance back, got %d", got)
+		}
+	})
+
+	t.Run("requests that never reach the service leave the average alone", func(t *testing.T) {
+		t.Parallel()
+		clk := &stepClock{now: time.Now(), step: 4 * time.Second}
+		shedder := NewLoadShedder(8, time.Second, clk)
+
+		serve(shedder.Shed(holding(shedder)))
+		clk.step = time.Millisecond
+		for i := 0; i < 30; i++ {
+			serve(shedder.Shed(ok))
+		}
+		if got := shedder.allowance(); got != 2 {
+			t.Fatalf("expected rejected requests not to restore the allowance, got %d", got)
+		}
+		if shedder.inFlight != 0 {
+			t.Fatalf("expected no requests in flight, got %d", shedder.inFlight)
+		}
+	})
+
+	t.Run("zero limit only tracks", func(t *testing.T) {
+		t.Parallel()
+		shedder := NewLoadShedder(0, time.Second, clock.NewFixed(time.Now()))
+		shedder.inFlight = 100
+		if rec := serve(shedder.Shed(ok)); rec.Code != http.StatusNoContent {
+			t.Fatalf("expected 204, got %d", rec.Code)
+		}
+	})
+}
@@ -0,0 +1,317 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/cimillas/ultimate-ticket/services/api/internal/app"
+	"github.com/cimillas/ultimate-ticket/services/api/internal/domain"
+)
+
+// AdminLotteryService is the minimal interface needed for admin lottery endpoints.
+type AdminLotteryService interface {
+	CreateLottery(ctx context.Context, in app.CreateLotteryInput) (domain.Lottery, error)
+	GetLottery(ctx context.Context, organizerID, lotteryID string) (domain.Lottery, error)
+	Draw(ctx context.Context, organizerID, lotteryID, seed string) (app.DrawResult, error)
+}
+
+// LotteryEntryService is the minimal interface needed for customer ballots.
+type LotteryEntryService interface {
+	Enter(ctx context.Context, lotteryID, customerID string, quantity int) (domain.Ballot, error)
+	GetBallot(ctx context.Context, lotteryID, customerID string) (domain.Ballot, error)
+}
+
+// HandleAdminLotteries returns an HTTP handler for POST /admin/lotteries.
+func HandleAdminLotteries(svc AdminLotteryService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			writeError(w, http.StatusMethodNotAllowed, codeMethodNotAllowed, "method not allowed")
+			return
+		}
+		organizerID, ok := adminOrganizer(w, r)
+		if !ok {
+			return
+		}
+
+		var req createLotteryRequest
+		dec := json.NewDecoder(r.Body)
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, codeInvalidRequestBody, "invalid request body")
+			return
+		}
+		if req.ZoneID == "" {
+			writeError(w, http.StatusBadRequest, codeMissingRequiredField, "zone_id is required")
+			return
+		}
+		opensAt, err1 := time.Parse(time.RFC3339, req.RegistrationOpensAt)
+		closesAt, err2 := time.Parse(time.RFC3339, req.RegistrationClosesAt)
+		if err1 != nil || err2 != nil {
+			writeError(w, http.StatusBadRequest, codeInvalidLotteryWindow, "registration_opens_at and registration_closes_at must be RFC3339")
+			return
+		}
+
+		lottery, err := svc.CreateLottery(r.Context(), app.CreateLotteryInput{
+			OrganizerID:          organizerID,
+			ZoneID:               req.ZoneID,
+			RegistrationOpensAt:  opensAt,
+			RegistrationClosesAt: closesAt,
+			ClaimWindow:          time.Duration(req.ClaimWindowMinutes) * time.Minute,
+			SeedHash:             req.SeedHash,
+		})
+		if err != nil {
+			switch err {
+			case domain.ErrInvalidID, domain.ErrZoneNotFound:
+				writeError(w, http.StatusNotFound, codeZoneNotFound, domain.ErrZoneNotFound.Error())
+			case domain.ErrInvalidLotteryWindow:
+				writeError(w, http.StatusBadRequest, codeInvalidLotteryWindow, err.Error())
+			case domain.ErrInvalidSeedHash:
+				writeError(w, http.StatusBadRequest, codeInvalidSeedHash, err.Error())
+			case domain.ErrLotteryExists:
+				writeError(w, http.StatusConflict, codeLotteryExists, err.Error())
+			default:
+				writeInternalError(w, r, err)
+			}
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(newLotteryResponse(lottery))
+	}
+}
+
+// HandleAdminLottery returns an HTTP handler for GET /admin/lotteries/{id}
+// and POST /admin/lotteries/{id}/draw.
+func HandleAdminLottery(svc AdminLotteryService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		lotteryID, action, ok := parseAdminLotteryPath(r.URL.Path)
+		if !ok {
+			writeError(w, http.StatusNotFound, codeNotFound, "not found")
+			return
+		}
+		if (action == "" && r.Method != http.MethodGet) || (action == "draw" && r.Method != http.MethodPost) {
+			writeError(w, http.StatusMethodNotAllowed, codeMethodNotAllowed, "method not allowed")
+			return
+		}
+		organizerID, ok := adminOrganizer(w, r)
+		if !ok {
+			return
+		}
+
+		if action == "" {
+			lottery, err := svc.GetLottery(r.Context(), organizerID, lotteryID)
+			if err != nil {
+				writeLotteryError(w, r, err)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(newLotteryResponse(lottery))
+			return
+		}
+
+		var req drawLotteryRequest
+		if r.ContentLength != 0 {
+			dec := json.NewDecoder(r.Body)
+			dec.DisallowUnknownFields()
+			if err := dec.Decode(&req); err != nil {
+				writeError(w, http.StatusBadRequest, codeInvalidRequestBody, "invalid request body")
+				return
+			}
+		}
+		result, err := svc.Draw(r.Context(), organizerID, lotteryID, req.Seed)
+		if err != nil {
+			writeLotteryError(w, r, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(drawResponse{
+			Lottery: newLotteryResponse(result.Lottery),
+			Ballots: result.Ballots,
+			Offered: result.Offered,
+		})
+	}
+}
+
+// HandleLotteryEntry returns an HTTP handler for POST and GET
+// /lotteries/{id}/entry, where signed-in customers enter a lottery and check
+// whether they won a purchase right.
+func HandleLotteryEntry(svc LotteryEntryService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		lotteryID, ok := parseLotteryEntryPath(r.URL.Path)
+		if !ok {
+			writeError(w, http.StatusNotFound, codeNotFound, "not found")
+			return
+		}
+		if r.Method != http.MethodPost && r.Method != http.MethodGet {
+			writeError(w, http.StatusMethodNotAllowed, codeMethodNotAllowed, "method not allowed")
+			return
+		}
+		customer, ok := CustomerFromContext(r.Context())
+		if !ok {
+			writeError(w, http.StatusUnauthorized, codeUnauthorized, "authentication required")
+			return
+		}
+
+		if r.Method == http.MethodGet {
+			ballot, err := svc.GetBallot(r.Context(), lotteryID, customer.ID)
+			if err != nil {
+				writeLotteryError(w, r, err)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(newBallotResponse(ballot))
+			return
+		}
+
+		var req enterLotteryRequest
+		dec := json.NewDecoder(r.Body)
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, codeInvalidRequestBody, "invalid request body")
+			return
+		}
+		ballot, err := svc.Enter(r.Context(), lotteryID, customer.ID, req.Quantity)
+		if writeQuantityRuleError(w, err) {
+			return
+		}
+		if err != nil {
+			writeLotteryError(w, r, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(newBallotResponse(ballot))
+	}
+}
+
+func writeLotteryError(w http.ResponseWriter, r *http.Request, err error) {
+	switch err {
+	case domain.ErrInvalidID, domain.ErrLotteryNotFound:
+		writeError(w, http.StatusNotFound, codeLotteryNotFound, domain.ErrLotteryNotFound.Error())
+	case domain.ErrBallotNotFound:
+		writeError(w, http.StatusNotFound, codeBallotNotFound, err.Error())
+	case domain.ErrInvalidQuantity:
+		writeError(w, http.StatusBadRequest, codeInvalidQuantity, err.Error())
+	case domain.ErrRegistrationClosed:
+		writeError(w, http.StatusConflict, codeRegistrationClosed, err.Error())
+	case domain.ErrRegistrationStillOpen:
+		writeError(w, http.StatusConflict, codeRegistrationStillOpen, err.Error())
+	case domain.ErrLotteryAlreadyDrawn:
+		writeError(w, http.StatusConflict, codeLotteryAlreadyDrawn, err.Error())
+	case domain.ErrLotterySeedMismatch:
+		writeError(w, http.StatusBadRequest, codeLotterySeedMismatch, err.Error())
+	case domain.ErrBallotExists:
+		writeError(w, http.StatusConflict, codeBallotExists, err.Error())
+	default:
+		writeInternalError(w, r, err)
+	}
+}
+
+type createLotteryRequest struct {
+	ZoneID               string `json:"zone_id"`
+	RegistrationOpensAt  string `json:"registration_opens_at"`
+	RegistrationClosesAt string `json:"registration_closes_at"`
+	ClaimWindowMinutes   int    `json:"claim_window_minutes"`
+	// SeedHash is optional; without it the server commits to a seed of its own.
+	SeedHash string `json:"seed_hash,omitempty"`
+}
+
+// drawLotteryRequest may be omitted when the server holds the seed; a
+// lottery created with the organizer's seed_hash needs the seed it commits to.
+type drawLotteryRequest struct {
+	Seed string `json:"seed,omitempty"`
+}
+
+type enterLotteryRequest struct {
+	Quantity int `json:"quantity"`
+}
+
+// lotteryResponse reports the seed hash from creation and the seed once the
+// lottery is drawn, so anyone can check the commitment and reproduce the
+// ranking.
+type lotteryResponse struct {
+	ID                   string     `json:"id"`
+	EventID              string     `json:"event_id"`
+	ZoneID               string     `json:"zone_id"`
+	RegistrationOpensAt  time.Time  `json:"registration_opens_at"`
+	RegistrationClosesAt time.Time  `json:"registration_closes_at"`
+	ClaimWindowMinutes   int        `json:"claim_window_minutes"`
+	Status               string     `json:"status"`
+	SeedHash             string     `json:"seed_hash"`
+	Seed                 string     `json:"seed,omitempty"`
+	DrawnAt              *time.Time `json:"drawn_at,omitempty"`
+}
+
+func newLotteryResponse(l domain.Lottery) lotteryResponse {
+	return lotteryResponse{
+		ID:                   l.ID,
+		EventID:              l.EventID,
+		ZoneID:               l.ZoneID,
+		RegistrationOpensAt:  l.RegistrationOpensAt,
+		RegistrationClosesAt: l.RegistrationClosesAt,
+		ClaimWindowMinutes:   int(l.ClaimWindow / time.Minute),
+		Status:               string(l.Status),
+		SeedHash:             l.SeedHash,
+		Seed:                 l.PublishedSeed(),
+		DrawnAt:              l.DrawnAt,
+	}
+}
+
+type drawResponse struct {
+	Lottery lotteryResponse `json:"lottery"`
+	Ballots int             `json:"ballots"`
+	Offered int             `json:"offered"`
+}
+
+type ballotResponse struct {
+	ID             string     `json:"id"`
+	LotteryID      string     `json:"lottery_id"`
+	Quantity       int        `json:"quantity"`
+	Status         string     `json:"status"`
+	Rank           int        `json:"rank,omitempty"`
+	OfferExpiresAt *time.Time `json:"offer_expires_at,omitempty"`
+	HoldID         string     `json:"hold_id,omitempty"`
+}
+
+func newBallotResponse(b domain.Ballot) ballotResponse {
+	resp := ballotResponse{
+		ID:        b.ID,
+		LotteryID: b.LotteryID,
+		Quantity:  b.Quantity,
+		Status:    string(b.Status),
+		Rank:      b.Rank,
+		HoldID:    b.HoldID,
+	}
+	if b.Status == domain.BallotOffered {
+		resp.OfferExpiresAt = b.OfferExpiresAt
+	}
+	return resp
+}
+
+// parseAdminLotteryPath accepts /admin/lotteries/{id} and
+// /admin/lotteries/{id}/draw.
+func parseAdminLotteryPath(path string) (string, string, bool) {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	if len(parts) < 3 || parts[0] != "admin" || parts[1] != "lotteries" || parts[2] == "" {
+		return "", "", false
+	}
+	switch {
+	case len(parts) == 3:
+		return parts[2], "", true
+	case len(parts) == 4 && parts[3] == "draw":
+		return parts[2], "draw", true
+	}
+	return "", "", false
+}
+
+func parseLotteryEntryPath(path string) (string, bool) {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	if len(parts) != 3 || parts[0] != "lotteries" || parts[1] == "" || parts[2] != "entry" {
+		return "", false
+	}
+	return parts[1], true
+}
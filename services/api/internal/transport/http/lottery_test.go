@@ -0,0 +1,396 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cimillas/ultimate-ticket/services/api/internal/app"
+	"github.com/cimillas/ultimate-ticket/services/api/internal/domain"
+)
+
+func TestHandleAdminLotteries(t *testing.T) {
+	t.Parallel()
+
+	valid := `{"zone_id":"zone-1","registration_opens_at":"2025-01-01T10:00:00Z","registration_closes_at":"2025-01-02T10:00:00Z","claim_window_minutes":30}`
+
+	tests := []struct {
+		name           string
+		method         string
+		body           string
+		serviceErr     error
+		expectedStatus int
+		expectedSubstr string
+	}{
+		{
+			name:           "creates",
+			method:         http.MethodPost,
+			body:           valid,
+			expectedStatus: http.StatusCreated,
+			expectedSubstr: `"zone_id":"zone-1","registration_opens_at":"2025-01-01T10:00:00Z","registration_closes_at":"2025-01-02T10:00:00Z","claim_window_minutes":30,"status":"open","seed_hash":"server-hash"}`,
+		},
+		{
+			name:           "creates with the organizer's seed hash",
+			method:         http.MethodPost,
+			body:           `{"zone_id":"zone-1","registration_opens_at":"2025-01-01T10:00:00Z","registration_closes_at":"2025-01-02T10:00:00Z","claim_window_minutes":30,"seed_hash":"organizer-hash"}`,
+			expectedStatus: http.StatusCreated,
+			expectedSubstr: `"seed_hash":"organizer-hash"`,
+		},
+		{
+			name:           "invalid seed hash",
+			method:         http.MethodPost,
+			body:           valid,
+			serviceErr:     domain.ErrInvalidSeedHash,
+			expectedStatus: http.StatusBadRequest,
+			expectedSubstr: `"code":"invalid_seed_hash"`,
+		},
+		{
+			name:           "missing zone",
+			method:         http.MethodPost,
+			body:           `{"registration_opens_at":"2025-01-01T10:00:00Z","registration_closes_at":"2025-01-02T10:00:00Z"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedSubstr: `"code":"missing_required_field"`,
+		},
+		{
+			name:           "invalid window format",
+			method:         http.MethodPost,
+			body:           `{"zone_id":"zone-1","registration_opens_at":"tomorrow","registration_closes_at":"2025-01-02T10:00:00Z"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedSubstr: `"code":"invalid_lottery_window"`,
+		},
+		{
+			name:           "invalid window",
+			method:         http.MethodPost,
+			body:           valid,
+			serviceErr:     domain.ErrInvalidLotteryWindow,
+			expectedStatus: http.StatusBadRequest,
+			expectedSubstr: `"code":"invalid_lottery_window"`,
+		},
+		{
+			name:           "zone not found",
+			method:         http.MethodPost,
+			body:           valid,
+			serviceErr:     domain.ErrZoneNotFound,
+			expectedStatus: http.StatusNotFound,
+			expectedSubstr: `"code":"zone_not_found"`,
+		},
+		{
+			name:           "zone already has a lottery",
+			method:         http.MethodPost,
+			body:           valid,
+			serviceErr:     domain.ErrLotteryExists,
+			expectedStatus: http.StatusConflict,
+			expectedSubstr: `"code":"lottery_exists"`,
+		},
+		{
+			name:           "method not allowed",
+			method:         http.MethodGet,
+			expectedStatus: http.StatusMethodNotAllowed,
+			expectedSubstr: `"code":"method_not_allowed"`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			svc := &stubLotteryService{err: tt.serviceErr}
+			req := withAdminKey(httptest.NewRequest(tt.method, "/admin/lotteries", bytes.NewBufferString(tt.body)), "org-1")
+			rec := httptest.NewRecorder()
+
+			HandleAdminLotteries(svc).ServeHTTP(rec, req)
+
+			if rec.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d (%s)", tt.expectedStatus, rec.Code, rec.Body.String())
+			}
+			if !strings.Contains(rec.Body.String(), tt.expectedSubstr) {
+				t.Fatalf("expected response to contain %q, got %q", tt.expectedSubstr, rec.Body.String())
+			}
+			if tt.expectedStatus == http.StatusCreated && (svc.created.OrganizerID != "org-1" || svc.created.ClaimWindow != 30*time.Minute) {
+				t.Fatalf("unexpected input %+v", svc.created)
+			}
+			if strings.Contains(rec.Body.String(), "server-seed") {
+				t.Fatalf("expected the seed to stay secret until the draw, got %q", rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandleAdminLottery(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		body           string
+		serviceErr     error
+		expectedStatus int
+		expectedSubstr string
+		expectedSeed   string
+	}{
+		{
+			name:           "gets",
+			method:         http.MethodGet,
+			path:           "/admin/lotteries/lottery-1",
+			expectedStatus: http.StatusOK,
+			expectedSubstr: `"id":"lottery-1","event_id":"","zone_id":"","registration_opens_at":"0001-01-01T00:00:00Z","registration_closes_at":"0001-01-01T00:00:00Z","claim_window_minutes":0,"status":"open","seed_hash":"hash-1"}`,
+		},
+		{
+			name:           "draws with seed",
+			method:         http.MethodPost,
+			path:           "/admin/lotteries/lottery-1/draw",
+			body:           `{"seed":"public-seed"}`,
+			expectedStatus: http.StatusOK,
+			expectedSubstr: `"seed_hash":"hash-1","seed":"public-seed","drawn_at":"2025-01-02T10:00:00Z"},"ballots":6,"offered":3`,
+			expectedSeed:   "public-seed",
+		},
+		{
+			name:           "seed does not match the commitment",
+			method:         http.MethodPost,
+			path:           "/admin/lotteries/lottery-1/draw",
+			body:           `{"seed":"other-seed"}`,
+			serviceErr:     domain.ErrLotterySeedMismatch,
+			expectedStatus: http.StatusBadRequest,
+			expectedSubstr: `"code":"lottery_seed_mismatch"`,
+			expectedSeed:   "other-seed",
+		},
+		{
+			name:           "draws without body",
+			method:         http.MethodPost,
+			path:           "/admin/lotteries/lottery-1/draw",
+			expectedStatus: http.StatusOK,
+			expectedSubstr: `"status":"drawn"`,
+		},
+		{
+			name:           "registration still open",
+			method:         http.MethodPost,
+			path:           "/admin/lotteries/lottery-1/draw",
+			serviceErr:     domain.ErrRegistrationStillOpen,
+			expectedStatus: http.StatusConflict,
+			expectedSubstr: `"code":"registration_still_open"`,
+		},
+		{
+			name:           "already drawn",
+			method:         http.MethodPost,
+			path:           "/admin/lotteries/lottery-1/draw",
+			serviceErr:     domain.ErrLotteryAlreadyDrawn,
+			expectedStatus: http.StatusConflict,
+			expectedSubstr: `"code":"lottery_already_drawn"`,
+		},
+		{
+			name:           "not found",
+			method:         http.MethodGet,
+			path:           "/admin/lotteries/lottery-2",
+			serviceErr:     domain.ErrLotteryNotFound,
+			expectedStatus: http.StatusNotFound,
+			expectedSubstr: `"code":"lottery_not_found"`,
+		},
+		{
+			name:           "draw needs post",
+			method:         http.MethodGet,
+			path:           "/admin/lotteries/lottery-1/draw",
+			expectedStatus: http.StatusMethodNotAllowed,
+			expectedSubstr: `"code":"method_not_allowed"`,
+		},
+		{
+			name:           "unknown action",
+			method:         http.MethodPost,
+			path:           "/admin/lotteries/lottery-1/cancel",
+			expectedStatus: http.StatusNotFound,
+			expectedSubstr: `"code":"not_found"`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			svc := &stubLotteryService{err: tt.serviceErr}
+			req := withAdminKey(httptest.NewRequest(tt.method, tt.path, bytes.NewBufferString(tt.body)), "org-1")
+			rec := httptest.NewRecorder()
+
+			HandleAdminLottery(svc).ServeHTTP(rec, req)
+
+			if rec.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d (%s)", tt.expectedStatus, rec.Code, rec.Body.String())
+			}
+			if !strings.Contains(rec.Body.String(), tt.expectedSubstr) {
+				t.Fatalf("expected response to contain %q, got %q", tt.expectedSubstr, rec.Body.String())
+			}
+			if svc.seed != tt.expectedSeed {
+				t.Fatalf("expected seed %q, got %q", tt.expectedSeed, svc.seed)
+			}
+		})
+	}
+}
+
+func TestHandleLotteryEntry(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		body           string
+		anonymous      bool
+		serviceErr     error
+		expectedStatus int
+		expectedSubstr string
+	}{
+		{
+			name:           "enters",
+			method:         http.MethodPost,
+			path:           "/lotteries/lottery-1/entry",
+			body:           `{"quantity":2}`,
+			expectedStatus: http.StatusCreated,
+			expectedSubstr: `"quantity":2,"status":"entered"`,
+		},
+		{
+			name:           "shows an offer",
+			method:         http.MethodGet,
+			path:           "/lotteries/lottery-1/entry",
+			expectedStatus: http.StatusOK,
+			expectedSubstr: `"status":"offered","rank":4,"offer_expires_at":"2025-01-02T10:30:00Z"`,
+		},
+		{
+			name:           "registration closed",
+			method:         http.MethodPost,
+			path:           "/lotteries/lottery-1/entry",
+			body:           `{"quantity":2}`,
+			serviceErr:     domain.ErrRegistrationClosed,
+			expectedStatus: http.StatusConflict,
+			expectedSubstr: `"code":"registration_closed"`,
+		},
+		{
+			name:           "already entered",
+			method:         http.MethodPost,
+			path:           "/lotteries/lottery-1/entry",
+			body:           `{"quantity":2}`,
+			serviceErr:     domain.ErrBallotExists,
+			expectedStatus: http.StatusConflict,
+			expectedSubstr: `"code":"ballot_exists"`,
+		},
+		{
+			name:           "zone quantity rules",
+			method:         http.MethodPost,
+			path:           "/lotteries/lottery-1/entry",
+			body:           `{"quantity":9}`,
+			serviceErr:     &domain.QuantityRuleError{Err: domain.ErrQuantityAboveMaximum, Min: 1, Max: 4, Step: 1},
+			expectedStatus: http.StatusBadRequest,
+			expectedSubstr: `"code":"quantity_above_maximum"`,
+		},
+		{
+			name:           "not entered",
+			method:         http.MethodGet,
+			path:           "/lotteries/lottery-1/entry",
+			serviceErr:     domain.ErrBallotNotFound,
+			expectedStatus: http.StatusNotFound,
+			expectedSubstr: `"code":"ballot_not_found"`,
+		},
+		{
+			name:           "anonymous",
+			method:         http.MethodPost,
+			path:           "/lotteries/lottery-1/entry",
+			body:           `{"quantity":2}`,
+			anonymous:      true,
+			expectedStatus: http.StatusUnauthorized,
+			expectedSubstr: `"code":"unauthorized"`,
+		},
+		{
+			name:           "unknown path",
+			method:         http.MethodGet,
+			path:           "/lotteries/lottery-1",
+			expectedStatus: http.StatusNotFound,
+			expectedSubstr: `"code":"not_found"`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			svc := &stubLotteryService{err: tt.serviceErr}
+			req := httptest.NewRequest(tt.method, tt.path, bytes.NewBufferString(tt.body))
+			if !tt.anonymous {
+				req = req.WithContext(WithCustomer(req.Context(), domain.Customer{ID: "cust-1"}))
+			}
+			rec := httptest.NewRecorder()
+
+			HandleLotteryEntry(svc).ServeHTTP(rec, req)
+
+			if rec.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d (%s)", tt.expectedStatus, rec.Code, rec.Body.String())
+			}
+			if !strings.Contains(rec.Body.String(), tt.expectedSubstr) {
+				t.Fatalf("expected response to contain %q, got %q", tt.expectedSubstr, rec.Body.String())
+			}
+		})
+	}
+}
+
+type stubLotteryService struct {
+	err     error
+	created app.CreateLotteryInput
+	seed    string
+}
+
+func (s *stubLotteryService) CreateLottery(_ context.Context, in app.CreateLotteryInput) (domain.Lottery, error) {
+	s.created = in
+	if s.err != nil {
+		return domain.Lottery{}, s.err
+	}
+	lottery := domain.Lottery{
+		ID:                   "lottery-1",
+		EventID:              "event-1",
+		ZoneID:               in.ZoneID,
+		RegistrationOpensAt:  in.RegistrationOpensAt,
+		RegistrationClosesAt: in.RegistrationClosesAt,
+		ClaimWindow:          in.ClaimWindow,
+		Status:               domain.LotteryOpen,
+		SeedHash:             in.SeedHash,
+	}
+	if lottery.SeedHash == "" {
+		lottery.Seed, lottery.SeedHash = "server-seed", "server-hash"
+	}
+	return lottery, nil
+}
+
+func (s *stubLotteryService) GetLottery(_ context.Context, _, lotteryID string) (domain.Lottery, error) {
+	if s.err != nil {
+		return domain.Lottery{}, s.err
+	}
+	return domain.Lottery{ID: lotteryID, Status: domain.LotteryOpen, SeedHash: "hash-1", Seed: "server-seed"}, nil
+}
+
+func (s *stubLotteryService) Draw(_ context.Context, _, lotteryID, seed string) (app.DrawResult, error) {
+	s.seed = seed
+	if s.err != nil {
+		return app.DrawResult{}, s.err
+	}
+	drawnAt := time.Date(2025, 1, 2, 10, 0, 0, 0, time.UTC)
+	return app.DrawResult{
+		Lottery: domain.Lottery{ID: lotteryID, Status: domain.LotteryDrawn, SeedHash: "hash-1", Seed: seed, DrawnAt: &drawnAt},
+		Ballots: 6,
+		Offered: 3,
+	}, nil
+}
+
+func (s *stubLotteryService) Enter(_ context.Context, lotteryID, _ string, quantity int) (domain.Ballot, error) {
+	if s.err != nil {
+		return domain.Ballot{}, s.err
+	}
+	return domain.Ballot{ID: "ballot-1", LotteryID: lotteryID, Quantity: quantity, Status: domain.BallotEntered}, nil
+}
+
+func (s *stubLotteryService) GetBallot(_ context.Context, lotteryID, _ string) (domain.Ballot, error) {
+	if s.err != nil {
+		return domain.Ballot{}, s.err
+	}
+	expiresAt := time.Date(2025, 1, 2, 10, 30, 0, 0, time.UTC)
+	return domain.Ballot{ID: "ballot-1", LotteryID: lotteryID, Quantity: 2, Status: domain.BallotOffered, Rank: 4, OfferExpiresAt: &expiresAt}, nil
+}
@@ -0,0 +1,58 @@
+package http
+
+import (
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/cimillas/ultimate-ticket/services/api/internal/metrics"
+)
+
+// MetricsWriter writes metrics in the Prometheus text format.
+type MetricsWriter interface {
+	WriteText(w io.Writer) error
+}
+
+// RequestObserver records served requests.
+type RequestObserver interface {
+	ObserveRequest(route, method string, status int, took time.Duration)
+}
+
+// HandleMetrics returns an HTTP handler for GET /metrics.
+func HandleMetrics(m MetricsWriter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			writeError(w, http.StatusMethodNotAllowed, codeMethodNotAllowed, "method not allowed")
+			return
+		}
+		w.Header().Set("Content-Type", metrics.ContentType)
+		w.Header().Set("Cache-Control", "no-store")
+		_ = m.WriteText(w)
+	}
+}
+
+// Instrument records every request with the routes pattern that matches it,
+// so paths carrying ids do not each get their own series.
+func Instrument(obs RequestObserver, routes *http.ServeMux, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+
+		_, route := routes.Handler(r)
+		if route == "" {
+			route = "unmatched"
+		}
+		obs.ObserveRequest(route, metricMethod(r.Method), rec.status, time.Since(start))
+	})
+}
+
+// metricMethod folds unknown methods into one label value.
+func metricMethod(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut,
+		http.MethodPatch, http.MethodDelete, http.MethodOptions:
+		return method
+	}
+	return "OTHER"
+}
@@ -0,0 +1,87 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cimillas/ultimate-ticket/services/api/internal/metrics"
+)
+
+type recordedRequest struct {
+	route, method string
+	status        int
+}
+
+type fakeRequestObserver struct {
+	mu       sync.Mutex
+	requests []recordedRequest
+}
+
+func (f *fakeRequestObserver) ObserveRequest(route, method string, status int, _ time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.requests = append(f.requests, recordedRequest{route, method, status})
+}
+
+func TestInstrument(t *testing.T) {
+	t.Parallel()
+
+	mux := http.NewServeMux()
+	mux.Handle("/holds/", http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	}))
+	mux.Handle("/", NotFoundHandler())
+	obs := &fakeRequestObserver{}
+	h := Instrument(obs, mux, mux)
+
+	for _, req := range []*http.Request{
+		httptest.NewRequest(http.MethodPost, "/holds/3f0c1d5e/confirm", nil),
+		httptest.NewRequest(http.MethodPost, "/holds/9a7b2c4d/confirm", nil),
+		httptest.NewRequest("BREW", "/nowhere", nil),
+	} {
+		h.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	want := []recordedRequest{
+		{"/holds/", http.MethodPost, http.StatusCreated},
+		{"/holds/", http.MethodPost, http.StatusCreated},
+		{"/", "OTHER", http.StatusNotFound},
+	}
+	if len(obs.requests) != len(want) {
+		t.Fatalf("expected %d requests, got %+v", len(want), obs.requests)
+	}
+	for i := range want {
+		if obs.requests[i] != want[i] {
+			t.Fatalf("request %d: expected %+v, got %+v", i, want[i], obs.requests[i])
+		}
+	}
+}
+
+func TestHandleMetrics(t *testing.T) {
+	t.Parallel()
+
+	reg := metrics.NewRegistry()
+	metrics.NewHTTP(reg).ObserveRequest("/holds", http.MethodPost, http.StatusCreated, 30*time.Millisecond)
+
+	rec := httptest.NewRecorder()
+	HandleMetrics(reg).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	if got := rec.Header().Get("Content-Type"); got != metrics.ContentType {
+		t.Fatalf("expected content type %q, got %q", metrics.ContentType, got)
+	}
+	if want := `http_requests_total{route="/holds",method="POST",status="201"} 1`; !strings.Contains(rec.Body.String(), want) {
+		t.Fatalf("expected %q in:\n%s", want, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	HandleMetrics(reg).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/metrics", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("expected 405, got %d", rec.Code)
+	}
+}
@@ -1,31 +1,182 @@
 package http
 
 import (
-	"log"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"net"
 	"net/http"
+	"strings"
+	"sync"
 	"time"
+
+	"github.com/cimillas/ultimate-ticket/services/api/internal/tracing"
 )
 
-// RequestLogger logs basic request details and latency.
-func RequestLogger(next http.Handler, logger *log.Logger) http.Handler {
+// RequestIDHeader lets callers correlate their requests with error responses,
+// logs and audit entries.
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLen bounds caller-supplied request IDs kept in logs and the
+// audit log.
+const maxRequestIDLen = 128
+
+type requestIDKey struct{}
+
+// RequestID gives every request an id: the caller's X-Request-ID when it is
+// usable, a random one otherwise. The id is echoed in the response header,
+// where writeError also picks it up for error bodies.
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := strings.TrimSpace(r.Header.Get(RequestIDHeader))
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+		w.Header().Set(RequestIDHeader, id)
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), requestIDKey{}, id)))
+	})
+}
+
+// validRequestID accepts short ids of printable ASCII, so callers cannot
+// smuggle control characters into logs.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLen {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// requestID returns the id set by RequestID, or the caller's header when the
+// middleware did not run.
+func requestID(r *http.Request) string {
+	if id, ok := r.Context().Value(requestIDKey{}).(string); ok {
+		return id
+	}
+	id := strings.TrimSpace(r.Header.Get(RequestIDHeader))
+	if len(id) > maxRequestIDLen {
+		id = id[:maxRequestIDLen]
+	}
+	return id
+}
+
+type requestLogKey struct{}
+
+// requestLog collects fields handlers add to their request's log line.
+type requestLog struct {
+	mu    sync.Mutex
+	attrs []slog.Attr
+	err   error
+}
+
+// logAttrs adds fields, such as the ids a handler works on, to the request's
+// log line.
+func logAttrs(r *http.Request, attrs ...slog.Attr) {
+	if entry, ok := r.Context().Value(requestLogKey{}).(*requestLog); ok {
+		entry.mu.Lock()
+		entry.attrs = append(entry.attrs, attrs...)
+		entry.mu.Unlock()
+	}
+}
+
+// logError records the error behind a failed request for its log line.
+func logError(r *http.Request, err error) {
+	if entry, ok := r.Context().Value(requestLogKey{}).(*requestLog); ok {
+		entry.mu.Lock()
+		entry.err = err
+		entry.mu.Unlock()
+	}
+}
+
+// RequestLogger logs one structured line per request with its status,
+// latency, request and trace ids and any fields the handler added. Requests
+// that end in a 5xx are logged at error level with the underlying error.
+func RequestLogger(next http.Handler, logger *slog.Logger) http.Handler {
 	if logger == nil {
-		logger = log.Default()
+		logger = slog.Default()
 	}
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
+		entry := &requestLog{}
 		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
-		next.ServeHTTP(rec, r)
-
-		logger.Printf(
-			"request method=%s path=%s status=%d duration=%s",
-			r.Method,
-			r.URL.Path,
-			rec.status,
-			time.Since(start),
-		)
+		next.ServeHTTP(rec, r.WithContext(context.WithValue(r.Context(), requestLogKey{}, entry)))
+
+		attrs := []slog.Attr{
+			slog.String("method", r.Method),
+			slog.String("path", r.URL.Path),
+			slog.Int("status", rec.status),
+			slog.Duration("duration", time.Since(start)),
+		}
+		if id := requestID(r); id != "" {
+			attrs = append(attrs, slog.String("request_id", id))
+		}
+		if sc := tracing.SpanFromContext(r.Context()).Context(); sc.IsValid() {
+			attrs = append(attrs, slog.String("trace_id", sc.TraceID.String()))
+		}
+		entry.mu.Lock()
+		attrs = append(attrs, entry.attrs...)
+		if entry.err != nil {
+			attrs = append(attrs, slog.String("error", entry.err.Error()))
+		}
+		entry.mu.Unlock()
+
+		level := slog.LevelInfo
+		if rec.status >= http.StatusInternalServerError {
+			level = slog.LevelError
+		}
+		logger.LogAttrs(r.Context(), level, "request", attrs...)
+	})
+}
+
+type clientIPKey struct{}
+
+// ClientIP records the client's address for rate limiting and risk scoring.
+// With trustForwardedFor it takes the last X-Forwarded-For entry, the one
+// added by the proxy in front of the API; only enable that behind a proxy
+// that sets the header, or clients can pick their own address.
+func ClientIP(trustForwardedFor bool, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ip := remoteIP(r)
+		if trustForwardedFor {
+			if forwarded := r.Header.Values("X-Forwarded-For"); len(forwarded) > 0 {
+				hops := strings.Split(forwarded[len(forwarded)-1], ",")
+				if hop := strings.TrimSpace(hops[len(hops)-1]); hop != "" {
+					ip = hop
+				}
+			}
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), clientIPKey{}, ip)))
 	})
 }
 
+// clientIP returns the address recorded by ClientIP, or the peer address when
+// the middleware did not run.
+func clientIP(r *http.Request) string {
+	if ip, ok := r.Context().Value(clientIPKey{}).(string); ok {
+		return ip
+	}
+	return remoteIP(r)
+}
+
+func remoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
 type statusRecorder struct {
 	http.ResponseWriter
 	status int
@@ -2,7 +2,9 @@ package http
 
 import (
 	"bytes"
-	"log"
+	"encoding/json"
+	"errors"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -13,7 +15,7 @@ func TestRequestLogger_LogsStatusAndPath(t *testing.T) {
 	t.Parallel()
 
 	buf := &bytes.Buffer{}
-	logger := log.New(buf, "", 0)
+	logger := slog.New(slog.NewTextHandler(buf, nil))
 
 	handler := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
 		w.WriteHeader(http.StatusCreated)
@@ -40,7 +42,7 @@ func TestRequestLogger_DefaultsTo200(t *testing.T) {
 	t.Parallel()
 
 	buf := &bytes.Buffer{}
-	logger := log.New(buf, "", 0)
+	logger := slog.New(slog.NewTextHandler(buf, nil))
 
 	handler := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
 		_, _ = w.Write([]byte("ok"))
@@ -56,3 +58,84 @@ func TestRequestLogger_DefaultsTo200(t *testing.T) {
 		t.Fatalf("expected default status 200 in log, got %q", out)
 	}
 }
+
+func TestRequestID(t *testing.T) {
+	t.Parallel()
+
+	var seen string
+	handler := RequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = requestID(r)
+		writeError(w, http.StatusNotFound, codeNotFound, "not found")
+	}))
+
+	tests := []struct {
+		name   string
+		header string
+		keep   bool
+	}{
+		{name: "honours caller id", header: "req-123", keep: true},
+		{name: "generates when missing", header: ""},
+		{name: "replaces ids with spaces", header: "req 123"},
+		{name: "replaces overlong ids", header: strings.Repeat("a", maxRequestIDLen+1)},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/nowhere", nil)
+		if tt.header != "" {
+			req.Header.Set(RequestIDHeader, tt.header)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		got := rec.Header().Get(RequestIDHeader)
+		if tt.keep && got != tt.header {
+			t.Fatalf("%s: expected %q, got %q", tt.name, tt.header, got)
+		}
+		if !tt.keep && (len(got) != 32 || got == tt.header) {
+			t.Fatalf("%s: expected a generated id, got %q", tt.name, got)
+		}
+		if seen != got {
+			t.Fatalf("%s: handler saw %q, response has %q", tt.name, seen, got)
+		}
+		if want := `"request_id":"` + got + `"`; !strings.Contains(rec.Body.String(), want) {
+			t.Fatalf("%s: expected %s in error body %s", tt.name, want, rec.Body.String())
+		}
+	}
+}
+
+func TestRequestLogger_LogsServerErrors(t *testing.T) {
+	t.Parallel()
+
+	buf := &bytes.Buffer{}
+	logger := slog.New(slog.NewJSONHandler(buf, nil))
+	handler := RequestID(RequestLogger(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		logAttrs(r, slog.String("event_id", "event-1"), slog.String("zone_id", "zone-1"))
+		writeInternalError(w, r, errors.New("connection refused"))
+	}), logger))
+
+	req := httptest.NewRequest(http.MethodPost, "/holds", nil)
+	req.Header.Set(RequestIDHeader, "req-123")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if strings.Contains(rec.Body.String(), "connection refused") {
+		t.Fatalf("expected the error to stay out of the response, got %s", rec.Body.String())
+	}
+	var line map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &line); err != nil {
+		t.Fatalf("expected one JSON log line, got %q: %v", buf.String(), err)
+	}
+	want := map[string]any{
+		"level":      "ERROR",
+		"msg":        "request",
+		"status":     float64(500),
+		"request_id": "req-123",
+		"event_id":   "event-1",
+		"zone_id":    "zone-1",
+		"error":      "connection refused",
+	}
+	for key, value := range want {
+		if line[key] != value {
+			t.Fatalf("%s: expected %v, got %v", key, value, line[key])
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/cimillas/ultimate-ticket/services/api/internal/domain"
+)
+
+// AdminNotificationService is the minimal interface needed for admin notification endpoints.
+type AdminNotificationService interface {
+	ListNotifications(ctx context.Context, organizerID string, status domain.NotificationStatus) ([]domain.Notification, error)
+	RetryNotification(ctx context.Context, organizerID, id string) (domain.Notification, error)
+}
+
+// HandleAdminNotifications returns an HTTP handler for GET /admin/notifications[?status=pending|sent|failed].
+func HandleAdminNotifications(svc AdminNotificationService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			writeError(w, http.StatusMethodNotAllowed, codeMethodNotAllowed, "method not allowed")
+			return
+		}
+		status := domain.NotificationStatus(r.URL.Query().Get("status"))
+		switch status {
+		case "", domain.NotificationPending, domain.NotificationSent, domain.NotificationFailed:
+		default:
+			writeError(w, http.StatusBadRequest, codeInvalidNotificationStatus, "invalid notification status")
+			return
+		}
+		organizerID, ok := adminOrganizer(w, r)
+		if !ok {
+			return
+		}
+
+		notifications, err := svc.ListNotifications(r.Context(), organizerID, status)
+		if err != nil {
+			writeInternalError(w, r, err)
+			return
+		}
+		resp := make([]notificationResponse, 0, len(notifications))
+		for _, n := range notifications {
+			resp = append(resp, newNotificationResponse(n))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(resp)
+	}
+}
+
+// HandleAdminNotification returns an HTTP handler for POST /admin/notifications/{id}/retry.
+func HandleAdminNotification(svc AdminNotificationService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/admin/notifications"), "/"), "/")
+		if len(parts) != 2 || parts[0] == "" || parts[1] != "retry" {
+			writeError(w, http.StatusNotFound, codeNotFound, "not found")
+			return
+		}
+		if r.Method != http.MethodPost {
+			writeError(w, http.StatusMethodNotAllowed, codeMethodNotAllowed, "method not allowed")
+			return
+		}
+
+		organizerID, ok := adminOrganizer(w, r)
+		if !ok {
+			return
+		}
+
+		n, err := svc.RetryNotification(r.Context(), organizerID, parts[0])
+		if err != nil {
+			switch err {
+			case domain.ErrInvalidID:
+				writeError(w, http.StatusNotFound, codeInvalidID, err.Error())
+			case domain.ErrNotificationNotFound:
+				writeError(w, http.StatusNotFound, codeNotificationNotFound, err.Error())
+			case domain.ErrNotificationNotFailed:
+				writeError(w, http.StatusConflict, codeNotificationNotFailed, err.Error())
+			default:
+				writeInternalError(w, r, err)
+			}
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		_ = json.NewEncoder(w).Encode(newNotificationResponse(n))
+	}
+}
+
+// notificationResponse omits the rendered bodies.
+type notificationResponse struct {
+	ID            string     `json:"id"`
+	Kind          string     `json:"kind"`
+	Recipient     string     `json:"recipient"`
+	Subject       string     `json:"subject"`
+	Status        string     `json:"status"`
+	Attempts      int        `json:"attempts"`
+	NextAttemptAt time.Time  `json:"next_attempt_at"`
+	LastError     string     `json:"last_error,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	SentAt        *time.Time `json:"sent_at,omitempty"`
+}
+
+func newNotificationResponse(n domain.Notification) notificationResponse {
+	return notificationResponse{
+		ID:            n.ID,
+		Kind:          string(n.Kind),
+		Recipient:     n.Recipient,
+		Subject:       n.Subject,
+		Status:        string(n.Status),
+		Attempts:      n.Attempts,
+		NextAttemptAt: n.NextAttemptAt,
+		LastError:     n.LastError,
+		CreatedAt:     n.CreatedAt,
+		SentAt:        n.SentAt,
+	}
+}
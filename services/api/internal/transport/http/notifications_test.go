@@ -0,0 +1,124 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cimillas/ultimate-ticket/services/api/internal/domain"
+)
+
+func TestHandleAdminNotifications(t *testing.T) {
+	t.Parallel()
+
+	n := domain.Notification{
+		ID:        "n-1",
+		Kind:      domain.NotificationOrderConfirmation,
+		Recipient: "fan@example.com",
+		Subject:   "Your tickets",
+		HTMLBody:  "<p>secret body</p>",
+		Status:    domain.NotificationFailed,
+		CreatedAt: time.Date(2025, 1, 4, 8, 0, 0, 0, time.UTC),
+	}
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		serviceErr     error
+		expectedStatus int
+		expectedSubstr string
+	}{
+		{
+			name:           "list",
+			method:         http.MethodGet,
+			path:           "/admin/notifications?status=failed",
+			expectedStatus: http.StatusOK,
+			expectedSubstr: `"recipient":"fan@example.com"`,
+		},
+		{
+			name:           "list with invalid status",
+			method:         http.MethodGet,
+			path:           "/admin/notifications?status=lost",
+			expectedStatus: http.StatusBadRequest,
+			expectedSubstr: `"code":"invalid_notification_status"`,
+		},
+		{
+			name:           "retry",
+			method:         http.MethodPost,
+			path:           "/admin/notifications/n-1/retry",
+			expectedStatus: http.StatusAccepted,
+			expectedSubstr: `"id":"n-1"`,
+		},
+		{
+			name:           "retry not failed",
+			method:         http.MethodPost,
+			path:           "/admin/notifications/n-1/retry",
+			serviceErr:     domain.ErrNotificationNotFailed,
+			expectedStatus: http.StatusConflict,
+			expectedSubstr: `"code":"notification_not_failed"`,
+		},
+		{
+			name:           "retry missing",
+			method:         http.MethodPost,
+			path:           "/admin/notifications/n-2/retry",
+			serviceErr:     domain.ErrNotificationNotFound,
+			expectedStatus: http.StatusNotFound,
+			expectedSubstr: `"code":"notification_not_found"`,
+		},
+		{
+			name:           "retry wrong method",
+			method:         http.MethodGet,
+			path:           "/admin/notifications/n-1/retry",
+			expectedStatus: http.StatusMethodNotAllowed,
+		},
+		{
+			name:           "unknown path",
+			method:         http.MethodPost,
+			path:           "/admin/notifications/n-1",
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			svc := &stubAdminNotificationService{notification: n, err: tt.serviceErr}
+			mux := http.NewServeMux()
+			mux.Handle("/admin/notifications", HandleAdminNotifications(svc))
+			mux.Handle("/admin/notifications/", HandleAdminNotification(svc))
+
+			req := withAdminKey(httptest.NewRequest(tt.method, tt.path, nil), "org-1")
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, req)
+
+			if rec.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d", tt.expectedStatus, rec.Code)
+			}
+			body := rec.Body.String()
+			if tt.expectedSubstr != "" && !strings.Contains(body, tt.expectedSubstr) {
+				t.Fatalf("expected response to contain %q, got %q", tt.expectedSubstr, body)
+			}
+			if strings.Contains(body, "secret body") {
+				t.Fatalf("expected rendered bodies to be omitted, got %q", body)
+			}
+		})
+	}
+}
+
+type stubAdminNotificationService struct {
+	notification domain.Notification
+	err          error
+}
+
+func (s *stubAdminNotificationService) ListNotifications(_ context.Context, _ string, _ domain.NotificationStatus) ([]domain.Notification, error) {
+	return []domain.Notification{s.notification}, s.err
+}
+
+func (s *stubAdminNotificationService) RetryNotification(_ context.Context, _, _ string) (domain.Notification, error) {
+	return s.notification, s.err
+}
@@ -0,0 +1,96 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/cimillas/ultimate-ticket/services/api/internal/domain"
+)
+
+// AdminOrderService is the minimal interface needed for admin order endpoints.
+type AdminOrderService interface {
+	CancelOrder(ctx context.Context, organizerID, orderID string) (domain.Order, error)
+	FulfillOrder(ctx context.Context, organizerID, orderID string) (domain.Order, error)
+	MarkOrderFailed(ctx context.Context, organizerID, orderID string) (domain.Order, error)
+}
+
+// HandleAdminOrder returns an HTTP handler for
+// POST /admin/orders/{id}/cancel|fulfill|fail.
+func HandleAdminOrder(svc AdminOrderService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/admin/orders"), "/"), "/")
+		if len(parts) != 2 || parts[0] == "" {
+			writeError(w, http.StatusNotFound, codeNotFound, "not found")
+			return
+		}
+		var transition func(ctx context.Context, organizerID, orderID string) (domain.Order, error)
+		switch parts[1] {
+		case "cancel":
+			transition = svc.CancelOrder
+		case "fulfill":
+			transition = svc.FulfillOrder
+		case "fail":
+			transition = svc.MarkOrderFailed
+		default:
+			writeError(w, http.StatusNotFound, codeNotFound, "not found")
+			return
+		}
+		if r.Method != http.MethodPost {
+			writeError(w, http.StatusMethodNotAllowed, codeMethodNotAllowed, "method not allowed")
+			return
+		}
+
+		organizerID, ok := adminOrganizer(w, r)
+		if !ok {
+			return
+		}
+
+		order, err := transition(r.Context(), organizerID, parts[0])
+		if err != nil {
+			switch err {
+			case domain.ErrInvalidID:
+				writeError(w, http.StatusNotFound, codeInvalidID, err.Error())
+			case domain.ErrOrderNotFound:
+				writeError(w, http.StatusNotFound, codeOrderNotFound, err.Error())
+			case domain.ErrInvalidOrderTransition:
+				writeError(w, http.StatusConflict, codeInvalidOrderTransition, err.Error())
+			default:
+				writeInternalError(w, r, err)
+			}
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(newAdminOrderResponse(order))
+	}
+}
+
+type adminOrderResponse struct {
+	ID          string     `json:"id"`
+	HoldID      string     `json:"hold_id"`
+	Status      string     `json:"status"`
+	CustomerID  string     `json:"customer_id,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	PaidAt      *time.Time `json:"paid_at,omitempty"`
+	FulfilledAt *time.Time `json:"fulfilled_at,omitempty"`
+	FailedAt    *time.Time `json:"failed_at,omitempty"`
+	CancelledAt *time.Time `json:"cancelled_at,omitempty"`
+}
+
+func newAdminOrderResponse(o domain.Order) adminOrderResponse {
+	return adminOrderResponse{
+		ID:          o.ID,
+		HoldID:      o.HoldID,
+		Status:      string(o.Status),
+		CustomerID:  o.CustomerID,
+		CreatedAt:   o.CreatedAt,
+		UpdatedAt:   o.UpdatedAt,
+		PaidAt:      o.PaidAt,
+		FulfilledAt: o.FulfilledAt,
+		FailedAt:    o.FailedAt,
+		CancelledAt: o.CancelledAt,
+	}
+}
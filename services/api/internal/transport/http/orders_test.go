@@ -0,0 +1,125 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/cimillas/ultimate-ticket/services/api/internal/domain"
+)
+
+func TestHandleAdminOrder(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		serviceErr     error
+		expectedStatus int
+		expectedSubstr string
+		expectedCall   string
+	}{
+		{
+			name:           "cancel",
+			method:         http.MethodPost,
+			path:           "/admin/orders/order-1/cancel",
+			expectedStatus: http.StatusOK,
+			expectedSubstr: `"id":"order-1"`,
+			expectedCall:   "cancel:org-1:order-1",
+		},
+		{
+			name:           "fulfill",
+			method:         http.MethodPost,
+			path:           "/admin/orders/order-1/fulfill",
+			expectedStatus: http.StatusOK,
+			expectedCall:   "fulfill:org-1:order-1",
+		},
+		{
+			name:           "fail",
+			method:         http.MethodPost,
+			path:           "/admin/orders/order-1/fail",
+			expectedStatus: http.StatusOK,
+			expectedCall:   "fail:org-1:order-1",
+		},
+		{
+			name:           "invalid transition",
+			method:         http.MethodPost,
+			path:           "/admin/orders/order-1/fulfill",
+			serviceErr:     domain.ErrInvalidOrderTransition,
+			expectedStatus: http.StatusConflict,
+			expectedSubstr: `"code":"invalid_order_transition"`,
+		},
+		{
+			name:           "missing order",
+			method:         http.MethodPost,
+			path:           "/admin/orders/order-2/cancel",
+			serviceErr:     domain.ErrOrderNotFound,
+			expectedStatus: http.StatusNotFound,
+			expectedSubstr: `"code":"order_not_found"`,
+		},
+		{
+			name:           "wrong method",
+			method:         http.MethodGet,
+			path:           "/admin/orders/order-1/cancel",
+			expectedStatus: http.StatusMethodNotAllowed,
+		},
+		{
+			name:           "unknown action",
+			method:         http.MethodPost,
+			path:           "/admin/orders/order-1/refund",
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			svc := &stubAdminOrderService{err: tt.serviceErr}
+			mux := http.NewServeMux()
+			mux.Handle("/admin/orders/", HandleAdminOrder(svc))
+
+			req := withAdminKey(httptest.NewRequest(tt.method, tt.path, nil), "org-1")
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, req)
+
+			if rec.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d", tt.expectedStatus, rec.Code)
+			}
+			if tt.expectedSubstr != "" && !strings.Contains(rec.Body.String(), tt.expectedSubstr) {
+				t.Fatalf("expected response to contain %q, got %q", tt.expectedSubstr, rec.Body.String())
+			}
+			if tt.expectedCall != "" && svc.call != tt.expectedCall {
+				t.Fatalf("expected call %q, got %q", tt.expectedCall, svc.call)
+			}
+		})
+	}
+}
+
+type stubAdminOrderService struct {
+	err  error
+	call string
+}
+
+func (s *stubAdminOrderService) transition(action, organizerID, orderID string) (domain.Order, error) {
+	s.call = action + ":" + organizerID + ":" + orderID
+	if s.err != nil {
+		return domain.Order{}, s.err
+	}
+	return domain.Order{ID: orderID, HoldID: "hold-1", OrganizerID: organizerID}, nil
+}
+
+func (s *stubAdminOrderService) CancelOrder(_ context.Context, organizerID, orderID string) (domain.Order, error) {
+	return s.transition("cancel", organizerID, orderID)
+}
+
+func (s *stubAdminOrderService) FulfillOrder(_ context.Context, organizerID, orderID string) (domain.Order, error) {
+	return s.transition("fulfill", organizerID, orderID)
+}
+
+func (s *stubAdminOrderService) MarkOrderFailed(_ context.Context, organizerID, orderID string) (domain.Order, error) {
+	return s.transition("fail", organizerID, orderID)
+}
@@ -0,0 +1,98 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/cimillas/ultimate-ticket/services/api/internal/app"
+	"github.com/cimillas/ultimate-ticket/services/api/internal/domain"
+	"github.com/cimillas/ultimate-ticket/services/api/internal/webhook"
+)
+
+const maxWebhookBodyBytes = 1 << 20
+
+// PaymentEventApplier is the minimal interface needed to ingest payment webhooks.
+type PaymentEventApplier interface {
+	Apply(ctx context.Context, event domain.PaymentEvent) (app.ApplyPaymentEventResult, error)
+}
+
+// SignatureVerifier checks a webhook signature header against the raw body.
+type SignatureVerifier interface {
+	Verify(header string, payload []byte) error
+}
+
+// HandlePaymentWebhook returns an HTTP handler for payment processor webhooks.
+func HandlePaymentWebhook(svc PaymentEventApplier, verifier SignatureVerifier) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			writeError(w, http.StatusMethodNotAllowed, codeMethodNotAllowed, "method not allowed")
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxWebhookBodyBytes+1))
+		if err != nil || len(body) > maxWebhookBodyBytes {
+			writeError(w, http.StatusBadRequest, codeInvalidRequestBody, "invalid request body")
+			return
+		}
+
+		if err := verifier.Verify(r.Header.Get(webhook.SignatureHeader), body); err != nil {
+			writeError(w, http.StatusUnauthorized, codeInvalidSignature, err.Error())
+			return
+		}
+
+		var req paymentWebhookRequest
+		if err := json.Unmarshal(body, &req); err != nil {
+			writeError(w, http.StatusBadRequest, codeInvalidRequestBody, "invalid request body")
+			return
+		}
+
+		res, err := svc.Apply(r.Context(), domain.PaymentEvent{
+			ID:               req.ID,
+			Type:             domain.PaymentEventType(req.Type),
+			OrderID:          req.OrderID,
+			PaymentReference: req.PaymentReference,
+			OccurredAt:       req.CreatedAt,
+		})
+		if err != nil {
+			switch err {
+			case domain.ErrPaymentEventInvalid:
+				writeError(w, http.StatusBadRequest, codeInvalidPaymentEvent, err.Error())
+			case domain.ErrOrderNotFound:
+				writeError(w, http.StatusNotFound, codeOrderNotFound, err.Error())
+			case domain.ErrInvalidID:
+				writeError(w, http.StatusNotFound, codeInvalidID, err.Error())
+			default:
+				writeInternalError(w, r, err)
+			}
+			return
+		}
+
+		resp := paymentWebhookResponse{
+			Outcome: string(res.Outcome),
+			OrderID: res.Order.ID,
+		}
+		if res.Order.Status != "" {
+			resp.OrderStatus = string(res.Order.Status)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		_ = json.NewEncoder(w).Encode(resp)
+	}
+}
+
+type paymentWebhookRequest struct {
+	ID               string    `json:"id"`
+	Type             string    `json:"type"`
+	OrderID          string    `json:"order_id,omitempty"`
+	PaymentReference string    `json:"payment_reference,omitempty"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+type paymentWebhookResponse struct {
+	Outcome     string `json:"outcome"`
+	OrderID     string `json:"order_id,omitempty"`
+	OrderStatus string `json:"order_status,omitempty"`
+}
@@ -0,0 +1,128 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cimillas/ultimate-ticket/services/api/internal/app"
+	"github.com/cimillas/ultimate-ticket/services/api/internal/clock"
+	"github.com/cimillas/ultimate-ticket/services/api/internal/domain"
+	"github.com/cimillas/ultimate-ticket/services/api/internal/webhook"
+)
+
+func TestHandlePaymentWebhook(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, 1, 7, 12, 0, 0, 0, time.UTC)
+	secret := []byte("whsec_test")
+	verifier := webhook.NewVerifier(secret, time.Minute, clock.NewFixed(now))
+	body := `{"id":"evt_1","type":"payment.captured","order_id":"order-1"}`
+
+	tests := []struct {
+		name           string
+		body           string
+		signature      string
+		result         app.ApplyPaymentEventResult
+		serviceErr     error
+		expectedStatus int
+		expectedSubstr string
+	}{
+		{
+			name:           "processed",
+			body:           body,
+			signature:      webhook.Sign(secret, now, []byte(body)),
+			result:         app.ApplyPaymentEventResult{Outcome: domain.PaymentEventProcessed, Order: domain.Order{ID: "order-1", Status: domain.OrderStatusPaid}},
+			expectedStatus: http.StatusOK,
+			expectedSubstr: `"order_status":"paid"`,
+		},
+		{
+			name:           "duplicate",
+			body:           body,
+			signature:      webhook.Sign(secret, now, []byte(body)),
+			result:         app.ApplyPaymentEventResult{Outcome: domain.PaymentEventDuplicate},
+			expectedStatus: http.StatusOK,
+			expectedSubstr: `"outcome":"duplicate"`,
+		},
+		{
+			name:           "missing signature",
+			body:           body,
+			expectedStatus: http.StatusUnauthorized,
+			expectedSubstr: `"code":"invalid_signature"`,
+		},
+		{
+			name:           "bad signature",
+			body:           body,
+			signature:      webhook.Sign([]byte("other"), now, []byte(body)),
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "stale signature",
+			body:           body,
+			signature:      webhook.Sign(secret, now.Add(-time.Hour), []byte(body)),
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "invalid json",
+			body:           `{"id":`,
+			signature:      webhook.Sign(secret, now, []byte(`{"id":`)),
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid event",
+			body:           body,
+			signature:      webhook.Sign(secret, now, []byte(body)),
+			serviceErr:     domain.ErrPaymentEventInvalid,
+			expectedStatus: http.StatusBadRequest,
+			expectedSubstr: `"code":"invalid_payment_event"`,
+		},
+		{
+			name:           "order not found",
+			body:           body,
+			signature:      webhook.Sign(secret, now, []byte(body)),
+			serviceErr:     domain.ErrOrderNotFound,
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			svc := &stubPaymentEventApplier{result: tt.result, err: tt.serviceErr}
+
+			req := httptest.NewRequest(http.MethodPost, "/webhooks/payments", bytes.NewBufferString(tt.body))
+			if tt.signature != "" {
+				req.Header.Set(webhook.SignatureHeader, tt.signature)
+			}
+			rec := httptest.NewRecorder()
+
+			HandlePaymentWebhook(svc, verifier).ServeHTTP(rec, req)
+
+			if rec.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d", tt.expectedStatus, rec.Code)
+			}
+			if tt.expectedSubstr != "" && !strings.Contains(rec.Body.String(), tt.expectedSubstr) {
+				t.Fatalf("expected response to contain %q, got %q", tt.expectedSubstr, rec.Body.String())
+			}
+			if tt.expectedStatus == http.StatusUnauthorized && svc.called {
+				t.Fatalf("expected service not to be called for unsigned request")
+			}
+		})
+	}
+}
+
+type stubPaymentEventApplier struct {
+	result app.ApplyPaymentEventResult
+	err    error
+	called bool
+}
+
+func (s *stubPaymentEventApplier) Apply(_ context.Context, _ domain.PaymentEvent) (app.ApplyPaymentEventResult, error) {
+	s.called = true
+	return s.result, s.err
+}
@@ -0,0 +1,102 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/cimillas/ultimate-ticket/services/api/internal/clock"
+	"github.com/cimillas/ultimate-ticket/services/api/internal/pow"
+)
+
+// Headers carrying a solved proof-of-work challenge on POST /holds.
+const (
+	ChallengeHeader = "Pow-Challenge"
+	SolutionHeader  = "Pow-Solution"
+)
+
+// ChallengeReplayStore remembers redeemed challenges until they expire.
+// Redeem reports false when the challenge was already spent on another hold.
+type ChallengeReplayStore interface {
+	Redeem(ctx context.Context, challengeID string, hold pow.Hold, expiresAt, now time.Time) (bool, error)
+}
+
+// ProofOfWork asks anonymous clients to solve a hash puzzle per hold, with a
+// difficulty that rises with the rate of hold attempts.
+type ProofOfWork struct {
+	issuer *pow.Issuer
+	tuner  *pow.Tuner
+	store  ChallengeReplayStore
+	clock  clock.Clock
+}
+
+func NewProofOfWork(issuer *pow.Issuer, tuner *pow.Tuner, store ChallengeReplayStore, clk clock.Clock) *ProofOfWork {
+	return &ProofOfWork{issuer: issuer, tuner: tuner, store: store, clock: clk}
+}
+
+// HandleChallenge returns an HTTP handler for GET /challenge, which issues a
+// challenge at the current difficulty.
+func HandleChallenge(p *ProofOfWork) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			writeError(w, http.StatusMethodNotAllowed, codeMethodNotAllowed, "method not allowed")
+			return
+		}
+		challenge, err := p.issuer.Issue(p.tuner.Difficulty(p.clock.Now()))
+		if err != nil {
+			writeInternalError(w, r, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		_ = json.NewEncoder(w).Encode(challengeResponse{
+			Challenge:  challenge.Token,
+			Difficulty: challenge.Difficulty,
+			ExpiresAt:  challenge.ExpiresAt,
+		})
+	}
+}
+
+// observe counts a hold attempt towards the load that tunes the difficulty.
+func (p *ProofOfWork) observe() {
+	p.tuner.Observe(p.clock.Now())
+}
+
+// verify checks the request's solution and spends its challenge on the hold
+// req asks for, so one solution admits one hold in one zone. It writes the
+// error response and returns false when the hold must not go ahead.
+func (p *ProofOfWork) verify(w http.ResponseWriter, r *http.Request, req createHoldRequest) bool {
+	token, solution := r.Header.Get(ChallengeHeader), r.Header.Get(SolutionHeader)
+	if token == "" || solution == "" {
+		writeError(w, http.StatusForbidden, codeProofOfWorkRequired, "proof of work required")
+		return false
+	}
+	challenge, err := p.issuer.Verify(token, solution)
+	if err != nil {
+		switch err {
+		case pow.ErrInvalidChallenge, pow.ErrChallengeExpired, pow.ErrInvalidSolution:
+			writeError(w, http.StatusForbidden, codeInvalidProofOfWork, err.Error())
+		default:
+			writeInternalError(w, r, err)
+		}
+		return false
+	}
+	hold := pow.Hold{EventID: req.EventID, ZoneID: req.ZoneID, IdempotencyKey: req.IdempotencyKey}
+	ok, err := p.store.Redeem(r.Context(), challenge.ID, hold, challenge.ExpiresAt, p.clock.Now())
+	if err != nil {
+		writeInternalError(w, r, err)
+		return false
+	}
+	if !ok {
+		writeError(w, http.StatusForbidden, codeProofOfWorkReused, "proof-of-work challenge already used")
+		return false
+	}
+	return true
+}
+
+type challengeResponse struct {
+	Challenge  string    `json:"challenge"`
+	Difficulty int       `json:"difficulty"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
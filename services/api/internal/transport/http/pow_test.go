@@ -0,0 +1,129 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/cimillas/ultimate-ticket/services/api/internal/clock"
+	"github.com/cimillas/ultimate-ticket/services/api/internal/domain"
+	"github.com/cimillas/ultimate-ticket/services/api/internal/pow"
+)
+
+func TestHandleChallenge(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	p := NewProofOfWork(pow.NewIssuer([]byte("secret"), clock.NewFixed(now)), pow.NewTuner(4, 12, 1), pow.NewMemoryReplayStore(), clock.NewFixed(now))
+
+	get := func() challengeResponse {
+		rec := httptest.NewRecorder()
+		HandleChallenge(p).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/challenge", nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d", rec.Code)
+		}
+		var resp challengeResponse
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		return resp
+	}
+
+	if resp := get(); resp.Challenge == "" || resp.Difficulty != 4 || !resp.ExpiresAt.Equal(now.Add(pow.DefaultTTL)) {
+		t.Fatalf("unexpected idle challenge %+v", resp)
+	}
+
+	// Hold attempts raise the difficulty: 40 over the window is 4/s, four
+	// times the threshold of 1/s.
+	hold := HandleCreateHold(&stubHoldService{}, WithProofOfWork(p))
+	for i := 0; i < 40; i++ {
+		hold.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/holds", bytes.NewBufferString(`{}`)))
+	}
+	if resp := get(); resp.Difficulty != 6 {
+		t.Fatalf("expected difficulty 6 under load, got %d", resp.Difficulty)
+	}
+
+	rec := httptest.NewRecorder()
+	HandleChallenge(p).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/challenge", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("expected 405, got %d", rec.Code)
+	}
+}
+
+func TestHandleCreateHold_ProofOfWork(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	issuer := pow.NewIssuer([]byte("secret"), clock.NewFixed(now))
+	p := NewProofOfWork(issuer, pow.NewTuner(4, 4, 0), pow.NewMemoryReplayStore(), clock.NewFixed(now))
+	h := HandleCreateHold(&stubHoldService{hold: domain.Hold{ID: "hold-1"}}, WithProofOfWork(p))
+
+	challenge, err := issuer.Issue(4)
+	if err != nil {
+		t.Fatalf("issue: %v", err)
+	}
+	solution := pow.Solve(challenge.Token, 4)
+	wrong := "wrong"
+	for n := 0; verifies(issuer, challenge.Token, wrong); n++ {
+		wrong = "wrong" + strconv.Itoa(n)
+	}
+
+	tests := []struct {
+		name       string
+		key        string
+		zone       string
+		challenge  string
+		solution   string
+		customer   bool
+		wantStatus int
+		wantCode   string
+	}{
+		{name: "missing", key: "k1", wantStatus: http.StatusForbidden, wantCode: codeProofOfWorkRequired},
+		{name: "wrong solution", key: "k1", challenge: challenge.Token, solution: wrong, wantStatus: http.StatusForbidden, wantCode: codeInvalidProofOfWork},
+		{name: "forged challenge", key: "k1", challenge: "forged.token", solution: solution, wantStatus: http.StatusForbidden, wantCode: codeInvalidProofOfWork},
+		{name: "valid", key: "k1", challenge: challenge.Token, solution: solution, wantStatus: http.StatusCreated},
+		{name: "retry of the same hold", key: "k1", challenge: challenge.Token, solution: solution, wantStatus: http.StatusCreated},
+		{name: "replay on another hold", key: "k2", challenge: challenge.Token, solution: solution, wantStatus: http.StatusForbidden, wantCode: codeProofOfWorkReused},
+		{name: "replay in another zone with the same key", key: "k1", zone: "z2", challenge: challenge.Token, solution: solution, wantStatus: http.StatusForbidden, wantCode: codeProofOfWorkReused},
+		{name: "signed-in customer", key: "k3", customer: true, wantStatus: http.StatusCreated},
+		{name: "signed-in customer with a reused challenge", key: "k4", customer: true, challenge: challenge.Token, solution: solution, wantStatus: http.StatusForbidden, wantCode: codeProofOfWorkReused},
+	}
+
+	// Cases run in order: the replay depends on the earlier redemption.
+	for _, tt := range tests {
+		zone := tt.zone
+		if zone == "" {
+			zone = "z1"
+		}
+		body := `{"event_id":"e1","zone_id":"` + zone + `","quantity":1,"idempotency_key":"` + tt.key + `"}`
+		req := httptest.NewRequest(http.MethodPost, "/holds", bytes.NewBufferString(body))
+		if tt.challenge != "" {
+			req.Header.Set(ChallengeHeader, tt.challenge)
+			req.Header.Set(SolutionHeader, tt.solution)
+		}
+		if tt.customer {
+			req = req.WithContext(WithCustomer(req.Context(), domain.Customer{ID: "customer-1"}))
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+
+		if rec.Code != tt.wantStatus {
+			t.Fatalf("%s: expected status %d, got %d: %s", tt.name, tt.wantStatus, rec.Code, rec.Body.String())
+		}
+		if tt.wantCode != "" {
+			var resp errorResponse
+			if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil || resp.Code != tt.wantCode {
+				t.Fatalf("%s: expected code %q, got %+v (%v)", tt.name, tt.wantCode, resp, err)
+			}
+		}
+	}
+}
+
+func verifies(issuer *pow.Issuer, token, solution string) bool {
+	_, err := issuer.Verify(token, solution)
+	return err == nil
+}
@@ -0,0 +1,148 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cimillas/ultimate-ticket/services/api/internal/app"
+	"github.com/cimillas/ultimate-ticket/services/api/internal/domain"
+)
+
+// AdmissionTokenHeader carries a waiting-room admission token on POST /holds.
+const AdmissionTokenHeader = "Admission-Token"
+
+// WaitingRoomService is the minimal interface needed for waiting-room endpoints.
+type WaitingRoomService interface {
+	Join(ctx context.Context, eventID, customerID string) (app.QueueStatus, error)
+	Status(ctx context.Context, entryID string) (app.QueueStatus, error)
+}
+
+// HandleJoinQueue returns an HTTP handler for POST /events/{event_id}/queue.
+// Signed-in customers who already wait or are admitted get their entry back
+// with 200 instead of a second place in the queue.
+func HandleJoinQueue(svc WaitingRoomService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		eventID, ok := parseEventQueuePath(r.URL.Path)
+		if !ok {
+			writeError(w, http.StatusNotFound, codeNotFound, "not found")
+			return
+		}
+		logAttrs(r, slog.String("event_id", eventID))
+		if r.Method != http.MethodPost {
+			writeError(w, http.StatusMethodNotAllowed, codeMethodNotAllowed, "method not allowed")
+			return
+		}
+
+		var customerID string
+		if customer, ok := CustomerFromContext(r.Context()); ok {
+			customerID = customer.ID
+		}
+		status, err := svc.Join(r.Context(), eventID, customerID)
+		if err != nil {
+			switch err {
+			case domain.ErrInvalidID:
+				writeError(w, http.StatusNotFound, codeInvalidID, err.Error())
+			case domain.ErrEventNotFound:
+				writeError(w, http.StatusNotFound, codeEventNotFound, err.Error())
+			case domain.ErrWaitingRoomDisabled:
+				writeError(w, http.StatusConflict, codeWaitingRoomDisabled, err.Error())
+			default:
+				writeInternalError(w, r, err)
+			}
+			return
+		}
+
+		setRetryAfter(w, status.RetryAfter)
+		w.Header().Set("Content-Type", "application/json")
+		if status.Created {
+			w.WriteHeader(http.StatusCreated)
+		}
+		_ = json.NewEncoder(w).Encode(newQueueEntryResponse(status))
+	}
+}
+
+// HandleQueueEntry returns an HTTP handler for GET /queue/{entry_id}. Clients
+// poll it until the entry is admitted and carries an admission token, waiting
+// as long as the Retry-After header suggests between polls. Polling only reads
+// the queue, never holds.
+func HandleQueueEntry(svc WaitingRoomService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		entryID := strings.TrimPrefix(r.URL.Path, "/queue/")
+		if entryID == "" || strings.Contains(entryID, "/") {
+			writeError(w, http.StatusNotFound, codeNotFound, "not found")
+			return
+		}
+		if r.Method != http.MethodGet {
+			writeError(w, http.StatusMethodNotAllowed, codeMethodNotAllowed, "method not allowed")
+			return
+		}
+
+		status, err := svc.Status(r.Context(), entryID)
+		if err != nil {
+			switch err {
+			case domain.ErrInvalidID, domain.ErrQueueEntryNotFound:
+				writeError(w, http.StatusNotFound, codeQueueEntryNotFound, domain.ErrQueueEntryNotFound.Error())
+			default:
+				writeInternalError(w, r, err)
+			}
+			return
+		}
+
+		setRetryAfter(w, status.RetryAfter)
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(newQueueEntryResponse(status))
+	}
+}
+
+func setRetryAfter(w http.ResponseWriter, d time.Duration) {
+	if d > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(int(d/time.Second)))
+	}
+}
+
+type queueEntryResponse struct {
+	ID       string `json:"id"`
+	EventID  string `json:"event_id"`
+	Status   string `json:"status"`
+	Position int    `json:"position,omitempty"`
+	Ahead    *int   `json:"ahead,omitempty"`
+	// EstimatedAdmissionAt is omitted while the event admits nobody.
+	EstimatedAdmissionAt *time.Time `json:"estimated_admission_at,omitempty"`
+	JoinedAt             time.Time  `json:"joined_at"`
+	AdmissionToken       string     `json:"admission_token,omitempty"`
+	ExpiresAt            *time.Time `json:"expires_at,omitempty"`
+}
+
+func newQueueEntryResponse(status app.QueueStatus) queueEntryResponse {
+	resp := queueEntryResponse{
+		ID:             status.Entry.ID,
+		EventID:        status.Entry.EventID,
+		Status:         string(status.Entry.Status),
+		Position:       status.Entry.Position,
+		JoinedAt:       status.Entry.JoinedAt,
+		AdmissionToken: status.AdmissionToken,
+	}
+	if status.Entry.Status == domain.QueueEntryWaiting {
+		resp.Ahead = &status.Ahead
+	}
+	if !status.EstimatedAdmissionAt.IsZero() {
+		resp.EstimatedAdmissionAt = &status.EstimatedAdmissionAt
+	}
+	if status.AdmissionToken != "" {
+		resp.ExpiresAt = &status.ExpiresAt
+	}
+	return resp
+}
+
+func parseEventQueuePath(path string) (string, bool) {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	if len(parts) != 3 || parts[0] != "events" || parts[1] == "" || parts[2] != "queue" {
+		return "", false
+	}
+	return parts[1], true
+}
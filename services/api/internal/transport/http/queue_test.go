@@ -0,0 +1,233 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cimillas/ultimate-ticket/services/api/internal/app"
+	"github.com/cimillas/ultimate-ticket/services/api/internal/domain"
+)
+
+func TestHandleJoinQueue(t *testing.T) {
+	t.Parallel()
+
+	joinedAt := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		existing       bool
+		serviceErr     error
+		expectedStatus int
+		expectedSubstr string
+	}{
+		{
+			name:           "joins",
+			method:         http.MethodPost,
+			path:           "/events/event-1/queue",
+			expectedStatus: http.StatusCreated,
+			expectedSubstr: `"status":"waiting","position":3`,
+		},
+		{
+			name:           "returns the customer's current entry",
+			method:         http.MethodPost,
+			path:           "/events/event-1/queue",
+			existing:       true,
+			expectedStatus: http.StatusOK,
+			expectedSubstr: `"id":"entry-1"`,
+		},
+		{
+			name:           "waiting room disabled",
+			method:         http.MethodPost,
+			path:           "/events/event-1/queue",
+			serviceErr:     domain.ErrWaitingRoomDisabled,
+			expectedStatus: http.StatusConflict,
+			expectedSubstr: `"code":"waiting_room_disabled"`,
+		},
+		{
+			name:           "event not found",
+			method:         http.MethodPost,
+			path:           "/events/event-1/queue",
+			serviceErr:     domain.ErrEventNotFound,
+			expectedStatus: http.StatusNotFound,
+			expectedSubstr: `"code":"event_not_found"`,
+		},
+		{
+			name:           "unknown path",
+			method:         http.MethodPost,
+			path:           "/events/event-1",
+			expectedStatus: http.StatusNotFound,
+			expectedSubstr: `"code":"not_found"`,
+		},
+		{
+			name:           "method not allowed",
+			method:         http.MethodGet,
+			path:           "/events/event-1/queue",
+			expectedStatus: http.StatusMethodNotAllowed,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			svc := &stubWaitingRoomService{
+				err: tt.serviceErr,
+				status: app.QueueStatus{Entry: domain.QueueEntry{
+					ID:       "entry-1",
+					EventID:  "event-1",
+					Status:   domain.QueueEntryWaiting,
+					Position: 3,
+					JoinedAt: joinedAt,
+				}, Created: !tt.existing},
+			}
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			req = req.WithContext(WithCustomer(req.Context(), domain.Customer{ID: "cust-1"}))
+			rec := httptest.NewRecorder()
+
+			HandleJoinQueue(svc).ServeHTTP(rec, req)
+
+			if rec.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d (%s)", tt.expectedStatus, rec.Code, rec.Body.String())
+			}
+			if tt.expectedSubstr != "" && !strings.Contains(rec.Body.String(), tt.expectedSubstr) {
+				t.Fatalf("expected response to contain %q, got %q", tt.expectedSubstr, rec.Body.String())
+			}
+			if tt.expectedStatus == http.StatusCreated && (svc.eventID != "event-1" || svc.customerID != "cust-1") {
+				t.Fatalf("expected join for event-1 by cust-1, got %q by %q", svc.eventID, svc.customerID)
+			}
+		})
+	}
+}
+
+func TestHandleQueueEntry(t *testing.T) {
+	t.Parallel()
+
+	admittedAt := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	admitted := app.QueueStatus{
+		Entry:          domain.QueueEntry{ID: "entry-1", EventID: "event-1", Status: domain.QueueEntryAdmitted, AdmittedAt: &admittedAt},
+		AdmissionToken: "signed-token",
+		ExpiresAt:      admittedAt.Add(10 * time.Minute),
+	}
+
+	tests := []struct {
+		name           string
+		path           string
+		status         app.QueueStatus
+		serviceErr     error
+		expectedStatus int
+		expectedSubstr string
+		unexpected     string
+		retryAfter     string
+	}{
+		{
+			name: "waiting",
+			path: "/queue/entry-1",
+			status: app.QueueStatus{
+				Entry:                domain.QueueEntry{ID: "entry-1", EventID: "event-1", Status: domain.QueueEntryWaiting, Position: 41},
+				Ahead:                40,
+				EstimatedAdmissionAt: admittedAt.Add(4 * time.Minute),
+				RetryAfter:           30 * time.Second,
+			},
+			expectedStatus: http.StatusOK,
+			expectedSubstr: `"status":"waiting","position":41,"ahead":40,"estimated_admission_at":"2025-01-01T12:04:00Z"`,
+			retryAfter:     "30",
+		},
+		{
+			name:           "first in line without estimate",
+			path:           "/queue/entry-1",
+			status:         app.QueueStatus{Entry: domain.QueueEntry{ID: "entry-1", Status: domain.QueueEntryWaiting, Position: 1}, RetryAfter: 30 * time.Second},
+			expectedStatus: http.StatusOK,
+			expectedSubstr: `"position":1,"ahead":0,"joined_at"`,
+			retryAfter:     "30",
+		},
+		{
+			name:           "admitted",
+			path:           "/queue/entry-1",
+			status:         admitted,
+			expectedStatus: http.StatusOK,
+			expectedSubstr: `"status":"admitted","joined_at":"0001-01-01T00:00:00Z","admission_token":"signed-token","expires_at":"2025-01-01T12:10:00Z"`,
+		},
+		{
+			name:           "expired",
+			path:           "/queue/entry-1",
+			status:         app.QueueStatus{Entry: domain.QueueEntry{ID: "entry-1", Status: domain.QueueEntryExpired}},
+			expectedStatus: http.StatusOK,
+			expectedSubstr: `"status":"expired"`,
+			unexpected:     `admission_token`,
+		},
+		{
+			name:           "admitted without ahead",
+			path:           "/queue/entry-1",
+			status:         admitted,
+			expectedStatus: http.StatusOK,
+			expectedSubstr: `"status":"admitted"`,
+			unexpected:     `ahead`,
+		},
+		{
+			name:           "not found",
+			path:           "/queue/entry-2",
+			serviceErr:     domain.ErrQueueEntryNotFound,
+			expectedStatus: http.StatusNotFound,
+			expectedSubstr: `"code":"queue_entry_not_found"`,
+		},
+		{
+			name:           "invalid id",
+			path:           "/queue/not-a-uuid",
+			serviceErr:     domain.ErrInvalidID,
+			expectedStatus: http.StatusNotFound,
+			expectedSubstr: `"code":"queue_entry_not_found"`,
+		},
+		{
+			name:           "nested path",
+			path:           "/queue/entry-1/extra",
+			expectedStatus: http.StatusNotFound,
+			expectedSubstr: `"code":"not_found"`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			svc := &stubWaitingRoomService{status: tt.status, err: tt.serviceErr}
+			rec := httptest.NewRecorder()
+
+			HandleQueueEntry(svc).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			if rec.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d (%s)", tt.expectedStatus, rec.Code, rec.Body.String())
+			}
+			if !strings.Contains(rec.Body.String(), tt.expectedSubstr) {
+				t.Fatalf("expected response to contain %q, got %q", tt.expectedSubstr, rec.Body.String())
+			}
+			if tt.unexpected != "" && strings.Contains(rec.Body.String(), tt.unexpected) {
+				t.Fatalf("expected response not to contain %q, got %q", tt.unexpected, rec.Body.String())
+			}
+			if got := rec.Header().Get("Retry-After"); got != tt.retryAfter {
+				t.Fatalf("expected Retry-After %q, got %q", tt.retryAfter, got)
+			}
+		})
+	}
+}
+
+type stubWaitingRoomService struct {
+	status     app.QueueStatus
+	err        error
+	eventID    string
+	customerID string
+}
+
+func (s *stubWaitingRoomService) Join(_ context.Context, eventID, customerID string) (app.QueueStatus, error) {
+	s.eventID, s.customerID = eventID, customerID
+	return s.status, s.err
+}
+
+func (s *stubWaitingRoomService) Status(_ context.Context, _ string) (app.QueueStatus, error) {
+	return s.status, s.err
+}
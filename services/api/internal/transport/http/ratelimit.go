@@ -0,0 +1,154 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cimillas/ultimate-ticket/services/api/internal/clock"
+	"github.com/cimillas/ultimate-ticket/services/api/internal/domain"
+)
+
+// RateLimitStore keeps token buckets by key. Take spends one token from the
+// bucket and returns how long the caller has to wait when none is left.
+type RateLimitStore interface {
+	Take(ctx context.Context, key string, limit domain.RateLimit, now time.Time) (time.Duration, error)
+}
+
+// RateLimiter throttles requests per client with a token bucket per route.
+// Clients are told apart by signed-in customer, then by authenticated API
+// key, then by IP address.
+type RateLimiter struct {
+	store  RateLimitStore
+	clock  clock.Clock
+	logger *slog.Logger
+}
+
+type RateLimiterOption func(*RateLimiter)
+
+// WithRateLimitLogger logs store errors; requests are let through when the
+// store fails.
+func WithRateLimitLogger(logger *slog.Logger) RateLimiterOption {
+	return func(l *RateLimiter) {
+		l.logger = logger
+	}
+}
+
+func NewRateLimiter(store RateLimitStore, clk clock.Clock, opts ...RateLimiterOption) *RateLimiter {
+	l := &RateLimiter{store: store, clock: clk, logger: slog.Default()}
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l
+}
+
+// Limit wraps next with its own bucket per client for route. A disabled
+// limit returns next unchanged.
+func (l *RateLimiter) Limit(route string, limit domain.RateLimit, next http.Handler) http.Handler {
+	if !limit.Enabled() {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		wait, err := l.store.Take(r.Context(), route+":"+l.clientKey(r), limit, l.clock.Now())
+		if err != nil {
+			l.logger.ErrorContext(r.Context(), "rate limit store failed", "route", route, "error", err)
+			next.ServeHTTP(w, r)
+			return
+		}
+		if wait > 0 {
+			setRetryAfter(w, (wait + time.Second - 1).Truncate(time.Second))
+			writeError(w, http.StatusTooManyRequests, codeRateLimited, "rate limit exceeded")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (l *RateLimiter) clientKey(r *http.Request) string {
+	if customer, ok := CustomerFromContext(r.Context()); ok {
+		return "customer:" + customer.ID
+	}
+	if key, ok := APIKeyFromContext(r.Context()); ok {
+		return "api_key:" + key.ID
+	}
+	return "ip:" + clientIP(r)
+}
+
+// memorySweepInterval is how often the memory store forgets full buckets.
+const memorySweepInterval = time.Minute
+
+// MemoryRateLimitStore keeps buckets in process. Each API instance then
+// enforces its own limits; use a shared store to limit across instances.
+type MemoryRateLimitStore struct {
+	mu        sync.Mutex
+	buckets   map[string]memoryBucket
+	lastSweep time.Time
+}
+
+type memoryBucket struct {
+	bucket domain.TokenBucket
+	fullAt time.Time
+}
+
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	return &MemoryRateLimitStore{buckets: make(map[string]memoryBucket)}
+}
+
+func (s *MemoryRateLimitStore) Take(_ context.Context, key string, limit domain.RateLimit, now time.Time) (time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if now.Sub(s.lastSweep) >= memorySweepInterval {
+		for k, b := range s.buckets {
+			if !b.fullAt.After(now) {
+				delete(s.buckets, k)
+			}
+		}
+		s.lastSweep = now
+	}
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b.bucket = limit.FullBucket(now)
+	}
+	bucket, wait := limit.Take(b.bucket, now)
+	s.buckets[key] = memoryBucket{bucket: bucket, fullAt: limit.FullAt(bucket)}
+	return wait, nil
+}
+
+// ParseRateLimit reads a limit written as "<requests>/<period>" with an
+// optional ":<burst>", e.g. "20/1m" or "5/s:10". "off" and "" disable it.
+func ParseRateLimit(s string) (domain.RateLimit, error) {
+	s = strings.TrimSpace(s)
+	if s == "" || s == "off" {
+		return domain.RateLimit{}, nil
+	}
+	rate, burst, hasBurst := strings.Cut(s, ":")
+	requests, period, ok := strings.Cut(rate, "/")
+	if !ok {
+		return domain.RateLimit{}, errors.New("expected <requests>/<period>")
+	}
+	var limit domain.RateLimit
+	var err error
+	if limit.Requests, err = strconv.Atoi(requests); err != nil || limit.Requests <= 0 {
+		return domain.RateLimit{}, fmt.Errorf("invalid request count %q", requests)
+	}
+	if period != "" && (period[0] < '0' || period[0] > '9') {
+		period = "1" + period
+	}
+	if limit.Per, err = time.ParseDuration(period); err != nil || limit.Per <= 0 {
+		return domain.RateLimit{}, fmt.Errorf("invalid period %q", period)
+	}
+	if hasBurst {
+		if limit.Burst, err = strconv.Atoi(burst); err != nil || limit.Burst <= 0 {
+			return domain.RateLimit{}, fmt.Errorf("invalid burst %q", burst)
+		}
+	}
+	return limit, nil
+}
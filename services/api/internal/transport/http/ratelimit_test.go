@@ -0,0 +1,191 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/cimillas/ultimate-ticket/services/api/internal/clock"
+	"github.com/cimillas/ultimate-ticket/services/api/internal/domain"
+)
+
+type failingRateLimitStore struct{}
+
+func (failingRateLimitStore) Take(context.Context, string, domain.RateLimit, time.Time) (time.Duration, error) {
+	return 0, errors.New("store down")
+}
+
+func TestRateLimiter_Limit(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	limit := domain.RateLimit{Requests: 2, Per: time.Minute}
+	ok := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	newRequest := func(remoteAddr string, customer *domain.Customer) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/holds", nil)
+		req.RemoteAddr = remoteAddr
+		if customer != nil {
+			req = req.WithContext(WithCustomer(req.Context(), *customer))
+		}
+		return req
+	}
+	serve := func(h http.Handler, req *http.Request) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("rejects once the bucket is empty", func(t *testing.T) {
+		t.Parallel()
+		limiter := NewRateLimiter(NewMemoryRateLimitStore(), clock.NewFixed(now))
+		h := limiter.Limit("holds", limit, ok)
+
+		for i := 0; i < 2; i++ {
+			if rec := serve(h, newRequest("10.0.0.1:1234", nil)); rec.Code != http.StatusNoContent {
+				t.Fatalf("request %d: expected 204, got %d", i+1, rec.Code)
+			}
+		}
+		rec := serve(h, newRequest("10.0.0.1:5678", nil))
+		if rec.Code != http.StatusTooManyRequests {
+			t.Fatalf("expected 429, got %d", rec.Code)
+		}
+		if got := rec.Header().Get("Retry-After"); got != "30" {
+			t.Fatalf("expected Retry-After 30, got %q", got)
+		}
+		var body errorResponse
+		if err := json.NewDecoder(rec.Body).Decode(&body); err != nil || body.Code != codeRateLimited {
+			t.Fatalf("expected code %q, got %+v (%v)", codeRateLimited, body, err)
+		}
+	})
+
+	t.Run("separate buckets per client and route", func(t *testing.T) {
+		t.Parallel()
+		limiter := NewRateLimiter(NewMemoryRateLimitStore(), clock.NewFixed(now))
+		holds := limiter.Limit("holds", limit, ok)
+		confirms := limiter.Limit("confirms", limit, ok)
+		customer := &domain.Customer{ID: "customer-1"}
+
+		for i := 0; i < 2; i++ {
+			serve(holds, newRequest("10.0.0.1:1234", nil))
+		}
+		if rec := serve(holds, newRequest("10.0.0.2:1234", nil)); rec.Code != http.StatusNoContent {
+			t.Fatalf("another IP: expected 204, got %d", rec.Code)
+		}
+		if rec := serve(holds, newRequest("10.0.0.1:1234", customer)); rec.Code != http.StatusNoContent {
+			t.Fatalf("signed-in customer: expected 204, got %d", rec.Code)
+		}
+		if rec := serve(confirms, newRequest("10.0.0.1:1234", nil)); rec.Code != http.StatusNoContent {
+			t.Fatalf("another route: expected 204, got %d", rec.Code)
+		}
+	})
+
+	t.Run("forwarded for", func(t *testing.T) {
+		t.Parallel()
+		limiter := NewRateLimiter(NewMemoryRateLimitStore(), clock.NewFixed(now))
+		h := ClientIP(true, limiter.Limit("holds", domain.RateLimit{Requests: 1, Per: time.Minute}, ok))
+
+		first := newRequest("10.0.0.1:1234", nil)
+		first.Header.Set("X-Forwarded-For", "1.1.1.1, 203.0.113.7")
+		if rec := serve(h, first); rec.Code != http.StatusNoContent {
+			t.Fatalf("expected 204, got %d", rec.Code)
+		}
+		spoofed := newRequest("10.0.0.1:1234", nil)
+		spoofed.Header.Set("X-Forwarded-For", "2.2.2.2, 203.0.113.7")
+		if rec := serve(h, spoofed); rec.Code != http.StatusTooManyRequests {
+			t.Fatalf("expected the proxy-added address to be used, got %d", rec.Code)
+		}
+		other := newRequest("10.0.0.1:1234", nil)
+		other.Header.Set("X-Forwarded-For", "203.0.113.8")
+		if rec := serve(h, other); rec.Code != http.StatusNoContent {
+			t.Fatalf("expected 204 for another client, got %d", rec.Code)
+		}
+	})
+
+	t.Run("disabled limit", func(t *testing.T) {
+		t.Parallel()
+		limiter := NewRateLimiter(failingRateLimitStore{}, clock.NewFixed(now))
+		if rec := serve(limiter.Limit("holds", domain.RateLimit{}, ok), newRequest("10.0.0.1:1234", nil)); rec.Code != http.StatusNoContent {
+			t.Fatalf("expected 204, got %d", rec.Code)
+		}
+	})
+
+	t.Run("store errors let requests through", func(t *testing.T) {
+		t.Parallel()
+		buf := &bytes.Buffer{}
+		limiter := NewRateLimiter(failingRateLimitStore{}, clock.NewFixed(now), WithRateLimitLogger(slog.New(slog.NewTextHandler(buf, nil))))
+		if rec := serve(limiter.Limit("holds", limit, ok), newRequest("10.0.0.1:1234", nil)); rec.Code != http.StatusNoContent {
+			t.Fatalf("expected 204, got %d", rec.Code)
+		}
+		if !bytes.Contains(buf.Bytes(), []byte("store down")) {
+			t.Fatalf("expected the store error to be logged, got %q", buf.String())
+		}
+	})
+}
+
+func TestMemoryRateLimitStore_Refills(t *testing.T) {
+	t.Parallel()
+
+	store := NewMemoryRateLimitStore()
+	ctx := context.Background()
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	limit := domain.RateLimit{Requests: 1, Per: 10 * time.Second, Burst: 3}
+
+	for i := 0; i < 3; i++ {
+		if wait, _ := store.Take(ctx, "k", limit, now); wait != 0 {
+			t.Fatalf("burst request %d: expected no wait, got %s", i+1, wait)
+		}
+	}
+	if wait, _ := store.Take(ctx, "k", limit, now.Add(4*time.Second)); wait != 6*time.Second {
+		t.Fatalf("expected a 6s wait, got %s", wait)
+	}
+	if wait, _ := store.Take(ctx, "k", limit, now.Add(10*time.Second)); wait != 0 {
+		t.Fatalf("expected a refilled token, got wait %s", wait)
+	}
+
+	// Full buckets are forgotten on the next sweep.
+	store.Take(ctx, "other", limit, now.Add(time.Hour))
+	if _, ok := store.buckets["k"]; ok {
+		t.Fatalf("expected the full bucket to be swept")
+	}
+}
+
+func TestParseRateLimit(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		in      string
+		want    domain.RateLimit
+		wantErr bool
+	}{
+		{in: "", want: domain.RateLimit{}},
+		{in: "off", want: domain.RateLimit{}},
+		{in: "20/1m", want: domain.RateLimit{Requests: 20, Per: time.Minute}},
+		{in: "5/s:10", want: domain.RateLimit{Requests: 5, Per: time.Second, Burst: 10}},
+		{in: "20", wantErr: true},
+		{in: "0/1m", wantErr: true},
+		{in: "20/fortnight", wantErr: true},
+		{in: "20/1m:0", wantErr: true},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.in, func(t *testing.T) {
+			t.Parallel()
+			got, err := ParseRateLimit(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if got != tt.want {
+				t.Fatalf("expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,218 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cimillas/ultimate-ticket/services/api/internal/domain"
+)
+
+// AdminRiskService is the minimal interface needed for risk endpoints.
+type AdminRiskService interface {
+	GetRiskRules(ctx context.Context, organizerID, eventID string) (domain.RiskRules, error)
+	UpdateRiskRules(ctx context.Context, organizerID, eventID string, rules domain.RiskRules) (domain.RiskRules, error)
+	ListDecisions(ctx context.Context, filter domain.RiskDecisionFilter) ([]domain.RiskAssessment, error)
+}
+
+// HandleAdminRiskRules returns an HTTP handler for GET and PUT
+// /admin/events/{event_id}/risk-rules. Other paths are passed to next.
+func HandleAdminRiskRules(svc AdminRiskService, next http.Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		eventID, ok := parseAdminRiskRulesPath(r.URL.Path)
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+		logAttrs(r, slog.String("event_id", eventID))
+
+		var rules domain.RiskRules
+		var err error
+		switch r.Method {
+		case http.MethodGet:
+			organizerID, ok := adminOrganizer(w, r)
+			if !ok {
+				return
+			}
+			rules, err = svc.GetRiskRules(r.Context(), organizerID, eventID)
+		case http.MethodPut:
+			organizerID, ok := adminOrganizer(w, r)
+			if !ok {
+				return
+			}
+			var req riskRulesRequest
+			dec := json.NewDecoder(r.Body)
+			dec.DisallowUnknownFields()
+			if err := dec.Decode(&req); err != nil {
+				writeError(w, http.StatusBadRequest, codeInvalidRequestBody, "invalid request body")
+				return
+			}
+			rules, err = svc.UpdateRiskRules(r.Context(), organizerID, eventID, req.toDomain())
+		default:
+			writeError(w, http.StatusMethodNotAllowed, codeMethodNotAllowed, "method not allowed")
+			return
+		}
+		if err != nil {
+			switch err {
+			case domain.ErrInvalidID:
+				writeError(w, http.StatusNotFound, codeInvalidID, err.Error())
+			case domain.ErrEventNotFound:
+				writeError(w, http.StatusNotFound, codeEventNotFound, err.Error())
+			case domain.ErrInvalidRiskRules:
+				writeError(w, http.StatusBadRequest, codeInvalidRiskRules, err.Error())
+			default:
+				writeInternalError(w, r, err)
+			}
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(newRiskRulesResponse(rules))
+	}
+}
+
+// HandleAdminRiskDecisions returns an HTTP handler for
+// GET /admin/risk-decisions[?event_id=&decision=&since=&limit=].
+// since is an RFC 3339 timestamp.
+func HandleAdminRiskDecisions(svc AdminRiskService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			writeError(w, http.StatusMethodNotAllowed, codeMethodNotAllowed, "method not allowed")
+			return
+		}
+		q := r.URL.Query()
+		filter := domain.RiskDecisionFilter{
+			EventID:  q.Get("event_id"),
+			Decision: domain.RiskDecision(q.Get("decision")),
+		}
+		switch filter.Decision {
+		case "", domain.RiskAllow, domain.RiskChallenge, domain.RiskBlock:
+		default:
+			writeError(w, http.StatusBadRequest, codeInvalidRiskFilter, "invalid decision")
+			return
+		}
+		if raw := q.Get("since"); raw != "" {
+			since, err := time.Parse(time.RFC3339, raw)
+			if err != nil {
+				writeError(w, http.StatusBadRequest, codeInvalidRiskFilter, "invalid since")
+				return
+			}
+			filter.Since = &since
+		}
+		if raw := q.Get("limit"); raw != "" {
+			limit, err := strconv.Atoi(raw)
+			if err != nil || limit <= 0 {
+				writeError(w, http.StatusBadRequest, codeInvalidRiskFilter, "invalid limit")
+				return
+			}
+			filter.Limit = limit
+		}
+		organizerID, ok := adminOrganizer(w, r)
+		if !ok {
+			return
+		}
+		filter.OrganizerID = organizerID
+
+		decisions, err := svc.ListDecisions(r.Context(), filter)
+		if err != nil {
+			if err == domain.ErrInvalidID {
+				writeError(w, http.StatusBadRequest, codeInvalidRiskFilter, "invalid event_id")
+				return
+			}
+			writeInternalError(w, r, err)
+			return
+		}
+		resp := make([]riskDecisionResponse, 0, len(decisions))
+		for _, d := range decisions {
+			resp = append(resp, newRiskDecisionResponse(d))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(resp)
+	}
+}
+
+type riskRulesRequest struct {
+	Enabled             bool     `json:"enabled"`
+	ChallengeScore      int      `json:"challenge_score"`
+	BlockScore          int      `json:"block_score"`
+	MaxHoldsPerIP       int      `json:"max_holds_per_ip"`
+	MaxHoldsPerCustomer int      `json:"max_holds_per_customer"`
+	MaxHoldsPerZone     int      `json:"max_holds_per_zone"`
+	BlockedUserAgents   []string `json:"blocked_user_agents"`
+}
+
+func (r riskRulesRequest) toDomain() domain.RiskRules {
+	return domain.RiskRules{
+		Enabled:             r.Enabled,
+		ChallengeScore:      r.ChallengeScore,
+		BlockScore:          r.BlockScore,
+		MaxHoldsPerIP:       r.MaxHoldsPerIP,
+		MaxHoldsPerCustomer: r.MaxHoldsPerCustomer,
+		MaxHoldsPerZone:     r.MaxHoldsPerZone,
+		BlockedUserAgents:   r.BlockedUserAgents,
+	}
+}
+
+type riskRulesResponse riskRulesRequest
+
+func newRiskRulesResponse(rules domain.RiskRules) riskRulesResponse {
+	agents := rules.BlockedUserAgents
+	if agents == nil {
+		agents = []string{}
+	}
+	return riskRulesResponse{
+		Enabled:             rules.Enabled,
+		ChallengeScore:      rules.ChallengeScore,
+		BlockScore:          rules.BlockScore,
+		MaxHoldsPerIP:       rules.MaxHoldsPerIP,
+		MaxHoldsPerCustomer: rules.MaxHoldsPerCustomer,
+		MaxHoldsPerZone:     rules.MaxHoldsPerZone,
+		BlockedUserAgents:   agents,
+	}
+}
+
+type riskDecisionResponse struct {
+	ID          string    `json:"id"`
+	EventID     string    `json:"event_id"`
+	ZoneID      string    `json:"zone_id"`
+	CustomerID  string    `json:"customer_id,omitempty"`
+	IP          string    `json:"ip"`
+	UserAgent   string    `json:"user_agent"`
+	ProofOfWork bool      `json:"proof_of_work"`
+	Score       int       `json:"score"`
+	Reasons     []string  `json:"reasons"`
+	Decision    string    `json:"decision"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+func newRiskDecisionResponse(a domain.RiskAssessment) riskDecisionResponse {
+	reasons := a.Reasons
+	if reasons == nil {
+		reasons = []string{}
+	}
+	return riskDecisionResponse{
+		ID:          a.ID,
+		EventID:     a.EventID,
+		ZoneID:      a.ZoneID,
+		CustomerID:  a.CustomerID,
+		IP:          a.IP,
+		UserAgent:   a.UserAgent,
+		ProofOfWork: a.ProofOfWork,
+		Score:       a.Score,
+		Reasons:     reasons,
+		Decision:    string(a.Decision),
+		CreatedAt:   a.CreatedAt,
+	}
+}
+
+func parseAdminRiskRulesPath(path string) (string, bool) {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	if len(parts) != 4 || parts[0] != "admin" || parts[1] != "events" || parts[2] == "" || parts[3] != "risk-rules" {
+		return "", false
+	}
+	return parts[2], true
+}
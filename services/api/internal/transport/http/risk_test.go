@@ -0,0 +1,216 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cimillas/ultimate-ticket/services/api/internal/domain"
+)
+
+func TestHandleAdminRiskRules(t *testing.T) {
+	t.Parallel()
+
+	valid := `{"enabled":true,"challenge_score":40,"block_score":90,"max_holds_per_ip":10,"max_holds_per_customer":5,"max_holds_per_zone":0,"blocked_user_agents":["curl"]}`
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		body           string
+		serviceErr     error
+		expectedStatus int
+		expectedSubstr string
+	}{
+		{
+			name:           "gets",
+			method:         http.MethodGet,
+			path:           "/admin/events/event-1/risk-rules",
+			expectedStatus: http.StatusOK,
+			expectedSubstr: `{"enabled":false,"challenge_score":50,"block_score":100,"max_holds_per_ip":0,"max_holds_per_customer":0,"max_holds_per_zone":0,"blocked_user_agents":[]}`,
+		},
+		{
+			name:           "replaces",
+			method:         http.MethodPut,
+			path:           "/admin/events/event-1/risk-rules",
+			body:           valid,
+			expectedStatus: http.StatusOK,
+			expectedSubstr: valid,
+		},
+		{
+			name:           "unknown field",
+			method:         http.MethodPut,
+			path:           "/admin/events/event-1/risk-rules",
+			body:           `{"enabled":true,"score":1}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedSubstr: `"code":"invalid_request_body"`,
+		},
+		{
+			name:           "invalid rules",
+			method:         http.MethodPut,
+			path:           "/admin/events/event-1/risk-rules",
+			body:           valid,
+			serviceErr:     domain.ErrInvalidRiskRules,
+			expectedStatus: http.StatusBadRequest,
+			expectedSubstr: `"code":"invalid_risk_rules"`,
+		},
+		{
+			name:           "event not found",
+			method:         http.MethodGet,
+			path:           "/admin/events/event-2/risk-rules",
+			serviceErr:     domain.ErrEventNotFound,
+			expectedStatus: http.StatusNotFound,
+			expectedSubstr: `"code":"event_not_found"`,
+		},
+		{
+			name:           "method not allowed",
+			method:         http.MethodDelete,
+			path:           "/admin/events/event-1/risk-rules",
+			expectedStatus: http.StatusMethodNotAllowed,
+			expectedSubstr: `"code":"method_not_allowed"`,
+		},
+		{
+			name:           "other paths go to next",
+			method:         http.MethodGet,
+			path:           "/admin/events/event-1/zones",
+			expectedStatus: http.StatusTeapot,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			svc := &stubRiskService{err: tt.serviceErr}
+			next := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusTeapot)
+			})
+			req := withAdminKey(httptest.NewRequest(tt.method, tt.path, bytes.NewBufferString(tt.body)), "org-1")
+			rec := httptest.NewRecorder()
+
+			HandleAdminRiskRules(svc, next).ServeHTTP(rec, req)
+
+			if rec.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d (%s)", tt.expectedStatus, rec.Code, rec.Body.String())
+			}
+			if !strings.Contains(rec.Body.String(), tt.expectedSubstr) {
+				t.Fatalf("expected response to contain %q, got %q", tt.expectedSubstr, rec.Body.String())
+			}
+			if tt.method == http.MethodPut && tt.expectedStatus == http.StatusOK && (svc.organizerID != "org-1" || svc.eventID != "event-1") {
+				t.Fatalf("unexpected update for %s/%s", svc.organizerID, svc.eventID)
+			}
+		})
+	}
+}
+
+func TestHandleAdminRiskDecisions(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name           string
+		query          string
+		serviceErr     error
+		expectedStatus int
+		expectedSubstr string
+		expectedFilter domain.RiskDecisionFilter
+	}{
+		{
+			name:           "lists",
+			query:          "?event_id=event-1&decision=block&limit=10",
+			expectedStatus: http.StatusOK,
+			expectedSubstr: `[{"id":"decision-1","event_id":"event-1","zone_id":"zone-1","ip":"203.0.113.7","user_agent":"curl/8.4","proof_of_work":false,"score":110,"reasons":["anonymous","blocked_user_agent"],"decision":"block","created_at":"2025-01-01T12:00:00Z"}]`,
+			expectedFilter: domain.RiskDecisionFilter{OrganizerID: "org-1", EventID: "event-1", Decision: domain.RiskBlock, Limit: 10},
+		},
+		{
+			name:           "invalid decision",
+			query:          "?decision=maybe",
+			expectedStatus: http.StatusBadRequest,
+			expectedSubstr: `"code":"invalid_risk_filter"`,
+		},
+		{
+			name:           "invalid since",
+			query:          "?since=yesterday",
+			expectedStatus: http.StatusBadRequest,
+			expectedSubstr: `"code":"invalid_risk_filter"`,
+		},
+		{
+			name:           "invalid limit",
+			query:          "?limit=-1",
+			expectedStatus: http.StatusBadRequest,
+			expectedSubstr: `"code":"invalid_risk_filter"`,
+		},
+		{
+			name:           "invalid event id",
+			query:          "?event_id=nope",
+			serviceErr:     domain.ErrInvalidID,
+			expectedStatus: http.StatusBadRequest,
+			expectedSubstr: `"code":"invalid_risk_filter"`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			svc := &stubRiskService{err: tt.serviceErr}
+			req := withAdminKey(httptest.NewRequest(http.MethodGet, "/admin/risk-decisions"+tt.query, nil), "org-1")
+			rec := httptest.NewRecorder()
+
+			HandleAdminRiskDecisions(svc).ServeHTTP(rec, req)
+
+			if rec.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d (%s)", tt.expectedStatus, rec.Code, rec.Body.String())
+			}
+			if !strings.Contains(rec.Body.String(), tt.expectedSubstr) {
+				t.Fatalf("expected response to contain %q, got %q", tt.expectedSubstr, rec.Body.String())
+			}
+			if tt.expectedFilter.OrganizerID != "" && svc.filter != tt.expectedFilter {
+				t.Fatalf("expected filter %+v, got %+v", tt.expectedFilter, svc.filter)
+			}
+		})
+	}
+}
+
+type stubRiskService struct {
+	err         error
+	organizerID string
+	eventID     string
+	filter      domain.RiskDecisionFilter
+}
+
+func (s *stubRiskService) GetRiskRules(_ context.Context, organizerID, eventID string) (domain.RiskRules, error) {
+	if s.err != nil {
+		return domain.RiskRules{}, s.err
+	}
+	return domain.DefaultRiskRules(), nil
+}
+
+func (s *stubRiskService) UpdateRiskRules(_ context.Context, organizerID, eventID string, rules domain.RiskRules) (domain.RiskRules, error) {
+	s.organizerID, s.eventID = organizerID, eventID
+	if s.err != nil {
+		return domain.RiskRules{}, s.err
+	}
+	return rules, nil
+}
+
+func (s *stubRiskService) ListDecisions(_ context.Context, filter domain.RiskDecisionFilter) ([]domain.RiskAssessment, error) {
+	s.filter = filter
+	if s.err != nil {
+		return nil, s.err
+	}
+	return []domain.RiskAssessment{{
+		ID:        "decision-1",
+		EventID:   "event-1",
+		ZoneID:    "zone-1",
+		IP:        "203.0.113.7",
+		UserAgent: "curl/8.4",
+		Score:     110,
+		Reasons:   []string{domain.RiskReasonAnonymous, domain.RiskReasonBlockedUserAgent},
+		Decision:  domain.RiskBlock,
+		CreatedAt: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC),
+	}}, nil
+}
@@ -0,0 +1,37 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/cimillas/ultimate-ticket/services/api/internal/tracing"
+)
+
+// TraceparentHeader carries the W3C trace context of the caller.
+const TraceparentHeader = tracing.TraceparentHeader
+
+// Trace starts a server span for every request, continuing the caller's trace
+// when it sends a valid traceparent header. Spans are named after the routes
+// pattern that matches, like Instrument's labels.
+func Trace(tracer *tracing.Tracer, routes *http.ServeMux, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, route := routes.Handler(r)
+		if route == "" {
+			route = "unmatched"
+		}
+		parent, _ := tracing.ParseTraceparent(r.Header.Get(TraceparentHeader))
+		ctx, span := tracer.StartRemote(r.Context(), metricMethod(r.Method)+" "+route, tracing.SpanKindServer, parent)
+		defer span.End()
+		span.SetAttr("http.method", r.Method)
+		span.SetAttr("http.route", route)
+		span.SetAttr("http.target", r.URL.Path)
+
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r.WithContext(ctx))
+
+		span.SetAttr("http.status_code", rec.status)
+		if rec.status >= http.StatusInternalServerError {
+			span.SetError(errors.New(http.StatusText(rec.status)))
+		}
+	})
+}
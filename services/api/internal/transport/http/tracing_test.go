@@ -0,0 +1,76 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/cimillas/ultimate-ticket/services/api/internal/clock"
+	"github.com/cimillas/ultimate-ticket/services/api/internal/tracing"
+)
+
+type recordingExporter struct {
+	spans []tracing.SpanData
+}
+
+func (r *recordingExporter) Export(_ context.Context, spans []tracing.SpanData) error {
+	r.spans = append(r.spans, spans...)
+	return nil
+}
+
+func TestTrace(t *testing.T) {
+	t.Parallel()
+
+	exp := &recordingExporter{}
+	tracer := tracing.NewTracer(exp, clock.NewFixed(time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)))
+	mux := http.NewServeMux()
+	var inner tracing.SpanContext
+	mux.Handle("/holds/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, span := tracing.Start(r.Context(), "HoldService.CreateHold")
+		inner = span.Context()
+		span.End()
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	h := Trace(tracer, mux, mux)
+
+	req := httptest.NewRequest(http.MethodPost, "/holds/3f0c1d5e/confirm", nil)
+	req.Header.Set(TraceparentHeader, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	h.ServeHTTP(httptest.NewRecorder(), req)
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/holds/9a7b2c4d/confirm", nil))
+
+	if _, err := tracer.Flush(context.Background()); err != nil {
+		t.Fatalf("flush: %v", err)
+	}
+	if len(exp.spans) != 4 {
+		t.Fatalf("expected 4 spans, got %d", len(exp.spans))
+	}
+	server := exp.spans[1]
+	if server.Name != "POST /holds/" || server.Kind != tracing.SpanKindServer {
+		t.Fatalf("unexpected server span %+v", server)
+	}
+	if server.TraceID.String() != "4bf92f3577b34da6a3ce929d0e0e4736" || server.ParentID.String() != "00f067aa0ba902b7" {
+		t.Fatalf("expected the caller's trace to continue, got %s parent %s", server.TraceID, server.ParentID)
+	}
+	if exp.spans[0].ParentID != server.SpanID {
+		t.Fatal("expected the handler's span to be a child of the server span")
+	}
+	if server.Error != "Service Unavailable" {
+		t.Fatalf("expected a 5xx to fail the span, got %q", server.Error)
+	}
+	want := map[string]any{"http.method": "POST", "http.route": "/holds/", "http.target": "/holds/3f0c1d5e/confirm", "http.status_code": 503}
+	for _, a := range server.Attributes {
+		if want[a.Key] != a.Value {
+			t.Fatalf("attribute %s: expected %v, got %v", a.Key, want[a.Key], a.Value)
+		}
+		delete(want, a.Key)
+	}
+	if len(want) != 0 {
+		t.Fatalf("missing attributes %v", want)
+	}
+
+	if fresh := exp.spans[3]; fresh.TraceID == server.TraceID || fresh.ParentID.IsValid() || inner.TraceID != fresh.TraceID {
+		t.Fatalf("expected a new trace without a traceparent, got %+v", fresh)
+	}
+}
@@ -0,0 +1,259 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/cimillas/ultimate-ticket/services/api/internal/app"
+	"github.com/cimillas/ultimate-ticket/services/api/internal/domain"
+)
+
+// AdminWebhookService is the minimal interface needed for admin webhook endpoints.
+type AdminWebhookService interface {
+	CreateSubscription(ctx context.Context, in app.CreateWebhookInput) (domain.WebhookSubscription, error)
+	ListSubscriptions(ctx context.Context, organizerID string) ([]domain.WebhookSubscription, error)
+	DeleteSubscription(ctx context.Context, organizerID, id string) error
+	ListDeliveries(ctx context.Context, organizerID, subscriptionID string, status domain.WebhookDeliveryStatus) ([]domain.WebhookDelivery, error)
+	ListAttempts(ctx context.Context, organizerID, deliveryID string) ([]domain.WebhookAttempt, error)
+	ReplayDelivery(ctx context.Context, organizerID, deliveryID string) (domain.WebhookDelivery, error)
+}
+
+// HandleAdminWebhooks returns an HTTP handler for webhook subscription creation/listing.
+func HandleAdminWebhooks(svc AdminWebhookService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		organizerID, ok := adminOrganizer(w, r)
+		if !ok {
+			return
+		}
+		switch r.Method {
+		case http.MethodGet:
+			subs, err := svc.ListSubscriptions(r.Context(), organizerID)
+			if err != nil {
+				writeInternalError(w, r, err)
+				return
+			}
+			resp := make([]webhookSubscriptionResponse, 0, len(subs))
+			for _, sub := range subs {
+				resp = append(resp, newWebhookSubscriptionResponse(sub))
+			}
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(resp)
+		case http.MethodPost:
+			var req createWebhookRequest
+			dec := json.NewDecoder(r.Body)
+			dec.DisallowUnknownFields()
+			if err := dec.Decode(&req); err != nil {
+				writeError(w, http.StatusBadRequest, codeInvalidRequestBody, "invalid request body")
+				return
+			}
+
+			sub, err := svc.CreateSubscription(r.Context(), app.CreateWebhookInput{
+				OrganizerID: organizerID,
+				URL:         req.URL,
+				Secret:      req.Secret,
+				EventTypes:  req.EventTypes,
+			})
+			if err != nil {
+				switch err {
+				case domain.ErrInvalidWebhookURL:
+					writeError(w, http.StatusBadRequest, codeInvalidWebhookURL, err.Error())
+				case domain.ErrWebhookSecretRequired:
+					writeError(w, http.StatusBadRequest, codeWebhookSecretRequired, err.Error())
+				case domain.ErrInvalidWebhookEvent:
+					writeError(w, http.StatusBadRequest, codeInvalidWebhookEvent, err.Error())
+				default:
+					writeInternalError(w, r, err)
+				}
+				return
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusCreated)
+			_ = json.NewEncoder(w).Encode(newWebhookSubscriptionResponse(sub))
+		default:
+			writeError(w, http.StatusMethodNotAllowed, codeMethodNotAllowed, "method not allowed")
+		}
+	}
+}
+
+// HandleAdminWebhook returns an HTTP handler for a single subscription and its deliveries:
+//
+//	DELETE /admin/webhooks/{id}
+//	GET    /admin/webhooks/{id}/deliveries[?status=pending|delivered|dead]
+//	GET    /admin/webhooks/deliveries/{delivery_id}/attempts
+//	POST   /admin/webhooks/deliveries/{delivery_id}/replay
+func HandleAdminWebhook(svc AdminWebhookService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		organizerID, ok := adminOrganizer(w, r)
+		if !ok {
+			return
+		}
+		parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/admin/webhooks"), "/"), "/")
+		switch {
+		case len(parts) == 3 && parts[0] == "deliveries" && parts[2] == "attempts" && parts[1] != "":
+			handleWebhookAttempts(w, r, svc, organizerID, parts[1])
+		case len(parts) == 3 && parts[0] == "deliveries" && parts[2] == "replay" && parts[1] != "":
+			handleWebhookReplay(w, r, svc, organizerID, parts[1])
+		case len(parts) == 2 && parts[1] == "deliveries" && parts[0] != "":
+			handleWebhookDeliveries(w, r, svc, organizerID, parts[0])
+		case len(parts) == 1 && parts[0] != "" && parts[0] != "deliveries":
+			if r.Method != http.MethodDelete {
+				writeError(w, http.StatusMethodNotAllowed, codeMethodNotAllowed, "method not allowed")
+				return
+			}
+			if err := svc.DeleteSubscription(r.Context(), organizerID, parts[0]); err != nil {
+				writeWebhookError(w, r, err)
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			writeError(w, http.StatusNotFound, codeNotFound, "not found")
+		}
+	}
+}
+
+func handleWebhookDeliveries(w http.ResponseWriter, r *http.Request, svc AdminWebhookService, organizerID, subscriptionID string) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, codeMethodNotAllowed, "method not allowed")
+		return
+	}
+	status := domain.WebhookDeliveryStatus(r.URL.Query().Get("status"))
+	switch status {
+	case "", domain.WebhookDeliveryPending, domain.WebhookDeliveryDelivered, domain.WebhookDeliveryDead, domain.WebhookDeliveryCancelled:
+	default:
+		writeError(w, http.StatusBadRequest, codeInvalidDeliveryStatus, "invalid delivery status")
+		return
+	}
+
+	deliveries, err := svc.ListDeliveries(r.Context(), organizerID, subscriptionID, status)
+	if err != nil {
+		writeWebhookError(w, r, err)
+		return
+	}
+	resp := make([]webhookDeliveryResponse, 0, len(deliveries))
+	for _, d := range deliveries {
+		resp = append(resp, newWebhookDeliveryResponse(d))
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+func handleWebhookAttempts(w http.ResponseWriter, r *http.Request, svc AdminWebhookService, organizerID, deliveryID string) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, codeMethodNotAllowed, "method not allowed")
+		return
+	}
+	attempts, err := svc.ListAttempts(r.Context(), organizerID, deliveryID)
+	if err != nil {
+		writeWebhookError(w, r, err)
+		return
+	}
+	resp := make([]webhookAttemptResponse, 0, len(attempts))
+	for _, a := range attempts {
+		resp = append(resp, webhookAttemptResponse{
+			AttemptedAt: a.AttemptedAt,
+			StatusCode:  a.StatusCode,
+			Error:       a.Error,
+			DurationMS:  a.Duration.Milliseconds(),
+		})
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+func handleWebhookReplay(w http.ResponseWriter, r *http.Request, svc AdminWebhookService, organizerID, deliveryID string) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, codeMethodNotAllowed, "method not allowed")
+		return
+	}
+	d, err := svc.ReplayDelivery(r.Context(), organizerID, deliveryID)
+	if err != nil {
+		writeWebhookError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	_ = json.NewEncoder(w).Encode(newWebhookDeliveryResponse(d))
+}
+
+func writeWebhookError(w http.ResponseWriter, r *http.Request, err error) {
+	switch err {
+	case domain.ErrInvalidID:
+		writeError(w, http.StatusNotFound, codeInvalidID, err.Error())
+	case domain.ErrWebhookNotFound:
+		writeError(w, http.StatusNotFound, codeWebhookNotFound, err.Error())
+	case domain.ErrDeliveryNotFound:
+		writeError(w, http.StatusNotFound, codeDeliveryNotFound, err.Error())
+	case domain.ErrDeliveryNotReplayable:
+		writeError(w, http.StatusConflict, codeDeliveryNotReplayable, err.Error())
+	default:
+		writeInternalError(w, r, err)
+	}
+}
+
+type createWebhookRequest struct {
+	URL        string   `json:"url"`
+	Secret     string   `json:"secret"`
+	EventTypes []string `json:"event_types,omitempty"`
+}
+
+// webhookSubscriptionResponse never includes the signing secret.
+type webhookSubscriptionResponse struct {
+	ID         string    `json:"id"`
+	URL        string    `json:"url"`
+	EventTypes []string  `json:"event_types"`
+	Active     bool      `json:"active"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+func newWebhookSubscriptionResponse(sub domain.WebhookSubscription) webhookSubscriptionResponse {
+	types := make([]string, 0, len(sub.EventTypes))
+	for _, t := range sub.EventTypes {
+		types = append(types, string(t))
+	}
+	return webhookSubscriptionResponse{
+		ID:         sub.ID,
+		URL:        sub.URL,
+		EventTypes: types,
+		Active:     sub.Active,
+		CreatedAt:  sub.CreatedAt,
+	}
+}
+
+type webhookDeliveryResponse struct {
+	ID             string     `json:"id"`
+	SubscriptionID string     `json:"subscription_id"`
+	EventID        string     `json:"event_id"`
+	EventType      string     `json:"event_type"`
+	Status         string     `json:"status"`
+	Attempts       int        `json:"attempts"`
+	NextAttemptAt  time.Time  `json:"next_attempt_at"`
+	LastError      string     `json:"last_error,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	DeliveredAt    *time.Time `json:"delivered_at,omitempty"`
+}
+
+func newWebhookDeliveryResponse(d domain.WebhookDelivery) webhookDeliveryResponse {
+	return webhookDeliveryResponse{
+		ID:             d.ID,
+		SubscriptionID: d.SubscriptionID,
+		EventID:        d.EventID,
+		EventType:      string(d.EventType),
+		Status:         string(d.Status),
+		Attempts:       d.Attempts,
+		NextAttemptAt:  d.NextAttemptAt,
+		LastError:      d.LastError,
+		CreatedAt:      d.CreatedAt,
+		DeliveredAt:    d.DeliveredAt,
+	}
+}
+
+type webhookAttemptResponse struct {
+	AttemptedAt time.Time `json:"attempted_at"`
+	StatusCode  int       `json:"status_code,omitempty"`
+	Error       string    `json:"error,omitempty"`
+	DurationMS  int64     `json:"duration_ms"`
+}
@@ -0,0 +1,233 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cimillas/ultimate-ticket/services/api/internal/app"
+	"github.com/cimillas/ultimate-ticket/services/api/internal/domain"
+)
+
+func TestHandleAdminWebhooks(t *testing.T) {
+	t.Parallel()
+
+	sub := domain.WebhookSubscription{
+		ID:         "sub-1",
+		URL:        "https://crm.example.com/hook",
+		Secret:     "shh",
+		EventTypes: []domain.OutboxEventType{domain.OutboxOrderPaid},
+		Active:     true,
+		CreatedAt:  time.Date(2025, 1, 4, 8, 0, 0, 0, time.UTC),
+	}
+
+	tests := []struct {
+		name           string
+		method         string
+		body           string
+		serviceErr     error
+		expectedStatus int
+		expectedSubstr string
+	}{
+		{
+			name:           "create",
+			method:         http.MethodPost,
+			body:           `{"url":"https://crm.example.com/hook","secret":"shh","event_types":["order.paid"]}`,
+			expectedStatus: http.StatusCreated,
+			expectedSubstr: `"event_types":["order.paid"]`,
+		},
+		{
+			name:           "create with invalid url",
+			method:         http.MethodPost,
+			body:           `{"url":"nope","secret":"shh"}`,
+			serviceErr:     domain.ErrInvalidWebhookURL,
+			expectedStatus: http.StatusBadRequest,
+			expectedSubstr: `"code":"invalid_webhook_url"`,
+		},
+		{
+			name:           "create with unknown field",
+			method:         http.MethodPost,
+			body:           `{"url":"https://crm.example.com","secret":"shh","extra":1}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedSubstr: `"code":"invalid_request_body"`,
+		},
+		{
+			name:           "list",
+			method:         http.MethodGet,
+			expectedStatus: http.StatusOK,
+			expectedSubstr: `"id":"sub-1"`,
+		},
+		{
+			name:           "method not allowed",
+			method:         http.MethodPut,
+			expectedStatus: http.StatusMethodNotAllowed,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			svc := &stubAdminWebhookService{sub: sub, err: tt.serviceErr}
+
+			req := withAdminKey(httptest.NewRequest(tt.method, "/admin/webhooks", bytes.NewBufferString(tt.body)), "org-1")
+			rec := httptest.NewRecorder()
+			HandleAdminWebhooks(svc).ServeHTTP(rec, req)
+
+			if rec.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d", tt.expectedStatus, rec.Code)
+			}
+			if tt.expectedSubstr != "" && !strings.Contains(rec.Body.String(), tt.expectedSubstr) {
+				t.Fatalf("expected response to contain %q, got %q", tt.expectedSubstr, rec.Body.String())
+			}
+			if strings.Contains(rec.Body.String(), "shh") {
+				t.Fatalf("expected secret to be omitted, got %q", rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandleAdminWebhook(t *testing.T) {
+	t.Parallel()
+
+	delivery := domain.WebhookDelivery{
+		ID:             "del-1",
+		SubscriptionID: "sub-1",
+		EventID:        "evt-1",
+		EventType:      domain.OutboxOrderPaid,
+		Status:         domain.WebhookDeliveryDead,
+		Attempts:       8,
+		LastError:      "unexpected status 500",
+	}
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		serviceErr     error
+		expectedStatus int
+		expectedSubstr string
+	}{
+		{
+			name:           "delete subscription",
+			method:         http.MethodDelete,
+			path:           "/admin/webhooks/sub-1",
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name:           "delete missing subscription",
+			method:         http.MethodDelete,
+			path:           "/admin/webhooks/sub-2",
+			serviceErr:     domain.ErrWebhookNotFound,
+			expectedStatus: http.StatusNotFound,
+			expectedSubstr: `"code":"webhook_not_found"`,
+		},
+		{
+			name:           "list deliveries",
+			method:         http.MethodGet,
+			path:           "/admin/webhooks/sub-1/deliveries?status=dead",
+			expectedStatus: http.StatusOK,
+			expectedSubstr: `"status":"dead"`,
+		},
+		{
+			name:           "list deliveries with bad status",
+			method:         http.MethodGet,
+			path:           "/admin/webhooks/sub-1/deliveries?status=lost",
+			expectedStatus: http.StatusBadRequest,
+			expectedSubstr: `"code":"invalid_delivery_status"`,
+		},
+		{
+			name:           "list attempts",
+			method:         http.MethodGet,
+			path:           "/admin/webhooks/deliveries/del-1/attempts",
+			expectedStatus: http.StatusOK,
+			expectedSubstr: `"status_code":500`,
+		},
+		{
+			name:           "replay",
+			method:         http.MethodPost,
+			path:           "/admin/webhooks/deliveries/del-1/replay",
+			expectedStatus: http.StatusAccepted,
+			expectedSubstr: `"id":"del-1"`,
+		},
+		{
+			name:           "replay delivered",
+			method:         http.MethodPost,
+			path:           "/admin/webhooks/deliveries/del-1/replay",
+			serviceErr:     domain.ErrDeliveryNotReplayable,
+			expectedStatus: http.StatusConflict,
+			expectedSubstr: `"code":"delivery_not_replayable"`,
+		},
+		{
+			name:           "replay with wrong method",
+			method:         http.MethodGet,
+			path:           "/admin/webhooks/deliveries/del-1/replay",
+			expectedStatus: http.StatusMethodNotAllowed,
+		},
+		{
+			name:           "unknown path",
+			method:         http.MethodGet,
+			path:           "/admin/webhooks/sub-1/other",
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			svc := &stubAdminWebhookService{delivery: delivery, err: tt.serviceErr}
+
+			req := withAdminKey(httptest.NewRequest(tt.method, tt.path, nil), "org-1")
+			rec := httptest.NewRecorder()
+			HandleAdminWebhook(svc).ServeHTTP(rec, req)
+
+			if rec.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d", tt.expectedStatus, rec.Code)
+			}
+			if tt.expectedSubstr != "" && !strings.Contains(rec.Body.String(), tt.expectedSubstr) {
+				t.Fatalf("expected response to contain %q, got %q", tt.expectedSubstr, rec.Body.String())
+			}
+		})
+	}
+}
+
+type stubAdminWebhookService struct {
+	sub      domain.WebhookSubscription
+	delivery domain.WebhookDelivery
+	err      error
+}
+
+func (s *stubAdminWebhookService) CreateSubscription(_ context.Context, _ app.CreateWebhookInput) (domain.WebhookSubscription, error) {
+	return s.sub, s.err
+}
+
+func (s *stubAdminWebhookService) ListSubscriptions(_ context.Context, _ string) ([]domain.WebhookSubscription, error) {
+	return []domain.WebhookSubscription{s.sub}, s.err
+}
+
+func (s *stubAdminWebhookService) DeleteSubscription(_ context.Context, _, _ string) error {
+	return s.err
+}
+
+func (s *stubAdminWebhookService) ListDeliveries(_ context.Context, _, _ string, _ domain.WebhookDeliveryStatus) ([]domain.WebhookDelivery, error) {
+	return []domain.WebhookDelivery{s.delivery}, s.err
+}
+
+func (s *stubAdminWebhookService) ListAttempts(_ context.Context, _, deliveryID string) ([]domain.WebhookAttempt, error) {
+	return []domain.WebhookAttempt{{DeliveryID: deliveryID, StatusCode: 500, Error: "unexpected status 500"}}, s.err
+}
+
+func (s *stubAdminWebhookService) ReplayDelivery(_ context.Context, _, _ string) (domain.WebhookDelivery, error) {
+	if s.err != nil {
+		return domain.WebhookDelivery{}, s.err
+	}
+	d := s.delivery
+	d.Status = domain.WebhookDeliveryPending
+	d.Attempts = 0
+	return d, nil
+}
@@ -0,0 +1,81 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/cimillas/ultimate-ticket/services/api/internal/app"
+	"github.com/cimillas/ultimate-ticket/services/api/internal/clock"
+	"github.com/cimillas/ultimate-ticket/services/api/internal/netguard"
+	"github.com/cimillas/ultimate-ticket/services/api/internal/tracing"
+)
+
+// Headers sent with outgoing deliveries besides the signature.
+const (
+	IDHeader    = "Webhook-Id"
+	EventHeader = "Webhook-Event"
+)
+
+// DefaultTimeout bounds a single outgoing delivery.
+const DefaultTimeout = 10 * time.Second
+
+// Sender posts signed webhook deliveries to organizer endpoints.
+type Sender struct {
+	client *http.Client
+	clock  clock.Clock
+}
+
+var _ app.WebhookSender = (*Sender)(nil)
+
+// NewSender returns a Sender; a nil client uses NewClient(DefaultTimeout, true).
+func NewSender(client *http.Client, clk clock.Clock) *Sender {
+	if client == nil {
+		client = NewClient(DefaultTimeout, true)
+	}
+	return &Sender{client: client, clock: clk}
+}
+
+// NewClient returns a client for deliveries that never follows redirects, so
+// a receiver cannot bounce a delivery elsewhere. With publicOnly it also
+// refuses to connect to loopback, private and other non-public addresses,
+// whatever the URL's host resolves to, and ignores proxy settings.
+func NewClient(timeout time.Duration, publicOnly bool) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if publicOnly {
+		dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second, Control: netguard.Control}
+		transport.DialContext = dialer.DialContext
+		transport.Proxy = nil
+	}
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+func (s *Sender) Send(ctx context.Context, req app.WebhookRequest) (int, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, req.URL, bytes.NewReader(req.Body))
+	if err != nil {
+		return 0, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set(IDHeader, req.EventID)
+	httpReq.Header.Set(EventHeader, string(req.EventType))
+	httpReq.Header.Set(SignatureHeader, Sign([]byte(req.Secret), s.clock.Now(), req.Body))
+	tracing.Inject(ctx, httpReq.Header)
+
+	resp, err := s.client.Do(httpReq)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	// Drain a bounded amount so the connection can be reused.
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,125 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cimillas/ultimate-ticket/services/api/internal/app"
+	"github.com/cimillas/ultimate-ticket/services/api/internal/clock"
+	"github.com/cimillas/ultimate-ticket/services/api/internal/domain"
+	"github.com/cimillas/ultimate-ticket/services/api/internal/netguard"
+	"github.com/cimillas/ultimate-ticket/services/api/internal/tracing"
+)
+
+func TestSender_SignsDeliveries(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	secret := []byte("organizer-secret")
+	verifier := NewVerifier(secret, DefaultTolerance, clock.NewFixed(now))
+
+	type received struct {
+		verifyErr error
+		id, event string
+		body      string
+		parent    string
+	}
+	got := make(chan received, 1)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		got <- received{
+			verifyErr: verifier.Verify(r.Header.Get(SignatureHeader), body),
+			id:        r.Header.Get(IDHeader),
+			event:     r.Header.Get(EventHeader),
+			body:      string(body),
+			parent:    r.Header.Get(tracing.TraceparentHeader),
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer receiver.Close()
+
+	tracer := tracing.NewTracer(tracing.NewWriterExporter(io.Discard), clock.NewFixed(now))
+	ctx, span := tracer.StartRemote(context.Background(), "deliver", tracing.SpanKindInternal, tracing.SpanContext{})
+	defer span.End()
+
+	sender := NewSender(receiver.Client(), clock.NewFixed(now))
+	status, err := sender.Send(ctx, app.WebhookRequest{
+		URL:       receiver.URL,
+		Secret:    string(secret),
+		EventID:   "evt-1",
+		EventType: domain.OutboxOrderPaid,
+		Body:      []byte(`{"id":"evt-1"}`),
+	})
+	if err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	if status != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d", status)
+	}
+
+	r := <-got
+	if r.verifyErr != nil {
+		t.Fatalf("expected valid signature, got %v", r.verifyErr)
+	}
+	if r.id != "evt-1" || r.event != "order.paid" || r.body != `{"id":"evt-1"}` {
+		t.Fatalf("unexpected delivery %+v", r)
+	}
+	if r.parent != span.Context().Traceparent() {
+		t.Fatalf("expected traceparent %q, got %q", span.Context().Traceparent(), r.parent)
+	}
+}
+
+func TestSender_ReportsFailures(t *testing.T) {
+	t.Parallel()
+
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	sender := NewSender(receiver.Client(), clock.NewFixed(time.Now()))
+
+	status, err := sender.Send(context.Background(), app.WebhookRequest{URL: receiver.URL, Secret: "s", Body: []byte(`{}`)})
+	if err != nil || status != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 without error, got %d (%v)", status, err)
+	}
+
+	receiver.Close()
+	if _, err := sender.Send(context.Background(), app.WebhookRequest{URL: receiver.URL, Secret: "s", Body: []byte(`{}`)}); err == nil {
+		t.Fatalf("expected connection error once the receiver is gone")
+	}
+}
+
+func TestNewClient(t *testing.T) {
+	t.Parallel()
+
+	var hits atomic.Int32
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		if r.URL.Path == "/hook" {
+			http.Redirect(w, r, "/elsewhere", http.StatusTemporaryRedirect)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer receiver.Close()
+	req := app.WebhookRequest{URL: receiver.URL + "/hook", Secret: "s", Body: []byte(`{}`)}
+
+	local := NewSender(NewClient(time.Second, false), clock.NewFixed(time.Now()))
+	status, err := local.Send(context.Background(), req)
+	if err != nil || status != http.StatusTemporaryRedirect || hits.Load() != 1 {
+		t.Fatalf("expected the redirect to be reported, not followed, got %d after %d requests (%v)", status, hits.Load(), err)
+	}
+
+	public := NewSender(nil, clock.NewFixed(time.Now()))
+	if _, err := public.Send(context.Background(), req); !errors.Is(err, netguard.ErrNonPublicAddress) {
+		t.Fatalf("expected the loopback receiver to be refused, got %v", err)
+	}
+	if hits.Load() != 1 {
+		t.Fatalf("expected no request to reach the receiver, got %d", hits.Load())
+	}
+}
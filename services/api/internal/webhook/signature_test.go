@@ -0,0 +1,54 @@
+package webhook
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cimillas/ultimate-ticket/services/api/internal/clock"
+)
+
+func TestVerifier(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, 1, 7, 12, 0, 0, 0, time.UTC)
+	secret := []byte("whsec_test")
+	payload := []byte(`{"id":"evt_1"}`)
+	v := NewVerifier(secret, time.Minute, clock.NewFixed(now))
+
+	tests := []struct {
+		name    string
+		header  string
+		payload []byte
+		want    error
+	}{
+		{name: "valid", header: Sign(secret, now, payload), payload: payload},
+		{name: "within tolerance", header: Sign(secret, now.Add(-30*time.Second), payload), payload: payload},
+		{name: "missing", header: "", payload: payload, want: ErrMissingSignature},
+		{name: "malformed", header: "garbage", payload: payload, want: ErrInvalidSignature},
+		{name: "tampered body", header: Sign(secret, now, payload), payload: []byte(`{"id":"evt_2"}`), want: ErrInvalidSignature},
+		{name: "wrong secret", header: Sign([]byte("other"), now, payload), payload: payload, want: ErrInvalidSignature},
+		{name: "too old", header: Sign(secret, now.Add(-2*time.Minute), payload), payload: payload, want: ErrSignatureTimestamp},
+		{name: "too far in future", header: Sign(secret, now.Add(2*time.Minute), payload), payload: payload, want: ErrSignatureTimestamp},
+		{
+			name:    "rotated secret",
+			header:  Sign([]byte("old"), now, payload) + ",v1=" + signatureOnly(Sign(secret, now, payload)),
+			payload: payload,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if err := v.Verify(tt.header, tt.payload); err != tt.want {
+				t.Fatalf("expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func signatureOnly(header string) string {
+	_, sig, _ := strings.Cut(header, ",v1=")
+	return sig
+}
@@ -0,0 +1,19 @@
+-- Order lifecycle: pending_payment -> paid -> fulfilled, or -> failed/cancelled
+ALTER TABLE orders
+    ADD COLUMN IF NOT EXISTS status TEXT NOT NULL DEFAULT 'paid'
+        CHECK (status IN ('pending_payment', 'paid', 'fulfilled', 'failed', 'cancelled')),
+    ADD COLUMN IF NOT EXISTS updated_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    ADD COLUMN IF NOT EXISTS paid_at      TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS fulfilled_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS failed_at    TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS cancelled_at TIMESTAMPTZ;
+
+-- Orders created before the lifecycle existed were final on creation.
+UPDATE orders SET paid_at = created_at, updated_at = created_at WHERE status = 'paid' AND paid_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS orders_status_idx ON orders(status);
+
+-- Holds released by a failed or cancelled order return their inventory.
+ALTER TABLE holds DROP CONSTRAINT IF EXISTS holds_status_check;
+ALTER TABLE holds ADD CONSTRAINT holds_status_check
+    CHECK (status IN ('active', 'confirmed', 'expired', 'released'));
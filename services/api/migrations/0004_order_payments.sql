@@ -0,0 +1,5 @@
+-- Payment provider reference for orders paid through a PaymentProvider
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS payment_reference TEXT;
+
+CREATE UNIQUE INDEX IF NOT EXISTS orders_payment_reference_unique ON orders(payment_reference)
+    WHERE payment_reference IS NOT NULL;
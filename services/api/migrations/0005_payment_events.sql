@@ -0,0 +1,8 @@
+-- Payment webhook deliveries, deduplicated by the processor's event id
+CREATE TABLE IF NOT EXISTS payment_events (
+    id          TEXT PRIMARY KEY,
+    type        TEXT NOT NULL,
+    order_id    UUID REFERENCES orders(id) ON DELETE SET NULL,
+    outcome     TEXT NOT NULL,
+    received_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,5 @@
+-- Open payment attempts freeze hold expiry for a bounded grace window
+ALTER TABLE holds ADD COLUMN IF NOT EXISTS payment_pending_until TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS holds_payment_pending_lookup ON holds(event_id, zone_id, status, payment_pending_until)
+    WHERE payment_pending_until IS NOT NULL;
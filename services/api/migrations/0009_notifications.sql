@@ -0,0 +1,20 @@
+-- Customer email on orders and the notification queue
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS customer_email TEXT;
+
+CREATE TABLE IF NOT EXISTS notifications (
+    id UUID PRIMARY KEY,
+    kind TEXT NOT NULL,
+    recipient TEXT NOT NULL,
+    subject TEXT NOT NULL,
+    text_body TEXT NOT NULL,
+    html_body TEXT NOT NULL,
+    status TEXT NOT NULL CHECK (status IN ('pending', 'sent', 'failed')),
+    attempts INT NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ NOT NULL,
+    last_error TEXT,
+    dedupe_key TEXT NOT NULL UNIQUE,
+    created_at TIMESTAMPTZ NOT NULL,
+    sent_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS notifications_due ON notifications(next_attempt_at) WHERE status = 'pending';
@@ -0,0 +1,11 @@
+-- Customer accounts and ownership of holds and orders
+CREATE TABLE IF NOT EXISTS customers (
+    id         UUID PRIMARY KEY,
+    email      TEXT NOT NULL UNIQUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+ALTER TABLE holds ADD COLUMN IF NOT EXISTS customer_id UUID REFERENCES customers(id);
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS customer_id UUID REFERENCES customers(id);
+
+CREATE INDEX IF NOT EXISTS orders_customer_lookup ON orders(customer_id, created_at) WHERE customer_id IS NOT NULL;
@@ -0,0 +1,20 @@
+-- Passwordless login codes and customer sessions
+CREATE TABLE IF NOT EXISTS login_codes (
+    id          UUID PRIMARY KEY,
+    email       TEXT NOT NULL,
+    code_hash   TEXT NOT NULL,
+    attempts    INT NOT NULL DEFAULT 0,
+    expires_at  TIMESTAMPTZ NOT NULL,
+    consumed_at TIMESTAMPTZ,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS login_codes_active ON login_codes(email, created_at) WHERE consumed_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS sessions (
+    token_hash  TEXT PRIMARY KEY,
+    customer_id UUID NOT NULL REFERENCES customers(id),
+    expires_at  TIMESTAMPTZ NOT NULL,
+    revoked_at  TIMESTAMPTZ,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
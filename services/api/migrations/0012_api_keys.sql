@@ -0,0 +1,11 @@
+-- Hashed API keys with roles for admin endpoints
+CREATE TABLE IF NOT EXISTS api_keys (
+    id           UUID PRIMARY KEY,
+    name         TEXT NOT NULL,
+    prefix       TEXT NOT NULL,
+    key_hash     TEXT NOT NULL UNIQUE,
+    role         TEXT NOT NULL CHECK (role IN ('owner', 'event_manager', 'box_office', 'scanner', 'read_only')),
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_used_at TIMESTAMPTZ,
+    revoked_at   TIMESTAMPTZ
+);
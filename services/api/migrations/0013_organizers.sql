@@ -0,0 +1,37 @@
+-- Organizers (tenants) owning events, admin keys, webhooks and notifications.
+-- Existing data moves to a default organizer so single-tenant installs keep working.
+CREATE TABLE IF NOT EXISTS organizers (
+    id         UUID PRIMARY KEY,
+    name       TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+INSERT INTO organizers (id, name)
+VALUES ('00000000-0000-0000-0000-000000000001', 'Default organizer')
+ON CONFLICT (id) DO NOTHING;
+
+ALTER TABLE events ADD COLUMN IF NOT EXISTS organizer_id UUID NOT NULL
+    DEFAULT '00000000-0000-0000-0000-000000000001' REFERENCES organizers(id);
+CREATE INDEX IF NOT EXISTS events_organizer ON events(organizer_id, created_at);
+
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS organizer_id UUID NOT NULL
+    DEFAULT '00000000-0000-0000-0000-000000000001' REFERENCES organizers(id);
+
+ALTER TABLE webhook_subscriptions ADD COLUMN IF NOT EXISTS organizer_id UUID NOT NULL
+    DEFAULT '00000000-0000-0000-0000-000000000001' REFERENCES organizers(id);
+
+ALTER TABLE webhook_deliveries ADD COLUMN IF NOT EXISTS organizer_id UUID REFERENCES organizers(id);
+UPDATE webhook_deliveries d SET organizer_id = s.organizer_id
+FROM webhook_subscriptions s
+WHERE d.subscription_id = s.id AND d.organizer_id IS NULL;
+ALTER TABLE webhook_deliveries ALTER COLUMN organizer_id SET NOT NULL;
+
+ALTER TABLE notifications ADD COLUMN IF NOT EXISTS organizer_id UUID REFERENCES organizers(id);
+-- Order notifications are deduplicated by "<outbox event id>:<order id>".
+UPDATE notifications n SET organizer_id = e.organizer_id
+FROM orders o
+JOIN holds h ON h.id = o.hold_id
+JOIN events e ON e.id = h.event_id
+WHERE o.id::text = split_part(n.dedupe_key, ':', 2) AND n.organizer_id IS NULL;
+UPDATE notifications SET organizer_id = '00000000-0000-0000-0000-000000000001' WHERE organizer_id IS NULL;
+ALTER TABLE notifications ALTER COLUMN organizer_id SET NOT NULL;
@@ -0,0 +1,27 @@
+-- Append-only audit log of administrative changes
+CREATE TABLE IF NOT EXISTS audit_log (
+    id              UUID PRIMARY KEY,
+    organizer_id    UUID NOT NULL REFERENCES organizers(id),
+    actor           TEXT NOT NULL,
+    action          TEXT NOT NULL,
+    target_type     TEXT NOT NULL,
+    target_id       TEXT NOT NULL,
+    before_snapshot JSONB,
+    after_snapshot  JSONB,
+    request_id      TEXT NOT NULL DEFAULT '',
+    created_at      TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS audit_log_organizer ON audit_log(organizer_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS audit_log_target ON audit_log(organizer_id, target_type, target_id);
+
+CREATE OR REPLACE FUNCTION audit_log_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS audit_log_append_only ON audit_log;
+CREATE TRIGGER audit_log_append_only
+    BEFORE UPDATE OR DELETE ON audit_log
+    FOR EACH ROW EXECUTE FUNCTION audit_log_append_only();
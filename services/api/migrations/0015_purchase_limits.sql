@@ -0,0 +1,2 @@
+-- Per-customer purchase limits sum a customer's holds for an event
+CREATE INDEX IF NOT EXISTS holds_customer_event ON holds(customer_id, event_id) WHERE customer_id IS NOT NULL;
@@ -0,0 +1,4 @@
+-- Per-hold quantity rules per zone; max_quantity 0 means no maximum
+ALTER TABLE zones ADD COLUMN IF NOT EXISTS min_quantity INTEGER NOT NULL DEFAULT 1 CHECK (min_quantity > 0);
+ALTER TABLE zones ADD COLUMN IF NOT EXISTS max_quantity INTEGER NOT NULL DEFAULT 0 CHECK (max_quantity >= 0);
+ALTER TABLE zones ADD COLUMN IF NOT EXISTS quantity_step INTEGER NOT NULL DEFAULT 1 CHECK (quantity_step > 0);
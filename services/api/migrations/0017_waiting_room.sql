@@ -0,0 +1,16 @@
+-- Per-event waiting room and its queue
+ALTER TABLE events ADD COLUMN IF NOT EXISTS waiting_room_enabled BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE events ADD COLUMN IF NOT EXISTS admit_per_minute INTEGER NOT NULL DEFAULT 0 CHECK (admit_per_minute >= 0);
+
+CREATE TABLE IF NOT EXISTS queue_entries (
+    id          UUID PRIMARY KEY,
+    seq         BIGSERIAL NOT NULL,
+    event_id    UUID NOT NULL REFERENCES events(id) ON DELETE CASCADE,
+    customer_id UUID REFERENCES customers(id),
+    status      TEXT NOT NULL CHECK (status IN ('waiting', 'admitted')),
+    joined_at   TIMESTAMPTZ NOT NULL,
+    admitted_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS queue_entries_waiting ON queue_entries(event_id, seq) WHERE status = 'waiting';
+CREATE INDEX IF NOT EXISTS queue_entries_admitted ON queue_entries(event_id, admitted_at) WHERE status = 'admitted';
@@ -0,0 +1,30 @@
+-- Ballot sales: one lottery per zone and one ballot per customer
+CREATE TABLE IF NOT EXISTS lotteries (
+    id                     UUID PRIMARY KEY,
+    event_id               UUID NOT NULL REFERENCES events(id) ON DELETE CASCADE,
+    zone_id                UUID NOT NULL UNIQUE REFERENCES zones(id) ON DELETE CASCADE,
+    registration_opens_at  TIMESTAMPTZ NOT NULL,
+    registration_closes_at TIMESTAMPTZ NOT NULL,
+    claim_window_seconds   INTEGER NOT NULL CHECK (claim_window_seconds > 0),
+    status                 TEXT NOT NULL CHECK (status IN ('open', 'drawn')),
+    seed                   TEXT NOT NULL DEFAULT '',
+    drawn_at               TIMESTAMPTZ,
+    created_at             TIMESTAMPTZ NOT NULL,
+    CHECK (registration_closes_at > registration_opens_at)
+);
+
+CREATE TABLE IF NOT EXISTS lottery_ballots (
+    id               UUID PRIMARY KEY,
+    lottery_id       UUID NOT NULL REFERENCES lotteries(id) ON DELETE CASCADE,
+    customer_id      UUID NOT NULL REFERENCES customers(id),
+    quantity         INTEGER NOT NULL CHECK (quantity > 0),
+    status           TEXT NOT NULL CHECK (status IN ('entered', 'waitlisted', 'offered', 'redeemed', 'lapsed')),
+    rank             INTEGER NOT NULL DEFAULT 0,
+    offer_expires_at TIMESTAMPTZ,
+    hold_id          UUID REFERENCES holds(id),
+    created_at       TIMESTAMPTZ NOT NULL,
+    UNIQUE (lottery_id, customer_id)
+);
+
+CREATE INDEX IF NOT EXISTS lottery_ballots_waitlisted ON lottery_ballots(lottery_id, rank) WHERE status = 'waitlisted';
+CREATE INDEX IF NOT EXISTS lottery_ballots_offered ON lottery_ballots(offer_expires_at) WHERE status = 'offered';
@@ -0,0 +1,8 @@
+-- Proof-of-work challenges spent on holds, kept until they expire
+CREATE TABLE IF NOT EXISTS pow_redemptions (
+    challenge_id    TEXT PRIMARY KEY,
+    idempotency_key TEXT NOT NULL,
+    expires_at      TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS pow_redemptions_expires_at ON pow_redemptions(expires_at);
@@ -0,0 +1,33 @@
+-- Per-event hold risk rules and the log of scored hold attempts
+CREATE TABLE IF NOT EXISTS event_risk_rules (
+    event_id               UUID PRIMARY KEY REFERENCES events(id) ON DELETE CASCADE,
+    enabled                BOOLEAN NOT NULL DEFAULT FALSE,
+    challenge_score        INTEGER NOT NULL CHECK (challenge_score > 0),
+    block_score            INTEGER NOT NULL,
+    max_holds_per_ip       INTEGER NOT NULL DEFAULT 0 CHECK (max_holds_per_ip >= 0),
+    max_holds_per_customer INTEGER NOT NULL DEFAULT 0 CHECK (max_holds_per_customer >= 0),
+    max_holds_per_zone     INTEGER NOT NULL DEFAULT 0 CHECK (max_holds_per_zone >= 0),
+    blocked_user_agents    TEXT[] NOT NULL DEFAULT '{}',
+    updated_at             TIMESTAMPTZ NOT NULL,
+    CHECK (block_score >= challenge_score)
+);
+
+CREATE TABLE IF NOT EXISTS risk_decisions (
+    id              UUID PRIMARY KEY,
+    event_id        UUID NOT NULL REFERENCES events(id) ON DELETE CASCADE,
+    zone_id         TEXT NOT NULL,
+    customer_id     UUID REFERENCES customers(id),
+    ip              TEXT NOT NULL,
+    user_agent      TEXT NOT NULL,
+    idempotency_key TEXT NOT NULL,
+    proof_of_work   BOOLEAN NOT NULL,
+    score           INTEGER NOT NULL,
+    reasons         TEXT[] NOT NULL,
+    decision        TEXT NOT NULL CHECK (decision IN ('allow', 'challenge', 'block')),
+    created_at      TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS risk_decisions_event ON risk_decisions(event_id, created_at);
+CREATE INDEX IF NOT EXISTS risk_decisions_ip ON risk_decisions(event_id, ip, created_at);
+CREATE INDEX IF NOT EXISTS risk_decisions_customer ON risk_decisions(event_id, customer_id, created_at) WHERE customer_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS risk_decisions_zone ON risk_decisions(event_id, zone_id, created_at);
@@ -0,0 +1,9 @@
+-- Deliveries of deactivated subscriptions are cancelled instead of left pending
+ALTER TABLE webhook_deliveries DROP CONSTRAINT IF EXISTS webhook_deliveries_status_check;
+ALTER TABLE webhook_deliveries ADD CONSTRAINT webhook_deliveries_status_check
+    CHECK (status IN ('pending', 'delivered', 'dead', 'cancelled'));
+
+UPDATE webhook_deliveries d
+SET status = 'cancelled'
+FROM webhook_subscriptions s
+WHERE s.id = d.subscription_id AND NOT s.active AND d.status = 'pending';
@@ -0,0 +1,10 @@
+-- Events that keep failing are dead-lettered so they stop holding back their
+-- aggregate; pending events are claimed by due time and aggregate.
+ALTER TABLE outbox ADD COLUMN IF NOT EXISTS status TEXT NOT NULL DEFAULT 'pending'
+    CHECK (status IN ('pending', 'published', 'dead'));
+
+UPDATE outbox SET status = 'published' WHERE published_at IS NOT NULL AND status = 'pending';
+
+DROP INDEX IF EXISTS outbox_unpublished;
+CREATE INDEX IF NOT EXISTS outbox_pending ON outbox(seq) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS outbox_pending_aggregate ON outbox(aggregate_type, aggregate_id, seq) WHERE status = 'pending';
@@ -0,0 +1,9 @@
+-- Holds remember the waiting-room admission they were made with, so one
+-- admission keeps one hold, and a customer waits at most once per event.
+ALTER TABLE holds ADD COLUMN IF NOT EXISTS queue_entry_id UUID REFERENCES queue_entries(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS holds_queue_entry ON holds(queue_entry_id) WHERE queue_entry_id IS NOT NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS queue_entries_customer_waiting
+    ON queue_entries(event_id, customer_id) WHERE status = 'waiting' AND customer_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS queue_entries_customer ON queue_entries(customer_id, event_id, seq) WHERE customer_id IS NOT NULL;
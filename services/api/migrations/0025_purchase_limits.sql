@@ -0,0 +1,3 @@
+-- Per-customer ticket limits per event and per zone; 0 means unlimited
+ALTER TABLE events ADD COLUMN IF NOT EXISTS purchase_limit INTEGER NOT NULL DEFAULT 0 CHECK (purchase_limit >= 0);
+ALTER TABLE zones ADD COLUMN IF NOT EXISTS purchase_limit INTEGER NOT NULL DEFAULT 0 CHECK (purchase_limit >= 0);
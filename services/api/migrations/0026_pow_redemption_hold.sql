@@ -0,0 +1,4 @@
+-- Bind each spent challenge to the hold's event and zone, not just its
+-- idempotency key, so one solution cannot buy a hold in every zone
+ALTER TABLE pow_redemptions ADD COLUMN IF NOT EXISTS event_id TEXT NOT NULL DEFAULT '';
+ALTER TABLE pow_redemptions ADD COLUMN IF NOT EXISTS zone_id TEXT NOT NULL DEFAULT '';
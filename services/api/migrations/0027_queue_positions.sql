@@ -0,0 +1,30 @@
+-- Queue positions from per-event counters instead of counting the entries
+-- ahead on every poll. Each entry takes the next ticket of its event when it
+-- joins; admitted is the highest ticket admitted so far, and entries are
+-- admitted in ticket order, so a waiting entry's position is ticket - admitted.
+CREATE TABLE IF NOT EXISTS queue_counters (
+    event_id UUID PRIMARY KEY REFERENCES events(id) ON DELETE CASCADE,
+    joined   BIGINT NOT NULL DEFAULT 0,
+    admitted BIGINT NOT NULL DEFAULT 0
+);
+
+ALTER TABLE queue_entries ADD COLUMN IF NOT EXISTS ticket BIGINT;
+
+UPDATE queue_entries q
+SET ticket = n.ticket
+FROM (
+    SELECT id, ROW_NUMBER() OVER (PARTITION BY event_id ORDER BY seq) AS ticket
+    FROM queue_entries
+) n
+WHERE q.id = n.id AND q.ticket IS NULL;
+
+INSERT INTO queue_counters (event_id, joined, admitted)
+SELECT event_id, MAX(ticket), COALESCE(MAX(ticket) FILTER (WHERE status = 'admitted'), 0)
+FROM queue_entries
+GROUP BY event_id
+ON CONFLICT (event_id) DO NOTHING;
+
+ALTER TABLE queue_entries ALTER COLUMN ticket SET NOT NULL;
+
+DROP INDEX IF EXISTS queue_entries_waiting;
+CREATE INDEX IF NOT EXISTS queue_entries_waiting_ticket ON queue_entries(event_id, ticket) WHERE status = 'waiting';
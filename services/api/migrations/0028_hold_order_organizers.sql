@@ -0,0 +1,26 @@
+-- Holds and orders carry the organizer of their event, so tenant-scoped
+-- queries filter on the row itself instead of joining through events. The
+-- composite foreign keys keep the copy equal to the event's organizer (for
+-- holds) and to the hold's organizer (for orders).
+CREATE UNIQUE INDEX IF NOT EXISTS events_id_organizer ON events(id, organizer_id);
+
+ALTER TABLE holds ADD COLUMN IF NOT EXISTS organizer_id UUID REFERENCES organizers(id);
+UPDATE holds h SET organizer_id = e.organizer_id
+FROM events e
+WHERE e.id = h.event_id AND h.organizer_id IS NULL;
+ALTER TABLE holds ALTER COLUMN organizer_id SET NOT NULL;
+ALTER TABLE holds DROP CONSTRAINT IF EXISTS holds_event_organizer_fkey;
+ALTER TABLE holds ADD CONSTRAINT holds_event_organizer_fkey
+    FOREIGN KEY (event_id, organizer_id) REFERENCES events(id, organizer_id) ON DELETE CASCADE;
+CREATE UNIQUE INDEX IF NOT EXISTS holds_id_organizer ON holds(id, organizer_id);
+CREATE INDEX IF NOT EXISTS holds_organizer ON holds(organizer_id, created_at);
+
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS organizer_id UUID REFERENCES organizers(id);
+UPDATE orders o SET organizer_id = h.organizer_id
+FROM holds h
+WHERE h.id = o.hold_id AND o.organizer_id IS NULL;
+ALTER TABLE orders ALTER COLUMN organizer_id SET NOT NULL;
+ALTER TABLE orders DROP CONSTRAINT IF EXISTS orders_hold_organizer_fkey;
+ALTER TABLE orders ADD CONSTRAINT orders_hold_organizer_fkey
+    FOREIGN KEY (hold_id, organizer_id) REFERENCES holds(id, organizer_id) ON DELETE CASCADE;
+CREATE INDEX IF NOT EXISTS orders_organizer ON orders(organizer_id, created_at);
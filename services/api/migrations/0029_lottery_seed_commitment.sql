@@ -0,0 +1,13 @@
+-- Lottery seeds are committed when the lottery is created: seed_hash is the
+-- hex SHA-256 of the seed and is published from the start, and the draw must
+-- use the seed it commits to. Open lotteries created before this get a
+-- server-drawn seed now, so their organizers can no longer pick one.
+ALTER TABLE lotteries ADD COLUMN IF NOT EXISTS seed_hash TEXT NOT NULL DEFAULT '';
+
+UPDATE lotteries
+SET seed = replace(gen_random_uuid()::text, '-', '')
+WHERE status = 'open' AND seed = '';
+
+UPDATE lotteries
+SET seed_hash = encode(sha256(convert_to(seed, 'UTF8')), 'hex')
+WHERE seed_hash = '' AND seed <> '';
@@ -0,0 +1,7 @@
+-- Login throttling counts the codes requested per address and per client IP,
+-- and the wrong guesses per address, over a recent window, including codes
+-- that were already replaced or burned.
+ALTER TABLE login_codes ADD COLUMN IF NOT EXISTS client_ip TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS login_codes_email_created ON login_codes(email, created_at);
+CREATE INDEX IF NOT EXISTS login_codes_client_ip_created ON login_codes(client_ip, created_at) WHERE client_ip <> '';
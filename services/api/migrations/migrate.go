@@ -15,19 +15,10 @@ var migrationFiles embed.FS
 
 // Apply runs embedded SQL migrations in filename order.
 func Apply(ctx context.Context, pool *pgxpool.Pool) error {
-	entries, err := migrationFiles.ReadDir(".")
+	names, err := migrationNames()
 	if err != nil {
-		return fmt.Errorf("read migrations: %w", err)
-	}
-
-	names := make([]string, 0, len(entries))
-	for _, e := range entries {
-		if e.IsDir() {
-			continue
-		}
-		names = append(names, e.Name())
+		return err
 	}
-	sort.Strings(names)
 
 	conn, err := pool.Acquire(ctx)
 	if err != nil {
@@ -77,3 +68,68 @@ CREATE TABLE IF NOT EXISTS schema_migrations (
 	}
 	return nil
 }
+
+// Pending lists the embedded migrations the database has not recorded, in
+// filename order. Empty files are never recorded, so they are not pending.
+func Pending(ctx context.Context, pool *pgxpool.Pool) ([]string, error) {
+	names, err := migrationNames()
+	if err != nil {
+		return nil, err
+	}
+
+	applied := make(map[string]bool)
+	var exists bool
+	if err := pool.QueryRow(ctx, `SELECT to_regclass('schema_migrations') IS NOT NULL`).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("check schema_migrations: %w", err)
+	}
+	if exists {
+		rows, err := pool.Query(ctx, `SELECT name FROM schema_migrations`)
+		if err != nil {
+			return nil, fmt.Errorf("list applied migrations: %w", err)
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var name string
+			if err := rows.Scan(&name); err != nil {
+				return nil, fmt.Errorf("scan applied migration: %w", err)
+			}
+			applied[name] = true
+		}
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("list applied migrations: %w", err)
+		}
+	}
+
+	var pending []string
+	for _, name := range names {
+		if applied[name] {
+			continue
+		}
+		sqlBytes, err := migrationFiles.ReadFile(name)
+		if err != nil {
+			return nil, fmt.Errorf("read migration %s: %w", name, err)
+		}
+		if strings.TrimSpace(string(sqlBytes)) == "" {
+			continue
+		}
+		pending = append(pending, name)
+	}
+	return pending, nil
+}
+
+func migrationNames() ([]string, error) {
+	entries, err := migrationFiles.ReadDir(".")
+	if err != nil {
+		return nil, fmt.Errorf("read migrations: %w", err)
+	}
+
+	names := make([]string, 0, len(entries))
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		names = append(names, e.Name())
+	}
+	sort.Strings(names)
+	return names, nil
+}
@@ -40,3 +40,33 @@ func TestApply_RecordsMigrations(t *testing.T) {
 		t.Fatalf("expected migration count unchanged, got %d vs %d", count2, count)
 	}
 }
+
+func TestPending(t *testing.T) {
+	pool := testutil.NewTestPool(t)
+	ctx := context.Background()
+
+	if err := migrations.Apply(ctx, pool); err != nil {
+		t.Fatalf("apply migrations: %v", err)
+	}
+	pending, err := migrations.Pending(ctx, pool)
+	if err != nil {
+		t.Fatalf("pending: %v", err)
+	}
+	if len(pending) != 0 {
+		t.Fatalf("expected no pending migrations, got %v", pending)
+	}
+
+	if _, err := pool.Exec(ctx, `DELETE FROM schema_migrations WHERE name = '0021_risk.sql'`); err != nil {
+		t.Fatalf("forget migration: %v", err)
+	}
+	t.Cleanup(func() {
+		_, _ = pool.Exec(context.Background(), `INSERT INTO schema_migrations (name) VALUES ('0021_risk.sql') ON CONFLICT DO NOTHING`)
+	})
+	pending, err = migrations.Pending(ctx, pool)
+	if err != nil {
+		t.Fatalf("pending: %v", err)
+	}
+	if len(pending) != 1 || pending[0] != "0021_risk.sql" {
+		t.Fatalf("expected 0021_risk.sql pending, got %v", pending)
+	}
+}